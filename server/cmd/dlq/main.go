@@ -0,0 +1,228 @@
+// Command dlq inspects, replays and purges dead-lettered jobs of the job queue
+//
+//	dlq list [-type T] [-before 2024-06-01] [-limit 20]
+//	dlq show <job-id>
+//	dlq replay <job-id>...
+//	dlq purge (-type T | -before 2024-06-01 | <job-id>...)
+//
+// Replayed jobs are run by the server's workers once they are pending again.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
+)
+
+func main() {
+	jobType := flag.String("type", "", "only dead jobs of this type (list, purge)")
+	before := flag.String("before", "", "only jobs that died before this RFC 3339 time or date (list, purge)")
+	limit := flag.Int("limit", 20, "maximum number of jobs listed")
+	timeout := flag.Duration("timeout", time.Minute, "timeout of the whole run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] list | show <job-id> | replay <job-id>... | purge [<job-id>...]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "MongoDB is configured by the usual MONGODB_* variables.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// Flags may follow the command too, e.g. "dlq list -type push.send"
+	command := flag.Arg(0)
+	if err := flag.CommandLine.Parse(flag.Args()[1:]); err != nil {
+		os.Exit(2)
+	}
+	args := flag.Args()
+
+	filter := repository.DeadJobFilter{Type: *jobType}
+	if *before != "" {
+		t, err := parseTime(*before)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filter.Before = t
+	}
+
+	cfg := config.NewConfig()
+	logger.Init(cfg.Env)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db := resources.NewDB(cfg)
+	if err := db.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer db.Close(context.Background())
+
+	queue, err := newQueue(cfg, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "list":
+		err = list(ctx, queue, filter, *limit)
+	case "show":
+		if len(args) != 1 {
+			log.Fatal("show takes a job ID")
+		}
+		err = show(ctx, queue, args[0])
+	case "replay":
+		if len(args) == 0 {
+			log.Fatal("replay takes job IDs")
+		}
+		err = replay(ctx, queue, args)
+	case "purge":
+		if len(args) == 0 && filter == (repository.DeadJobFilter{}) {
+			log.Fatal("purge takes job IDs, -type or -before")
+		}
+		err = purge(ctx, queue, filter, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newQueue creates the job queue on the jobs collection, with the configured ID codec
+func newQueue(cfg *config.Config, db resources.DBResource) (*jobs.Queue, error) {
+	clk := clock.New()
+	ids, err := idgen.New(cfg.IDs.Strategy, clk)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := repository.NewIDCodec(cfg.IDs.Codecs["jobs"], ids)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID codec for jobs: %w", err)
+	}
+	return jobs.NewQueue(repository.NewJobRepository(db, clk, codec), clk, cfg.Jobs), nil
+}
+
+// list prints a page of dead jobs, most recently dead first
+func list(ctx context.Context, queue *jobs.Queue, filter repository.DeadJobFilter, limit int) error {
+	dead, total, err := queue.ListDead(ctx, filter, domain.ListOptions{Limit: limit})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tATTEMPTS\tDIED\tLAST ERROR")
+	for _, job := range dead {
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\t%s\n", job.ID, job.Type, job.Attempts, job.MaxAttempts,
+			job.UpdatedAt.Format(time.RFC3339), truncate(job.LastError, 80))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d of %d dead jobs\n", len(dead), total)
+	return nil
+}
+
+// show prints a dead job with its payload and error history
+func show(ctx context.Context, queue *jobs.Queue, id string) error {
+	job, err := queue.Get(ctx, id)
+	if err == repository.ErrNotFound || (err == nil && job.Status != repository.JobDead) {
+		return fmt.Errorf("dead job %s not found", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("ID:       %s\nType:     %s\nAttempts: %d/%d\nCreated:  %s\nDied:     %s\n",
+		job.ID, job.Type, job.Attempts, job.MaxAttempts, job.CreatedAt.Format(time.RFC3339), job.UpdatedAt.Format(time.RFC3339))
+
+	var payload interface{}
+	if err := json.Unmarshal(job.Payload, &payload); err == nil {
+		indented, _ := json.MarshalIndent(payload, "", "  ")
+		fmt.Printf("Payload:\n%s\n", indented)
+	} else {
+		fmt.Printf("Payload:  %q\n", job.Payload)
+	}
+
+	fmt.Println("Errors:")
+	for _, e := range job.Errors {
+		fmt.Printf("  #%d %s  %s\n", e.Attempt, e.At.Format(time.RFC3339), e.Error)
+	}
+	return nil
+}
+
+// replay makes dead jobs pending again, reporting those that are not dead
+func replay(ctx context.Context, queue *jobs.Queue, ids []string) error {
+	for _, id := range ids {
+		job, err := queue.Replay(ctx, id)
+		if err == repository.ErrNotFound {
+			fmt.Printf("%s: not a dead job\n", id)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to replay %s: %w", id, err)
+		}
+		fmt.Printf("%s: replayed %s\n", job.ID, job.Type)
+	}
+	return nil
+}
+
+// purge removes the dead jobs of ids, or those matching filter
+func purge(ctx context.Context, queue *jobs.Queue, filter repository.DeadJobFilter, ids []string) error {
+	if len(ids) == 0 {
+		removed, err := queue.Purge(ctx, filter)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purged %d dead jobs\n", removed)
+		return nil
+	}
+
+	for _, id := range ids {
+		removed, err := queue.Purge(ctx, repository.DeadJobFilter{ID: id})
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", id, err)
+		}
+		if removed == 0 {
+			fmt.Printf("%s: not a dead job\n", id)
+		} else {
+			fmt.Printf("%s: purged\n", id)
+		}
+	}
+	return nil
+}
+
+// parseTime parses an RFC 3339 timestamp or a date
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected an RFC 3339 timestamp or a date (YYYY-MM-DD)", value)
+}
+
+// truncate shortens s to n runes
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
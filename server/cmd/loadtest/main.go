@@ -0,0 +1,193 @@
+// Command loadtest drives a built-in scenario at a fixed request rate against a running server,
+// reports latency percentiles and error rates, and optionally fails when SLO thresholds are missed
+//
+//	go run ./cmd/loadtest -target http://localhost:8080 -scenario mixed -rps 200 -duration 1m -p99 300ms -max-error-rate 0.001
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/httpclient"
+)
+
+func main() {
+	target := flag.String("target", "http://localhost:8080", "base URL of the server under test")
+	scenarioName := flag.String("scenario", "ping", "scenario to run: "+strings.Join(scenarioNames(), ", "))
+	rps := flag.Float64("rps", 50, "requests per second to send")
+	duration := flag.Duration("duration", 30*time.Second, "how long to send requests for")
+	concurrency := flag.Int("concurrency", 64, "maximum requests in flight; requests due while all are busy are dropped")
+	seed := flag.Int("seed", 100, "number of users created before scenarios that read users")
+	cleanup := flag.Bool("cleanup", true, "delete the users created by the run when it ends")
+	authHeader := flag.String("auth", "", "Authorization header sent with every request")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout of each request")
+	jsonOutput := flag.Bool("json", false, "print the report as JSON")
+	list := flag.Bool("list", false, "list the built-in scenarios and exit")
+
+	var limits thresholds
+	flag.DurationVar(&limits.p50, "p50", 0, "fail when the p50 latency exceeds this (0 disables)")
+	flag.DurationVar(&limits.p95, "p95", 0, "fail when the p95 latency exceeds this (0 disables)")
+	flag.DurationVar(&limits.p99, "p99", 0, "fail when the p99 latency exceeds this (0 disables)")
+	flag.Float64Var(&limits.maxErrorRate, "max-error-rate", -1, "fail when the share of failed requests exceeds this, e.g. 0.01 (negative disables)")
+	flag.Float64Var(&limits.minRPSRatio, "min-rps-ratio", 0, "fail when the achieved rate is below this share of -rps, e.g. 0.95 (0 disables)")
+	flag.Parse()
+
+	if *list {
+		for _, name := range scenarioNames() {
+			fmt.Printf("%-12s %s\n", name, scenarios[name].description)
+		}
+		return
+	}
+
+	sc, ok := scenarios[*scenarioName]
+	if !ok {
+		log.Fatalf("Unknown scenario %q, expected one of %s", *scenarioName, strings.Join(scenarioNames(), ", "))
+	}
+	if *rps <= 0 || *concurrency <= 0 || *duration <= 0 {
+		log.Fatal("-rps, -concurrency and -duration must be positive")
+	}
+
+	// Exit last, once deferred cleanup has run
+	exitCode := 0
+	defer func() { os.Exit(exitCode) }()
+
+	// The client logs every request; keep only errors so the report stays readable
+	logger.Init("production")
+	logger.SetLevel(zapcore.ErrorLevel)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := httpclient.DefaultConfig(*target).
+		WithServiceName("loadtest").
+		WithRequestTimeout(*timeout).
+		WithRetryEnabled(false).
+		WithCircuitBreakerEnabled(false).
+		WithMaxIdleConns(*concurrency)
+	if *authHeader != "" {
+		cfg = cfg.WithDefaultHeader("Authorization", *authHeader)
+	}
+	client, err := httpclient.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	users := &userPool{runID: fmt.Sprintf("%x", time.Now().UnixNano())}
+	if *cleanup {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if failed := users.cleanup(ctx, client); failed > 0 {
+				log.Printf("Failed to delete %d users created by the run", failed)
+			}
+		}()
+	}
+	if sc.seed {
+		if *seed <= 0 {
+			log.Fatalf("Scenario %s needs -seed users", *scenarioName)
+		}
+		log.Printf("Seeding %d users", *seed)
+		if err := users.seed(ctx, client, *seed); err != nil {
+			log.Printf("Failed to seed users: %v", err)
+			exitCode = 1
+			return
+		}
+	}
+
+	log.Printf("Running %s at %.1f rps for %s against %s", *scenarioName, *rps, *duration, *target)
+	results, dropped, elapsed := run(ctx, client, sc, users, *rps, *duration, *concurrency)
+
+	report := &Report{
+		Scenario:  *scenarioName,
+		TargetRPS: *rps,
+		Duration:  elapsed.Round(time.Millisecond),
+		Dropped:   dropped,
+		Stats:     results.report(elapsed),
+	}
+	report.Violations = limits.check(report)
+
+	if *jsonOutput {
+		if err := writeJSON(os.Stdout, report); err != nil {
+			log.Printf("Failed to write report: %v", err)
+		}
+	} else {
+		writeText(os.Stdout, report)
+	}
+
+	if len(report.Violations) > 0 {
+		exitCode = 1
+	}
+}
+
+// run sends the scenario's requests at rps for duration with at most concurrency in flight
+// Requests are scheduled open-loop: a slow server does not slow the schedule down, and requests due
+// while every worker is busy are counted as dropped instead of being queued.
+func run(ctx context.Context, client *httpclient.Client, sc scenario, users *userPool, rps float64, duration time.Duration, concurrency int) (*recorder, int64, time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	results := newRecorder()
+	requests := make(chan request)
+	var dropped atomic.Int64
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range requests {
+				// Requests in flight when the run ends are allowed to finish
+				start := time.Now()
+				resp, err := send(context.WithoutCancel(ctx), client, req)
+				latency := time.Since(start)
+
+				status := 0
+				if resp != nil {
+					status = resp.StatusCode
+				}
+				failed := err != nil || httpclient.DefaultIsError(status)
+				results.record(req.name, latency, status, failed)
+
+				if !failed && req.name == "create user" {
+					users.record(resp)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+	defer ticker.Stop()
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	start := time.Now()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case requests <- sc.next(rng, users):
+			default:
+				dropped.Add(1)
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	close(requests)
+	wg.Wait()
+	return results, dropped.Load(), elapsed
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// total is the name of the report row aggregating all requests
+const total = "total"
+
+// recorder collects request outcomes by request name
+type recorder struct {
+	mu      sync.Mutex
+	results map[string]*results
+}
+
+// results are the outcomes of one kind of request
+type results struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{results: make(map[string]*results)}
+}
+
+// record adds the outcome of a request; status is 0 when no response was received
+func (r *recorder) record(name string, latency time.Duration, status int, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range []string{name, total} {
+		res, ok := r.results[key]
+		if !ok {
+			res = &results{statuses: make(map[int]int)}
+			r.results[key] = res
+		}
+		res.latencies = append(res.latencies, latency)
+		res.statuses[status]++
+		if failed {
+			res.errors++
+		}
+	}
+}
+
+// Stats summarizes the outcomes of one kind of request
+// Latencies are encoded by writeJSON, in milliseconds.
+type Stats struct {
+	Name      string         `json:"name"`
+	Requests  int            `json:"requests"`
+	Errors    int            `json:"errors"`
+	ErrorRate float64        `json:"errorRate"`
+	RPS       float64        `json:"rps"`
+	P50       time.Duration  `json:"-"`
+	P90       time.Duration  `json:"-"`
+	P95       time.Duration  `json:"-"`
+	P99       time.Duration  `json:"-"`
+	Max       time.Duration  `json:"-"`
+	Statuses  map[string]int `json:"statuses"`
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Scenario   string        `json:"scenario"`
+	TargetRPS  float64       `json:"targetRps"`
+	Duration   time.Duration `json:"-"`
+	Dropped    int64         `json:"dropped"`
+	Stats      []Stats       `json:"stats"`
+	Violations []string      `json:"violations,omitempty"`
+}
+
+// Total returns the row aggregating all requests
+func (r *Report) Total() Stats {
+	for _, s := range r.Stats {
+		if s.Name == total {
+			return s
+		}
+	}
+	return Stats{Name: total}
+}
+
+// report summarizes what was recorded over elapsed, with the total row last
+func (r *recorder) report(elapsed time.Duration) []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]Stats, 0, len(r.results))
+	for name, res := range r.results {
+		sorted := append([]time.Duration(nil), res.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		statuses := make(map[string]int, len(res.statuses))
+		for status, n := range res.statuses {
+			key := fmt.Sprint(status)
+			if status == 0 {
+				key = "none"
+			}
+			statuses[key] = n
+		}
+
+		s := Stats{
+			Name:     name,
+			Requests: len(sorted),
+			Errors:   res.errors,
+			P50:      percentile(sorted, 50),
+			P90:      percentile(sorted, 90),
+			P95:      percentile(sorted, 95),
+			P99:      percentile(sorted, 99),
+			Max:      percentile(sorted, 100),
+			Statuses: statuses,
+		}
+		if s.Requests > 0 {
+			s.ErrorRate = float64(s.Errors) / float64(s.Requests)
+		}
+		if elapsed > 0 {
+			s.RPS = float64(s.Requests) / elapsed.Seconds()
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if (stats[i].Name == total) != (stats[j].Name == total) {
+			return stats[j].Name == total
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// thresholds are the SLO assertions of a run; zero latencies and ratios and a negative error rate are not asserted
+type thresholds struct {
+	p50, p95, p99 time.Duration
+	maxErrorRate  float64
+	minRPSRatio   float64
+}
+
+// check returns the thresholds the total row of report violates
+func (t thresholds) check(report *Report) []string {
+	s := report.Total()
+
+	var violations []string
+	for _, c := range []struct {
+		name  string
+		limit time.Duration
+		got   time.Duration
+	}{
+		{"p50", t.p50, s.P50},
+		{"p95", t.p95, s.P95},
+		{"p99", t.p99, s.P99},
+	} {
+		if c.limit > 0 && c.got > c.limit {
+			violations = append(violations, fmt.Sprintf("%s latency %s exceeds %s", c.name, round(c.got), c.limit))
+		}
+	}
+
+	if t.maxErrorRate >= 0 && s.ErrorRate > t.maxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", s.ErrorRate*100, t.maxErrorRate*100))
+	}
+	if t.minRPSRatio > 0 && report.TargetRPS > 0 && s.RPS < t.minRPSRatio*report.TargetRPS {
+		violations = append(violations, fmt.Sprintf("achieved %.1f rps, below %.0f%% of the %.1f rps target",
+			s.RPS, t.minRPSRatio*100, report.TargetRPS))
+	}
+	return violations
+}
+
+// writeText prints the report as a table
+func writeText(w io.Writer, report *Report) {
+	fmt.Fprintf(w, "scenario %s, target %.1f rps for %s, %d dropped\n\n",
+		report.Scenario, report.TargetRPS, report.Duration, report.Dropped)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "request\trequests\trps\terrors\tp50\tp90\tp95\tp99\tmax\t")
+	for _, s := range report.Stats {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.2f%%\t%s\t%s\t%s\t%s\t%s\t\n",
+			s.Name, s.Requests, s.RPS, s.ErrorRate*100,
+			round(s.P50), round(s.P90), round(s.P95), round(s.P99), round(s.Max))
+	}
+	tw.Flush()
+
+	if len(report.Violations) > 0 {
+		fmt.Fprintln(w, "\nSLO violations:")
+		for _, v := range report.Violations {
+			fmt.Fprintf(w, "  %s\n", v)
+		}
+	}
+}
+
+// writeJSON prints the report as JSON with latencies in milliseconds
+func writeJSON(w io.Writer, report *Report) error {
+	type stats struct {
+		Stats
+		P50 float64 `json:"p50Ms"`
+		P90 float64 `json:"p90Ms"`
+		P95 float64 `json:"p95Ms"`
+		P99 float64 `json:"p99Ms"`
+		Max float64 `json:"maxMs"`
+	}
+	out := struct {
+		*Report
+		Duration string  `json:"duration"`
+		Stats    []stats `json:"stats"`
+	}{Report: report, Duration: report.Duration.String()}
+
+	for _, s := range report.Stats {
+		out.Stats = append(out.Stats, stats{
+			Stats: s,
+			P50:   ms(s.P50),
+			P90:   ms(s.P90),
+			P95:   ms(s.P95),
+			P99:   ms(s.P99),
+			Max:   ms(s.Max),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"quizizz.com/pkg/httpclient"
+)
+
+// request is a single call issued by a scenario; name groups it in the report
+type request struct {
+	name   string
+	method string
+	path   string
+	body   interface{}
+}
+
+// scenario is a built-in workload
+type scenario struct {
+	description string
+
+	// seed creates -seed users before the run for the scenario to read
+	seed bool
+
+	// next picks the request to send, given the users created so far
+	next func(rng *rand.Rand, users *userPool) request
+}
+
+// scenarios are the built-in workloads, selected with -scenario
+var scenarios = map[string]scenario{
+	"ping": {
+		description: "GET /api/v1/ping, measuring the stack without touching storage",
+		next: func(*rand.Rand, *userPool) request {
+			return request{name: "ping", method: http.MethodGet, path: "/api/v1/ping"}
+		},
+	},
+	"users-read": {
+		description: "90% GET /api/v1/users/:id and 10% filtered GET /api/v1/users over seeded users",
+		seed:        true,
+		next: func(rng *rand.Rand, users *userPool) request {
+			if rng.Float64() < 0.9 {
+				return getUser(users.pick(rng))
+			}
+			return listUsers(users.pick(rng))
+		},
+	},
+	"users-write": {
+		description: "POST /api/v1/users creating a new user per request",
+		next: func(rng *rand.Rand, users *userPool) request {
+			return createUser(users)
+		},
+	},
+	"mixed": {
+		description: "70% get, 20% filtered list and 10% create over seeded users",
+		seed:        true,
+		next: func(rng *rand.Rand, users *userPool) request {
+			switch p := rng.Float64(); {
+			case p < 0.7:
+				return getUser(users.pick(rng))
+			case p < 0.9:
+				return listUsers(users.pick(rng))
+			default:
+				return createUser(users)
+			}
+		},
+	},
+}
+
+// scenarioNames returns the names of the built-in scenarios, sorted
+func scenarioNames() []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getUser(u seededUser) request {
+	return request{name: "get user", method: http.MethodGet, path: "/api/v1/users/" + url.PathEscape(u.ID)}
+}
+
+func listUsers(u seededUser) request {
+	return request{name: "list users", method: http.MethodGet, path: "/api/v1/users?email=" + url.QueryEscape(u.Email)}
+}
+
+func createUser(users *userPool) request {
+	n := users.counter.Add(1)
+	return request{
+		name:   "create user",
+		method: http.MethodPost,
+		path:   "/api/v1/users",
+		body: map[string]string{
+			"name":  fmt.Sprintf("Load Test User %d", n),
+			"email": fmt.Sprintf("loadtest-%s-%d@example.com", users.runID, n),
+		},
+	}
+}
+
+// seededUser is a user created by the load test
+type seededUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// userPool tracks the users created during a run, so scenarios can read them and they can be
+// deleted afterwards
+type userPool struct {
+	runID   string
+	counter atomic.Int64
+
+	mu    sync.RWMutex
+	users []seededUser
+}
+
+// pick returns a random seeded user
+func (p *userPool) pick(rng *rand.Rand) seededUser {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.users[rng.Intn(len(p.users))]
+}
+
+// record remembers the user in a successful create response
+func (p *userPool) record(resp *httpclient.Response) {
+	var user seededUser
+	if json.Unmarshal(resp.Body, &user) != nil || user.ID == "" {
+		return
+	}
+	p.mu.Lock()
+	p.users = append(p.users, user)
+	p.mu.Unlock()
+}
+
+// seed creates n users through the API
+func (p *userPool) seed(ctx context.Context, client *httpclient.Client, n int) error {
+	for i := 0; i < n; i++ {
+		req := createUser(p)
+		resp, err := send(ctx, client, req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusCreated {
+			return fmt.Errorf("creating user returned %d: %s", resp.StatusCode, strings.TrimSpace(string(resp.Body)))
+		}
+		p.record(resp)
+	}
+	return nil
+}
+
+// cleanup deletes every user created during the run and returns the number of failed deletes
+func (p *userPool) cleanup(ctx context.Context, client *httpclient.Client) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	failed := 0
+	for _, user := range p.users {
+		resp, err := client.Request(ctx, http.MethodDelete, "/api/v1/users/"+url.PathEscape(user.ID), nil, httpclient.WithoutRetry())
+		if err != nil || (resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound) {
+			failed++
+		}
+	}
+	return failed
+}
+
+// send issues a request without retries, asking for unwrapped resources
+func send(ctx context.Context, client *httpclient.Client, req request) (*httpclient.Response, error) {
+	return client.Request(ctx, req.method, req.path, req.body,
+		httpclient.WithHeader("X-API-Envelope", "none"),
+		httpclient.WithoutRetry(),
+	)
+}
@@ -0,0 +1,204 @@
+// Command replay sends traffic captured by the capture middleware to another environment and reports
+// responses whose status (and optionally body) differ from the captured ones
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/capture"
+	"quizizz.com/pkg/httpclient"
+	"quizizz.com/pkg/redact"
+)
+
+// skippedHeaders are not replayed: they are redacted, set by the client, or describe the original connection
+var skippedHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Host":              true,
+	"Connection":        true,
+	"Accept-Encoding":   true,
+	"X-Request-Id":      true,
+	"X-Forwarded-For":   true,
+	"Traceparent":       true,
+	"Tracestate":        true,
+	"Transfer-Encoding": true,
+}
+
+// mismatch describes a replayed exchange whose response differed from the captured one
+type mismatch struct {
+	exchange *capture.Exchange
+	status   int
+	reason   string
+}
+
+func main() {
+	file := flag.String("file", "", "capture file (newline-delimited JSON) to replay")
+	target := flag.String("target", "", "base URL of the environment to replay against, e.g. https://staging.example.com")
+	rate := flag.Float64("rate", 10, "maximum requests per second (0 for unlimited)")
+	concurrency := flag.Int("concurrency", 4, "number of requests in flight")
+	unsafe := flag.Bool("unsafe", false, "also replay non-GET requests, which may modify the target's data")
+	compareBody := flag.Bool("compare-body", false, "compare JSON response bodies in addition to status codes")
+	ignore := flag.String("ignore", "meta,links", "comma-separated top-level JSON fields ignored when comparing bodies")
+	authHeader := flag.String("auth", "", "Authorization header sent with every request, since captured credentials are redacted")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of each request")
+	flag.Parse()
+
+	if *file == "" || *target == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger.Init("development")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := httpclient.DefaultConfig(*target).
+		WithServiceName("replay").
+		WithRequestTimeout(*timeout).
+		WithRetryEnabled(false).
+		WithCircuitBreakerEnabled(false)
+	client, err := httpclient.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	input, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open capture file: %v", err)
+	}
+	defer input.Close()
+
+	ignored := make(map[string]bool)
+	for _, field := range strings.Split(*ignore, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			ignored[field] = true
+		}
+	}
+
+	var (
+		mu         sync.Mutex
+		mismatches []mismatch
+		replayed   int
+		skipped    int
+		failed     int
+	)
+
+	var ticker *time.Ticker
+	if *rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / *rate))
+		defer ticker.Stop()
+	}
+
+	exchanges := make(chan *capture.Exchange)
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for exchange := range exchanges {
+				resp, err := replay(ctx, client, exchange, *authHeader)
+
+				mu.Lock()
+				replayed++
+				switch {
+				case err != nil:
+					failed++
+					log.Printf("%s %s: %v", exchange.Method, exchange.Path, err)
+				case resp.StatusCode != exchange.Status:
+					mismatches = append(mismatches, mismatch{exchange, resp.StatusCode, "status"})
+				case *compareBody && !sameJSON(exchange.ResponseBody, resp.Body, ignored):
+					mismatches = append(mismatches, mismatch{exchange, resp.StatusCode, "body"})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err = capture.Read(input, func(exchange *capture.Exchange) error {
+		if (!*unsafe && exchange.Method != http.MethodGet && exchange.Method != http.MethodHead) || exchange.Truncated {
+			skipped++
+			return nil
+		}
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case exchanges <- exchange:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(exchanges)
+	wg.Wait()
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Stopped reading captures: %v", err)
+	}
+
+	fmt.Printf("replayed %d, skipped %d, failed %d, mismatched %d\n", replayed, skipped, failed, len(mismatches))
+	for _, m := range mismatches {
+		fmt.Printf("  %s %s?%s: captured %d, got %d (%s differs, request %s)\n",
+			m.exchange.Method, m.exchange.Path, m.exchange.Query, m.exchange.Status, m.status, m.reason, m.exchange.RequestID)
+	}
+
+	if failed > 0 || len(mismatches) > 0 {
+		os.Exit(1)
+	}
+}
+
+// replay sends a captured request to the target
+func replay(ctx context.Context, client *httpclient.Client, exchange *capture.Exchange, authHeader string) (*httpclient.Response, error) {
+	headers := make(map[string]string)
+	for name, values := range exchange.Header {
+		if skippedHeaders[http.CanonicalHeaderKey(name)] || len(values) == 0 || values[0] == redact.Placeholder {
+			continue
+		}
+		headers[name] = values[0]
+	}
+	if authHeader != "" {
+		headers["Authorization"] = authHeader
+	}
+
+	path := exchange.Path
+	if exchange.Query != "" {
+		path += "?" + exchange.Query
+	}
+
+	var body interface{}
+	if exchange.Body != "" {
+		body = []byte(exchange.Body)
+	}
+	return client.Request(ctx, exchange.Method, path, body, httpclient.WithHeaders(headers), httpclient.WithoutRetry())
+}
+
+// sameJSON reports whether two JSON bodies are equal apart from ignored top-level fields
+// Bodies that are not JSON objects are compared byte for byte.
+func sameJSON(captured string, replayed []byte, ignored map[string]bool) bool {
+	var want, got map[string]interface{}
+	if json.Unmarshal([]byte(captured), &want) != nil || json.Unmarshal(replayed, &got) != nil {
+		return bytes.Equal([]byte(captured), replayed)
+	}
+	for field := range ignored {
+		delete(want, field)
+		delete(got, field)
+	}
+	return reflect.DeepEqual(want, got)
+}
@@ -0,0 +1,104 @@
+// Command search sets up the search index of users and schedules reindexing it
+//
+//	search setup
+//	search reindex
+//
+// setup puts the index template and creates the first index behind the users alias; the server
+// does the same when it starts. reindex enqueues a job the server's workers run: it fills a new
+// index from MongoDB, e.g. after the mappings changed, then swaps the alias to it.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/search"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", time.Minute, "timeout of the whole run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] setup | reindex\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "The cluster is configured by the SEARCH_* variables, MongoDB by the usual MONGODB_* variables.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.NewConfig()
+	logger.Init(cfg.Env)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var err error
+	switch flag.Arg(0) {
+	case "setup":
+		err = setup(ctx, cfg)
+	case "reindex":
+		err = reindex(ctx, cfg)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// setup puts the index template and creates the users index when missing
+func setup(ctx context.Context, cfg *config.Config) error {
+	cluster := resources.NewSearch(cfg)
+	if err := cluster.Connect(ctx); err != nil {
+		return err
+	}
+	defer cluster.Close(context.Background())
+
+	index := search.NewUsers(cluster, clock.New(), cfg.Search)
+	if err := index.Setup(ctx); err != nil {
+		return err
+	}
+	fmt.Printf("Index %s is set up\n", index.Alias())
+	return nil
+}
+
+// reindex enqueues the reindex job
+func reindex(ctx context.Context, cfg *config.Config) error {
+	db := resources.NewDB(cfg)
+	if err := db.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	defer db.Close(context.Background())
+
+	clk := clock.New()
+	ids, err := idgen.New(cfg.IDs.Strategy, clk)
+	if err != nil {
+		return err
+	}
+	codec, err := repository.NewIDCodec(cfg.IDs.Codecs["jobs"], ids)
+	if err != nil {
+		return fmt.Errorf("invalid ID codec for jobs: %w", err)
+	}
+	queue := jobs.NewQueue(repository.NewJobRepository(db, clk, codec), clk, cfg.Jobs)
+
+	job, err := queue.Enqueue(ctx, search.ReindexJobType, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Enqueued reindex job %s\n", job.ID)
+	return nil
+}
@@ -0,0 +1,113 @@
+// Command seed upserts the fixture files of a directory into MongoDB
+// Each file seeds the collection it is named after (users.json, quizzes.yaml); running it again
+// updates the same documents instead of duplicating them.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/seed"
+)
+
+// protectedEnvs are environments seeded only with -allow-production
+var protectedEnvs = map[string]bool{
+	"production": true,
+	"prod":       true,
+}
+
+func main() {
+	dir := flag.String("dir", "fixtures", "directory of fixture files (<collection>.json or <collection>.yaml)")
+	only := flag.String("only", "", "comma-separated collections to seed (default all files in -dir)")
+	keys := flag.String("keys", "", "comma-separated natural keys overriding the defaults, e.g. users=email,quizzes=code")
+	dryRun := flag.Bool("dry-run", false, "parse the fixtures and report what would be seeded without writing")
+	allowProduction := flag.Bool("allow-production", false, "allow seeding when ENV is production")
+	timeout := flag.Duration("timeout", time.Minute, "timeout of the whole run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n\nMongoDB is configured by the usual MONGODB_* variables.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cfg := config.NewConfig()
+	logger.Init(cfg.Env)
+
+	if protectedEnvs[strings.ToLower(cfg.Env)] && !*allowProduction {
+		log.Fatalf("Refusing to seed database %q in ENV=%s without -allow-production", cfg.MongoDB.Database, cfg.Env)
+	}
+
+	files, err := seed.Files(*dir, splitList(*only))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("No fixture files found in %s", *dir)
+	}
+
+	if *dryRun {
+		for _, collection := range seed.Collections(files) {
+			docs, err := seed.ReadFile(files[collection])
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s: %d documents from %s\n", collection, len(docs), files[collection])
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db := resources.NewDB(cfg)
+	if err := db.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer db.Close(context.Background())
+
+	keyOverrides, err := parseKeys(*keys)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Seeding database %q (ENV=%s)\n", cfg.MongoDB.Database, cfg.Env)
+	loader := seed.NewLoader(db.(*resources.DB).GetDatabase(), keyOverrides, time.Now)
+	for _, collection := range seed.Collections(files) {
+		result, err := loader.LoadFile(ctx, collection, files[collection])
+		if err != nil {
+			log.Fatalf("Failed to seed %s: %v", collection, err)
+		}
+		fmt.Printf("%s: %d inserted, %d updated, %d unchanged\n", collection, result.Inserted, result.Updated, result.Unchanged)
+	}
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseKeys parses collection=field pairs
+func parseKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range splitList(value) {
+		collection, field, ok := strings.Cut(pair, "=")
+		if !ok || collection == "" || field == "" {
+			return nil, fmt.Errorf("invalid key %q, expected collection=field", pair)
+		}
+		keys[strings.TrimSpace(collection)] = strings.TrimSpace(field)
+	}
+	return keys, nil
+}
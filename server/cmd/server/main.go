@@ -1,12 +1,10 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"log"
 
 	"quizizz.com/internal/config"
-	"quizizz.com/internal/resources"
 	"quizizz.com/wire"
 )
 
@@ -16,24 +14,9 @@ func main() {
 	// Initialize configuration
 	cfg := config.NewConfig()
 
-	// Create resources (not yet connected)
-	db := resources.NewDB(cfg)
-	redis := resources.NewRedis(cfg)
-	res := &resources.Resources{
-		DB:    db,
-		Redis: redis,
-	}
-
-	// Initialize resources BEFORE creating the app
-	// This ensures resources are connected when repositories are created
-	fmt.Println("Initializing resources...")
-	ctx := context.Background()
-	if err := resources.InitResources(ctx, res); err != nil {
-		log.Fatalf("Failed to initialize resources: %v", err)
-	}
-
-	// Now initialize the application with connected resources
-	app, err := wire.InitializeAppWithResources(cfg, res)
+	// Initialize the application; resources are connected before the repositories are created
+	fmt.Println("Initializing application...")
+	app, err := wire.InitializeApp(cfg, wire.Options{})
 	if err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
@@ -0,0 +1,125 @@
+// Command views lists and rebuilds the denormalized read models
+//
+//	views list
+//	views rebuild [view...]
+//
+// rebuild recomputes the named views, or every view, from their source collections, e.g. to
+// backfill a new view or after changes were missed. Views keep answering reads while rebuilt.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/views"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", time.Hour, "timeout of the whole run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] list | rebuild [view...]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "MongoDB is configured by the usual MONGODB_* variables.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	cfg := config.NewConfig()
+	logger.Init(cfg.Env)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	db := resources.NewDB(cfg)
+	if err := db.Connect(ctx); err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer db.Close(context.Background())
+
+	maintainer, err := newMaintainer(cfg, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch command {
+	case "list":
+		for _, name := range maintainer.Views() {
+			fmt.Println(name)
+		}
+	case "rebuild":
+		err = rebuild(ctx, maintainer, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newMaintainer creates the maintainer of the views, without following changes
+func newMaintainer(cfg *config.Config, db resources.DBResource) (*views.Maintainer, error) {
+	clk := clock.New()
+	ids, err := idgen.New(cfg.IDs.Strategy, clk)
+	if err != nil {
+		return nil, err
+	}
+	codec := func(collection string) (repository.IDCodec, error) {
+		codec, err := repository.NewIDCodec(cfg.IDs.Codecs[collection], ids)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID codec for %s: %w", collection, err)
+		}
+		return codec, nil
+	}
+
+	jobsCodec, err := codec("jobs")
+	if err != nil {
+		return nil, err
+	}
+	usersCodec, err := codec("users")
+	if err != nil {
+		return nil, err
+	}
+	summaries := repository.NewUserSummaryRepository(db, repository.CacheConfig{}, clk, usersCodec)
+	if syncer, ok := summaries.(repository.CollectionSyncer); ok {
+		if err := syncer.SyncCollection(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	queue := jobs.NewQueue(repository.NewJobRepository(db, clk, jobsCodec), clk, cfg.Jobs)
+	return views.NewMaintainer([]views.View{
+		views.NewUserSummaryView(summaries),
+	}, queue, nil, clk, cfg.Views)
+}
+
+// rebuild recomputes the named views, or every view, and prints how many documents they hold
+func rebuild(ctx context.Context, maintainer *views.Maintainer, names []string) error {
+	results, err := maintainer.Rebuild(ctx, names...)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VIEW\tDOCUMENTS\tELAPSED")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", result.View, result.Documents, result.Elapsed.Round(time.Millisecond))
+	}
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
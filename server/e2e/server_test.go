@@ -0,0 +1,193 @@
+//go:build e2e
+
+// Package e2e runs black-box smoke tests against the fully wired application
+// The app is built from the real wire graph, backed by MongoDB and Redis testcontainers, and
+// served on a random port; tests only talk to it over HTTP.
+package e2e
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/testutil/integration"
+	"quizizz.com/pkg/httpclient"
+	"quizizz.com/pkg/otel"
+	"quizizz.com/wire"
+)
+
+// Timeouts of the server lifecycle
+const (
+	startTimeout = 30 * time.Second
+	stopTimeout  = 15 * time.Second
+)
+
+// server is the application running in process for a test
+type server struct {
+	BaseURL   string
+	Client    *httpclient.Client
+	Collector *collector
+
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	done     chan error
+}
+
+// startServer boots the application on a random port and waits until it is ready
+// Traces are exported over OTLP HTTP to an in-process collector and metrics are served on the
+// metrics path. The server is stopped when the test ends unless Stop was called before.
+func startServer(t *testing.T) *server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	logger.Init("test")
+
+	collector := newCollector(t)
+
+	cfg := config.NewConfig()
+	cfg.Env = "test"
+	cfg.Port = freePort(t)
+	cfg.OTEL.Enabled = true
+	cfg.OTEL.TracesExporters = []string{otel.ExporterOTLPHTTP}
+	cfg.OTEL.TracingHTTPEndpoint = collector.Host()
+	cfg.OTEL.TracingExporterInsecure = true
+	cfg.OTEL.TracingSampleRatio = 1
+	cfg.OTEL.MetricsEnabled = true
+	cfg.OTEL.RuntimeMetrics = false
+
+	// Each run gets its own database on the shared containers, removed with them by the reaper
+	integration.UseContainers(t, cfg)
+
+	res := &resources.Resources{
+		DB:    resources.NewDB(cfg),
+		Redis: resources.NewRedis(cfg),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	require.NoError(t, resources.InitResources(ctx, res), "Failed to connect to containers")
+
+	app, err := wire.InitializeApp(cfg, wire.Options{Resources: res})
+	require.NoError(t, err, "Failed to initialize application")
+
+	runCtx, stop := context.WithCancel(context.Background())
+	s := &server{
+		BaseURL:   "http://127.0.0.1:" + cfg.Port,
+		Collector: collector,
+		cancel:    stop,
+		done:      make(chan error, 1),
+	}
+	go func() {
+		s.done <- app.RunContext(runCtx)
+	}()
+	t.Cleanup(func() { s.Stop(t) })
+
+	s.Client, err = httpclient.New(httpclient.DefaultConfig(s.BaseURL).
+		WithServiceName("e2e").
+		WithRetryEnabled(false).
+		WithCircuitBreakerEnabled(false))
+	require.NoError(t, err, "Failed to create HTTP client")
+	t.Cleanup(s.Client.Close)
+
+	s.waitReady(t)
+	return s
+}
+
+// waitReady polls the readiness endpoint until it succeeds, failing if the app exits first
+func (s *server) waitReady(t *testing.T) {
+	t.Helper()
+
+	deadline := time.Now().Add(startTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-s.done:
+			t.Fatalf("Server exited before becoming ready: %v", err)
+		default:
+		}
+
+		resp, err := s.Client.Get(context.Background(), "/readyz", nil)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("Server did not become ready within %s", startTimeout)
+}
+
+// Stop shuts the app down gracefully, which flushes pending spans to the collector
+func (s *server) Stop(t *testing.T) {
+	t.Helper()
+
+	s.stopOnce.Do(func() {
+		s.cancel()
+		select {
+		case err := <-s.done:
+			require.NoError(t, err, "Server did not shut down cleanly")
+		case <-time.After(stopTimeout):
+			t.Fatalf("Server did not shut down within %s", stopTimeout)
+		}
+	})
+}
+
+// freePort returns a TCP port that was free when asked for
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to find a free port")
+	defer listener.Close()
+
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// collector is a minimal OTLP HTTP receiver counting the trace exports it is sent
+type collector struct {
+	server  *httptest.Server
+	exports atomic.Int64
+	bytes   atomic.Int64
+}
+
+// newCollector starts a collector that is closed when the test ends
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		n, _ := io.Copy(io.Discard, r.Body)
+		c.exports.Add(1)
+		c.bytes.Add(n)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// Host returns the host:port of the collector, as expected by the OTLP HTTP exporter
+func (c *collector) Host() string {
+	return strings.TrimPrefix(c.server.URL, "http://")
+}
+
+// Exports returns the number of trace export requests received
+func (c *collector) Exports() int64 {
+	return c.exports.Load()
+}
+
+// Bytes returns the total size of the trace export payloads received
+func (c *collector) Bytes() int64 {
+	return c.bytes.Load()
+}
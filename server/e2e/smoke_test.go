@@ -0,0 +1,104 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/pkg/httpclient"
+)
+
+// user is the user resource as returned by the API without the response envelope
+type user struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// plain asks for raw resources instead of the success/data envelope
+var plain = map[string]string{"X-API-Envelope": "none"}
+
+func TestSmoke(t *testing.T) {
+	s := startServer(t)
+	ctx := context.Background()
+
+	t.Run("Health", func(t *testing.T) {
+		for _, path := range []string{"/livez", "/readyz", "/_meta/health"} {
+			resp, err := s.Client.Get(ctx, path, nil)
+			require.NoError(t, err, path)
+			assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		}
+	})
+
+	t.Run("User lifecycle", func(t *testing.T) {
+		email := fmt.Sprintf("e2e-%d@example.com", time.Now().UnixNano())
+
+		// Create
+		var created user
+		err := s.Client.PostJSON(ctx, "/api/v1/users", map[string]string{
+			"name":  "E2E User",
+			"email": email,
+		}, &created, plain)
+		require.NoError(t, err)
+		require.NotEmpty(t, created.ID)
+		assert.Equal(t, "E2E User", created.Name)
+		assert.Equal(t, email, created.Email)
+
+		// Read
+		var fetched user
+		require.NoError(t, s.Client.GetJSON(ctx, "/api/v1/users/"+created.ID, plain, &fetched))
+		assert.Equal(t, created, fetched)
+
+		// Update
+		var updated user
+		err = s.Client.PutJSON(ctx, "/api/v1/users/"+created.ID, map[string]string{
+			"name":  "E2E User Renamed",
+			"email": email,
+		}, &updated, plain)
+		require.NoError(t, err)
+		assert.Equal(t, "E2E User Renamed", updated.Name)
+
+		// List
+		var users []user
+		require.NoError(t, s.Client.GetJSON(ctx, "/api/v1/users?email="+email, plain, &users))
+		require.Len(t, users, 1)
+		assert.Equal(t, created.ID, users[0].ID)
+
+		// Delete
+		resp, err := s.Client.Delete(ctx, "/api/v1/users/"+created.ID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// Gone
+		err = s.Client.GetJSON(ctx, "/api/v1/users/"+created.ID, plain, &fetched)
+		var statusErr *httpclient.StatusError
+		require.True(t, errors.As(err, &statusErr), "expected a status error, got %v", err)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		resp, err := s.Client.Get(ctx, "/metrics", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		body := string(resp.Body)
+		assert.Contains(t, body, "target_info")
+		assert.Contains(t, body, "slo_requests")
+		assert.Contains(t, body, `slo="users_write"`)
+	})
+
+	// Traces are exported in batches; stopping the app flushes what is still pending
+	t.Run("Traces", func(t *testing.T) {
+		s.Stop(t)
+
+		assert.Positive(t, s.Collector.Exports(), "no trace exports reached the collector")
+		assert.Positive(t, s.Collector.Bytes(), "trace exports were empty")
+	})
+}
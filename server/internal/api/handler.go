@@ -5,10 +5,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"quizizz.com/internal/api/handlers"
 	"quizizz.com/internal/api/handlers/health"
-	"quizizz.com/internal/api/handlers/ping"
+	"quizizz.com/internal/api/handlers/pages"
 	"quizizz.com/internal/api/handlers/user"
 	"quizizz.com/internal/api/routes"
+	"quizizz.com/internal/healthcheck"
+	"quizizz.com/internal/module"
 	"quizizz.com/internal/service"
+	"quizizz.com/internal/web"
+	"quizizz.com/pkg/middleware"
 )
 
 // Version represents the API version
@@ -24,21 +28,56 @@ func (h *Handler) API() *routes.API {
 }
 
 // NewHandler creates a new Handler
-func NewHandler(appService service.AppService, userService service.UserService) *Handler {
+// responseCache may be nil to disable server-side response caching, checks to always report ready,
+// policies to apply no middleware policy to the route groups, modules to mount no module, and
+// renderer to serve no HTML page
+func NewHandler(
+	appService service.AppService,
+	userService service.UserService,
+	gdprService service.GDPRService,
+	sessionService service.SessionService,
+	preferencesService service.PreferencesService,
+	avatarService service.AvatarService,
+	notificationService service.NotificationService,
+	summaryService service.UserSummaryService,
+	responseCache *middleware.ResponseCache,
+	checks *healthcheck.Registry,
+	policies routes.Policies,
+	modules *module.Registry,
+	renderer *web.Renderer,
+) *Handler {
 	// Create base handler with common dependencies
 	baseHandler := handlers.NewBaseHandler(appService)
 
 	// Create specific handlers
-	healthHandler := health.NewHandler(baseHandler, Version)
-	pingHandler := ping.NewHandler(baseHandler)
+	healthHandler := health.NewHandler(baseHandler, Version, checks)
 	userHandler := user.NewHandler(baseHandler, userService)
+	gdprHandler := user.NewGDPRHandler(baseHandler, gdprService)
+	sessionHandler := user.NewSessionHandler(baseHandler, sessionService)
+	preferencesHandler := user.NewPreferencesHandler(baseHandler, preferencesService)
+	avatarHandler := user.NewAvatarHandler(baseHandler, avatarService)
+	deviceHandler := user.NewDeviceHandler(baseHandler, notificationService)
+	summaryHandler := user.NewSummaryHandler(baseHandler, summaryService)
+	var pagesHandler *pages.Handler
+	if renderer != nil {
+		pagesHandler = pages.NewHandler(baseHandler, renderer, Version, checks)
+	}
 
 	// Create API routes
 	api := routes.NewAPI(
 		baseHandler,
 		healthHandler,
-		pingHandler,
 		userHandler,
+		gdprHandler,
+		sessionHandler,
+		preferencesHandler,
+		avatarHandler,
+		deviceHandler,
+		summaryHandler,
+		pagesHandler,
+		modules,
+		responseCache,
+		policies,
 	)
 
 	return &Handler{
@@ -46,8 +85,23 @@ func NewHandler(appService service.AppService, userService service.UserService)
 	}
 }
 
+// Routes returns the metadata of the registered routes
+func (h *Handler) Routes() []routes.Route {
+	return h.api.Routes.Routes()
+}
+
 // RegisterRoutes registers all API routes
 func (h *Handler) RegisterRoutes(router *gin.Engine) {
 	// Register all routes from the API
 	h.api.RegisterRoutes(router)
 }
+
+// RegisterHealthRoutes registers only the health check routes
+func (h *Handler) RegisterHealthRoutes(router gin.IRouter) {
+	h.api.RegisterHealthRoutes(router)
+}
+
+// RegisterAPIRoutes registers all routes except the health checks, HTML pages included
+func (h *Handler) RegisterAPIRoutes(router *gin.Engine) {
+	h.api.RegisterAPIRoutes(router)
+}
@@ -0,0 +1,241 @@
+// Package admin serves the user management, stats, notification and dead job endpoints of administrators
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/auth/impersonation"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/errors"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/correlation"
+)
+
+// User represents a user as administrators see it
+type User struct {
+	ID                    string    `json:"id"`
+	Name                  string    `json:"name"`
+	Email                 string    `json:"email"`
+	Roles                 []string  `json:"roles"`
+	Locked                bool      `json:"locked"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// LockRequest is the optional body of a lock
+type LockRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// Handler handles admin requests
+type Handler struct {
+	*handlers.BaseHandler
+	adminService        service.AdminService
+	notificationService service.NotificationService
+	queue               *jobs.Queue
+}
+
+// NewHandler creates a new admin handler
+func NewHandler(
+	base *handlers.BaseHandler,
+	adminService service.AdminService,
+	notificationService service.NotificationService,
+	queue *jobs.Queue,
+) *Handler {
+	return &Handler{
+		BaseHandler:         base,
+		adminService:        adminService,
+		notificationService: notificationService,
+		queue:               queue,
+	}
+}
+
+// userListSpec whitelists the filters and sorts of the admin user list
+var userListSpec = handlers.ListSpec{
+	Filters: []string{"q", "name", "email", "createdAfter", "createdBefore", "role", "locked"},
+	Sorts:   domain.UserSortFields,
+}
+
+// ListUsers returns a page of users, e.g. ?role=support&locked=false&createdAfter=2024-01-01&sort=-created_at
+func (h *Handler) ListUsers(c *gin.Context) {
+	logger := h.GetRequestLogger(c)
+
+	query, err := h.GetListQuery(c, userListSpec)
+	if err != nil {
+		logger.Warn("Invalid list parameters", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	filter, err := parseUserFilter(query.Filters)
+	if err != nil {
+		logger.Warn("Invalid user filter", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	users, total, err := h.adminService.ListUsers(c.Request.Context(), filter, query.Options())
+	if err != nil {
+		logger.Error("Failed to list users", zap.Error(err))
+		response.InternalServerError(c, "Failed to list users")
+		return
+	}
+
+	response.Paginated(c, gin.H{
+		"users": toAPIUsers(users),
+		"count": len(users),
+	}, query.Page, query.Limit, total)
+}
+
+// ForcePasswordReset makes a user reset their password at their next sign-in, signing them out
+func (h *Handler) ForcePasswordReset(c *gin.Context) {
+	err := h.adminService.ForcePasswordReset(c.Request.Context(), actor(c), c.Param("id"))
+	h.respond(c, err, "Failed to force password reset")
+}
+
+// LockUser locks a user out, signing them out
+func (h *Handler) LockUser(c *gin.Context) {
+	var req LockRequest
+	if c.Request.ContentLength != 0 && !h.ShouldBindJSON(c, &req) {
+		return
+	}
+
+	err := h.adminService.Lock(c.Request.Context(), actor(c), c.Param("id"), req.Reason)
+	h.respond(c, err, "Failed to lock user")
+}
+
+// UnlockUser lifts the lock of a user
+func (h *Handler) UnlockUser(c *gin.Context) {
+	err := h.adminService.Unlock(c.Request.Context(), actor(c), c.Param("id"))
+	h.respond(c, err, "Failed to unlock user")
+}
+
+// Impersonate returns a short-lived token letting the current administrator act as a user
+// Clients send it as "Authorization: Impersonation <token>".
+func (h *Handler) Impersonate(c *gin.Context) {
+	token, claims, err := h.adminService.Impersonate(c.Request.Context(), actor(c), c.Param("id"))
+	if err != nil {
+		h.respond(c, err, "Failed to impersonate user")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Created(c, gin.H{
+		"token":      token,
+		"token_type": impersonation.Scheme,
+		"user_id":    claims.Subject,
+		"expires_at": claims.Expires(),
+	})
+}
+
+// GetStats returns the user counts, the signups per day and the newest users
+func (h *Handler) GetStats(c *gin.Context) {
+	stats, err := h.adminService.Stats(c.Request.Context())
+	if err != nil {
+		h.GetRequestLogger(c).Error("Failed to compute stats", zap.Error(err))
+		response.InternalServerError(c, "Failed to compute stats")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"total":          stats.Total,
+		"locked":         stats.Locked,
+		"signups_by_day": stats.SignupsByDay,
+		"recent_signups": toAPIUsers(stats.RecentSignups),
+	})
+}
+
+// respond responds to an operation on a user with 204, or with the error of err
+func (h *Handler) respond(c *gin.Context, err error, message string) {
+	logger := h.GetRequestLogger(c).With(zap.String("userId", c.Param("id")))
+	switch {
+	case err == nil:
+		response.NoContent(c)
+	case errors.Is(err, service.ErrUserNotFound):
+		logger.Warn("User not found")
+		response.NotFound(c, "User not found")
+	case errors.Is(err, service.ErrOwnAccount), errors.Is(err, service.ErrCannotImpersonate):
+		logger.Warn(message, zap.Error(err))
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, impersonation.ErrDisabled):
+		logger.Warn(message, zap.Error(err))
+		response.Fail(c, errors.HTTPError(http.StatusNotImplemented, "Impersonation is not configured"))
+	default:
+		logger.Error(message, zap.Error(err))
+		response.InternalServerError(c, message)
+	}
+}
+
+// actor returns the ID of the administrator making the request
+func actor(c *gin.Context) string {
+	return correlation.FromContext(c.Request.Context()).UserID
+}
+
+// parseUserFilter converts the whitelisted filter parameters into a user filter
+func parseUserFilter(params map[string]string) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		Query: params["q"],
+		Name:  params["name"],
+		Role:  params["role"],
+	}
+
+	var err error
+	if raw, ok := params["email"]; ok {
+		if filter.Email, err = domain.ParseEmail(raw); err != nil {
+			message := "Invalid email"
+			var invalid *domain.ValidationError
+			if errors.As(err, &invalid) {
+				message = invalid.Message
+			}
+			return filter, errors.BadRequest(message)
+		}
+	}
+	if raw, ok := params["createdAfter"]; ok {
+		if filter.CreatedAfter, err = handlers.ParseTimeParam("createdAfter", raw); err != nil {
+			return filter, err
+		}
+	}
+	if raw, ok := params["createdBefore"]; ok {
+		if filter.CreatedBefore, err = handlers.ParseTimeParam("createdBefore", raw); err != nil {
+			return filter, err
+		}
+	}
+	if raw, ok := params["locked"]; ok {
+		locked, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.BadRequest("locked must be true or false")
+		}
+		filter.Locked = &locked
+	}
+
+	return filter, nil
+}
+
+// toAPIUsers converts domain users to their admin view
+func toAPIUsers(domainUsers []*domain.User) []User {
+	users := make([]User, 0, len(domainUsers))
+	for _, u := range domainUsers {
+		roles := u.Roles
+		if roles == nil {
+			roles = []string{}
+		}
+		users = append(users, User{
+			ID:                    u.ID,
+			Name:                  u.Name.String(),
+			Email:                 u.Email.String(),
+			Roles:                 roles,
+			Locked:                u.Locked,
+			PasswordResetRequired: u.PasswordResetRequired,
+			CreatedAt:             u.CreatedAt,
+			UpdatedAt:             u.UpdatedAt,
+		})
+	}
+	return users
+}
@@ -0,0 +1,265 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+)
+
+const (
+	// defaultStatsWindow is the window of the job stats when none is requested
+	defaultStatsWindow = time.Hour
+
+	// maxStatsWindow bounds the window of the job stats, as succeeded jobs are only kept so long
+	maxStatsWindow = 7 * 24 * time.Hour
+)
+
+// JobStats reports the queue of a job type, or of every type for the totals
+type JobStats struct {
+	Type         string  `json:"type,omitempty"`
+	Pending      int64   `json:"pending"`
+	Scheduled    int64   `json:"scheduled"`
+	Running      int64   `json:"running"`
+	Dead         int64   `json:"dead"`
+	Succeeded    int64   `json:"succeeded"`
+	Failed       int64   `json:"failed"`
+	Throughput   float64 `json:"throughput_per_minute"`
+	FailureRatio float64 `json:"failure_ratio"`
+	Concurrency  int     `json:"concurrency,omitempty"`
+	InFlight     int     `json:"in_flight"`
+}
+
+// GetJobStats returns the depth of every job queue with its throughput and failure ratio over a
+// window, e.g. ?window=15m (default 1h, at most 7 days)
+func (h *Handler) GetJobStats(c *gin.Context) {
+	window := defaultStatsWindow
+	if raw := c.Query("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 || parsed > maxStatsWindow {
+			response.BadRequest(c, "window must be a positive duration of at most 168h, e.g. 15m")
+			return
+		}
+		window = parsed
+	}
+
+	stats, err := h.queue.Stats(c.Request.Context(), window)
+	if err != nil {
+		h.GetRequestLogger(c).Error("Failed to compute job stats", zap.Error(err))
+		response.InternalServerError(c, "Failed to compute job stats")
+		return
+	}
+
+	types := make([]JobStats, len(stats.Types))
+	for i, s := range stats.Types {
+		types[i] = toAPIJobStats(s)
+	}
+	response.Success(c, gin.H{
+		"window": stats.Window.String(),
+		"since":  stats.Since,
+		"types":  types,
+		"total":  toAPIJobStats(stats.Total),
+	})
+}
+
+// DeadJob is a dead-lettered job with its payload and the errors of its last attempts
+type DeadJob struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	Errors      []JobError      `json:"errors"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// JobError is the error of a failed attempt of a job
+type JobError struct {
+	Attempt int       `json:"attempt"`
+	Error   string    `json:"error"`
+	At      time.Time `json:"at"`
+}
+
+// deadJobListSpec whitelists the filters of the dead job list; it is always sorted by death, newest first
+var deadJobListSpec = handlers.ListSpec{
+	Filters: []string{"type", "before"},
+}
+
+// ListDeadJobs returns a page of dead-lettered jobs, e.g. ?type=avatar.process&before=2024-06-01
+func (h *Handler) ListDeadJobs(c *gin.Context) {
+	logger := h.GetRequestLogger(c)
+
+	query, err := h.GetListQuery(c, deadJobListSpec)
+	if err != nil {
+		logger.Warn("Invalid list parameters", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	filter, err := parseDeadJobFilter(query.Filters)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	dead, total, err := h.queue.ListDead(c.Request.Context(), filter, query.Options())
+	if err != nil {
+		logger.Error("Failed to list dead jobs", zap.Error(err))
+		response.InternalServerError(c, "Failed to list dead jobs")
+		return
+	}
+
+	apiJobs := make([]DeadJob, len(dead))
+	for i, job := range dead {
+		apiJobs[i] = toAPIDeadJob(job)
+	}
+	response.Paginated(c, gin.H{
+		"jobs":  apiJobs,
+		"count": len(apiJobs),
+	}, query.Page, query.Limit, total)
+}
+
+// GetDeadJob returns a dead-lettered job
+func (h *Handler) GetDeadJob(c *gin.Context) {
+	id := c.Param("jobId")
+	job, err := h.queue.Get(c.Request.Context(), id)
+	if err == repository.ErrNotFound || (err == nil && job.Status != repository.JobDead) {
+		response.NotFound(c, "Dead job not found")
+		return
+	}
+	if err != nil {
+		h.GetRequestLogger(c).Error("Failed to get dead job", zap.String("jobId", id), zap.Error(err))
+		response.InternalServerError(c, "Failed to get dead job")
+		return
+	}
+	response.Success(c, toAPIDeadJob(job))
+}
+
+// ReplayDeadJob makes a dead-lettered job pending again with a fresh set of attempts
+func (h *Handler) ReplayDeadJob(c *gin.Context) {
+	id := c.Param("jobId")
+	logger := h.GetRequestLogger(c).With(zap.String("jobId", id))
+
+	job, err := h.queue.Replay(c.Request.Context(), id)
+	if err == repository.ErrNotFound {
+		response.NotFound(c, "Dead job not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to replay dead job", zap.Error(err))
+		response.InternalServerError(c, "Failed to replay dead job")
+		return
+	}
+
+	logger.Info("Dead job replayed by administrator", zap.String("actor", actor(c)))
+	response.Success(c, toAPIDeadJob(job))
+}
+
+// DeleteDeadJob purges a dead-lettered job
+func (h *Handler) DeleteDeadJob(c *gin.Context) {
+	id := c.Param("jobId")
+	removed, err := h.queue.Purge(c.Request.Context(), repository.DeadJobFilter{ID: id})
+	if err != nil {
+		h.GetRequestLogger(c).Error("Failed to purge dead job", zap.String("jobId", id), zap.Error(err))
+		response.InternalServerError(c, "Failed to purge dead job")
+		return
+	}
+	if removed == 0 {
+		response.NotFound(c, "Dead job not found")
+		return
+	}
+	response.NoContent(c)
+}
+
+// PurgeDeadJobs purges the dead-lettered jobs of a type or that died before a time, e.g.
+// ?type=push.send&before=2024-06-01; at least one filter is required
+func (h *Handler) PurgeDeadJobs(c *gin.Context) {
+	logger := h.GetRequestLogger(c)
+
+	query, err := h.GetListQuery(c, deadJobListSpec)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if len(query.Filters) == 0 {
+		response.BadRequest(c, "Purging dead jobs requires a type or before filter")
+		return
+	}
+	filter, err := parseDeadJobFilter(query.Filters)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	removed, err := h.queue.Purge(c.Request.Context(), filter)
+	if err != nil {
+		logger.Error("Failed to purge dead jobs", zap.Error(err))
+		response.InternalServerError(c, "Failed to purge dead jobs")
+		return
+	}
+
+	logger.Info("Dead jobs purged by administrator", zap.String("actor", actor(c)), zap.Int64("removed", removed))
+	response.Success(c, gin.H{"removed": removed})
+}
+
+// parseDeadJobFilter converts the whitelisted filter parameters into a dead job filter
+func parseDeadJobFilter(params map[string]string) (repository.DeadJobFilter, error) {
+	filter := repository.DeadJobFilter{Type: params["type"]}
+	if raw, ok := params["before"]; ok {
+		before, err := handlers.ParseTimeParam("before", raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.Before = before
+	}
+	return filter, nil
+}
+
+// toAPIDeadJob converts a job to its API form
+func toAPIDeadJob(job *repository.Job) DeadJob {
+	errors := make([]JobError, len(job.Errors))
+	for i, e := range job.Errors {
+		errors[i] = JobError{Attempt: e.Attempt, Error: e.Error, At: e.At}
+	}
+
+	var payload json.RawMessage
+	if json.Valid(job.Payload) {
+		payload = job.Payload
+	}
+	return DeadJob{
+		ID:          job.ID,
+		Type:        job.Type,
+		Payload:     payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		Errors:      errors,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
+
+// toAPIJobStats converts the stats of a job queue to their API form
+func toAPIJobStats(s jobs.TypeStats) JobStats {
+	return JobStats{
+		Type:         s.Type,
+		Pending:      s.Pending,
+		Scheduled:    s.Scheduled,
+		Running:      s.Running,
+		Dead:         s.Dead,
+		Succeeded:    s.Succeeded,
+		Failed:       s.Failed,
+		Throughput:   s.Throughput,
+		FailureRatio: s.FailureRatio,
+		Concurrency:  s.Concurrency,
+		InFlight:     s.InFlight,
+	}
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"quizizz.com/internal/auth/impersonation"
+	"quizizz.com/internal/auth/rbac"
+	"quizizz.com/internal/module"
+	"quizizz.com/internal/repository"
+)
+
+// ProviderSet provides the admin module
+var ProviderSet = wire.NewSet(
+	NewHandler,
+	NewModule,
+)
+
+// Module serves the admin endpoints and makes requests with impersonation tokens on behalf of their user
+// Each route requires a permission of the current user's roles; the admin route policy can add
+// authentication and a stricter rate limit on top.
+type Module struct {
+	module.Base
+	handler       *Handler
+	authorizer    *rbac.Authorizer
+	impersonation gin.HandlerFunc
+}
+
+// NewModule creates the admin module
+func NewModule(handler *Handler, authorizer *rbac.Authorizer, tokens *impersonation.Tokens, audit repository.AuditRepository) *Module {
+	return &Module{
+		handler:       handler,
+		authorizer:    authorizer,
+		impersonation: impersonation.Middleware(tokens, audit),
+	}
+}
+
+// Name returns the module name, which is also its path
+func (m *Module) Name() string {
+	return "admin"
+}
+
+// Routes registers the user management routes under /admin/users, GET /admin/stats, the push
+// notification routes, GET /admin/jobs/stats and the dead job routes under /admin/jobs/dead
+func (m *Module) Routes(r gin.IRouter) {
+	read := m.authorizer.Require(rbac.PermUsersRead)
+	manage := m.authorizer.Require(rbac.PermUsersManage)
+
+	r.GET("/users", read, m.handler.ListUsers)
+	r.POST("/users/:id/password-reset", manage, m.handler.ForcePasswordReset)
+	r.POST("/users/:id/lock", manage, m.handler.LockUser)
+	r.DELETE("/users/:id/lock", manage, m.handler.UnlockUser)
+	r.POST("/users/:id/impersonate", m.authorizer.Require(rbac.PermUsersImpersonate), m.handler.Impersonate)
+	r.GET("/stats", m.authorizer.Require(rbac.PermStatsRead), m.handler.GetStats)
+
+	notify := m.authorizer.Require(rbac.PermNotificationsSend)
+	r.POST("/users/:id/notifications", notify, m.handler.NotifyUser)
+	r.POST("/topics/:topic/notifications", notify, m.handler.NotifyTopic)
+	r.GET("/notifications/:notificationId", notify, m.handler.GetNotification)
+
+	r.GET("/jobs/stats", m.authorizer.Require(rbac.PermStatsRead), m.handler.GetJobStats)
+	manageJobs := m.authorizer.Require(rbac.PermJobsManage)
+	dead := r.Group("/jobs/dead", manageJobs)
+	dead.GET("", m.handler.ListDeadJobs)
+	dead.DELETE("", m.handler.PurgeDeadJobs)
+	dead.GET("/:jobId", m.handler.GetDeadJob)
+	dead.POST("/:jobId/replay", m.handler.ReplayDeadJob)
+	dead.DELETE("/:jobId", m.handler.DeleteDeadJob)
+}
+
+// Middleware applies impersonation tokens to every request, ahead of authentication policies and RBAC
+func (m *Module) Middleware() []gin.HandlerFunc {
+	return []gin.HandlerFunc{m.impersonation}
+}
+
+// Providers returns ProviderSet
+func (m *Module) Providers() wire.ProviderSet {
+	return ProviderSet
+}
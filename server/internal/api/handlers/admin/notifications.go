@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/errors"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+)
+
+// NotificationRequest is the body of a push notification sent by an administrator
+type NotificationRequest struct {
+	Title       string            `json:"title"`
+	Body        string            `json:"body"`
+	Data        map[string]string `json:"data"`
+	CollapseKey string            `json:"collapse_key"`
+}
+
+// Notification is a sent push notification; its ID is that of the job delivering it
+type Notification struct {
+	ID        string           `json:"notification_id"`
+	Status    string           `json:"status"`
+	Receipts  map[string]int64 `json:"receipts,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// NotifyUser sends a push notification to the devices of a user
+// The notification is delivered in the background; its status is at GET /admin/notifications/:id.
+func (h *Handler) NotifyUser(c *gin.Context) {
+	notification, ok := h.bindNotification(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.notificationService.NotifyUser(c.Request.Context(), c.Param("id"), notification)
+	h.respondNotification(c, job, err)
+}
+
+// NotifyTopic sends a push notification to the devices subscribed to a topic
+func (h *Handler) NotifyTopic(c *gin.Context) {
+	notification, ok := h.bindNotification(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.notificationService.NotifyTopic(c.Request.Context(), c.Param("topic"), notification)
+	h.respondNotification(c, job, err)
+}
+
+// GetNotification returns the status of a notification and its receipts counted by status
+func (h *Handler) GetNotification(c *gin.Context) {
+	id := c.Param("notificationId")
+	job, receipts, err := h.notificationService.Status(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			response.NotFound(c, "Notification not found")
+			return
+		}
+		h.GetRequestLogger(c).Error("Failed to get notification", zap.String("notificationId", id), zap.Error(err))
+		response.InternalServerError(c, "Failed to get notification")
+		return
+	}
+
+	notification := toAPINotification(job)
+	notification.Receipts = receipts
+	response.Success(c, notification)
+}
+
+// bindNotification binds the body of a send into a notification
+func (h *Handler) bindNotification(c *gin.Context) (*domain.Notification, bool) {
+	var req NotificationRequest
+	if !h.ShouldBindJSON(c, &req) {
+		return nil, false
+	}
+	return &domain.Notification{
+		Title:       req.Title,
+		Body:        req.Body,
+		Data:        req.Data,
+		CollapseKey: req.CollapseKey,
+	}, true
+}
+
+// respondNotification responds to a send with 202 and the notification, or with the error of err
+func (h *Handler) respondNotification(c *gin.Context, job *repository.Job, err error) {
+	logger := h.GetRequestLogger(c)
+	switch {
+	case err == nil:
+		logger.Info("Push notification enqueued", zap.String("notificationId", job.ID), zap.String("actor", actor(c)))
+		response.Accepted(c, toAPINotification(job))
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, "User not found")
+	case errors.Is(err, domain.ErrInvalidNotification), errors.Is(err, domain.ErrInvalidDevice):
+		logger.Warn("Invalid notification", zap.Error(err))
+		response.BadRequest(c, err.Error())
+	default:
+		logger.Error("Failed to send notification", zap.Error(err))
+		response.InternalServerError(c, "Failed to send notification")
+	}
+}
+
+// toAPINotification converts the job delivering a notification to its API form
+func toAPINotification(job *repository.Job) Notification {
+	return Notification{
+		ID:        job.ID,
+		Status:    job.Status,
+		Error:     job.LastError,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
@@ -2,9 +2,15 @@
 package handlers
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/errors"
 	"quizizz.com/internal/service"
+	"quizizz.com/pkg/correlation"
 )
 
 // BaseHandler contains common dependencies and utilities for handlers
@@ -25,13 +31,22 @@ func NewBaseHandler(appService service.AppService) *BaseHandler {
 	}
 }
 
-// ShouldBindJSON wraps gin's binding with error handling
+// ShouldBindJSON binds the JSON request body to obj, responding with the error when it fails
+// Bodies cut short by a BodyLimit middleware get 413 Payload Too Large, other failures 400.
 func (h *BaseHandler) ShouldBindJSON(c *gin.Context, obj interface{}) bool {
-	if err := c.ShouldBindJSON(obj); err != nil {
-		c.Error(err)
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+	c.Error(err)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		response.Fail(c, errors.PayloadTooLarge(maxBytesErr.Limit))
 		return false
 	}
-	return true
+	response.BadRequest(c, "Invalid request body")
+	return false
 }
 
 // GetRequestLogger returns a logger with request context
@@ -42,9 +57,72 @@ func (h *BaseHandler) GetRequestLogger(c *gin.Context) *zap.Logger {
 		requestID = "unknown"
 	}
 
-	return zap.L().With(
+	fields := []zap.Field{
 		zap.String("requestID", requestID.(string)),
 		zap.String("method", c.Request.Method),
 		zap.String("path", c.Request.URL.Path),
-	)
+	}
+	if correlationID := c.GetString("correlationID"); correlationID != "" && correlationID != requestID {
+		fields = append(fields, zap.String("correlationID", correlationID))
+	}
+	if ids := correlation.FromContext(c.Request.Context()); ids.Impersonated() {
+		fields = append(fields, zap.String("userID", ids.UserID), zap.String("actorID", ids.ActorID))
+	}
+
+	return zap.L().With(fields...)
+}
+
+// GetFields parses the sparse fieldset query parameter (e.g. ?fields=name,email)
+// Only fields present in allowed are accepted; an empty result means all fields
+func (h *BaseHandler) GetFields(c *gin.Context, allowed ...string) ([]string, error) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	allowedSet := make(map[string]struct{}, len(allowed))
+	for _, field := range allowed {
+		allowedSet[field] = struct{}{}
+	}
+
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := allowedSet[field]; !ok {
+			err := &errors.AppError{
+				StatusCode: http.StatusBadRequest,
+				Message:    "Unknown field: " + field,
+				Original:   errors.ErrBadRequest,
+			}
+			return nil, err.WithContext("field", field)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// GetIfMatchVersion parses the If-Match header into the document version the client expects
+// ok is false when the header is absent or "*"; an ETag that is not a single version ETag
+// can never match, so it yields a 412 error
+func (h *BaseHandler) GetIfMatchVersion(c *gin.Context) (version int64, ok bool, err error) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, false, nil
+	}
+
+	version, ok = response.ParseVersionETag(raw)
+	if !ok {
+		appErr := &errors.AppError{
+			StatusCode: http.StatusPreconditionFailed,
+			Message:    "If-Match must be a single ETag returned by this API",
+			Original:   errors.ErrPreconditionFailed,
+		}
+		return 0, false, appErr.WithContext("header", "If-Match")
+	}
+
+	return version, true, nil
 }
@@ -0,0 +1,63 @@
+// Package csrf issues the CSRF tokens browser clients authenticated by cookie send on unsafe requests
+package csrf
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/middleware"
+)
+
+// Handler handles CSRF token requests
+type Handler struct {
+	*handlers.BaseHandler
+	cfg middleware.CSRFConfig
+}
+
+// NewHandler creates a new CSRF handler with the cookie settings of the configuration
+func NewHandler(base *handlers.BaseHandler, cfg *config.Config) *Handler {
+	return &Handler{
+		BaseHandler: base,
+		cfg: middleware.CSRFConfig{
+			SessionCookie: cfg.CSRF.SessionCookie,
+			CookieName:    cfg.CSRF.CookieName,
+			HeaderName:    cfg.CSRF.HeaderName,
+			SameSite:      sameSite(cfg.CSRF.SameSite),
+			Secure:        cfg.CSRF.Secure,
+			MaxAge:        cfg.CSRF.TokenTTL,
+		},
+	}
+}
+
+// Token returns the client's CSRF token, setting the token cookie when the client has none
+func (h *Handler) Token(c *gin.Context) {
+	token, err := middleware.IssueCSRFToken(c, h.cfg)
+	if err != nil {
+		h.GetRequestLogger(c).Error("Failed to issue CSRF token", zap.Error(err))
+		response.InternalError(c, "Failed to issue CSRF token")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, gin.H{
+		"token":  token,
+		"header": h.cfg.HeaderName,
+	})
+}
+
+// sameSite parses a SameSite cookie attribute, defaulting to Lax
+func sameSite(name string) http.SameSite {
+	switch strings.ToLower(name) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
@@ -0,0 +1,52 @@
+package csrf
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/module"
+	"quizizz.com/pkg/middleware"
+)
+
+// ProviderSet provides the CSRF module
+var ProviderSet = wire.NewSet(
+	NewHandler,
+	NewModule,
+)
+
+// Module issues CSRF tokens and checks them on every unsafe cookie-authenticated request
+type Module struct {
+	module.Base
+	handler *Handler
+	enabled bool
+}
+
+// NewModule creates the CSRF module, which does nothing unless CSRF protection is enabled
+func NewModule(handler *Handler, cfg *config.Config) *Module {
+	return &Module{handler: handler, enabled: cfg.CSRF.Enabled}
+}
+
+// Name returns the module name, which is also its path
+func (m *Module) Name() string {
+	return "csrf"
+}
+
+// Routes registers GET /csrf, issuing the client's token
+func (m *Module) Routes(r gin.IRouter) {
+	if m.enabled {
+		r.GET("", m.handler.Token)
+	}
+}
+
+// Middleware returns the CSRF check applied to every request
+func (m *Module) Middleware() []gin.HandlerFunc {
+	if !m.enabled {
+		return nil
+	}
+	return []gin.HandlerFunc{middleware.CSRF(m.handler.cfg)}
+}
+
+// Providers returns ProviderSet
+func (m *Module) Providers() wire.ProviderSet {
+	return ProviderSet
+}
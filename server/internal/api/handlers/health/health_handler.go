@@ -2,22 +2,29 @@
 package health
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"quizizz.com/internal/api/handlers"
 	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/errors"
+	"quizizz.com/internal/healthcheck"
 )
 
 // Handler handles health check requests
 type Handler struct {
 	*handlers.BaseHandler
 	version string
+	checks  *healthcheck.Registry
 }
 
 // NewHandler creates a new health handler
-func NewHandler(base *handlers.BaseHandler, version string) *Handler {
+// checks may be nil, in which case the service always reports ready.
+func NewHandler(base *handlers.BaseHandler, version string, checks *healthcheck.Registry) *Handler {
 	return &Handler{
 		BaseHandler: base,
 		version:     version,
+		checks:      checks,
 	}
 }
 
@@ -39,11 +46,41 @@ func (h *Handler) LivenessCheck(c *gin.Context) {
 	})
 }
 
-// ReadinessCheck handles Kubernetes readiness probe
+// ReadinessCheck handles Kubernetes readiness probe, failing with 503 while any check fails
 func (h *Handler) ReadinessCheck(c *gin.Context) {
-	// Here you might check database connections, cache availability, etc.
-	// For simplicity, we're just returning success
+	if h.checks == nil {
+		response.Success(c, gin.H{
+			"status": "ready",
+		})
+		return
+	}
+
+	report := h.checks.Run(c.Request.Context())
+	if !report.Healthy {
+		h.GetRequestLogger(c).Warn("Readiness check failed")
+		err := &errors.AppError{
+			StatusCode: http.StatusServiceUnavailable,
+			Message:    "Service is not ready",
+			Original:   errors.ErrServiceUnavailable,
+		}
+		response.Fail(c, err.WithContext("checks", report.Checks))
+		return
+	}
+
 	response.Success(c, gin.H{
 		"status": "ready",
+		"checks": report.Checks,
 	})
 }
+
+// StatusCheck returns the recent health of each dependency: the history of its checks, whether it
+// has an incident and its uptime since the process started
+// It responds 200 whatever the status, so it can back a public status page; it responds 404 when no
+// history is kept.
+func (h *Handler) StatusCheck(c *gin.Context) {
+	if h.checks == nil || h.checks.History() == nil {
+		response.NotFound(c, "Status history is disabled")
+		return
+	}
+	response.Success(c, h.checks.History().Status())
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/errors"
+)
+
+// Pagination defaults of list endpoints
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// listParams are the query parameters every list accepts besides its filters
+var listParams = []string{"fields", "page", "limit", "sort"}
+
+// ListSpec declares the query parameters a list endpoint accepts
+type ListSpec struct {
+	// Filters are the names of the accepted filter parameters; any other parameter is rejected
+	Filters []string
+
+	// Sorts are the fields the list can be sorted by, with ?sort=field or ?sort=-field for descending
+	Sorts []string
+}
+
+// ListQuery is a list request parsed against a ListSpec
+type ListQuery struct {
+	// Filters holds the non-empty filter parameters by name
+	Filters map[string]string
+
+	Sort       string
+	Descending bool
+	Page       int
+	Limit      int
+
+	// Paged is set when the request has any filter, sort or pagination parameter
+	Paged bool
+}
+
+// Options returns the list options selecting the requested page
+func (q *ListQuery) Options() domain.ListOptions {
+	return domain.ListOptions{
+		Sort:       q.Sort,
+		Descending: q.Descending,
+		Offset:     (q.Page - 1) * q.Limit,
+		Limit:      q.Limit,
+	}
+}
+
+// GetListQuery parses the filter, sort and pagination query parameters of a list request
+// Parameters outside spec are rejected rather than ignored, so a misspelled filter does not
+// silently return the unfiltered list.
+func (h *BaseHandler) GetListQuery(c *gin.Context, spec ListSpec) (*ListQuery, error) {
+	query := &ListQuery{
+		Filters: make(map[string]string),
+		Page:    1,
+		Limit:   DefaultListLimit,
+	}
+
+	for param, values := range c.Request.URL.Query() {
+		if !slices.Contains(spec.Filters, param) && !slices.Contains(listParams, param) {
+			return nil, invalidParam(param, "Unknown query parameter: "+param)
+		}
+		if param != "fields" {
+			query.Paged = true
+		}
+		if slices.Contains(spec.Filters, param) && values[0] != "" {
+			query.Filters[param] = values[0]
+		}
+	}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return nil, invalidParam("page", "page must be a positive integer")
+		}
+		query.Page = page
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxListLimit {
+			return nil, invalidParam("limit", "limit must be between 1 and "+strconv.Itoa(MaxListLimit))
+		}
+		query.Limit = limit
+	}
+
+	if raw := c.Query("sort"); raw != "" {
+		field, descending := strings.CutPrefix(raw, "-")
+		if !slices.Contains(spec.Sorts, field) {
+			return nil, invalidParam("sort", "Cannot sort by "+field)
+		}
+		query.Sort = field
+		query.Descending = descending
+	}
+
+	return query, nil
+}
+
+// ParseTimeParam parses the value of a time query parameter, an RFC 3339 timestamp or a date
+func ParseTimeParam(param, value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, invalidParam(param, param+" must be an RFC 3339 timestamp or a date (YYYY-MM-DD)")
+}
+
+// invalidParam returns the 400 error reporting an invalid query parameter
+func invalidParam(param, message string) error {
+	err := &errors.AppError{
+		StatusCode: http.StatusBadRequest,
+		Message:    message,
+		Original:   errors.ErrBadRequest,
+	}
+	return err.WithContext("param", param)
+}
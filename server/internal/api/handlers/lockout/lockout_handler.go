@@ -0,0 +1,69 @@
+// Package lockout lets operators inspect and lift the login lockouts of accounts and client IPs
+package lockout
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/auth/throttle"
+	"quizizz.com/pkg/correlation"
+)
+
+// Handler handles lockout requests
+type Handler struct {
+	*handlers.BaseHandler
+	throttle *throttle.Throttle
+}
+
+// NewHandler creates a new lockout handler
+func NewHandler(base *handlers.BaseHandler, throttle *throttle.Throttle) *Handler {
+	return &Handler{
+		BaseHandler: base,
+		throttle:    throttle,
+	}
+}
+
+// GetStatus returns the failed attempts and lockout of an account or IP
+func (h *Handler) GetStatus(c *gin.Context) {
+	kind, key := throttle.Kind(c.Param("kind")), c.Param("key")
+
+	status, err := h.throttle.Status(c.Request.Context(), kind, key)
+	if err != nil {
+		h.fail(c, "Failed to get lockout status", err)
+		return
+	}
+	response.Success(c, gin.H{
+		"kind":             status.Kind,
+		"key":              status.Key,
+		"failures":         status.Failures,
+		"lockouts":         status.Lockouts,
+		"locked":           status.LockedFor > 0,
+		"lockedForSeconds": int64(status.LockedFor.Round(time.Second).Seconds()),
+	})
+}
+
+// Unlock lifts the lockout of an account or IP and clears its failed attempts
+func (h *Handler) Unlock(c *gin.Context) {
+	kind, key := throttle.Kind(c.Param("kind")), c.Param("key")
+	actor := correlation.FromContext(c.Request.Context()).UserID
+
+	if err := h.throttle.Unlock(c.Request.Context(), kind, key, actor); err != nil {
+		h.fail(c, "Failed to unlock", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// fail responds with the error of a throttle call
+func (h *Handler) fail(c *gin.Context, message string, err error) {
+	if errors.Is(err, throttle.ErrInvalidKind) {
+		response.BadRequest(c, "Kind must be account or ip")
+		return
+	}
+	h.GetRequestLogger(c).Error(message, zap.Error(err))
+	response.InternalError(c, message)
+}
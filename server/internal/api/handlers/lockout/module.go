@@ -0,0 +1,41 @@
+package lockout
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"quizizz.com/internal/module"
+)
+
+// ProviderSet provides the lockout module
+var ProviderSet = wire.NewSet(
+	NewHandler,
+	NewModule,
+)
+
+// Module serves the lockout endpoints
+// They lift security controls, so deployments should require authentication through the lockouts route policy.
+type Module struct {
+	module.Base
+	handler *Handler
+}
+
+// NewModule creates the lockout module
+func NewModule(handler *Handler) *Module {
+	return &Module{handler: handler}
+}
+
+// Name returns the module name, which is also its path
+func (m *Module) Name() string {
+	return "lockouts"
+}
+
+// Routes registers GET and DELETE /lockouts/:kind/:key, where kind is account or ip
+func (m *Module) Routes(r gin.IRouter) {
+	r.GET("/:kind/:key", m.handler.GetStatus)
+	r.DELETE("/:kind/:key", m.handler.Unlock)
+}
+
+// Providers returns ProviderSet
+func (m *Module) Providers() wire.ProviderSet {
+	return ProviderSet
+}
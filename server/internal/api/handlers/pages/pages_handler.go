@@ -0,0 +1,117 @@
+// Package pages serves the server-rendered HTML pages
+package pages
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/healthcheck"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/web"
+)
+
+// htmlContentType is the content type of rendered pages
+const htmlContentType = "text/html; charset=utf-8"
+
+// StatusPage is the data of the status page
+type StatusPage struct {
+	Healthy   bool
+	Version   string
+	CheckedAt time.Time
+	Checks    []resources.HealthCheck
+
+	// Uptime and Incidents hold the share of successful checks and the incident flags of each
+	// dependency, by name, when the health history is kept
+	Uptime    map[string]float64
+	Incidents map[string]bool
+}
+
+// Handler handles page requests
+type Handler struct {
+	*handlers.BaseHandler
+	renderer *web.Renderer
+	version  string
+	checks   *healthcheck.Registry
+}
+
+// NewHandler creates a new pages handler
+// checks may be nil, in which case the status page lists no dependency.
+func NewHandler(base *handlers.BaseHandler, renderer *web.Renderer, version string, checks *healthcheck.Registry) *Handler {
+	return &Handler{
+		BaseHandler: base,
+		renderer:    renderer,
+		version:     version,
+		checks:      checks,
+	}
+}
+
+// Status renders the status page from the readiness checks, responding 503 while any check fails
+func (h *Handler) Status(c *gin.Context) {
+	page := StatusPage{Healthy: true, Version: h.version, CheckedAt: time.Now()}
+	if h.checks != nil {
+		report := h.checks.Run(c.Request.Context())
+		page.Healthy = report.Healthy
+		for _, check := range report.Checks {
+			page.Checks = append(page.Checks, publicCheck(check))
+		}
+		if history := h.checks.History(); history != nil {
+			page.Uptime, page.Incidents = make(map[string]float64), make(map[string]bool)
+			for _, dep := range history.Status().Dependencies {
+				page.Uptime[dep.Name], page.Incidents[dep.Name] = dep.Uptime, dep.Incident
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if !page.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	h.render(c, status, "pages/status", page)
+}
+
+// publicCheck returns check with a generic message in place of its error, which names hosts, ports
+// and drivers; the errors are served by /readyz
+func publicCheck(check resources.HealthCheck) resources.HealthCheck {
+	check.Message = "Operational"
+	if check.Status != "ok" {
+		check.Message = "Unavailable"
+	}
+	return check
+}
+
+// ListEmails renders the list of the email templates that can be previewed
+func (h *Handler) ListEmails(c *gin.Context) {
+	h.render(c, http.StatusOK, "pages/emails", h.renderer.Emails())
+}
+
+// PreviewEmail renders an email template with its sample data
+func (h *Handler) PreviewEmail(c *gin.Context) {
+	name := c.Param("name")
+	data, ok := web.PreviewData(name)
+	if !ok || !h.renderer.Has("emails/"+name) {
+		response.NotFound(c, "Email not found")
+		return
+	}
+	h.render(c, http.StatusOK, "emails/"+name, data)
+}
+
+// render responds with a rendered template
+func (h *Handler) render(c *gin.Context, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := h.renderer.Render(&buf, name, data); err != nil {
+		h.GetRequestLogger(c).Error("Failed to render page", zap.String("template", name), zap.Error(err))
+		response.InternalServerError(c, "Failed to render page")
+		return
+	}
+	c.Data(status, htmlContentType, buf.Bytes())
+}
+
+// Previews reports whether the email previews are served
+func (h *Handler) Previews() bool {
+	return h.renderer.Previews()
+}
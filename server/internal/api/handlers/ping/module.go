@@ -0,0 +1,39 @@
+package ping
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"quizizz.com/internal/module"
+)
+
+// ProviderSet provides the ping module
+var ProviderSet = wire.NewSet(
+	NewHandler,
+	NewModule,
+)
+
+// Module serves the ping endpoint
+type Module struct {
+	module.Base
+	handler *Handler
+}
+
+// NewModule creates the ping module
+func NewModule(handler *Handler) *Module {
+	return &Module{handler: handler}
+}
+
+// Name returns the module name, which is also its path
+func (m *Module) Name() string {
+	return "ping"
+}
+
+// Routes registers GET /ping
+func (m *Module) Routes(r gin.IRouter) {
+	r.GET("", m.handler.Ping)
+}
+
+// Providers returns ProviderSet
+func (m *Module) Providers() wire.ProviderSet {
+	return ProviderSet
+}
@@ -0,0 +1,43 @@
+package uploads
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"quizizz.com/internal/module"
+)
+
+// ProviderSet provides the uploads module
+var ProviderSet = wire.NewSet(
+	NewHandler,
+	NewModule,
+)
+
+// Module serves the endpoints presigning uploads to object storage and confirming them
+// Uploads belong to the current user, so deployments should require authentication through the
+// uploads route policy.
+type Module struct {
+	module.Base
+	handler *Handler
+}
+
+// NewModule creates the uploads module
+func NewModule(handler *Handler) *Module {
+	return &Module{handler: handler}
+}
+
+// Name returns the module name, which is also its path
+func (m *Module) Name() string {
+	return "uploads"
+}
+
+// Routes registers POST /uploads/presign, POST /uploads/:id/confirm and GET /uploads/:id
+func (m *Module) Routes(r gin.IRouter) {
+	r.POST("/presign", m.handler.Presign)
+	r.POST("/:id/confirm", m.handler.Confirm)
+	r.GET("/:id", m.handler.GetUpload)
+}
+
+// Providers returns ProviderSet
+func (m *Module) Providers() wire.ProviderSet {
+	return ProviderSet
+}
@@ -0,0 +1,177 @@
+// Package uploads lets users upload files straight to S3-compatible object storage through
+// presigned URLs, keeping file bytes off the API servers
+package uploads
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/errors"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/correlation"
+)
+
+// Upload represents an upload in the API
+type Upload struct {
+	ID          string     `json:"id"`
+	Key         string     `json:"key"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Status      string     `json:"status"`
+	ETag        string     `json:"etag,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// PresignRequest is the body of a presign request, declaring the file to upload
+type PresignRequest struct {
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required"`
+}
+
+// PresignResponse holds the pending upload and the request uploading its file
+type PresignResponse struct {
+	Upload  Upload                      `json:"upload"`
+	Request *resources.PresignedRequest `json:"request"`
+}
+
+// Handler handles upload requests
+type Handler struct {
+	*handlers.BaseHandler
+	uploadService service.UploadService
+}
+
+// NewHandler creates a new upload handler
+func NewHandler(base *handlers.BaseHandler, uploadService service.UploadService) *Handler {
+	return &Handler{
+		BaseHandler:   base,
+		uploadService: uploadService,
+	}
+}
+
+// Presign stores a pending upload of the current user and responds with the presigned PUT request
+// uploading its file, which must be sent with the given headers before it expires
+func (h *Handler) Presign(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	logger := h.GetRequestLogger(c).With(zap.String("userId", userID))
+
+	var req PresignRequest
+	if !h.ShouldBindJSON(c, &req) {
+		logger.Warn("Invalid request body")
+		return
+	}
+
+	upload := &domain.Upload{Filename: req.Filename, ContentType: req.ContentType, Size: req.Size}
+	request, err := h.uploadService.Presign(c.Request.Context(), userID, upload)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to presign upload")
+		return
+	}
+	response.Created(c, PresignResponse{Upload: toAPIUpload(upload), Request: request})
+}
+
+// Confirm checks the uploaded file of an upload of the current user and completes the upload
+// It responds 409 until the file is uploaded, and 422 when the file does not match the upload,
+// which is then deleted.
+func (h *Handler) Confirm(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", userID), zap.String("uploadId", id))
+
+	upload, err := h.uploadService.Confirm(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to confirm upload")
+		return
+	}
+	response.Success(c, toAPIUpload(upload))
+}
+
+// GetUpload returns an upload of the current user
+func (h *Handler) GetUpload(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", userID), zap.String("uploadId", id))
+
+	upload, err := h.uploadService.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to get upload")
+		return
+	}
+	response.Success(c, toAPIUpload(upload))
+}
+
+// currentUser returns the ID of the current user, responding 401 when the request is anonymous
+func (h *Handler) currentUser(c *gin.Context) (string, bool) {
+	userID := correlation.FromContext(c.Request.Context()).UserID
+	if userID == "" {
+		response.Unauthorized(c, "Authentication required")
+		return "", false
+	}
+	return userID, true
+}
+
+// fail responds to a failed upload operation
+func (h *Handler) fail(c *gin.Context, logger *zap.Logger, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		logger.Warn("Upload not found")
+		response.NotFound(c, "Upload not found")
+	case errors.Is(err, service.ErrUploadsDisabled):
+		logger.Warn(message, zap.Error(err))
+		response.Fail(c, errors.HTTPError(http.StatusNotImplemented, "Uploads are not configured"))
+	case errors.Is(err, domain.ErrInvalidUpload):
+		logger.Warn(message, zap.Error(err))
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrUploadIncomplete):
+		logger.Warn(message, zap.Error(err))
+		response.Fail(c, &errors.AppError{
+			StatusCode: http.StatusConflict,
+			Message:    "The file has not been uploaded yet",
+			Original:   errors.ErrConflict,
+		})
+	case errors.Is(err, service.ErrUploadMismatch):
+		logger.Warn(message, zap.Error(err))
+		response.Fail(c, errors.HTTPError(http.StatusUnprocessableEntity, "The uploaded file does not match the upload"))
+	default:
+		logger.Error(message, zap.Error(err))
+		response.InternalServerError(c, message)
+	}
+}
+
+// toAPIUpload converts an upload to its API form
+func toAPIUpload(u *domain.Upload) Upload {
+	upload := Upload{
+		ID:          u.ID,
+		Key:         u.Key,
+		Filename:    u.Filename,
+		ContentType: u.ContentType,
+		Size:        u.Size,
+		Status:      u.Status,
+		ETag:        u.ETag,
+		CreatedAt:   u.CreatedAt,
+		ExpiresAt:   u.ExpiresAt,
+	}
+	if !u.CompletedAt.IsZero() {
+		completedAt := u.CompletedAt
+		upload.CompletedAt = &completedAt
+	}
+	return upload
+}
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/imaging"
+	"quizizz.com/internal/service"
+)
+
+// AvatarUpload represents an accepted avatar upload in the API
+type AvatarUpload struct {
+	JobID       string    `json:"job_id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// AvatarHandler handles the profile images of users
+type AvatarHandler struct {
+	*handlers.BaseHandler
+	avatarService service.AvatarService
+}
+
+// NewAvatarHandler creates a new avatar handler
+func NewAvatarHandler(base *handlers.BaseHandler, avatarService service.AvatarService) *AvatarHandler {
+	return &AvatarHandler{
+		BaseHandler:   base,
+		avatarService: avatarService,
+	}
+}
+
+// UploadAvatar accepts a new profile image of a user, sent as the request body (e.g. image/jpeg)
+// The image is resized in the background; the user's avatar URLs change once it is done.
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	job, err := h.avatarService.Upload(c.Request.Context(), id, c.Request.Body)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			response.NotFound(c, "User not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidImage) {
+			logger.Warn("Invalid avatar image", zap.Error(err))
+			response.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error("Failed to upload avatar", zap.Error(err))
+		response.InternalServerError(c, "Failed to upload avatar")
+		return
+	}
+
+	response.Accepted(c, AvatarUpload{
+		JobID:       job.ID,
+		Status:      job.Status,
+		RequestedAt: job.CreatedAt,
+	})
+}
+
+// GetAvatar serves a variant of a user's avatar by its size, e.g. /users/:id/avatar/128
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	id, size := c.Param("id"), c.Param("size")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id), zap.String("size", size))
+
+	stream, info, err := h.avatarService.Open(c.Request.Context(), id, size)
+	if err != nil {
+		if err == service.ErrUserNotFound || err == service.ErrAvatarNotFound {
+			response.NotFound(c, "Avatar not found")
+			return
+		}
+		logger.Error("Failed to open avatar", zap.Error(err))
+		response.InternalServerError(c, "Failed to get avatar")
+		return
+	}
+	defer stream.Close()
+
+	c.DataFromReader(http.StatusOK, info.Length, imaging.ContentType, stream, nil)
+}
+
+// DeleteAvatar removes the avatar of a user
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	err := h.avatarService.Delete(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound || err == service.ErrAvatarNotFound {
+			response.NotFound(c, "Avatar not found")
+			return
+		}
+		logger.Error("Failed to delete avatar", zap.Error(err))
+		response.InternalServerError(c, "Failed to delete avatar")
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// avatarURLs returns the URL of each variant of a user's avatar by size, or nil without an avatar
+// The URLs carry the avatar version, so they can be cached for good and change with every upload.
+func avatarURLs(user *domain.User) map[string]string {
+	if user.Avatar == nil {
+		return nil
+	}
+	urls := make(map[string]string, len(user.Avatar.Variants))
+	for size := range user.Avatar.Variants {
+		urls[size] = fmt.Sprintf("/api/v1/users/%s/avatar/%s?v=%s",
+			url.PathEscape(user.ID), url.PathEscape(size), url.QueryEscape(user.Avatar.Version))
+	}
+	return urls
+}
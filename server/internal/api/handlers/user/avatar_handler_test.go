@@ -0,0 +1,115 @@
+package user
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/middleware"
+)
+
+func setupAvatarHandler(t *testing.T) (*gin.Engine, *mocks.AvatarService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := mocks.NewAvatarService(t)
+	handler := NewAvatarHandler(handlers.NewBaseHandler(nil), mockService)
+
+	router := gin.New()
+	router.Use(middleware.Correlation())
+	router.PUT("/api/v1/users/:id/avatar", handler.UploadAvatar)
+	router.GET("/api/v1/users/:id/avatar/:size", handler.GetAvatar)
+	router.DELETE("/api/v1/users/:id/avatar", handler.DeleteAvatar)
+	return router, mockService
+}
+
+func TestAvatarHandler_UploadAvatar(t *testing.T) {
+	t.Run("Accepted", func(t *testing.T) {
+		router, mockService := setupAvatarHandler(t)
+		mockService.EXPECT().Upload(mock.Anything, "user-1", mock.Anything).
+			Return(&repository.Job{ID: "job-1", Status: repository.JobPending, CreatedAt: time.Now()}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/user-1/avatar", strings.NewReader("png bytes"))
+		req.Header.Set("Content-Type", "image/png")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"job_id":"job-1"`)
+	})
+
+	t.Run("Invalid image", func(t *testing.T) {
+		router, mockService := setupAvatarHandler(t)
+		mockService.EXPECT().Upload(mock.Anything, "user-1", mock.Anything).
+			Return(nil, fmt.Errorf("%w: unsupported image format", service.ErrInvalidImage))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/user-1/avatar", strings.NewReader("%PDF"))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unsupported image format")
+	})
+}
+
+func TestAvatarHandler_GetAvatar(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupAvatarHandler(t)
+		mockService.EXPECT().Open(mock.Anything, "user-1", "128").
+			Return(io.NopCloser(strings.NewReader("RIFF....WEBP")), &repository.FileInfo{Length: 12}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1/avatar/128", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/webp", w.Header().Get("Content-Type"))
+		assert.Equal(t, "RIFF....WEBP", w.Body.String())
+	})
+
+	t.Run("No avatar", func(t *testing.T) {
+		router, mockService := setupAvatarHandler(t)
+		mockService.EXPECT().Open(mock.Anything, "user-1", "128").Return(nil, nil, service.ErrAvatarNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1/avatar/128", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAvatarHandler_DeleteAvatar(t *testing.T) {
+	router, mockService := setupAvatarHandler(t)
+	mockService.EXPECT().Delete(mock.Anything, "user-1").Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/v1/users/user-1/avatar", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAvatarURLs(t *testing.T) {
+	assert.Nil(t, avatarURLs(&domain.User{ID: "user-1"}))
+
+	urls := avatarURLs(&domain.User{ID: "user-1", Avatar: &domain.Avatar{
+		Version:  "abc",
+		Variants: map[string]string{"64": "file-1", "128": "file-2"},
+	}})
+	assert.Equal(t, map[string]string{
+		"64":  "/api/v1/users/user-1/avatar/64?v=abc",
+		"128": "/api/v1/users/user-1/avatar/128?v=abc",
+	}, urls)
+}
@@ -0,0 +1,168 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/service"
+)
+
+// Device represents a device registered for push notifications in the API, without its token
+type Device struct {
+	ID        string    `json:"id"`
+	Platform  string    `json:"platform"`
+	Name      string    `json:"name,omitempty"`
+	Topics    []string  `json:"topics"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RegisterDeviceRequest is the body of a device registration
+type RegisterDeviceRequest struct {
+	Platform string `json:"platform" binding:"required"`
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name"`
+}
+
+// DeviceHandler handles the devices users register for push notifications and their topics
+type DeviceHandler struct {
+	*handlers.BaseHandler
+	notificationService service.NotificationService
+}
+
+// NewDeviceHandler creates a new device handler
+func NewDeviceHandler(base *handlers.BaseHandler, notificationService service.NotificationService) *DeviceHandler {
+	return &DeviceHandler{
+		BaseHandler:         base,
+		notificationService: notificationService,
+	}
+}
+
+// ListDevices returns the devices of a user, most recently registered first
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	devices, err := h.notificationService.ListDevices(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			response.NotFound(c, "User not found")
+			return
+		}
+		logger.Error("Failed to list devices", zap.Error(err))
+		response.InternalServerError(c, "Failed to list devices")
+		return
+	}
+
+	apiDevices := make([]Device, len(devices))
+	for i, d := range devices {
+		apiDevices[i] = toAPIDevice(d)
+	}
+	response.Success(c, gin.H{
+		"devices": apiDevices,
+		"count":   len(apiDevices),
+	})
+}
+
+// RegisterDevice registers a device of a user with the token the push provider gave the app on it
+// Registering a token again updates its registration and responds 200 instead of 201.
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	var req RegisterDeviceRequest
+	if !h.ShouldBindJSON(c, &req) {
+		logger.Warn("Invalid request body")
+		return
+	}
+
+	device := &domain.Device{Platform: req.Platform, Token: req.Token, Name: req.Name}
+	created, err := h.notificationService.RegisterDevice(c.Request.Context(), id, device)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to register device")
+		return
+	}
+
+	if created {
+		response.Created(c, toAPIDevice(device))
+		return
+	}
+	response.Success(c, toAPIDevice(device))
+}
+
+// UnregisterDevice removes a device of a user, which stops receiving push notifications
+func (h *DeviceHandler) UnregisterDevice(c *gin.Context) {
+	id, deviceID := c.Param("id"), c.Param("deviceId")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id), zap.String("deviceId", deviceID))
+
+	err := h.notificationService.UnregisterDevice(c.Request.Context(), id, deviceID)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to unregister device")
+		return
+	}
+	response.NoContent(c)
+}
+
+// Subscribe subscribes a device of a user to a topic, e.g. PUT /users/:id/devices/:deviceId/topics/news
+func (h *DeviceHandler) Subscribe(c *gin.Context) {
+	id, deviceID, topic := c.Param("id"), c.Param("deviceId"), c.Param("topic")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id), zap.String("deviceId", deviceID), zap.String("topic", topic))
+
+	err := h.notificationService.Subscribe(c.Request.Context(), id, deviceID, topic)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to subscribe device")
+		return
+	}
+	response.NoContent(c)
+}
+
+// Unsubscribe unsubscribes a device of a user from a topic
+func (h *DeviceHandler) Unsubscribe(c *gin.Context) {
+	id, deviceID, topic := c.Param("id"), c.Param("deviceId"), c.Param("topic")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id), zap.String("deviceId", deviceID), zap.String("topic", topic))
+
+	err := h.notificationService.Unsubscribe(c.Request.Context(), id, deviceID, topic)
+	if err != nil {
+		h.fail(c, logger, err, "Failed to unsubscribe device")
+		return
+	}
+	response.NoContent(c)
+}
+
+// fail responds to a failed device operation
+func (h *DeviceHandler) fail(c *gin.Context, logger *zap.Logger, err error, message string) {
+	switch {
+	case err == service.ErrUserNotFound:
+		response.NotFound(c, "User not found")
+	case err == service.ErrDeviceNotFound:
+		logger.Warn("Device not found")
+		response.NotFound(c, "Device not found")
+	case errors.Is(err, domain.ErrInvalidDevice):
+		logger.Warn(message, zap.Error(err))
+		response.BadRequest(c, err.Error())
+	default:
+		logger.Error(message, zap.Error(err))
+		response.InternalServerError(c, message)
+	}
+}
+
+// toAPIDevice converts a device to its API form
+func toAPIDevice(d *domain.Device) Device {
+	topics := d.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return Device{
+		ID:        d.ID,
+		Platform:  d.Platform,
+		Name:      d.Name,
+		Topics:    topics,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/middleware"
+)
+
+func setupDeviceHandler(t *testing.T) (*gin.Engine, *mocks.NotificationService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := mocks.NewNotificationService(t)
+	handler := NewDeviceHandler(handlers.NewBaseHandler(nil), mockService)
+
+	router := gin.New()
+	router.Use(middleware.Correlation())
+	router.GET("/api/v1/users/:id/devices", handler.ListDevices)
+	router.POST("/api/v1/users/:id/devices", handler.RegisterDevice)
+	router.DELETE("/api/v1/users/:id/devices/:deviceId", handler.UnregisterDevice)
+	router.PUT("/api/v1/users/:id/devices/:deviceId/topics/:topic", handler.Subscribe)
+	router.DELETE("/api/v1/users/:id/devices/:deviceId/topics/:topic", handler.Unsubscribe)
+	return router, mockService
+}
+
+func TestDeviceHandler_RegisterDevice(t *testing.T) {
+	register := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users/user-1/devices", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("New device", func(t *testing.T) {
+		router, mockService := setupDeviceHandler(t)
+		mockService.EXPECT().RegisterDevice(mock.Anything, "user-1", mock.MatchedBy(func(d *domain.Device) bool {
+			return d.Platform == "ios" && d.Token == "secret-token" && d.Name == "iPhone"
+		})).RunAndReturn(func(_ context.Context, userID string, d *domain.Device) (bool, error) {
+			d.ID, d.UserID = "device-1", userID
+			return true, nil
+		})
+
+		w := register(router, `{"platform":"ios","token":"secret-token","name":"iPhone"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"device-1"`)
+		assert.Contains(t, w.Body.String(), `"topics":[]`)
+		assert.NotContains(t, w.Body.String(), "secret-token", "the token is never returned")
+	})
+
+	t.Run("Registered token", func(t *testing.T) {
+		router, mockService := setupDeviceHandler(t)
+		mockService.EXPECT().RegisterDevice(mock.Anything, "user-1", mock.Anything).Return(false, nil)
+
+		w := register(router, `{"platform":"web","token":"token"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		router, _ := setupDeviceHandler(t)
+
+		w := register(router, `{"platform":"web"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid platform", func(t *testing.T) {
+		router, mockService := setupDeviceHandler(t)
+		mockService.EXPECT().RegisterDevice(mock.Anything, "user-1", mock.Anything).
+			Return(false, fmt.Errorf("%w: unknown platform palm", domain.ErrInvalidDevice))
+
+		w := register(router, `{"platform":"palm","token":"token"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown platform palm")
+	})
+}
+
+func TestDeviceHandler_ListDevices(t *testing.T) {
+	router, mockService := setupDeviceHandler(t)
+	mockService.EXPECT().ListDevices(mock.Anything, "user-1").Return([]*domain.Device{
+		{ID: "device-1", Platform: "android", Token: "secret-token", Topics: []string{"news"}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/users/user-1/devices", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	assert.Contains(t, w.Body.String(), `"topics":["news"]`)
+	assert.NotContains(t, w.Body.String(), "secret-token")
+}
+
+func TestDeviceHandler_Topics(t *testing.T) {
+	send := func(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Subscribe and unsubscribe", func(t *testing.T) {
+		router, mockService := setupDeviceHandler(t)
+		mockService.EXPECT().Subscribe(mock.Anything, "user-1", "device-1", "news").Return(nil)
+		mockService.EXPECT().Unsubscribe(mock.Anything, "user-1", "device-1", "news").Return(nil)
+
+		assert.Equal(t, http.StatusNoContent, send(router, "PUT", "/api/v1/users/user-1/devices/device-1/topics/news").Code)
+		assert.Equal(t, http.StatusNoContent, send(router, "DELETE", "/api/v1/users/user-1/devices/device-1/topics/news").Code)
+	})
+
+	t.Run("Device not found", func(t *testing.T) {
+		router, mockService := setupDeviceHandler(t)
+		mockService.EXPECT().Subscribe(mock.Anything, "user-1", "missing", "news").Return(service.ErrDeviceNotFound)
+		mockService.EXPECT().UnregisterDevice(mock.Anything, "user-1", "missing").Return(service.ErrDeviceNotFound)
+
+		assert.Equal(t, http.StatusNotFound, send(router, "PUT", "/api/v1/users/user-1/devices/missing/topics/news").Code)
+		assert.Equal(t, http.StatusNotFound, send(router, "DELETE", "/api/v1/users/user-1/devices/missing").Code)
+	})
+}
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/errors"
+	"quizizz.com/internal/service"
+)
+
+// Preferences represents the effective preferences of a user in the API
+type Preferences struct {
+	UserID string `json:"user_id"`
+	domain.Preferences
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// PreferencesHandler handles the preferences of users
+type PreferencesHandler struct {
+	*handlers.BaseHandler
+	preferencesService service.PreferencesService
+}
+
+// NewPreferencesHandler creates a new preferences handler
+func NewPreferencesHandler(base *handlers.BaseHandler, preferencesService service.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{
+		BaseHandler:        base,
+		preferencesService: preferencesService,
+	}
+}
+
+// GetPreferences returns the preferences of a user merged over the defaults, tagged with their version
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	prefs, err := h.preferencesService.Get(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			response.NotFound(c, "User not found")
+			return
+		}
+		logger.Error("Failed to get preferences", zap.Error(err))
+		response.InternalServerError(c, "Failed to get preferences")
+		return
+	}
+
+	response.SetVersionETag(c, prefs.Version)
+	response.Success(c, toAPIPreferences(prefs))
+}
+
+// UpdatePreferences replaces the settings of a user; settings left out return to their default
+// If-Match makes the write conditional on the version returned by GetPreferences.
+func (h *PreferencesHandler) UpdatePreferences(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	expectedVersion, hasPrecondition, err := h.GetIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("Invalid If-Match header", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	var settings domain.PreferenceSettings
+	if !h.ShouldBindJSON(c, &settings) {
+		logger.Warn("Invalid request body")
+		return
+	}
+
+	var expected *int64
+	if hasPrecondition {
+		expected = &expectedVersion
+	}
+	prefs, err := h.preferencesService.Update(c.Request.Context(), id, settings, expected)
+	switch {
+	case err == nil:
+	case err == service.ErrUserNotFound:
+		response.NotFound(c, "User not found")
+		return
+	case errors.Is(err, domain.ErrInvalidPreferences):
+		logger.Warn("Invalid preferences", zap.Error(err))
+		response.BadRequest(c, err.Error())
+		return
+	case err == service.ErrVersionConflict:
+		logger.Warn("Preferences modified concurrently")
+		if hasPrecondition {
+			response.PreconditionFailed(c, "Preferences have been modified since they were retrieved")
+			return
+		}
+		response.Fail(c, &errors.AppError{
+			StatusCode: http.StatusConflict,
+			Message:    "Preferences were modified concurrently, please retry",
+			Original:   errors.ErrConflict,
+		})
+		return
+	default:
+		logger.Error("Failed to update preferences", zap.Error(err))
+		response.InternalServerError(c, "Failed to update preferences")
+		return
+	}
+
+	logger.Info("Preferences updated", zap.Int64("version", prefs.Version))
+	response.SetVersionETag(c, prefs.Version)
+	response.Success(c, toAPIPreferences(prefs))
+}
+
+// toAPIPreferences converts stored preferences to their API representation
+func toAPIPreferences(prefs *domain.UserPreferences) Preferences {
+	apiPrefs := Preferences{UserID: prefs.UserID, Preferences: prefs.Effective()}
+	if !prefs.UpdatedAt.IsZero() {
+		apiPrefs.UpdatedAt = &prefs.UpdatedAt
+	}
+	return apiPrefs
+}
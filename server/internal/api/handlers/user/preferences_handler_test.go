@@ -0,0 +1,116 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/middleware"
+)
+
+func setupPreferencesHandler(t *testing.T) (*gin.Engine, *mocks.PreferencesService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := mocks.NewPreferencesService(t)
+	handler := NewPreferencesHandler(handlers.NewBaseHandler(nil), mockService)
+
+	router := gin.New()
+	router.Use(middleware.Correlation())
+	router.GET("/api/v1/users/:id/preferences", handler.GetPreferences)
+	router.PUT("/api/v1/users/:id/preferences", handler.UpdatePreferences)
+	return router, mockService
+}
+
+func TestPreferencesHandler_GetPreferences(t *testing.T) {
+	t.Run("Defaults merged with settings", func(t *testing.T) {
+		router, mockService := setupPreferencesHandler(t)
+		dark := "dark"
+		mockService.EXPECT().Get(mock.Anything, "user-1").Return(&domain.UserPreferences{
+			UserID:   "user-1",
+			Settings: domain.PreferenceSettings{Theme: &dark},
+			Version:  3,
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1/preferences", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"v3"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"theme":"dark"`)
+		assert.Contains(t, w.Body.String(), `"locale":"en"`)
+		assert.Contains(t, w.Body.String(), `"digest":"weekly"`)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		router, mockService := setupPreferencesHandler(t)
+		mockService.EXPECT().Get(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/missing/preferences", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPreferencesHandler_UpdatePreferences(t *testing.T) {
+	put := func(router *gin.Engine, body, ifMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/user-1/preferences", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupPreferencesHandler(t)
+		mockService.EXPECT().Update(mock.Anything, "user-1", mock.MatchedBy(func(s domain.PreferenceSettings) bool {
+			return *s.Locale == "pt-BR" && !*s.Notifications.Email && s.Theme == nil
+		}), (*int64)(nil)).RunAndReturn(func(_ context.Context, userID string, s domain.PreferenceSettings, _ *int64) (*domain.UserPreferences, error) {
+			return &domain.UserPreferences{UserID: userID, Settings: s, Version: 1}, nil
+		})
+
+		w := put(router, `{"locale":"pt-BR","notifications":{"email":false}}`, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"locale":"pt-BR"`)
+		assert.Contains(t, w.Body.String(), `"email":false`)
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		router, mockService := setupPreferencesHandler(t)
+		mockService.EXPECT().Update(mock.Anything, "user-1", mock.Anything, (*int64)(nil)).
+			Return(nil, fmt.Errorf("%w: theme must be one of %v", domain.ErrInvalidPreferences, domain.Themes))
+
+		w := put(router, `{"theme":"neon"}`, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "theme must be one of")
+	})
+
+	t.Run("Stale If-Match", func(t *testing.T) {
+		router, mockService := setupPreferencesHandler(t)
+		mockService.EXPECT().Update(mock.Anything, "user-1", mock.Anything, mock.MatchedBy(func(v *int64) bool {
+			return v != nil && *v == 2
+		})).Return(nil, service.ErrVersionConflict)
+
+		w := put(router, `{}`, `"v2"`)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
@@ -0,0 +1,167 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/correlation"
+)
+
+// Session represents a session of the current user in the API
+type Session struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	ClientIP   string    `json:"client_ip,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RefreshRequest is the body of a refresh token rotation
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionHandler handles the sessions of the current user and the rotation of their refresh tokens
+type SessionHandler struct {
+	*handlers.BaseHandler
+	sessionService service.SessionService
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(base *handlers.BaseHandler, sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		BaseHandler:    base,
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions returns the active sessions of the current user, most recently used first
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	logger := h.GetRequestLogger(c).With(zap.String("userId", userID))
+
+	sessions, err := h.sessionService.List(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to list sessions", zap.Error(err))
+		response.InternalServerError(c, "Failed to list sessions")
+		return
+	}
+
+	apiSessions := make([]Session, len(sessions))
+	for i, s := range sessions {
+		apiSessions[i] = toAPISession(s)
+	}
+	response.Success(c, gin.H{
+		"sessions": apiSessions,
+		"count":    len(apiSessions),
+	})
+}
+
+// RevokeSession revokes a session of the current user, signing its device out
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	sessionID := c.Param("sessionId")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", userID), zap.String("sessionId", sessionID))
+
+	err := h.sessionService.Revoke(c.Request.Context(), userID, sessionID)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		logger.Warn("Session not found for revocation")
+		response.NotFound(c, "Session not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to revoke session", zap.Error(err))
+		response.InternalServerError(c, "Failed to revoke session")
+		return
+	}
+	response.NoContent(c)
+}
+
+// RevokeSessions revokes every session of the current user, signing all their devices out
+func (h *SessionHandler) RevokeSessions(c *gin.Context) {
+	userID, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	logger := h.GetRequestLogger(c).With(zap.String("userId", userID))
+
+	if _, err := h.sessionService.RevokeAll(c.Request.Context(), userID); err != nil {
+		logger.Error("Failed to revoke sessions", zap.Error(err))
+		response.InternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	response.NoContent(c)
+}
+
+// Refresh rotates a refresh token, returning the token replacing it
+// A token is good for one refresh: presenting it again revokes its session.
+func (h *SessionHandler) Refresh(c *gin.Context) {
+	logger := h.GetRequestLogger(c)
+
+	var req RefreshRequest
+	if !h.ShouldBindJSON(c, &req) {
+		logger.Warn("Invalid request body")
+		return
+	}
+
+	session, token, err := h.sessionService.Refresh(c.Request.Context(), req.RefreshToken, c.Request.UserAgent())
+	if errors.Is(err, service.ErrRefreshTokenReused) {
+		logger.Warn("Refresh token reused")
+		response.Unauthorized(c, "Refresh token already used; the session was revoked")
+		return
+	}
+	if errors.Is(err, service.ErrInvalidRefreshToken) {
+		logger.Warn("Invalid refresh token")
+		response.Unauthorized(c, "Invalid refresh token")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to refresh session", zap.Error(err))
+		response.InternalServerError(c, "Failed to refresh session")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, gin.H{
+		"refresh_token": token,
+		"session":       toAPISession(session),
+	})
+}
+
+// currentUser returns the ID of the user the request is made for, responding 401 when there is none
+func (h *SessionHandler) currentUser(c *gin.Context) (string, bool) {
+	userID := correlation.FromContext(c.Request.Context()).UserID
+	if userID == "" {
+		h.GetRequestLogger(c).Warn("No user for the current user's sessions")
+		response.Unauthorized(c, "Authentication required")
+		return "", false
+	}
+	return userID, true
+}
+
+// toAPISession converts a stored session to its API form, leaving out its token hashes
+func toAPISession(s *repository.Session) Session {
+	return Session{
+		ID:         s.ID,
+		Device:     s.Device,
+		UserAgent:  s.UserAgent,
+		ClientIP:   s.ClientIP,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
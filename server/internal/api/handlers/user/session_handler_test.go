@@ -0,0 +1,156 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/correlation"
+	"quizizz.com/pkg/middleware"
+)
+
+func setupSessionHandler(t *testing.T) (*gin.Engine, *mocks.SessionService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := mocks.NewSessionService(t)
+	handler := NewSessionHandler(handlers.NewBaseHandler(nil), mockService)
+
+	router := gin.New()
+	router.Use(middleware.Correlation())
+	router.GET("/api/v1/users/me/sessions", handler.ListSessions)
+	router.DELETE("/api/v1/users/me/sessions", handler.RevokeSessions)
+	router.DELETE("/api/v1/users/me/sessions/:sessionId", handler.RevokeSession)
+	router.POST("/api/v1/sessions/refresh", handler.Refresh)
+	return router, mockService
+}
+
+// asUser returns req made on behalf of userID, as authentication would set it
+func asUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: userID}))
+}
+
+func TestSessionHandler_ListSessions(t *testing.T) {
+	usedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupSessionHandler(t)
+		mockService.EXPECT().List(mock.Anything, "user-1").Return([]*repository.Session{{
+			ID:         "session-1",
+			UserID:     "user-1",
+			Device:     "Pixel 8",
+			TokenHash:  "secret-hash",
+			CreatedAt:  usedAt,
+			LastUsedAt: usedAt,
+			ExpiresAt:  usedAt.Add(time.Hour),
+		}}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/me/sessions", nil)
+		req = asUser(req, "user-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"device":"Pixel 8"`)
+		assert.NotContains(t, w.Body.String(), "secret-hash")
+	})
+
+	t.Run("No current user", func(t *testing.T) {
+		router, _ := setupSessionHandler(t)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/me/sessions", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestSessionHandler_RevokeSession(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupSessionHandler(t)
+		mockService.EXPECT().Revoke(mock.Anything, "user-1", "session-1").Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/users/me/sessions/session-1", nil)
+		req = asUser(req, "user-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Session not found", func(t *testing.T) {
+		router, mockService := setupSessionHandler(t)
+		mockService.EXPECT().Revoke(mock.Anything, "user-1", "missing").Return(service.ErrSessionNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/users/me/sessions/missing", nil)
+		req = asUser(req, "user-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Revoke all", func(t *testing.T) {
+		router, mockService := setupSessionHandler(t)
+		mockService.EXPECT().RevokeAll(mock.Anything, "user-1").Return(int64(2), nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/users/me/sessions", nil)
+		req = asUser(req, "user-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestSessionHandler_Refresh(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupSessionHandler(t)
+		mockService.EXPECT().Refresh(mock.Anything, "session-1.old", "app/1.0").
+			Return(&repository.Session{ID: "session-1"}, "session-1.new", nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/sessions/refresh", strings.NewReader(`{"refresh_token":"session-1.old"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "app/1.0")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), `"refresh_token":"session-1.new"`)
+	})
+
+	t.Run("Reused token", func(t *testing.T) {
+		router, mockService := setupSessionHandler(t)
+		mockService.EXPECT().Refresh(mock.Anything, "session-1.old", mock.Anything).
+			Return(nil, "", service.ErrRefreshTokenReused)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/sessions/refresh", strings.NewReader(`{"refresh_token":"session-1.old"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "UNAUTHORIZED")
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		router, _ := setupSessionHandler(t)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/sessions/refresh", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,118 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+)
+
+// UserSummary represents a user with the counts of their sessions and devices in the API
+type UserSummary struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Roles      []string   `json:"roles,omitempty"`
+	Locked     bool       `json:"locked,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Sessions   int64      `json:"sessions"`
+	Devices    int64      `json:"devices"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+
+	// RefreshedAt is when the summary was computed; it lags writes to the user
+	RefreshedAt time.Time `json:"refreshed_at"`
+}
+
+// summaryListSpec whitelists the sorts of the user summary list; it has no filters
+var summaryListSpec = handlers.ListSpec{
+	Sorts: service.UserSummarySortFields,
+}
+
+// SummaryHandler serves user summaries from the read model, for list and dashboard reads that
+// would otherwise count sessions and devices per request
+type SummaryHandler struct {
+	*handlers.BaseHandler
+	summaryService service.UserSummaryService
+}
+
+// NewSummaryHandler creates a new user summary handler
+func NewSummaryHandler(base *handlers.BaseHandler, summaryService service.UserSummaryService) *SummaryHandler {
+	return &SummaryHandler{
+		BaseHandler:    base,
+		summaryService: summaryService,
+	}
+}
+
+// ListSummaries returns a page of user summaries, newest users first unless sorted otherwise
+func (h *SummaryHandler) ListSummaries(c *gin.Context) {
+	logger := h.GetRequestLogger(c)
+
+	query, err := h.GetListQuery(c, summaryListSpec)
+	if err != nil {
+		logger.Warn("Invalid list parameters", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	summaries, total, err := h.summaryService.List(c.Request.Context(), query.Options())
+	if err != nil {
+		logger.Error("Failed to list user summaries", zap.Error(err))
+		response.InternalServerError(c, "Failed to list user summaries")
+		return
+	}
+
+	response.Paginated(c, gin.H{
+		"summaries": toAPIUserSummaries(summaries),
+		"count":     len(summaries),
+	}, query.Page, query.Limit, total)
+}
+
+// GetSummary returns the summary of a user
+func (h *SummaryHandler) GetSummary(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+
+	summary, err := h.summaryService.Get(c.Request.Context(), id)
+	if errors.Is(err, service.ErrUserNotFound) {
+		response.NotFound(c, "User not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get user summary", zap.Error(err))
+		response.InternalServerError(c, "Failed to get user summary")
+		return
+	}
+
+	response.Success(c, toAPIUserSummary(summary))
+}
+
+func toAPIUserSummaries(summaries []*repository.UserSummary) []UserSummary {
+	result := make([]UserSummary, len(summaries))
+	for i, summary := range summaries {
+		result[i] = toAPIUserSummary(summary)
+	}
+	return result
+}
+
+func toAPIUserSummary(summary *repository.UserSummary) UserSummary {
+	result := UserSummary{
+		ID:          summary.UserID,
+		Name:        summary.Name,
+		Email:       summary.Email,
+		Roles:       summary.Roles,
+		Locked:      summary.Locked,
+		CreatedAt:   summary.CreatedAt,
+		Sessions:    summary.Sessions,
+		Devices:     summary.Devices,
+		RefreshedAt: summary.RefreshedAt,
+	}
+	if !summary.LastSeenAt.IsZero() {
+		result.LastSeenAt = &summary.LastSeenAt
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+	"quizizz.com/pkg/middleware"
+)
+
+func setupSummaryHandler(t *testing.T) (*gin.Engine, *mocks.UserSummaryService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := mocks.NewUserSummaryService(t)
+	handler := NewSummaryHandler(handlers.NewBaseHandler(nil), mockService)
+
+	router := gin.New()
+	router.Use(middleware.Correlation())
+	router.GET("/api/v1/users/summaries", handler.ListSummaries)
+	router.GET("/api/v1/users/:id/summary", handler.GetSummary)
+	return router, mockService
+}
+
+func TestSummaryHandler_ListSummaries(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Sorted page", func(t *testing.T) {
+		router, mockService := setupSummaryHandler(t)
+		mockService.EXPECT().List(mock.Anything, domain.ListOptions{Sort: "sessions", Descending: true, Offset: 10, Limit: 10}).
+			Return([]*repository.UserSummary{{UserID: "user-1", Name: "Ada", CreatedAt: createdAt, Sessions: 3}}, int64(11), nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/summaries?sort=-sessions&page=2&limit=10", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"user-1"`)
+		assert.Contains(t, w.Body.String(), `"sessions":3`)
+		assert.Contains(t, w.Body.String(), `"total":11`)
+		assert.NotContains(t, w.Body.String(), "last_seen_at", "users never seen have no last_seen_at")
+	})
+
+	t.Run("Unknown sort", func(t *testing.T) {
+		router, _ := setupSummaryHandler(t)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/summaries?sort=password", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		router, mockService := setupSummaryHandler(t)
+		mockService.EXPECT().List(mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("database unavailable"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/summaries", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestSummaryHandler_GetSummary(t *testing.T) {
+	t.Run("Found", func(t *testing.T) {
+		router, mockService := setupSummaryHandler(t)
+		lastSeenAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		mockService.EXPECT().Get(mock.Anything, "user-1").
+			Return(&repository.UserSummary{UserID: "user-1", Name: "Ada", Devices: 2, LastSeenAt: lastSeenAt}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1/summary", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"devices":2`)
+		assert.Contains(t, w.Body.String(), `"last_seen_at":"2024-01-02T03:04:05Z"`)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		router, mockService := setupSummaryHandler(t)
+		mockService.EXPECT().Get(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/missing/summary", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,189 @@
+package user
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/errors"
+	"quizizz.com/internal/service"
+)
+
+// exportFlushEvery is the number of rows written between flushes of the response
+const exportFlushEvery = 500
+
+// exportContentTypes maps the supported export formats to their content types
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+}
+
+// exportWriter encodes users in an export format
+type exportWriter interface {
+	WriteHeader() error
+	Write(user *domain.User) error
+	Flush() error
+}
+
+// ExportUsers streams all users matching the name/email filters as CSV or NDJSON
+func (h *Handler) ExportUsers(c *gin.Context) {
+	logger := h.GetRequestLogger(c)
+
+	format := c.DefaultQuery("format", "csv")
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		logger.Warn("Unsupported export format", zap.String("format", format))
+		err := &errors.AppError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Unsupported export format: " + format,
+			Original:   errors.ErrBadRequest,
+		}
+		response.Fail(c, err.WithContext("format", format))
+		return
+	}
+
+	filter := domain.UserFilter{Name: c.Query("name")}
+	if email := c.Query("email"); email != "" {
+		parsed, err := domain.ParseEmail(email)
+		if err != nil {
+			logger.Warn("Invalid email filter", zap.Error(err))
+			response.Fail(c, invalidField("email", err))
+			return
+		}
+		filter.Email = parsed
+	}
+	logger.Debug("Exporting users", zap.String("format", format))
+
+	writer := newExportWriter(format, c.Writer)
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Status(http.StatusOK)
+		return writer.WriteHeader()
+	}
+
+	// Headers are only sent once the first row is ready, so failures before that get a regular error response
+	rows := 0
+	err := h.userService.Export(c.Request.Context(), filter, func(user *domain.User) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(user); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	if err == nil && !started {
+		err = start()
+	}
+
+	if err != nil {
+		if started {
+			// The status line is already sent; all we can do is cut the stream short
+			logger.Error("User export interrupted", zap.Int("rows", rows), zap.Error(err))
+			c.Abort()
+			return
+		}
+		if err == service.ErrExportTooLarge {
+			logger.Warn("User export too large")
+			response.Fail(c, &errors.AppError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    fmt.Sprintf("Export exceeds %d rows, narrow the filter", service.MaxExportRows),
+				Original:   err,
+			})
+			return
+		}
+		logger.Error("Failed to export users", zap.Error(err))
+		response.InternalServerError(c, "Failed to export users")
+		return
+	}
+
+	if err := writer.Flush(); err != nil {
+		logger.Error("Failed to flush user export", zap.Error(err))
+		return
+	}
+
+	logger.Info("Users exported", zap.String("format", format), zap.Int("rows", rows))
+}
+
+// newExportWriter returns the writer for format, which must be a key of exportContentTypes
+func newExportWriter(format string, w io.Writer) exportWriter {
+	if format == "ndjson" {
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}
+	}
+	return &csvExportWriter{writer: csv.NewWriter(w)}
+}
+
+// csvExportWriter writes users as CSV rows
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvExportWriter) WriteHeader() error {
+	return w.writer.Write([]string{"id", "name", "email", "created_at", "updated_at"})
+}
+
+func (w *csvExportWriter) Write(user *domain.User) error {
+	return w.writer.Write([]string{
+		csvCell(user.ID),
+		csvCell(user.Name.String()),
+		csvCell(user.Email.String()),
+		user.CreatedAt.UTC().Format(time.RFC3339),
+		user.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// csvCell neutralizes user-controlled values that spreadsheets would evaluate as formulas, by
+// prefixing them with a quote
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (w *csvExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonExportWriter writes users as newline-delimited JSON objects
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonExportWriter) WriteHeader() error {
+	return nil
+}
+
+func (w *ndjsonExportWriter) Write(user *domain.User) error {
+	return w.encoder.Encode(user)
+}
+
+func (w *ndjsonExportWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/service"
+)
+
+// ErasureRequest describes an accepted erasure in the API
+type ErasureRequest struct {
+	JobID       string    `json:"job_id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// GDPRHandler handles data subject requests on users
+type GDPRHandler struct {
+	*handlers.BaseHandler
+	gdprService service.GDPRService
+}
+
+// NewGDPRHandler creates a new GDPR handler
+func NewGDPRHandler(base *handlers.BaseHandler, gdprService service.GDPRService) *GDPRHandler {
+	return &GDPRHandler{
+		BaseHandler: base,
+		gdprService: gdprService,
+	}
+}
+
+// ExportData returns all data held about a user as a JSON attachment
+// The export is a file rather than an API resource, so it is not wrapped in the response envelope.
+func (h *GDPRHandler) ExportData(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+	logger.Debug("Exporting user data")
+
+	export, err := h.gdprService.Export(context.Background(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			logger.Warn("User not found for data export")
+			response.NotFound(c, "User not found")
+			return
+		}
+		logger.Error("Failed to export user data", zap.Error(err))
+		response.InternalServerError(c, "Failed to export user data")
+		return
+	}
+
+	filename := fmt.Sprintf("user-%s-%s.json", id, export.ExportedAt.UTC().Format("20060102T150405Z"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.JSON(http.StatusOK, export)
+}
+
+// EraseData accepts a request to erase all data held about a user
+// The erasure runs as a background job; the response carries its ID.
+func (h *GDPRHandler) EraseData(c *gin.Context) {
+	id := c.Param("id")
+	logger := h.GetRequestLogger(c).With(zap.String("userId", id))
+	logger.Debug("Requesting user data erasure")
+
+	job, err := h.gdprService.RequestErasure(c.Request.Context(), id)
+	if err != nil {
+		if err == service.ErrUserNotFound {
+			logger.Warn("User not found for data erasure")
+			response.NotFound(c, "User not found")
+			return
+		}
+		logger.Error("Failed to request user data erasure", zap.Error(err))
+		response.InternalServerError(c, "Failed to request user data erasure")
+		return
+	}
+
+	logger.Info("User data erasure accepted", zap.String("jobId", job.ID))
+	response.Accepted(c, ErasureRequest{
+		JobID:       job.ID,
+		Status:      job.Status,
+		RequestedAt: job.CreatedAt,
+	})
+}
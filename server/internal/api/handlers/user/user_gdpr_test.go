@@ -0,0 +1,114 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/gdpr"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/service"
+)
+
+func setupGDPRHandler(t *testing.T) (*gin.Engine, *mocks.GDPRService) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := mocks.NewGDPRService(t)
+	handler := NewGDPRHandler(handlers.NewBaseHandler(nil), mockService)
+
+	router := gin.New()
+	router.GET("/api/v1/users/:id/data-export", handler.ExportData)
+	router.DELETE("/api/v1/users/:id/gdpr", handler.EraseData)
+	return router, mockService
+}
+
+func TestGDPRHandler_ExportData(t *testing.T) {
+	exportedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupGDPRHandler(t)
+		mockService.EXPECT().Export(mock.Anything, "user-1").Return(&gdpr.Export{
+			UserID:      "user-1",
+			ExportedAt:  exportedAt,
+			Collections: map[string]interface{}{"users": map[string]string{"name": "Test User"}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1/data-export", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="user-user-1-20240102T030405Z.json"`, w.Header().Get("Content-Disposition"))
+		assert.JSONEq(t, `{
+			"user_id": "user-1",
+			"exported_at": "2024-01-02T03:04:05Z",
+			"collections": {"users": {"name": "Test User"}}
+		}`, w.Body.String())
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		router, mockService := setupGDPRHandler(t)
+		mockService.EXPECT().Export(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/missing/data-export", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		router, mockService := setupGDPRHandler(t)
+		mockService.EXPECT().Export(mock.Anything, "user-1").Return(nil, errors.New("boom"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1/data-export", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGDPRHandler_EraseData(t *testing.T) {
+	t.Run("Accepted", func(t *testing.T) {
+		router, mockService := setupGDPRHandler(t)
+		mockService.EXPECT().RequestErasure(mock.Anything, "user-1").Return(&repository.Job{
+			ID:        "job-1",
+			Status:    repository.JobPending,
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/users/user-1/gdpr", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		require.True(t, responseObj.Success)
+		data := responseObj.Data.(map[string]interface{})
+		assert.Equal(t, "job-1", data["job_id"])
+		assert.Equal(t, repository.JobPending, data["status"])
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		router, mockService := setupGDPRHandler(t)
+		mockService.EXPECT().RequestErasure(mock.Anything, "missing").Return(nil, service.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/v1/users/missing/gdpr", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -4,6 +4,7 @@ package user
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -19,8 +20,14 @@ type User struct {
 	ID    string `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email,omitempty"`
+
+	// Avatar holds the URL of each avatar variant by its size in pixels; read-only
+	Avatar map[string]string `json:"avatar,omitempty"`
 }
 
+// selectableFields lists the fields clients may request via ?fields=
+var selectableFields = []string{"id", "name", "email", "avatar"}
+
 // Handler handles user-related requests
 type Handler struct {
 	*handlers.BaseHandler
@@ -35,33 +42,124 @@ func NewHandler(base *handlers.BaseHandler, userService service.UserService) *Ha
 	}
 }
 
+// userListSpec whitelists the filters and sorts of the user list
+var userListSpec = handlers.ListSpec{
+	Filters: []string{"q", "name", "email", "createdAfter", "createdBefore"},
+	Sorts:   domain.UserSortFields,
+}
+
 // ListUsers returns a list of users
+// With any filter, sort or pagination parameter it returns a page of matching users,
+// e.g. ?q=ada&createdAfter=2024-01-01&sort=-created_at&page=2; otherwise all users.
 func (h *Handler) ListUsers(c *gin.Context) {
 	logger := h.GetRequestLogger(c)
 	logger.Debug("Listing users")
 
+	fields, err := h.GetFields(c, selectableFields...)
+	if err != nil {
+		logger.Warn("Invalid fields parameter", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	query, err := h.GetListQuery(c, userListSpec)
+	if err != nil {
+		logger.Warn("Invalid list parameters", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+	if query.Paged {
+		h.searchUsers(c, query, fields)
+		return
+	}
+
 	// Use service to get users
-	domainUsers, err := h.userService.List(context.Background())
+	domainUsers, err := h.userService.List(context.Background(), fields...)
 	if err != nil {
 		logger.Error("Failed to list users", zap.Error(err))
 		response.InternalServerError(c, "Failed to list users")
 		return
 	}
 
-	// Convert domain users to API users
+	users := toAPIUsers(domainUsers)
+	response.SuccessWithMeta(c, gin.H{
+		"users": response.SelectFields(users, fields),
+		"count": len(users),
+	}, response.NewMeta(c), response.SelfLink(c))
+}
+
+// searchUsers responds with the page of users selected by query
+func (h *Handler) searchUsers(c *gin.Context, query *handlers.ListQuery, fields []string) {
+	logger := h.GetRequestLogger(c)
+
+	filter, err := parseUserFilter(query.Filters)
+	if err != nil {
+		logger.Warn("Invalid user filter", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
+	domainUsers, total, err := h.userService.Search(c.Request.Context(), filter, query.Options())
+	if errors.Is(err, service.ErrInvalidUser) {
+		logger.Warn("Invalid user search", zap.Error(err))
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to search users", zap.Error(err))
+		response.InternalServerError(c, "Failed to list users")
+		return
+	}
+
+	users := toAPIUsers(domainUsers)
+	response.Paginated(c, gin.H{
+		"users": response.SelectFields(users, fields),
+		"count": len(users),
+	}, query.Page, query.Limit, total)
+}
+
+// parseUserFilter converts the whitelisted filter parameters into a user filter
+func parseUserFilter(params map[string]string) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		Query: params["q"],
+		Name:  params["name"],
+	}
+
+	var err error
+	if raw, ok := params["email"]; ok {
+		if filter.Email, err = domain.ParseEmail(raw); err != nil {
+			return filter, invalidField("email", err)
+		}
+	}
+	if raw, ok := params["createdAfter"]; ok {
+		if filter.CreatedAfter, err = handlers.ParseTimeParam("createdAfter", raw); err != nil {
+			return filter, err
+		}
+	}
+	if raw, ok := params["createdBefore"]; ok {
+		if filter.CreatedBefore, err = handlers.ParseTimeParam("createdBefore", raw); err != nil {
+			return filter, err
+		}
+	}
+	if !filter.CreatedAfter.IsZero() && !filter.CreatedBefore.IsZero() && !filter.CreatedAfter.Before(filter.CreatedBefore) {
+		return filter, errors.BadRequest("createdAfter must be before createdBefore")
+	}
+
+	return filter, nil
+}
+
+// toAPIUsers converts domain users to API users
+func toAPIUsers(domainUsers []*domain.User) []User {
 	users := make([]User, 0, len(domainUsers))
 	for _, domainUser := range domainUsers {
 		users = append(users, User{
-			ID:    domainUser.ID,
-			Name:  domainUser.Name,
-			Email: domainUser.Email,
+			ID:     domainUser.ID,
+			Name:   domainUser.Name.String(),
+			Email:  domainUser.Email.String(),
+			Avatar: avatarURLs(domainUser),
 		})
 	}
-
-	response.Success(c, gin.H{
-		"users": users,
-		"count": len(users),
-	})
+	return users
 }
 
 // GetUser returns a user by ID
@@ -77,8 +175,15 @@ func (h *Handler) GetUser(c *gin.Context) {
 		return
 	}
 
+	fields, err := h.GetFields(c, selectableFields...)
+	if err != nil {
+		logger.Warn("Invalid fields parameter", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
 	// Use service to get user
-	domainUser, err := h.userService.GetByID(context.Background(), id)
+	domainUser, err := h.userService.GetByID(context.Background(), id, fields...)
 	if err != nil {
 		// Handle different types of errors
 		if err == service.ErrUserNotFound {
@@ -93,12 +198,18 @@ func (h *Handler) GetUser(c *gin.Context) {
 
 	// Convert domain user to API user
 	user := User{
-		ID:    domainUser.ID,
-		Name:  domainUser.Name,
-		Email: domainUser.Email,
+		ID:     domainUser.ID,
+		Name:   domainUser.Name.String(),
+		Email:  domainUser.Email.String(),
+		Avatar: avatarURLs(domainUser),
+	}
+
+	// Only the full representation is tied to the document version
+	if len(fields) == 0 {
+		response.SetVersionETag(c, domainUser.Version)
 	}
 
-	response.Success(c, user)
+	response.Success(c, response.SelectFields(user, fields))
 }
 
 // CreateUser creates a new user
@@ -109,34 +220,50 @@ func (h *Handler) CreateUser(c *gin.Context) {
 	var userRequest User
 	if !h.ShouldBindJSON(c, &userRequest) {
 		logger.Warn("Invalid request body")
-		response.BadRequest(c, "Invalid request body")
 		return
 	}
 
-	// Validate user input
-	if userRequest.Name == "" {
-		err := &errors.AppError{
-			StatusCode: http.StatusBadRequest,
-			Message:    "Name is required",
-		}
-		err.WithContext("field", "name")
-		response.Fail(c, err)
+	// Validate user input; an email is only checked when given
+	name, err := domain.ParseUserName(userRequest.Name)
+	if err != nil {
+		logger.Warn("Invalid user name", zap.Error(err))
+		response.Fail(c, invalidField("name", err))
 		return
 	}
+	var email domain.Email
+	if userRequest.Email != "" {
+		if email, err = domain.ParseEmail(userRequest.Email); err != nil {
+			logger.Warn("Invalid email", zap.Error(err))
+			response.Fail(c, invalidField("email", err))
+			return
+		}
+	}
 
-	// Convert API user to domain user
-	domainUser := domain.NewUser(userRequest.Name, userRequest.Email)
+	// Convert API user to domain user; the service assigns its ID and timestamps
+	domainUser := &domain.User{
+		Name:  name,
+		Email: email,
+	}
 
 	// Use service to create user
-	err := h.userService.Create(context.Background(), domainUser)
+	err = h.userService.Create(context.Background(), domainUser)
+	var conflict *service.ConflictError
+	if errors.As(err, &conflict) {
+		logger.Warn("User already exists", zap.String("field", conflict.Field))
+		response.Fail(c, conflictField(conflict))
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to create user", zap.Error(err))
 		response.InternalServerError(c, "Failed to create user")
 		return
 	}
 
-	// Return created user
+	// Return created user as stored, with its normalized fields
 	userRequest.ID = domainUser.ID
+	userRequest.Avatar = nil
+	userRequest.Name = name.String()
+	userRequest.Email = email.String()
 	logger.Info("User created", zap.String("userId", userRequest.ID))
 	response.Created(c, userRequest)
 }
@@ -157,13 +284,36 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 	var userRequest User
 	if !h.ShouldBindJSON(c, &userRequest) {
 		logger.Warn("Invalid request body")
-		response.BadRequest(c, "Invalid request body")
 		return
 	}
 
 	// Set the ID from the path parameter
 	userRequest.ID = id
 
+	// Validate user input; the email is optional and kept when omitted
+	name, err := domain.ParseUserName(userRequest.Name)
+	if err != nil {
+		logger.Warn("Invalid user name", zap.Error(err))
+		response.Fail(c, invalidField("name", err))
+		return
+	}
+	var email domain.Email
+	if userRequest.Email != "" {
+		if email, err = domain.ParseEmail(userRequest.Email); err != nil {
+			logger.Warn("Invalid email", zap.Error(err))
+			response.Fail(c, invalidField("email", err))
+			return
+		}
+	}
+
+	// Honor If-Match so concurrent edits don't silently overwrite each other
+	expectedVersion, hasPrecondition, err := h.GetIfMatchVersion(c)
+	if err != nil {
+		logger.Warn("Invalid If-Match header", zap.Error(err))
+		response.Fail(c, err)
+		return
+	}
+
 	// Get existing user
 	existingUser, err := h.userService.GetByID(context.Background(), id)
 	if err != nil {
@@ -177,21 +327,53 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if hasPrecondition && existingUser.Version != expectedVersion {
+		logger.Warn("If-Match precondition failed",
+			zap.Int64("expectedVersion", expectedVersion),
+			zap.Int64("currentVersion", existingUser.Version),
+		)
+		response.PreconditionFailed(c, "User has been modified since it was retrieved")
+		return
+	}
+
 	// Update user fields
-	existingUser.Name = userRequest.Name
-	if userRequest.Email != "" {
-		existingUser.Email = userRequest.Email
+	existingUser.Name = name
+	if email != "" {
+		existingUser.Email = email
 	}
+	userRequest.Name = existingUser.Name.String()
+	userRequest.Email = existingUser.Email.String()
 
 	// Use service to update user
 	err = h.userService.Update(context.Background(), existingUser)
+	if err == service.ErrVersionConflict {
+		logger.Warn("User modified concurrently")
+		if hasPrecondition {
+			response.PreconditionFailed(c, "User has been modified since it was retrieved")
+			return
+		}
+		response.Fail(c, &errors.AppError{
+			StatusCode: http.StatusConflict,
+			Message:    "User was modified concurrently, please retry",
+			Original:   errors.ErrConflict,
+		})
+		return
+	}
+	var conflict *service.ConflictError
+	if errors.As(err, &conflict) {
+		logger.Warn("User already exists", zap.String("field", conflict.Field))
+		response.Fail(c, conflictField(conflict))
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to update user", zap.Error(err))
 		response.InternalServerError(c, "Failed to update user")
 		return
 	}
 
+	userRequest.Avatar = avatarURLs(existingUser)
 	logger.Info("User updated", zap.String("userId", userRequest.ID))
+	response.SetVersionETag(c, existingUser.Version)
 	response.Success(c, userRequest)
 }
 
@@ -224,3 +406,32 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	logger.Info("User deleted", zap.String("userId", id))
 	response.NoContent(c)
 }
+
+// invalidField returns the 400 error for a request field the domain rejected, with the client
+// message of its *domain.ValidationError
+func invalidField(field string, err error) *errors.AppError {
+	message := "Invalid " + field
+	var invalid *domain.ValidationError
+	if errors.As(err, &invalid) {
+		message = invalid.Message
+	}
+
+	appErr := &errors.AppError{
+		StatusCode: http.StatusBadRequest,
+		Message:    message,
+		Original:   errors.ErrBadRequest,
+	}
+	return appErr.WithContext("field", field)
+}
+
+// conflictField returns the 409 error reporting that another user has the value of conflict's field,
+// with a code such as EMAIL_ALREADY_EXISTS
+func conflictField(conflict *service.ConflictError) *errors.AppError {
+	appErr := &errors.AppError{
+		StatusCode: http.StatusConflict,
+		Code:       strings.ToUpper(conflict.Field) + "_ALREADY_EXISTS",
+		Message:    conflict.Error(),
+		Original:   errors.ErrConflict,
+	}
+	return appErr.WithContext("field", conflict.Field)
+}
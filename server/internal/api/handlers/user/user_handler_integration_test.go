@@ -1,8 +1,11 @@
 package user_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"image"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,10 +13,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
 	"quizizz.com/internal/api/response"
 	"quizizz.com/internal/domain"
 	"quizizz.com/internal/service"
 	"quizizz.com/internal/testutil/integration"
+	"quizizz.com/pkg/correlation"
 )
 
 func TestIntegration_UserAPI(t *testing.T) {
@@ -76,10 +81,8 @@ func TestIntegration_UserAPI(t *testing.T) {
 		env := integration.Setup(t)
 		defer env.Cleanup()
 
-		// First, create a user to ensure we have at least one
-		user := domain.NewUser("List Test User", "list@example.com")
-		err := env.UserService.Create(context.Background(), user)
-		require.NoError(t, err)
+		// Seed a few users to list
+		seeded := env.SeedUsers(t, 5)
 
 		// GET request to list users
 		w := httptest.NewRecorder()
@@ -91,7 +94,7 @@ func TestIntegration_UserAPI(t *testing.T) {
 
 		// Parse response
 		var listResp response.Response
-		err = json.Unmarshal(w.Body.Bytes(), &listResp)
+		err := json.Unmarshal(w.Body.Bytes(), &listResp)
 		require.NoError(t, err)
 
 		// Check that we have users
@@ -104,7 +107,7 @@ func TestIntegration_UserAPI(t *testing.T) {
 
 		count, ok := data["count"].(float64)
 		require.True(t, ok)
-		assert.True(t, count > 0)
+		assert.Equal(t, float64(len(seeded)), count)
 	})
 
 	// Test updating a user
@@ -114,7 +117,7 @@ func TestIntegration_UserAPI(t *testing.T) {
 		defer env.Cleanup()
 
 		// First, create a user
-		user := domain.NewUser("Update Test User", "update@example.com")
+		user := domain.NewUser(env.IDs, "Update Test User", "update@example.com", env.Clock.Now())
 		err := env.UserService.Create(context.Background(), user)
 		require.NoError(t, err)
 
@@ -136,8 +139,8 @@ func TestIntegration_UserAPI(t *testing.T) {
 		// Get the updated user
 		updatedUser, err := env.UserService.GetByID(context.Background(), user.ID)
 		require.NoError(t, err)
-		assert.Equal(t, "Updated User", updatedUser.Name)
-		assert.Equal(t, "updated@example.com", updatedUser.Email)
+		assert.Equal(t, domain.UserName("Updated User"), updatedUser.Name)
+		assert.Equal(t, domain.Email("updated@example.com"), updatedUser.Email)
 	})
 
 	// Test deleting a user
@@ -147,7 +150,7 @@ func TestIntegration_UserAPI(t *testing.T) {
 		defer env.Cleanup()
 
 		// First, create a user
-		user := domain.NewUser("Delete Test User", "delete@example.com")
+		user := domain.NewUser(env.IDs, "Delete Test User", "delete@example.com", env.Clock.Now())
 		err := env.UserService.Create(context.Background(), user)
 		require.NoError(t, err)
 
@@ -164,4 +167,449 @@ func TestIntegration_UserAPI(t *testing.T) {
 		assert.Equal(t, service.ErrUserNotFound, err)
 		assert.Nil(t, deletedUser)
 	})
+
+	// Test searching users with filters and pagination
+	t.Run("Search users", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		for _, name := range []string{"Search Ada", "Search Alan", "Search Grace"} {
+			email := domain.Email(strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com")
+			user := domain.NewUser(env.IDs, domain.UserName(name), email, env.Clock.Now())
+			require.NoError(t, env.UserService.Create(context.Background(), user))
+		}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users?q=grace&sort=name&limit=1", nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var responseObj response.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &responseObj))
+		data := responseObj.Data.(map[string]interface{})
+		users := data["users"].([]interface{})
+		require.Len(t, users, 1)
+		assert.Equal(t, "Search Grace", users[0].(map[string]interface{})["name"])
+		assert.Equal(t, int64(1), responseObj.Meta.Pagination.Total)
+	})
+
+	// Test HEAD, OPTIONS and unsupported methods
+	t.Run("HEAD and OPTIONS", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+		env.SeedUsers(t, 1)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("HEAD", "/api/v1/users", nil)
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("OPTIONS", "/api/v1/users/abc", nil)
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET, HEAD, PUT, DELETE, OPTIONS", w.Header().Get("Allow"))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PATCH", "/api/v1/users/abc", nil)
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "GET, HEAD, PUT, DELETE", w.Header().Get("Allow"))
+
+		// Module routes get HEAD too
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("HEAD", "/api/v1/ping", nil)
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	// Test creating a user with an email another user has
+	t.Run("Create user with existing email", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		user := domain.NewUser(env.IDs, "Existing User", "existing@example.com", env.Clock.Now())
+		require.NoError(t, env.UserService.Create(context.Background(), user))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users", strings.NewReader(`{"name":"New User","email":"existing@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "EMAIL_ALREADY_EXISTS")
+	})
+
+	// Test the data export and erasure of a user
+	t.Run("Export and erase user data", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		user := domain.NewUser(env.IDs, "GDPR Test User", "gdpr@example.com", env.Clock.Now())
+		require.NoError(t, env.UserService.Create(context.Background(), user))
+
+		// Export the user's data
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/"+user.ID+"/data-export", nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var export struct {
+			UserID      string                            `json:"user_id"`
+			Collections map[string]map[string]interface{} `json:"collections"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+		assert.Equal(t, user.ID, export.UserID)
+		assert.Equal(t, "gdpr@example.com", export.Collections["users"]["email"])
+
+		// Request the erasure, which is accepted before it runs
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("DELETE", "/api/v1/users/"+user.ID+"/gdpr", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		processed, err := env.Jobs.RunOnce(context.Background())
+		require.NoError(t, err)
+		require.True(t, processed)
+
+		_, err = env.UserService.GetByID(context.Background(), user.ID)
+		assert.Equal(t, service.ErrUserNotFound, err)
+
+		records, err := env.Audit.ListBySubject(context.Background(), user.ID)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "192.0.2.1", records[0].ClientIP)
+		assert.Empty(t, records[1].ClientIP)
+	})
+
+	// Test listing, refreshing and revoking the current user's sessions
+	t.Run("Sessions", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		session, token, err := env.Sessions.Issue(context.Background(), "user-1", "Pixel 8", "app/1.0")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/me/sessions", nil)
+		// Authentication sets the current user in the request context
+		req = req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: "user-1"}))
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), session.ID)
+
+		// Refreshing rotates the token; the old one is then reuse and revokes the session
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/sessions/refresh", strings.NewReader(`{"refresh_token":"`+token+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/sessions/refresh", strings.NewReader(`{"refresh_token":"`+token+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("DELETE", "/api/v1/users/me/sessions/"+session.ID, nil)
+		req = req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: "user-1"}))
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		ctx := context.Background()
+		adminUser := &domain.User{Name: "Ada Admin", Email: "ada@example.com", Roles: []string{"admin"}}
+		require.NoError(t, env.UserService.Create(ctx, adminUser))
+		member := &domain.User{Name: "Uma User", Email: "uma@example.com"}
+		require.NoError(t, env.UserService.Create(ctx, member))
+
+		as := func(req *http.Request, userID string) *http.Request {
+			return req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: userID}))
+		}
+
+		// Users without the role are forbidden
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/admin/users", nil)
+		env.Router.ServeHTTP(w, as(req, member.ID))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/admin/users/"+member.ID+"/lock", strings.NewReader(`{"reason":"spam"}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, as(req, adminUser.ID))
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/admin/users?locked=true", nil)
+		env.Router.ServeHTTP(w, as(req, adminUser.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), member.ID)
+		assert.NotContains(t, w.Body.String(), adminUser.ID)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/admin/stats", nil)
+		env.Router.ServeHTTP(w, as(req, adminUser.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total":2`)
+		assert.Contains(t, w.Body.String(), `"locked":1`)
+	})
+
+	t.Run("Impersonation", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		ctx := context.Background()
+		adminUser := &domain.User{Name: "Ada Admin", Email: "ada@example.com", Roles: []string{"admin"}}
+		require.NoError(t, env.UserService.Create(ctx, adminUser))
+		member := &domain.User{Name: "Uma User", Email: "uma@example.com"}
+		require.NoError(t, env.UserService.Create(ctx, member))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/admin/users/"+member.ID+"/impersonate", nil)
+		req = req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: adminUser.ID}))
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var body struct {
+			Data struct {
+				Token string `json:"token"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+		// The token makes requests on behalf of the user, audited with the administrator as actor
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/users/me/sessions", nil)
+		req.Header.Set("Authorization", "Impersonation "+body.Data.Token)
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		records, err := env.Audit.ListBySubject(ctx, member.ID)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "admin.impersonation_started", records[0].Action)
+		assert.Equal(t, "impersonation.request", records[1].Action)
+		assert.Equal(t, member.ID, records[1].UserID)
+		assert.Equal(t, adminUser.ID, records[1].ActorID)
+	})
+
+	t.Run("Preferences", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		user := &domain.User{Name: "Pat Prefs", Email: "pat@example.com"}
+		require.NoError(t, env.UserService.Create(context.Background(), user))
+		path := "/api/v1/users/" + user.ID + "/preferences"
+
+		// Users start with the defaults at version 0
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"v0"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"theme":"system"`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PUT", path, strings.NewReader(`{"theme":"dark","notifications":{"digest":"off"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"v0"`)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", path, nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"theme":"dark"`)
+		assert.Contains(t, w.Body.String(), `"digest":"off"`)
+		assert.Contains(t, w.Body.String(), `"email":true`)
+
+		// Writes against a stale version are rejected
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PUT", path, strings.NewReader(`{"theme":"light"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"v0"`)
+		env.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("Avatar", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		user := &domain.User{Name: "Ava Tar", Email: "ava@example.com"}
+		require.NoError(t, env.UserService.Create(context.Background(), user))
+
+		img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+		var upload bytes.Buffer
+		require.NoError(t, png.Encode(&upload, img))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/"+user.ID+"/avatar", &upload)
+		req.Header.Set("Content-Type", "image/png")
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusAccepted, w.Code)
+
+		processed, err := env.Jobs.RunOnce(context.Background())
+		require.NoError(t, err)
+		require.True(t, processed)
+
+		// The user resource links the variants, which are served as WebP
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/users/"+user.ID, nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data struct {
+				Avatar map[string]string `json:"avatar"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Contains(t, body.Data.Avatar, "128")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", body.Data.Avatar["128"], nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/webp", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Cache-Control"), "max-age=31536000")
+		cfg, err := webp.DecodeConfig(w.Body)
+		require.NoError(t, err)
+		assert.Equal(t, 128, cfg.Width)
+	})
+
+	t.Run("Devices", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		ctx := context.Background()
+		adminUser := &domain.User{Name: "Ada Admin", Email: "ada@example.com", Roles: []string{"admin"}}
+		require.NoError(t, env.UserService.Create(ctx, adminUser))
+		user := &domain.User{Name: "Dev Ice", Email: "dev@example.com"}
+		require.NoError(t, env.UserService.Create(ctx, user))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users/"+user.ID+"/devices", strings.NewReader(`{"platform":"android","token":"fcm-token"}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var device struct {
+			Data struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &device))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("PUT", "/api/v1/users/"+user.ID+"/devices/"+device.Data.ID+"/topics/news", nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		// An administrator sends to the topic; the notification is delivered by a job
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/admin/topics/news/notifications", strings.NewReader(`{"title":"Breaking","body":"News"}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: adminUser.ID})))
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var notification struct {
+			Data struct {
+				ID string `json:"notification_id"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &notification))
+
+		processed, err := env.Jobs.RunOnce(ctx)
+		require.NoError(t, err)
+		require.True(t, processed)
+		require.Len(t, env.Push.Sent(), 1)
+		assert.Equal(t, "fcm-token", env.Push.Sent()[0].Token)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/admin/notifications/"+notification.Data.ID, nil)
+		env.Router.ServeHTTP(w, req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: adminUser.ID})))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"succeeded"`)
+		assert.Contains(t, w.Body.String(), `"delivered":1`)
+	})
+
+	t.Run("Summaries", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		ctx := context.Background()
+		user := &domain.User{Name: "Sum Mary", Email: "summary@example.com"}
+		require.NoError(t, env.UserService.Create(ctx, user))
+
+		// The summary is computed on first read, before the read model caught up
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/"+user.ID+"/summary", nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"sessions":0`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/users/summaries?sort=name", nil)
+		env.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"`+user.ID+`"`)
+	})
+
+	t.Run("Dead jobs", func(t *testing.T) {
+		env := integration.Setup(t)
+		defer env.Cleanup()
+
+		ctx := context.Background()
+		adminUser := &domain.User{Name: "Ada Admin", Email: "ada@example.com", Roles: []string{"admin"}}
+		require.NoError(t, env.UserService.Create(ctx, adminUser))
+		as := func(req *http.Request) *http.Request {
+			return req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: adminUser.ID}))
+		}
+
+		// A push job with neither a user nor a topic fails permanently
+		job, err := env.Jobs.Enqueue(ctx, service.PushJobType, map[string]string{})
+		require.NoError(t, err)
+		_, err = env.Jobs.RunOnce(ctx)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/admin/jobs/dead?type="+service.PushJobType, nil)
+		env.Router.ServeHTTP(w, as(req))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), job.ID)
+		assert.Contains(t, w.Body.String(), "must have either a user or a topic")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/admin/jobs/stats?window=15m", nil)
+		env.Router.ServeHTTP(w, as(req))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"window":"15m0s"`)
+		assert.Contains(t, w.Body.String(), `"failure_ratio":1`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/v1/admin/jobs/dead/"+job.ID+"/replay", nil)
+		env.Router.ServeHTTP(w, as(req))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"pending"`)
+
+		_, err = env.Jobs.RunOnce(ctx)
+		require.NoError(t, err)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("DELETE", "/api/v1/admin/jobs/dead/"+job.ID, nil)
+		env.Router.ServeHTTP(w, as(req))
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/admin/jobs/dead/"+job.ID, nil)
+		env.Router.ServeHTTP(w, as(req))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
@@ -2,12 +2,14 @@ package user
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -15,61 +17,20 @@ import (
 	"github.com/stretchr/testify/require"
 	"quizizz.com/internal/api/handlers"
 	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/api/versioning"
 	"quizizz.com/internal/domain"
+	"quizizz.com/internal/mocks"
 	"quizizz.com/internal/service"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/middleware"
 )
 
-// Mock implementations
-type MockAppService struct {
-	mock.Mock
-}
-
-func (m *MockAppService) GetPingMessage() string {
-	args := m.Called()
-	return args.String(0)
-}
-
-type MockUserService struct {
-	mock.Mock
-}
-
-func (m *MockUserService) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	args := m.Called(ctx, id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*domain.User), args.Error(1)
-}
-
-func (m *MockUserService) List(ctx context.Context) ([]*domain.User, error) {
-	args := m.Called(ctx)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*domain.User), args.Error(1)
-}
-
-func (m *MockUserService) Create(ctx context.Context, user *domain.User) error {
-	args := m.Called(ctx, user)
-	return args.Error(0)
-}
-
-func (m *MockUserService) Update(ctx context.Context, user *domain.User) error {
-	args := m.Called(ctx, user)
-	return args.Error(0)
-}
-
-func (m *MockUserService) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
-
 // Setup test function
-func setupUserHandler() (*Handler, *MockAppService, *MockUserService) {
+func setupUserHandler() (*Handler, *mocks.AppService, *mocks.UserService) {
 	gin.SetMode(gin.TestMode)
 
-	mockAppService := new(MockAppService)
-	mockUserService := new(MockUserService)
+	mockAppService := new(mocks.AppService)
+	mockUserService := new(mocks.UserService)
 
 	baseHandler := handlers.NewBaseHandler(mockAppService)
 	handler := NewHandler(baseHandler, mockUserService)
@@ -77,6 +38,19 @@ func setupUserHandler() (*Handler, *MockAppService, *MockUserService) {
 	return handler, mockAppService, mockUserService
 }
 
+// expectExport makes Export stream users to the export callback, then return err
+func expectExport(m *mocks.UserService, filter domain.UserFilter, users []*domain.User, err error) {
+	m.EXPECT().Export(mock.Anything, filter, mock.Anything).
+		RunAndReturn(func(_ context.Context, _ domain.UserFilter, fn func(*domain.User) error) error {
+			for _, user := range users {
+				if err := fn(user); err != nil {
+					return err
+				}
+			}
+			return err
+		})
+}
+
 // Helper functions
 func createTestRouter(handler *Handler) *gin.Engine {
 	router := gin.New()
@@ -84,6 +58,7 @@ func createTestRouter(handler *Handler) *gin.Engine {
 	// Add the logger for testing
 	router.Use(func(c *gin.Context) {
 		c.Set("requestID", "test-request-id")
+		c.Set("requestStart", time.Now())
 		c.Next()
 	})
 
@@ -92,11 +67,18 @@ func createTestRouter(handler *Handler) *gin.Engine {
 	{
 		users.GET("", handler.ListUsers)
 		users.POST("", handler.CreateUser)
+		users.GET("/export", handler.ExportUsers)
 		users.GET("/:id", handler.GetUser)
 		users.PUT("/:id", handler.UpdateUser)
 		users.DELETE("/:id", handler.DeleteUser)
 	}
 
+	// v2 serves the same handlers through its response transformer
+	usersV2 := router.Group("/api/v2/users", versioning.Use(versioning.V2))
+	{
+		usersV2.GET("", handler.ListUsers)
+	}
+
 	return router
 }
 
@@ -159,6 +141,42 @@ func TestHandler_ListUsers(t *testing.T) {
 		require.True(t, ok, "Count is not a number")
 		assert.Equal(t, float64(2), count)
 
+		// Check metadata and links; timing is reported in a header, keeping the body stable
+		require.NotNil(t, responseObj.Meta)
+		assert.Equal(t, "test-request-id", responseObj.Meta.RequestID)
+		require.NotNil(t, responseObj.Links)
+		assert.Equal(t, "/api/v1/users", responseObj.Links.Self)
+		assert.Regexp(t, `^app;dur=[0-9.]+$`, w.Header().Get("Server-Timing"))
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("V2 field naming", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations
+		mockUserService.On("List", mock.Anything).Return([]*domain.User{{ID: "user-1", Name: "User 1"}}, nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v2/users", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, string(versioning.V2), w.Header().Get(versioning.HeaderName))
+
+		// snake_case keys of the v1 payload are camelCase in v2
+		var body map[string]interface{}
+		parseResponse(t, w, &body)
+		meta, ok := body["meta"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "test-request-id", meta["requestId"])
+		assert.NotContains(t, meta, "request_id")
+
 		// Verify mock expectations
 		mockUserService.AssertExpectations(t)
 	})
@@ -193,6 +211,253 @@ func TestHandler_ListUsers(t *testing.T) {
 	})
 }
 
+func TestHandler_SearchUsers(t *testing.T) {
+	t.Run("Filters, sorting and pagination", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations; page 2 of 10 starts at the 11th user
+		filter := domain.UserFilter{
+			Query:         "ada",
+			Email:         "ada@example.com",
+			CreatedAfter:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedBefore: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		}
+		opts := domain.ListOptions{Sort: domain.UserSortName, Descending: true, Offset: 10, Limit: 10}
+		mockUserService.EXPECT().Search(mock.Anything, filter, opts).
+			Return([]*domain.User{{ID: "user-1", Name: "Ada", Email: "ada@example.com"}}, int64(11), nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users?q=ada&email=ada@example.com&createdAfter=2024-01-01"+
+			"&createdBefore=2024-02-01T12:00:00Z&sort=-name&page=2&limit=10", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		data := responseObj.Data.(map[string]interface{})
+		assert.Len(t, data["users"], 1)
+
+		require.NotNil(t, responseObj.Meta)
+		require.NotNil(t, responseObj.Meta.Pagination)
+		assert.Equal(t, 2, responseObj.Meta.Pagination.Page)
+		assert.Equal(t, int64(11), responseObj.Meta.Pagination.Total)
+		assert.Equal(t, 2, responseObj.Meta.Pagination.TotalPages)
+		require.NotNil(t, responseObj.Links)
+		assert.Contains(t, responseObj.Links.Prev, "page=1")
+		assert.Empty(t, responseObj.Links.Next)
+	})
+
+	t.Run("Default page", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		mockUserService.EXPECT().Search(mock.Anything, domain.UserFilter{Name: "ada"}, domain.ListOptions{Limit: handlers.DefaultListLimit}).
+			Return([]*domain.User{}, int64(0), nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users?name=ada", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+		param string
+	}{
+		{name: "Unknown parameter", query: "role=admin", param: "role"},
+		{name: "Unknown sort field", query: "sort=password", param: "sort"},
+		{name: "Limit too large", query: "limit=1000", param: "limit"},
+		{name: "Invalid page", query: "page=0", param: "page"},
+		{name: "Invalid time", query: "createdAfter=yesterday", param: "createdAfter"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			handler, _, mockUserService := setupUserHandler()
+			router := createTestRouter(handler)
+
+			// Perform request
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/users?"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			// Assertions
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var responseObj response.Response
+			parseResponse(t, w, &responseObj)
+			require.NotNil(t, responseObj.Error)
+			assert.Equal(t, tt.param, responseObj.Error.Details["param"])
+			mockUserService.AssertNotCalled(t, "Search")
+		})
+	}
+
+	t.Run("Empty time range", func(t *testing.T) {
+		// Setup
+		handler, _, _ := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users?createdAfter=2024-02-01&createdBefore=2024-01-01", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_ExportUsers(t *testing.T) {
+	domainUsers := []*domain.User{
+		{ID: "user-1", Name: "User 1", Email: "user1@example.com"},
+		{ID: "user-2", Name: "User 2", Email: "user2@example.com"},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations
+		filter := domain.UserFilter{Name: "user"}
+		expectExport(mockUserService, filter, domainUsers, nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/export?format=csv&name=user", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=\"users-")
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "id,name,email,created_at,updated_at", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "user-1,User 1,user1@example.com,"))
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("CSV escapes formulas", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations
+		users := []*domain.User{
+			{ID: "user-1", Name: "=HYPERLINK(\"http://evil.example\")", Email: "+1@example.com"},
+			{ID: "user-2", Name: "-2+3", Email: "@sum@example.com"},
+			{ID: "user-3", Name: "\tTabbed", Email: "a-b@example.com"},
+			{ID: "user-4", Name: "\rReturned", Email: "c=d@example.com"},
+		}
+		expectExport(mockUserService, domain.UserFilter{}, users, nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/export?format=csv", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 5)
+		assert.Equal(t, []string{"user-1", "'=HYPERLINK(\"http://evil.example\")", "'+1@example.com"}, records[1][:3])
+		assert.Equal(t, []string{"user-2", "'-2+3", "'@sum@example.com"}, records[2][:3])
+		assert.Equal(t, []string{"user-3", "'\tTabbed", "a-b@example.com"}, records[3][:3])
+		assert.Equal(t, []string{"user-4", "'\rReturned", "c=d@example.com"}, records[4][:3])
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations
+		expectExport(mockUserService, domain.UserFilter{}, domainUsers, nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/export?format=ndjson", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var first domain.User
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, "user-1", first.ID)
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/export?format=xml", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		assert.Equal(t, "Unsupported export format: xml", responseObj.Error.Message)
+
+		// Service must not be called
+		mockUserService.AssertNotCalled(t, "Export", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Too many rows", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations
+		expectExport(mockUserService, domain.UserFilter{}, nil, service.ErrExportTooLarge)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/export", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		assert.False(t, responseObj.Success)
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+}
+
 func TestHandler_GetUser(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		// Setup
@@ -233,10 +498,112 @@ func TestHandler_GetUser(t *testing.T) {
 		assert.Equal(t, "User 1", userData["name"])
 		assert.Equal(t, "user1@example.com", userData["email"])
 
+		// Every envelope carries the request ID
+		require.NotNil(t, responseObj.Meta)
+		assert.Equal(t, "test-request-id", responseObj.Meta.RequestID)
+
 		// Verify mock expectations
 		mockUserService.AssertExpectations(t)
 	})
 
+	t.Run("Sparse fieldset", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Mock data
+		user := &domain.User{
+			ID:    "user-1",
+			Name:  "User 1",
+			Email: "user1@example.com",
+		}
+
+		// Set expectations
+		mockUserService.On("GetByID", mock.Anything, "user-1", "name").Return(user, nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1?fields=name", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// Parse response
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+
+		// Only the requested fields (plus id) are returned
+		userData, ok := responseObj.Data.(map[string]interface{})
+		require.True(t, ok, "Data is not a map")
+		assert.Equal(t, "user-1", userData["id"])
+		assert.Equal(t, "User 1", userData["name"])
+		assert.NotContains(t, userData, "email")
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Without envelope", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Mock data
+		user := &domain.User{
+			ID:    "user-1",
+			Name:  "User 1",
+			Email: "user1@example.com",
+		}
+
+		// Set expectations
+		mockUserService.On("GetByID", mock.Anything, "user-1").Return(user, nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1", nil)
+		req.Header.Set(response.EnvelopeHeader, response.EnvelopeNone)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, response.EnvelopeNone, w.Header().Get(response.EnvelopeHeader))
+
+		// The resource is returned without the success/data wrapper
+		var userData map[string]interface{}
+		parseResponse(t, w, &userData)
+		assert.Equal(t, "user-1", userData["id"])
+		assert.Equal(t, "User 1", userData["name"])
+		assert.NotContains(t, userData, "success")
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/users/user-1?fields=password", nil)
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		// Parse response
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+
+		assert.False(t, responseObj.Success)
+		assert.Equal(t, "Unknown field: password", responseObj.Error.Message)
+
+		// Service must not be called for invalid input
+		mockUserService.AssertNotCalled(t, "GetByID")
+	})
+
 	t.Run("User not found", func(t *testing.T) {
 		// Setup
 		handler, _, mockUserService := setupUserHandler()
@@ -296,6 +663,38 @@ func TestHandler_GetUser(t *testing.T) {
 	})
 }
 
+func TestHandler_GetUser_Snapshots(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		id     string
+		user   *domain.User
+		err    error
+		query  string
+		fields []interface{}
+	}{
+		{name: "get_user", id: "user-1", user: &domain.User{ID: "user-1", Name: "User 1", Email: "user1@example.com", CreatedAt: created, UpdatedAt: created}},
+		{name: "get_user_sparse", id: "user-1", user: &domain.User{ID: "user-1", Name: "User 1"}, query: "?fields=id,name", fields: []interface{}{"id", "name"}},
+		{name: "get_user_not_found", id: "non-existent", err: service.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, mockUserService := setupUserHandler()
+			router := createTestRouter(handler)
+			args := append([]interface{}{mock.Anything, tt.id}, tt.fields...)
+			mockUserService.On("GetByID", args...).Return(tt.user, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/users/"+tt.id+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			testutil.AssertResponseSnapshot(t, w, tt.name)
+		})
+	}
+}
+
 func TestHandler_CreateUser(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		// Setup
@@ -369,6 +768,30 @@ func TestHandler_CreateUser(t *testing.T) {
 		assert.Equal(t, "Invalid request body", responseObj.Error.Message)
 	})
 
+	t.Run("Request body too large", func(t *testing.T) {
+		// Setup
+		handler, _, _ := setupUserHandler()
+		router := gin.New()
+		router.POST("/api/v1/users", middleware.BodyLimit(16), handler.CreateUser)
+
+		// Perform request with a body of unknown length, so the limit is hit while binding
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users", strings.NewReader(`{"name":"New User","email":"newuser@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+
+		require.NotNil(t, responseObj.Error)
+		assert.Equal(t, "PAYLOAD_TOO_LARGE", responseObj.Error.Code)
+		assert.Equal(t, float64(16), responseObj.Error.Details["limit"])
+	})
+
 	t.Run("Missing name", func(t *testing.T) {
 		// Setup
 		handler, _, _ := setupUserHandler()
@@ -396,6 +819,74 @@ func TestHandler_CreateUser(t *testing.T) {
 		assert.Equal(t, "Name is required", responseObj.Error.Message)
 	})
 
+	t.Run("Missing email", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// The email is left to the service, as before it was validated in the domain
+		mockUserService.On("Create", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
+			return user.Name == "New User" && user.Email == ""
+		})).Return(nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users", strings.NewReader(`{"name":"New User"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockUserService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		// Setup
+		handler, mockService, _ := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users", strings.NewReader(`{"name":"New User","email":"not-an-email"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		require.NotNil(t, responseObj.Error)
+		assert.Equal(t, "Email is not a valid address", responseObj.Error.Message)
+		assert.Equal(t, "email", responseObj.Error.Details["field"])
+		mockService.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("Email already exists", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Mock behavior - another user has the email
+		mockUserService.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).
+			Return(service.ErrEmailAlreadyExists)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/users", strings.NewReader(`{"name":"New User","email":"taken@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		require.NotNil(t, responseObj.Error)
+		assert.Equal(t, "EMAIL_ALREADY_EXISTS", responseObj.Error.Code)
+		assert.Equal(t, "email", responseObj.Error.Details["field"])
+	})
+
 	t.Run("Service error", func(t *testing.T) {
 		// Setup
 		handler, _, mockUserService := setupUserHandler()
@@ -432,6 +923,74 @@ func TestHandler_CreateUser(t *testing.T) {
 }
 
 func TestHandler_UpdateUser(t *testing.T) {
+	t.Run("If-Match precondition failed", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Mock data: the stored user has moved on to version 3
+		existingUser := &domain.User{
+			ID:      "user-1",
+			Name:    "Original Name",
+			Email:   "original@example.com",
+			Version: 3,
+		}
+
+		// Set expectations
+		mockUserService.On("GetByID", mock.Anything, "user-1").Return(existingUser, nil)
+
+		// Perform request with a stale version
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/user-1", strings.NewReader(`{"name":"Updated Name"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", response.VersionETag(2))
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		assert.Equal(t, "PRECONDITION_FAILED", responseObj.Error.Code)
+
+		// The update must not be attempted
+		mockUserService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("If-Match matches", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Mock data
+		existingUser := &domain.User{
+			ID:      "user-1",
+			Name:    "Original Name",
+			Email:   "original@example.com",
+			Version: 3,
+		}
+
+		// Set expectations; the service bumps the version on success
+		mockUserService.On("GetByID", mock.Anything, "user-1").Return(existingUser, nil)
+		mockUserService.On("Update", mock.Anything, mock.AnythingOfType("*domain.User")).
+			Run(func(args mock.Arguments) { args.Get(1).(*domain.User).Version++ }).
+			Return(nil)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/user-1", strings.NewReader(`{"name":"Updated Name"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", response.VersionETag(3))
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, response.VersionETag(4), w.Header().Get("ETag"))
+
+		// Verify mock expectations
+		mockUserService.AssertExpectations(t)
+	})
+
 	t.Run("Success", func(t *testing.T) {
 		// Setup
 		handler, _, mockUserService := setupUserHandler()
@@ -480,6 +1039,35 @@ func TestHandler_UpdateUser(t *testing.T) {
 		mockUserService.AssertExpectations(t)
 	})
 
+	t.Run("Email already exists", func(t *testing.T) {
+		// Setup
+		handler, _, mockUserService := setupUserHandler()
+		router := createTestRouter(handler)
+
+		// Set expectations; another user has the new email
+		mockUserService.On("GetByID", mock.Anything, "user-1").Return(&domain.User{
+			ID:    "user-1",
+			Name:  "Original Name",
+			Email: "original@example.com",
+		}, nil)
+		mockUserService.On("Update", mock.Anything, mock.AnythingOfType("*domain.User")).
+			Return(service.ErrEmailAlreadyExists)
+
+		// Perform request
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/api/v1/users/user-1", strings.NewReader(`{"name":"Updated Name","email":"taken@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		// Assertions
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		var responseObj response.Response
+		parseResponse(t, w, &responseObj)
+		require.NotNil(t, responseObj.Error)
+		assert.Equal(t, "EMAIL_ALREADY_EXISTS", responseObj.Error.Code)
+	})
+
 	t.Run("User not found", func(t *testing.T) {
 		// Setup
 		handler, _, mockUserService := setupUserHandler()
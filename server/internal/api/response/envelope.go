@@ -0,0 +1,86 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnvelopeHeader lets clients choose the response format ("none" returns raw resources)
+const EnvelopeHeader = "X-API-Envelope"
+
+// EnvelopeNone disables the success/data wrapper
+const EnvelopeNone = "none"
+
+// envelopeKey is the context key holding a route's envelope override
+const envelopeKey = "responseEnvelope"
+
+// WithoutEnvelope returns a middleware making a route respond with raw resources regardless of the header
+// Use it for routes consumed by gateways or clients that need plain payloads.
+func WithoutEnvelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(envelopeKey, EnvelopeNone)
+		c.Next()
+	}
+}
+
+// enveloped reports whether the response for c should use the standard envelope
+func enveloped(c *gin.Context) bool {
+	if mode := c.GetString(envelopeKey); mode != "" {
+		return mode != EnvelopeNone
+	}
+	return !strings.EqualFold(strings.TrimSpace(c.GetHeader(EnvelopeHeader)), EnvelopeNone)
+}
+
+// write sends resp with the given status, unwrapping it when the envelope is disabled
+// Without the envelope, successful responses carry only the data, failures only the error object,
+// and links move to a Link header. The body passes through the request's Transformer, if any.
+func write(c *gin.Context, status int, resp Response) {
+	setServerTiming(c)
+
+	if enveloped(c) {
+		// Every envelope carries the request ID so clients can quote it when reporting problems
+		if requestID := c.GetString(requestIDKey); requestID != "" {
+			if resp.Meta == nil {
+				resp.Meta = &Meta{}
+			}
+			if resp.Meta.RequestID == "" {
+				resp.Meta.RequestID = requestID
+			}
+		}
+		c.JSON(status, transform(c, resp))
+		return
+	}
+
+	c.Header(EnvelopeHeader, EnvelopeNone)
+
+	if !resp.Success {
+		c.JSON(status, transform(c, resp.Error))
+		return
+	}
+
+	if resp.Links != nil {
+		if header := linkHeader(resp.Links); header != "" {
+			c.Header("Link", header)
+		}
+	}
+	c.JSON(status, transform(c, resp.Data))
+}
+
+// linkHeader formats links as an RFC 8288 Link header
+func linkHeader(links *Links) string {
+	var parts []string
+	for _, link := range []struct{ rel, href string }{
+		{"self", links.Self},
+		{"next", links.Next},
+		{"prev", links.Prev},
+		{"first", links.First},
+		{"last", links.Last},
+	} {
+		if link.href != "" {
+			parts = append(parts, fmt.Sprintf(`<%s>; rel="%s"`, link.href, link.rel))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
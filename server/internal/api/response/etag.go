@@ -0,0 +1,32 @@
+package response
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionETag returns the strong ETag identifying version of a document
+func VersionETag(version int64) string {
+	return `"v` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseVersionETag returns the document version encoded in an ETag created by VersionETag
+func ParseVersionETag(etag string) (int64, bool) {
+	etag = strings.TrimSpace(etag)
+	if !strings.HasPrefix(etag, `"v`) || !strings.HasSuffix(etag, `"`) || len(etag) < 4 {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(etag[2:len(etag)-1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// SetVersionETag sets the ETag header of the response to the given document version
+func SetVersionETag(c *gin.Context, version int64) {
+	c.Header("ETag", VersionETag(version))
+}
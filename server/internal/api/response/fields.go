@@ -0,0 +1,50 @@
+package response
+
+import (
+	"encoding/json"
+)
+
+// SelectFields reduces data to the requested JSON fields (sparse fieldset)
+// data may be a single object or a slice of objects; the "id" field is always kept.
+// If fields is empty or data cannot be reshaped, data is returned unchanged.
+func SelectFields(data interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return data
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+
+	keep := make(map[string]struct{}, len(fields)+1)
+	keep["id"] = struct{}{}
+	for _, field := range fields {
+		keep[field] = struct{}{}
+	}
+
+	var list []map[string]interface{}
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, item := range list {
+			filterKeys(item, keep)
+		}
+		return list
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		filterKeys(object, keep)
+		return object
+	}
+
+	return data
+}
+
+// filterKeys removes every key from m that is not present in keep
+func filterKeys(m map[string]interface{}, keep map[string]struct{}) {
+	for key := range m {
+		if _, ok := keep[key]; !ok {
+			delete(m, key)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package response
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys read by the metadata helpers
+const (
+	requestIDKey    = "requestID"
+	requestStartKey = "requestStart"
+)
+
+// Meta is the optional metadata block of a response
+type Meta struct {
+	RequestID  string      `json:"request_id,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the position of a page within a collection
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// Links holds navigation links relative to the API host
+type Links struct {
+	Self  string `json:"self"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// NewMeta returns the metadata of the current request
+func NewMeta(c *gin.Context) *Meta {
+	return &Meta{
+		RequestID: c.GetString(requestIDKey),
+	}
+}
+
+// setServerTiming reports the time spent handling the request so far in the Server-Timing header
+// Timing stays out of the body so that identical representations keep identical bodies, and
+// with them their ETag and their cached or deduplicated copies.
+func setServerTiming(c *gin.Context) {
+	start, ok := c.Get(requestStartKey)
+	if !ok {
+		return
+	}
+	if t, ok := start.(time.Time); ok {
+		c.Header("Server-Timing", "app;dur="+strconv.FormatFloat(float64(time.Since(t).Microseconds())/1000, 'f', -1, 64))
+	}
+}
+
+// NewPagination returns the pagination block for a page of limit items out of total
+func NewPagination(page, limit int, total int64) *Pagination {
+	p := &Pagination{
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	}
+
+	if limit > 0 {
+		p.TotalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+
+	return p
+}
+
+// SelfLink returns links containing only the URL of the current request
+func SelfLink(c *gin.Context) *Links {
+	return &Links{Self: c.Request.URL.RequestURI()}
+}
+
+// PaginationLinks returns self/next/prev/first/last links for p, keeping the other query parameters
+func PaginationLinks(c *gin.Context, p *Pagination) *Links {
+	links := SelfLink(c)
+	if p == nil || p.Limit <= 0 {
+		return links
+	}
+
+	pageURL := func(page int) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("limit", strconv.Itoa(p.Limit))
+		return (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).RequestURI()
+	}
+
+	links.First = pageURL(1)
+	if p.TotalPages > 0 {
+		links.Last = pageURL(p.TotalPages)
+	}
+	if p.Page < p.TotalPages {
+		links.Next = pageURL(p.Page + 1)
+	}
+	if p.Page > 1 {
+		links.Prev = pageURL(p.Page - 1)
+	}
+
+	return links
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"quizizz.com/internal/errors"
+	"quizizz.com/pkg/errortracking"
 )
 
 // Response is the standard API response envelope
@@ -13,6 +14,8 @@ type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   *Error      `json:"error,omitempty"`
+	Meta    *Meta       `json:"meta,omitempty"`
+	Links   *Links      `json:"links,omitempty"`
 }
 
 // Error represents the error details in a response
@@ -24,15 +27,40 @@ type Error struct {
 
 // Success sends a successful response with data
 func Success(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Success: true,
 		Data:    data,
 	})
 }
 
+// SuccessWithMeta sends a successful response with data, metadata and navigation links
+func SuccessWithMeta(c *gin.Context, data interface{}, meta *Meta, links *Links) {
+	write(c, http.StatusOK, Response{
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+		Links:   links,
+	})
+}
+
+// Paginated sends a page of a collection with pagination metadata and self/next/prev links
+func Paginated(c *gin.Context, data interface{}, page, limit int, total int64) {
+	meta := NewMeta(c)
+	meta.Pagination = NewPagination(page, limit, total)
+	SuccessWithMeta(c, data, meta, PaginationLinks(c, meta.Pagination))
+}
+
 // Created sends a 201 created response with data
 func Created(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusCreated, Response{
+	write(c, http.StatusCreated, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// Accepted sends a 202 accepted response for work that completes asynchronously
+func Accepted(c *gin.Context, data interface{}) {
+	write(c, http.StatusAccepted, Response{
 		Success: true,
 		Data:    data,
 	})
@@ -44,9 +72,13 @@ func NoContent(c *gin.Context) {
 }
 
 // Fail sends an error response
+// Errors answered with a 5xx status are reported to error tracking with the request's scope.
 func Fail(c *gin.Context, err error) {
 	// Get status code from the error
 	statusCode := errors.GetStatusCode(err)
+	if statusCode >= http.StatusInternalServerError {
+		errortracking.CaptureException(c.Request.Context(), err)
+	}
 
 	// Get context from the error
 	contextMap := errors.GetContextMap(err)
@@ -60,16 +92,22 @@ func Fail(c *gin.Context, err error) {
 		Details: contextMap,
 	}
 
-	// Create a code based on the error if possible
-	if statusCode == http.StatusBadRequest {
+	// Use the error's own code, or create one based on the status if possible
+	if code := errors.GetCode(err); code != "" {
+		errorResponse.Code = code
+	} else if statusCode == http.StatusBadRequest {
 		errorResponse.Code = "BAD_REQUEST"
 	} else if statusCode == http.StatusNotFound {
 		errorResponse.Code = "NOT_FOUND"
+	} else if statusCode == http.StatusConflict {
+		errorResponse.Code = "CONFLICT"
+	} else if statusCode == http.StatusPreconditionFailed {
+		errorResponse.Code = "PRECONDITION_FAILED"
 	} else if statusCode == http.StatusInternalServerError {
 		errorResponse.Code = "INTERNAL_ERROR"
 	}
 
-	c.JSON(statusCode, Response{
+	write(c, statusCode, Response{
 		Success: false,
 		Error:   &errorResponse,
 	})
@@ -80,12 +118,31 @@ func BadRequest(c *gin.Context, message string) {
 	Fail(c, errors.BadRequest(message))
 }
 
+// Unauthorized sends a 401 unauthorized response
+func Unauthorized(c *gin.Context, message string) {
+	Fail(c, errors.Unauthorized(message))
+}
+
+// Forbidden sends a 403 forbidden response
+func Forbidden(c *gin.Context, message string) {
+	Fail(c, errors.Forbidden(message))
+}
+
 // NotFound sends a 404 not found response
 func NotFound(c *gin.Context, message string) {
 	Fail(c, errors.NotFound(message))
 }
 
-// InternalError sends a 500 internal server error response
+// PreconditionFailed sends a 412 precondition failed response
+func PreconditionFailed(c *gin.Context, message string) {
+	Fail(c, &errors.AppError{
+		StatusCode: http.StatusPreconditionFailed,
+		Message:    message,
+		Original:   errors.ErrPreconditionFailed,
+	})
+}
+
+// InternalError sends a 500 internal server error response, reporting it to error tracking
 func InternalError(c *gin.Context, message string) {
 	Fail(c, errors.Internal(message))
 }
@@ -0,0 +1,35 @@
+package response
+
+import "github.com/gin-gonic/gin"
+
+// Transformer rewrites a response body before it is serialized
+// It receives the complete body (the envelope, or the raw resource without it) and returns its replacement.
+type Transformer func(body interface{}) (interface{}, error)
+
+// transformerKey is the context key holding the transformer of a request
+const transformerKey = "responseTransformer"
+
+// SetTransformer makes responses written for c pass through transformer
+func SetTransformer(c *gin.Context, transformer Transformer) {
+	c.Set(transformerKey, transformer)
+}
+
+// transform applies the request's transformer to body, returning body unchanged when there is none
+func transform(c *gin.Context, body interface{}) interface{} {
+	value, ok := c.Get(transformerKey)
+	if !ok {
+		return body
+	}
+
+	transformer, ok := value.(Transformer)
+	if !ok {
+		return body
+	}
+
+	transformed, err := transformer(body)
+	if err != nil {
+		// A response that cannot be adapted is still better served in its original shape
+		return body
+	}
+	return transformed
+}
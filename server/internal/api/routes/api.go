@@ -2,60 +2,282 @@
 package routes
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"quizizz.com/internal/api/handlers"
 	"quizizz.com/internal/api/handlers/health"
-	"quizizz.com/internal/api/handlers/ping"
+	"quizizz.com/internal/api/handlers/pages"
 	"quizizz.com/internal/api/handlers/user"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/api/versioning"
+	"quizizz.com/internal/module"
+	"quizizz.com/internal/slo"
+	"quizizz.com/pkg/middleware"
+)
+
+// Cache policies for read routes
+// Responses embed user data, so they are private and vary by credentials.
+var (
+	userListCache = middleware.CachePolicy{
+		MaxAge:      10 * time.Second,
+		Private:     true,
+		Vary:        []string{"Accept", "Authorization", response.EnvelopeHeader},
+		ServerTTL:   10 * time.Second,
+		Key:         middleware.KeyByURLAndHeaders("Accept", "Authorization", response.EnvelopeHeader),
+		Deduplicate: true,
+	}
+	userCache = middleware.CachePolicy{
+		MaxAge:      0, // revalidate with the version ETag on every use
+		Private:     true,
+		Vary:        []string{"Accept", "Authorization", response.EnvelopeHeader},
+		Deduplicate: true,
+	}
+	noStore = middleware.CachePolicy{NoStore: true}
+	// avatarCache lets avatars be cached for good: their URLs change with every upload
+	avatarCache = middleware.CachePolicy{MaxAge: 365 * 24 * time.Hour}
+)
+
+// Service level objectives of the user routes
+var (
+	userReadSLO = slo.Objective{
+		Name:               "users_read",
+		AvailabilityTarget: 0.999,
+		Latency:            300 * time.Millisecond,
+		LatencyTarget:      0.99,
+	}
+	userWriteSLO = slo.Objective{
+		Name:               "users_write",
+		AvailabilityTarget: 0.999,
+		Latency:            time.Second,
+		LatencyTarget:      0.99,
+	}
 )
 
 // API defines the API routes
 type API struct {
 	BaseHandler   *handlers.BaseHandler
 	HealthHandler *health.Handler
-	PingHandler   *ping.Handler
 	UserHandler   *user.Handler
+	GDPRHandler   *user.GDPRHandler
+
+	// SessionHandler serves the current user's sessions and refresh token rotation
+	SessionHandler *user.SessionHandler
+
+	// PreferencesHandler serves the preferences of users
+	PreferencesHandler *user.PreferencesHandler
+
+	// AvatarHandler serves the profile images of users
+	AvatarHandler *user.AvatarHandler
+
+	// DeviceHandler serves the devices users register for push notifications
+	DeviceHandler *user.DeviceHandler
+
+	// SummaryHandler serves user summaries from the read model
+	SummaryHandler *user.SummaryHandler
+
+	// PagesHandler serves the server-rendered HTML pages; nil serves none
+	PagesHandler *pages.Handler
+
+	// Modules are the feature modules mounted in every API version
+	Modules *module.Registry
+
+	// ResponseCache backs routes whose policy sets a ServerTTL; nil disables server-side caching
+	ResponseCache *middleware.ResponseCache
+
+	// Routes records the metadata of the registered routes
+	Routes *Registry
+
+	// Policies holds the middleware policies of the route groups by group name
+	Policies Policies
+
+	// groupPolicies holds the policies applied to the groups created by group
+	groupPolicies map[*gin.RouterGroup][]Policy
 }
 
 // NewAPI creates a new API routes instance
 func NewAPI(
 	baseHandler *handlers.BaseHandler,
 	healthHandler *health.Handler,
-	pingHandler *ping.Handler,
 	userHandler *user.Handler,
+	gdprHandler *user.GDPRHandler,
+	sessionHandler *user.SessionHandler,
+	preferencesHandler *user.PreferencesHandler,
+	avatarHandler *user.AvatarHandler,
+	deviceHandler *user.DeviceHandler,
+	summaryHandler *user.SummaryHandler,
+	pagesHandler *pages.Handler,
+	modules *module.Registry,
+	responseCache *middleware.ResponseCache,
+	policies Policies,
 ) *API {
 	return &API{
-		BaseHandler:   baseHandler,
-		HealthHandler: healthHandler,
-		PingHandler:   pingHandler,
-		UserHandler:   userHandler,
+		BaseHandler:        baseHandler,
+		HealthHandler:      healthHandler,
+		UserHandler:        userHandler,
+		GDPRHandler:        gdprHandler,
+		SessionHandler:     sessionHandler,
+		PreferencesHandler: preferencesHandler,
+		AvatarHandler:      avatarHandler,
+		DeviceHandler:      deviceHandler,
+		SummaryHandler:     summaryHandler,
+		PagesHandler:       pagesHandler,
+		Modules:            modules,
+		ResponseCache:      responseCache,
+		Routes:             NewRegistry(),
+		Policies:           policies,
+		groupPolicies:      make(map[*gin.RouterGroup][]Policy),
 	}
 }
 
+// cached prepends the HTTP caching and deduplication middleware declared by policy to handler
+// Requests missing the response cache are deduplicated, so that only one of them reaches handler.
+func (a *API) cached(policy middleware.CachePolicy, handler gin.HandlerFunc) []gin.HandlerFunc {
+	handlers := []gin.HandlerFunc{middleware.CacheControl(policy)}
+	if policy.ServerTTL > 0 && a.ResponseCache != nil {
+		handlers = append(handlers, a.ResponseCache.Middleware(policy.ServerTTL, policy.Key))
+	}
+	if policy.Deduplicate {
+		handlers = append(handlers, middleware.Deduplicate(policy.Vary...))
+	}
+	return append(handlers, handler)
+}
+
 // RegisterRoutes registers all the API routes
 func (a *API) RegisterRoutes(router *gin.Engine) {
-	// Health check routes
-	router.GET("/_meta/health", a.HealthHandler.HealthCheck)
-	router.GET("/livez", a.HealthHandler.LivenessCheck)
-	router.GET("/readyz", a.HealthHandler.ReadinessCheck)
-
-	// API group with versioning
-	apiGroup := router.Group("/api")
-	{
-		v1 := apiGroup.Group("/v1")
-		{
-			// Ping endpoint
-			v1.GET("/ping", a.PingHandler.Ping)
-
-			// User routes
-			users := v1.Group("/users")
-			{
-				users.GET("", a.UserHandler.ListUsers)
-				users.POST("", a.UserHandler.CreateUser)
-				users.GET("/:id", a.UserHandler.GetUser)
-				users.PUT("/:id", a.UserHandler.UpdateUser)
-				users.DELETE("/:id", a.UserHandler.DeleteUser)
-			}
+	a.RegisterHealthRoutes(router)
+	a.RegisterAPIRoutes(router)
+}
+
+// RegisterHealthRoutes registers the health check routes, which may be served on the admin port instead
+func (a *API) RegisterHealthRoutes(router gin.IRouter) {
+	router = a.group(router, "", GroupHealth)
+	a.handle(router, http.MethodGet, "/_meta/health", Meta{Name: "health.check", RateLimit: RateLimitHealth},
+		a.HealthHandler.HealthCheck)
+	a.handle(router, http.MethodGet, "/livez", Meta{Name: "health.live", RateLimit: RateLimitHealth},
+		a.HealthHandler.LivenessCheck)
+	a.handle(router, http.MethodGet, "/readyz", Meta{Name: "health.ready", RateLimit: RateLimitHealth},
+		a.HealthHandler.ReadinessCheck)
+	a.handle(router, http.MethodGet, "/_meta/status", Meta{Name: "health.status", RateLimit: RateLimitHealth},
+		a.HealthHandler.StatusCheck)
+}
+
+// RegisterAPIRoutes registers the versioned API routes and the HTML pages
+func (a *API) RegisterAPIRoutes(router *gin.Engine) {
+	if a.PagesHandler != nil {
+		a.registerPageRoutes(a.group(router, "", GroupPages))
+	}
+
+	// API group with versioning; each supported version gets its own group and response transformer
+	apiGroup := a.group(router, "/api", GroupAPI)
+	registrars := map[versioning.Version]func(*gin.RouterGroup){
+		versioning.V1: a.registerV1,
+		versioning.V2: a.registerV2,
+	}
+	for _, version := range versioning.Supported {
+		register, ok := registrars[version]
+		if !ok {
+			continue
 		}
+		register(a.group(apiGroup, "/"+string(version), "", versioning.Use(version)))
 	}
 }
+
+// registerV1 registers the v1 API routes
+func (a *API) registerV1(group *gin.RouterGroup) {
+	// User routes
+	users := a.group(group, "/users", GroupUsers)
+	a.registerUserRoutes(users)
+	a.registerSessionRoutes(users, a.group(group, "/sessions", GroupSessions))
+
+	// Feature modules
+	a.registerModules(group)
+}
+
+// registerV2 registers the v2 API routes
+// v2 shares the v1 handlers and differs only in field naming, applied by its response transformer.
+// Register routes whose behaviour diverges here, before falling back to the shared ones.
+func (a *API) registerV2(group *gin.RouterGroup) {
+	a.registerV1(group)
+}
+
+// registerUserRoutes registers the user routes shared by all versions
+// The exports stream for as long as the result set takes, so they are not held to a latency objective.
+func (a *API) registerUserRoutes(users *gin.RouterGroup) {
+	read, write := slo.Track(userReadSLO), slo.Track(userWriteSLO)
+
+	a.handle(users, http.MethodGet, "", userMeta("users.list", RateLimitRead),
+		withSLO(read, a.cached(userListCache, a.UserHandler.ListUsers))...)
+	a.handle(users, http.MethodPost, "", userMeta("users.create", RateLimitWrite),
+		write, a.UserHandler.CreateUser)
+	a.handle(users, http.MethodGet, "/export", userMeta("users.export", RateLimitExport),
+		a.cached(noStore, a.UserHandler.ExportUsers)...)
+
+	// Summaries are read from the user summary read model, without aggregating per request
+	a.handle(users, http.MethodGet, "/summaries", userMeta("users.summaries.list", RateLimitRead),
+		withSLO(read, a.cached(userListCache, a.SummaryHandler.ListSummaries))...)
+	a.handle(users, http.MethodGet, "/:id/summary", userMeta("users.summaries.get", RateLimitRead),
+		withSLO(read, a.cached(userListCache, a.SummaryHandler.GetSummary))...)
+
+	a.handle(users, http.MethodGet, "/:id", userMeta("users.get", RateLimitRead),
+		withSLO(read, a.cached(userCache, a.UserHandler.GetUser))...)
+	a.handle(users, http.MethodPut, "/:id", userMeta("users.update", RateLimitWrite),
+		write, a.UserHandler.UpdateUser)
+	a.handle(users, http.MethodDelete, "/:id", userMeta("users.delete", RateLimitWrite),
+		write, a.UserHandler.DeleteUser)
+	a.handle(users, http.MethodGet, "/:id/preferences", userMeta("users.preferences.get", RateLimitRead),
+		withSLO(read, a.cached(userCache, a.PreferencesHandler.GetPreferences))...)
+	a.handle(users, http.MethodPut, "/:id/preferences", userMeta("users.preferences.update", RateLimitWrite),
+		write, a.PreferencesHandler.UpdatePreferences)
+
+	// Avatars are uploaded as the request body and processed in the background; images are public
+	a.handle(users, http.MethodPut, "/:id/avatar", userMeta("users.avatar.upload", RateLimitWrite),
+		write, a.AvatarHandler.UploadAvatar)
+	a.handle(users, http.MethodDelete, "/:id/avatar", userMeta("users.avatar.delete", RateLimitWrite),
+		write, a.AvatarHandler.DeleteAvatar)
+	a.handle(users, http.MethodGet, "/:id/avatar/:size", Meta{Name: "users.avatar.get", Auth: AuthNone, RateLimit: RateLimitRead},
+		a.cached(avatarCache, a.AvatarHandler.GetAvatar)...)
+
+	// Devices registered for push notifications, and the topics they subscribe to
+	a.handle(users, http.MethodGet, "/:id/devices", userMeta("users.devices.list", RateLimitRead),
+		a.cached(noStore, a.DeviceHandler.ListDevices)...)
+	a.handle(users, http.MethodPost, "/:id/devices", userMeta("users.devices.register", RateLimitWrite),
+		write, a.DeviceHandler.RegisterDevice)
+	a.handle(users, http.MethodDelete, "/:id/devices/:deviceId", userMeta("users.devices.unregister", RateLimitWrite),
+		write, a.DeviceHandler.UnregisterDevice)
+	a.handle(users, http.MethodPut, "/:id/devices/:deviceId/topics/:topic", userMeta("users.devices.subscribe", RateLimitWrite),
+		write, a.DeviceHandler.Subscribe)
+	a.handle(users, http.MethodDelete, "/:id/devices/:deviceId/topics/:topic", userMeta("users.devices.unsubscribe", RateLimitWrite),
+		write, a.DeviceHandler.Unsubscribe)
+
+	// Data subject requests; erasure is accepted here and runs as a background job
+	a.handle(users, http.MethodGet, "/:id/data-export", userMeta("users.data_export", RateLimitExport),
+		a.cached(noStore, a.GDPRHandler.ExportData)...)
+	a.handle(users, http.MethodDelete, "/:id/gdpr", userMeta("users.erase", RateLimitWrite),
+		write, a.GDPRHandler.EraseData)
+}
+
+// registerSessionRoutes registers the current user's session routes under users and the refresh
+// token rotation under sessions, which the refresh token itself authenticates
+func (a *API) registerSessionRoutes(users, sessions *gin.RouterGroup) {
+	a.handle(users, http.MethodGet, "/me/sessions", userMeta("users.sessions.list", RateLimitRead),
+		a.cached(noStore, a.SessionHandler.ListSessions)...)
+	a.handle(users, http.MethodDelete, "/me/sessions", userMeta("users.sessions.revoke_all", RateLimitWrite),
+		a.SessionHandler.RevokeSessions)
+	a.handle(users, http.MethodDelete, "/me/sessions/:sessionId", userMeta("users.sessions.revoke", RateLimitWrite),
+		a.SessionHandler.RevokeSession)
+
+	a.handle(sessions, http.MethodPost, "/refresh", Meta{Name: "sessions.refresh", Auth: AuthNone, RateLimit: RateLimitWrite},
+		a.SessionHandler.Refresh)
+}
+
+// userMeta returns the metadata of a user route, whose authentication is set by the users policy
+func userMeta(name, rateLimit string) Meta {
+	return Meta{Name: name, RateLimit: rateLimit}
+}
+
+// withSLO prepends an SLO tracking middleware to a handler chain
+func withSLO(track gin.HandlerFunc, chain []gin.HandlerFunc) []gin.HandlerFunc {
+	return append([]gin.HandlerFunc{track}, chain...)
+}
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/internal/module"
+)
+
+// registerModules mounts the routes of every module under group, at /<name> behind the module's policy
+func (a *API) registerModules(group *gin.RouterGroup) {
+	for _, m := range a.Modules.Modules() {
+		m.Routes(&moduleRouter{
+			RouterGroup: a.group(group, "/"+m.Name(), m.Name()),
+			api:         a,
+			module:      m,
+		})
+	}
+}
+
+// moduleRouter registers the routes of a module through the API, so they are recorded in the
+// registry under the module's name and GET routes answer HEAD
+// Routes registered on groups the module creates itself are served but not recorded.
+type moduleRouter struct {
+	*gin.RouterGroup
+	api    *API
+	module module.Module
+}
+
+// Handle registers a route of the module
+func (r *moduleRouter) Handle(method, relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	rateLimit := RateLimitWrite
+	if method == http.MethodGet {
+		rateLimit = RateLimitRead
+	}
+	r.api.handle(r.RouterGroup, method, relativePath, Meta{Name: r.module.Name(), RateLimit: rateLimit}, handlers...)
+	return r
+}
+
+// GET registers a GET route of the module, which also answers HEAD
+func (r *moduleRouter) GET(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodGet, relativePath, handlers...)
+}
+
+// POST registers a POST route of the module
+func (r *moduleRouter) POST(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodPost, relativePath, handlers...)
+}
+
+// PUT registers a PUT route of the module
+func (r *moduleRouter) PUT(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodPut, relativePath, handlers...)
+}
+
+// PATCH registers a PATCH route of the module
+func (r *moduleRouter) PATCH(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodPatch, relativePath, handlers...)
+}
+
+// DELETE registers a DELETE route of the module
+func (r *moduleRouter) DELETE(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodDelete, relativePath, handlers...)
+}
+
+// OPTIONS registers an OPTIONS route of the module
+func (r *moduleRouter) OPTIONS(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodOptions, relativePath, handlers...)
+}
+
+// HEAD registers a HEAD route of the module, for paths without a GET route
+func (r *moduleRouter) HEAD(relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	return r.Handle(http.MethodHead, relativePath, handlers...)
+}
+
+// Match registers a route of the module for each of methods
+func (r *moduleRouter) Match(methods []string, relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	for _, method := range methods {
+		r.Handle(method, relativePath, handlers...)
+	}
+	return r
+}
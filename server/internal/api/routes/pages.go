@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/internal/web"
+	"quizizz.com/pkg/middleware"
+)
+
+// Cache policies for pages
+var (
+	// statusCache lets shared caches absorb bursts of status page views
+	statusCache = middleware.CachePolicy{MaxAge: 10 * time.Second}
+	// staticCache lets static assets be cached for a while; they only change with deployments
+	staticCache = middleware.CachePolicy{MaxAge: time.Hour}
+)
+
+// registerPageRoutes registers the status page, the static assets under /static and, when enabled,
+// the email previews under /_dev/emails
+func (a *API) registerPageRoutes(pages *gin.RouterGroup) {
+	a.handle(pages, http.MethodGet, "/status", Meta{Name: "pages.status", Auth: AuthNone, RateLimit: RateLimitRead},
+		a.cached(statusCache, a.PagesHandler.Status)...)
+
+	static := http.StripPrefix("/static", http.FileServer(http.FS(web.Static())))
+	a.handle(pages, http.MethodGet, "/static/*filepath", Meta{Name: "pages.static", Auth: AuthNone, RateLimit: RateLimitRead},
+		a.cached(staticCache, gin.WrapH(static))...)
+
+	if a.PagesHandler.Previews() {
+		a.handle(pages, http.MethodGet, "/_dev/emails", Meta{Name: "pages.emails.list", RateLimit: RateLimitRead},
+			a.cached(noStore, a.PagesHandler.ListEmails)...)
+		a.handle(pages, http.MethodGet, "/_dev/emails/:name", Meta{Name: "pages.emails.preview", RateLimit: RateLimitRead},
+			a.cached(noStore, a.PagesHandler.PreviewEmail)...)
+	}
+}
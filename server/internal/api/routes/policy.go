@@ -0,0 +1,149 @@
+package routes
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/internal/auth/serviceauth"
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/middleware"
+)
+
+// Route groups policies can be declared for
+const (
+	GroupHealth = "health"
+	GroupAPI    = "api"
+	GroupUsers  = "users"
+
+	// GroupSessions holds the refresh token rotation
+	GroupSessions = "sessions"
+
+	// GroupPages holds the server-rendered HTML pages and their static assets
+	GroupPages = "pages"
+)
+
+// Policy is the middleware applied to the routes of a group, or to a single route when it is named
+// after the route
+type Policy struct {
+	Name string
+
+	// AuthRequired rejects requests without credentials
+	AuthRequired bool
+
+	// RateLimiter limits requests per client; nil disables rate limiting
+	RateLimiter *middleware.RateLimiter
+
+	// CacheTTL overrides the server-side cache TTL of routes caching responses (0 keeps their own)
+	CacheTTL time.Duration
+
+	// MaxBodySize caps request bodies in bytes (0 disables the limit)
+	MaxBodySize int64
+
+	// LatencyBudget overrides the latency above which requests are flagged as slow (0 keeps the default)
+	LatencyBudget time.Duration
+
+	// ServiceAuth rejects requests without a service token of an allowed caller; nil admits any request
+	ServiceAuth gin.HandlerFunc
+}
+
+// Policies holds the policies of the route groups and routes by name
+type Policies map[string]Policy
+
+// NewPolicies builds the route group policies declared in the configuration
+// Groups in the same rate limit tier share its limiter, so the tier bounds their requests together.
+func NewPolicies(cfg *config.Config) (Policies, error) {
+	limiters := make(map[string]*middleware.RateLimiter)
+	policies := make(Policies, len(cfg.Routes.Policies))
+
+	var verifier *serviceauth.Verifier
+	for name, policyCfg := range cfg.Routes.Policies {
+		if len(policyCfg.ServiceCallers) > 0 && verifier == nil {
+			var err error
+			if verifier, err = serviceauth.NewVerifier(cfg.ServiceAuth, nil); err != nil {
+				return nil, fmt.Errorf("route policy %s: %w", name, err)
+			}
+		}
+	}
+
+	for name, policyCfg := range cfg.Routes.Policies {
+		policy := Policy{
+			Name:          name,
+			AuthRequired:  policyCfg.AuthRequired,
+			CacheTTL:      policyCfg.CacheTTL,
+			MaxBodySize:   policyCfg.MaxBodySize,
+			LatencyBudget: policyCfg.LatencyBudget,
+		}
+
+		if tier := policyCfg.RateLimitTier; tier != "" {
+			rate, ok := cfg.Routes.RateLimitTiers[tier]
+			if !ok || rate <= 0 {
+				return nil, fmt.Errorf("route policy %s: unknown rate limit tier %q", name, tier)
+			}
+			if limiters[tier] == nil {
+				limiters[tier] = middleware.NewRateLimiter(rate, int(math.Ceil(rate)))
+			}
+			policy.RateLimiter = limiters[tier]
+		}
+		if len(policyCfg.ServiceCallers) > 0 {
+			policy.ServiceAuth = verifier.Require(policyCfg.ServiceCallers...)
+		}
+
+		policies[name] = policy
+	}
+
+	return policies, nil
+}
+
+// Middleware returns the middleware enforcing the policy
+// Rate limiting comes first so rejected requests are counted too.
+func (p Policy) Middleware() []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if p.RateLimiter != nil {
+		handlers = append(handlers, middleware.RateLimit(p.RateLimiter))
+	}
+	if p.ServiceAuth != nil {
+		handlers = append(handlers, p.ServiceAuth)
+	}
+	if p.AuthRequired {
+		handlers = append(handlers, middleware.RequireAuth())
+	}
+	if p.MaxBodySize > 0 {
+		handlers = append(handlers, middleware.BodyLimit(p.MaxBodySize))
+	}
+	if p.CacheTTL > 0 {
+		handlers = append(handlers, middleware.CacheTTL(p.CacheTTL))
+	}
+	if p.LatencyBudget > 0 {
+		handlers = append(handlers, middleware.LatencyBudget(p.LatencyBudget))
+	}
+	return handlers
+}
+
+// group creates a route group at relativePath applying the policy declared for name, if any,
+// before handlers; the group's routes also inherit the policies of parent
+func (a *API) group(parent gin.IRouter, relativePath, name string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+	var applied []Policy
+	if parentGroup, ok := parent.(*gin.RouterGroup); ok {
+		applied = append(applied, a.groupPolicies[parentGroup]...)
+	}
+
+	var chain []gin.HandlerFunc
+	if policy, ok := a.Policies[name]; ok {
+		applied = append(applied, policy)
+		chain = policy.Middleware()
+	}
+
+	group := parent.Group(relativePath, append(chain, handlers...)...)
+	a.groupPolicies[group] = applied
+	return group
+}
+
+// policiesOf returns the policies applied to the routes of router
+func (a *API) policiesOf(router gin.IRoutes) []Policy {
+	if group, ok := router.(*gin.RouterGroup); ok {
+		return a.groupPolicies[group]
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package routes
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/pkg/middleware"
+)
+
+// Auth is the authentication a route requires
+type Auth string
+
+// Authentication requirements
+const (
+	AuthNone     Auth = "none"
+	AuthOptional Auth = "optional"
+	AuthRequired Auth = "required"
+)
+
+// Rate limit classes grouping routes with a similar cost
+const (
+	RateLimitRead   = "read"
+	RateLimitWrite  = "write"
+	RateLimitExport = "export"
+	RateLimitHealth = "health"
+)
+
+// Meta is the metadata declared for a route when it is registered
+type Meta struct {
+	// Name identifies the operation, e.g. users.get; it is shared by the versions serving it
+	Name string
+
+	// Auth is the authentication the route requires; by default AuthRequired when a policy of its
+	// group requires authentication and AuthNone otherwise
+	Auth Auth
+
+	// RateLimit is the rate limit class of the route
+	RateLimit string
+}
+
+// Route is a registered route and its metadata
+type Route struct {
+	Meta
+
+	Method string
+
+	// Path is the full route pattern, e.g. /api/v1/users/:id
+	Path string
+
+	// Policies names the policies applied to the route, outermost first; a policy named after the
+	// route comes last
+	Policies []string
+}
+
+// routeKey is the context key holding the metadata of the matched route
+const routeKey = "route"
+
+// RouteFromContext returns the metadata of the route matched by the request
+// Middleware registered globally sees it once c.Next() returns.
+func RouteFromContext(c *gin.Context) (Route, bool) {
+	value, ok := c.Get(routeKey)
+	if !ok {
+		return Route{}, false
+	}
+	route, ok := value.(Route)
+	return route, ok
+}
+
+// Registry records the routes registered by the API with their metadata
+type Registry struct {
+	mu     sync.RWMutex
+	routes map[string]Route
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{routes: make(map[string]Route)}
+}
+
+// Routes returns the registered routes sorted by path and method
+func (r *Registry) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]Route, 0, len(r.routes))
+	for _, route := range r.routes {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Lookup returns the route registered for method and the route pattern path
+func (r *Registry) Lookup(method, path string) (Route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	route, ok := r.routes[method+" "+path]
+	return route, ok
+}
+
+// add records route, replacing the route of the same method and path registered on another router
+func (r *Registry) add(route Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[route.Method+" "+route.Path] = route
+}
+
+// basePather is implemented by gin routers and groups
+type basePather interface {
+	BasePath() string
+}
+
+// handle registers handlers for method and relativePath on router, recording the route in the registry
+// GET routes also answer HEAD; net/http sends their headers and drops the body. The policy named
+// after the route, if any, runs after those of its groups, so its body limit can tighten theirs.
+func (a *API) handle(router gin.IRoutes, method, relativePath string, meta Meta, handlers ...gin.HandlerFunc) {
+	policies := a.policiesOf(router)
+	var routeChain []gin.HandlerFunc
+	if policy, ok := a.Policies[meta.Name]; ok && meta.Name != "" {
+		policies = append(policies[:len(policies):len(policies)], policy)
+		routeChain = policy.Middleware()
+	}
+	names := make([]string, len(policies))
+	for i, policy := range policies {
+		names[i] = policy.Name
+		if policy.AuthRequired && meta.Auth == "" {
+			meta.Auth = AuthRequired
+		}
+	}
+	if meta.Auth == "" {
+		meta.Auth = AuthNone
+	}
+
+	methods := []string{method}
+	if method == http.MethodGet {
+		methods = append(methods, http.MethodHead)
+	}
+
+	for _, m := range methods {
+		route := Route{Meta: meta, Method: m, Path: fullPath(router, relativePath), Policies: names}
+		a.Routes.add(route)
+
+		chain := append([]gin.HandlerFunc{withRoute(route)}, routeChain...)
+		chain = append(chain, handlers[:len(handlers)-1]...)
+		chain = append(chain, middleware.HandlerTiming(), handlers[len(handlers)-1])
+		router.Handle(m, relativePath, chain...)
+	}
+}
+
+// withRoute stores the metadata of route in the context of its requests
+func withRoute(route Route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(routeKey, route)
+		c.Next()
+	}
+}
+
+// fullPath joins relativePath to the base path of router
+func fullPath(router gin.IRoutes, relativePath string) string {
+	base := "/"
+	if group, ok := router.(basePather); ok {
+		base = group.BasePath()
+	}
+	if relativePath == "" {
+		return base
+	}
+
+	joined := path.Join(base, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && joined[len(joined)-1] != '/' {
+		joined += "/"
+	}
+	return joined
+}
@@ -0,0 +1,59 @@
+package versioning
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// CamelCaseKeys renames every snake_case object key in body to camelCase (created_at -> createdAt)
+// Handlers keep returning v1 structs; the body is round-tripped through JSON and rewritten recursively.
+func CamelCaseKeys(body interface{}) (interface{}, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	return renameKeys(decoded, snakeToCamel), nil
+}
+
+// renameKeys applies rename to the keys of all objects nested in value
+func renameKeys(value interface{}, rename func(string) string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renamed[rename(key)] = renameKeys(item, rename)
+		}
+		return renamed
+	case []interface{}:
+		for i, item := range v {
+			v[i] = renameKeys(item, rename)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// snakeToCamel converts a snake_case identifier to camelCase
+func snakeToCamel(s string) string {
+	if !strings.Contains(s, "_") {
+		return s
+	}
+
+	parts := strings.Split(s, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+// Package versioning negotiates the API version of a request and adapts responses to it
+package versioning
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/internal/api/response"
+)
+
+// Version identifies a major API version as it appears in paths (e.g. "v1")
+type Version string
+
+// Supported API versions
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+)
+
+// Default is served when neither the path nor the Accept header select a version
+const Default = V1
+
+// Supported lists all versions in registration order
+var Supported = []Version{V1, V2}
+
+// HeaderName is the response header reporting the version that served a request
+const HeaderName = "API-Version"
+
+// contextKey is the gin context key holding the request's version
+const contextKey = "apiVersion"
+
+// transformers adapt the response body of a version; versions without one return v1 payloads as is
+var transformers = map[Version]response.Transformer{
+	V2: CamelCaseKeys,
+}
+
+// IsSupported reports whether v is a supported version
+func IsSupported(v Version) bool {
+	for _, supported := range Supported {
+		if v == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Use returns a middleware marking requests as served by v and applying its response transformer
+func Use(v Version) gin.HandlerFunc {
+	transformer := transformers[v]
+
+	return func(c *gin.Context) {
+		c.Set(contextKey, v)
+		c.Header(HeaderName, string(v))
+		if transformer != nil {
+			response.SetTransformer(c, transformer)
+		}
+		c.Next()
+	}
+}
+
+// FromContext returns the version serving the request, or Default when none was set
+func FromContext(c *gin.Context) Version {
+	if v, ok := c.Get(contextKey); ok {
+		if version, ok := v.(Version); ok {
+			return version
+		}
+	}
+	return Default
+}
+
+// FromAccept returns the version requested by an Accept header
+// Both vendor media types (application/vnd.stride.v2+json) and a version parameter
+// (application/json; version=2) are understood.
+func FromAccept(accept string) (Version, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		if v, ok := params["version"]; ok {
+			return Version("v" + strings.TrimPrefix(v, "v")), true
+		}
+
+		if strings.HasPrefix(mediaType, "application/vnd.") {
+			for _, segment := range strings.FieldsFunc(mediaType, func(r rune) bool { return r == '.' || r == '+' }) {
+				if len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == "" {
+					return Version(segment), true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Handler serves unversioned paths below prefix (e.g. /api/users) with the version negotiated
+// from the Accept header, by rewriting them to the versioned path before routing
+// Unsupported versions are rejected with 406 Not Acceptable.
+func Handler(next http.Handler, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix+"/")
+		if !ok || IsSupported(Version(strings.SplitN(rest, "/", 2)[0])) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		v, requested := FromAccept(r.Header.Get("Accept"))
+		if !requested {
+			v = Default
+		}
+		if !IsSupported(v) {
+			http.Error(w, "Unsupported API version: "+string(v), http.StatusNotAcceptable)
+			return
+		}
+
+		r.URL.Path = prefix + "/" + string(v) + "/" + rest
+		r.URL.RawPath = ""
+		next.ServeHTTP(w, r)
+	})
+}
@@ -3,19 +3,36 @@ package app
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
 	"quizizz.com/internal/api"
+	"quizizz.com/internal/api/versioning"
+	"quizizz.com/internal/clients"
 	"quizizz.com/internal/config"
+	"quizizz.com/internal/healthcheck"
+	"quizizz.com/internal/jobs"
 	"quizizz.com/internal/logger"
+	"quizizz.com/internal/module"
 	"quizizz.com/internal/resources"
+	"quizizz.com/internal/search"
+	"quizizz.com/internal/views"
+	"quizizz.com/pkg/capture"
+	"quizizz.com/pkg/clientip"
+	"quizizz.com/pkg/errortracking"
+	"quizizz.com/pkg/listener"
 	"quizizz.com/pkg/middleware"
 	"quizizz.com/pkg/otel"
 )
@@ -25,12 +42,37 @@ type App struct {
 	router         *gin.Engine
 	config         *config.Config
 	server         *http.Server
+	tls            bool
 	resources      *resources.Resources
+	clients        *clients.Registry
+	checks         *healthcheck.Registry
+	jobs           *jobs.Queue
+	modules        *module.Registry
+	views          *views.Maintainer
+	indexer        *search.Indexer
+	adminServer    *http.Server
+	grpcServer     *grpc.Server
+	adminGRPC      *grpc.Server
+	capture        *capture.Recorder
+	errors         errortracking.Reporter
 	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
 }
 
 // NewApp creates a new App
-func NewApp(config *config.Config, handler *api.Handler, resources *resources.Resources) *App {
+// Health endpoints are served on the admin port instead of the public ports when config.Health.AdminOnly is set.
+// Job workers run in this process when config.Jobs.Enabled is set. It fails when the server configuration is invalid.
+func NewApp(
+	config *config.Config,
+	handler *api.Handler,
+	resources *resources.Resources,
+	clients *clients.Registry,
+	checks *healthcheck.Registry,
+	queue *jobs.Queue,
+	modules *module.Registry,
+	maintainer *views.Maintainer,
+	indexer *search.Indexer,
+) (*App, error) {
 	// Initialize logger
 	logger.Init(config.Env)
 
@@ -39,43 +81,305 @@ func NewApp(config *config.Config, handler *api.Handler, resources *resources.Re
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	if err := config.Server.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid server configuration: %w", err)
+	}
+
 	// Create a new Gin engine without default middleware
 	router := gin.New()
 
+	// Read client IPs from the forwarding headers of trusted proxies only
+	if err := clientip.Configure(router, config.Server.TrustedProxies, config.Server.ClientIPHeaders); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
+	// Answer OPTIONS and unsupported methods with the Allow header of the path
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(middleware.NoMethod())
+
 	// Add custom middleware
 	router.Use(middleware.RequestID())
-	router.Use(middleware.Logger())
-	router.Use(middleware.Recovery())
+	router.Use(middleware.ClientIP())
+	router.Use(middleware.Correlation())
+	router.Use(middleware.Logger(middleware.LoggerConfig{
+		SampleRate:    config.AccessLog.SampleRate,
+		SlowThreshold: config.AccessLog.SlowThreshold,
+		Skip:          config.AccessLog.SkipPaths,
+	}))
 
-	// Add OpenTelemetry middleware if enabled
+	// Add OpenTelemetry middleware if enabled; it wraps Recovery so panics are recorded on the open span
 	if config.OTEL.Enabled {
 		router.Use(middleware.OTEL(config.OTEL.ServiceName))
 	}
 
-	// Register routes
-	handler.RegisterRoutes(router)
+	reporter := newErrorReporter(config)
+	router.Use(middleware.Recovery(reporter))
+	router.Use(middleware.SlowRequests(config.Server.LatencyBudget))
+	router.Use(middleware.ETag())
+
+	// Bound request bodies on every route; route group policies can lower the limit
+	if config.Server.MaxBodySize > 0 {
+		router.Use(middleware.BodyLimit(config.Server.MaxBodySize))
+	}
+
+	// Record user, tenant and client version on spans and propagate them downstream
+	router.Use(middleware.Baggage())
+
+	// Apply the middleware modules bring, e.g. CSRF checks
+	router.Use(modules.Middleware()...)
+
+	// Capture a sample of sanitized traffic for replay against other environments
+	var recorder *capture.Recorder
+	if config.Capture.Enabled {
+		recorder = newCaptureRecorder(config)
+	}
+	if recorder != nil {
+		router.Use(middleware.Capture(middleware.CaptureConfig{
+			Recorder:    recorder,
+			SampleRate:  config.Capture.SampleRate,
+			MaxBodySize: config.Capture.MaxBodySize,
+			Skip:        []string{"/_meta/health", "/livez", "/readyz", config.OTEL.MetricsPath},
+		}))
+	}
+
+	// Expose metrics in Prometheus format if enabled
+	if config.OTEL.MetricsEnabled {
+		router.GET(config.OTEL.MetricsPath, gin.WrapH(otel.MetricsHandler()))
+	}
+
+	// Register routes; health checks move to the admin port when it is the only place to serve them
+	adminOnly := config.Health.AdminOnly && config.Health.AdminPort != ""
+	if adminOnly {
+		handler.RegisterAPIRoutes(router)
+	} else {
+		handler.RegisterRoutes(router)
+	}
 
-	// Configure HTTP server
+	// Configure HTTP server; unversioned /api paths are routed to the version negotiated from Accept
 	server := &http.Server{
-		Addr:         ":" + config.Port,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              ":" + config.Port,
+		Handler:           versioning.Handler(router, "/api"),
+		ReadTimeout:       config.Server.ReadTimeout,
+		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+		WriteTimeout:      config.Server.WriteTimeout,
+		IdleTimeout:       config.Server.IdleTimeout,
+		MaxHeaderBytes:    config.Server.MaxHeaderBytes,
+	}
+	serveTLS, err := configureProtocols(server, config.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure server TLS: %w", err)
 	}
 
-	return &App{
+	app := &App{
 		router:    router,
 		config:    config,
 		server:    server,
+		tls:       serveTLS,
 		resources: resources,
+		clients:   clients,
+		checks:    checks,
+		jobs:      queue,
+		modules:   modules,
+		views:     maintainer,
+		indexer:   indexer,
+		capture:   recorder,
+		errors:    reporter,
+	}
+
+	if config.GRPC.Enabled {
+		app.grpcServer = grpc.NewServer()
+	}
+	if config.Health.AdminPort != "" {
+		app.adminServer = app.newAdminServer(handler)
+	}
+	if config.Health.AdminOnly && config.Health.AdminPort == "" {
+		logger.Warn("HEALTH_ADMIN_ONLY is set without ADMIN_PORT, serving health checks on the public ports")
+	}
+
+	return app, nil
+}
+
+// newErrorReporter returns the reporter sending panics, server errors and error logs to Sentry, or
+// a no-op reporter when no Sentry DSN is configured or the client cannot be created
+// It becomes the default reporter, used by response.Fail and the logger.
+func newErrorReporter(config *config.Config) errortracking.Reporter {
+	if config.ErrorTracking.SentryDSN == "" {
+		return errortracking.Nop{}
+	}
+
+	reporter, err := errortracking.NewSentry(errortracking.SentryConfig{
+		DSN:         config.ErrorTracking.SentryDSN,
+		Environment: config.Env,
+		Release:     config.ErrorTracking.Release,
+		SampleRate:  config.ErrorTracking.SampleRate,
+	})
+	if err != nil {
+		logger.Error("Error tracking disabled", zap.Error(err))
+		return errortracking.Nop{}
+	}
+	errortracking.SetDefault(reporter)
+	return reporter
+}
+
+// newCaptureRecorder opens the capture file, returning nil (capture disabled) when it cannot be opened
+func newCaptureRecorder(config *config.Config) *capture.Recorder {
+	sink, err := capture.NewFileSink(config.Capture.File)
+	if err != nil {
+		logger.Error("Traffic capture disabled", zap.String("file", config.Capture.File), zap.Error(err))
+		return nil
+	}
+
+	logger.Info("Capturing traffic",
+		zap.String("file", config.Capture.File),
+		zap.Float64("sampleRate", config.Capture.SampleRate),
+	)
+	return capture.NewRecorder(sink, config.Capture.QueueSize)
+}
+
+// newAdminServer creates the admin server serving the HTTP health routes and, with gRPC enabled,
+// the gRPC health service on the same port over h2c
+func (a *App) newAdminServer(handler *api.Handler) *http.Server {
+	adminRouter := gin.New()
+	adminRouter.Use(middleware.Recovery(a.errors))
+	handler.RegisterHealthRoutes(adminRouter)
+
+	var adminHandler http.Handler = adminRouter
+	if a.config.GRPC.Enabled {
+		a.adminGRPC = grpc.NewServer()
+		adminHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+				a.adminGRPC.ServeHTTP(w, r)
+				return
+			}
+			adminRouter.ServeHTTP(w, r)
+		})
+	}
+
+	return &http.Server{
+		Addr:              ":" + a.config.Health.AdminPort,
+		Handler:           h2c.NewHandler(adminHandler, &http2.Server{}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// startHealthServices registers the gRPC health service where it is served and starts the servers
+// besides the public HTTP server, reporting listener errors on serverErrors
+func (a *App) startHealthServices(ctx context.Context, serverErrors chan<- error) error {
+	if a.checks != nil {
+		a.checks.Watch(ctx, a.config.Health.CheckInterval)
+	}
+	adminOnly := a.config.Health.AdminOnly && a.adminServer != nil
+	if a.grpcServer != nil && !adminOnly {
+		healthcheck.RegisterGRPC(ctx, a.grpcServer, a.checks, a.config.Health.CheckInterval)
+	}
+	if a.adminGRPC != nil {
+		healthcheck.RegisterGRPC(ctx, a.adminGRPC, a.checks, a.config.Health.CheckInterval)
+	}
+
+	if a.grpcServer != nil {
+		l, err := listener.Listen(ctx, ":"+a.config.GRPC.Port, a.listenerConfig())
+		if err != nil {
+			return fmt.Errorf("failed to listen on gRPC port: %w", err)
+		}
+		go func() {
+			logger.Info("gRPC server is listening", zap.String("port", a.config.GRPC.Port))
+			serverErrors <- a.grpcServer.Serve(l)
+		}()
+	}
+
+	if a.adminServer != nil {
+		l, err := listener.Listen(ctx, a.adminServer.Addr, a.listenerConfig())
+		if err != nil {
+			return fmt.Errorf("failed to listen on admin port: %w", err)
+		}
+		go func() {
+			logger.Info("Admin server is listening", zap.String("port", a.config.Health.AdminPort))
+			if err := a.adminServer.Serve(l); err != nil && err != http.ErrServerClosed {
+				serverErrors <- err
+			}
+		}()
+	}
+
+	return nil
+}
+
+// stopHealthServices stops the gRPC and admin servers
+func (a *App) stopHealthServices(ctx context.Context) {
+	if a.grpcServer != nil {
+		a.grpcServer.GracefulStop()
+	}
+	if a.adminGRPC != nil {
+		a.adminGRPC.GracefulStop()
+	}
+	if a.adminServer != nil {
+		if err := a.adminServer.Shutdown(ctx); err != nil {
+			logger.Error("Could not stop admin server gracefully", zap.Error(err))
+		}
 	}
 }
 
-// Run starts the application
+// drain fails the readiness checks and waits for the drain period, or until ctx is done
+func (a *App) drain(ctx context.Context) {
+	period := a.config.Server.DrainPeriod
+	if a.checks == nil || period <= 0 {
+		return
+	}
+
+	a.checks.Drain()
+	logger.Info("Draining connections", zap.Duration("period", period))
+
+	timer := time.NewTimer(period)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		logger.Warn("Draining cut short", zap.String("reason", context.Cause(ctx).Error()))
+	}
+}
+
+// listen returns the listener of the public server, the socket handed over by socket activation
+// when there is one
+func (a *App) listen(ctx context.Context) (net.Listener, error) {
+	l, err := listener.Inherited()
+	if err != nil {
+		return nil, err
+	}
+	if l != nil {
+		logger.Info("Using inherited listener", zap.String("addr", l.Addr().String()))
+		return l, nil
+	}
+	return listener.Listen(ctx, a.server.Addr, a.listenerConfig())
+}
+
+// listenerConfig returns how the server listeners are created
+func (a *App) listenerConfig() listener.Config {
+	return listener.Config{ReusePort: a.config.Server.ReusePort}
+}
+
+// Run starts the application and serves until an interrupt or terminate signal is received
 func (a *App) Run() error {
-	ctx := context.Background()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	// Channel to listen for an interrupt or terminate signal from the OS.
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(shutdown)
+
+	go func() {
+		select {
+		case sig := <-shutdown:
+			cancel(fmt.Errorf("received signal %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return a.RunContext(ctx)
+}
 
+// RunContext starts the application and serves until ctx is done, then shuts down gracefully
+// Tests use it to run the full app in process and stop it without sending signals.
+func (a *App) RunContext(ctx context.Context) error {
 	// Initialize OpenTelemetry
 	if a.config.OTEL.Enabled {
 		logger.Info("Initializing OpenTelemetry")
@@ -86,6 +390,15 @@ func (a *App) Run() error {
 		a.tracerProvider = tracerProvider
 	}
 
+	// Initialize metrics
+	if a.config.OTEL.MetricsEnabled {
+		meterProvider, err := otel.InitMeter(ctx, a.config)
+		if err != nil {
+			return fmt.Errorf("failed to initialize metrics: %w", err)
+		}
+		a.meterProvider = meterProvider
+	}
+
 	// Note: Resources are already initialized in main.go before app creation
 	// This ensures resources are connected when repositories are created
 
@@ -98,36 +411,118 @@ func (a *App) Run() error {
 	// Channel to listen for errors coming from the listener.
 	serverErrors := make(chan error, 1)
 
+	// Start the gRPC and admin servers; health status updates stop when Run returns
+	healthCtx, stopHealth := context.WithCancel(ctx)
+	defer stopHealth()
+	if err := a.startHealthServices(healthCtx, serverErrors); err != nil {
+		return err
+	}
+
+	// Start the job workers; jobs in flight at shutdown finish before resources are closed
+	if a.jobs != nil && a.config.Jobs.Enabled {
+		a.jobs.Start(context.WithoutCancel(ctx))
+	}
+
+	// Follow the changes refreshing the read models; the refreshes run on the job workers
+	if a.views != nil {
+		a.views.Start(context.WithoutCancel(ctx))
+	}
+
+	// Index the users changed by writes in bulk, when search is enabled
+	if a.indexer != nil {
+		a.indexer.Start(context.WithoutCancel(ctx))
+	}
+
+	// Start the feature modules before serving their routes
+	if err := a.modules.Start(ctx); err != nil {
+		return err
+	}
+
 	// Start the server
+	l, err := a.listen(ctx)
+	if err != nil {
+		return err
+	}
 	go func() {
-		logger.Info("Server is listening", zap.String("port", a.config.Port))
-		serverErrors <- a.server.ListenAndServe()
+		logger.Info("Server is listening", zap.String("addr", l.Addr().String()), zap.Bool("tls", a.tls))
+		if a.tls {
+			serverErrors <- a.server.ServeTLS(l, "", "")
+			return
+		}
+		serverErrors <- a.server.Serve(l)
 	}()
 
-	// Channel to listen for an interrupt or terminate signal from the OS.
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
-
 	// Blocking main and waiting for shutdown or server errors.
 	select {
 	case err := <-serverErrors:
 		logger.Error("Server error", zap.Error(err))
 		return fmt.Errorf("error: starting server: %w", err)
 
-	case sig := <-shutdown:
-		logger.Info("Server is shutting down", zap.String("signal", sig.String()))
+	case <-ctx.Done():
+		logger.Info("Server is shutting down", zap.String("reason", context.Cause(ctx).Error()))
+
+		// Fail readiness and keep serving while load balancers stop sending traffic, unless a
+		// server fails meanwhile and there is nothing left to drain
+		drainCtx, stopDrain := context.WithCancelCause(context.Background())
+		go func() {
+			select {
+			case err := <-serverErrors:
+				stopDrain(fmt.Errorf("server error: %w", err))
+			case <-drainCtx.Done():
+			}
+		}()
+		a.drain(drainCtx)
+		stopDrain(nil)
 
 		// Give outstanding requests a deadline for completion.
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
+		// Stop serving health checks before resources go away
+		a.stopHealthServices(ctx)
+
+		// Stop the job workers while their resources are still open
+		if a.jobs != nil {
+			if err := a.jobs.Stop(ctx); err != nil {
+				logger.Error("Could not stop job workers gracefully", zap.Error(err))
+			}
+		}
+
+		// Stop following the changes of the read models while the database is still open
+		if a.views != nil {
+			if err := a.views.Stop(ctx); err != nil {
+				logger.Error("Could not stop view maintainer gracefully", zap.Error(err))
+			}
+		}
+
+		// Index the pending users while the cluster and the database are still open
+		if a.indexer != nil {
+			if err := a.indexer.Stop(ctx); err != nil {
+				logger.Error("Could not stop search indexer gracefully", zap.Error(err))
+			}
+		}
+
+		// Stop the feature modules while their resources are still open
+		if err := a.modules.Stop(ctx); err != nil {
+			logger.Error("Could not stop modules gracefully", zap.Error(err))
+		}
+
 		// Close all resources
 		resources.CloseResources(ctx, a.resources)
+		a.clients.Close()
+		if a.capture != nil {
+			if err := a.capture.Close(); err != nil {
+				logger.Error("Error closing traffic capture", zap.Error(err))
+			}
+		}
+		if !a.errors.Flush(a.config.ErrorTracking.FlushTimeout) {
+			logger.Warn("Timed out sending reported errors")
+		}
 
-		// Shutdown tracing
-		if a.tracerProvider != nil {
+		// Shutdown tracing and metrics
+		if a.tracerProvider != nil || a.meterProvider != nil {
 			if err := otel.Shutdown(ctx); err != nil {
-				logger.Error("Error shutting down tracer provider", zap.Error(err))
+				logger.Error("Error shutting down telemetry providers", zap.Error(err))
 			}
 		}
 
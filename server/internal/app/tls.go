@@ -0,0 +1,54 @@
+package app
+
+import (
+	"crypto/tls"
+	"net/http"
+	"slices"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/certs"
+)
+
+// configureProtocols sets up TLS, HTTP/2 and h2c on server, reporting whether it serves TLS
+// Certificates come from Let's Encrypt when autocert domains are configured, from the certificate
+// files otherwise. Without TLS, h2c lets internal clients use HTTP/2 over cleartext.
+func configureProtocols(server *http.Server, cfg config.ServerConfig) (bool, error) {
+	switch {
+	case len(cfg.AutocertDomains) > 0:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		server.TLSConfig = manager.TLSConfig()
+
+	case cfg.TLSCertFile != "":
+		reloader, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadInterval)
+		if err != nil {
+			return false, err
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+
+	default:
+		if cfg.H2C {
+			server.Handler = h2c.NewHandler(server.Handler, &http2.Server{})
+		}
+		return false, nil
+	}
+
+	// net/http negotiates HTTP/2 over TLS by itself; an empty TLSNextProto turns it off
+	if !cfg.HTTP2 {
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		server.TLSConfig.NextProtos = slices.DeleteFunc(server.TLSConfig.NextProtos, func(proto string) bool {
+			return proto == http2.NextProtoTLS
+		})
+	}
+	return true, nil
+}
@@ -0,0 +1,123 @@
+package impersonation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/correlation"
+	"quizizz.com/pkg/middleware"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestTokens(key string, clk *testutil.FakeClock) *Tokens {
+	return NewTokens(&config.Config{Admin: config.AdminConfig{
+		ImpersonationSigningKey: key,
+		ImpersonationTTL:        15 * time.Minute,
+	}}, clk)
+}
+
+func TestTokens_Verify(t *testing.T) {
+	clk := testutil.NewFakeClock(testTime)
+	tokens := newTestTokens("test-key", clk)
+
+	token, issued, err := tokens.Issue("admin-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, testTime.Add(15*time.Minute), issued.Expires())
+
+	claims, err := tokens.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, issued, claims)
+
+	t.Run("Rejected tokens", func(t *testing.T) {
+		other, _, err := newTestTokens("other-key", clk).Issue("admin-1", "user-1")
+		require.NoError(t, err)
+
+		for name, token := range map[string]string{
+			"malformed": "not-a-token",
+			"other key": other,
+			"tampered":  "x" + token,
+		} {
+			_, err := tokens.Verify(token)
+			assert.ErrorIs(t, err, ErrInvalidToken, name)
+		}
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		clk := testutil.NewFakeClock(testTime)
+		tokens := newTestTokens("test-key", clk)
+		token, _, err := tokens.Issue("admin-1", "user-1")
+		require.NoError(t, err)
+
+		clk.Advance(15 * time.Minute)
+		_, err = tokens.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Disabled without a key", func(t *testing.T) {
+		_, _, err := newTestTokens("", clk).Issue("admin-1", "user-1")
+		assert.ErrorIs(t, err, ErrDisabled)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := newTestTokens("test-key", testutil.NewFakeClock(testTime))
+	audit := repository.NewMockAuditRepository(nil)
+
+	router := gin.New()
+	router.Use(middleware.Correlation(), Middleware(tokens, audit))
+	router.POST("/whoami", func(c *gin.Context) {
+		ids := correlation.FromContext(c.Request.Context())
+		c.String(http.StatusOK, ids.UserID+" as "+ids.ActorID)
+	})
+
+	token, claims, err := tokens.Issue("admin-1", "user-1")
+	require.NoError(t, err)
+
+	t.Run("Requests are made for the subject and audited", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/whoami", nil)
+		req.Header.Set("Authorization", "Impersonation "+token)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "user-1 as admin-1", w.Body.String())
+
+		records, _ := audit.ListBySubject(context.Background(), "user-1")
+		require.Len(t, records, 1)
+		assert.Equal(t, AuditRequest, records[0].Action)
+		assert.Equal(t, "user-1", records[0].UserID)
+		assert.Equal(t, "admin-1", records[0].ActorID)
+		assert.Equal(t, claims.ID, records[0].Details["tokenId"])
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/whoami", nil)
+		req.Header.Set("Authorization", "Impersonation forged")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, Scheme, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Other credentials pass through", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/whoami", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, " as ", w.Body.String())
+	})
+}
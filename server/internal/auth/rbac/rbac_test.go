@@ -0,0 +1,73 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/correlation"
+)
+
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	users := repository.NewMockUserRepository()
+	ctx := context.Background()
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "admin-1", Name: "Ada", Email: "ada@example.com", Roles: []string{RoleAdmin}}))
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "support-1", Name: "Sam", Email: "sam@example.com", Roles: []string{RoleSupport}}))
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "user-1", Name: "Uma", Email: "uma@example.com"}))
+
+	return NewAuthorizer(users, &config.Config{Admin: config.AdminConfig{BootstrapAdmins: []string{"root"}}})
+}
+
+func TestAuthorizer_Check(t *testing.T) {
+	authorizer := newTestAuthorizer(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		userID string
+		perm   Permission
+		err    error
+	}{
+		{"admin-1", PermUsersImpersonate, nil},
+		{"support-1", PermUsersRead, nil},
+		{"support-1", PermUsersManage, ErrPermissionDenied},
+		{"user-1", PermStatsRead, ErrPermissionDenied},
+		{"unknown", PermUsersRead, ErrPermissionDenied},
+		{"root", PermUsersManage, nil},
+	} {
+		err := authorizer.Check(ctx, tc.userID, tc.perm)
+		if tc.err == nil {
+			assert.NoError(t, err, "%s %s", tc.userID, tc.perm)
+		} else {
+			assert.ErrorIs(t, err, tc.err, "%s %s", tc.userID, tc.perm)
+		}
+	}
+}
+
+func TestAuthorizer_Require(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authorizer := newTestAuthorizer(t)
+
+	router := gin.New()
+	router.GET("/admin", authorizer.Require(PermUsersManage), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for userID, status := range map[string]int{
+		"admin-1":   http.StatusOK,
+		"support-1": http.StatusForbidden,
+		"":          http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		req = req.WithContext(correlation.WithIDs(req.Context(), correlation.IDs{UserID: userID}))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, userID)
+	}
+}
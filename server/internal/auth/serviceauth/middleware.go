@@ -0,0 +1,80 @@
+package serviceauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/httpclient"
+)
+
+// AnyService allows every trusted service to call a route
+const AnyService = "*"
+
+// callerKey is the context key holding the authenticated calling service
+type callerKey struct{}
+
+// WithCaller returns a copy of ctx carrying the calling service
+func WithCaller(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, callerKey{}, service)
+}
+
+// CallerFromContext returns the calling service authenticated for the request of ctx, or ""
+func CallerFromContext(ctx context.Context) string {
+	service, _ := ctx.Value(callerKey{}).(string)
+	return service
+}
+
+// Require returns a middleware rejecting requests without a valid token from one of callers
+// Requests without a valid token get 401, those of other services 403. The caller is stored in the
+// request context and on the request's span.
+func (v *Verifier) Require(callers ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := v.Verify(c.GetHeader(v.header))
+		if err != nil {
+			logger.WarnCtx(c.Request.Context(), "Service authentication failed", zap.Error(err))
+			if errors.Is(err, ErrMissingToken) {
+				c.Header("WWW-Authenticate", "ServiceToken")
+			}
+			response.Unauthorized(c, "Service authentication required")
+			c.Abort()
+			return
+		}
+		if !slices.Contains(callers, AnyService) && !slices.Contains(callers, claims.Issuer) {
+			logger.WarnCtx(c.Request.Context(), "Service not allowed", zap.String("caller", claims.Issuer), zap.Error(ErrCallerNotAllowed))
+			response.Forbidden(c, "Service not allowed")
+			c.Abort()
+			return
+		}
+
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("service.caller", claims.Issuer))
+		c.Request = c.Request.WithContext(WithCaller(c.Request.Context(), claims.Issuer))
+		c.Next()
+	}
+}
+
+// Attach returns a client middleware authenticating every request to audience in header
+// Requests that already carry the header keep it.
+func (s *Signer) Attach(audience, header string) httpclient.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpclient.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) != "" {
+				return next.RoundTrip(req)
+			}
+			token, err := s.Token(audience)
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(header, token)
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package serviceauth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/httpclient"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testKey returns a deterministic key pair of a service and its base64 encodings
+func testKey(seed byte) (ed25519.PrivateKey, string, string) {
+	seedBytes := make([]byte, ed25519.SeedSize)
+	seedBytes[0] = seed
+	key := ed25519.NewKeyFromSeed(seedBytes)
+	return key,
+		base64.StdEncoding.EncodeToString(seedBytes),
+		base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+type testEnv struct {
+	clock    *testutil.FakeClock
+	billing  *Signer
+	search   *Signer
+	verifier *Verifier
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	clk := testutil.NewFakeClock(testTime)
+	_, billingSeed, billingPublic := testKey(1)
+	_, searchSeed, _ := testKey(2)
+
+	cfg := config.ServiceAuthConfig{TokenTTL: 5 * time.Minute, ClockSkew: 30 * time.Second, Header: "X-Service-Token"}
+
+	billingCfg := cfg
+	billingCfg.Name, billingCfg.SigningKey = "billing", billingSeed
+	billing, err := NewSigner(billingCfg, clk)
+	require.NoError(t, err)
+
+	// search is not trusted by the api
+	searchCfg := cfg
+	searchCfg.Name, searchCfg.SigningKey = "search", searchSeed
+	search, err := NewSigner(searchCfg, clk)
+	require.NoError(t, err)
+
+	apiCfg := cfg
+	apiCfg.Name, apiCfg.TrustedKeys = "api", map[string]string{"billing": billingPublic}
+	verifier, err := NewVerifier(apiCfg, clk)
+	require.NoError(t, err)
+
+	return &testEnv{clock: clk, billing: billing, search: search, verifier: verifier}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Run("Valid token", func(t *testing.T) {
+		env := newTestEnv(t)
+		token, err := env.billing.Token("api")
+		require.NoError(t, err)
+
+		claims, err := env.verifier.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "billing", claims.Issuer)
+		assert.Equal(t, "api", claims.Audience)
+	})
+
+	t.Run("Rejected tokens", func(t *testing.T) {
+		env := newTestEnv(t)
+		otherAudience, err := env.billing.Token("search")
+		require.NoError(t, err)
+		untrusted, err := env.search.Token("api")
+		require.NoError(t, err)
+
+		// A token claiming to come from search, signed with billing's key
+		billingKey, _, _ := testKey(1)
+		spoofed, err := encode(Claims{Issuer: "search", Audience: "api", IssuedAt: testTime.Unix(), ExpiresAt: testTime.Add(time.Minute).Unix()}, "billing", billingKey)
+		require.NoError(t, err)
+
+		valid, err := env.billing.Token("api")
+		require.NoError(t, err)
+		tampered := valid[:len(valid)-2] + "AA"
+
+		for name, tc := range map[string]struct {
+			token string
+			err   error
+		}{
+			"missing":        {"", ErrMissingToken},
+			"malformed":      {"not-a-token", ErrInvalidToken},
+			"other audience": {otherAudience, ErrInvalidToken},
+			"untrusted":      {untrusted, ErrUntrustedService},
+			"spoofed issuer": {spoofed, ErrInvalidToken},
+			"tampered":       {tampered, ErrInvalidToken},
+		} {
+			_, err := env.verifier.Verify(tc.token)
+			assert.ErrorIs(t, err, tc.err, name)
+		}
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		env := newTestEnv(t)
+		token, err := env.billing.Token("api")
+		require.NoError(t, err)
+
+		env.clock.Advance(5*time.Minute + 10*time.Second)
+		_, err = env.verifier.Verify(token)
+		assert.NoError(t, err, "within the clock skew")
+
+		env.clock.Advance(time.Minute)
+		_, err = env.verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestSigner_Token(t *testing.T) {
+	env := newTestEnv(t)
+	first, err := env.billing.Token("api")
+	require.NoError(t, err)
+
+	env.clock.Advance(time.Minute)
+	cached, err := env.billing.Token("api")
+	require.NoError(t, err)
+	assert.Equal(t, first, cached)
+
+	env.clock.Advance(2 * time.Minute)
+	renewed, err := env.billing.Token("api")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, renewed)
+
+	signer, err := NewSigner(config.ServiceAuthConfig{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, signer, "no signing key")
+}
+
+func TestVerifier_Require(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := newTestEnv(t)
+
+	router := gin.New()
+	router.GET("/internal", env.verifier.Require("billing"), func(c *gin.Context) {
+		c.String(http.StatusOK, CallerFromContext(c.Request.Context()))
+	})
+	router.GET("/ledger-only", env.verifier.Require("ledger"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// The client middleware attaches the token
+	client := &http.Client{Transport: env.billing.Attach("api", "X-Service-Token")(httpclient.RoundTripFunc(
+		func(req *http.Request) (*http.Response, error) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Result(), nil
+		}))}
+
+	resp, err := client.Get("http://api/internal")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", string(body))
+
+	resp, err = client.Get("http://api/ledger-only")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/internal", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "ServiceToken", w.Header().Get("WWW-Authenticate"))
+}
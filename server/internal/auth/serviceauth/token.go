@@ -0,0 +1,142 @@
+package serviceauth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// algorithm is the JWS algorithm of service tokens
+const algorithm = "EdDSA"
+
+// Claims are the claims of a service token
+type Claims struct {
+	// Issuer is the calling service, which signed the token
+	Issuer string `json:"iss"`
+
+	// Audience is the called service
+	Audience string `json:"aud"`
+
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+
+	// ID tells tokens apart in logs
+	ID string `json:"jti,omitempty"`
+}
+
+// header is the JOSE header of a service token
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// encode returns the compact JWS of claims signed with key, identifying the key as keyID
+func encode(claims Claims, keyID string, key ed25519.PrivateKey) (string, error) {
+	headerJSON, err := json.Marshal(header{Algorithm: algorithm, Type: "JWT", KeyID: keyID})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	signature := ed25519.Sign(key, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// decode verifies the signature of a compact JWS with the key its header names and returns its claims
+// It does not check the claims themselves.
+func decode(token string, keys map[string]ed25519.PublicKey) (string, Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", Claims{}, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return "", Claims{}, err
+	}
+	if h.Algorithm != algorithm {
+		return "", Claims{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Algorithm)
+	}
+	key, ok := keys[h.KeyID]
+	if !ok {
+		return "", Claims{}, fmt.Errorf("%w: %q", ErrUntrustedService, h.KeyID)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return "", Claims{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", Claims{}, err
+	}
+	return h.KeyID, claims, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return nil
+}
+
+// ParsePrivateKey parses a base64 Ed25519 private key, or the 32-byte seed it derives from
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	data, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	switch len(data) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(data), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(data), nil
+	default:
+		return nil, fmt.Errorf("ed25519 private key has %d bytes, expected %d or %d", len(data), ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// ParsePublicKey parses a base64 Ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	data, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("ed25519 public key has %d bytes, expected %d", len(data), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// decodeKey decodes a key in standard or URL-safe base64, padded or not
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimRight(strings.TrimSpace(encoded), "=")
+	if data, err := base64.RawStdEncoding.DecodeString(encoded); err == nil {
+		return data, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("key is not valid base64")
+	}
+	return data, nil
+}
+
+// expired reports whether claims are outside their validity at now, allowing skew either way
+func (c Claims) expired(now time.Time, skew time.Duration) bool {
+	return now.Add(-skew).Unix() >= c.ExpiresAt || now.Add(skew).Unix() < c.IssuedAt
+}
@@ -0,0 +1,165 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"quizizz.com/pkg/clock"
+)
+
+// Store holds the expiring counters and locks of the throttle
+type Store interface {
+	// Incr increments the counter at key, which expires ttl after it is created, returning its value
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+
+	// Lock sets key for ttl, replacing any lock already set
+	Lock(ctx context.Context, key string, ttl time.Duration) error
+
+	// TTL returns how long key has left to live, or 0 when it is not set
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	// Get returns the value of the counter at key, or 0 when it is not set
+	Get(ctx context.Context, key string) (int64, error)
+
+	// Delete removes keys
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// RedisStore is a Store in Redis, shared by every instance of the application
+type RedisStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisStore creates a Store in client
+func NewRedisStore(client redis.Cmdable) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Incr increments the counter at key, setting its expiry when it is created
+// Creating the counter with its expiry and incrementing it run in one transaction, so a failure
+// between the two cannot leave a counter that never expires.
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, ttl)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Lock sets key for ttl
+func (s *RedisStore) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, 1, ttl).Err()
+}
+
+// TTL returns how long key has left to live
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// Get returns the value of the counter at key
+func (s *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	n, err := s.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Delete removes keys
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// MemoryStore is a Store in memory, for tests and single-instance deployments
+type MemoryStore struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	entries map[string]memoryEntry
+}
+
+// memoryEntry is a value of a MemoryStore and its expiry
+type memoryEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore reading time from clk
+func NewMemoryStore(clk clock.Clock) *MemoryStore {
+	return &MemoryStore{clock: clk, entries: make(map[string]memoryEntry)}
+}
+
+// Incr increments the counter at key, setting its expiry when it is created
+func (s *MemoryStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.live(key)
+	if !ok {
+		entry = memoryEntry{expiresAt: s.clock.Now().Add(ttl)}
+	}
+	entry.value++
+	s.entries[key] = entry
+	return entry.value, nil
+}
+
+// Lock sets key for ttl
+func (s *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{value: 1, expiresAt: s.clock.Now().Add(ttl)}
+	return nil
+}
+
+// TTL returns how long key has left to live
+func (s *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.live(key)
+	if !ok {
+		return 0, nil
+	}
+	return entry.expiresAt.Sub(s.clock.Now()), nil
+}
+
+// Get returns the value of the counter at key
+func (s *MemoryStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, _ := s.live(key)
+	return entry.value, nil
+}
+
+// Delete removes keys
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// live returns the entry at key unless it has expired; the caller holds s.mu
+func (s *MemoryStore) live(key string) (memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok || !s.clock.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
@@ -0,0 +1,251 @@
+// Package throttle protects logins against brute-force attacks
+//
+// Failed attempts are counted per account and per client IP over a fixed window, which starts at
+// the first failure and is not extended by later ones. Reaching the limit locks the account or IP
+// out; each lockout within a day lasts twice as long as the one before it, up to a maximum.
+// Lockouts and manual unlocks are recorded in the audit log.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+)
+
+// Kind is what failed attempts are counted against
+type Kind string
+
+// Kinds of throttled keys
+const (
+	KindAccount Kind = "account"
+	KindIP      Kind = "ip"
+)
+
+// Audit actions recorded by the throttle
+const (
+	AuditLockedOut = "auth.locked_out"
+	AuditUnlocked  = "auth.unlocked"
+)
+
+// lockoutHistory is how long past lockouts make the next one longer
+const lockoutHistory = 24 * time.Hour
+
+// ErrLocked is wrapped by the errors of attempts made while locked out
+var ErrLocked = errors.New("too many failed login attempts")
+
+// ErrInvalidKind is returned for kinds other than KindAccount and KindIP
+var ErrInvalidKind = errors.New("invalid throttle kind")
+
+// LockedError reports a locked out account or IP and when it may try again
+type LockedError struct {
+	Kind       Kind
+	RetryAfter time.Duration
+}
+
+// Error describes the lockout
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s locked out for %s: %v", e.Kind, e.RetryAfter.Round(time.Second), ErrLocked)
+}
+
+// Unwrap returns ErrLocked
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// Status is the throttling state of an account or IP
+type Status struct {
+	Kind Kind
+	Key  string
+
+	// Failures counts the failed attempts in the current window
+	Failures int64
+
+	// Lockouts counts the lockouts of the last day
+	Lockouts int64
+
+	// LockedFor is how long the lockout has left, 0 when not locked out
+	LockedFor time.Duration
+}
+
+// Throttle counts failed logins and locks out accounts and IPs making too many
+type Throttle struct {
+	store Store
+	audit repository.AuditRepository
+	cfg   config.LoginThrottleConfig
+}
+
+// New creates a Throttle keeping its state in store and recording lockouts in audit
+func New(store Store, audit repository.AuditRepository, cfg config.LoginThrottleConfig) *Throttle {
+	return &Throttle{store: store, audit: audit, cfg: cfg}
+}
+
+// Check returns a *LockedError when account or ip is locked out; an empty account or ip is not checked
+func (t *Throttle) Check(ctx context.Context, account, ip string) error {
+	for _, key := range t.keys(account, ip) {
+		ttl, err := t.store.TTL(ctx, t.key(key.kind, key.value, "lock"))
+		if err != nil {
+			return err
+		}
+		if ttl > 0 {
+			return &LockedError{Kind: key.kind, RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login of account from ip, locking out either when it reaches its limit
+// It returns a *LockedError when the failure caused a lockout.
+func (t *Throttle) RecordFailure(ctx context.Context, account, ip string) error {
+	var locked error
+	for _, key := range t.keys(account, ip) {
+		failures, err := t.store.Incr(ctx, t.key(key.kind, key.value, "failures"), t.cfg.Window)
+		if err != nil {
+			return err
+		}
+		if failures < int64(t.limit(key.kind)) {
+			continue
+		}
+
+		lockout, err := t.lockOut(ctx, key.kind, key.value)
+		if err != nil {
+			return err
+		}
+		if locked == nil {
+			locked = &LockedError{Kind: key.kind, RetryAfter: lockout}
+		}
+	}
+	return locked
+}
+
+// RecordSuccess clears the failures of account after a successful login
+// Failures of the IP are kept, so one valid account does not reset an attack on others.
+func (t *Throttle) RecordSuccess(ctx context.Context, account string) error {
+	if account == "" {
+		return nil
+	}
+	return t.store.Delete(ctx, t.key(KindAccount, account, "failures"))
+}
+
+// Status returns the throttling state of key
+func (t *Throttle) Status(ctx context.Context, kind Kind, key string) (*Status, error) {
+	if kind != KindAccount && kind != KindIP {
+		return nil, ErrInvalidKind
+	}
+
+	status := &Status{Kind: kind, Key: key}
+	var err error
+	if status.Failures, err = t.store.Get(ctx, t.key(kind, key, "failures")); err != nil {
+		return nil, err
+	}
+	if status.Lockouts, err = t.store.Get(ctx, t.key(kind, key, "lockouts")); err != nil {
+		return nil, err
+	}
+	if status.LockedFor, err = t.store.TTL(ctx, t.key(kind, key, "lock")); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Unlock lifts the lockout of key and clears its failures and lockout history, recording who did it
+func (t *Throttle) Unlock(ctx context.Context, kind Kind, key, actor string) error {
+	if kind != KindAccount && kind != KindIP {
+		return ErrInvalidKind
+	}
+
+	err := t.store.Delete(ctx,
+		t.key(kind, key, "lock"),
+		t.key(kind, key, "failures"),
+		t.key(kind, key, "lockouts"),
+	)
+	if err != nil {
+		return err
+	}
+
+	logger.InfoCtx(ctx, "Login throttle unlocked", zap.String("kind", string(kind)), zap.String("key", key))
+	return t.record(ctx, AuditUnlocked, kind, key, map[string]interface{}{"actor": actor})
+}
+
+// lockOut locks key out for twice as long as its previous lockout of the day
+func (t *Throttle) lockOut(ctx context.Context, kind Kind, key string) (time.Duration, error) {
+	lockouts, err := t.store.Incr(ctx, t.key(kind, key, "lockouts"), lockoutHistory)
+	if err != nil {
+		return 0, err
+	}
+
+	lockout := LockoutDuration(t.cfg.BaseLockout, t.cfg.MaxLockout, lockouts)
+	if err := t.store.Lock(ctx, t.key(kind, key, "lock"), lockout); err != nil {
+		return 0, err
+	}
+	if err := t.store.Delete(ctx, t.key(kind, key, "failures")); err != nil {
+		return 0, err
+	}
+
+	logger.WarnCtx(ctx, "Login throttle locked out",
+		zap.String("kind", string(kind)),
+		zap.String("key", key),
+		zap.Duration("lockout", lockout),
+		zap.Int64("lockouts", lockouts),
+	)
+	err = t.record(ctx, AuditLockedOut, kind, key, map[string]interface{}{
+		"lockout":  lockout.String(),
+		"lockouts": lockouts,
+	})
+	return lockout, err
+}
+
+// LockoutDuration returns the duration of the nth lockout (from 1): base doubled for each previous
+// lockout, capped at max
+func LockoutDuration(base, max time.Duration, n int64) time.Duration {
+	lockout := base
+	for i := int64(1); i < n && lockout < max; i++ {
+		lockout *= 2
+	}
+	return min(lockout, max)
+}
+
+// record appends an audit record of action on key
+func (t *Throttle) record(ctx context.Context, action string, kind Kind, key string, details map[string]interface{}) error {
+	details["kind"] = string(kind)
+	return t.audit.Record(ctx, &repository.AuditRecord{
+		Action:  action,
+		Subject: string(kind) + ":" + key,
+		Details: details,
+	})
+}
+
+// limit returns the number of failures locking out a key of kind
+func (t *Throttle) limit(kind Kind) int {
+	if kind == KindIP {
+		return t.cfg.MaxIPFailures
+	}
+	return t.cfg.MaxAccountFailures
+}
+
+// throttledKey is a key failures are counted against
+type throttledKey struct {
+	kind  Kind
+	value string
+}
+
+// keys returns the non-empty keys of an attempt
+func (t *Throttle) keys(account, ip string) []throttledKey {
+	var keys []throttledKey
+	if account != "" {
+		keys = append(keys, throttledKey{KindAccount, account})
+	}
+	if ip != "" {
+		keys = append(keys, throttledKey{KindIP, ip})
+	}
+	return keys
+}
+
+// key returns the store key of a counter or lock of key
+func (t *Throttle) key(kind Kind, key, suffix string) string {
+	return t.cfg.KeyPrefix + string(kind) + ":" + key + ":" + suffix
+}
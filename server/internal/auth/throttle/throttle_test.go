@@ -0,0 +1,148 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var testConfig = config.LoginThrottleConfig{
+	KeyPrefix:          "test:",
+	MaxAccountFailures: 3,
+	MaxIPFailures:      5,
+	Window:             15 * time.Minute,
+	BaseLockout:        time.Minute,
+	MaxLockout:         10 * time.Minute,
+}
+
+func newTestThrottle() (*Throttle, *repository.MockAuditRepository, *testutil.FakeClock) {
+	clk := testutil.NewFakeClock(testTime)
+	audit := repository.NewMockAuditRepository(clk)
+	return New(NewMemoryStore(clk), audit, testConfig), audit, clk
+}
+
+func TestLockoutDuration(t *testing.T) {
+	assert.Equal(t, time.Minute, LockoutDuration(time.Minute, 10*time.Minute, 1))
+	assert.Equal(t, 2*time.Minute, LockoutDuration(time.Minute, 10*time.Minute, 2))
+	assert.Equal(t, 8*time.Minute, LockoutDuration(time.Minute, 10*time.Minute, 4))
+	assert.Equal(t, 10*time.Minute, LockoutDuration(time.Minute, 10*time.Minute, 5))
+	assert.Equal(t, 10*time.Minute, LockoutDuration(time.Minute, 10*time.Minute, 100))
+}
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Locks out an account at its limit", func(t *testing.T) {
+		throttle, audit, clk := newTestThrottle()
+
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", "192.0.2.1"))
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", "192.0.2.1"))
+		err := throttle.RecordFailure(ctx, "ada", "192.0.2.1")
+
+		var locked *LockedError
+		require.ErrorAs(t, err, &locked)
+		assert.True(t, errors.Is(err, ErrLocked))
+		assert.Equal(t, KindAccount, locked.Kind)
+		assert.Equal(t, time.Minute, locked.RetryAfter)
+
+		assert.ErrorIs(t, throttle.Check(ctx, "ada", "192.0.2.2"), ErrLocked)
+		assert.NoError(t, throttle.Check(ctx, "grace", "192.0.2.1"))
+
+		records, err := audit.ListBySubject(ctx, "account:ada")
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, AuditLockedOut, records[0].Action)
+
+		clk.Advance(time.Minute)
+		assert.NoError(t, throttle.Check(ctx, "ada", "192.0.2.1"))
+	})
+
+	t.Run("Lockouts double within a day", func(t *testing.T) {
+		throttle, _, clk := newTestThrottle()
+
+		var locked *LockedError
+		for lockout := range 2 {
+			for range testConfig.MaxAccountFailures - 1 {
+				require.NoError(t, throttle.RecordFailure(ctx, "ada", ""))
+			}
+			require.ErrorAs(t, throttle.RecordFailure(ctx, "ada", ""), &locked)
+			assert.Equal(t, time.Minute<<lockout, locked.RetryAfter)
+			clk.Advance(locked.RetryAfter)
+		}
+	})
+
+	t.Run("Failures expire after the window", func(t *testing.T) {
+		throttle, _, clk := newTestThrottle()
+
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", ""))
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", ""))
+		clk.Advance(testConfig.Window)
+		assert.NoError(t, throttle.RecordFailure(ctx, "ada", ""))
+	})
+
+	t.Run("The window starts at the first failure", func(t *testing.T) {
+		throttle, _, clk := newTestThrottle()
+
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", ""))
+		clk.Advance(testConfig.Window - time.Second)
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", ""))
+		clk.Advance(time.Second)
+
+		// Failures of the previous window no longer count, however recent
+		status, err := throttle.Status(ctx, KindAccount, "ada")
+		require.NoError(t, err)
+		assert.Zero(t, status.Failures)
+	})
+
+	t.Run("Success clears account failures only", func(t *testing.T) {
+		throttle, _, _ := newTestThrottle()
+
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", "192.0.2.1"))
+		require.NoError(t, throttle.RecordFailure(ctx, "ada", "192.0.2.1"))
+		require.NoError(t, throttle.RecordSuccess(ctx, "ada"))
+
+		account, err := throttle.Status(ctx, KindAccount, "ada")
+		require.NoError(t, err)
+		assert.Zero(t, account.Failures)
+
+		ip, err := throttle.Status(ctx, KindIP, "192.0.2.1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), ip.Failures)
+	})
+
+	t.Run("Unlock lifts the lockout and records it", func(t *testing.T) {
+		throttle, audit, _ := newTestThrottle()
+
+		for range testConfig.MaxIPFailures {
+			_ = throttle.RecordFailure(ctx, "", "192.0.2.1")
+		}
+		assert.ErrorIs(t, throttle.Check(ctx, "", "192.0.2.1"), ErrLocked)
+
+		require.NoError(t, throttle.Unlock(ctx, KindIP, "192.0.2.1", "admin"))
+		assert.NoError(t, throttle.Check(ctx, "", "192.0.2.1"))
+
+		status, err := throttle.Status(ctx, KindIP, "192.0.2.1")
+		require.NoError(t, err)
+		assert.Equal(t, &Status{Kind: KindIP, Key: "192.0.2.1"}, status)
+
+		records, err := audit.ListBySubject(ctx, "ip:192.0.2.1")
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, AuditUnlocked, records[1].Action)
+		assert.Equal(t, "admin", records[1].Details["actor"])
+	})
+
+	t.Run("Invalid kind", func(t *testing.T) {
+		throttle, _, _ := newTestThrottle()
+		assert.ErrorIs(t, throttle.Unlock(ctx, "user", "ada", "admin"), ErrInvalidKind)
+	})
+}
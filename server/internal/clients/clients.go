@@ -0,0 +1,135 @@
+// Package clients builds preconfigured HTTP clients for the downstream services declared in config
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"quizizz.com/internal/auth/serviceauth"
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/httpclient"
+)
+
+// ErrUnknownService is returned when a client is requested for a service missing from config
+var ErrUnknownService = errors.New("unknown downstream service")
+
+// Registry holds one HTTP client per downstream service
+type Registry struct {
+	clients map[string]*httpclient.Client
+}
+
+// NewRegistry creates the clients of all services in cfg.Downstream
+// With a service auth signing key, requests to services that have service auth on carry a service
+// token addressed to them.
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	signer, err := serviceauth.NewSigner(cfg.ServiceAuth, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{clients: make(map[string]*httpclient.Client, len(cfg.Downstream))}
+	for name, service := range cfg.Downstream {
+		clientConfig, err := clientConfig(cfg, name, service)
+		if err != nil {
+			registry.Close()
+			return nil, err
+		}
+		if signer != nil && service.ServiceAuth {
+			clientConfig.Use(signer.Attach(service.Audience, cfg.ServiceAuth.Header))
+		}
+
+		client, err := httpclient.New(clientConfig)
+		if err != nil {
+			registry.Close()
+			return nil, fmt.Errorf("failed to create client for %s: %w", name, err)
+		}
+		registry.clients[name] = client
+	}
+
+	return registry, nil
+}
+
+// clientConfig applies a service's overrides to the httpclient defaults
+func clientConfig(cfg *config.Config, name string, service config.DownstreamConfig) (*httpclient.Config, error) {
+	if service.BaseURL == "" {
+		return nil, fmt.Errorf("downstream service %s has no base URL", name)
+	}
+
+	clientConfig := httpclient.DefaultConfig(service.BaseURL).
+		WithServiceName(cfg.AppName + "->" + name).
+		WithRetryEnabled(service.RetryEnabled).
+		WithCircuitBreakerEnabled(service.BreakerEnabled).
+		WithIdentityPropagation(true).
+		WithDebug(cfg.Env == "development")
+	clientConfig.CircuitBreaker.Name = name
+
+	if service.Timeout > 0 {
+		clientConfig.WithRequestTimeout(service.Timeout)
+	}
+	if service.MaxRetries > 0 {
+		clientConfig.WithMaxRetries(service.MaxRetries)
+	}
+	if service.BreakerTimeout > 0 {
+		clientConfig.CircuitBreaker.Timeout = service.BreakerTimeout
+	}
+
+	switch service.AuthType {
+	case "":
+	case "bearer":
+		clientConfig.Use(httpclient.SetHeader("Authorization", "Bearer "+service.AuthToken))
+	case "api_key":
+		header := service.AuthHeader
+		if header == "" {
+			header = "X-API-Key"
+		}
+		clientConfig.Use(httpclient.SetHeader(header, service.AuthToken))
+	case "oauth2":
+		clientConfig.OAuth2 = &httpclient.OAuth2Config{
+			TokenURL:     service.OAuth2TokenURL,
+			ClientID:     service.OAuth2ClientID,
+			ClientSecret: service.OAuth2ClientSecret,
+			Scopes:       service.OAuth2Scopes,
+		}
+	default:
+		return nil, fmt.Errorf("downstream service %s has unsupported auth type %q", name, service.AuthType)
+	}
+
+	return clientConfig, nil
+}
+
+// Get returns the client of the named service
+func (r *Registry) Get(name string) (*httpclient.Client, error) {
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownService, name)
+	}
+	return client, nil
+}
+
+// MustGet returns the client of the named service, panicking when it is not configured
+// Use it while wiring, where a missing service is a deployment error.
+func (r *Registry) MustGet(name string) *httpclient.Client {
+	client, err := r.Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// Names returns the configured service names in sorted order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close releases the clients' connections and background work
+func (r *Registry) Close() {
+	for _, client := range r.clients {
+		client.Close()
+	}
+}
@@ -0,0 +1,230 @@
+package clients
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/auth/serviceauth"
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/httpclient"
+)
+
+// headerServer records the headers of the last request it received
+type headerServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	header http.Header
+}
+
+func newHeaderServer(t *testing.T) *headerServer {
+	s := &headerServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.header = r.Header.Clone()
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// lastHeader returns the headers of the last request
+func (s *headerServer) lastHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+// newTestRegistry creates a registry of services, closed when the test ends
+func newTestRegistry(t *testing.T, cfg *config.Config) *Registry {
+	t.Helper()
+	registry, err := NewRegistry(cfg)
+	require.NoError(t, err)
+	t.Cleanup(registry.Close)
+	return registry
+}
+
+func TestNewRegistry(t *testing.T) {
+	t.Run("Creates a client per service", func(t *testing.T) {
+		registry := newTestRegistry(t, &config.Config{Downstream: map[string]config.DownstreamConfig{
+			"search":  {BaseURL: "http://search.internal"},
+			"billing": {BaseURL: "http://billing.internal"},
+		}})
+
+		assert.Equal(t, []string{"billing", "search"}, registry.Names())
+
+		client, err := registry.Get("billing")
+		require.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.Same(t, client, registry.MustGet("billing"))
+	})
+
+	t.Run("Rejects unknown services", func(t *testing.T) {
+		registry := newTestRegistry(t, &config.Config{})
+
+		_, err := registry.Get("billing")
+		assert.ErrorIs(t, err, ErrUnknownService)
+		assert.Panics(t, func() { registry.MustGet("billing") })
+	})
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		wantErr string
+	}{
+		{
+			name:    "Requires a base URL",
+			cfg:     &config.Config{Downstream: map[string]config.DownstreamConfig{"billing": {}}},
+			wantErr: "downstream service billing has no base URL",
+		},
+		{
+			name: "Rejects unsupported auth types",
+			cfg: &config.Config{Downstream: map[string]config.DownstreamConfig{
+				"billing": {BaseURL: "http://billing.internal", AuthType: "digest"},
+			}},
+			wantErr: `downstream service billing has unsupported auth type "digest"`,
+		},
+		{
+			name:    "Rejects invalid signing keys",
+			cfg:     &config.Config{ServiceAuth: config.ServiceAuthConfig{SigningKey: "not a key"}},
+			wantErr: "invalid service auth signing key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(tt.cfg)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	t.Run("Applies overrides", func(t *testing.T) {
+		cfg := &config.Config{AppName: "api", Env: "development"}
+		clientConfig, err := clientConfig(cfg, "billing", config.DownstreamConfig{
+			BaseURL:        "http://billing.internal",
+			Timeout:        3 * time.Second,
+			RetryEnabled:   true,
+			MaxRetries:     5,
+			BreakerEnabled: true,
+			BreakerTimeout: time.Minute,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "http://billing.internal", clientConfig.BaseURL)
+		assert.Equal(t, "api->billing", clientConfig.ServiceName)
+		assert.Equal(t, 3*time.Second, clientConfig.Timeouts.RequestTimeout)
+		assert.True(t, clientConfig.Retry.Enabled)
+		assert.Equal(t, 5, clientConfig.Retry.MaxRetries)
+		assert.True(t, clientConfig.CircuitBreaker.Enabled)
+		assert.Equal(t, "billing", clientConfig.CircuitBreaker.Name)
+		assert.Equal(t, time.Minute, clientConfig.CircuitBreaker.Timeout)
+		assert.True(t, clientConfig.Debug)
+		assert.True(t, clientConfig.PropagateIdentity, "downstream services are internal")
+	})
+
+	t.Run("Keeps defaults for zero values", func(t *testing.T) {
+		defaults := httpclient.DefaultConfig("http://billing.internal")
+		clientConfig, err := clientConfig(&config.Config{Env: "production"}, "billing", config.DownstreamConfig{
+			BaseURL: "http://billing.internal",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, defaults.Timeouts.RequestTimeout, clientConfig.Timeouts.RequestTimeout)
+		assert.Equal(t, defaults.Retry.MaxRetries, clientConfig.Retry.MaxRetries)
+		assert.Equal(t, defaults.CircuitBreaker.Timeout, clientConfig.CircuitBreaker.Timeout)
+		assert.False(t, clientConfig.Retry.Enabled)
+		assert.False(t, clientConfig.CircuitBreaker.Enabled)
+		assert.False(t, clientConfig.Debug)
+	})
+
+	t.Run("Configures OAuth2 client credentials", func(t *testing.T) {
+		clientConfig, err := clientConfig(&config.Config{}, "billing", config.DownstreamConfig{
+			BaseURL:            "http://billing.internal",
+			AuthType:           "oauth2",
+			OAuth2TokenURL:     "http://auth.internal/token",
+			OAuth2ClientID:     "api",
+			OAuth2ClientSecret: "s3cret",
+			OAuth2Scopes:       []string{"invoices:read"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, &httpclient.OAuth2Config{
+			TokenURL:     "http://auth.internal/token",
+			ClientID:     "api",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"invoices:read"},
+		}, clientConfig.OAuth2)
+	})
+}
+
+func TestAuthentication(t *testing.T) {
+	tests := []struct {
+		name    string
+		service config.DownstreamConfig
+		header  string
+		want    string
+	}{
+		{name: "Sends bearer tokens", service: config.DownstreamConfig{AuthType: "bearer", AuthToken: "t1"}, header: "Authorization", want: "Bearer t1"},
+		{name: "Sends API keys", service: config.DownstreamConfig{AuthType: "api_key", AuthToken: "k1"}, header: "X-Api-Key", want: "k1"},
+		{name: "Sends API keys in the configured header", service: config.DownstreamConfig{AuthType: "api_key", AuthToken: "k1", AuthHeader: "X-Billing-Key"}, header: "X-Billing-Key", want: "k1"},
+		{name: "Sends no credentials by default", header: "Authorization", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newHeaderServer(t)
+			tt.service.BaseURL = server.URL
+			registry := newTestRegistry(t, &config.Config{Downstream: map[string]config.DownstreamConfig{"billing": tt.service}})
+
+			_, err := registry.MustGet("billing").Get(context.Background(), "/invoices", nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, server.lastHeader().Get(tt.header))
+		})
+	}
+
+	t.Run("Sends service tokens to services with service auth", func(t *testing.T) {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = 1
+		public := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		authConfig := config.ServiceAuthConfig{
+			Name:       "api",
+			SigningKey: base64.StdEncoding.EncodeToString(seed),
+			Header:     "X-Service-Token",
+			TokenTTL:   time.Minute,
+		}
+
+		billing, search := newHeaderServer(t), newHeaderServer(t)
+		registry := newTestRegistry(t, &config.Config{
+			ServiceAuth: authConfig,
+			Downstream: map[string]config.DownstreamConfig{
+				"billing": {BaseURL: billing.URL, ServiceAuth: true, Audience: "billing"},
+				"search":  {BaseURL: search.URL},
+			},
+		})
+
+		for _, name := range registry.Names() {
+			_, err := registry.MustGet(name).Get(context.Background(), "/", nil)
+			require.NoError(t, err)
+		}
+
+		verifier, err := serviceauth.NewVerifier(config.ServiceAuthConfig{
+			Name:        "billing",
+			TrustedKeys: map[string]string{"api": base64.StdEncoding.EncodeToString(public)},
+		}, nil)
+		require.NoError(t, err)
+		claims, err := verifier.Verify(billing.lastHeader().Get("X-Service-Token"))
+		require.NoError(t, err)
+		assert.Equal(t, "api", claims.Issuer)
+		assert.Equal(t, "billing", claims.Audience)
+
+		assert.Empty(t, search.lastHeader().Get("X-Service-Token"))
+	})
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,15 +18,579 @@ type MongoDBConfig struct {
 	MinPoolSize    uint64
 	ConnectTimeout time.Duration
 	Timeout        time.Duration
+
+	// ReadPreference is the default read preference applied to collections
+	// (primary, primaryPreferred, secondary, secondaryPreferred, nearest). Empty keeps the driver default.
+	ReadPreference string
+
+	// ReadConcern is the default read concern level applied to collections
+	// (local, available, majority, linearizable, snapshot). Empty keeps the driver default.
+	ReadConcern string
+
+	// MaxStaleness bounds replication lag for non-primary read preferences (0 disables the check)
+	MaxStaleness time.Duration
+
+	// GridFSBucket is the bucket name used by the file repository
+	GridFSBucket string
+
+	// GridFSChunkSizeBytes is the chunk size for files stored in GridFS
+	GridFSChunkSizeBytes int32
+
+	// SlowQueryThreshold is the command duration above which queries are logged as slow (0 disables)
+	SlowQueryThreshold time.Duration
+
+	// SchemaValidation controls how declared collection schemas are enforced (strict, warn, off)
+	SchemaValidation string
+
+	// SyncCollectionsOnStartup applies declared indexes and schemas when repositories are created
+	SyncCollectionsOnStartup bool
+
+	// CollectionReadPreferences overrides ReadPreference per collection, e.g. "events=secondaryPreferred"
+	CollectionReadPreferences map[string]string
 }
 
 // RedisConfig holds all Redis configuration
 type RedisConfig struct {
 	Host     string
 	Port     string
+	Server   ServerConfig
 	Password string
 	DB       int
 	Timeout  time.Duration
+
+	// SlowCommandThreshold is the command duration above which commands are logged as slow (0 disables)
+	SlowCommandThreshold time.Duration
+}
+
+// RepositoryCacheConfig holds configuration for the repository read-through cache
+type RepositoryCacheConfig struct {
+	// Enabled determines if repositories cache lookups by ID in Redis
+	Enabled bool
+
+	// TTL is how long found documents are cached
+	TTL time.Duration
+
+	// NegativeTTL is how long not-found lookups are cached (0 disables negative caching)
+	NegativeTTL time.Duration
+
+	// CountTTL is how long exact counts are cached (0 disables count caching)
+	CountTTL time.Duration
+}
+
+// ResponseCacheConfig holds configuration for the Redis-backed HTTP response cache
+type ResponseCacheConfig struct {
+	// Enabled determines if routes declaring a server TTL cache full responses in Redis
+	Enabled bool
+
+	// KeyPrefix namespaces response cache keys
+	KeyPrefix string
+}
+
+// AccessLogConfig holds configuration for the HTTP access log
+type AccessLogConfig struct {
+	// SampleRate is the fraction of successful requests logged (0.0 - 1.0); errors are always logged
+	SampleRate float64
+
+	// SlowThreshold is the latency above which successful requests are always logged (0 disables)
+	SlowThreshold time.Duration
+
+	// SkipPaths are paths whose successful requests are never logged, e.g. probes and metrics
+	SkipPaths []string
+}
+
+// ErrorTrackingConfig holds configuration for reporting panics and server errors
+type ErrorTrackingConfig struct {
+	// SentryDSN is the Sentry project errors are sent to; empty disables error tracking
+	SentryDSN string
+
+	// Release tags reported errors with the deployed version
+	Release string
+
+	// SampleRate is the fraction of errors sent (0.0 - 1.0)
+	SampleRate float64
+
+	// FlushTimeout bounds the wait for reported errors to be sent on shutdown
+	FlushTimeout time.Duration
+}
+
+// CSRFConfig holds configuration for the CSRF protection of browser clients authenticated by cookie
+type CSRFConfig struct {
+	// Enabled checks CSRF tokens on unsafe requests carrying the session cookie and serves /api/<version>/csrf
+	Enabled bool
+
+	// SessionCookie is the cookie authenticating browser clients
+	SessionCookie string
+
+	// CookieName and HeaderName are the cookie holding the token and the header echoing it
+	CookieName string
+	HeaderName string
+
+	// SameSite is the SameSite attribute of the token cookie: strict, lax or none
+	SameSite string
+
+	// Secure restricts the token cookie to HTTPS
+	Secure bool
+
+	// TokenTTL is the lifetime of the token cookie
+	TokenTTL time.Duration
+}
+
+// LoginThrottleConfig holds configuration for the brute-force protection of logins
+type LoginThrottleConfig struct {
+	// KeyPrefix namespaces the throttle's Redis keys
+	KeyPrefix string
+
+	// MaxAccountFailures and MaxIPFailures are the failed attempts locking out an account or a
+	// client IP when made within Window of the first of them
+	MaxAccountFailures int
+	MaxIPFailures      int
+	Window             time.Duration
+
+	// BaseLockout is the first lockout of the day; each further one doubles, up to MaxLockout
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// SessionConfig holds configuration for the sessions refresh tokens are issued for
+type SessionConfig struct {
+	// RefreshTokenTTL is how long a session lasts without being refreshed; each refresh extends it
+	RefreshTokenTTL time.Duration
+
+	// MaxPerUser caps the active sessions of a user; the least recently used are revoked beyond it
+	// (0 disables the cap)
+	MaxPerUser int
+}
+
+// ServiceAuthConfig holds configuration for authenticating calls between internal services
+// Each service signs short-lived tokens with its own Ed25519 key; receivers verify them with the
+// public keys of the services they trust.
+type ServiceAuthConfig struct {
+	// Name is the identity of this service in the tokens it signs; defaults to the app name
+	Name string
+
+	// SigningKey is the base64 Ed25519 private key (or its 32-byte seed) signing outgoing tokens;
+	// without it downstream calls carry no service token
+	SigningKey string
+
+	// TrustedKeys are the base64 Ed25519 public keys of the services allowed to call this one, by name,
+	// e.g. "billing=MCow...,search=MCow..."
+	TrustedKeys map[string]string
+
+	// Header carries the service token
+	Header string
+
+	// TokenTTL is how long signed tokens are valid
+	TokenTTL time.Duration
+
+	// ClockSkew is the difference between service clocks tolerated when checking token times
+	ClockSkew time.Duration
+}
+
+// AdminConfig holds configuration for the admin API
+type AdminConfig struct {
+	// BootstrapAdmins are user IDs holding the admin role whatever their stored roles, so a fresh
+	// deployment has someone to grant roles
+	BootstrapAdmins []string
+
+	// StatsDays is the period the stats count signups per day over
+	StatsDays int
+
+	// StatsRecentSignups is the number of newest users the stats list
+	StatsRecentSignups int
+
+	// ImpersonationSigningKey signs impersonation tokens; without it impersonation is disabled
+	ImpersonationSigningKey string
+
+	// ImpersonationTTL is how long an impersonation token is valid
+	ImpersonationTTL time.Duration
+}
+
+// AvatarConfig holds configuration for the avatar image pipeline
+type AvatarConfig struct {
+	// Sizes are the edge lengths in pixels of the square WebP variants made of every avatar
+	Sizes []int
+
+	// MaxUploadBytes bounds the size of uploaded image files
+	MaxUploadBytes int64
+
+	// MaxPixels bounds the dimensions of uploaded images, which are decoded in memory
+	MaxPixels int
+}
+
+// PushConfig holds configuration for push notifications
+// A provider is enabled by its identifying setting; devices of a platform without one cannot be notified.
+type PushConfig struct {
+	// FCMProjectID is the Firebase project delivering to Android and web devices
+	FCMProjectID string
+
+	// FCMCredentialsFile is the service account key (JSON) authorizing requests to FCM
+	FCMCredentialsFile string
+
+	// FCMBaseURL is the FCM HTTP v1 API endpoint
+	FCMBaseURL string
+
+	// APNsTopic is the bundle ID of the iOS app delivered to through APNs
+	APNsTopic string
+
+	// APNsKeyFile is the token signing key (.p8) authorizing requests to APNs, identified by
+	// APNsKeyID and the APNsTeamID of the developer account
+	APNsKeyFile string
+	APNsKeyID   string
+	APNsTeamID  string
+
+	// APNsBaseURL is the APNs endpoint; development builds of the app use api.sandbox.push.apple.com
+	APNsBaseURL string
+
+	// Timeout bounds each request to a provider
+	Timeout time.Duration
+
+	// BatchSize is the number of messages sent concurrently
+	BatchSize int
+
+	// MaxRetries bounds the retries of a message failing with a transient error
+	MaxRetries int
+
+	// RetryBackoff is the pause before the first retry of a batch, doubled for each retry after
+	RetryBackoff time.Duration
+}
+
+// CaptureConfig holds configuration for capturing sampled traffic for replay (see cmd/replay)
+type CaptureConfig struct {
+	// Enabled determines if sampled request/response pairs are captured
+	Enabled bool
+
+	// SampleRate is the fraction of requests captured (0.0 - 1.0)
+	SampleRate float64
+
+	// File is the newline-delimited JSON file exchanges are appended to
+	File string
+
+	// MaxBodySize caps the bytes kept of each request and response body
+	MaxBodySize int
+
+	// QueueSize bounds the exchanges waiting to be written; further exchanges are dropped
+	QueueSize int
+}
+
+// IDConfig holds configuration for generating entity IDs
+type IDConfig struct {
+	// Strategy selects the ID format: uuidv7 (default), ulid or nanoid (see pkg/idgen)
+	Strategy string
+
+	// Codecs declares how each collection stores IDs: string (generated by Strategy, the default)
+	// or objectid, e.g. "users=string,legacy_events=objectid"
+	Codecs map[string]string
+}
+
+// JobsConfig holds configuration for the background job queue
+type JobsConfig struct {
+	// Enabled runs job workers in this process; jobs can be enqueued either way
+	Enabled bool
+
+	// Workers is the number of jobs processed concurrently
+	Workers int
+
+	// PollInterval is how long idle workers wait before looking for due jobs again
+	PollInterval time.Duration
+
+	// Lease is how long a claimed job is held before another worker may claim it again
+	Lease time.Duration
+
+	// MaxAttempts is the default number of attempts before a job is dead-lettered
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the first retry, doubling with every attempt up to MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+
+	// Concurrency limits the jobs of a type running at once in each process, e.g. to spare a rate
+	// limited API; types without a limit may use every worker
+	Concurrency map[string]int
+}
+
+// ServerConfig holds configuration for the public HTTP server
+type ServerConfig struct {
+	// TLSCertFile and TLSKeyFile enable TLS with a certificate and key in PEM files
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSReloadInterval is how often the certificate files are checked for renewal (0 loads them once)
+	TLSReloadInterval time.Duration
+
+	// ReusePort sets SO_REUSEPORT on the server listeners, so a new process can start listening on
+	// the same ports before the old one stops
+	ReusePort bool
+
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout bound the phases of a request and
+	// idle keep-alive connections (see http.Server); 0 disables a timeout
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// MaxHeaderBytes caps the size of request headers
+	MaxHeaderBytes int
+
+	// MaxBodySize caps request bodies in bytes on every route (0 disables the limit); route group
+	// policies can lower it
+	MaxBodySize int64
+
+	// LatencyBudget is the latency above which requests are flagged as slow (0 disables); route
+	// policies can override it
+	LatencyBudget time.Duration
+
+	// TrustedProxies are the IPs and CIDRs of the load balancers and proxies in front of the server;
+	// the client IP is read from ClientIPHeaders only on requests they forward. Empty trusts none.
+	TrustedProxies []string
+
+	// ClientIPHeaders are the headers carrying the client IP, in order of preference, e.g.
+	// CF-Connecting-IP behind Cloudflare
+	ClientIPHeaders []string
+
+	// DrainPeriod is how long the server keeps serving after failing readiness at shutdown, for load
+	// balancers to stop sending traffic; it should exceed their probe interval and HEALTH_CHECK_INTERVAL
+	DrainPeriod time.Duration
+
+	// HTTP2 serves HTTP/2 to TLS clients negotiating it with ALPN
+	HTTP2 bool
+
+	// H2C serves cleartext HTTP/2 (h2c) when TLS is off, for internal traffic behind a proxy
+	H2C bool
+
+	// AutocertDomains enables TLS with Let's Encrypt certificates for the listed domains;
+	// it takes precedence over TLSCertFile and requires the server to be reachable on port 443
+	AutocertDomains []string
+
+	// AutocertCacheDir is the directory certificates obtained with autocert are cached in
+	AutocertCacheDir string
+
+	// AutocertEmail is the contact address registered with Let's Encrypt
+	AutocertEmail string
+}
+
+// RoutePolicyConfig holds the middleware policy applied to a route group
+type RoutePolicyConfig struct {
+	// AuthRequired rejects requests without credentials
+	AuthRequired bool
+
+	// RateLimitTier names the tier of RoutesConfig.RateLimitTiers limiting requests per client (empty disables)
+	RateLimitTier string
+
+	// CacheTTL overrides the server-side cache TTL of routes caching responses (0 keeps their own)
+	CacheTTL time.Duration
+
+	// MaxBodySize caps request bodies in bytes (0 disables the limit)
+	MaxBodySize int64
+
+	// LatencyBudget overrides the latency above which requests are flagged as slow (0 keeps the default)
+	LatencyBudget time.Duration
+
+	// ServiceCallers are the internal services allowed to call the routes, which then require a
+	// service token; "*" allows any trusted service
+	ServiceCallers []string
+}
+
+// RoutesConfig holds the middleware policies of the route groups
+type RoutesConfig struct {
+	// Policies holds the policy of each route group by name: health, api (every versioned route), users
+	// or the name of a module; a policy named after a route, e.g. users.create, applies to it alone
+	Policies map[string]RoutePolicyConfig
+
+	// RateLimitTiers are the requests per second allowed per client by tier, e.g. "standard=50,strict=5"
+	RateLimitTiers map[string]float64
+}
+
+// RetentionConfig holds configuration for purging data past its retention period
+type RetentionConfig struct {
+	// Enabled schedules the purge job; it runs on the job workers
+	Enabled bool
+
+	// Interval is how often the purge job is scheduled
+	Interval time.Duration
+
+	// BatchSize is the number of documents removed per batch
+	BatchSize int
+
+	// BatchDelay is the pause between batches, limiting the load a purge puts on the database
+	BatchDelay time.Duration
+
+	// MaxBatches bounds the batches per rule and run; the remainder is purged by the next run
+	MaxBatches int
+
+	// JobsMaxAge is how long succeeded and dead jobs are kept; 0 keeps them forever
+	JobsMaxAge time.Duration
+
+	// AuditLogMaxAge is how long audit records stay in the audit log before they are archived; 0 keeps them forever
+	AuditLogMaxAge time.Duration
+
+	// PushReceiptsMaxAge is how long push delivery receipts are kept for analytics; 0 keeps them forever
+	PushReceiptsMaxAge time.Duration
+
+	// SagasMaxAge is how long completed and compensated sagas are kept; 0 keeps them forever
+	SagasMaxAge time.Duration
+}
+
+// ViewsConfig holds configuration for the denormalized read models
+type ViewsConfig struct {
+	// Enabled keeps the read models up to date; they still answer reads when disabled
+	Enabled bool
+
+	// ChangeStreams refreshes read models from MongoDB change streams, which need a replica set;
+	// without them only event handlers and rebuilds refresh the read models
+	ChangeStreams bool
+
+	// RebuildSchedule is the cron schedule of full rebuilds catching up missed changes; empty
+	// disables them
+	RebuildSchedule string
+}
+
+// UploadsConfig holds configuration for uploads sent straight to S3-compatible object storage
+// Clients PUT files to presigned URLs, so their bytes never go through the API servers.
+type UploadsConfig struct {
+	// Enabled serves the presign and confirmation endpoints
+	Enabled bool
+
+	// Endpoint is the base URL of the storage API, e.g. https://s3.eu-west-1.amazonaws.com or a
+	// MinIO server; PathStyle addresses buckets in the path instead of the host name, as MinIO needs
+	Endpoint  string
+	Region    string
+	Bucket    string
+	PathStyle bool
+
+	// AccessKeyID and SecretAccessKey sign the presigned URLs and the requests checking uploads
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// KeyPrefix is prepended to the keys of uploaded objects
+	KeyPrefix string
+
+	// URLTTL is how long presigned URLs stay valid
+	URLTTL time.Duration
+
+	// MaxSize bounds the size of uploaded files in bytes
+	MaxSize int64
+
+	// ContentTypes lists the media types that can be uploaded
+	ContentTypes []string
+
+	// PendingMaxAge is how long uploads that were never confirmed are kept; a bucket lifecycle
+	// rule should expire their objects after the same age
+	PendingMaxAge time.Duration
+
+	// Timeout bounds each request to the storage API
+	Timeout time.Duration
+}
+
+// PagesConfig holds configuration for the server-rendered HTML pages
+type PagesConfig struct {
+	// Enabled serves the status page and the static assets next to the JSON API
+	Enabled bool
+
+	// EmailPreviews serves the email templates rendered with sample data under /_dev/emails; keep it
+	// off in production
+	EmailPreviews bool
+}
+
+// SearchConfig holds configuration for the Elasticsearch or OpenSearch cluster
+// Searches fall back to MongoDB while it is disabled or failing.
+type SearchConfig struct {
+	// Enabled indexes users and answers searches from the cluster
+	Enabled bool
+
+	// URL is the base URL of the cluster; Username and Password authenticate with basic auth when set
+	URL      string
+	Username string
+	Password string
+
+	// IndexPrefix namespaces the indices, aliases and templates of the application, e.g. by environment
+	IndexPrefix string
+
+	// Timeout bounds each request to the cluster
+	Timeout time.Duration
+
+	// BulkSize is the number of documents the indexer buffers before sending a bulk request
+	BulkSize int
+
+	// FlushInterval bounds how long documents stay buffered before they are indexed
+	FlushInterval time.Duration
+}
+
+// DownstreamConfig holds the settings of an HTTP service this application calls
+// Zero values keep the httpclient defaults.
+type DownstreamConfig struct {
+	// BaseURL is the base URL of the service
+	BaseURL string
+
+	// Timeout bounds each request, including retries' individual attempts
+	Timeout time.Duration
+
+	// RetryEnabled determines if failed requests are retried
+	RetryEnabled bool
+
+	// MaxRetries overrides the number of retries
+	MaxRetries int
+
+	// BreakerEnabled determines if a circuit breaker guards the service
+	BreakerEnabled bool
+
+	// BreakerTimeout overrides how long an open breaker rejects requests
+	BreakerTimeout time.Duration
+
+	// AuthType selects how requests authenticate: "", "bearer", "api_key" or "oauth2"
+	AuthType string
+
+	// AuthToken is the bearer token or API key
+	AuthToken string
+
+	// AuthHeader is the header carrying the API key; defaults to X-API-Key
+	AuthHeader string
+
+	// OAuth2TokenURL, OAuth2ClientID, OAuth2ClientSecret and OAuth2Scopes configure client credentials auth
+	OAuth2TokenURL     string
+	OAuth2ClientID     string
+	OAuth2ClientSecret string
+	OAuth2Scopes       []string
+
+	// ServiceAuth attaches a service token to requests when this service has a signing key
+	ServiceAuth bool
+
+	// Audience is the name the service expects in service tokens; defaults to the service name
+	Audience string
+}
+
+// GRPCConfig holds configuration for the gRPC server
+type GRPCConfig struct {
+	// Enabled determines if the gRPC server is started
+	Enabled bool
+
+	// Port is the port the gRPC server listens on
+	Port string
+}
+
+// HealthConfig holds configuration for health checks and the admin port
+type HealthConfig struct {
+	// CheckTimeout bounds a run of all readiness checks
+	CheckTimeout time.Duration
+
+	// CheckInterval is how often the gRPC health service refreshes its status, and the status
+	// history records the checks
+	CheckInterval time.Duration
+
+	// HistorySize is the number of results of each check /_meta/status keeps; 0 disables the history
+	HistorySize int
+
+	// IncidentThreshold is the number of failed checks in a row flagging an incident on a dependency
+	IncidentThreshold int
+
+	// OptionalChecks names the dependencies (e.g. "search,push") the service stays ready without;
+	// their failures are reported but do not fail readiness
+	OptionalChecks []string
+
+	// AdminPort serves health endpoints (HTTP and, with gRPC enabled, gRPC health) on a separate port; empty disables it
+	AdminPort string
+
+	// AdminOnly serves health endpoints on AdminPort only, keeping them off the public HTTP and gRPC ports
+	AdminOnly bool
 }
 
 // OTELConfig holds configuration for OpenTelemetry
@@ -33,14 +601,65 @@ type OTELConfig struct {
 	// ServiceName is the name of the service
 	ServiceName string
 
-	// TracingExporterEndpoint is the endpoint for the tracing exporter (e.g. OTLP, Jaeger)
+	// TracesExporters lists the trace exporters to use: otlp (gRPC), otlp_http, stdout, zipkin or none
+	TracesExporters []string
+
+	// TracingExporterEndpoint is the host:port of the OTLP gRPC collector
 	TracingExporterEndpoint string
 
+	// TracingHTTPEndpoint is the host:port of the OTLP HTTP collector
+	TracingHTTPEndpoint string
+
+	// ZipkinEndpoint is the URL of the Zipkin span collector
+	ZipkinEndpoint string
+
+	// ExporterConnectTimeout bounds each attempt to connect a trace exporter; attempts are retried
+	// in the background, so startup never waits on the collector
+	ExporterConnectTimeout time.Duration
+
+	// ExporterTimeout bounds each export of a span batch
+	ExporterTimeout time.Duration
+
 	// TracingExporterInsecure determines if the tracing exporter should use TLS
 	TracingExporterInsecure bool
 
+	// Propagators lists the trace context formats read and written: tracecontext, baggage, b3,
+	// b3multi or jaeger
+	Propagators []string
+
 	// TracingSampleRatio is the ratio of traces to sample (0.0 - 1.0)
 	TracingSampleRatio float64
+
+	// SamplingRouteRatios overrides TracingSampleRatio per route pattern, e.g. "/health=0,/api/v1/users*=0.5"
+	SamplingRouteRatios map[string]float64
+
+	// TracingMaxPerSecond caps the number of new traces sampled per second (0 disables the cap)
+	TracingMaxPerSecond float64
+
+	// SampleErrors exports traces containing an error span, or slower than TailLatencyThreshold,
+	// even when sampling dropped them
+	SampleErrors bool
+
+	// TailLatencyThreshold exports unsampled traces whose root span took longer (0 disables)
+	TailLatencyThreshold time.Duration
+
+	// TailMaxTraces bounds the number of unsampled traces buffered while they complete
+	TailMaxTraces int
+
+	// MetricsEnabled determines if metrics are collected and exposed
+	MetricsEnabled bool
+
+	// MetricsPath is the HTTP path serving metrics in Prometheus format
+	MetricsPath string
+
+	// RuntimeMetrics enables Go runtime metrics (goroutines, GC pauses, heap)
+	RuntimeMetrics bool
+
+	// RuntimeMetricsInterval is the minimum interval between reads of runtime memory statistics
+	RuntimeMetricsInterval time.Duration
+
+	// HostMetrics enables host metrics (CPU, memory, network)
+	HostMetrics bool
 }
 
 // Config holds all configuration for the application
@@ -50,19 +669,80 @@ type Config struct {
 	LogLevel string
 	Env      string
 
+	Server    ServerConfig
+	AccessLog AccessLogConfig
+
 	// Resource configurations
 	MongoDB MongoDBConfig
 	Redis   RedisConfig
 	OTEL    OTELConfig
+
+	RepositoryCache RepositoryCacheConfig
+	ResponseCache   ResponseCacheConfig
+
+	GRPC   GRPCConfig
+	Health HealthConfig
+
+	Capture       CaptureConfig
+	ErrorTracking ErrorTrackingConfig
+	CSRF          CSRFConfig
+	LoginThrottle LoginThrottleConfig
+	Sessions      SessionConfig
+	ServiceAuth   ServiceAuthConfig
+	Admin         AdminConfig
+	Avatars       AvatarConfig
+	Push          PushConfig
+
+	IDs IDConfig
+
+	Jobs      JobsConfig
+	Retention RetentionConfig
+	Views     ViewsConfig
+
+	Search SearchConfig
+
+	Uploads UploadsConfig
+
+	Pages PagesConfig
+
+	Routes RoutesConfig
+
+	// Downstream holds the HTTP services this application calls, by name
+	Downstream map[string]DownstreamConfig
 }
 
 // NewConfig creates a new Config
 func NewConfig() *Config {
+	env := getEnv("ENV", "development")
+	defaults := defaultServerConfig(env)
+
 	return &Config{
 		AppName:  getEnv("APP_NAME", "go-template-api"),
 		Port:     getEnv("PORT", "8080"),
 		LogLevel: getEnv("LOG_LEVEL", "info"),
-		Env:      getEnv("ENV", "development"),
+		Env:      env,
+
+		Server: ServerConfig{
+			ReadTimeout:       getEnvAsDuration("SERVER_READ_TIMEOUT", defaults.ReadTimeout),
+			ReadHeaderTimeout: getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", defaults.ReadHeaderTimeout),
+			WriteTimeout:      getEnvAsDuration("SERVER_WRITE_TIMEOUT", defaults.WriteTimeout),
+			IdleTimeout:       getEnvAsDuration("SERVER_IDLE_TIMEOUT", defaults.IdleTimeout),
+			MaxHeaderBytes:    getEnvAsInt("SERVER_MAX_HEADER_BYTES", defaults.MaxHeaderBytes),
+			MaxBodySize:       int64(getEnvAsInt("SERVER_MAX_BODY_SIZE", int(defaults.MaxBodySize))),
+			LatencyBudget:     getEnvAsDuration("SERVER_LATENCY_BUDGET", time.Second),
+			TrustedProxies:    getEnvAsList("TRUSTED_PROXIES", nil),
+			ClientIPHeaders:   getEnvAsList("CLIENT_IP_HEADERS", []string{"X-Forwarded-For", "X-Real-IP"}),
+			TLSCertFile:       getEnv("TLS_CERT_FILE", ""),
+			TLSKeyFile:        getEnv("TLS_KEY_FILE", ""),
+			TLSReloadInterval: getEnvAsDuration("TLS_RELOAD_INTERVAL", time.Minute),
+			ReusePort:         getEnvAsBool("SERVER_REUSE_PORT", false),
+			DrainPeriod:       getEnvAsDuration("SHUTDOWN_DRAIN_PERIOD", defaults.DrainPeriod),
+			HTTP2:             getEnvAsBool("HTTP2_ENABLED", true),
+			H2C:               getEnvAsBool("H2C_ENABLED", false),
+			AutocertDomains:   getEnvAsList("AUTOCERT_DOMAINS", nil),
+			AutocertCacheDir:  getEnv("AUTOCERT_CACHE_DIR", "autocert"),
+			AutocertEmail:     getEnv("AUTOCERT_EMAIL", ""),
+		},
 
 		MongoDB: MongoDBConfig{
 			URI:            getEnv("MONGODB_URI", "mongodb://localhost:27017"),
@@ -71,6 +751,16 @@ func NewConfig() *Config {
 			MinPoolSize:    uint64(getEnvAsInt("MONGODB_MIN_POOL_SIZE", 10)),
 			ConnectTimeout: getEnvAsDuration("MONGODB_CONNECT_TIMEOUT", 10*time.Second),
 			Timeout:        getEnvAsDuration("MONGODB_TIMEOUT", 5*time.Second),
+
+			ReadPreference:            getEnv("MONGODB_READ_PREFERENCE", ""),
+			ReadConcern:               getEnv("MONGODB_READ_CONCERN", ""),
+			MaxStaleness:              getEnvAsDuration("MONGODB_MAX_STALENESS", 0),
+			SlowQueryThreshold:        getEnvAsDuration("MONGODB_SLOW_QUERY_THRESHOLD", 100*time.Millisecond),
+			GridFSBucket:              getEnv("MONGODB_GRIDFS_BUCKET", "fs"),
+			GridFSChunkSizeBytes:      int32(getEnvAsInt("MONGODB_GRIDFS_CHUNK_SIZE", 255*1024)),
+			SchemaValidation:          getEnv("MONGODB_SCHEMA_VALIDATION", "warn"),
+			SyncCollectionsOnStartup:  getEnvAsBool("MONGODB_SYNC_COLLECTIONS_ON_STARTUP", true),
+			CollectionReadPreferences: getEnvAsMap("MONGODB_COLLECTION_READ_PREFERENCES"),
 		},
 
 		Redis: RedisConfig{
@@ -79,16 +769,341 @@ func NewConfig() *Config {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 			Timeout:  getEnvAsDuration("REDIS_TIMEOUT", 5*time.Second),
+
+			SlowCommandThreshold: getEnvAsDuration("REDIS_SLOW_COMMAND_THRESHOLD", 20*time.Millisecond),
+		},
+
+		RepositoryCache: RepositoryCacheConfig{
+			Enabled:     getEnvAsBool("REPOSITORY_CACHE_ENABLED", false),
+			TTL:         getEnvAsDuration("REPOSITORY_CACHE_TTL", 5*time.Minute),
+			NegativeTTL: getEnvAsDuration("REPOSITORY_CACHE_NEGATIVE_TTL", 30*time.Second),
+			CountTTL:    getEnvAsDuration("REPOSITORY_CACHE_COUNT_TTL", 30*time.Second),
+		},
+
+		ResponseCache: ResponseCacheConfig{
+			Enabled:   getEnvAsBool("RESPONSE_CACHE_ENABLED", false),
+			KeyPrefix: getEnv("RESPONSE_CACHE_KEY_PREFIX", "http:"),
 		},
 
 		OTEL: OTELConfig{
 			Enabled:                 getEnvAsBool("OTEL_ENABLED", true),
 			ServiceName:             getEnv("OTEL_SERVICE_NAME", "go-template-api"),
+			TracesExporters:         getEnvAsList("OTEL_TRACES_EXPORTER", []string{"otlp"}),
 			TracingExporterEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			TracingHTTPEndpoint:     getEnv("OTEL_EXPORTER_OTLP_HTTP_ENDPOINT", "localhost:4318"),
+			ZipkinEndpoint:          getEnv("OTEL_EXPORTER_ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans"),
+			ExporterConnectTimeout:  getEnvAsDuration("OTEL_EXPORTER_CONNECT_TIMEOUT", 5*time.Second),
+			ExporterTimeout:         getEnvAsDuration("OTEL_EXPORTER_TIMEOUT", 10*time.Second),
 			TracingExporterInsecure: getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+			Propagators:             getEnvAsList("OTEL_PROPAGATORS", []string{"tracecontext", "baggage"}),
 			TracingSampleRatio:      getEnvAsFloat("OTEL_TRACE_SAMPLER_ARG", 1.0),
+			SamplingRouteRatios:     getEnvAsFloatMap("OTEL_SAMPLING_ROUTES"),
+			TracingMaxPerSecond:     getEnvAsFloat("OTEL_TRACES_MAX_PER_SECOND", 0),
+			SampleErrors:            getEnvAsBool("OTEL_SAMPLE_ERRORS", true),
+			TailLatencyThreshold:    getEnvAsDuration("OTEL_TAIL_LATENCY_THRESHOLD", 2*time.Second),
+			TailMaxTraces:           getEnvAsInt("OTEL_TAIL_MAX_TRACES", 10000),
+			MetricsEnabled:          getEnvAsBool("OTEL_METRICS_ENABLED", true),
+			MetricsPath:             getEnv("OTEL_METRICS_PATH", "/metrics"),
+			RuntimeMetrics:          getEnvAsBool("OTEL_RUNTIME_METRICS", true),
+			RuntimeMetricsInterval:  getEnvAsDuration("OTEL_RUNTIME_METRICS_INTERVAL", 15*time.Second),
+			HostMetrics:             getEnvAsBool("OTEL_HOST_METRICS", false),
+		},
+
+		GRPC: GRPCConfig{
+			Enabled: getEnvAsBool("GRPC_ENABLED", false),
+			Port:    getEnv("GRPC_PORT", "9090"),
+		},
+
+		Health: HealthConfig{
+			CheckTimeout:      getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			CheckInterval:     getEnvAsDuration("HEALTH_CHECK_INTERVAL", 5*time.Second),
+			HistorySize:       getEnvAsInt("HEALTH_HISTORY_SIZE", 60),
+			IncidentThreshold: getEnvAsInt("HEALTH_INCIDENT_THRESHOLD", 3),
+			OptionalChecks:    getEnvAsList("HEALTH_OPTIONAL_CHECKS", nil),
+			AdminPort:         getEnv("ADMIN_PORT", ""),
+			AdminOnly:         getEnvAsBool("HEALTH_ADMIN_ONLY", false),
+		},
+
+		AccessLog: AccessLogConfig{
+			SampleRate:    getEnvAsFloat("ACCESS_LOG_SAMPLE_RATE", 1),
+			SlowThreshold: getEnvAsDuration("ACCESS_LOG_SLOW_THRESHOLD", time.Second),
+			SkipPaths:     getEnvAsList("ACCESS_LOG_SKIP_PATHS", []string{"/livez", "/readyz", "/_meta/health", "/metrics"}),
+		},
+
+		Capture: CaptureConfig{
+			Enabled:     getEnvAsBool("CAPTURE_ENABLED", false),
+			SampleRate:  getEnvAsFloat("CAPTURE_SAMPLE_RATE", 0.01),
+			File:        getEnv("CAPTURE_FILE", "capture.ndjson"),
+			MaxBodySize: getEnvAsInt("CAPTURE_MAX_BODY_SIZE", 64*1024),
+			QueueSize:   getEnvAsInt("CAPTURE_QUEUE_SIZE", 1000),
+		},
+
+		ErrorTracking: ErrorTrackingConfig{
+			SentryDSN:    getEnv("SENTRY_DSN", ""),
+			Release:      getEnv("SENTRY_RELEASE", ""),
+			SampleRate:   getEnvAsFloat("SENTRY_SAMPLE_RATE", 1),
+			FlushTimeout: getEnvAsDuration("SENTRY_FLUSH_TIMEOUT", 2*time.Second),
+		},
+
+		CSRF: CSRFConfig{
+			Enabled:       getEnvAsBool("CSRF_ENABLED", false),
+			SessionCookie: getEnv("CSRF_SESSION_COOKIE", "session"),
+			CookieName:    getEnv("CSRF_COOKIE_NAME", "csrf_token"),
+			HeaderName:    getEnv("CSRF_HEADER_NAME", "X-CSRF-Token"),
+			SameSite:      getEnv("CSRF_COOKIE_SAMESITE", "lax"),
+			Secure:        getEnvAsBool("CSRF_COOKIE_SECURE", env == "production"),
+			TokenTTL:      getEnvAsDuration("CSRF_TOKEN_TTL", 12*time.Hour),
+		},
+
+		LoginThrottle: LoginThrottleConfig{
+			KeyPrefix:          getEnv("LOGIN_THROTTLE_KEY_PREFIX", "login_throttle:"),
+			MaxAccountFailures: getEnvAsInt("LOGIN_THROTTLE_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      getEnvAsInt("LOGIN_THROTTLE_MAX_IP_FAILURES", 50),
+			Window:             getEnvAsDuration("LOGIN_THROTTLE_WINDOW", 15*time.Minute),
+			BaseLockout:        getEnvAsDuration("LOGIN_THROTTLE_BASE_LOCKOUT", time.Minute),
+			MaxLockout:         getEnvAsDuration("LOGIN_THROTTLE_MAX_LOCKOUT", time.Hour),
+		},
+
+		Sessions: SessionConfig{
+			RefreshTokenTTL: getEnvAsDuration("SESSION_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			MaxPerUser:      getEnvAsInt("SESSION_MAX_PER_USER", 20),
+		},
+
+		ServiceAuth: ServiceAuthConfig{
+			Name:        getEnv("SERVICE_AUTH_NAME", getEnv("APP_NAME", "go-template-api")),
+			SigningKey:  getEnv("SERVICE_AUTH_SIGNING_KEY", ""),
+			TrustedKeys: getEnvAsMap("SERVICE_AUTH_TRUSTED_KEYS"),
+			Header:      getEnv("SERVICE_AUTH_HEADER", "X-Service-Token"),
+			TokenTTL:    getEnvAsDuration("SERVICE_AUTH_TOKEN_TTL", 5*time.Minute),
+			ClockSkew:   getEnvAsDuration("SERVICE_AUTH_CLOCK_SKEW", 30*time.Second),
+		},
+
+		Admin: AdminConfig{
+			BootstrapAdmins:    getEnvAsList("ADMIN_BOOTSTRAP_USER_IDS", nil),
+			StatsDays:          getEnvAsInt("ADMIN_STATS_DAYS", 30),
+			StatsRecentSignups: getEnvAsInt("ADMIN_STATS_RECENT_SIGNUPS", 10),
+
+			ImpersonationSigningKey: getEnv("ADMIN_IMPERSONATION_SIGNING_KEY", ""),
+			ImpersonationTTL:        getEnvAsDuration("ADMIN_IMPERSONATION_TTL", 15*time.Minute),
+		},
+
+		Avatars: AvatarConfig{
+			Sizes:          getEnvAsIntList("AVATAR_SIZES", []int{64, 128, 256}),
+			MaxUploadBytes: int64(getEnvAsInt("AVATAR_MAX_UPLOAD_BYTES", 5<<20)),
+			MaxPixels:      getEnvAsInt("AVATAR_MAX_PIXELS", 25_000_000),
+		},
+
+		Push: PushConfig{
+			FCMProjectID:       getEnv("PUSH_FCM_PROJECT_ID", ""),
+			FCMCredentialsFile: getEnv("PUSH_FCM_CREDENTIALS_FILE", ""),
+			FCMBaseURL:         getEnv("PUSH_FCM_BASE_URL", "https://fcm.googleapis.com"),
+			APNsTopic:          getEnv("PUSH_APNS_TOPIC", ""),
+			APNsKeyFile:        getEnv("PUSH_APNS_KEY_FILE", ""),
+			APNsKeyID:          getEnv("PUSH_APNS_KEY_ID", ""),
+			APNsTeamID:         getEnv("PUSH_APNS_TEAM_ID", ""),
+			APNsBaseURL:        getEnv("PUSH_APNS_BASE_URL", "https://api.push.apple.com"),
+			Timeout:            getEnvAsDuration("PUSH_TIMEOUT", 10*time.Second),
+			BatchSize:          getEnvAsInt("PUSH_BATCH_SIZE", 100),
+			MaxRetries:         getEnvAsInt("PUSH_MAX_RETRIES", 3),
+			RetryBackoff:       getEnvAsDuration("PUSH_RETRY_BACKOFF", time.Second),
 		},
+
+		IDs: IDConfig{
+			Strategy: getEnv("ID_STRATEGY", "uuidv7"),
+			Codecs:   getEnvAsMap("ID_CODECS"),
+		},
+
+		Jobs: JobsConfig{
+			Enabled:         getEnvAsBool("JOBS_ENABLED", true),
+			Workers:         getEnvAsInt("JOBS_WORKERS", 4),
+			PollInterval:    getEnvAsDuration("JOBS_POLL_INTERVAL", time.Second),
+			Lease:           getEnvAsDuration("JOBS_LEASE", 5*time.Minute),
+			MaxAttempts:     getEnvAsInt("JOBS_MAX_ATTEMPTS", 5),
+			RetryBackoff:    getEnvAsDuration("JOBS_RETRY_BACKOFF", 10*time.Second),
+			MaxRetryBackoff: getEnvAsDuration("JOBS_MAX_RETRY_BACKOFF", time.Hour),
+			Concurrency:     getEnvAsIntMap("JOBS_CONCURRENCY"),
+		},
+
+		Retention: RetentionConfig{
+			Enabled:        getEnvAsBool("RETENTION_ENABLED", true),
+			Interval:       getEnvAsDuration("RETENTION_INTERVAL", time.Hour),
+			BatchSize:      getEnvAsInt("RETENTION_BATCH_SIZE", 500),
+			BatchDelay:     getEnvAsDuration("RETENTION_BATCH_DELAY", 100*time.Millisecond),
+			MaxBatches:     getEnvAsInt("RETENTION_MAX_BATCHES", 100),
+			JobsMaxAge:     getEnvAsDuration("RETENTION_JOBS_MAX_AGE", 7*24*time.Hour),
+			AuditLogMaxAge: getEnvAsDuration("RETENTION_AUDIT_LOG_MAX_AGE", 365*24*time.Hour),
+
+			PushReceiptsMaxAge: getEnvAsDuration("RETENTION_PUSH_RECEIPTS_MAX_AGE", 90*24*time.Hour),
+			SagasMaxAge:        getEnvAsDuration("RETENTION_SAGAS_MAX_AGE", 30*24*time.Hour),
+		},
+
+		Views: ViewsConfig{
+			Enabled:         getEnvAsBool("VIEWS_ENABLED", true),
+			ChangeStreams:   getEnvAsBool("VIEWS_CHANGE_STREAMS", true),
+			RebuildSchedule: getEnv("VIEWS_REBUILD_SCHEDULE", "0 3 * * *"),
+		},
+
+		Search: SearchConfig{
+			Enabled:       getEnvAsBool("SEARCH_ENABLED", false),
+			URL:           getEnv("SEARCH_URL", "http://localhost:9200"),
+			Username:      getEnv("SEARCH_USERNAME", ""),
+			Password:      getEnv("SEARCH_PASSWORD", ""),
+			IndexPrefix:   getEnv("SEARCH_INDEX_PREFIX", env),
+			Timeout:       getEnvAsDuration("SEARCH_TIMEOUT", 5*time.Second),
+			BulkSize:      getEnvAsInt("SEARCH_BULK_SIZE", 500),
+			FlushInterval: getEnvAsDuration("SEARCH_FLUSH_INTERVAL", time.Second),
+		},
+
+		Uploads: UploadsConfig{
+			Enabled:         getEnvAsBool("UPLOADS_ENABLED", false),
+			Endpoint:        getEnv("UPLOADS_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          getEnv("UPLOADS_S3_REGION", "us-east-1"),
+			Bucket:          getEnv("UPLOADS_S3_BUCKET", ""),
+			PathStyle:       getEnvAsBool("UPLOADS_S3_PATH_STYLE", false),
+			AccessKeyID:     getEnv("UPLOADS_S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("UPLOADS_S3_SECRET_ACCESS_KEY", ""),
+			KeyPrefix:       getEnv("UPLOADS_KEY_PREFIX", "uploads/"),
+			URLTTL:          getEnvAsDuration("UPLOADS_URL_TTL", 15*time.Minute),
+			MaxSize:         int64(getEnvAsInt("UPLOADS_MAX_SIZE", 100<<20)),
+			ContentTypes:    getEnvAsList("UPLOADS_CONTENT_TYPES", []string{"image/jpeg", "image/png", "image/webp", "application/pdf", "video/mp4"}),
+			PendingMaxAge:   getEnvAsDuration("UPLOADS_PENDING_MAX_AGE", 24*time.Hour),
+			Timeout:         getEnvAsDuration("UPLOADS_TIMEOUT", 10*time.Second),
+		},
+
+		Pages: PagesConfig{
+			Enabled:       getEnvAsBool("PAGES_ENABLED", false),
+			EmailPreviews: getEnvAsBool("PAGES_EMAIL_PREVIEWS", env == "development"),
+		},
+
+		Routes: RoutesConfig{
+			Policies:       loadRoutePolicies(),
+			RateLimitTiers: getEnvAsFloatMap("RATE_LIMIT_TIERS"),
+		},
+
+		Downstream: loadDownstream(),
+	}
+}
+
+// defaultServerConfig returns the server limits of env
+// Production is strict and drains behind its load balancers; elsewhere the timeouts leave room for
+// stepping through requests in a debugger.
+func defaultServerConfig(env string) ServerConfig {
+	cfg := ServerConfig{
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		MaxBodySize:       10 << 20,
+	}
+
+	switch env {
+	case "production":
+		cfg.DrainPeriod = 10 * time.Second
+	case "development":
+		cfg.ReadTimeout = 5 * time.Minute
+		cfg.WriteTimeout = 5 * time.Minute
+	}
+	return cfg
+}
+
+// Validate reports server settings that cannot work
+func (c ServerConfig) Validate() error {
+	var errs []error
+	for name, d := range map[string]time.Duration{
+		"SERVER_READ_TIMEOUT":        c.ReadTimeout,
+		"SERVER_READ_HEADER_TIMEOUT": c.ReadHeaderTimeout,
+		"SERVER_WRITE_TIMEOUT":       c.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        c.IdleTimeout,
+		"SHUTDOWN_DRAIN_PERIOD":      c.DrainPeriod,
+		"SERVER_LATENCY_BUDGET":      c.LatencyBudget,
+	} {
+		if d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", name))
+		}
+	}
+	if c.ReadTimeout > 0 && c.ReadHeaderTimeout > c.ReadTimeout {
+		errs = append(errs, errors.New("SERVER_READ_HEADER_TIMEOUT must not exceed SERVER_READ_TIMEOUT"))
+	}
+	if c.MaxHeaderBytes < 0 {
+		errs = append(errs, errors.New("SERVER_MAX_HEADER_BYTES must not be negative"))
+	}
+	if c.MaxBodySize < 0 {
+		errs = append(errs, errors.New("SERVER_MAX_BODY_SIZE must not be negative"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// loadRoutePolicies reads the route policies listed in ROUTE_POLICIES (e.g. "api,users,users.create"),
+// each configured by ROUTE_POLICY_<NAME>_* variables such as ROUTE_POLICY_USERS_AUTH_REQUIRED or
+// ROUTE_POLICY_USERS_CREATE_MAX_BODY_SIZE
+func loadRoutePolicies() map[string]RoutePolicyConfig {
+	names := getEnv("ROUTE_POLICIES", "")
+	if names == "" {
+		return nil
+	}
+
+	policies := make(map[string]RoutePolicyConfig)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "ROUTE_POLICY_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name)) + "_"
+		policies[name] = RoutePolicyConfig{
+			AuthRequired:   getEnvAsBool(prefix+"AUTH_REQUIRED", false),
+			RateLimitTier:  getEnv(prefix+"RATE_LIMIT_TIER", ""),
+			CacheTTL:       getEnvAsDuration(prefix+"CACHE_TTL", 0),
+			MaxBodySize:    int64(getEnvAsInt(prefix+"MAX_BODY_SIZE", 0)),
+			LatencyBudget:  getEnvAsDuration(prefix+"LATENCY_BUDGET", 0),
+			ServiceCallers: getEnvAsList(prefix+"SERVICE_CALLERS", nil),
+		}
+	}
+
+	return policies
+}
+
+// loadDownstream reads the services listed in DOWNSTREAM_SERVICES (e.g. "billing,search"),
+// each configured by DOWNSTREAM_<NAME>_* variables such as DOWNSTREAM_BILLING_BASE_URL
+func loadDownstream() map[string]DownstreamConfig {
+	names := getEnv("DOWNSTREAM_SERVICES", "")
+	if names == "" {
+		return nil
 	}
+
+	services := make(map[string]DownstreamConfig)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "DOWNSTREAM_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name)) + "_"
+		services[name] = DownstreamConfig{
+			BaseURL:            getEnv(prefix+"BASE_URL", ""),
+			Timeout:            getEnvAsDuration(prefix+"TIMEOUT", 0),
+			RetryEnabled:       getEnvAsBool(prefix+"RETRY_ENABLED", true),
+			MaxRetries:         getEnvAsInt(prefix+"MAX_RETRIES", 0),
+			BreakerEnabled:     getEnvAsBool(prefix+"BREAKER_ENABLED", true),
+			BreakerTimeout:     getEnvAsDuration(prefix+"BREAKER_TIMEOUT", 0),
+			AuthType:           getEnv(prefix+"AUTH_TYPE", ""),
+			AuthToken:          getEnv(prefix+"AUTH_TOKEN", ""),
+			AuthHeader:         getEnv(prefix+"AUTH_HEADER", ""),
+			OAuth2TokenURL:     getEnv(prefix+"OAUTH2_TOKEN_URL", ""),
+			OAuth2ClientID:     getEnv(prefix+"OAUTH2_CLIENT_ID", ""),
+			OAuth2ClientSecret: getEnv(prefix+"OAUTH2_CLIENT_SECRET", ""),
+			OAuth2Scopes:       getEnvAsList(prefix+"OAUTH2_SCOPES", nil),
+			ServiceAuth:        getEnvAsBool(prefix+"SERVICE_AUTH", true),
+			Audience:           getEnv(prefix+"AUDIENCE", name),
+		}
+	}
+
+	return services
 }
 
 // getEnv retrieves an environment variable or returns a default value
@@ -158,3 +1173,102 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 
 	return value
 }
+
+// getEnvAsMap retrieves an environment variable of comma-separated key=value pairs as a map
+// Malformed pairs are skipped; returns nil when the variable is unset or empty
+func getEnvAsMap(key string) map[string]string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(valueStr, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" {
+			continue
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
+// getEnvAsFloatMap retrieves an environment variable of comma-separated key=number pairs as a map
+// Pairs with malformed numbers are skipped; returns nil when the variable is unset or empty
+func getEnvAsFloatMap(key string) map[string]float64 {
+	pairs := getEnvAsMap(key)
+	if pairs == nil {
+		return nil
+	}
+
+	result := make(map[string]float64, len(pairs))
+	for k, v := range pairs {
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		result[k] = value
+	}
+
+	return result
+}
+
+// getEnvAsIntMap retrieves an environment variable of comma-separated key=integer pairs as a map
+// Pairs with malformed integers are skipped; returns nil when the variable is unset or empty
+func getEnvAsIntMap(key string) map[string]int {
+	pairs := getEnvAsMap(key)
+	if pairs == nil {
+		return nil
+	}
+
+	result := make(map[string]int, len(pairs))
+	for k, v := range pairs {
+		value, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		result[k] = value
+	}
+
+	return result
+}
+
+// getEnvAsList retrieves an environment variable of comma-separated values as a slice or returns a default value
+// Empty values are skipped
+func getEnvAsList(key string, defaultValue []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// getEnvAsIntList retrieves a comma-separated list of integers or returns a default value
+// A malformed number returns the default value rather than a partial list
+func getEnvAsIntList(key string, defaultValue []int) []int {
+	values := getEnvAsList(key, nil)
+	if values == nil {
+		return defaultValue
+	}
+
+	result := make([]int, 0, len(values))
+	for _, value := range values {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return defaultValue
+		}
+		result = append(result, n)
+	}
+
+	return result
+}
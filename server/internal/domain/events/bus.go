@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Handler consumes events
+type Handler func(ctx context.Context, event *Event) error
+
+// Publisher publishes events to their consumers
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// Bus is an in-process Publisher delivering every event to the subscribed handlers, in the order
+// they subscribed
+// Delivery is synchronous: Publish returns once every handler ran, so handlers should only hand
+// events off, e.g. by enqueuing a job.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus creates a Bus without subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe delivers the events published from now on to handler
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers event to every handler, even when some fail, and returns their joined errors
+func (b *Bus) Publish(ctx context.Context, event *Event) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to handle %s event %s: %w", event.Key(), event.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
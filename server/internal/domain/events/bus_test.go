@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/domain"
+)
+
+func TestBus_Publish(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: "user-1", Name: "Jane", Email: "jane@example.com", CreatedAt: testTime}
+	event, err := New(testIDs(), testTime, UserCreated(user))
+	require.NoError(t, err)
+
+	t.Run("Delivers to every handler", func(t *testing.T) {
+		bus := NewBus()
+		var delivered []string
+		bus.Subscribe(func(ctx context.Context, event *Event) error {
+			delivered = append(delivered, "first")
+			return errors.New("unavailable")
+		})
+		bus.Subscribe(func(ctx context.Context, event *Event) error {
+			delivered = append(delivered, "second")
+			return nil
+		})
+
+		err := bus.Publish(ctx, event)
+		assert.ErrorContains(t, err, "user.created.v1 event event-1: unavailable")
+		assert.Equal(t, []string{"first", "second"}, delivered, "a failing handler does not stop delivery")
+	})
+
+	t.Run("Without subscribers", func(t *testing.T) {
+		assert.NoError(t, NewBus().Publish(ctx, event))
+	})
+}
@@ -0,0 +1,211 @@
+// Package events defines the versioned domain events every producer and consumer agrees on
+//
+// An event is an Event envelope around a Payload identified by its type and schema version, e.g.
+// user.created v1. Payload shapes never change once published: a breaking change is a new payload
+// type with the next version (UserCreatedV2), registered alongside the old one so consumers can
+// still decode events written before the change.
+//
+// Producers Publish events to a Publisher; Bus delivers them to the handlers subscribed in the
+// process.
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"quizizz.com/pkg/idgen"
+)
+
+// Errors returned when building or decoding events
+var (
+	ErrUnknownEvent = errors.New("unknown event type or version")
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// Payload is the body of an event
+type Payload interface {
+	// EventType names the event, e.g. user.created
+	EventType() string
+
+	// EventVersion is the schema version of the payload, starting at 1
+	EventVersion() int
+
+	// Validate reports whether the payload satisfies its schema
+	Validate() error
+}
+
+// Event is the envelope shared by all events
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Payload    Payload   `json:"-"`
+}
+
+// New returns an event with an ID from ids wrapping payload, or ErrInvalidEvent if the payload is invalid
+func New(ids idgen.Generator, occurredAt time.Time, payload Payload) (*Event, error) {
+	if err := validate(payload); err != nil {
+		return nil, err
+	}
+	return &Event{
+		ID:         ids.NewID(),
+		Type:       payload.EventType(),
+		Version:    payload.EventVersion(),
+		OccurredAt: occurredAt.UTC(),
+		Payload:    payload,
+	}, nil
+}
+
+// Key returns the type and version of the event, e.g. user.created.v1
+func (e *Event) Key() string {
+	return key(e.Type, e.Version)
+}
+
+// envelope is the encoded form of an Event
+type envelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// MarshalJSON encodes the event with its payload under data
+func (e Event) MarshalJSON() ([]byte, error) {
+	if e.Payload == nil {
+		return nil, fmt.Errorf("%w: %s has no payload", ErrInvalidEvent, key(e.Type, e.Version))
+	}
+	if e.Type != e.Payload.EventType() || e.Version != e.Payload.EventVersion() {
+		return nil, fmt.Errorf("%w: %s does not match its %T payload", ErrInvalidEvent, key(e.Type, e.Version), e.Payload)
+	}
+
+	data, err := json.Marshal(e.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{
+		ID:         e.ID,
+		Type:       e.Type,
+		Version:    e.Version,
+		OccurredAt: e.OccurredAt,
+		Data:       data,
+	})
+}
+
+// UnmarshalJSON decodes an event into the payload registered for its type and version
+// Payloads must match their schema exactly: unknown fields and invalid values are rejected.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+
+	newPayload, ok := lookup(env.Type, env.Version)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, key(env.Type, env.Version))
+	}
+	payload := newPayload()
+
+	decoder := json.NewDecoder(bytes.NewReader(env.Data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidEvent, key(env.Type, env.Version), err)
+	}
+	if err := validate(payload); err != nil {
+		return err
+	}
+
+	*e = Event{
+		ID:         env.ID,
+		Type:       env.Type,
+		Version:    env.Version,
+		OccurredAt: env.OccurredAt,
+		Payload:    payload,
+	}
+	return nil
+}
+
+// Marshal encodes event for a transport such as a message bus or webhook
+func Marshal(event *Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Unmarshal decodes an event encoded by Marshal
+func Unmarshal(data []byte) (*Event, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Schema identifies a registered event payload
+type Schema struct {
+	Type    string
+	Version int
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]func() Payload)
+)
+
+// Register makes the payload returned by newPayload decodable
+// It panics if the type and version are already registered, as two shapes for one schema is a bug.
+func Register(newPayload func() Payload) {
+	p := newPayload()
+	k := key(p.EventType(), p.EventVersion())
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[k]; ok {
+		panic("events: " + k + " registered twice")
+	}
+	registry[k] = newPayload
+}
+
+// Schemas returns the registered payloads sorted by type and version
+func Schemas() []Schema {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	schemas := make([]Schema, 0, len(registry))
+	for _, newPayload := range registry {
+		p := newPayload()
+		schemas = append(schemas, Schema{Type: p.EventType(), Version: p.EventVersion()})
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].Type != schemas[j].Type {
+			return schemas[i].Type < schemas[j].Type
+		}
+		return schemas[i].Version < schemas[j].Version
+	})
+	return schemas
+}
+
+func lookup(eventType string, version int) (func() Payload, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	newPayload, ok := registry[key(eventType, version)]
+	return newPayload, ok
+}
+
+func validate(payload Payload) error {
+	if payload == nil {
+		return fmt.Errorf("%w: no payload", ErrInvalidEvent)
+	}
+	if err := payload.Validate(); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidEvent, key(payload.EventType(), payload.EventVersion()), err)
+	}
+	return nil
+}
+
+func key(eventType string, version int) string {
+	return fmt.Sprintf("%s.v%d", eventType, version)
+}
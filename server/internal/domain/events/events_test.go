@@ -0,0 +1,99 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/idgen"
+)
+
+var testTime = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testIDs() idgen.Generator {
+	return idgen.Func(func() string { return "event-1" })
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Valid payload", func(t *testing.T) {
+		user := &domain.User{ID: "user-1", Name: "Jane", Email: "jane@example.com", CreatedAt: testTime}
+
+		event, err := New(testIDs(), testTime, UserCreated(user))
+		require.NoError(t, err)
+		assert.Equal(t, "event-1", event.ID)
+		assert.Equal(t, UserCreatedType, event.Type)
+		assert.Equal(t, 1, event.Version)
+		assert.Equal(t, "user.created.v1", event.Key())
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		_, err := New(testIDs(), testTime, &UserCreatedV1{UserID: "user-1", Name: "Jane", Email: "jane", CreatedAt: testTime})
+		assert.ErrorIs(t, err, ErrInvalidEvent)
+		assert.ErrorIs(t, err, domain.ErrInvalidEmail)
+	})
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	for _, payload := range []Payload{
+		&UserCreatedV1{UserID: "user-1", Name: "Jane", Email: "jane@example.com", CreatedAt: testTime},
+		&UserUpdatedV1{UserID: "user-1", Name: "Jane", Email: "jane@example.com", Version: 2, UpdatedAt: testTime},
+		UserDeleted("user-1", testTime),
+	} {
+		event, err := New(testIDs(), testTime, payload)
+		require.NoError(t, err)
+
+		data, err := Marshal(event)
+		require.NoError(t, err)
+
+		decoded, err := Unmarshal(data)
+		require.NoError(t, err, string(data))
+		assert.Equal(t, event, decoded)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("Encoded shape", func(t *testing.T) {
+		event, err := New(testIDs(), testTime, UserDeleted("user-1", testTime))
+		require.NoError(t, err)
+
+		data, err := Marshal(event)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id": "event-1",
+			"type": "user.deleted",
+			"version": 1,
+			"occurred_at": "2024-01-01T12:00:00Z",
+			"data": {"user_id": "user-1", "deleted_at": "2024-01-01T12:00:00Z"}
+		}`, string(data))
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		_, err := Unmarshal([]byte(`{"id":"e","type":"user.deleted","version":9,"data":{}}`))
+		assert.ErrorIs(t, err, ErrUnknownEvent)
+	})
+
+	t.Run("Unknown fields", func(t *testing.T) {
+		_, err := Unmarshal([]byte(`{"id":"e","type":"user.deleted","version":1,
+			"data":{"user_id":"user-1","deleted_at":"2024-01-01T12:00:00Z","reason":"spam"}}`))
+		assert.ErrorIs(t, err, ErrInvalidEvent)
+	})
+
+	t.Run("Missing fields", func(t *testing.T) {
+		_, err := Unmarshal([]byte(`{"id":"e","type":"user.deleted","version":1,"data":{"user_id":"user-1"}}`))
+		assert.ErrorIs(t, err, ErrInvalidEvent)
+	})
+}
+
+func TestSchemas(t *testing.T) {
+	assert.Equal(t, []Schema{
+		{Type: UserCreatedType, Version: 1},
+		{Type: UserDeletedType, Version: 1},
+		{Type: UserUpdatedType, Version: 1},
+	}, Schemas())
+
+	assert.Panics(t, func() {
+		Register(func() Payload { return &UserCreatedV1{} })
+	})
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"errors"
+	"time"
+
+	"quizizz.com/internal/domain"
+)
+
+// User event types
+const (
+	UserCreatedType = "user.created"
+	UserUpdatedType = "user.updated"
+	UserDeletedType = "user.deleted"
+)
+
+func init() {
+	Register(func() Payload { return &UserCreatedV1{} })
+	Register(func() Payload { return &UserUpdatedV1{} })
+	Register(func() Payload { return &UserDeletedV1{} })
+}
+
+// UserCreatedV1 is published when a user is created
+type UserCreatedV1 struct {
+	UserID    string          `json:"user_id"`
+	Name      domain.UserName `json:"name"`
+	Email     domain.Email    `json:"email"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// UserCreated returns the user.created payload for user
+func UserCreated(user *domain.User) *UserCreatedV1 {
+	return &UserCreatedV1{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func (*UserCreatedV1) EventType() string { return UserCreatedType }
+
+func (*UserCreatedV1) EventVersion() int { return 1 }
+
+func (p *UserCreatedV1) Validate() error {
+	if p.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if p.CreatedAt.IsZero() {
+		return errors.New("created_at is required")
+	}
+	if err := p.Name.Validate(); err != nil {
+		return err
+	}
+	return p.Email.Validate()
+}
+
+// UserUpdatedV1 is published when a user is updated, with the user's fields after the update
+type UserUpdatedV1 struct {
+	UserID    string          `json:"user_id"`
+	Name      domain.UserName `json:"name"`
+	Email     domain.Email    `json:"email"`
+	Version   int64           `json:"version"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// UserUpdated returns the user.updated payload for user
+func UserUpdated(user *domain.User) *UserUpdatedV1 {
+	return &UserUpdatedV1{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Version:   user.Version,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+func (*UserUpdatedV1) EventType() string { return UserUpdatedType }
+
+func (*UserUpdatedV1) EventVersion() int { return 1 }
+
+func (p *UserUpdatedV1) Validate() error {
+	if p.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if p.Version < 0 {
+		return errors.New("version must not be negative")
+	}
+	if p.UpdatedAt.IsZero() {
+		return errors.New("updated_at is required")
+	}
+	if err := p.Name.Validate(); err != nil {
+		return err
+	}
+	return p.Email.Validate()
+}
+
+// UserDeletedV1 is published when a user is deleted
+type UserDeletedV1 struct {
+	UserID    string    `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// UserDeleted returns the user.deleted payload for the user with id
+func UserDeleted(id string, deletedAt time.Time) *UserDeletedV1 {
+	return &UserDeletedV1{UserID: id, DeletedAt: deletedAt}
+}
+
+func (*UserDeletedV1) EventType() string { return UserDeletedType }
+
+func (*UserDeletedV1) EventVersion() int { return 1 }
+
+func (p *UserDeletedV1) Validate() error {
+	if p.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if p.DeletedAt.IsZero() {
+		return errors.New("deleted_at is required")
+	}
+	return nil
+}
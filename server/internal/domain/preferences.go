@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// ErrInvalidPreferences is wrapped by the validation errors of preference settings
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// Preference values
+var (
+	Themes        = []string{"system", "light", "dark"}
+	DigestOptions = []string{"off", "daily", "weekly"}
+)
+
+// localePattern matches BCP 47 tags made of a language and an optional region, e.g. en or pt-BR
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// Preferences are the effective settings of a user: their own settings merged over the defaults
+type Preferences struct {
+	Theme         string                  `json:"theme"`
+	Locale        string                  `json:"locale"`
+	Notifications NotificationPreferences `json:"notifications"`
+}
+
+// NotificationPreferences are the notification settings of a user
+type NotificationPreferences struct {
+	Email  bool   `json:"email"`
+	Push   bool   `json:"push"`
+	Digest string `json:"digest"`
+}
+
+// DefaultPreferences returns the settings of users who have not set their own
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Theme:  "system",
+		Locale: "en",
+		Notifications: NotificationPreferences{
+			Email:  true,
+			Push:   true,
+			Digest: "weekly",
+		},
+	}
+}
+
+// PreferenceSettings are the settings a user chose; nil fields take their default, so defaults
+// changed later apply to every user who did not choose otherwise
+type PreferenceSettings struct {
+	Theme         *string              `json:"theme,omitempty" bson:"theme,omitempty"`
+	Locale        *string              `json:"locale,omitempty" bson:"locale,omitempty"`
+	Notifications NotificationSettings `json:"notifications" bson:"notifications"`
+}
+
+// NotificationSettings are the notification settings a user chose
+type NotificationSettings struct {
+	Email  *bool   `json:"email,omitempty" bson:"email,omitempty"`
+	Push   *bool   `json:"push,omitempty" bson:"push,omitempty"`
+	Digest *string `json:"digest,omitempty" bson:"digest,omitempty"`
+}
+
+// UserPreferences are the stored settings of a user
+type UserPreferences struct {
+	UserID    string
+	Settings  PreferenceSettings
+	UpdatedAt time.Time
+	Version   int64
+}
+
+// Effective returns the settings of p merged over the defaults; p may be nil
+func (p *UserPreferences) Effective() Preferences {
+	prefs := DefaultPreferences()
+	if p == nil {
+		return prefs
+	}
+	return p.Settings.Apply(prefs)
+}
+
+// Apply returns prefs with the settings of s chosen
+func (s PreferenceSettings) Apply(prefs Preferences) Preferences {
+	setIf(&prefs.Theme, s.Theme)
+	setIf(&prefs.Locale, s.Locale)
+	setIf(&prefs.Notifications.Email, s.Notifications.Email)
+	setIf(&prefs.Notifications.Push, s.Notifications.Push)
+	setIf(&prefs.Notifications.Digest, s.Notifications.Digest)
+	return prefs
+}
+
+// Validate reports the first invalid setting of s, wrapping ErrInvalidPreferences
+func (s PreferenceSettings) Validate() error {
+	if s.Theme != nil && !slices.Contains(Themes, *s.Theme) {
+		return fmt.Errorf("%w: theme must be one of %v", ErrInvalidPreferences, Themes)
+	}
+	if s.Locale != nil && !localePattern.MatchString(*s.Locale) {
+		return fmt.Errorf("%w: locale %q is not a language tag such as en or pt-BR", ErrInvalidPreferences, *s.Locale)
+	}
+	if digest := s.Notifications.Digest; digest != nil && !slices.Contains(DigestOptions, *digest) {
+		return fmt.Errorf("%w: notifications.digest must be one of %v", ErrInvalidPreferences, DigestOptions)
+	}
+	return nil
+}
+
+// setIf sets *dst to *value when value is not nil
+func setIf[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
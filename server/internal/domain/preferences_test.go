@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserPreferences_Effective(t *testing.T) {
+	dark, digest, push := "dark", "off", false
+
+	var none *UserPreferences
+	assert.Equal(t, DefaultPreferences(), none.Effective())
+
+	prefs := &UserPreferences{Settings: PreferenceSettings{
+		Theme:         &dark,
+		Notifications: NotificationSettings{Push: &push, Digest: &digest},
+	}}
+	effective := prefs.Effective()
+	assert.Equal(t, "dark", effective.Theme)
+	assert.Equal(t, "en", effective.Locale, "default")
+	assert.True(t, effective.Notifications.Email, "default")
+	assert.False(t, effective.Notifications.Push)
+	assert.Equal(t, "off", effective.Notifications.Digest)
+}
+
+func TestPreferenceSettings_Validate(t *testing.T) {
+	valid, ptBR := "light", "pt-BR"
+	assert.NoError(t, PreferenceSettings{Theme: &valid, Locale: &ptBR}.Validate())
+	assert.NoError(t, PreferenceSettings{}.Validate())
+
+	neon, locale, hourly := "neon", "english", "hourly"
+	for name, settings := range map[string]PreferenceSettings{
+		"theme":  {Theme: &neon},
+		"locale": {Locale: &locale},
+		"digest": {Notifications: NotificationSettings{Digest: &hourly}},
+	} {
+		assert.ErrorIs(t, settings.Validate(), ErrInvalidPreferences, name)
+	}
+}
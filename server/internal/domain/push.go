@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Push notification errors
+var (
+	// ErrInvalidDevice is wrapped by the validation errors of device registrations and topics
+	ErrInvalidDevice = errors.New("invalid device")
+
+	// ErrInvalidNotification is wrapped by the validation errors of notifications
+	ErrInvalidNotification = errors.New("invalid notification")
+)
+
+// DevicePlatforms are the platforms devices can be registered on
+var DevicePlatforms = []string{"android", "ios", "web"}
+
+// Push notification limits
+const (
+	// MaxDeviceTopics bounds the topics a device subscribes to
+	MaxDeviceTopics = 100
+
+	// MaxNotificationBytes bounds the content of a notification, leaving room in the 4KB payload
+	// limit of the providers for their own fields
+	MaxNotificationBytes = 3072
+
+	maxDeviceTokenLength = 4096
+	maxDeviceNameLength  = 100
+)
+
+// topicPattern matches topic names, e.g. news or class-42.announcements
+var topicPattern = regexp.MustCompile(`^[a-zA-Z0-9_.~-]{1,100}$`)
+
+// Device is a device of a user registered for push notifications
+type Device struct {
+	ID       string
+	UserID   string
+	Platform string
+
+	// Token is the token the provider of the platform gave the app on the device
+	Token string
+
+	// Name is the name the client gave the device, e.g. "Pixel 8"
+	Name string
+
+	// Topics are the topics the device subscribes to
+	Topics []string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate reports the first invalid field of a device registration, wrapping ErrInvalidDevice
+func (d *Device) Validate() error {
+	if !slices.Contains(DevicePlatforms, d.Platform) {
+		return fmt.Errorf("%w: platform must be one of %v", ErrInvalidDevice, DevicePlatforms)
+	}
+	if d.Token == "" || len(d.Token) > maxDeviceTokenLength {
+		return fmt.Errorf("%w: token must be 1 to %d characters", ErrInvalidDevice, maxDeviceTokenLength)
+	}
+	if len(d.Name) > maxDeviceNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidDevice, maxDeviceNameLength)
+	}
+	return nil
+}
+
+// ValidateTopic returns an error wrapping ErrInvalidDevice unless topic is a valid topic name
+func ValidateTopic(topic string) error {
+	if !topicPattern.MatchString(topic) {
+		return fmt.Errorf("%w: topic %q must be 1 to 100 letters, digits or any of _.~-", ErrInvalidDevice, topic)
+	}
+	return nil
+}
+
+// Notification is a push notification sent to the devices of a user or of a topic's subscribers
+type Notification struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+
+	// CollapseKey lets a notification replace an undelivered one with the same key
+	CollapseKey string `json:"collapse_key,omitempty"`
+}
+
+// Validate reports the first invalid field of a notification, wrapping ErrInvalidNotification
+func (n *Notification) Validate() error {
+	if n.Title == "" && n.Body == "" {
+		return fmt.Errorf("%w: title or body is required", ErrInvalidNotification)
+	}
+
+	size := len(n.Title) + len(n.Body) + len(n.CollapseKey)
+	for key, value := range n.Data {
+		if key == "" || key == "aps" {
+			return fmt.Errorf("%w: data key %q is reserved", ErrInvalidNotification, key)
+		}
+		size += len(key) + len(value)
+	}
+	if size > MaxNotificationBytes {
+		return fmt.Errorf("%w: content must be at most %d bytes", ErrInvalidNotification, MaxNotificationBytes)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevice_Validate(t *testing.T) {
+	assert.NoError(t, (&Device{Platform: "ios", Token: "token", Name: "iPhone"}).Validate())
+
+	for name, device := range map[string]*Device{
+		"platform": {Platform: "symbian", Token: "token"},
+		"no token": {Platform: "android"},
+		"token":    {Platform: "android", Token: strings.Repeat("t", 4097)},
+		"name":     {Platform: "web", Token: "token", Name: strings.Repeat("n", 101)},
+	} {
+		assert.ErrorIs(t, device.Validate(), ErrInvalidDevice, name)
+	}
+}
+
+func TestValidateTopic(t *testing.T) {
+	assert.NoError(t, ValidateTopic("class-42.announcements"))
+
+	for _, topic := range []string{"", "two words", "news/sports", strings.Repeat("t", 101)} {
+		assert.ErrorIs(t, ValidateTopic(topic), ErrInvalidDevice, topic)
+	}
+}
+
+func TestNotification_Validate(t *testing.T) {
+	assert.NoError(t, (&Notification{Title: "Hello", Data: map[string]string{"quizId": "42"}}).Validate())
+
+	for name, notification := range map[string]*Notification{
+		"empty":    {},
+		"reserved": {Title: "Hello", Data: map[string]string{"aps": "{}"}},
+		"too long": {Body: strings.Repeat("b", MaxNotificationBytes+1)},
+	} {
+		assert.ErrorIs(t, notification.Validate(), ErrInvalidNotification, name)
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidUpload is wrapped by the validation errors of uploads
+var ErrInvalidUpload = errors.New("invalid upload")
+
+// Upload statuses
+const (
+	// UploadPending means the upload was presigned and its object is not confirmed yet
+	UploadPending = "pending"
+
+	// UploadCompleted means the object was uploaded and matched the presigned constraints
+	UploadCompleted = "completed"
+)
+
+// maxUploadFilenameLength bounds the filenames of uploads
+const maxUploadFilenameLength = 255
+
+// Upload is a file a user uploads straight to object storage through a presigned URL
+type Upload struct {
+	ID     string
+	UserID string
+
+	// Key is the key of the object in the bucket
+	Key string
+
+	// Filename is the name of the file on the client, kept for downloads
+	Filename string
+
+	// ContentType and Size are declared when presigning; the uploaded object must match them
+	ContentType string
+	Size        int64
+
+	Status string
+
+	// ETag is the entity tag storage gave the object, set once the upload is completed
+	ETag string
+
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	CompletedAt time.Time
+}
+
+// Validate reports the first invalid field of an upload request, wrapping ErrInvalidUpload
+func (u *Upload) Validate() error {
+	if u.Filename == "" || len(u.Filename) > maxUploadFilenameLength || !utf8.ValidString(u.Filename) {
+		return fmt.Errorf("%w: filename must be 1 to %d bytes of UTF-8", ErrInvalidUpload, maxUploadFilenameLength)
+	}
+	if strings.ContainsAny(u.Filename, "/\\\x00") {
+		return fmt.Errorf("%w: filename must not contain path separators", ErrInvalidUpload)
+	}
+	if _, _, err := mime.ParseMediaType(u.ContentType); err != nil || !strings.Contains(u.ContentType, "/") {
+		return fmt.Errorf("%w: %q is not a content type", ErrInvalidUpload, u.ContentType)
+	}
+	if u.Size <= 0 {
+		return fmt.Errorf("%w: size must be positive", ErrInvalidUpload)
+	}
+	return nil
+}
+
+// MediaType returns the content type without its parameters, e.g. image/png
+func (u *Upload) MediaType() string {
+	mediaType, _, err := mime.ParseMediaType(u.ContentType)
+	if err != nil {
+		return u.ContentType
+	}
+	return mediaType
+}
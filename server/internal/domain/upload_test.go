@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpload_Validate(t *testing.T) {
+	assert.NoError(t, (&Upload{Filename: "report.pdf", ContentType: "application/pdf", Size: 1024}).Validate())
+
+	for name, upload := range map[string]*Upload{
+		"no filename":  {ContentType: "image/png", Size: 1},
+		"filename":     {Filename: strings.Repeat("f", 256), ContentType: "image/png", Size: 1},
+		"path":         {Filename: "../etc/passwd", ContentType: "text/plain", Size: 1},
+		"content type": {Filename: "photo.png", ContentType: "png", Size: 1},
+		"size":         {Filename: "photo.png", ContentType: "image/png"},
+	} {
+		assert.ErrorIs(t, upload.Validate(), ErrInvalidUpload, name)
+	}
+}
+
+func TestUpload_MediaType(t *testing.T) {
+	assert.Equal(t, "text/csv", (&Upload{ContentType: "text/csv; charset=utf-8"}).MediaType())
+}
@@ -1,23 +1,102 @@
 package domain
 
 import (
+	"slices"
 	"time"
+
+	"quizizz.com/pkg/idgen"
 )
 
 // User represents a user in the system
 type User struct {
 	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
+	Name      UserName  `json:"name"`
+	Email     Email     `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+
+	// Roles grant the user permissions beyond their own data, e.g. admin
+	Roles []string `json:"roles,omitempty"`
+
+	// Locked users are locked out by an administrator: their sessions are revoked and they cannot sign in
+	Locked bool `json:"locked,omitempty"`
+
+	// PasswordResetRequired makes the user choose a new password at their next sign-in
+	PasswordResetRequired bool `json:"password_reset_required,omitempty"`
+
+	// Avatar is the profile image of the user, nil until one is uploaded and processed
+	Avatar *Avatar `json:"avatar,omitempty"`
 }
 
-// NewUser creates a new User
-func NewUser(name, email string) *User {
-	now := time.Now()
+// Avatar is the profile image of a user, stored as square WebP variants of standard sizes
+type Avatar struct {
+	// Version identifies the upload the variants were made from, so their URLs change with every upload
+	Version string `json:"version" bson:"version"`
+
+	// Variants holds the stored file of each variant by its size in pixels, e.g. "128"
+	Variants map[string]string `json:"variants" bson:"variants"`
+
+	UpdatedAt time.Time `json:"updated_at" bson:"updatedAt"`
+}
+
+// UserFilter narrows down a set of users; empty fields match all users
+type UserFilter struct {
+	Query         string    // full-text search on the name, matching any of its words
+	Name          string    // case-insensitive substring match
+	Email         Email     // exact match
+	CreatedAfter  time.Time // created at or after
+	CreatedBefore time.Time // created strictly before
+	Role          string    // holding the role
+	Locked        *bool     // locked, or not locked, when set
+}
+
+// User sort fields
+const (
+	UserSortName      = "name"
+	UserSortEmail     = "email"
+	UserSortCreatedAt = "created_at"
+)
+
+// UserSortFields lists the fields users can be sorted by
+var UserSortFields = []string{UserSortName, UserSortEmail, UserSortCreatedAt}
+
+// ListOptions orders and pages a list; a zero Limit returns all items
+type ListOptions struct {
+	Sort       string // one of the list's sort fields, its default order when empty
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+// UserStats summarizes the user base for administrators
+type UserStats struct {
+	Total  int64 `json:"total"`
+	Locked int64 `json:"locked"`
+
+	// SignupsByDay counts the users created on each day of the period, oldest first; days without
+	// signups are omitted
+	SignupsByDay []DailyCount `json:"signups_by_day"`
+
+	// RecentSignups are the newest users, newest first
+	RecentSignups []*User `json:"recent_signups"`
+}
+
+// DailyCount is a count for a day, in UTC
+type DailyCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int64  `json:"count"`
+}
+
+// HasRole reports whether u holds role
+func (u *User) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+// NewUser creates a new User created at now, with an ID from ids
+func NewUser(ids idgen.Generator, name UserName, email Email, now time.Time) *User {
 	return &User{
-		ID:        GenerateID(),
+		ID:        ids.NewID(),
 		Name:      name,
 		Email:     email,
 		CreatedAt: now,
@@ -25,8 +104,10 @@ func NewUser(name, email string) *User {
 	}
 }
 
-// GenerateID generates a new ID for a user
-// In a real application, you might use UUID or another ID generation strategy
-func GenerateID() string {
-	return time.Now().Format("20060102150405") + "-user"
+// Validate reports the first invalid field of u, wrapping ErrInvalidUserName or ErrInvalidEmail
+func (u *User) Validate() error {
+	if err := u.Name.Validate(); err != nil {
+		return err
+	}
+	return u.Email.Validate()
 }
@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Validation errors of value types; errors returned by the Parse functions are *ValidationError
+// wrapping one of these
+var (
+	ErrInvalidEmail    = errors.New("invalid email")
+	ErrInvalidUserName = errors.New("invalid user name")
+)
+
+// ValidationError is a value rejected by a Parse function or Validate, wrapping ErrInvalidEmail or
+// ErrInvalidUserName
+// Message describes the problem to API clients; it never includes the rejected value, so it stays
+// the same for every value failing the same rule.
+type ValidationError struct {
+	Err     error
+	Message string
+}
+
+// Error returns the sentinel and the message
+func (e *ValidationError) Error() string {
+	return e.Err.Error() + ": " + e.Message
+}
+
+// Unwrap returns the sentinel
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// invalid returns the ValidationError of err with message
+func invalid(err error, message string) error {
+	return &ValidationError{Err: err, Message: message}
+}
+
+// Length limits of value types
+const (
+	MaxEmailLength    = 254 // RFC 5321 path limit
+	MaxUserNameLength = 100 // in characters
+)
+
+// Email is a validated, normalized email address
+// The zero value means no email; values from ParseEmail are never empty.
+type Email string
+
+// ParseEmail validates s as a bare address (no display name) and normalizes it to lower case
+func ParseEmail(s string) (Email, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", invalid(ErrInvalidEmail, "Email is required")
+	}
+	if len(s) > MaxEmailLength {
+		return "", invalid(ErrInvalidEmail, fmt.Sprintf("Email must be at most %d characters", MaxEmailLength))
+	}
+
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return "", invalid(ErrInvalidEmail, "Email is not a valid address")
+	}
+	if !strings.Contains(s[strings.LastIndexByte(s, '@')+1:], ".") {
+		return "", invalid(ErrInvalidEmail, "Email must include a domain")
+	}
+	return Email(strings.ToLower(s)), nil
+}
+
+// String returns the address
+func (e Email) String() string {
+	return string(e)
+}
+
+// Validate reports whether e is an address ParseEmail would return, e.g. for values converted directly
+func (e Email) Validate() error {
+	parsed, err := ParseEmail(string(e))
+	if err != nil {
+		return err
+	}
+	if parsed != e {
+		return invalid(ErrInvalidEmail, "Email must be trimmed and lower case")
+	}
+	return nil
+}
+
+// UnmarshalJSON parses a JSON string with ParseEmail; an empty string leaves the email unset
+func (e *Email) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONValue(data, e, ParseEmail)
+}
+
+// MarshalBSONValue stores the email as a BSON string
+func (e Email) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(string(e))
+}
+
+// UnmarshalBSONValue parses a stored BSON string with ParseEmail
+func (e *Email) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalBSONValue(t, data, e, ParseEmail)
+}
+
+// UserName is a validated display name: trimmed, non-empty, at most MaxUserNameLength characters
+// and free of control characters
+type UserName string
+
+// ParseUserName validates s as a user name, trimming surrounding whitespace
+func ParseUserName(s string) (UserName, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", invalid(ErrInvalidUserName, "Name is required")
+	}
+	if !utf8.ValidString(s) {
+		return "", invalid(ErrInvalidUserName, "Name must be valid UTF-8")
+	}
+	if utf8.RuneCountInString(s) > MaxUserNameLength {
+		return "", invalid(ErrInvalidUserName, fmt.Sprintf("Name must be at most %d characters", MaxUserNameLength))
+	}
+	if strings.IndexFunc(s, unicode.IsControl) >= 0 {
+		return "", invalid(ErrInvalidUserName, "Name must not contain control characters")
+	}
+	return UserName(s), nil
+}
+
+// String returns the name
+func (n UserName) String() string {
+	return string(n)
+}
+
+// Validate reports whether n is a name ParseUserName would return, e.g. for values converted directly
+func (n UserName) Validate() error {
+	parsed, err := ParseUserName(string(n))
+	if err != nil {
+		return err
+	}
+	if parsed != n {
+		return invalid(ErrInvalidUserName, "Name must not have surrounding whitespace")
+	}
+	return nil
+}
+
+// UnmarshalJSON parses a JSON string with ParseUserName; an empty string leaves the name unset
+func (n *UserName) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONValue(data, n, ParseUserName)
+}
+
+// MarshalBSONValue stores the name as a BSON string
+func (n UserName) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(string(n))
+}
+
+// UnmarshalBSONValue parses a stored BSON string with ParseUserName
+func (n *UserName) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalBSONValue(t, data, n, ParseUserName)
+}
+
+// unmarshalJSONValue decodes a JSON string into v with parse
+// Empty strings and null leave v unset, so partial updates can omit fields.
+func unmarshalJSONValue[T ~string](data []byte, v *T, parse func(string) (T, error)) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		*v = ""
+		return nil
+	}
+	parsed, err := parse(*s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// unmarshalBSONValue decodes a stored BSON string into v with parse; null and missing values leave v unset
+func unmarshalBSONValue[T ~string](t bsontype.Type, data []byte, v *T, parse func(string) (T, error)) error {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*v = ""
+		return nil
+	case bsontype.String:
+	default:
+		return fmt.Errorf("cannot decode BSON %s into %T", t, v)
+	}
+
+	var s string
+	if err := bson.UnmarshalValue(t, data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*v = ""
+		return nil
+	}
+	parsed, err := parse(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseEmail(t *testing.T) {
+	t.Run("Valid emails are normalized", func(t *testing.T) {
+		email, err := ParseEmail("  Jane.Doe+test@Example.COM ")
+		require.NoError(t, err)
+		assert.Equal(t, Email("jane.doe+test@example.com"), email)
+		assert.NoError(t, email.Validate())
+	})
+
+	t.Run("Invalid emails", func(t *testing.T) {
+		for s, message := range map[string]string{
+			"":                        "Email is required",
+			"jane":                    "Email is not a valid address",
+			"jane@":                   "Email is not a valid address",
+			"@example.com":            "Email is not a valid address",
+			"jane@localhost":          "Email must include a domain",
+			"Jane <jane@example.com>": "Email is not a valid address",
+			"jane doe@example.com":    "Email is not a valid address",
+			strings.Repeat("a", MaxEmailLength) + "@example.com": "Email must be at most 254 characters",
+		} {
+			_, err := ParseEmail(s)
+			assert.ErrorIs(t, err, ErrInvalidEmail, s)
+
+			var invalid *ValidationError
+			require.ErrorAs(t, err, &invalid, s)
+			assert.Equal(t, message, invalid.Message, s)
+		}
+	})
+
+	t.Run("Unnormalized values are invalid", func(t *testing.T) {
+		assert.ErrorIs(t, Email("Jane@example.com").Validate(), ErrInvalidEmail)
+	})
+}
+
+func TestParseUserName(t *testing.T) {
+	t.Run("Valid names are trimmed", func(t *testing.T) {
+		name, err := ParseUserName("  Jane Doe ")
+		require.NoError(t, err)
+		assert.Equal(t, UserName("Jane Doe"), name)
+		assert.NoError(t, name.Validate())
+	})
+
+	t.Run("Invalid names", func(t *testing.T) {
+		for s, message := range map[string]string{
+			"":            "Name is required",
+			"   ":         "Name is required",
+			"Jane\nDoe":   "Name must not contain control characters",
+			"Jane\xffDoe": "Name must be valid UTF-8",
+			strings.Repeat("é", MaxUserNameLength+1): "Name must be at most 100 characters",
+		} {
+			_, err := ParseUserName(s)
+			assert.ErrorIs(t, err, ErrInvalidUserName, s)
+
+			var invalid *ValidationError
+			require.ErrorAs(t, err, &invalid, s)
+			assert.Equal(t, message, invalid.Message, s)
+		}
+	})
+
+	t.Run("Limit counts characters", func(t *testing.T) {
+		_, err := ParseUserName(strings.Repeat("é", MaxUserNameLength))
+		assert.NoError(t, err)
+	})
+}
+
+func TestValues_JSON(t *testing.T) {
+	t.Run("Decoding validates", func(t *testing.T) {
+		var user User
+		require.NoError(t, json.Unmarshal([]byte(`{"name":" Jane ","email":"Jane@Example.com"}`), &user))
+		assert.Equal(t, UserName("Jane"), user.Name)
+		assert.Equal(t, Email("jane@example.com"), user.Email)
+
+		err := json.Unmarshal([]byte(`{"email":"jane"}`), &user)
+		assert.ErrorIs(t, err, ErrInvalidEmail)
+	})
+
+	t.Run("Empty values stay unset", func(t *testing.T) {
+		var user User
+		require.NoError(t, json.Unmarshal([]byte(`{"name":"","email":null}`), &user))
+		assert.Empty(t, user.Name)
+		assert.Empty(t, user.Email)
+	})
+
+	t.Run("Encoded as strings", func(t *testing.T) {
+		data, err := json.Marshal(User{Name: "Jane", Email: "jane@example.com"})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"name":"Jane","email":"jane@example.com"`)
+	})
+}
+
+func TestValues_BSON(t *testing.T) {
+	type document struct {
+		Name  UserName `bson:"name"`
+		Email Email    `bson:"email"`
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		data, err := bson.Marshal(document{Name: "Jane", Email: "jane@example.com"})
+		require.NoError(t, err)
+
+		var raw bson.M
+		require.NoError(t, bson.Unmarshal(data, &raw))
+		assert.Equal(t, "jane@example.com", raw["email"])
+
+		var doc document
+		require.NoError(t, bson.Unmarshal(data, &doc))
+		assert.Equal(t, document{Name: "Jane", Email: "jane@example.com"}, doc)
+	})
+
+	t.Run("Decoding validates", func(t *testing.T) {
+		data, err := bson.Marshal(bson.M{"name": "Jane", "email": "jane"})
+		require.NoError(t, err)
+
+		var doc document
+		assert.ErrorIs(t, bson.Unmarshal(data, &doc), ErrInvalidEmail)
+	})
+
+	t.Run("Non-strings are rejected", func(t *testing.T) {
+		data, err := bson.Marshal(bson.M{"name": 42})
+		require.NoError(t, err)
+
+		var doc document
+		assert.Error(t, bson.Unmarshal(data, &doc))
+	})
+}
@@ -16,6 +16,8 @@ var (
 	ErrForbidden          = errors.New("forbidden")
 	ErrConflict           = errors.New("conflict")
 	ErrServiceUnavailable = errors.New("service unavailable")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrPayloadTooLarge    = errors.New("payload too large")
 )
 
 // AppError represents an application-specific error
@@ -26,6 +28,9 @@ type AppError struct {
 	// StatusCode is the associated HTTP status code (if any)
 	StatusCode int
 
+	// Code is the machine-readable error code (if any), overriding the one derived from StatusCode
+	Code string
+
 	// Message is the user-facing error message
 	Message string
 
@@ -58,6 +63,16 @@ func (e *AppError) WithContext(key string, value interface{}) *AppError {
 	return e
 }
 
+// Is reports whether any error in err's tree matches target, like the standard library's errors.Is
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's tree that matches target, like the standard library's errors.As
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
 // New creates a new error with a message
 func New(message string) error {
 	return &AppError{
@@ -115,6 +130,37 @@ func NotFound(message string) error {
 	}
 }
 
+// Unauthorized creates a 401 error
+func Unauthorized(message string) error {
+	return &AppError{
+		StatusCode: http.StatusUnauthorized,
+		Code:       "UNAUTHORIZED",
+		Message:    message,
+		Original:   ErrUnauthorized,
+	}
+}
+
+// Forbidden creates a 403 error
+func Forbidden(message string) error {
+	return &AppError{
+		StatusCode: http.StatusForbidden,
+		Code:       "FORBIDDEN",
+		Message:    message,
+		Original:   ErrForbidden,
+	}
+}
+
+// PayloadTooLarge creates a 413 error for a request body over limit bytes
+func PayloadTooLarge(limit int64) error {
+	err := &AppError{
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Code:       "PAYLOAD_TOO_LARGE",
+		Message:    fmt.Sprintf("Request body exceeds %d bytes", limit),
+		Original:   ErrPayloadTooLarge,
+	}
+	return err.WithContext("limit", limit)
+}
+
 // Internal creates a 500 error
 func Internal(message string) error {
 	return &AppError{
@@ -146,11 +192,22 @@ func GetStatusCode(err error) int {
 		return http.StatusConflict
 	case errors.Is(err, ErrServiceUnavailable):
 		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrPayloadTooLarge):
+		return http.StatusRequestEntityTooLarge
 	default:
 		return http.StatusInternalServerError
 	}
 }
 
+// GetCode extracts the machine-readable error code from an error, or "" when it has none
+func GetCode(err error) string {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
 // GetContextMap extracts the context map from an error
 func GetContextMap(err error) map[string]interface{} {
 	var appErr *AppError
@@ -0,0 +1,49 @@
+package gdpr
+
+import (
+	"context"
+	"errors"
+
+	"quizizz.com/internal/repository"
+)
+
+// avatarsCollection exposes the stored variants of the users' avatars: their file IDs are exported,
+// and the files are deleted along with the reference on the user
+type avatarsCollection struct {
+	users repository.UserRepository
+	files repository.FileRepository
+}
+
+// Avatars returns the Collection of avatar images; register it after Users so it is erased first
+func Avatars(users repository.UserRepository, files repository.FileRepository) Collection {
+	return avatarsCollection{users: users, files: files}
+}
+
+func (avatarsCollection) Name() string { return "avatars" }
+
+func (c avatarsCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	user, err := c.users.GetByID(ctx, userID)
+	if err != nil || user == nil || user.Avatar == nil {
+		return nil, err
+	}
+	return user.Avatar, nil
+}
+
+func (c avatarsCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	user, err := c.users.GetByID(ctx, userID)
+	if err != nil || user == nil || user.Avatar == nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, id := range user.Avatar.Variants {
+		err := c.files.Delete(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return deleted, err
+		}
+		if err == nil {
+			deleted++
+		}
+	}
+	return deleted, c.users.SetAvatar(ctx, userID, nil)
+}
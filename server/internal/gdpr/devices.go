@@ -0,0 +1,97 @@
+package gdpr
+
+import (
+	"context"
+	"time"
+
+	"quizizz.com/internal/repository"
+)
+
+// deviceExport is the exported form of a device registered for push notifications, without its token
+type deviceExport struct {
+	ID        string    `json:"id"`
+	Platform  string    `json:"platform"`
+	Name      string    `json:"name,omitempty"`
+	Topics    []string  `json:"topics"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// pushReceiptExport is the exported form of a push receipt
+type pushReceiptExport struct {
+	NotificationID string    `json:"notification_id"`
+	DeviceID       string    `json:"device_id"`
+	Topic          string    `json:"topic,omitempty"`
+	Status         string    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// devicesCollection exposes the devices collection: the user's devices are exported and deleted
+type devicesCollection struct {
+	repo repository.DeviceRepository
+}
+
+// Devices returns the Collection of devices registered for push notifications
+func Devices(repo repository.DeviceRepository) Collection {
+	return devicesCollection{repo: repo}
+}
+
+func (devicesCollection) Name() string { return "devices" }
+
+func (c devicesCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	devices, err := c.repo.ListByUser(ctx, userID)
+	if err != nil || len(devices) == 0 {
+		return nil, err
+	}
+
+	exported := make([]deviceExport, len(devices))
+	for i, d := range devices {
+		exported[i] = deviceExport{
+			ID:        d.ID,
+			Platform:  d.Platform,
+			Name:      d.Name,
+			Topics:    d.Topics,
+			CreatedAt: d.CreatedAt,
+		}
+	}
+	return exported, nil
+}
+
+func (c devicesCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	return c.repo.DeleteByUser(ctx, userID)
+}
+
+// pushReceiptsCollection exposes the push_receipts collection: the user's receipts are exported and
+// deleted
+type pushReceiptsCollection struct {
+	repo repository.PushReceiptRepository
+}
+
+// PushReceipts returns the Collection of push delivery receipts
+func PushReceipts(repo repository.PushReceiptRepository) Collection {
+	return pushReceiptsCollection{repo: repo}
+}
+
+func (pushReceiptsCollection) Name() string { return "push_receipts" }
+
+func (c pushReceiptsCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	receipts, err := c.repo.ListByUser(ctx, userID)
+	if err != nil || len(receipts) == 0 {
+		return nil, err
+	}
+
+	exported := make([]pushReceiptExport, len(receipts))
+	for i, r := range receipts {
+		exported[i] = pushReceiptExport{
+			NotificationID: r.NotificationID,
+			DeviceID:       r.DeviceID,
+			Topic:          r.Topic,
+			Status:         r.Status,
+			CreatedAt:      r.CreatedAt,
+		}
+	}
+	return exported, nil
+}
+
+func (c pushReceiptsCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	return c.repo.DeleteByUser(ctx, userID)
+}
@@ -0,0 +1,62 @@
+// Package gdpr collects a user's personal data across collections for export and erasure
+//
+// Every collection holding personal data registers a Collection with the Registry, so data
+// exports (right of access) and erasures (right to be forgotten) cover it without the GDPR
+// service knowing about each collection.
+package gdpr
+
+import (
+	"context"
+	"time"
+)
+
+// Collection exports and erases the personal data of a user held in one collection
+// Erase must be idempotent: erasures are retried and may run again after a partial failure.
+type Collection interface {
+	// Name identifies the collection in exports and audit records
+	Name() string
+
+	// Export returns the user's data in a JSON-encodable form, or nil when there is none
+	Export(ctx context.Context, userID string) (interface{}, error)
+
+	// Erase deletes or anonymizes the user's data, returning the number of documents affected
+	Erase(ctx context.Context, userID string) (int64, error)
+}
+
+// Export is a machine-readable dump of a user's data
+type Export struct {
+	UserID      string                 `json:"user_id"`
+	ExportedAt  time.Time              `json:"exported_at"`
+	Collections map[string]interface{} `json:"collections"`
+}
+
+// Registry holds the collections with personal data
+type Registry struct {
+	collections []Collection
+}
+
+// NewRegistry creates a Registry of collections
+// Register the collection owning the user first: erasure runs in reverse order, so data referring
+// to the user is erased before the user itself.
+func NewRegistry(collections ...Collection) *Registry {
+	r := &Registry{}
+	for _, c := range collections {
+		r.Register(c)
+	}
+	return r
+}
+
+// Register adds a collection; it panics if a collection with the same name is registered
+func (r *Registry) Register(c Collection) {
+	for _, existing := range r.collections {
+		if existing.Name() == c.Name() {
+			panic("gdpr: collection " + c.Name() + " registered twice")
+		}
+	}
+	r.collections = append(r.collections, c)
+}
+
+// Collections returns the registered collections in registration order
+func (r *Registry) Collections() []Collection {
+	return append([]Collection(nil), r.collections...)
+}
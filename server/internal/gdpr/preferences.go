@@ -0,0 +1,36 @@
+package gdpr
+
+import (
+	"context"
+
+	"quizizz.com/internal/repository"
+)
+
+// preferencesCollection exposes the user_preferences collection: the settings the user chose are
+// exported and deleted
+type preferencesCollection struct {
+	repo repository.PreferencesRepository
+}
+
+// Preferences returns the Collection of user preferences
+func Preferences(repo repository.PreferencesRepository) Collection {
+	return preferencesCollection{repo: repo}
+}
+
+func (preferencesCollection) Name() string { return "user_preferences" }
+
+func (c preferencesCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	prefs, err := c.repo.Get(ctx, userID)
+	if err != nil || prefs == nil {
+		return nil, err
+	}
+	return prefs.Settings, nil
+}
+
+func (c preferencesCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	deleted, err := c.repo.Delete(ctx, userID)
+	if err != nil || !deleted {
+		return 0, err
+	}
+	return 1, nil
+}
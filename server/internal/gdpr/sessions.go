@@ -0,0 +1,57 @@
+package gdpr
+
+import (
+	"context"
+	"time"
+
+	"quizizz.com/internal/repository"
+)
+
+// sessionExport is the exported form of a session, without its token hashes
+type sessionExport struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	ClientIP   string    `json:"client_ip,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// sessionsCollection exposes the sessions collection: the user's active sessions are exported and
+// every session is deleted
+type sessionsCollection struct {
+	repo repository.SessionRepository
+}
+
+// Sessions returns the Collection of user sessions
+func Sessions(repo repository.SessionRepository) Collection {
+	return sessionsCollection{repo: repo}
+}
+
+func (sessionsCollection) Name() string { return "sessions" }
+
+func (c sessionsCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	sessions, err := c.repo.ListByUser(ctx, userID)
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+
+	exported := make([]sessionExport, len(sessions))
+	for i, s := range sessions {
+		exported[i] = sessionExport{
+			ID:         s.ID,
+			Device:     s.Device,
+			UserAgent:  s.UserAgent,
+			ClientIP:   s.ClientIP,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+		}
+	}
+	return exported, nil
+}
+
+func (c sessionsCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	return c.repo.DeleteByUser(ctx, userID)
+}
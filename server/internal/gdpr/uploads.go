@@ -0,0 +1,69 @@
+package gdpr
+
+import (
+	"context"
+	"time"
+
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+)
+
+// uploadExport is the exported form of an upload, without its object key
+type uploadExport struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// uploadsCollection exposes the uploads collection: the user's uploads are exported, and their
+// objects are deleted along with them
+type uploadsCollection struct {
+	repo    repository.UploadRepository
+	storage resources.ObjectStorageResource
+}
+
+// Uploads returns the Collection of files uploaded to object storage; storage is nil while uploads
+// are disabled, when only the uploads are deleted
+func Uploads(repo repository.UploadRepository, storage resources.ObjectStorageResource) Collection {
+	return uploadsCollection{repo: repo, storage: storage}
+}
+
+func (uploadsCollection) Name() string { return "uploads" }
+
+func (c uploadsCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	uploads, err := c.repo.ListByUser(ctx, userID)
+	if err != nil || len(uploads) == 0 {
+		return nil, err
+	}
+
+	exported := make([]uploadExport, len(uploads))
+	for i, u := range uploads {
+		exported[i] = uploadExport{
+			ID:          u.ID,
+			Filename:    u.Filename,
+			ContentType: u.ContentType,
+			Size:        u.Size,
+			Status:      u.Status,
+			CreatedAt:   u.CreatedAt,
+		}
+	}
+	return exported, nil
+}
+
+func (c uploadsCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	if c.storage != nil {
+		uploads, err := c.repo.ListByUser(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+		for _, u := range uploads {
+			if err := c.storage.Delete(ctx, u.Key); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return c.repo.DeleteByUser(ctx, userID)
+}
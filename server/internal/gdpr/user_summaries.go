@@ -0,0 +1,28 @@
+package gdpr
+
+import (
+	"context"
+
+	"quizizz.com/internal/repository"
+)
+
+// userSummariesCollection exposes the user_summaries read model: the user's summary is deleted but
+// not exported, since it only copies and counts data exported from its sources
+type userSummariesCollection struct {
+	repo repository.UserSummaryRepository
+}
+
+// UserSummaries returns the Collection of user summaries
+func UserSummaries(repo repository.UserSummaryRepository) Collection {
+	return userSummariesCollection{repo: repo}
+}
+
+func (userSummariesCollection) Name() string { return repository.UserSummaryCollection }
+
+func (userSummariesCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	return nil, nil
+}
+
+func (c userSummariesCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	return c.repo.Delete(ctx, userID)
+}
@@ -0,0 +1,39 @@
+package gdpr
+
+import (
+	"context"
+	"errors"
+
+	"quizizz.com/internal/repository"
+)
+
+// usersCollection exposes the users collection: the user's profile is exported and deleted
+type usersCollection struct {
+	repo repository.UserRepository
+}
+
+// Users returns the Collection of user profiles
+func Users(repo repository.UserRepository) Collection {
+	return usersCollection{repo: repo}
+}
+
+func (usersCollection) Name() string { return "users" }
+
+func (c usersCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	user, err := c.repo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (c usersCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	err := c.repo.Delete(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
@@ -0,0 +1,51 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// RegisterGRPC registers the standard gRPC health service on server and keeps it in sync with
+// the registry until ctx is done
+// The overall status ("" service) reflects all checks, like /readyz; each check is also exposed
+// as a service of its own name.
+func RegisterGRPC(ctx context.Context, server *grpc.Server, registry *Registry, interval time.Duration) {
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	update := func() {
+		report := registry.Run(ctx)
+		healthServer.SetServingStatus("", servingStatus(report.Healthy))
+		for _, check := range report.Checks {
+			healthServer.SetServingStatus(check.Name, servingStatus(check.Status == "ok"))
+		}
+	}
+	update()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				healthServer.Shutdown()
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	}()
+}
+
+// servingStatus converts a health result to its gRPC status
+func servingStatus(healthy bool) healthpb.HealthCheckResponse_ServingStatus {
+	if healthy {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,118 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newHealthClient serves the health service of registry in memory, refreshed every interval until
+// the returned cancel function is called
+func newHealthClient(t *testing.T, registry *Registry, interval time.Duration) (healthpb.HealthClient, context.CancelFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterGRPC(ctx, server, registry, interval)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///health",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn), cancel
+}
+
+// servingStatusOf returns the status the health service reports for service
+func servingStatusOf(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestRegisterGRPC(t *testing.T) {
+	t.Run("Maps the overall and per-check status", func(t *testing.T) {
+		registry := NewRegistry(time.Second)
+		registry.Register("mongodb", passing)
+		registry.Register("redis", failing)
+		registry.RegisterOptional("search", failing)
+		client, _ := newHealthClient(t, registry, time.Hour)
+
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, client, ""))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatusOf(t, client, "mongodb"))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, client, "redis"))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, client, "search"))
+
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Serves while only optional checks fail", func(t *testing.T) {
+		registry := NewRegistry(time.Second)
+		registry.Register("mongodb", passing)
+		registry.RegisterOptional("search", failing)
+		client, _ := newHealthClient(t, registry, time.Hour)
+
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatusOf(t, client, ""))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, client, "search"))
+	})
+
+	t.Run("Refreshes the status every interval", func(t *testing.T) {
+		var healthy atomic.Bool
+		registry := NewRegistry(time.Second)
+		registry.Register("mongodb", func(context.Context) error {
+			if healthy.Load() {
+				return nil
+			}
+			return failing(context.Background())
+		})
+		client, _ := newHealthClient(t, registry, 5*time.Millisecond)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatusOf(t, client, ""))
+
+		healthy.Store(true)
+		assert.Eventually(t, func() bool {
+			return servingStatusOf(t, client, "") == healthpb.HealthCheckResponse_SERVING
+		}, time.Second, 5*time.Millisecond)
+
+		registry.Drain()
+		assert.Eventually(t, func() bool {
+			return servingStatusOf(t, client, "") == healthpb.HealthCheckResponse_NOT_SERVING
+		}, time.Second, 5*time.Millisecond, "draining services stop serving")
+	})
+
+	t.Run("Stops serving when the context is done", func(t *testing.T) {
+		registry := NewRegistry(time.Second)
+		registry.Register("mongodb", passing)
+		client, cancel := newHealthClient(t, registry, 5*time.Millisecond)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatusOf(t, client, ""))
+
+		cancel()
+		assert.Eventually(t, func() bool {
+			return servingStatusOf(t, client, "") == healthpb.HealthCheckResponse_NOT_SERVING
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestServingStatus(t *testing.T) {
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(true))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(false))
+}
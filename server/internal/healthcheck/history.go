@@ -0,0 +1,222 @@
+package healthcheck
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+)
+
+// Sample is the status of a check at a point in time
+// The error of failed checks is not kept: the history is public, and errors name hosts, ports and
+// drivers. It is logged when a dependency starts failing, and served by /readyz.
+type Sample struct {
+	Time   time.Time `json:"time"`
+	Status string    `json:"status"`
+}
+
+// DependencyStatus is the recent health of a dependency
+type DependencyStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+
+	// Optional is set for dependencies the service can run without, whose failures keep it healthy
+	Optional bool `json:"optional,omitempty"`
+
+	// Incident is set once the dependency failed IncidentThreshold checks in a row, and cleared by
+	// its next successful check
+	Incident      bool       `json:"incident"`
+	IncidentSince *time.Time `json:"incidentSince,omitempty"`
+
+	// ConsecutiveFailures counts the failed checks since the last successful one
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// Checks and Failures count the checks since the process started; Uptime is the share of them
+	// that succeeded
+	Checks   int64   `json:"checks"`
+	Failures int64   `json:"failures"`
+	Uptime   float64 `json:"uptime"`
+
+	// History holds the most recent samples, oldest first
+	History []Sample `json:"history"`
+}
+
+// Status is the recent health of every dependency
+type Status struct {
+	Healthy  bool `json:"healthy"`
+	Incident bool `json:"incident"`
+
+	// Since is when the history started
+	Since        time.Time          `json:"since"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+// History keeps the results of the last checks of each dependency in a ring buffer, along with
+// counters and incident flags
+type History struct {
+	size      int
+	threshold int
+
+	mu    sync.Mutex
+	since time.Time
+	deps  map[string]*dependencyHistory
+}
+
+// dependencyHistory is the history of one dependency
+type dependencyHistory struct {
+	samples       []Sample
+	next          int
+	checks        int64
+	failures      int64
+	consecutive   int
+	incidentSince time.Time
+	optional      bool
+}
+
+// NewHistory creates a History keeping size samples per dependency, flagging an incident after
+// threshold failed checks in a row
+func NewHistory(size, threshold int) *History {
+	return &History{
+		size:      max(size, 1),
+		threshold: max(threshold, 1),
+		since:     time.Now(),
+		deps:      make(map[string]*dependencyHistory),
+	}
+}
+
+// Record adds the results of a run of the checks
+func (h *History) Record(report Report) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, check := range report.Checks {
+		dep, ok := h.deps[check.Name]
+		if !ok {
+			dep = &dependencyHistory{samples: make([]Sample, 0, h.size)}
+			h.deps[check.Name] = dep
+		}
+		if check.Status != "ok" && dep.consecutive == 0 {
+			logger.Warn("Dependency check started failing",
+				zap.String("dependency", check.Name),
+				zap.String("error", check.Message),
+			)
+		} else if check.Status == "ok" && dep.consecutive > 0 {
+			logger.Info("Dependency check recovered",
+				zap.String("dependency", check.Name),
+				zap.Int("failures", dep.consecutive),
+			)
+		}
+		dep.record(check, h.size, h.threshold)
+	}
+}
+
+// record adds the result of a check
+func (d *dependencyHistory) record(check resources.HealthCheck, size, threshold int) {
+	sample := Sample{Time: check.Time, Status: check.Status}
+	if len(d.samples) < size {
+		d.samples = append(d.samples, sample)
+	} else {
+		d.samples[d.next] = sample
+	}
+	d.next = (d.next + 1) % size
+	d.optional = check.Optional
+
+	d.checks++
+	if check.Status == "ok" {
+		d.consecutive = 0
+		d.incidentSince = time.Time{}
+		return
+	}
+	d.failures++
+	d.consecutive++
+	if d.consecutive == threshold {
+		d.incidentSince = check.Time
+	}
+}
+
+// Status returns the recent health of the dependencies, sorted by name
+func (h *History) Status() Status {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := Status{Healthy: true, Since: h.since, Dependencies: make([]DependencyStatus, 0, len(h.deps))}
+	for name, dep := range h.deps {
+		history := make([]Sample, 0, len(dep.samples))
+		if len(dep.samples) == h.size {
+			history = append(history, dep.samples[dep.next:]...)
+			history = append(history, dep.samples[:dep.next]...)
+		} else {
+			history = append(history, dep.samples...)
+		}
+
+		dependency := DependencyStatus{
+			Name:                name,
+			Status:              history[len(history)-1].Status,
+			Optional:            dep.optional,
+			ConsecutiveFailures: dep.consecutive,
+			Checks:              dep.checks,
+			Failures:            dep.failures,
+			Uptime:              float64(dep.checks-dep.failures) / float64(dep.checks),
+			History:             history,
+		}
+		if !dep.incidentSince.IsZero() {
+			since := dep.incidentSince
+			dependency.Incident, dependency.IncidentSince = true, &since
+			status.Incident = true
+		}
+		if dependency.Status != "ok" && !dependency.Optional {
+			status.Healthy = false
+		}
+		status.Dependencies = append(status.Dependencies, dependency)
+	}
+	sort.Slice(status.Dependencies, func(i, j int) bool { return status.Dependencies[i].Name < status.Dependencies[j].Name })
+	return status
+}
+
+// KeepHistory makes the registry record the results of the checks run by Watch in a History of
+// size samples per dependency
+func (r *Registry) KeepHistory(size, incidentThreshold int) {
+	r.history = NewHistory(size, incidentThreshold)
+}
+
+// History returns the history of the checks, or nil when none is kept
+func (r *Registry) History() *History {
+	return r.history
+}
+
+// Watch runs the checks every interval until ctx is done, recording their results in the history;
+// it does nothing when no history is kept
+// Runs while the service drains are not recorded: they run no check.
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	if r.history == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	record := func() {
+		if report := r.Run(ctx); !r.draining.Load() && ctx.Err() == nil {
+			r.history.Record(report)
+		}
+	}
+
+	go func() {
+		record()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				record()
+			}
+		}
+	}()
+}
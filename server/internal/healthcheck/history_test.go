@@ -0,0 +1,83 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/resources"
+)
+
+var historyStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// run returns a report of the checks at minute i of the history, failing the named dependencies
+func run(i int, failing ...string) Report {
+	report := Report{Healthy: len(failing) == 0}
+	for _, name := range []string{"mongodb", "redis"} {
+		check := resources.HealthCheck{Name: name, Status: "ok", Time: historyStart.Add(time.Duration(i) * time.Minute)}
+		for _, f := range failing {
+			if f == name {
+				check.Status, check.Message = "error", "dial tcp 10.0.3.7:27017: connect: connection refused"
+			}
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("Keeps the last samples of each dependency, oldest first", func(t *testing.T) {
+		history := NewHistory(3, 2)
+		for i := 0; i < 5; i++ {
+			history.Record(run(i))
+		}
+
+		status := history.Status()
+		require.Len(t, status.Dependencies, 2)
+		mongo := status.Dependencies[0]
+		assert.Equal(t, "mongodb", mongo.Name)
+		require.Len(t, mongo.History, 3)
+		assert.Equal(t, historyStart.Add(2*time.Minute), mongo.History[0].Time)
+		assert.Equal(t, historyStart.Add(4*time.Minute), mongo.History[2].Time)
+		assert.Equal(t, int64(5), mongo.Checks)
+		assert.Equal(t, 1.0, mongo.Uptime)
+		assert.True(t, status.Healthy)
+	})
+
+	t.Run("Flags incidents after consecutive failures until a success", func(t *testing.T) {
+		history := NewHistory(10, 2)
+		history.Record(run(0))
+		history.Record(run(1, "mongodb"))
+		assert.False(t, history.Status().Incident, "a single failure is no incident")
+
+		history.Record(run(2, "mongodb"))
+		status := history.Status()
+		mongo := status.Dependencies[0]
+		assert.True(t, status.Incident)
+		assert.False(t, status.Healthy)
+		assert.True(t, mongo.Incident)
+		assert.Equal(t, historyStart.Add(2*time.Minute), *mongo.IncidentSince)
+		assert.Equal(t, 2, mongo.ConsecutiveFailures)
+		assert.InDelta(t, 1.0/3, mongo.Uptime, 1e-9)
+		assert.False(t, status.Dependencies[1].Incident)
+
+		history.Record(run(3))
+		status = history.Status()
+		assert.False(t, status.Incident)
+		assert.True(t, status.Healthy)
+		assert.Nil(t, status.Dependencies[0].IncidentSince)
+	})
+
+	t.Run("Never exposes the errors of failed checks", func(t *testing.T) {
+		history := NewHistory(10, 1)
+		history.Record(run(0, "mongodb", "redis"))
+
+		body, err := json.Marshal(history.Status())
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "10.0.3.7")
+		assert.NotContains(t, string(body), "connection refused")
+		assert.Contains(t, string(body), `"status":"error"`)
+	})
+}
@@ -0,0 +1,147 @@
+// Package healthcheck runs the readiness checks shared by the HTTP /readyz endpoint and the gRPC health service
+package healthcheck
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"quizizz.com/internal/resources"
+)
+
+// Check reports whether a dependency is usable; a nil error means healthy
+type Check func(ctx context.Context) error
+
+// Report is the result of running all checks
+type Report struct {
+	// Healthy is set when every critical check passed
+	Healthy bool `json:"healthy"`
+
+	// Degraded is set when an optional check failed
+	Degraded bool                    `json:"degraded"`
+	Checks   []resources.HealthCheck `json:"checks"`
+}
+
+// registration is a registered check and whether the service can run without its dependency
+type registration struct {
+	check    Check
+	optional bool
+}
+
+// Registry holds the named readiness checks of the service
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]registration
+
+	draining atomic.Bool
+
+	// history records the results of the checks run by Watch; nil keeps none
+	history *History
+}
+
+// NewRegistry creates an empty registry running each check with the given timeout
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Registry{timeout: timeout, checks: make(map[string]registration)}
+}
+
+// Register adds a named critical check, replacing any check with the same name
+// The service is not ready while a critical check fails.
+func (r *Registry) Register(name string, check Check) {
+	r.register(name, registration{check: check})
+}
+
+// RegisterOptional adds a named check of a dependency the service can run without, replacing any
+// check with the same name
+// A failing optional check is reported and degrades the service, but keeps it ready.
+func (r *Registry) RegisterOptional(name string, check Check) {
+	r.register(name, registration{check: check, optional: true})
+}
+
+func (r *Registry) register(name string, reg registration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = reg
+}
+
+// RegisterResource adds a critical check pinging res
+func (r *Registry) RegisterResource(res resources.Resource) {
+	r.Register(res.Name(), res.Ping)
+}
+
+// Names returns the registered check names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Drain makes the service report not ready from now on, so load balancers stop routing to it before
+// it shuts down
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Run runs all checks concurrently and reports the service healthy when all critical checks pass
+// A draining service is reported unhealthy without running the checks.
+func (r *Registry) Run(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{Checks: []resources.HealthCheck{{
+			Name:    "draining",
+			Status:  "error",
+			Message: "service is shutting down",
+			Time:    time.Now(),
+		}}}
+	}
+
+	r.mu.RLock()
+	checks := make(map[string]registration, len(r.checks))
+	for name, reg := range r.checks {
+		checks[name] = reg
+	}
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	results := make(chan resources.HealthCheck, len(checks))
+	for name, reg := range checks {
+		go func(name string, reg registration) {
+			result := resources.HealthCheck{Name: name, Status: "ok", Optional: reg.optional}
+			if err := reg.check(ctx); err != nil {
+				result.Status = "error"
+				result.Message = err.Error()
+			}
+			result.Time = time.Now()
+			results <- result
+		}(name, reg)
+	}
+
+	report := Report{Healthy: true, Checks: make([]resources.HealthCheck, 0, len(checks))}
+	for range checks {
+		result := <-results
+		switch {
+		case result.Status == "ok":
+		case result.Optional:
+			report.Degraded = true
+		default:
+			report.Healthy = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	sort.Slice(report.Checks, func(i, j int) bool { return report.Checks[i].Name < report.Checks[j].Name })
+
+	return report
+}
@@ -0,0 +1,157 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/resources"
+)
+
+// passing and failing are checks of healthy and unhealthy dependencies
+var (
+	passing Check = func(context.Context) error { return nil }
+	failing Check = func(context.Context) error { return errors.New("connection refused") }
+)
+
+// statuses returns the status of each check of report by name
+func statuses(report Report) map[string]string {
+	result := make(map[string]string, len(report.Checks))
+	for _, check := range report.Checks {
+		result[check.Name] = check.Status
+	}
+	return result
+}
+
+func TestRegistry_Run(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		critical     map[string]Check
+		optional     map[string]Check
+		wantHealthy  bool
+		wantDegraded bool
+	}{
+		{name: "Healthy without checks", wantHealthy: true},
+		{
+			name:        "Healthy when every check passes",
+			critical:    map[string]Check{"mongodb": passing, "redis": passing},
+			optional:    map[string]Check{"search": passing},
+			wantHealthy: true,
+		},
+		{
+			name:     "Unhealthy when a critical check fails",
+			critical: map[string]Check{"mongodb": passing, "redis": failing},
+			optional: map[string]Check{"search": passing},
+		},
+		{
+			name:         "Degraded but healthy when an optional check fails",
+			critical:     map[string]Check{"mongodb": passing, "redis": passing},
+			optional:     map[string]Check{"search": failing, "push": passing},
+			wantHealthy:  true,
+			wantDegraded: true,
+		},
+		{
+			name:         "Unhealthy and degraded when both fail",
+			critical:     map[string]Check{"mongodb": failing},
+			optional:     map[string]Check{"search": failing},
+			wantDegraded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(time.Second)
+			for name, check := range tt.critical {
+				registry.Register(name, check)
+			}
+			for name, check := range tt.optional {
+				registry.RegisterOptional(name, check)
+			}
+
+			report := registry.Run(ctx)
+			assert.Equal(t, tt.wantHealthy, report.Healthy)
+			assert.Equal(t, tt.wantDegraded, report.Degraded)
+			require.Len(t, report.Checks, len(tt.critical)+len(tt.optional))
+			for _, check := range report.Checks {
+				_, optional := tt.optional[check.Name]
+				assert.Equal(t, optional, check.Optional, check.Name)
+			}
+		})
+	}
+
+	t.Run("Reports every check by name with its error", func(t *testing.T) {
+		registry := NewRegistry(time.Second)
+		registry.Register("redis", failing)
+		registry.Register("mongodb", passing)
+		registry.RegisterOptional("search", passing)
+
+		report := registry.Run(ctx)
+		assert.Equal(t, []string{"mongodb", "redis", "search"}, registry.Names())
+		require.Len(t, report.Checks, 3)
+		assert.Equal(t, "mongodb", report.Checks[0].Name)
+		assert.Equal(t, resources.HealthCheck{Name: "redis", Status: "error", Message: "connection refused", Time: report.Checks[1].Time},
+			report.Checks[1])
+		assert.False(t, report.Checks[1].Time.IsZero())
+	})
+
+	t.Run("Registering a name again replaces its check", func(t *testing.T) {
+		registry := NewRegistry(time.Second)
+		registry.Register("search", failing)
+		registry.RegisterOptional("search", failing)
+
+		report := registry.Run(ctx)
+		assert.True(t, report.Healthy)
+		assert.Equal(t, map[string]string{"search": "error"}, statuses(report))
+	})
+
+	t.Run("Bounds checks by the timeout", func(t *testing.T) {
+		registry := NewRegistry(10 * time.Millisecond)
+		registry.Register("mongodb", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		start := time.Now()
+		report := registry.Run(ctx)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.False(t, report.Healthy)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Message)
+	})
+
+	t.Run("Reports draining services unhealthy without running checks", func(t *testing.T) {
+		registry := NewRegistry(time.Second)
+		ran := false
+		registry.Register("mongodb", func(context.Context) error {
+			ran = true
+			return nil
+		})
+		registry.Drain()
+
+		report := registry.Run(ctx)
+		assert.False(t, report.Healthy)
+		assert.False(t, ran)
+		assert.Equal(t, map[string]string{"draining": "error"}, statuses(report))
+	})
+}
+
+func TestHistory_OptionalDependencies(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("mongodb", passing)
+	registry.RegisterOptional("search", failing)
+
+	history := NewHistory(10, 1)
+	history.Record(registry.Run(context.Background()))
+
+	status := history.Status()
+	assert.True(t, status.Healthy, "failing optional dependencies keep the service healthy")
+	assert.True(t, status.Incident)
+	require.Len(t, status.Dependencies, 2)
+	assert.False(t, status.Dependencies[0].Optional)
+	assert.True(t, status.Dependencies[1].Optional)
+	assert.Equal(t, "error", status.Dependencies[1].Status)
+}
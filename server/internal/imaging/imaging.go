@@ -0,0 +1,85 @@
+// Package imaging turns uploaded images into the variants served to clients
+//
+// Images are decoded to pixels, turned upright, cropped and scaled, then encoded anew as WebP. Only
+// the pixels of an upload survive: EXIF and other metadata, such as GPS positions and device serial
+// numbers, are dropped along with the original encoding.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF decoder
+	_ "image/jpeg" // register the JPEG decoder
+	_ "image/png"  // register the PNG decoder
+	"io"
+
+	"github.com/HugoSmits86/nativewebp" // registers the WebP decoder
+	"golang.org/x/image/draw"
+)
+
+// ContentType is the content type of encoded variants
+const ContentType = "image/webp"
+
+// Image errors
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image too large")
+)
+
+// Decode decodes a JPEG, PNG, GIF or WebP image and returns it upright with its format
+// Images with more than maxPixels pixels are rejected from their header, before their pixels are
+// decoded; maxPixels <= 0 accepts every size.
+func Decode(r io.Reader, maxPixels int) (image.Image, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format, err := Inspect(data, maxPixels)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if format == "jpeg" {
+		img = Orient(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Inspect returns the format of an image from its header, checking it like Decode without decoding it
+func Inspect(data []byte, maxPixels int) (string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return "", fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrTooLarge, cfg.Width, cfg.Height, maxPixels)
+	}
+	return format, nil
+}
+
+// Thumbnail returns img cropped to its centered square and scaled to size x size
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
+
+// EncodeWebP encodes img as a lossless WebP
+func EncodeWebP(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return nil, fmt.Errorf("failed to encode webp: %w", err)
+	}
+	return buf.Bytes(), nil
+}
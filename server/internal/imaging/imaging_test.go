@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// halves returns a w x h image whose left half is red and right half is blue
+func halves(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation returns a JPEG of img carrying an EXIF segment with orientation
+func withOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+
+	// Big-endian TIFF with a single IFD entry: the orientation SHORT
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, exifTagOrientation)
+	tiff = append(tiff, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// isRed reports whether c is mostly red, allowing for lossy compression
+func isRed(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r > 0xC000 && b < 0x4000
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Turns JPEG images upright", func(t *testing.T) {
+		data := withOrientation(t, halves(40, 20), OrientationRotate90)
+		assert.Equal(t, OrientationRotate90, jpegOrientation(data))
+
+		img, format, err := Decode(bytes.NewReader(data), 0)
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+		// Rotating clockwise brings the left half to the top
+		assert.True(t, isRed(img.At(10, 5)))
+		assert.False(t, isRed(img.At(10, 35)))
+	})
+
+	t.Run("Reads WebP images", func(t *testing.T) {
+		data, err := EncodeWebP(halves(8, 8))
+		require.NoError(t, err)
+
+		img, format, err := Decode(bytes.NewReader(data), 0)
+		require.NoError(t, err)
+		assert.Equal(t, "webp", format)
+		assert.Equal(t, color.NRGBA{R: 255, A: 255}, color.NRGBAModel.Convert(img.At(0, 0)))
+	})
+
+	t.Run("Rejects oversized images before decoding them", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, halves(40, 20)))
+
+		_, _, err := Decode(&buf, 799)
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("Rejects other files", func(t *testing.T) {
+		_, _, err := Decode(strings.NewReader("%PDF-1.7"), 0)
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestThumbnail(t *testing.T) {
+	img := Thumbnail(halves(60, 20), 8)
+
+	assert.Equal(t, image.Rect(0, 0, 8, 8), img.Bounds())
+	// The centered square straddles both halves
+	assert.True(t, isRed(img.At(1, 4)))
+	assert.False(t, isRed(img.At(6, 4)))
+}
+
+func TestOrient(t *testing.T) {
+	src := halves(4, 2)
+	for orientation, want := range map[int]image.Rectangle{
+		OrientationNormal:     image.Rect(0, 0, 4, 2),
+		OrientationRotate180:  image.Rect(0, 0, 4, 2),
+		OrientationTranspose:  image.Rect(0, 0, 2, 4),
+		OrientationRotate270:  image.Rect(0, 0, 2, 4),
+		OrientationTransverse: image.Rect(0, 0, 2, 4),
+	} {
+		assert.Equal(t, want, Orient(src, orientation).Bounds(), "orientation %d", orientation)
+	}
+
+	// Flipping horizontally swaps the halves
+	assert.False(t, isRed(Orient(src, OrientationFlipH).At(0, 0)))
+	// Rotating counterclockwise brings the left half to the bottom
+	assert.True(t, isRed(Orient(src, OrientationRotate270).At(0, 3)))
+}
+
+func TestEncodeWebP(t *testing.T) {
+	data := withOrientation(t, halves(16, 16), OrientationFlipV)
+	img, _, err := Decode(bytes.NewReader(data), 0)
+	require.NoError(t, err)
+
+	encoded, err := EncodeWebP(img)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(encoded, []byte("RIFF")))
+	assert.NotContains(t, string(encoded), "Exif", "metadata is stripped")
+}
@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// EXIF orientations, naming how the stored pixels must be transformed to display upright
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5
+	OrientationRotate90   = 6 // clockwise
+	OrientationTransverse = 7
+	OrientationRotate270  = 8 // clockwise
+)
+
+// exifTagOrientation is the TIFF tag of the orientation
+const exifTagOrientation = 0x0112
+
+// Orient returns img transformed as its EXIF orientation requires to display upright
+// Unknown orientations return img unchanged.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return img
+	}
+
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+
+	// source returns the source pixel of the destination pixel x, y
+	var source func(x, y int) (int, int)
+	dw, dh := w, h
+	switch orientation {
+	case OrientationFlipH:
+		source = func(x, y int) (int, int) { return w - 1 - x, y }
+	case OrientationRotate180:
+		source = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case OrientationFlipV:
+		source = func(x, y int) (int, int) { return x, h - 1 - y }
+	case OrientationTranspose:
+		dw, dh = h, w
+		source = func(x, y int) (int, int) { return y, x }
+	case OrientationRotate90:
+		dw, dh = h, w
+		source = func(x, y int) (int, int) { return y, h - 1 - x }
+	case OrientationTransverse:
+		dw, dh = h, w
+		source = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case OrientationRotate270:
+		dw, dh = h, w
+		source = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := source(x, y)
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG file, or OrientationNormal without one
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return OrientationNormal
+	}
+
+	// Walk the segments preceding the image data, looking for the EXIF APP1 segment
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // start of scan, end of image
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return OrientationNormal
+}
+
+// tiffOrientation returns the orientation tag of the first IFD of TIFF data
+func tiffOrientation(data []byte) int {
+	if len(data) < 8 {
+		return OrientationNormal
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return OrientationNormal
+	}
+
+	ifd := int(order.Uint32(data[4:]))
+	if ifd+2 > len(data) {
+		return OrientationNormal
+	}
+	entries := int(order.Uint16(data[ifd:]))
+	for k := 0; k < entries; k++ {
+		entry := ifd + 2 + 12*k
+		if entry+12 > len(data) {
+			break
+		}
+		if order.Uint16(data[entry:]) != exifTagOrientation {
+			continue
+		}
+		// The orientation is a SHORT stored in the first bytes of the value field
+		if order.Uint16(data[entry+2:]) != 3 {
+			break
+		}
+		if v := int(order.Uint16(data[entry+8:])); v >= OrientationNormal && v <= OrientationRotate270 {
+			return v
+		}
+		break
+	}
+	return OrientationNormal
+}
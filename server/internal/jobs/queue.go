@@ -0,0 +1,526 @@
+// Package jobs runs background work from a persistent queue
+//
+// Producers Enqueue a job of a registered type with a JSON payload; workers started with Start
+// claim due jobs, highest Priority first, run their handler and retry failures with exponential
+// backoff. A job that fails with a Permanent error or runs out of attempts is dead-lettered (status
+// dead) and kept for inspection. Handlers may run more than once for the same job (e.g. when a
+// worker dies mid-job), so they must be idempotent. Dead jobs are listed, replayed or purged with
+// ListDead, Replay and Purge.
+//
+// Periodic jobs (Every, Cron) are stored like any other job: while the workers run, the next
+// occurrence is enqueued ahead of time with a key deduplicating it across processes. The jobs of a
+// type running at once in a process are limited by JobsConfig.Concurrency, and Stats reports queue
+// depths, throughput and failure ratios.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// ErrUnknownType is returned when enqueuing a job type no handler is registered for
+var ErrUnknownType = errors.New("unknown job type")
+
+// ErrDuplicate is returned when enqueuing a job with the Key of a stored job
+var ErrDuplicate = errors.New("duplicate job")
+
+// Handler runs a job; returning an error retries it unless the error is Permanent
+type Handler func(ctx context.Context, job *repository.Job) error
+
+// Results of processing a job, used as the "result" metric attribute
+const (
+	ResultSucceeded = "succeeded"
+	ResultRetried   = "retried"
+	ResultDead      = "dead"
+)
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered without further attempts, e.g. for invalid payloads
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// EnqueueOption customizes an enqueued job
+type EnqueueOption func(*repository.Job)
+
+// RunAt delays the job until t
+func RunAt(t time.Time) EnqueueOption {
+	return func(job *repository.Job) {
+		job.RunAt = t
+	}
+}
+
+// MaxAttempts overrides the configured number of attempts for the job
+func MaxAttempts(n int) EnqueueOption {
+	return func(job *repository.Job) {
+		if n > 0 {
+			job.MaxAttempts = n
+		}
+	}
+}
+
+// Priority orders the job among due jobs, highest first; jobs default to 0
+func Priority(n int) EnqueueOption {
+	return func(job *repository.Job) {
+		job.Priority = n
+	}
+}
+
+// Key deduplicates the job: Enqueue fails with ErrDuplicate while a job with the same key is stored
+func Key(key string) EnqueueOption {
+	return func(job *repository.Job) {
+		job.Key = key
+	}
+}
+
+// periodic is a job type enqueued on a schedule
+type periodic struct {
+	jobType  string
+	schedule Schedule
+}
+
+// Queue enqueues jobs and runs the registered handlers on them
+type Queue struct {
+	repo   repository.JobRepository
+	clock  clock.Clock
+	config config.JobsConfig
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	schedules []periodic
+
+	// claimMu serializes claims so running never exceeds the concurrency limits
+	claimMu sync.Mutex
+	running map[string]int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	processed metric.Int64Counter
+	duration  metric.Float64Histogram
+}
+
+// NewQueue creates a Queue storing jobs in repo
+func NewQueue(repo repository.JobRepository, clk clock.Clock, cfg config.JobsConfig) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	q := &Queue{
+		repo:     repo,
+		clock:    clk,
+		config:   cfg,
+		handlers: make(map[string]Handler),
+		running:  make(map[string]int),
+	}
+	q.initInstruments()
+	return q
+}
+
+// initInstruments creates the job counters; failures leave them nil and only disable metrics
+func (q *Queue) initInstruments() {
+	meter := otel.Meter("jobs")
+
+	var err error
+	q.processed, err = meter.Int64Counter("jobs.processed",
+		metric.WithDescription("Number of job attempts by type and result"),
+	)
+	if err != nil {
+		logger.Error("Failed to create jobs.processed counter", zap.Error(err))
+	}
+	q.duration, err = meter.Float64Histogram("jobs.duration",
+		metric.WithDescription("Duration of job attempts by type and result"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Error("Failed to create jobs.duration histogram", zap.Error(err))
+	}
+}
+
+// Register sets the handler of jobType; register handlers before calling Start
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Every enqueues a job of jobType without payload every interval, on multiples of interval, while
+// the workers run
+// Every process running workers schedules it, but each occurrence is enqueued once.
+func (q *Queue) Every(jobType string, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	q.schedule(jobType, Every(interval))
+}
+
+// Cron enqueues a job of jobType without payload at the times of the cron expression spec (see
+// ParseCron) while the workers run
+func (q *Queue) Cron(jobType, spec string) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	q.schedule(jobType, schedule)
+	return nil
+}
+
+func (q *Queue) schedule(jobType string, schedule Schedule) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.schedules = append(q.schedules, periodic{jobType: jobType, schedule: schedule})
+}
+
+// Enqueue stores a job of jobType with payload encoded as JSON
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*repository.Job, error) {
+	if q.handler(jobType) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s job payload: %w", jobType, err)
+	}
+
+	job := &repository.Job{
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: q.config.MaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := q.repo.Enqueue(ctx, job); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicate, job.Key)
+		}
+		return nil, err
+	}
+
+	logger.InfoCtx(ctx, "Job enqueued", zap.String("jobId", job.ID), zap.String("jobType", jobType))
+	return job, nil
+}
+
+// Get returns a job by ID, or repository.ErrNotFound
+func (q *Queue) Get(ctx context.Context, id string) (*repository.Job, error) {
+	return q.repo.Get(ctx, id)
+}
+
+// ListDead returns a page of the dead-lettered jobs matching filter, most recently dead first, and
+// their total count
+func (q *Queue) ListDead(ctx context.Context, filter repository.DeadJobFilter, opts domain.ListOptions) ([]*repository.Job, int64, error) {
+	return q.repo.ListDead(ctx, filter, opts)
+}
+
+// Replay makes a dead-lettered job pending again with a fresh set of attempts, or returns
+// repository.ErrNotFound unless the job is dead
+// Jobs of types without a handler can be replayed, e.g. from a CLI; the workers that register one run them.
+func (q *Queue) Replay(ctx context.Context, id string) (*repository.Job, error) {
+	job, err := q.repo.Revive(ctx, id, q.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoCtx(ctx, "Dead job replayed", zap.String("jobId", job.ID), zap.String("jobType", job.Type))
+	return job, nil
+}
+
+// Purge removes the dead-lettered jobs matching filter, returning how many were removed
+func (q *Queue) Purge(ctx context.Context, filter repository.DeadJobFilter) (int64, error) {
+	removed, err := q.repo.DeleteDead(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.InfoCtx(ctx, "Dead jobs purged",
+		zap.String("jobId", filter.ID),
+		zap.String("jobType", filter.Type),
+		zap.Int64("removed", removed),
+	)
+	return removed, nil
+}
+
+// Decode decodes the JSON payload of job into v, as a Permanent error if it does not match
+func Decode(job *repository.Job, v interface{}) error {
+	if err := json.Unmarshal(job.Payload, v); err != nil {
+		return Permanent(fmt.Errorf("invalid %s job payload: %w", job.Type, err))
+	}
+	return nil
+}
+
+// Start runs the configured number of workers and enqueues periodic jobs until Stop is called or ctx is done
+func (q *Queue) Start(ctx context.Context) {
+	ctx, q.cancel = context.WithCancel(ctx)
+
+	logger.Info("Starting job workers", zap.Int("workers", q.config.Workers))
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	for _, s := range q.schedules {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.enqueuePeriodic(ctx, s)
+		}()
+	}
+}
+
+// Stop stops claiming jobs and waits for running jobs to finish or ctx to be done
+// Jobs still running when ctx is done are claimed again once their lease expires.
+func (q *Queue) Stop(ctx context.Context) error {
+	if q.cancel == nil {
+		return nil
+	}
+	q.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("job workers did not stop: %w", ctx.Err())
+	}
+}
+
+// work processes jobs until ctx is done, waiting PollInterval whenever none is due
+func (q *Queue) work(ctx context.Context) {
+	for {
+		processed, err := q.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to process job", zap.Error(err))
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+// enqueuePeriodic enqueues the next occurrence of p ahead of time, waits for it and repeats until
+// ctx is done
+// Occurrences are keyed by their time, so processes enqueuing the same one store a single job.
+// Occurrences missed while the process was not waiting are skipped, except the latest.
+func (q *Queue) enqueuePeriodic(ctx context.Context, p periodic) {
+	next := p.schedule.Next(q.clock.Now())
+	for !next.IsZero() {
+		key := fmt.Sprintf("%s@%s", p.jobType, next.UTC().Format(time.RFC3339))
+		_, err := q.Enqueue(ctx, p.jobType, nil, RunAt(next), Key(key))
+		if err != nil && !errors.Is(err, ErrDuplicate) && ctx.Err() == nil {
+			logger.Error("Failed to enqueue periodic job", zap.String("jobType", p.jobType), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.clock.After(next.Sub(q.clock.Now())):
+		}
+
+		now := q.clock.Now()
+		next = p.schedule.Next(next)
+		for !next.IsZero() && !next.After(now) {
+			following := p.schedule.Next(next)
+			if following.IsZero() || following.After(now) {
+				break
+			}
+			next = following
+		}
+	}
+}
+
+// RunOnce claims and processes a single due job, reporting whether there was one
+// Jobs of types running at their concurrency limit are left for other workers and processes.
+func (q *Queue) RunOnce(ctx context.Context) (bool, error) {
+	job, err := q.claim(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+	defer q.release(job.Type)
+
+	// Running jobs finish even when the workers are stopping, within their lease
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.config.Lease)
+	defer cancel()
+
+	log := logger.With(
+		zap.String("jobId", job.ID),
+		zap.String("jobType", job.Type),
+		zap.Int("attempt", job.Attempts),
+	)
+
+	start := q.clock.Now()
+	runErr := q.run(runCtx, job)
+	elapsed := q.clock.Now().Sub(start)
+
+	result, err := q.settle(runCtx, job, runErr)
+	q.record(runCtx, job.Type, result, elapsed)
+
+	switch result {
+	case ResultSucceeded:
+		log.Info("Job succeeded", zap.Duration("duration", elapsed))
+	case ResultRetried:
+		log.Warn("Job failed, retrying", zap.Time("retryAt", job.RunAt), zap.Error(runErr))
+	case ResultDead:
+		log.Error("Job failed permanently", zap.Error(runErr))
+	}
+	return true, err
+}
+
+// claim leases the next due job of the registered types under their concurrency limit, counting
+// it as running until release
+func (q *Queue) claim(ctx context.Context) (*repository.Job, error) {
+	q.claimMu.Lock()
+	defer q.claimMu.Unlock()
+
+	registered := q.types()
+	types := make([]string, 0, len(registered))
+	for _, jobType := range registered {
+		if limit := q.config.Concurrency[jobType]; limit <= 0 || q.running[jobType] < limit {
+			types = append(types, jobType)
+		}
+	}
+	if len(types) == 0 && len(registered) > 0 {
+		return nil, nil
+	}
+
+	job, err := q.repo.Claim(ctx, types, q.config.Lease)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	q.running[job.Type]++
+	return job, nil
+}
+
+// release stops counting a job of jobType as running
+func (q *Queue) release(jobType string) {
+	q.claimMu.Lock()
+	defer q.claimMu.Unlock()
+	q.running[jobType]--
+}
+
+// run calls the handler of job, turning panics into errors
+func (q *Queue) run(ctx context.Context, job *repository.Job) (err error) {
+	handler := q.handler(job.Type)
+	if handler == nil {
+		return Permanent(fmt.Errorf("%w: %s", ErrUnknownType, job.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// settle records the outcome of an attempt on job
+func (q *Queue) settle(ctx context.Context, job *repository.Job, runErr error) (string, error) {
+	if runErr == nil {
+		return ResultSucceeded, q.repo.Complete(ctx, job)
+	}
+
+	if IsPermanent(runErr) || job.Attempts >= job.MaxAttempts {
+		return ResultDead, q.repo.Bury(ctx, job, runErr.Error())
+	}
+	return ResultRetried, q.repo.Retry(ctx, job, q.clock.Now().Add(q.backoff(job.Attempts)), runErr.Error())
+}
+
+// backoff returns the delay before retrying after attempt, doubling from RetryBackoff up to MaxRetryBackoff
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.config.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if q.config.MaxRetryBackoff > 0 && delay >= q.config.MaxRetryBackoff {
+			return q.config.MaxRetryBackoff
+		}
+	}
+	return delay
+}
+
+// record adds an attempt to the job metrics
+func (q *Queue) record(ctx context.Context, jobType, result string, elapsed time.Duration) {
+	attrs := metric.WithAttributes(
+		attribute.String("type", jobType),
+		attribute.String("result", result),
+	)
+	if q.processed != nil {
+		q.processed.Add(ctx, 1, attrs)
+	}
+	if q.duration != nil {
+		q.duration.Record(ctx, elapsed.Seconds(), attrs)
+	}
+}
+
+func (q *Queue) handler(jobType string) Handler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.handlers[jobType]
+}
+
+// types returns the registered job types, the only ones this queue claims
+func (q *Queue) types() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	types := make([]string, 0, len(q.handlers))
+	for jobType := range q.handlers {
+		types = append(types, jobType)
+	}
+	return types
+}
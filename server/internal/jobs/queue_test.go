@@ -0,0 +1,521 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var testConfig = config.JobsConfig{
+	Workers:         1,
+	PollInterval:    10 * time.Millisecond,
+	Lease:           time.Minute,
+	MaxAttempts:     3,
+	RetryBackoff:    10 * time.Second,
+	MaxRetryBackoff: 15 * time.Second,
+}
+
+type testPayload struct {
+	Value string `json:"value"`
+}
+
+func newTestQueue() (*Queue, *repository.MockJobRepository, *testutil.FakeClock) {
+	clk := testutil.NewFakeClock(testTime)
+	repo := repository.NewMockJobRepository(clk)
+	return NewQueue(repo, clk, testConfig), repo, clk
+}
+
+func TestQueue_Enqueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Registered type", func(t *testing.T) {
+		queue, repo, _ := newTestQueue()
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error { return nil })
+
+		job, err := queue.Enqueue(ctx, "test", testPayload{Value: "a"})
+		require.NoError(t, err)
+
+		stored, err := repo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobPending, stored.Status)
+		assert.Equal(t, testTime, stored.RunAt)
+		assert.Equal(t, testConfig.MaxAttempts, stored.MaxAttempts)
+		assert.JSONEq(t, `{"value":"a"}`, string(stored.Payload))
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		queue, _, _ := newTestQueue()
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error { return nil })
+
+		job, err := queue.Enqueue(ctx, "test", nil, RunAt(testTime.Add(time.Hour)), MaxAttempts(1))
+		require.NoError(t, err)
+		assert.Equal(t, testTime.Add(time.Hour), job.RunAt)
+		assert.Equal(t, 1, job.MaxAttempts)
+
+		processed, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.False(t, processed, "delayed jobs are not due yet")
+	})
+
+	t.Run("Unknown type", func(t *testing.T) {
+		queue, _, _ := newTestQueue()
+
+		_, err := queue.Enqueue(ctx, "unknown", nil)
+		assert.ErrorIs(t, err, ErrUnknownType)
+	})
+}
+
+func TestQueue_RunOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		queue, repo, _ := newTestQueue()
+		var got testPayload
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+			return Decode(job, &got)
+		})
+
+		job, err := queue.Enqueue(ctx, "test", testPayload{Value: "a"})
+		require.NoError(t, err)
+
+		processed, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.True(t, processed)
+		assert.Equal(t, "a", got.Value)
+
+		stored, err := repo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobSucceeded, stored.Status)
+		assert.Equal(t, 1, stored.Attempts)
+	})
+
+	t.Run("No due jobs", func(t *testing.T) {
+		queue, _, _ := newTestQueue()
+
+		processed, err := queue.RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("Retries with backoff until dead", func(t *testing.T) {
+		queue, repo, clk := newTestQueue()
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+			return errors.New("boom")
+		})
+
+		job, err := queue.Enqueue(ctx, "test", nil)
+		require.NoError(t, err)
+
+		for _, backoff := range []time.Duration{10 * time.Second, 15 * time.Second} {
+			_, err := queue.RunOnce(ctx)
+			require.NoError(t, err)
+
+			stored, err := repo.Get(ctx, job.ID)
+			require.NoError(t, err)
+			assert.Equal(t, repository.JobPending, stored.Status)
+			assert.Equal(t, clk.Now().Add(backoff), stored.RunAt)
+			assert.Equal(t, "boom", stored.LastError)
+
+			processed, err := queue.RunOnce(ctx)
+			require.NoError(t, err)
+			assert.False(t, processed, "retries wait for their backoff")
+			clk.Advance(backoff)
+		}
+
+		_, err = queue.RunOnce(ctx)
+		require.NoError(t, err)
+
+		stored, err := repo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobDead, stored.Status)
+		assert.Equal(t, 3, stored.Attempts)
+	})
+
+	t.Run("Permanent errors are not retried", func(t *testing.T) {
+		queue, repo, _ := newTestQueue()
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+			return Decode(job, &testPayload{})
+		})
+
+		job, err := queue.Enqueue(ctx, "test", "not an object")
+		require.NoError(t, err)
+
+		_, err = queue.RunOnce(ctx)
+		require.NoError(t, err)
+
+		stored, err := repo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobDead, stored.Status)
+		assert.Equal(t, 1, stored.Attempts)
+	})
+
+	t.Run("Panics fail the attempt", func(t *testing.T) {
+		queue, repo, _ := newTestQueue()
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+			panic("boom")
+		})
+
+		job, err := queue.Enqueue(ctx, "test", nil)
+		require.NoError(t, err)
+
+		_, err = queue.RunOnce(ctx)
+		require.NoError(t, err)
+
+		stored, err := repo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobPending, stored.Status)
+		assert.Contains(t, stored.LastError, "panicked")
+	})
+
+	t.Run("Expired leases are claimed again", func(t *testing.T) {
+		queue, repo, clk := newTestQueue()
+		queue.Register("test", func(ctx context.Context, job *repository.Job) error { return nil })
+
+		job, err := queue.Enqueue(ctx, "test", nil)
+		require.NoError(t, err)
+
+		// A worker claims the job and dies
+		claimed, err := repo.Claim(ctx, []string{"test"}, testConfig.Lease)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+
+		processed, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.False(t, processed, "leased jobs are not claimed twice")
+
+		clk.Advance(testConfig.Lease)
+		processed, err = queue.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.True(t, processed)
+
+		stored, err := repo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobSucceeded, stored.Status)
+		assert.Equal(t, 2, stored.Attempts)
+
+		// The dead worker can no longer settle its claim
+		assert.ErrorIs(t, repo.Complete(ctx, claimed), repository.ErrNotFound)
+	})
+}
+
+func TestQueue_DeadLetters(t *testing.T) {
+	ctx := context.Background()
+	queue, _, clk := newTestQueue()
+	fail := true
+	queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	queue.Register("other", func(ctx context.Context, job *repository.Job) error {
+		return Permanent(errors.New("invalid"))
+	})
+
+	job, err := queue.Enqueue(ctx, "test", testPayload{Value: "a"})
+	require.NoError(t, err)
+	for i := 0; i < testConfig.MaxAttempts; i++ {
+		_, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+		clk.Advance(testConfig.MaxRetryBackoff)
+	}
+	_, err = queue.Enqueue(ctx, "other", nil)
+	require.NoError(t, err)
+	_, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+
+	dead, total, err := queue.ListDead(ctx, repository.DeadJobFilter{}, domain.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "other", dead[0].Type, "most recently dead first")
+
+	dead, total, err = queue.ListDead(ctx, repository.DeadJobFilter{Type: "test"}, domain.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.JSONEq(t, `{"value":"a"}`, string(dead[0].Payload))
+	require.Len(t, dead[0].Errors, 3, "every failed attempt is kept")
+	assert.Equal(t, 3, dead[0].Errors[2].Attempt)
+	assert.Equal(t, "boom", dead[0].Errors[2].Error)
+
+	t.Run("Replay", func(t *testing.T) {
+		fail = false
+		replayed, err := queue.Replay(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobPending, replayed.Status)
+		assert.Equal(t, 0, replayed.Attempts)
+
+		processed, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.True(t, processed)
+		stored, err := queue.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobSucceeded, stored.Status)
+		assert.Len(t, stored.Errors, 3, "the error history survives the replay")
+
+		_, err = queue.Replay(ctx, job.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound, "only dead jobs are replayed")
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		removed, err := queue.Purge(ctx, repository.DeadJobFilter{Before: clk.Now()})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), removed, "the job died at the current time")
+
+		removed, err = queue.Purge(ctx, repository.DeadJobFilter{Type: "other"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+
+		_, total, err := queue.ListDead(ctx, repository.DeadJobFilter{}, domain.ListOptions{})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
+
+func TestQueue_Priority(t *testing.T) {
+	ctx := context.Background()
+	queue, _, _ := newTestQueue()
+	var order []string
+	queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+		var payload testPayload
+		if err := Decode(job, &payload); err != nil {
+			return err
+		}
+		order = append(order, payload.Value)
+		return nil
+	})
+
+	_, err := queue.Enqueue(ctx, "test", testPayload{Value: "low"}, Priority(-1))
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "test", testPayload{Value: "default"})
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "test", testPayload{Value: "high"}, Priority(10))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"high", "default", "low"}, order)
+}
+
+func TestQueue_Key(t *testing.T) {
+	ctx := context.Background()
+	queue, _, _ := newTestQueue()
+	queue.Register("test", func(ctx context.Context, job *repository.Job) error { return nil })
+
+	_, err := queue.Enqueue(ctx, "test", nil, Key("report@2024-01-02"))
+	require.NoError(t, err)
+
+	_, err = queue.Enqueue(ctx, "test", nil, Key("report@2024-01-02"))
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	_, err = queue.Enqueue(ctx, "test", nil, Key("report@2024-01-03"))
+	assert.NoError(t, err)
+}
+
+func TestQueue_Concurrency(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.NewFakeClock(testTime)
+	cfg := testConfig
+	cfg.Concurrency = map[string]int{"slow": 1}
+	queue := NewQueue(repository.NewMockJobRepository(clk), clk, cfg)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	queue.Register("slow", func(ctx context.Context, job *repository.Job) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	queue.Register("fast", func(ctx context.Context, job *repository.Job) error { return nil })
+
+	for i := 0; i < 2; i++ {
+		_, err := queue.Enqueue(ctx, "slow", nil, Priority(1))
+		require.NoError(t, err)
+	}
+	fast, err := queue.Enqueue(ctx, "fast", nil)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := queue.RunOnce(ctx)
+		done <- err
+	}()
+	<-started
+
+	stats, err := queue.Stats(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, TypeStats{Type: "slow", Pending: 1, Running: 1, Concurrency: 1, InFlight: 1}, stats.Types[1])
+
+	processed, err := queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed, "the limit only holds back the slow jobs")
+	stored, err := queue.Get(ctx, fast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, repository.JobSucceeded, stored.Status)
+
+	processed, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.False(t, processed, "the second slow job waits for the first")
+
+	close(release)
+	require.NoError(t, <-done)
+	processed, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+}
+
+func TestQueue_Stats(t *testing.T) {
+	ctx := context.Background()
+	queue, _, clk := newTestQueue()
+	queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+		var payload testPayload
+		if err := Decode(job, &payload); err != nil {
+			return err
+		}
+		if payload.Value == "fail" {
+			return Permanent(errors.New("boom"))
+		}
+		return nil
+	})
+	queue.Register("idle", func(ctx context.Context, job *repository.Job) error { return nil })
+
+	for _, value := range []string{"ok", "ok", "ok", "fail"} {
+		_, err := queue.Enqueue(ctx, "test", testPayload{Value: value})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 4; i++ {
+		_, err := queue.RunOnce(ctx)
+		require.NoError(t, err)
+	}
+	_, err := queue.Enqueue(ctx, "test", testPayload{Value: "ok"})
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, "test", testPayload{Value: "ok"}, RunAt(testTime.Add(time.Hour)))
+	require.NoError(t, err)
+
+	stats, err := queue.Stats(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, testTime.Add(-10*time.Minute), stats.Since)
+	require.Len(t, stats.Types, 2)
+	assert.Equal(t, TypeStats{Type: "idle"}, stats.Types[0], "registered types are reported without jobs")
+	assert.Equal(t, TypeStats{
+		Type:         "test",
+		Pending:      1,
+		Scheduled:    1,
+		Dead:         1,
+		Succeeded:    3,
+		Failed:       1,
+		Throughput:   0.3,
+		FailureRatio: 0.25,
+	}, stats.Types[1])
+	assert.Equal(t, int64(1), stats.Total.Dead)
+
+	clk.Advance(time.Hour)
+	stats, err = queue.Stats(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Types[1].Succeeded, "jobs settled before the window are not counted")
+	assert.Zero(t, stats.Types[1].FailureRatio)
+	assert.Equal(t, int64(2), stats.Types[1].Pending)
+}
+
+func TestQueue_StartStop(t *testing.T) {
+	clk := testutil.NewFakeClock(testTime)
+	queue := NewQueue(repository.NewMockJobRepository(clk), clk, testConfig)
+
+	done := make(chan string, 1)
+	queue.Register("test", func(ctx context.Context, job *repository.Job) error {
+		var payload testPayload
+		if err := Decode(job, &payload); err != nil {
+			return err
+		}
+		done <- payload.Value
+		return nil
+	})
+
+	queue.Start(context.Background())
+	_, err := queue.Enqueue(context.Background(), "test", testPayload{Value: "a"})
+	require.NoError(t, err)
+
+	select {
+	case value := <-done:
+		assert.Equal(t, "a", value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("job was not processed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, queue.Stop(ctx))
+}
+
+func TestQueue_Every(t *testing.T) {
+	clk := testutil.NewFakeClock(testTime)
+	repo := repository.NewMockJobRepository(clk)
+	queue := NewQueue(repo, clk, testConfig)
+
+	done := make(chan struct{}, 1)
+	queue.Register("periodic", func(ctx context.Context, job *repository.Job) error {
+		done <- struct{}{}
+		return nil
+	})
+	queue.Every("periodic", time.Hour)
+
+	queue.Start(context.Background())
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.NoError(t, queue.Stop(ctx))
+	}()
+
+	require.Eventually(t, func() bool { return clk.Waiters() == 1 }, 5*time.Second, time.Millisecond)
+	for i := 0; i < 2; i++ {
+		clk.Advance(time.Hour)
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("periodic job %d was not processed", i+1)
+		}
+	}
+}
+
+func TestQueue_Cron(t *testing.T) {
+	clk := testutil.NewFakeClock(testTime)
+	repo := repository.NewMockJobRepository(clk)
+	queue := NewQueue(repo, clk, testConfig)
+	queue.Register("report", func(ctx context.Context, job *repository.Job) error { return nil })
+
+	assert.Error(t, queue.Cron("report", "every day"))
+	require.NoError(t, queue.Cron("report", "30 * * * *"))
+
+	// Another process already enqueued the first occurrence
+	_, err := queue.Enqueue(context.Background(), "report", nil, Key("report@2024-01-02T03:30:00Z"))
+	require.NoError(t, err)
+
+	queue.Start(context.Background())
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.NoError(t, queue.Stop(ctx))
+	}()
+
+	require.Eventually(t, func() bool { return clk.Waiters() == 1 }, 5*time.Second, time.Millisecond)
+	stats, err := queue.Stats(context.Background(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total.Pending+stats.Total.Scheduled+stats.Total.Succeeded, "the occurrence is enqueued once")
+
+	clk.Set(time.Date(2024, 1, 2, 3, 30, 0, 0, time.UTC))
+	require.Eventually(t, func() bool {
+		stats, err := queue.Stats(context.Background(), time.Hour)
+		return err == nil && stats.Total.Scheduled == 1 && stats.Total.Succeeded == 1
+	}, 5*time.Second, time.Millisecond, "the next occurrence is enqueued ahead of time")
+}
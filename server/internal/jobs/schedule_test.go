@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvery(t *testing.T) {
+	schedule := Every(time.Hour)
+
+	assert.Equal(t, time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC), schedule.Next(testTime))
+	assert.Equal(t, time.Date(2024, 1, 2, 5, 0, 0, 0, time.UTC), schedule.Next(time.Date(2024, 1, 2, 4, 0, 0, 0, time.UTC)))
+}
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2024, 1, 31, 22, 50, 0, 0, time.UTC) // a Wednesday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 22, 51, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)},
+		{"0,55 22 * * *", time.Date(2024, 1, 31, 22, 55, 0, 0, time.UTC)},
+		{"30 9-17/4 * * *", time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 1-5", time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 5", time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)}, // the 15th or a Friday
+		{"@hourly", time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseCron(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@often"} {
+			_, err := ParseCron(spec)
+			assert.Error(t, err, spec)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TypeStats reports the queue of a job type
+type TypeStats struct {
+	Type string
+
+	// Pending jobs are due, Scheduled jobs wait for a later time, Running jobs are claimed by a
+	// worker of any process and Dead jobs wait for inspection
+	Pending   int64
+	Scheduled int64
+	Running   int64
+	Dead      int64
+
+	// Succeeded jobs and Failed attempts over the window
+	Succeeded int64
+	Failed    int64
+
+	// Throughput is the number of jobs succeeding per minute over the window
+	Throughput float64
+
+	// FailureRatio is the share of attempts over the window that failed, 0 without attempts
+	FailureRatio float64
+
+	// Concurrency is the limit of jobs running at once in each process, 0 when unlimited, and
+	// InFlight the jobs running in this process
+	Concurrency int
+	InFlight    int
+}
+
+// Stats reports the queues of every job type over a window ending now
+type Stats struct {
+	Since  time.Time
+	Window time.Duration
+	Types  []TypeStats
+
+	// Total sums the queues of every type
+	Total TypeStats
+}
+
+// Stats reports the queue of every stored or registered job type, sorted by type, with the jobs
+// that succeeded and failed over the last window
+func (q *Queue) Stats(ctx context.Context, window time.Duration) (*Stats, error) {
+	since := q.clock.Now().Add(-window)
+	counts, err := q.repo.Stats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*TypeStats)
+	for _, jobType := range q.types() {
+		byType[jobType] = &TypeStats{Type: jobType}
+	}
+	for _, c := range counts {
+		byType[c.Type] = &TypeStats{
+			Type:      c.Type,
+			Pending:   c.Pending,
+			Scheduled: c.Scheduled,
+			Running:   c.Running,
+			Dead:      c.Dead,
+			Succeeded: c.Succeeded,
+			Failed:    c.Failed,
+		}
+	}
+
+	q.claimMu.Lock()
+	for jobType, s := range byType {
+		s.Concurrency = q.config.Concurrency[jobType]
+		s.InFlight = q.running[jobType]
+	}
+	q.claimMu.Unlock()
+
+	stats := &Stats{Since: since, Window: window, Types: make([]TypeStats, 0, len(byType))}
+	for _, s := range byType {
+		rates(s, window)
+		stats.Types = append(stats.Types, *s)
+
+		stats.Total.Pending += s.Pending
+		stats.Total.Scheduled += s.Scheduled
+		stats.Total.Running += s.Running
+		stats.Total.Dead += s.Dead
+		stats.Total.Succeeded += s.Succeeded
+		stats.Total.Failed += s.Failed
+		stats.Total.InFlight += s.InFlight
+	}
+	rates(&stats.Total, window)
+	sort.Slice(stats.Types, func(i, j int) bool { return stats.Types[i].Type < stats.Types[j].Type })
+	return stats, nil
+}
+
+// rates derives the throughput and failure ratio of s from its counts over window
+func rates(s *TypeStats, window time.Duration) {
+	if window > 0 {
+		s.Throughput = float64(s.Succeeded) / window.Minutes()
+	}
+	if attempts := s.Succeeded + s.Failed; attempts > 0 {
+		s.FailureRatio = float64(s.Failed) / float64(attempts)
+	}
+}
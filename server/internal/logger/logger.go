@@ -9,6 +9,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"quizizz.com/pkg/correlation"
 )
 
 var (
@@ -44,8 +45,9 @@ func Init(env string) {
 		config.Level = zap.NewAtomicLevelAt(logLevel)
 
 		var err error
-		// Add AddCallerSkip(1) to skip the logger wrapper and show the actual caller
-		globalLogger, err = config.Build(zap.AddCallerSkip(1))
+		// Add AddCallerSkip(1) to skip the logger wrapper and show the actual caller; errors are
+		// also sent to error tracking
+		globalLogger, err = config.Build(zap.AddCallerSkip(1), zap.WrapCore(newTrackingCore))
 		if err != nil {
 			// If we can't initialize the logger, use a simple stdout logger
 			core := zapcore.NewCore(
@@ -73,7 +75,7 @@ func SetLevel(level zapcore.Level) {
 		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 		config.EncoderConfig.FunctionKey = "function"
 
-		newLogger, err := config.Build(zap.AddCallerSkip(1))
+		newLogger, err := config.Build(zap.AddCallerSkip(1), zap.WrapCore(newTrackingCore))
 		if err == nil {
 			// If successful, replace the global logger
 			globalLogger = newLogger
@@ -95,7 +97,7 @@ func Info(msg string, fields ...zap.Field) {
 // InfoCtx logs an info level message with structured context, including trace information
 func InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
 	ensureLogger()
-	globalLogger.Info(msg, appendTraceFields(ctx, fields)...)
+	globalLogger.Info(msg, appendContextFields(ctx, fields)...)
 }
 
 // Error logs an error level message with structured context
@@ -105,9 +107,10 @@ func Error(msg string, fields ...zap.Field) {
 }
 
 // ErrorCtx logs an error level message with structured context, including trace information
+// The message is reported to error tracking with the request and user of ctx.
 func ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
 	ensureLogger()
-	globalLogger.Error(msg, appendTraceFields(ctx, fields)...)
+	globalLogger.Error(msg, append(appendContextFields(ctx, fields), contextField(ctx))...)
 }
 
 // Debug logs a debug level message with structured context
@@ -119,7 +122,7 @@ func Debug(msg string, fields ...zap.Field) {
 // DebugCtx logs a debug level message with structured context, including trace information
 func DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
 	ensureLogger()
-	globalLogger.Debug(msg, appendTraceFields(ctx, fields)...)
+	globalLogger.Debug(msg, appendContextFields(ctx, fields)...)
 }
 
 // Warn logs a warning level message with structured context
@@ -131,7 +134,7 @@ func Warn(msg string, fields ...zap.Field) {
 // WarnCtx logs a warning level message with structured context, including trace information
 func WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
 	ensureLogger()
-	globalLogger.Warn(msg, appendTraceFields(ctx, fields)...)
+	globalLogger.Warn(msg, appendContextFields(ctx, fields)...)
 }
 
 // Fatal logs a fatal level message with structured context and exits
@@ -143,7 +146,7 @@ func Fatal(msg string, fields ...zap.Field) {
 // FatalCtx logs a fatal level message with structured context, including trace information, and exits
 func FatalCtx(ctx context.Context, msg string, fields ...zap.Field) {
 	ensureLogger()
-	globalLogger.Fatal(msg, appendTraceFields(ctx, fields)...)
+	globalLogger.Fatal(msg, append(appendContextFields(ctx, fields), contextField(ctx))...)
 }
 
 // With creates a child logger with additional context
@@ -155,7 +158,7 @@ func With(fields ...zap.Field) *zap.Logger {
 // WithCtx creates a child logger with additional context, including trace information
 func WithCtx(ctx context.Context, fields ...zap.Field) *zap.Logger {
 	ensureLogger()
-	return globalLogger.With(appendTraceFields(ctx, fields)...)
+	return globalLogger.With(appendContextFields(ctx, fields)...)
 }
 
 // ensureLogger initializes the logger if it hasn't been done yet
@@ -173,6 +176,19 @@ func Sync() error {
 	return nil
 }
 
+// appendContextFields adds the trace of ctx and, for work done under impersonation, its user and
+// actor to the field list
+func appendContextFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	fields = appendTraceFields(ctx, fields)
+	if ctx == nil {
+		return fields
+	}
+	if ids := correlation.FromContext(ctx); ids.Impersonated() {
+		fields = append(fields[:len(fields):len(fields)], zap.String("userID", ids.UserID), zap.String("actorID", ids.ActorID))
+	}
+	return fields
+}
+
 // appendTraceFields adds trace and span IDs from the context to the field list
 func appendTraceFields(ctx context.Context, fields []zap.Field) []zap.Field {
 	if ctx == nil {
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"quizizz.com/pkg/errortracking"
+)
+
+// Keys of the fields steering error tracking; their SkipType keeps them out of the log
+const (
+	notReportedKey = "errortracking.skip"
+	contextKey     = "errortracking.context"
+)
+
+// fatalFlushTimeout bounds the wait for a fatal entry to reach error tracking before the process exits
+const fatalFlushTimeout = 2 * time.Second
+
+// NotReported returns a field keeping an error entry out of error tracking, for errors that are
+// reported elsewhere; it is not written to the log
+func NotReported() zap.Field {
+	return zap.Field{Key: notReportedKey, Type: zapcore.SkipType}
+}
+
+// contextField returns a field carrying ctx, so entries logged with it are reported with its scope
+func contextField(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextKey, Type: zapcore.SkipType, Interface: ctx}
+}
+
+// trackingCore reports entries at error level and above to errortracking.Default()
+// Entries with an error field are reported as that exception, others as messages; their fields
+// are sent along as extra data, and entries logged with a context get its request and user.
+type trackingCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+}
+
+// newTrackingCore wraps core to report its error entries
+func newTrackingCore(core zapcore.Core) zapcore.Core {
+	return &trackingCore{Core: core}
+}
+
+// With adds fields to the core, keeping them for the entries it reports
+func (c *trackingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &trackingCore{
+		Core:   c.Core.With(fields),
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// Check adds the core to entries it is enabled for, so Write sees them
+func (c *trackingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write writes the entry, then reports it when it is an error
+func (c *trackingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(entry, fields)
+	if entry.Level < zapcore.ErrorLevel {
+		return err
+	}
+
+	all := append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	var cause error
+	var ctx context.Context
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range all {
+		if field.Type == zapcore.SkipType {
+			switch field.Key {
+			case notReportedKey:
+				return err
+			case contextKey:
+				ctx, _ = field.Interface.(context.Context)
+			}
+			continue
+		}
+		if fieldErr, ok := field.Interface.(error); ok && field.Type == zapcore.ErrorType && cause == nil {
+			cause = fieldErr
+		}
+		field.AddTo(encoder)
+	}
+
+	reporter := errortracking.Default()
+	scope := errortracking.ScopeFromContext(ctx)
+	scope.Extra = encoder.Fields
+	scope.Extra["message"] = entry.Message
+	if entry.Caller.Defined {
+		scope.Extra["caller"] = entry.Caller.TrimmedPath()
+	}
+
+	if cause != nil {
+		reporter.CaptureException(cause, scope)
+	} else {
+		reporter.CaptureMessage(entry.Message, trackingLevel(entry.Level), scope)
+	}
+	if entry.Level >= zapcore.DPanicLevel {
+		reporter.Flush(fatalFlushTimeout)
+	}
+	return err
+}
+
+// trackingLevel maps a log level to the level of its error tracking message
+func trackingLevel(level zapcore.Level) errortracking.Level {
+	if level >= zapcore.DPanicLevel {
+		return errortracking.LevelFatal
+	}
+	return errortracking.LevelError
+}
@@ -0,0 +1,374 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	impersonation "quizizz.com/internal/auth/impersonation"
+	domain "quizizz.com/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AdminService is an autogenerated mock type for the AdminService type
+type AdminService struct {
+	mock.Mock
+}
+
+type AdminService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *AdminService) EXPECT() *AdminService_Expecter {
+	return &AdminService_Expecter{mock: &_m.Mock}
+}
+
+// ForcePasswordReset provides a mock function with given fields: ctx, actorID, userID
+func (_m *AdminService) ForcePasswordReset(ctx context.Context, actorID string, userID string) error {
+	ret := _m.Called(ctx, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForcePasswordReset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, actorID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AdminService_ForcePasswordReset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForcePasswordReset'
+type AdminService_ForcePasswordReset_Call struct {
+	*mock.Call
+}
+
+// ForcePasswordReset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - userID string
+func (_e *AdminService_Expecter) ForcePasswordReset(ctx interface{}, actorID interface{}, userID interface{}) *AdminService_ForcePasswordReset_Call {
+	return &AdminService_ForcePasswordReset_Call{Call: _e.mock.On("ForcePasswordReset", ctx, actorID, userID)}
+}
+
+func (_c *AdminService_ForcePasswordReset_Call) Run(run func(ctx context.Context, actorID string, userID string)) *AdminService_ForcePasswordReset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *AdminService_ForcePasswordReset_Call) Return(_a0 error) *AdminService_ForcePasswordReset_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AdminService_ForcePasswordReset_Call) RunAndReturn(run func(context.Context, string, string) error) *AdminService_ForcePasswordReset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Impersonate provides a mock function with given fields: ctx, actorID, userID
+func (_m *AdminService) Impersonate(ctx context.Context, actorID string, userID string) (string, impersonation.Claims, error) {
+	ret := _m.Called(ctx, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Impersonate")
+	}
+
+	var r0 string
+	var r1 impersonation.Claims
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, impersonation.Claims, error)); ok {
+		return rf(ctx, actorID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, actorID, userID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) impersonation.Claims); ok {
+		r1 = rf(ctx, actorID, userID)
+	} else {
+		r1 = ret.Get(1).(impersonation.Claims)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, actorID, userID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// AdminService_Impersonate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Impersonate'
+type AdminService_Impersonate_Call struct {
+	*mock.Call
+}
+
+// Impersonate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - userID string
+func (_e *AdminService_Expecter) Impersonate(ctx interface{}, actorID interface{}, userID interface{}) *AdminService_Impersonate_Call {
+	return &AdminService_Impersonate_Call{Call: _e.mock.On("Impersonate", ctx, actorID, userID)}
+}
+
+func (_c *AdminService_Impersonate_Call) Run(run func(ctx context.Context, actorID string, userID string)) *AdminService_Impersonate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *AdminService_Impersonate_Call) Return(_a0 string, _a1 impersonation.Claims, _a2 error) *AdminService_Impersonate_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *AdminService_Impersonate_Call) RunAndReturn(run func(context.Context, string, string) (string, impersonation.Claims, error)) *AdminService_Impersonate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function with given fields: ctx, filter, opts
+func (_m *AdminService) ListUsers(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	ret := _m.Called(ctx, filter, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []*domain.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, domain.ListOptions) ([]*domain.User, int64, error)); ok {
+		return rf(ctx, filter, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, domain.ListOptions) []*domain.User); ok {
+		r0 = rf(ctx, filter, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserFilter, domain.ListOptions) int64); ok {
+		r1 = rf(ctx, filter, opts)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.UserFilter, domain.ListOptions) error); ok {
+		r2 = rf(ctx, filter, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// AdminService_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type AdminService_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.UserFilter
+//   - opts domain.ListOptions
+func (_e *AdminService_Expecter) ListUsers(ctx interface{}, filter interface{}, opts interface{}) *AdminService_ListUsers_Call {
+	return &AdminService_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, filter, opts)}
+}
+
+func (_c *AdminService_ListUsers_Call) Run(run func(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions)) *AdminService_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserFilter), args[2].(domain.ListOptions))
+	})
+	return _c
+}
+
+func (_c *AdminService_ListUsers_Call) Return(_a0 []*domain.User, _a1 int64, _a2 error) *AdminService_ListUsers_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *AdminService_ListUsers_Call) RunAndReturn(run func(context.Context, domain.UserFilter, domain.ListOptions) ([]*domain.User, int64, error)) *AdminService_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Lock provides a mock function with given fields: ctx, actorID, userID, reason
+func (_m *AdminService) Lock(ctx context.Context, actorID string, userID string, reason string) error {
+	ret := _m.Called(ctx, actorID, userID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Lock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, actorID, userID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AdminService_Lock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Lock'
+type AdminService_Lock_Call struct {
+	*mock.Call
+}
+
+// Lock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - userID string
+//   - reason string
+func (_e *AdminService_Expecter) Lock(ctx interface{}, actorID interface{}, userID interface{}, reason interface{}) *AdminService_Lock_Call {
+	return &AdminService_Lock_Call{Call: _e.mock.On("Lock", ctx, actorID, userID, reason)}
+}
+
+func (_c *AdminService_Lock_Call) Run(run func(ctx context.Context, actorID string, userID string, reason string)) *AdminService_Lock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *AdminService_Lock_Call) Return(_a0 error) *AdminService_Lock_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AdminService_Lock_Call) RunAndReturn(run func(context.Context, string, string, string) error) *AdminService_Lock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx
+func (_m *AdminService) Stats(ctx context.Context) (*domain.UserStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *domain.UserStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*domain.UserStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *domain.UserStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AdminService_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type AdminService_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *AdminService_Expecter) Stats(ctx interface{}) *AdminService_Stats_Call {
+	return &AdminService_Stats_Call{Call: _e.mock.On("Stats", ctx)}
+}
+
+func (_c *AdminService_Stats_Call) Run(run func(ctx context.Context)) *AdminService_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *AdminService_Stats_Call) Return(_a0 *domain.UserStats, _a1 error) *AdminService_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AdminService_Stats_Call) RunAndReturn(run func(context.Context) (*domain.UserStats, error)) *AdminService_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unlock provides a mock function with given fields: ctx, actorID, userID
+func (_m *AdminService) Unlock(ctx context.Context, actorID string, userID string) error {
+	ret := _m.Called(ctx, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unlock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, actorID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AdminService_Unlock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unlock'
+type AdminService_Unlock_Call struct {
+	*mock.Call
+}
+
+// Unlock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID string
+//   - userID string
+func (_e *AdminService_Expecter) Unlock(ctx interface{}, actorID interface{}, userID interface{}) *AdminService_Unlock_Call {
+	return &AdminService_Unlock_Call{Call: _e.mock.On("Unlock", ctx, actorID, userID)}
+}
+
+func (_c *AdminService_Unlock_Call) Run(run func(ctx context.Context, actorID string, userID string)) *AdminService_Unlock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *AdminService_Unlock_Call) Return(_a0 error) *AdminService_Unlock_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AdminService_Unlock_Call) RunAndReturn(run func(context.Context, string, string) error) *AdminService_Unlock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAdminService creates a new instance of AdminService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAdminService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AdminService {
+	mock := &AdminService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
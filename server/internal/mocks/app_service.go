@@ -0,0 +1,77 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// AppService is an autogenerated mock type for the AppService type
+type AppService struct {
+	mock.Mock
+}
+
+type AppService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *AppService) EXPECT() *AppService_Expecter {
+	return &AppService_Expecter{mock: &_m.Mock}
+}
+
+// GetPingMessage provides a mock function with no fields
+func (_m *AppService) GetPingMessage() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPingMessage")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// AppService_GetPingMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPingMessage'
+type AppService_GetPingMessage_Call struct {
+	*mock.Call
+}
+
+// GetPingMessage is a helper method to define mock.On call
+func (_e *AppService_Expecter) GetPingMessage() *AppService_GetPingMessage_Call {
+	return &AppService_GetPingMessage_Call{Call: _e.mock.On("GetPingMessage")}
+}
+
+func (_c *AppService_GetPingMessage_Call) Run(run func()) *AppService_GetPingMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *AppService_GetPingMessage_Call) Return(_a0 string) *AppService_GetPingMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AppService_GetPingMessage_Call) RunAndReturn(run func() string) *AppService_GetPingMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAppService creates a new instance of AppService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAppService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AppService {
+	mock := &AppService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,215 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+
+	repository "quizizz.com/internal/repository"
+)
+
+// AvatarService is an autogenerated mock type for the AvatarService type
+type AvatarService struct {
+	mock.Mock
+}
+
+type AvatarService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *AvatarService) EXPECT() *AvatarService_Expecter {
+	return &AvatarService_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: ctx, userID
+func (_m *AvatarService) Delete(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AvatarService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type AvatarService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *AvatarService_Expecter) Delete(ctx interface{}, userID interface{}) *AvatarService_Delete_Call {
+	return &AvatarService_Delete_Call{Call: _e.mock.On("Delete", ctx, userID)}
+}
+
+func (_c *AvatarService_Delete_Call) Run(run func(ctx context.Context, userID string)) *AvatarService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *AvatarService_Delete_Call) Return(_a0 error) *AvatarService_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AvatarService_Delete_Call) RunAndReturn(run func(context.Context, string) error) *AvatarService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Open provides a mock function with given fields: ctx, userID, size
+func (_m *AvatarService) Open(ctx context.Context, userID string, size string) (io.ReadCloser, *repository.FileInfo, error) {
+	ret := _m.Called(ctx, userID, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Open")
+	}
+
+	var r0 io.ReadCloser
+	var r1 *repository.FileInfo
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (io.ReadCloser, *repository.FileInfo, error)); ok {
+		return rf(ctx, userID, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) io.ReadCloser); ok {
+		r0 = rf(ctx, userID, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) *repository.FileInfo); ok {
+		r1 = rf(ctx, userID, size)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*repository.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, userID, size)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// AvatarService_Open_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Open'
+type AvatarService_Open_Call struct {
+	*mock.Call
+}
+
+// Open is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - size string
+func (_e *AvatarService_Expecter) Open(ctx interface{}, userID interface{}, size interface{}) *AvatarService_Open_Call {
+	return &AvatarService_Open_Call{Call: _e.mock.On("Open", ctx, userID, size)}
+}
+
+func (_c *AvatarService_Open_Call) Run(run func(ctx context.Context, userID string, size string)) *AvatarService_Open_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *AvatarService_Open_Call) Return(_a0 io.ReadCloser, _a1 *repository.FileInfo, _a2 error) *AvatarService_Open_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *AvatarService_Open_Call) RunAndReturn(run func(context.Context, string, string) (io.ReadCloser, *repository.FileInfo, error)) *AvatarService_Open_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upload provides a mock function with given fields: ctx, userID, upload
+func (_m *AvatarService) Upload(ctx context.Context, userID string, upload io.Reader) (*repository.Job, error) {
+	ret := _m.Called(ctx, userID, upload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upload")
+	}
+
+	var r0 *repository.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader) (*repository.Job, error)); ok {
+		return rf(ctx, userID, upload)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Reader) *repository.Job); ok {
+		r0 = rf(ctx, userID, upload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, io.Reader) error); ok {
+		r1 = rf(ctx, userID, upload)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AvatarService_Upload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upload'
+type AvatarService_Upload_Call struct {
+	*mock.Call
+}
+
+// Upload is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - upload io.Reader
+func (_e *AvatarService_Expecter) Upload(ctx interface{}, userID interface{}, upload interface{}) *AvatarService_Upload_Call {
+	return &AvatarService_Upload_Call{Call: _e.mock.On("Upload", ctx, userID, upload)}
+}
+
+func (_c *AvatarService_Upload_Call) Run(run func(ctx context.Context, userID string, upload io.Reader)) *AvatarService_Upload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(io.Reader))
+	})
+	return _c
+}
+
+func (_c *AvatarService_Upload_Call) Return(_a0 *repository.Job, _a1 error) *AvatarService_Upload_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AvatarService_Upload_Call) RunAndReturn(run func(context.Context, string, io.Reader) (*repository.Job, error)) *AvatarService_Upload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAvatarService creates a new instance of AvatarService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAvatarService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AvatarService {
+	mock := &AvatarService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
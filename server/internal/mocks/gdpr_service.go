@@ -0,0 +1,157 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	gdpr "quizizz.com/internal/gdpr"
+
+	repository "quizizz.com/internal/repository"
+)
+
+// GDPRService is an autogenerated mock type for the GDPRService type
+type GDPRService struct {
+	mock.Mock
+}
+
+type GDPRService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *GDPRService) EXPECT() *GDPRService_Expecter {
+	return &GDPRService_Expecter{mock: &_m.Mock}
+}
+
+// Export provides a mock function with given fields: ctx, userID
+func (_m *GDPRService) Export(ctx context.Context, userID string) (*gdpr.Export, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 *gdpr.Export
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*gdpr.Export, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *gdpr.Export); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gdpr.Export)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GDPRService_Export_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Export'
+type GDPRService_Export_Call struct {
+	*mock.Call
+}
+
+// Export is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *GDPRService_Expecter) Export(ctx interface{}, userID interface{}) *GDPRService_Export_Call {
+	return &GDPRService_Export_Call{Call: _e.mock.On("Export", ctx, userID)}
+}
+
+func (_c *GDPRService_Export_Call) Run(run func(ctx context.Context, userID string)) *GDPRService_Export_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *GDPRService_Export_Call) Return(_a0 *gdpr.Export, _a1 error) *GDPRService_Export_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *GDPRService_Export_Call) RunAndReturn(run func(context.Context, string) (*gdpr.Export, error)) *GDPRService_Export_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestErasure provides a mock function with given fields: ctx, userID
+func (_m *GDPRService) RequestErasure(ctx context.Context, userID string) (*repository.Job, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestErasure")
+	}
+
+	var r0 *repository.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.Job, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.Job); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GDPRService_RequestErasure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestErasure'
+type GDPRService_RequestErasure_Call struct {
+	*mock.Call
+}
+
+// RequestErasure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *GDPRService_Expecter) RequestErasure(ctx interface{}, userID interface{}) *GDPRService_RequestErasure_Call {
+	return &GDPRService_RequestErasure_Call{Call: _e.mock.On("RequestErasure", ctx, userID)}
+}
+
+func (_c *GDPRService_RequestErasure_Call) Run(run func(ctx context.Context, userID string)) *GDPRService_RequestErasure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *GDPRService_RequestErasure_Call) Return(_a0 *repository.Job, _a1 error) *GDPRService_RequestErasure_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *GDPRService_RequestErasure_Call) RunAndReturn(run func(context.Context, string) (*repository.Job, error)) *GDPRService_RequestErasure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewGDPRService creates a new instance of GDPRService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGDPRService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *GDPRService {
+	mock := &GDPRService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,565 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	httpclient "quizizz.com/pkg/httpclient"
+)
+
+// HTTPClient is an autogenerated mock type for the API type
+type HTTPClient struct {
+	mock.Mock
+}
+
+type HTTPClient_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *HTTPClient) EXPECT() *HTTPClient_Expecter {
+	return &HTTPClient_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: ctx, urlPath, headers
+func (_m *HTTPClient) Delete(ctx context.Context, urlPath string, headers map[string]string) (*httpclient.Response, error) {
+	ret := _m.Called(ctx, urlPath, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 *httpclient.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) (*httpclient.Response, error)); ok {
+		return rf(ctx, urlPath, headers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *httpclient.Response); ok {
+		r0 = rf(ctx, urlPath, headers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*httpclient.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = rf(ctx, urlPath, headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HTTPClient_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type HTTPClient_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) Delete(ctx interface{}, urlPath interface{}, headers interface{}) *HTTPClient_Delete_Call {
+	return &HTTPClient_Delete_Call{Call: _e.mock.On("Delete", ctx, urlPath, headers)}
+}
+
+func (_c *HTTPClient_Delete_Call) Run(run func(ctx context.Context, urlPath string, headers map[string]string)) *HTTPClient_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_Delete_Call) Return(_a0 *httpclient.Response, _a1 error) *HTTPClient_Delete_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HTTPClient_Delete_Call) RunAndReturn(run func(context.Context, string, map[string]string) (*httpclient.Response, error)) *HTTPClient_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, urlPath, headers
+func (_m *HTTPClient) Get(ctx context.Context, urlPath string, headers map[string]string) (*httpclient.Response, error) {
+	ret := _m.Called(ctx, urlPath, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *httpclient.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) (*httpclient.Response, error)); ok {
+		return rf(ctx, urlPath, headers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) *httpclient.Response); ok {
+		r0 = rf(ctx, urlPath, headers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*httpclient.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = rf(ctx, urlPath, headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HTTPClient_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type HTTPClient_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) Get(ctx interface{}, urlPath interface{}, headers interface{}) *HTTPClient_Get_Call {
+	return &HTTPClient_Get_Call{Call: _e.mock.On("Get", ctx, urlPath, headers)}
+}
+
+func (_c *HTTPClient_Get_Call) Run(run func(ctx context.Context, urlPath string, headers map[string]string)) *HTTPClient_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_Get_Call) Return(_a0 *httpclient.Response, _a1 error) *HTTPClient_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HTTPClient_Get_Call) RunAndReturn(run func(context.Context, string, map[string]string) (*httpclient.Response, error)) *HTTPClient_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetJSON provides a mock function with given fields: ctx, urlPath, headers, target
+func (_m *HTTPClient) GetJSON(ctx context.Context, urlPath string, headers map[string]string, target interface{}) error {
+	ret := _m.Called(ctx, urlPath, headers, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJSON")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string, interface{}) error); ok {
+		r0 = rf(ctx, urlPath, headers, target)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HTTPClient_GetJSON_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetJSON'
+type HTTPClient_GetJSON_Call struct {
+	*mock.Call
+}
+
+// GetJSON is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - headers map[string]string
+//   - target interface{}
+func (_e *HTTPClient_Expecter) GetJSON(ctx interface{}, urlPath interface{}, headers interface{}, target interface{}) *HTTPClient_GetJSON_Call {
+	return &HTTPClient_GetJSON_Call{Call: _e.mock.On("GetJSON", ctx, urlPath, headers, target)}
+}
+
+func (_c *HTTPClient_GetJSON_Call) Run(run func(ctx context.Context, urlPath string, headers map[string]string, target interface{})) *HTTPClient_GetJSON_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string]string), args[3].(interface{}))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_GetJSON_Call) Return(_a0 error) *HTTPClient_GetJSON_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *HTTPClient_GetJSON_Call) RunAndReturn(run func(context.Context, string, map[string]string, interface{}) error) *HTTPClient_GetJSON_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Patch provides a mock function with given fields: ctx, urlPath, body, headers
+func (_m *HTTPClient) Patch(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*httpclient.Response, error) {
+	ret := _m.Called(ctx, urlPath, body, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Patch")
+	}
+
+	var r0 *httpclient.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) (*httpclient.Response, error)); ok {
+		return rf(ctx, urlPath, body, headers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) *httpclient.Response); ok {
+		r0 = rf(ctx, urlPath, body, headers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*httpclient.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, map[string]string) error); ok {
+		r1 = rf(ctx, urlPath, body, headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HTTPClient_Patch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Patch'
+type HTTPClient_Patch_Call struct {
+	*mock.Call
+}
+
+// Patch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - body interface{}
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) Patch(ctx interface{}, urlPath interface{}, body interface{}, headers interface{}) *HTTPClient_Patch_Call {
+	return &HTTPClient_Patch_Call{Call: _e.mock.On("Patch", ctx, urlPath, body, headers)}
+}
+
+func (_c *HTTPClient_Patch_Call) Run(run func(ctx context.Context, urlPath string, body interface{}, headers map[string]string)) *HTTPClient_Patch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_Patch_Call) Return(_a0 *httpclient.Response, _a1 error) *HTTPClient_Patch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HTTPClient_Patch_Call) RunAndReturn(run func(context.Context, string, interface{}, map[string]string) (*httpclient.Response, error)) *HTTPClient_Patch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Post provides a mock function with given fields: ctx, urlPath, body, headers
+func (_m *HTTPClient) Post(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*httpclient.Response, error) {
+	ret := _m.Called(ctx, urlPath, body, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Post")
+	}
+
+	var r0 *httpclient.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) (*httpclient.Response, error)); ok {
+		return rf(ctx, urlPath, body, headers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) *httpclient.Response); ok {
+		r0 = rf(ctx, urlPath, body, headers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*httpclient.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, map[string]string) error); ok {
+		r1 = rf(ctx, urlPath, body, headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HTTPClient_Post_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Post'
+type HTTPClient_Post_Call struct {
+	*mock.Call
+}
+
+// Post is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - body interface{}
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) Post(ctx interface{}, urlPath interface{}, body interface{}, headers interface{}) *HTTPClient_Post_Call {
+	return &HTTPClient_Post_Call{Call: _e.mock.On("Post", ctx, urlPath, body, headers)}
+}
+
+func (_c *HTTPClient_Post_Call) Run(run func(ctx context.Context, urlPath string, body interface{}, headers map[string]string)) *HTTPClient_Post_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_Post_Call) Return(_a0 *httpclient.Response, _a1 error) *HTTPClient_Post_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HTTPClient_Post_Call) RunAndReturn(run func(context.Context, string, interface{}, map[string]string) (*httpclient.Response, error)) *HTTPClient_Post_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PostJSON provides a mock function with given fields: ctx, urlPath, body, target, headers
+func (_m *HTTPClient) PostJSON(ctx context.Context, urlPath string, body interface{}, target interface{}, headers map[string]string) error {
+	ret := _m.Called(ctx, urlPath, body, target, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostJSON")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, interface{}, map[string]string) error); ok {
+		r0 = rf(ctx, urlPath, body, target, headers)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HTTPClient_PostJSON_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PostJSON'
+type HTTPClient_PostJSON_Call struct {
+	*mock.Call
+}
+
+// PostJSON is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - body interface{}
+//   - target interface{}
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) PostJSON(ctx interface{}, urlPath interface{}, body interface{}, target interface{}, headers interface{}) *HTTPClient_PostJSON_Call {
+	return &HTTPClient_PostJSON_Call{Call: _e.mock.On("PostJSON", ctx, urlPath, body, target, headers)}
+}
+
+func (_c *HTTPClient_PostJSON_Call) Run(run func(ctx context.Context, urlPath string, body interface{}, target interface{}, headers map[string]string)) *HTTPClient_PostJSON_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(interface{}), args[4].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_PostJSON_Call) Return(_a0 error) *HTTPClient_PostJSON_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *HTTPClient_PostJSON_Call) RunAndReturn(run func(context.Context, string, interface{}, interface{}, map[string]string) error) *HTTPClient_PostJSON_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Put provides a mock function with given fields: ctx, urlPath, body, headers
+func (_m *HTTPClient) Put(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*httpclient.Response, error) {
+	ret := _m.Called(ctx, urlPath, body, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Put")
+	}
+
+	var r0 *httpclient.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) (*httpclient.Response, error)); ok {
+		return rf(ctx, urlPath, body, headers)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, map[string]string) *httpclient.Response); ok {
+		r0 = rf(ctx, urlPath, body, headers)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*httpclient.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, map[string]string) error); ok {
+		r1 = rf(ctx, urlPath, body, headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HTTPClient_Put_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Put'
+type HTTPClient_Put_Call struct {
+	*mock.Call
+}
+
+// Put is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - body interface{}
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) Put(ctx interface{}, urlPath interface{}, body interface{}, headers interface{}) *HTTPClient_Put_Call {
+	return &HTTPClient_Put_Call{Call: _e.mock.On("Put", ctx, urlPath, body, headers)}
+}
+
+func (_c *HTTPClient_Put_Call) Run(run func(ctx context.Context, urlPath string, body interface{}, headers map[string]string)) *HTTPClient_Put_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_Put_Call) Return(_a0 *httpclient.Response, _a1 error) *HTTPClient_Put_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HTTPClient_Put_Call) RunAndReturn(run func(context.Context, string, interface{}, map[string]string) (*httpclient.Response, error)) *HTTPClient_Put_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PutJSON provides a mock function with given fields: ctx, urlPath, body, target, headers
+func (_m *HTTPClient) PutJSON(ctx context.Context, urlPath string, body interface{}, target interface{}, headers map[string]string) error {
+	ret := _m.Called(ctx, urlPath, body, target, headers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PutJSON")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, interface{}, map[string]string) error); ok {
+		r0 = rf(ctx, urlPath, body, target, headers)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HTTPClient_PutJSON_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PutJSON'
+type HTTPClient_PutJSON_Call struct {
+	*mock.Call
+}
+
+// PutJSON is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlPath string
+//   - body interface{}
+//   - target interface{}
+//   - headers map[string]string
+func (_e *HTTPClient_Expecter) PutJSON(ctx interface{}, urlPath interface{}, body interface{}, target interface{}, headers interface{}) *HTTPClient_PutJSON_Call {
+	return &HTTPClient_PutJSON_Call{Call: _e.mock.On("PutJSON", ctx, urlPath, body, target, headers)}
+}
+
+func (_c *HTTPClient_PutJSON_Call) Run(run func(ctx context.Context, urlPath string, body interface{}, target interface{}, headers map[string]string)) *HTTPClient_PutJSON_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(interface{}), args[3].(interface{}), args[4].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *HTTPClient_PutJSON_Call) Return(_a0 error) *HTTPClient_PutJSON_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *HTTPClient_PutJSON_Call) RunAndReturn(run func(context.Context, string, interface{}, interface{}, map[string]string) error) *HTTPClient_PutJSON_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Request provides a mock function with given fields: ctx, method, urlPath, body, opts
+func (_m *HTTPClient) Request(ctx context.Context, method string, urlPath string, body interface{}, opts ...httpclient.RequestOption) (*httpclient.Response, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, method, urlPath, body)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Request")
+	}
+
+	var r0 *httpclient.Response
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}, ...httpclient.RequestOption) (*httpclient.Response, error)); ok {
+		return rf(ctx, method, urlPath, body, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}, ...httpclient.RequestOption) *httpclient.Response); ok {
+		r0 = rf(ctx, method, urlPath, body, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*httpclient.Response)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, interface{}, ...httpclient.RequestOption) error); ok {
+		r1 = rf(ctx, method, urlPath, body, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HTTPClient_Request_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Request'
+type HTTPClient_Request_Call struct {
+	*mock.Call
+}
+
+// Request is a helper method to define mock.On call
+//   - ctx context.Context
+//   - method string
+//   - urlPath string
+//   - body interface{}
+//   - opts ...httpclient.RequestOption
+func (_e *HTTPClient_Expecter) Request(ctx interface{}, method interface{}, urlPath interface{}, body interface{}, opts ...interface{}) *HTTPClient_Request_Call {
+	return &HTTPClient_Request_Call{Call: _e.mock.On("Request",
+		append([]interface{}{ctx, method, urlPath, body}, opts...)...)}
+}
+
+func (_c *HTTPClient_Request_Call) Run(run func(ctx context.Context, method string, urlPath string, body interface{}, opts ...httpclient.RequestOption)) *HTTPClient_Request_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]httpclient.RequestOption, len(args)-4)
+		for i, a := range args[4:] {
+			if a != nil {
+				variadicArgs[i] = a.(httpclient.RequestOption)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(interface{}), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *HTTPClient_Request_Call) Return(_a0 *httpclient.Response, _a1 error) *HTTPClient_Request_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HTTPClient_Request_Call) RunAndReturn(run func(context.Context, string, string, interface{}, ...httpclient.RequestOption) (*httpclient.Response, error)) *HTTPClient_Request_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewHTTPClient creates a new instance of HTTPClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHTTPClient(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *HTTPClient {
+	mock := &HTTPClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,11 @@
+// Package mocks provides testify mocks of the application's interfaces, generated by mockery
+//
+// Expectations can be set with On or the typed expecter:
+//
+//	repo := mocks.NewUserRepository(t)
+//	repo.EXPECT().GetByID(mock.Anything, "user-1").Return(user, nil)
+//
+// Constructors register AssertExpectations as a test cleanup.
+package mocks
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.5 --config .mockery.yaml
@@ -0,0 +1,490 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	domain "quizizz.com/internal/domain"
+
+	repository "quizizz.com/internal/repository"
+)
+
+// NotificationService is an autogenerated mock type for the NotificationService type
+type NotificationService struct {
+	mock.Mock
+}
+
+type NotificationService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *NotificationService) EXPECT() *NotificationService_Expecter {
+	return &NotificationService_Expecter{mock: &_m.Mock}
+}
+
+// ListDevices provides a mock function with given fields: ctx, userID
+func (_m *NotificationService) ListDevices(ctx context.Context, userID string) ([]*domain.Device, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDevices")
+	}
+
+	var r0 []*domain.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*domain.Device, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*domain.Device); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NotificationService_ListDevices_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDevices'
+type NotificationService_ListDevices_Call struct {
+	*mock.Call
+}
+
+// ListDevices is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *NotificationService_Expecter) ListDevices(ctx interface{}, userID interface{}) *NotificationService_ListDevices_Call {
+	return &NotificationService_ListDevices_Call{Call: _e.mock.On("ListDevices", ctx, userID)}
+}
+
+func (_c *NotificationService_ListDevices_Call) Run(run func(ctx context.Context, userID string)) *NotificationService_ListDevices_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *NotificationService_ListDevices_Call) Return(_a0 []*domain.Device, _a1 error) *NotificationService_ListDevices_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *NotificationService_ListDevices_Call) RunAndReturn(run func(context.Context, string) ([]*domain.Device, error)) *NotificationService_ListDevices_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NotifyTopic provides a mock function with given fields: ctx, topic, notification
+func (_m *NotificationService) NotifyTopic(ctx context.Context, topic string, notification *domain.Notification) (*repository.Job, error) {
+	ret := _m.Called(ctx, topic, notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NotifyTopic")
+	}
+
+	var r0 *repository.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Notification) (*repository.Job, error)); ok {
+		return rf(ctx, topic, notification)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Notification) *repository.Job); ok {
+		r0 = rf(ctx, topic, notification)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.Notification) error); ok {
+		r1 = rf(ctx, topic, notification)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NotificationService_NotifyTopic_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NotifyTopic'
+type NotificationService_NotifyTopic_Call struct {
+	*mock.Call
+}
+
+// NotifyTopic is a helper method to define mock.On call
+//   - ctx context.Context
+//   - topic string
+//   - notification *domain.Notification
+func (_e *NotificationService_Expecter) NotifyTopic(ctx interface{}, topic interface{}, notification interface{}) *NotificationService_NotifyTopic_Call {
+	return &NotificationService_NotifyTopic_Call{Call: _e.mock.On("NotifyTopic", ctx, topic, notification)}
+}
+
+func (_c *NotificationService_NotifyTopic_Call) Run(run func(ctx context.Context, topic string, notification *domain.Notification)) *NotificationService_NotifyTopic_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*domain.Notification))
+	})
+	return _c
+}
+
+func (_c *NotificationService_NotifyTopic_Call) Return(_a0 *repository.Job, _a1 error) *NotificationService_NotifyTopic_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *NotificationService_NotifyTopic_Call) RunAndReturn(run func(context.Context, string, *domain.Notification) (*repository.Job, error)) *NotificationService_NotifyTopic_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NotifyUser provides a mock function with given fields: ctx, userID, notification
+func (_m *NotificationService) NotifyUser(ctx context.Context, userID string, notification *domain.Notification) (*repository.Job, error) {
+	ret := _m.Called(ctx, userID, notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NotifyUser")
+	}
+
+	var r0 *repository.Job
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Notification) (*repository.Job, error)); ok {
+		return rf(ctx, userID, notification)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Notification) *repository.Job); ok {
+		r0 = rf(ctx, userID, notification)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.Notification) error); ok {
+		r1 = rf(ctx, userID, notification)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NotificationService_NotifyUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NotifyUser'
+type NotificationService_NotifyUser_Call struct {
+	*mock.Call
+}
+
+// NotifyUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - notification *domain.Notification
+func (_e *NotificationService_Expecter) NotifyUser(ctx interface{}, userID interface{}, notification interface{}) *NotificationService_NotifyUser_Call {
+	return &NotificationService_NotifyUser_Call{Call: _e.mock.On("NotifyUser", ctx, userID, notification)}
+}
+
+func (_c *NotificationService_NotifyUser_Call) Run(run func(ctx context.Context, userID string, notification *domain.Notification)) *NotificationService_NotifyUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*domain.Notification))
+	})
+	return _c
+}
+
+func (_c *NotificationService_NotifyUser_Call) Return(_a0 *repository.Job, _a1 error) *NotificationService_NotifyUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *NotificationService_NotifyUser_Call) RunAndReturn(run func(context.Context, string, *domain.Notification) (*repository.Job, error)) *NotificationService_NotifyUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegisterDevice provides a mock function with given fields: ctx, userID, device
+func (_m *NotificationService) RegisterDevice(ctx context.Context, userID string, device *domain.Device) (bool, error) {
+	ret := _m.Called(ctx, userID, device)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterDevice")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Device) (bool, error)); ok {
+		return rf(ctx, userID, device)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Device) bool); ok {
+		r0 = rf(ctx, userID, device)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.Device) error); ok {
+		r1 = rf(ctx, userID, device)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NotificationService_RegisterDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterDevice'
+type NotificationService_RegisterDevice_Call struct {
+	*mock.Call
+}
+
+// RegisterDevice is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - device *domain.Device
+func (_e *NotificationService_Expecter) RegisterDevice(ctx interface{}, userID interface{}, device interface{}) *NotificationService_RegisterDevice_Call {
+	return &NotificationService_RegisterDevice_Call{Call: _e.mock.On("RegisterDevice", ctx, userID, device)}
+}
+
+func (_c *NotificationService_RegisterDevice_Call) Run(run func(ctx context.Context, userID string, device *domain.Device)) *NotificationService_RegisterDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*domain.Device))
+	})
+	return _c
+}
+
+func (_c *NotificationService_RegisterDevice_Call) Return(_a0 bool, _a1 error) *NotificationService_RegisterDevice_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *NotificationService_RegisterDevice_Call) RunAndReturn(run func(context.Context, string, *domain.Device) (bool, error)) *NotificationService_RegisterDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Status provides a mock function with given fields: ctx, notificationID
+func (_m *NotificationService) Status(ctx context.Context, notificationID string) (*repository.Job, map[string]int64, error) {
+	ret := _m.Called(ctx, notificationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 *repository.Job
+	var r1 map[string]int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.Job, map[string]int64, error)); ok {
+		return rf(ctx, notificationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.Job); ok {
+		r0 = rf(ctx, notificationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Job)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) map[string]int64); ok {
+		r1 = rf(ctx, notificationID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(map[string]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, notificationID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NotificationService_Status_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Status'
+type NotificationService_Status_Call struct {
+	*mock.Call
+}
+
+// Status is a helper method to define mock.On call
+//   - ctx context.Context
+//   - notificationID string
+func (_e *NotificationService_Expecter) Status(ctx interface{}, notificationID interface{}) *NotificationService_Status_Call {
+	return &NotificationService_Status_Call{Call: _e.mock.On("Status", ctx, notificationID)}
+}
+
+func (_c *NotificationService_Status_Call) Run(run func(ctx context.Context, notificationID string)) *NotificationService_Status_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *NotificationService_Status_Call) Return(_a0 *repository.Job, _a1 map[string]int64, _a2 error) *NotificationService_Status_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *NotificationService_Status_Call) RunAndReturn(run func(context.Context, string) (*repository.Job, map[string]int64, error)) *NotificationService_Status_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Subscribe provides a mock function with given fields: ctx, userID, deviceID, topic
+func (_m *NotificationService) Subscribe(ctx context.Context, userID string, deviceID string, topic string) error {
+	ret := _m.Called(ctx, userID, deviceID, topic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, deviceID, topic)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NotificationService_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type NotificationService_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - deviceID string
+//   - topic string
+func (_e *NotificationService_Expecter) Subscribe(ctx interface{}, userID interface{}, deviceID interface{}, topic interface{}) *NotificationService_Subscribe_Call {
+	return &NotificationService_Subscribe_Call{Call: _e.mock.On("Subscribe", ctx, userID, deviceID, topic)}
+}
+
+func (_c *NotificationService_Subscribe_Call) Run(run func(ctx context.Context, userID string, deviceID string, topic string)) *NotificationService_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *NotificationService_Subscribe_Call) Return(_a0 error) *NotificationService_Subscribe_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *NotificationService_Subscribe_Call) RunAndReturn(run func(context.Context, string, string, string) error) *NotificationService_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnregisterDevice provides a mock function with given fields: ctx, userID, deviceID
+func (_m *NotificationService) UnregisterDevice(ctx context.Context, userID string, deviceID string) error {
+	ret := _m.Called(ctx, userID, deviceID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnregisterDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, deviceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NotificationService_UnregisterDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnregisterDevice'
+type NotificationService_UnregisterDevice_Call struct {
+	*mock.Call
+}
+
+// UnregisterDevice is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - deviceID string
+func (_e *NotificationService_Expecter) UnregisterDevice(ctx interface{}, userID interface{}, deviceID interface{}) *NotificationService_UnregisterDevice_Call {
+	return &NotificationService_UnregisterDevice_Call{Call: _e.mock.On("UnregisterDevice", ctx, userID, deviceID)}
+}
+
+func (_c *NotificationService_UnregisterDevice_Call) Run(run func(ctx context.Context, userID string, deviceID string)) *NotificationService_UnregisterDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *NotificationService_UnregisterDevice_Call) Return(_a0 error) *NotificationService_UnregisterDevice_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *NotificationService_UnregisterDevice_Call) RunAndReturn(run func(context.Context, string, string) error) *NotificationService_UnregisterDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unsubscribe provides a mock function with given fields: ctx, userID, deviceID, topic
+func (_m *NotificationService) Unsubscribe(ctx context.Context, userID string, deviceID string, topic string) error {
+	ret := _m.Called(ctx, userID, deviceID, topic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unsubscribe")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, deviceID, topic)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NotificationService_Unsubscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unsubscribe'
+type NotificationService_Unsubscribe_Call struct {
+	*mock.Call
+}
+
+// Unsubscribe is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - deviceID string
+//   - topic string
+func (_e *NotificationService_Expecter) Unsubscribe(ctx interface{}, userID interface{}, deviceID interface{}, topic interface{}) *NotificationService_Unsubscribe_Call {
+	return &NotificationService_Unsubscribe_Call{Call: _e.mock.On("Unsubscribe", ctx, userID, deviceID, topic)}
+}
+
+func (_c *NotificationService_Unsubscribe_Call) Run(run func(ctx context.Context, userID string, deviceID string, topic string)) *NotificationService_Unsubscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *NotificationService_Unsubscribe_Call) Return(_a0 error) *NotificationService_Unsubscribe_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *NotificationService_Unsubscribe_Call) RunAndReturn(run func(context.Context, string, string, string) error) *NotificationService_Unsubscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewNotificationService creates a new instance of NotificationService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNotificationService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NotificationService {
+	mock := &NotificationService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
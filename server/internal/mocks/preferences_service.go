@@ -0,0 +1,157 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	domain "quizizz.com/internal/domain"
+)
+
+// PreferencesService is an autogenerated mock type for the PreferencesService type
+type PreferencesService struct {
+	mock.Mock
+}
+
+type PreferencesService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *PreferencesService) EXPECT() *PreferencesService_Expecter {
+	return &PreferencesService_Expecter{mock: &_m.Mock}
+}
+
+// Get provides a mock function with given fields: ctx, userID
+func (_m *PreferencesService) Get(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *domain.UserPreferences
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.UserPreferences, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.UserPreferences); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserPreferences)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreferencesService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type PreferencesService_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *PreferencesService_Expecter) Get(ctx interface{}, userID interface{}) *PreferencesService_Get_Call {
+	return &PreferencesService_Get_Call{Call: _e.mock.On("Get", ctx, userID)}
+}
+
+func (_c *PreferencesService_Get_Call) Run(run func(ctx context.Context, userID string)) *PreferencesService_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PreferencesService_Get_Call) Return(_a0 *domain.UserPreferences, _a1 error) *PreferencesService_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PreferencesService_Get_Call) RunAndReturn(run func(context.Context, string) (*domain.UserPreferences, error)) *PreferencesService_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, userID, settings, expectedVersion
+func (_m *PreferencesService) Update(ctx context.Context, userID string, settings domain.PreferenceSettings, expectedVersion *int64) (*domain.UserPreferences, error) {
+	ret := _m.Called(ctx, userID, settings, expectedVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *domain.UserPreferences
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.PreferenceSettings, *int64) (*domain.UserPreferences, error)); ok {
+		return rf(ctx, userID, settings, expectedVersion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.PreferenceSettings, *int64) *domain.UserPreferences); ok {
+		r0 = rf(ctx, userID, settings, expectedVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserPreferences)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.PreferenceSettings, *int64) error); ok {
+		r1 = rf(ctx, userID, settings, expectedVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreferencesService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type PreferencesService_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - settings domain.PreferenceSettings
+//   - expectedVersion *int64
+func (_e *PreferencesService_Expecter) Update(ctx interface{}, userID interface{}, settings interface{}, expectedVersion interface{}) *PreferencesService_Update_Call {
+	return &PreferencesService_Update_Call{Call: _e.mock.On("Update", ctx, userID, settings, expectedVersion)}
+}
+
+func (_c *PreferencesService_Update_Call) Run(run func(ctx context.Context, userID string, settings domain.PreferenceSettings, expectedVersion *int64)) *PreferencesService_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(domain.PreferenceSettings), args[3].(*int64))
+	})
+	return _c
+}
+
+func (_c *PreferencesService_Update_Call) Return(_a0 *domain.UserPreferences, _a1 error) *PreferencesService_Update_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PreferencesService_Update_Call) RunAndReturn(run func(context.Context, string, domain.PreferenceSettings, *int64) (*domain.UserPreferences, error)) *PreferencesService_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewPreferencesService creates a new instance of PreferencesService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPreferencesService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PreferencesService {
+	mock := &PreferencesService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
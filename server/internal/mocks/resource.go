@@ -0,0 +1,219 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Resource is an autogenerated mock type for the Resource type
+type Resource struct {
+	mock.Mock
+}
+
+type Resource_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Resource) EXPECT() *Resource_Expecter {
+	return &Resource_Expecter{mock: &_m.Mock}
+}
+
+// Close provides a mock function with given fields: ctx
+func (_m *Resource) Close(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Resource_Close_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Close'
+type Resource_Close_Call struct {
+	*mock.Call
+}
+
+// Close is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Resource_Expecter) Close(ctx interface{}) *Resource_Close_Call {
+	return &Resource_Close_Call{Call: _e.mock.On("Close", ctx)}
+}
+
+func (_c *Resource_Close_Call) Run(run func(ctx context.Context)) *Resource_Close_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Resource_Close_Call) Return(_a0 error) *Resource_Close_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Resource_Close_Call) RunAndReturn(run func(context.Context) error) *Resource_Close_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Connect provides a mock function with given fields: ctx
+func (_m *Resource) Connect(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Connect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Resource_Connect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Connect'
+type Resource_Connect_Call struct {
+	*mock.Call
+}
+
+// Connect is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Resource_Expecter) Connect(ctx interface{}) *Resource_Connect_Call {
+	return &Resource_Connect_Call{Call: _e.mock.On("Connect", ctx)}
+}
+
+func (_c *Resource_Connect_Call) Run(run func(ctx context.Context)) *Resource_Connect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Resource_Connect_Call) Return(_a0 error) *Resource_Connect_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Resource_Connect_Call) RunAndReturn(run func(context.Context) error) *Resource_Connect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Name provides a mock function with no fields
+func (_m *Resource) Name() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Resource_Name_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Name'
+type Resource_Name_Call struct {
+	*mock.Call
+}
+
+// Name is a helper method to define mock.On call
+func (_e *Resource_Expecter) Name() *Resource_Name_Call {
+	return &Resource_Name_Call{Call: _e.mock.On("Name")}
+}
+
+func (_c *Resource_Name_Call) Run(run func()) *Resource_Name_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Resource_Name_Call) Return(_a0 string) *Resource_Name_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Resource_Name_Call) RunAndReturn(run func() string) *Resource_Name_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Ping provides a mock function with given fields: ctx
+func (_m *Resource) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Resource_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type Resource_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Resource_Expecter) Ping(ctx interface{}) *Resource_Ping_Call {
+	return &Resource_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *Resource_Ping_Call) Run(run func(ctx context.Context)) *Resource_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Resource_Ping_Call) Return(_a0 error) *Resource_Ping_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Resource_Ping_Call) RunAndReturn(run func(context.Context) error) *Resource_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewResource creates a new instance of Resource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewResource(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Resource {
+	mock := &Resource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
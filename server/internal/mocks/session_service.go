@@ -0,0 +1,336 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	repository "quizizz.com/internal/repository"
+)
+
+// SessionService is an autogenerated mock type for the SessionService type
+type SessionService struct {
+	mock.Mock
+}
+
+type SessionService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *SessionService) EXPECT() *SessionService_Expecter {
+	return &SessionService_Expecter{mock: &_m.Mock}
+}
+
+// Issue provides a mock function with given fields: ctx, userID, device, userAgent
+func (_m *SessionService) Issue(ctx context.Context, userID string, device string, userAgent string) (*repository.Session, string, error) {
+	ret := _m.Called(ctx, userID, device, userAgent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Issue")
+	}
+
+	var r0 *repository.Session
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*repository.Session, string, error)); ok {
+		return rf(ctx, userID, device, userAgent)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *repository.Session); ok {
+		r0 = rf(ctx, userID, device, userAgent)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) string); ok {
+		r1 = rf(ctx, userID, device, userAgent)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string) error); ok {
+		r2 = rf(ctx, userID, device, userAgent)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SessionService_Issue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Issue'
+type SessionService_Issue_Call struct {
+	*mock.Call
+}
+
+// Issue is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - device string
+//   - userAgent string
+func (_e *SessionService_Expecter) Issue(ctx interface{}, userID interface{}, device interface{}, userAgent interface{}) *SessionService_Issue_Call {
+	return &SessionService_Issue_Call{Call: _e.mock.On("Issue", ctx, userID, device, userAgent)}
+}
+
+func (_c *SessionService_Issue_Call) Run(run func(ctx context.Context, userID string, device string, userAgent string)) *SessionService_Issue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *SessionService_Issue_Call) Return(_a0 *repository.Session, _a1 string, _a2 error) *SessionService_Issue_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *SessionService_Issue_Call) RunAndReturn(run func(context.Context, string, string, string) (*repository.Session, string, error)) *SessionService_Issue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, userID
+func (_m *SessionService) List(ctx context.Context, userID string) ([]*repository.Session, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*repository.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*repository.Session, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*repository.Session); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type SessionService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *SessionService_Expecter) List(ctx interface{}, userID interface{}) *SessionService_List_Call {
+	return &SessionService_List_Call{Call: _e.mock.On("List", ctx, userID)}
+}
+
+func (_c *SessionService_List_Call) Run(run func(ctx context.Context, userID string)) *SessionService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *SessionService_List_Call) Return(_a0 []*repository.Session, _a1 error) *SessionService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *SessionService_List_Call) RunAndReturn(run func(context.Context, string) ([]*repository.Session, error)) *SessionService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Refresh provides a mock function with given fields: ctx, token, userAgent
+func (_m *SessionService) Refresh(ctx context.Context, token string, userAgent string) (*repository.Session, string, error) {
+	ret := _m.Called(ctx, token, userAgent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *repository.Session
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*repository.Session, string, error)); ok {
+		return rf(ctx, token, userAgent)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *repository.Session); ok {
+		r0 = rf(ctx, token, userAgent)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) string); ok {
+		r1 = rf(ctx, token, userAgent)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, token, userAgent)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SessionService_Refresh_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refresh'
+type SessionService_Refresh_Call struct {
+	*mock.Call
+}
+
+// Refresh is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+//   - userAgent string
+func (_e *SessionService_Expecter) Refresh(ctx interface{}, token interface{}, userAgent interface{}) *SessionService_Refresh_Call {
+	return &SessionService_Refresh_Call{Call: _e.mock.On("Refresh", ctx, token, userAgent)}
+}
+
+func (_c *SessionService_Refresh_Call) Run(run func(ctx context.Context, token string, userAgent string)) *SessionService_Refresh_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *SessionService_Refresh_Call) Return(_a0 *repository.Session, _a1 string, _a2 error) *SessionService_Refresh_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *SessionService_Refresh_Call) RunAndReturn(run func(context.Context, string, string) (*repository.Session, string, error)) *SessionService_Refresh_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function with given fields: ctx, userID, sessionID
+func (_m *SessionService) Revoke(ctx context.Context, userID string, sessionID string) error {
+	ret := _m.Called(ctx, userID, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionService_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type SessionService_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+//   - sessionID string
+func (_e *SessionService_Expecter) Revoke(ctx interface{}, userID interface{}, sessionID interface{}) *SessionService_Revoke_Call {
+	return &SessionService_Revoke_Call{Call: _e.mock.On("Revoke", ctx, userID, sessionID)}
+}
+
+func (_c *SessionService_Revoke_Call) Run(run func(ctx context.Context, userID string, sessionID string)) *SessionService_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *SessionService_Revoke_Call) Return(_a0 error) *SessionService_Revoke_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SessionService_Revoke_Call) RunAndReturn(run func(context.Context, string, string) error) *SessionService_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAll provides a mock function with given fields: ctx, userID
+func (_m *SessionService) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAll")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionService_RevokeAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAll'
+type SessionService_RevokeAll_Call struct {
+	*mock.Call
+}
+
+// RevokeAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *SessionService_Expecter) RevokeAll(ctx interface{}, userID interface{}) *SessionService_RevokeAll_Call {
+	return &SessionService_RevokeAll_Call{Call: _e.mock.On("RevokeAll", ctx, userID)}
+}
+
+func (_c *SessionService_RevokeAll_Call) Run(run func(ctx context.Context, userID string)) *SessionService_RevokeAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *SessionService_RevokeAll_Call) Return(_a0 int64, _a1 error) *SessionService_RevokeAll_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *SessionService_RevokeAll_Call) RunAndReturn(run func(context.Context, string) (int64, error)) *SessionService_RevokeAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSessionService creates a new instance of SessionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionService {
+	mock := &SessionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
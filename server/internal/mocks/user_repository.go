@@ -0,0 +1,703 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	domain "quizizz.com/internal/domain"
+
+	time "time"
+)
+
+// UserRepository is an autogenerated mock type for the UserRepository type
+type UserRepository struct {
+	mock.Mock
+}
+
+type UserRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *UserRepository) EXPECT() *UserRepository_Expecter {
+	return &UserRepository_Expecter{mock: &_m.Mock}
+}
+
+// Count provides a mock function with given fields: ctx, filter
+func (_m *UserRepository) Count(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Count")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter) (int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter) int64); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_Count_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Count'
+type UserRepository_Count_Call struct {
+	*mock.Call
+}
+
+// Count is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.UserFilter
+func (_e *UserRepository_Expecter) Count(ctx interface{}, filter interface{}) *UserRepository_Count_Call {
+	return &UserRepository_Count_Call{Call: _e.mock.On("Count", ctx, filter)}
+}
+
+func (_c *UserRepository_Count_Call) Run(run func(ctx context.Context, filter domain.UserFilter)) *UserRepository_Count_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserFilter))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Count_Call) Return(_a0 int64, _a1 error) *UserRepository_Count_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_Count_Call) RunAndReturn(run func(context.Context, domain.UserFilter) (int64, error)) *UserRepository_Count_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: ctx, user
+func (_m *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type UserRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user *domain.User
+func (_e *UserRepository_Expecter) Create(ctx interface{}, user interface{}) *UserRepository_Create_Call {
+	return &UserRepository_Create_Call{Call: _e.mock.On("Create", ctx, user)}
+}
+
+func (_c *UserRepository_Create_Call) Run(run func(ctx context.Context, user *domain.User)) *UserRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.User))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Create_Call) Return(_a0 error) *UserRepository_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_Create_Call) RunAndReturn(run func(context.Context, *domain.User) error) *UserRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *UserRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type UserRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *UserRepository_Expecter) Delete(ctx interface{}, id interface{}) *UserRepository_Delete_Call {
+	return &UserRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *UserRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *UserRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Delete_Call) Return(_a0 error) *UserRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *UserRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function with given fields: ctx, id, fields
+func (_m *UserRepository) GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (*domain.User, error)); ok {
+		return rf(ctx, id, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) *domain.User); ok {
+		r0 = rf(ctx, id, fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, id, fields...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type UserRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - fields ...string
+func (_e *UserRepository_Expecter) GetByID(ctx interface{}, id interface{}, fields ...interface{}) *UserRepository_GetByID_Call {
+	return &UserRepository_GetByID_Call{Call: _e.mock.On("GetByID",
+		append([]interface{}{ctx, id}, fields...)...)}
+}
+
+func (_c *UserRepository_GetByID_Call) Run(run func(ctx context.Context, id string, fields ...string)) *UserRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetByID_Call) Return(_a0 *domain.User, _a1 error) *UserRepository_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetByID_Call) RunAndReturn(run func(context.Context, string, ...string) (*domain.User, error)) *UserRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, fields
+func (_m *UserRepository) List(ctx context.Context, fields ...string) ([]*domain.User, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) ([]*domain.User, error)); ok {
+		return rf(ctx, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) []*domain.User); ok {
+		r0 = rf(ctx, fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ...string) error); ok {
+		r1 = rf(ctx, fields...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type UserRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fields ...string
+func (_e *UserRepository_Expecter) List(ctx interface{}, fields ...interface{}) *UserRepository_List_Call {
+	return &UserRepository_List_Call{Call: _e.mock.On("List",
+		append([]interface{}{ctx}, fields...)...)}
+}
+
+func (_c *UserRepository_List_Call) Run(run func(ctx context.Context, fields ...string)) *UserRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *UserRepository_List_Call) Return(_a0 []*domain.User, _a1 error) *UserRepository_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_List_Call) RunAndReturn(run func(context.Context, ...string) ([]*domain.User, error)) *UserRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function with given fields: ctx, filter, opts
+func (_m *UserRepository) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	ret := _m.Called(ctx, filter, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*domain.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, domain.ListOptions) ([]*domain.User, int64, error)); ok {
+		return rf(ctx, filter, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, domain.ListOptions) []*domain.User); ok {
+		r0 = rf(ctx, filter, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserFilter, domain.ListOptions) int64); ok {
+		r1 = rf(ctx, filter, opts)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.UserFilter, domain.ListOptions) error); ok {
+		r2 = rf(ctx, filter, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserRepository_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type UserRepository_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.UserFilter
+//   - opts domain.ListOptions
+func (_e *UserRepository_Expecter) Search(ctx interface{}, filter interface{}, opts interface{}) *UserRepository_Search_Call {
+	return &UserRepository_Search_Call{Call: _e.mock.On("Search", ctx, filter, opts)}
+}
+
+func (_c *UserRepository_Search_Call) Run(run func(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions)) *UserRepository_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserFilter), args[2].(domain.ListOptions))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Search_Call) Return(_a0 []*domain.User, _a1 int64, _a2 error) *UserRepository_Search_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserRepository_Search_Call) RunAndReturn(run func(context.Context, domain.UserFilter, domain.ListOptions) ([]*domain.User, int64, error)) *UserRepository_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAvatar provides a mock function with given fields: ctx, id, avatar
+func (_m *UserRepository) SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error {
+	ret := _m.Called(ctx, id, avatar)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAvatar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Avatar) error); ok {
+		r0 = rf(ctx, id, avatar)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_SetAvatar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAvatar'
+type UserRepository_SetAvatar_Call struct {
+	*mock.Call
+}
+
+// SetAvatar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - avatar *domain.Avatar
+func (_e *UserRepository_Expecter) SetAvatar(ctx interface{}, id interface{}, avatar interface{}) *UserRepository_SetAvatar_Call {
+	return &UserRepository_SetAvatar_Call{Call: _e.mock.On("SetAvatar", ctx, id, avatar)}
+}
+
+func (_c *UserRepository_SetAvatar_Call) Run(run func(ctx context.Context, id string, avatar *domain.Avatar)) *UserRepository_SetAvatar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*domain.Avatar))
+	})
+	return _c
+}
+
+func (_c *UserRepository_SetAvatar_Call) Return(_a0 error) *UserRepository_SetAvatar_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_SetAvatar_Call) RunAndReturn(run func(context.Context, string, *domain.Avatar) error) *UserRepository_SetAvatar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLocked provides a mock function with given fields: ctx, id, locked
+func (_m *UserRepository) SetLocked(ctx context.Context, id string, locked bool) error {
+	ret := _m.Called(ctx, id, locked)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLocked")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, id, locked)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_SetLocked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLocked'
+type UserRepository_SetLocked_Call struct {
+	*mock.Call
+}
+
+// SetLocked is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - locked bool
+func (_e *UserRepository_Expecter) SetLocked(ctx interface{}, id interface{}, locked interface{}) *UserRepository_SetLocked_Call {
+	return &UserRepository_SetLocked_Call{Call: _e.mock.On("SetLocked", ctx, id, locked)}
+}
+
+func (_c *UserRepository_SetLocked_Call) Run(run func(ctx context.Context, id string, locked bool)) *UserRepository_SetLocked_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *UserRepository_SetLocked_Call) Return(_a0 error) *UserRepository_SetLocked_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_SetLocked_Call) RunAndReturn(run func(context.Context, string, bool) error) *UserRepository_SetLocked_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPasswordResetRequired provides a mock function with given fields: ctx, id, required
+func (_m *UserRepository) SetPasswordResetRequired(ctx context.Context, id string, required bool) error {
+	ret := _m.Called(ctx, id, required)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPasswordResetRequired")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, id, required)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_SetPasswordResetRequired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPasswordResetRequired'
+type UserRepository_SetPasswordResetRequired_Call struct {
+	*mock.Call
+}
+
+// SetPasswordResetRequired is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - required bool
+func (_e *UserRepository_Expecter) SetPasswordResetRequired(ctx interface{}, id interface{}, required interface{}) *UserRepository_SetPasswordResetRequired_Call {
+	return &UserRepository_SetPasswordResetRequired_Call{Call: _e.mock.On("SetPasswordResetRequired", ctx, id, required)}
+}
+
+func (_c *UserRepository_SetPasswordResetRequired_Call) Run(run func(ctx context.Context, id string, required bool)) *UserRepository_SetPasswordResetRequired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *UserRepository_SetPasswordResetRequired_Call) Return(_a0 error) *UserRepository_SetPasswordResetRequired_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_SetPasswordResetRequired_Call) RunAndReturn(run func(context.Context, string, bool) error) *UserRepository_SetPasswordResetRequired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx, since, recent
+func (_m *UserRepository) Stats(ctx context.Context, since time.Time, recent int) (*domain.UserStats, error) {
+	ret := _m.Called(ctx, since, recent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *domain.UserStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) (*domain.UserStats, error)); ok {
+		return rf(ctx, since, recent)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) *domain.UserStats); ok {
+		r0 = rf(ctx, since, recent)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, since, recent)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type UserRepository_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - since time.Time
+//   - recent int
+func (_e *UserRepository_Expecter) Stats(ctx interface{}, since interface{}, recent interface{}) *UserRepository_Stats_Call {
+	return &UserRepository_Stats_Call{Call: _e.mock.On("Stats", ctx, since, recent)}
+}
+
+func (_c *UserRepository_Stats_Call) Run(run func(ctx context.Context, since time.Time, recent int)) *UserRepository_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Stats_Call) Return(_a0 *domain.UserStats, _a1 error) *UserRepository_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_Stats_Call) RunAndReturn(run func(context.Context, time.Time, int) (*domain.UserStats, error)) *UserRepository_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stream provides a mock function with given fields: ctx, filter, fn
+func (_m *UserRepository) Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stream")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, func(*domain.User) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_Stream_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stream'
+type UserRepository_Stream_Call struct {
+	*mock.Call
+}
+
+// Stream is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.UserFilter
+//   - fn func(*domain.User) error
+func (_e *UserRepository_Expecter) Stream(ctx interface{}, filter interface{}, fn interface{}) *UserRepository_Stream_Call {
+	return &UserRepository_Stream_Call{Call: _e.mock.On("Stream", ctx, filter, fn)}
+}
+
+func (_c *UserRepository_Stream_Call) Run(run func(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error)) *UserRepository_Stream_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserFilter), args[2].(func(*domain.User) error))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Stream_Call) Return(_a0 error) *UserRepository_Stream_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_Stream_Call) RunAndReturn(run func(context.Context, domain.UserFilter, func(*domain.User) error) error) *UserRepository_Stream_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, user
+func (_m *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type UserRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user *domain.User
+func (_e *UserRepository_Expecter) Update(ctx interface{}, user interface{}) *UserRepository_Update_Call {
+	return &UserRepository_Update_Call{Call: _e.mock.On("Update", ctx, user)}
+}
+
+func (_c *UserRepository_Update_Call) Run(run func(ctx context.Context, user *domain.User)) *UserRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.User))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Update_Call) Return(_a0 error) *UserRepository_Update_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_Update_Call) RunAndReturn(run func(context.Context, *domain.User) error) *UserRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserRepository {
+	mock := &UserRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,440 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	domain "quizizz.com/internal/domain"
+)
+
+// UserService is an autogenerated mock type for the UserService type
+type UserService struct {
+	mock.Mock
+}
+
+type UserService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *UserService) EXPECT() *UserService_Expecter {
+	return &UserService_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, user
+func (_m *UserService) Create(ctx context.Context, user *domain.User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserService_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type UserService_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user *domain.User
+func (_e *UserService_Expecter) Create(ctx interface{}, user interface{}) *UserService_Create_Call {
+	return &UserService_Create_Call{Call: _e.mock.On("Create", ctx, user)}
+}
+
+func (_c *UserService_Create_Call) Run(run func(ctx context.Context, user *domain.User)) *UserService_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.User))
+	})
+	return _c
+}
+
+func (_c *UserService_Create_Call) Return(_a0 error) *UserService_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserService_Create_Call) RunAndReturn(run func(context.Context, *domain.User) error) *UserService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *UserService) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type UserService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *UserService_Expecter) Delete(ctx interface{}, id interface{}) *UserService_Delete_Call {
+	return &UserService_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *UserService_Delete_Call) Run(run func(ctx context.Context, id string)) *UserService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_Delete_Call) Return(_a0 error) *UserService_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserService_Delete_Call) RunAndReturn(run func(context.Context, string) error) *UserService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Export provides a mock function with given fields: ctx, filter, fn
+func (_m *UserService) Export(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, func(*domain.User) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserService_Export_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Export'
+type UserService_Export_Call struct {
+	*mock.Call
+}
+
+// Export is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.UserFilter
+//   - fn func(*domain.User) error
+func (_e *UserService_Expecter) Export(ctx interface{}, filter interface{}, fn interface{}) *UserService_Export_Call {
+	return &UserService_Export_Call{Call: _e.mock.On("Export", ctx, filter, fn)}
+}
+
+func (_c *UserService_Export_Call) Run(run func(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error)) *UserService_Export_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserFilter), args[2].(func(*domain.User) error))
+	})
+	return _c
+}
+
+func (_c *UserService_Export_Call) Return(_a0 error) *UserService_Export_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserService_Export_Call) RunAndReturn(run func(context.Context, domain.UserFilter, func(*domain.User) error) error) *UserService_Export_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function with given fields: ctx, id, fields
+func (_m *UserService) GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) (*domain.User, error)); ok {
+		return rf(ctx, id, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) *domain.User); ok {
+		r0 = rf(ctx, id, fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, id, fields...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserService_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type UserService_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - fields ...string
+func (_e *UserService_Expecter) GetByID(ctx interface{}, id interface{}, fields ...interface{}) *UserService_GetByID_Call {
+	return &UserService_GetByID_Call{Call: _e.mock.On("GetByID",
+		append([]interface{}{ctx, id}, fields...)...)}
+}
+
+func (_c *UserService_GetByID_Call) Run(run func(ctx context.Context, id string, fields ...string)) *UserService_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-2)
+		for i, a := range args[2:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *UserService_GetByID_Call) Return(_a0 *domain.User, _a1 error) *UserService_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserService_GetByID_Call) RunAndReturn(run func(context.Context, string, ...string) (*domain.User, error)) *UserService_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, fields
+func (_m *UserService) List(ctx context.Context, fields ...string) ([]*domain.User, error) {
+	_va := make([]interface{}, len(fields))
+	for _i := range fields {
+		_va[_i] = fields[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) ([]*domain.User, error)); ok {
+		return rf(ctx, fields...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) []*domain.User); ok {
+		r0 = rf(ctx, fields...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ...string) error); ok {
+		r1 = rf(ctx, fields...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type UserService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fields ...string
+func (_e *UserService_Expecter) List(ctx interface{}, fields ...interface{}) *UserService_List_Call {
+	return &UserService_List_Call{Call: _e.mock.On("List",
+		append([]interface{}{ctx}, fields...)...)}
+}
+
+func (_c *UserService_List_Call) Run(run func(ctx context.Context, fields ...string)) *UserService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *UserService_List_Call) Return(_a0 []*domain.User, _a1 error) *UserService_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserService_List_Call) RunAndReturn(run func(context.Context, ...string) ([]*domain.User, error)) *UserService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function with given fields: ctx, filter, opts
+func (_m *UserService) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	ret := _m.Called(ctx, filter, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*domain.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, domain.ListOptions) ([]*domain.User, int64, error)); ok {
+		return rf(ctx, filter, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilter, domain.ListOptions) []*domain.User); ok {
+		r0 = rf(ctx, filter, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.UserFilter, domain.ListOptions) int64); ok {
+		r1 = rf(ctx, filter, opts)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.UserFilter, domain.ListOptions) error); ok {
+		r2 = rf(ctx, filter, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserService_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type UserService_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.UserFilter
+//   - opts domain.ListOptions
+func (_e *UserService_Expecter) Search(ctx interface{}, filter interface{}, opts interface{}) *UserService_Search_Call {
+	return &UserService_Search_Call{Call: _e.mock.On("Search", ctx, filter, opts)}
+}
+
+func (_c *UserService_Search_Call) Run(run func(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions)) *UserService_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.UserFilter), args[2].(domain.ListOptions))
+	})
+	return _c
+}
+
+func (_c *UserService_Search_Call) Return(_a0 []*domain.User, _a1 int64, _a2 error) *UserService_Search_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserService_Search_Call) RunAndReturn(run func(context.Context, domain.UserFilter, domain.ListOptions) ([]*domain.User, int64, error)) *UserService_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, user
+func (_m *UserService) Update(ctx context.Context, user *domain.User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserService_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type UserService_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user *domain.User
+func (_e *UserService_Expecter) Update(ctx interface{}, user interface{}) *UserService_Update_Call {
+	return &UserService_Update_Call{Call: _e.mock.On("Update", ctx, user)}
+}
+
+func (_c *UserService_Update_Call) Run(run func(ctx context.Context, user *domain.User)) *UserService_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.User))
+	})
+	return _c
+}
+
+func (_c *UserService_Update_Call) Return(_a0 error) *UserService_Update_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserService_Update_Call) RunAndReturn(run func(context.Context, *domain.User) error) *UserService_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserService {
+	mock := &UserService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
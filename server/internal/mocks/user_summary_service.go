@@ -0,0 +1,164 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+	domain "quizizz.com/internal/domain"
+
+	repository "quizizz.com/internal/repository"
+)
+
+// UserSummaryService is an autogenerated mock type for the UserSummaryService type
+type UserSummaryService struct {
+	mock.Mock
+}
+
+type UserSummaryService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *UserSummaryService) EXPECT() *UserSummaryService_Expecter {
+	return &UserSummaryService_Expecter{mock: &_m.Mock}
+}
+
+// Get provides a mock function with given fields: ctx, userID
+func (_m *UserSummaryService) Get(ctx context.Context, userID string) (*repository.UserSummary, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *repository.UserSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*repository.UserSummary, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *repository.UserSummary); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.UserSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserSummaryService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type UserSummaryService_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID string
+func (_e *UserSummaryService_Expecter) Get(ctx interface{}, userID interface{}) *UserSummaryService_Get_Call {
+	return &UserSummaryService_Get_Call{Call: _e.mock.On("Get", ctx, userID)}
+}
+
+func (_c *UserSummaryService_Get_Call) Run(run func(ctx context.Context, userID string)) *UserSummaryService_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserSummaryService_Get_Call) Return(_a0 *repository.UserSummary, _a1 error) *UserSummaryService_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserSummaryService_Get_Call) RunAndReturn(run func(context.Context, string) (*repository.UserSummary, error)) *UserSummaryService_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, opts
+func (_m *UserSummaryService) List(ctx context.Context, opts domain.ListOptions) ([]*repository.UserSummary, int64, error) {
+	ret := _m.Called(ctx, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*repository.UserSummary
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ListOptions) ([]*repository.UserSummary, int64, error)); ok {
+		return rf(ctx, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ListOptions) []*repository.UserSummary); ok {
+		r0 = rf(ctx, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*repository.UserSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ListOptions) int64); ok {
+		r1 = rf(ctx, opts)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.ListOptions) error); ok {
+		r2 = rf(ctx, opts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserSummaryService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type UserSummaryService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - opts domain.ListOptions
+func (_e *UserSummaryService_Expecter) List(ctx interface{}, opts interface{}) *UserSummaryService_List_Call {
+	return &UserSummaryService_List_Call{Call: _e.mock.On("List", ctx, opts)}
+}
+
+func (_c *UserSummaryService_List_Call) Run(run func(ctx context.Context, opts domain.ListOptions)) *UserSummaryService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.ListOptions))
+	})
+	return _c
+}
+
+func (_c *UserSummaryService_List_Call) Return(_a0 []*repository.UserSummary, _a1 int64, _a2 error) *UserSummaryService_List_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserSummaryService_List_Call) RunAndReturn(run func(context.Context, domain.ListOptions) ([]*repository.UserSummary, int64, error)) *UserSummaryService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewUserSummaryService creates a new instance of UserSummaryService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserSummaryService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserSummaryService {
+	mock := &UserSummaryService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
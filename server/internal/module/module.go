@@ -0,0 +1,110 @@
+// Package module lets feature verticals plug into the application
+//
+// A Module brings its routes, its Wire providers and its lifecycle hooks. Modules are listed once,
+// in internal/modules, which the injectors and the API build from; adding a vertical does not
+// touch api.NewHandler, routes.NewAPI or the wire files.
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Module is a feature vertical of the application
+type Module interface {
+	// Name identifies the module; its routes are mounted under /api/<version>/<Name>
+	Name() string
+
+	// Routes registers the module's routes on r, relative to its mount path
+	Routes(r gin.IRouter)
+
+	// Providers returns the module's Wire providers
+	// Wire cannot call methods, so the module also exports them as its package's ProviderSet,
+	// which internal/modules includes in the injectors.
+	Providers() wire.ProviderSet
+
+	// Start runs before the application serves requests
+	Start(ctx context.Context) error
+
+	// Stop runs at shutdown, while resources are still open
+	Stop(ctx context.Context) error
+}
+
+// MiddlewareProvider is implemented by modules applying middleware to every request, e.g. to check
+// credentials; the application installs it ahead of the routes
+type MiddlewareProvider interface {
+	Middleware() []gin.HandlerFunc
+}
+
+// Base implements the lifecycle hooks of Module as no-ops, for modules with nothing to start
+type Base struct{}
+
+// Start does nothing
+func (Base) Start(ctx context.Context) error { return nil }
+
+// Stop does nothing
+func (Base) Stop(ctx context.Context) error { return nil }
+
+// Registry holds the modules of the application in registration order
+type Registry struct {
+	modules []Module
+}
+
+// NewRegistry creates a Registry of modules
+func NewRegistry(modules ...Module) *Registry {
+	return &Registry{modules: modules}
+}
+
+// Modules returns the registered modules; a nil Registry has none
+func (r *Registry) Modules() []Module {
+	if r == nil {
+		return nil
+	}
+	return r.modules
+}
+
+// Middleware returns the middleware of the modules providing some, in registration order
+func (r *Registry) Middleware() []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	for _, m := range r.Modules() {
+		if provider, ok := m.(MiddlewareProvider); ok {
+			handlers = append(handlers, provider.Middleware()...)
+		}
+	}
+	return handlers
+}
+
+// Start starts the modules in registration order
+// It stops at the first failure, stopping the modules already started.
+func (r *Registry) Start(ctx context.Context) error {
+	for i, m := range r.Modules() {
+		if err := m.Start(ctx); err != nil {
+			stopErr := stopAll(ctx, r.modules[:i])
+			return errors.Join(fmt.Errorf("failed to start module %s: %w", m.Name(), err), stopErr)
+		}
+	}
+	return nil
+}
+
+// Stop stops the modules in reverse registration order, returning their errors together
+func (r *Registry) Stop(ctx context.Context) error {
+	return stopAll(ctx, r.Modules())
+}
+
+// stopAll stops modules in reverse order; a failing module does not keep the others running
+func stopAll(ctx context.Context, modules []Module) error {
+	var errs []error
+	for i := len(modules) - 1; i >= 0; i-- {
+		if err := modules[i].Stop(ctx); err != nil {
+			logger.Error("Failed to stop module", zap.String("module", modules[i].Name()), zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to stop module %s: %w", modules[i].Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
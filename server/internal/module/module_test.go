@@ -0,0 +1,100 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeModule records its lifecycle calls in a shared log
+type fakeModule struct {
+	name     string
+	startErr error
+	stopErr  error
+	log      *[]string
+}
+
+func (m *fakeModule) Name() string                { return m.name }
+func (m *fakeModule) Routes(r gin.IRouter)        {}
+func (m *fakeModule) Providers() wire.ProviderSet { return wire.ProviderSet{} }
+
+func (m *fakeModule) Start(ctx context.Context) error {
+	*m.log = append(*m.log, "start "+m.name)
+	return m.startErr
+}
+
+func (m *fakeModule) Stop(ctx context.Context) error {
+	*m.log = append(*m.log, "stop "+m.name)
+	return m.stopErr
+}
+
+func TestRegistry_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stops in reverse order", func(t *testing.T) {
+		var log []string
+		registry := NewRegistry(&fakeModule{name: "a", log: &log}, &fakeModule{name: "b", log: &log})
+
+		assert.NoError(t, registry.Start(ctx))
+		assert.NoError(t, registry.Stop(ctx))
+		assert.Equal(t, []string{"start a", "start b", "stop b", "stop a"}, log)
+	})
+
+	t.Run("Failed start stops the started modules", func(t *testing.T) {
+		var log []string
+		registry := NewRegistry(
+			&fakeModule{name: "a", log: &log},
+			&fakeModule{name: "b", log: &log, startErr: errors.New("boom")},
+			&fakeModule{name: "c", log: &log},
+		)
+
+		assert.ErrorContains(t, registry.Start(ctx), "failed to start module b: boom")
+		assert.Equal(t, []string{"start a", "start b", "stop a"}, log)
+	})
+
+	t.Run("Failed stop does not keep others running", func(t *testing.T) {
+		var log []string
+		registry := NewRegistry(
+			&fakeModule{name: "a", log: &log},
+			&fakeModule{name: "b", log: &log, stopErr: errors.New("boom")},
+		)
+
+		assert.ErrorContains(t, registry.Stop(ctx), "failed to stop module b: boom")
+		assert.Equal(t, []string{"stop b", "stop a"}, log)
+	})
+
+	t.Run("Nil registry", func(t *testing.T) {
+		var registry *Registry
+		assert.Empty(t, registry.Modules())
+		assert.NoError(t, registry.Start(ctx))
+		assert.NoError(t, registry.Stop(ctx))
+	})
+}
+
+// middlewareModule is a fakeModule applying middleware to every request
+type middlewareModule struct {
+	fakeModule
+	handlers []gin.HandlerFunc
+}
+
+func (m *middlewareModule) Middleware() []gin.HandlerFunc { return m.handlers }
+
+func TestRegistry_Middleware(t *testing.T) {
+	var log []string
+	first := func(c *gin.Context) {}
+	second := func(c *gin.Context) {}
+	registry := NewRegistry(
+		&middlewareModule{fakeModule: fakeModule{name: "a", log: &log}, handlers: []gin.HandlerFunc{first}},
+		&fakeModule{name: "b", log: &log},
+		&middlewareModule{fakeModule: fakeModule{name: "c", log: &log}, handlers: []gin.HandlerFunc{second}},
+	)
+
+	assert.Len(t, registry.Middleware(), 2)
+
+	var empty *Registry
+	assert.Empty(t, empty.Middleware())
+}
@@ -0,0 +1,36 @@
+// Package modules lists the feature modules of the application
+// Register a new vertical by adding its ProviderSet and its module to New.
+package modules
+
+import (
+	"github.com/google/wire"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/handlers/admin"
+	"quizizz.com/internal/api/handlers/csrf"
+	"quizizz.com/internal/api/handlers/lockout"
+	"quizizz.com/internal/api/handlers/ping"
+	"quizizz.com/internal/api/handlers/uploads"
+	"quizizz.com/internal/module"
+)
+
+// ProviderSet provides the module registry and the providers of every module
+var ProviderSet = wire.NewSet(
+	handlers.NewBaseHandler,
+	ping.ProviderSet,
+	csrf.ProviderSet,
+	lockout.ProviderSet,
+	admin.ProviderSet,
+	uploads.ProviderSet,
+	New,
+)
+
+// New creates the registry of the modules, in the order they start
+func New(pingModule *ping.Module, csrfModule *csrf.Module, lockoutModule *lockout.Module, adminModule *admin.Module, uploadsModule *uploads.Module) *module.Registry {
+	return module.NewRegistry(
+		pingModule,
+		csrfModule,
+		lockoutModule,
+		adminModule,
+		uploadsModule,
+	)
+}
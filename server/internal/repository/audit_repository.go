@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clientip"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/correlation"
+)
+
+// AuditRecord is an entry of the append-only audit log
+type AuditRecord struct {
+	ID string
+
+	// Action names what happened, e.g. gdpr.erasure_requested
+	Action string
+
+	// Subject is the ID of the entity the action applies to, e.g. a user ID
+	Subject string
+
+	// Details holds action-specific data
+	Details map[string]interface{}
+
+	// ClientIP is the IP of the client whose request caused the action; empty for background work
+	ClientIP string
+
+	// UserID is the user whose request caused the action, and ActorID the administrator who made the
+	// request while impersonating them, if any
+	UserID  string
+	ActorID string
+
+	CreatedAt time.Time
+}
+
+// AuditRepository stores audit records; records are never updated, and only removed by retention
+type AuditRepository interface {
+	// Record appends record to the log, assigning its ID and CreatedAt
+	Record(ctx context.Context, record *AuditRecord) error
+
+	// ListBySubject returns the records of subject, oldest first
+	ListBySubject(ctx context.Context, subject string) ([]*AuditRecord, error)
+}
+
+// auditRepositoryImpl is the MongoDB implementation of AuditRepository
+type auditRepositoryImpl struct {
+	*BaseRepository[auditDocument]
+}
+
+// auditDocument represents the MongoDB document structure for audit records
+type auditDocument struct {
+	ID        interface{}            `bson:"_id"`
+	Action    string                 `bson:"action"`
+	Subject   string                 `bson:"subject"`
+	Details   map[string]interface{} `bson:"details,omitempty"`
+	ClientIP  string                 `bson:"clientIp,omitempty"`
+	UserID    string                 `bson:"userId,omitempty"`
+	ActorID   string                 `bson:"actorId,omitempty"`
+	CreatedAt time.Time              `bson:"createdAt"`
+}
+
+// NewAuditRepository creates a new AuditRepository storing records in the audit_log collection with IDs from ids
+func NewAuditRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) AuditRepository {
+	dbInstance := db.(*resources.DB)
+
+	return &auditRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[auditDocument](BaseRepositoryConfig{
+			Collection: dbInstance.Collection("audit_log"),
+			EntityName: "audit record",
+		}, WithClock(clk), WithIDCodec(ids)),
+	}
+}
+
+// Record appends record to the log
+// Records without a client IP, user or actor get those of the request ctx belongs to, if any.
+func (r *auditRepositoryImpl) Record(ctx context.Context, record *AuditRecord) error {
+	id, err := r.EncodeID(r.NewID())
+	if err != nil {
+		return err
+	}
+	record.ID = r.DecodeID(id)
+	record.CreatedAt = r.Now()
+	record.fillFromContext(ctx)
+
+	doc := auditDocument{
+		ID:        id,
+		Action:    record.Action,
+		Subject:   record.Subject,
+		Details:   record.Details,
+		ClientIP:  record.ClientIP,
+		UserID:    record.UserID,
+		ActorID:   record.ActorID,
+		CreatedAt: record.CreatedAt,
+	}
+	_, err = r.InsertOne(ctx, &doc)
+	return err
+}
+
+// ListBySubject returns the records of subject, oldest first
+func (r *auditRepositoryImpl) ListBySubject(ctx context.Context, subject string) ([]*AuditRecord, error) {
+	docs, err := r.Find(ctx, bson.M{"subject": subject}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*AuditRecord, len(docs))
+	for i, doc := range docs {
+		records[i] = &AuditRecord{
+			ID:        r.DecodeID(doc.ID),
+			Action:    doc.Action,
+			Subject:   doc.Subject,
+			Details:   doc.Details,
+			ClientIP:  doc.ClientIP,
+			UserID:    doc.UserID,
+			ActorID:   doc.ActorID,
+			CreatedAt: doc.CreatedAt,
+		}
+	}
+	return records, nil
+}
+
+// fillFromContext sets the client IP, user and actor of record left empty from the request of ctx
+func (record *AuditRecord) fillFromContext(ctx context.Context) {
+	if record.ClientIP == "" {
+		record.ClientIP = clientip.FromContext(ctx)
+	}
+	ids := correlation.FromContext(ctx)
+	if record.UserID == "" {
+		record.UserID = ids.UserID
+	}
+	if record.ActorID == "" && ids.Impersonated() {
+		record.ActorID = ids.ActorID
+	}
+}
@@ -7,22 +7,26 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
 )
 
 // Common repository errors
 var (
-	ErrNotFound      = errors.New("document not found")
-	ErrAlreadyExists = errors.New("document already exists")
-	ErrInvalidID     = errors.New("invalid document ID")
-	ErrInvalidInput  = errors.New("invalid input")
+	ErrNotFound        = errors.New("document not found")
+	ErrAlreadyExists   = errors.New("document already exists")
+	ErrInvalidID       = errors.New("invalid document ID")
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrVersionConflict = errors.New("document version conflict")
 )
 
 // BaseRepository provides common MongoDB operations using generics for type safety
@@ -31,6 +35,44 @@ type BaseRepository[T any] struct {
 	collection *mongo.Collection
 	tracer     trace.Tracer
 	entityName string // For better error messages
+	options    repositoryOptions
+}
+
+// CollectionSyncer is implemented by repositories that declare collection-level settings
+type CollectionSyncer interface {
+	SyncCollection(ctx context.Context) error
+}
+
+// Option declares collection-level behavior for a BaseRepository
+type Option func(*repositoryOptions)
+
+// repositoryOptions holds the collection-level declarations applied by SyncCollection
+type repositoryOptions struct {
+	ttl    *ttlIndex
+	schema *collectionSchema
+	clock  clock.Clock
+	ids    IDCodec
+}
+
+// newRepositoryOptions applies opts in order
+func newRepositoryOptions(opts []Option) repositoryOptions {
+	o := repositoryOptions{clock: clock.New()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ids == nil {
+		o.ids = StringIDCodec(idgen.NewUUIDv7(o.clock))
+	}
+	return o
+}
+
+// WithClock sets the clock stamping updatedAt and other times written by the repository
+func WithClock(clk clock.Clock) Option {
+	return func(o *repositoryOptions) {
+		if clk != nil {
+			o.clock = clk
+		}
+	}
 }
 
 // BaseRepositoryConfig configures a BaseRepository
@@ -40,16 +82,17 @@ type BaseRepositoryConfig struct {
 }
 
 // NewBaseRepository creates a new BaseRepository with generic type
-func NewBaseRepository[T any](collection *mongo.Collection) *BaseRepository[T] {
+func NewBaseRepository[T any](collection *mongo.Collection, opts ...Option) *BaseRepository[T] {
 	return &BaseRepository[T]{
 		collection: collection,
 		tracer:     otel.Tracer("repository"),
 		entityName: collection.Name(),
+		options:    newRepositoryOptions(opts),
 	}
 }
 
 // NewBaseRepositoryWithConfig creates a new BaseRepository with configuration
-func NewBaseRepositoryWithConfig[T any](cfg BaseRepositoryConfig) *BaseRepository[T] {
+func NewBaseRepositoryWithConfig[T any](cfg BaseRepositoryConfig, opts ...Option) *BaseRepository[T] {
 	entityName := cfg.EntityName
 	if entityName == "" {
 		entityName = cfg.Collection.Name()
@@ -59,7 +102,22 @@ func NewBaseRepositoryWithConfig[T any](cfg BaseRepositoryConfig) *BaseRepositor
 		collection: cfg.Collection,
 		tracer:     otel.Tracer("repository"),
 		entityName: entityName,
+		options:    newRepositoryOptions(opts),
+	}
+}
+
+// SyncCollection applies the collection-level declarations (schema validator, TTL index) to the database
+// It is safe to call on every startup
+func (r *BaseRepository[T]) SyncCollection(ctx context.Context) error {
+	if err := r.applySchema(ctx); err != nil {
+		return err
 	}
+	return r.ensureTTLIndex(ctx)
+}
+
+// Now returns the current time of the repository's clock
+func (r *BaseRepository[T]) Now() time.Time {
+	return r.options.clock.Now()
 }
 
 // EntityName returns the entity name for this repository
@@ -67,8 +125,37 @@ func (r *BaseRepository[T]) EntityName() string {
 	return r.entityName
 }
 
+// WithReadPreference returns a copy of the repository whose operations use the given read preference
+// Use it to route heavy list/analytics queries to secondaries without changing the client defaults:
+//
+//	docs, err := r.WithReadPreference(readpref.SecondaryPreferred()).Find(ctx, filter)
+func (r *BaseRepository[T]) WithReadPreference(rp *readpref.ReadPref) *BaseRepository[T] {
+	return r.withCollectionOptions(options.Collection().SetReadPreference(rp))
+}
+
+// WithReadConcern returns a copy of the repository whose operations use the given read concern
+func (r *BaseRepository[T]) WithReadConcern(rc *readconcern.ReadConcern) *BaseRepository[T] {
+	return r.withCollectionOptions(options.Collection().SetReadConcern(rc))
+}
+
+// withCollectionOptions returns a copy of the repository bound to a clone of the collection with opts applied
+func (r *BaseRepository[T]) withCollectionOptions(opts *options.CollectionOptions) *BaseRepository[T] {
+	collection, err := r.collection.Clone(opts)
+	if err != nil {
+		logger.Warn("Failed to clone collection with options, using defaults",
+			zap.String("collection", r.collection.Name()),
+			zap.Error(err),
+		)
+		return r
+	}
+
+	clone := *r
+	clone.collection = collection
+	return &clone
+}
+
 // FindByID finds a document by its ID and returns it
-func (r *BaseRepository[T]) FindByID(ctx context.Context, id string) (*T, error) {
+func (r *BaseRepository[T]) FindByID(ctx context.Context, id string, opts ...*options.FindOneOptions) (*T, error) {
 	ctx, span := r.tracer.Start(ctx, "BaseRepository.FindByID",
 		trace.WithAttributes(
 			attribute.String("collection", r.collection.Name()),
@@ -77,18 +164,14 @@ func (r *BaseRepository[T]) FindByID(ctx context.Context, id string) (*T, error)
 	)
 	defer span.End()
 
-	// Convert string ID to ObjectID if needed
-	var filter bson.M
-	objectID, err := primitive.ObjectIDFromHex(id)
+	// IDs the collection's codec rejects cannot match any document
+	filter, err := r.IDFilter(id)
 	if err != nil {
-		// If not a valid ObjectID, search by string ID
-		filter = bson.M{"_id": id}
-	} else {
-		filter = bson.M{"_id": objectID}
+		return nil, ErrNotFound
 	}
 
 	var result T
-	err = r.collection.FindOne(ctx, filter).Decode(&result)
+	err = r.collection.FindOne(ctx, filter, opts...).Decode(&result)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			span.RecordError(ErrNotFound)
@@ -166,6 +249,90 @@ func (r *BaseRepository[T]) Find(ctx context.Context, filter interface{}, opts .
 	return results, nil
 }
 
+// DefaultStreamBatchSize is the cursor batch size used by FindEach and FindChan when opts don't set one
+const DefaultStreamBatchSize int32 = 500
+
+// FindEach streams documents matching the filter to fn one at a time without loading them all in memory
+// Iteration stops at the first error returned by fn, which is returned as is.
+func (r *BaseRepository[T]) FindEach(ctx context.Context, filter interface{}, fn func(*T) error, opts ...*options.FindOptions) error {
+	ctx, span := r.tracer.Start(ctx, "BaseRepository.FindEach",
+		trace.WithAttributes(
+			attribute.String("collection", r.collection.Name()),
+		),
+	)
+	defer span.End()
+
+	opts = append([]*options.FindOptions{options.Find().SetBatchSize(DefaultStreamBatchSize)}, opts...)
+
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to find documents",
+			zap.String("collection", r.collection.Name()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var count int64
+	for cursor.Next(ctx) {
+		var doc T
+		if err := cursor.Decode(&doc); err != nil {
+			span.RecordError(err)
+			logger.ErrorCtx(ctx, "Failed to decode document",
+				zap.String("collection", r.collection.Name()),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to decode document: %w", err)
+		}
+
+		if err := fn(&doc); err != nil {
+			return err
+		}
+		count++
+	}
+	span.SetAttributes(attribute.Int64("count", count))
+
+	if err := cursor.Err(); err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to iterate documents",
+			zap.String("collection", r.collection.Name()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to iterate documents: %w", err)
+	}
+
+	return nil
+}
+
+// FindChan streams documents matching the filter over a channel
+// The document channel is closed when iteration ends; the error channel then receives at most one error.
+// Cancel ctx to stop early, otherwise the channel must be drained to release the cursor.
+func (r *BaseRepository[T]) FindChan(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (<-chan *T, <-chan error) {
+	docs := make(chan *T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(docs)
+
+		err := r.FindEach(ctx, filter, func(doc *T) error {
+			select {
+			case docs <- doc:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, opts...)
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return docs, errs
+}
+
 // FindAll finds all documents in the collection
 func (r *BaseRepository[T]) FindAll(ctx context.Context, opts ...*options.FindOptions) ([]T, error) {
 	return r.Find(ctx, bson.M{}, opts...)
@@ -194,15 +361,7 @@ func (r *BaseRepository[T]) InsertOne(ctx context.Context, document *T) (string,
 		return "", fmt.Errorf("failed to insert document: %w", err)
 	}
 
-	// Extract the inserted ID
-	var id string
-	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
-		id = oid.Hex()
-	} else {
-		id = fmt.Sprintf("%v", result.InsertedID)
-	}
-
-	return id, nil
+	return r.DecodeID(result.InsertedID), nil
 }
 
 // InsertMany inserts multiple documents
@@ -237,11 +396,7 @@ func (r *BaseRepository[T]) InsertMany(ctx context.Context, documents []*T) ([]s
 	// Extract the inserted IDs
 	ids := make([]string, len(result.InsertedIDs))
 	for i, insertedID := range result.InsertedIDs {
-		if oid, ok := insertedID.(primitive.ObjectID); ok {
-			ids[i] = oid.Hex()
-		} else {
-			ids[i] = fmt.Sprintf("%v", insertedID)
-		}
+		ids[i] = r.DecodeID(insertedID)
 	}
 
 	return ids, nil
@@ -257,13 +412,10 @@ func (r *BaseRepository[T]) UpdateByID(ctx context.Context, id string, update in
 	)
 	defer span.End()
 
-	// Convert string ID to ObjectID if needed
-	var filter bson.M
-	objectID, err := primitive.ObjectIDFromHex(id)
+	// IDs the collection's codec rejects cannot match any document
+	filter, err := r.IDFilter(id)
 	if err != nil {
-		filter = bson.M{"_id": id}
-	} else {
-		filter = bson.M{"_id": objectID}
+		return ErrNotFound
 	}
 
 	// Ensure update has the correct format
@@ -283,7 +435,7 @@ func (r *BaseRepository[T]) UpdateByID(ctx context.Context, id string, update in
 
 	// Always update the updatedAt field
 	if setDoc, ok := updateDoc["$set"].(bson.M); ok {
-		setDoc["updatedAt"] = time.Now()
+		setDoc["updatedAt"] = r.Now()
 	}
 
 	result, err := r.collection.UpdateOne(ctx, filter, updateDoc)
@@ -294,6 +446,9 @@ func (r *BaseRepository[T]) UpdateByID(ctx context.Context, id string, update in
 			zap.String("id", id),
 			zap.Error(err),
 		)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
 		return fmt.Errorf("failed to update document: %w", err)
 	}
 
@@ -320,6 +475,9 @@ func (r *BaseRepository[T]) UpdateOne(ctx context.Context, filter interface{}, u
 			zap.String("collection", r.collection.Name()),
 			zap.Error(err),
 		)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyExists
+		}
 		return fmt.Errorf("failed to update document: %w", err)
 	}
 
@@ -362,13 +520,10 @@ func (r *BaseRepository[T]) DeleteByID(ctx context.Context, id string) error {
 	)
 	defer span.End()
 
-	// Convert string ID to ObjectID if needed
-	var filter bson.M
-	objectID, err := primitive.ObjectIDFromHex(id)
+	// IDs the collection's codec rejects cannot match any document
+	filter, err := r.IDFilter(id)
 	if err != nil {
-		filter = bson.M{"_id": id}
-	} else {
-		filter = bson.M{"_id": objectID}
+		return ErrNotFound
 	}
 
 	result, err := r.collection.DeleteOne(ctx, filter)
@@ -459,6 +614,29 @@ func (r *BaseRepository[T]) Count(ctx context.Context, filter interface{}, opts
 	return count, nil
 }
 
+// EstimatedCount returns the approximate number of documents in the collection from its metadata
+// It does not scan the collection, so prefer it over Count for totals on large collections.
+func (r *BaseRepository[T]) EstimatedCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "BaseRepository.EstimatedCount",
+		trace.WithAttributes(
+			attribute.String("collection", r.collection.Name()),
+		),
+	)
+	defer span.End()
+
+	count, err := r.collection.EstimatedDocumentCount(ctx, opts...)
+	if err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to estimate document count",
+			zap.String("collection", r.collection.Name()),
+			zap.Error(err),
+		)
+		return 0, fmt.Errorf("failed to estimate document count: %w", err)
+	}
+
+	return count, nil
+}
+
 // Exists checks if a document matching the filter exists
 func (r *BaseRepository[T]) Exists(ctx context.Context, filter interface{}) (bool, error) {
 	count, err := r.Count(ctx, filter)
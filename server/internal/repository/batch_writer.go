@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/clock"
+)
+
+// ErrWriterStopped is returned when writing to a stopped BatchWriter
+var ErrWriterStopped = errors.New("batch writer stopped")
+
+// Results of flushing a write, used as the "result" metric attribute
+const (
+	BatchWritten = "written"
+	BatchFailed  = "failed"
+)
+
+// BulkWriter runs bulk writes; *mongo.Collection implements it
+type BulkWriter interface {
+	Name() string
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+}
+
+// BatchWriterConfig configures a BatchWriter
+type BatchWriterConfig struct {
+	// Size is the number of buffered writes flushed together
+	Size int
+
+	// Interval is the longest a buffered write waits for its batch to fill
+	Interval time.Duration
+
+	// MaxPending bounds the buffered writes; writes beyond it block until a flush makes room
+	MaxPending int
+
+	// Timeout bounds a flush
+	Timeout time.Duration
+}
+
+// BatchWriter buffers inserts and updates of documents of type T and flushes them with unordered
+// bulk writes once Size are buffered or Interval elapsed, for ingestion paths where writing every
+// document on its own would overload MongoDB
+//
+// Writes are acknowledged once buffered: a flush that fails is logged and counted but not retried,
+// and duplicate key errors are ignored so inserts with client-side IDs can be repeated. Writes that
+// need to be durable before responding belong in the repositories or on the job queue. Stop
+// flushes the buffered writes and returns the error of that last flush.
+type BatchWriter[T any] struct {
+	target BulkWriter
+	clock  clock.Clock
+	config BatchWriterConfig
+
+	writes  chan mongo.WriteModel
+	flushes chan chan error
+
+	// stopping is closed first when the writer stops, waking writes blocked on a full buffer so
+	// they release mu; writes is closed once they have
+	stopping chan struct{}
+	stopOnce sync.Once
+	mu       sync.RWMutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// err is the error of the last flush, set before done is closed
+	err error
+
+	written metric.Int64Counter
+	size    metric.Int64Histogram
+}
+
+// NewBatchWriter creates a BatchWriter flushing to target; call Start to flush in the background
+func NewBatchWriter[T any](target BulkWriter, clk clock.Clock, cfg BatchWriterConfig) *BatchWriter[T] {
+	if clk == nil {
+		clk = clock.New()
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = 500
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.MaxPending < cfg.Size {
+		cfg.MaxPending = 10 * cfg.Size
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	w := &BatchWriter[T]{
+		target:   target,
+		clock:    clk,
+		config:   cfg,
+		writes:   make(chan mongo.WriteModel, cfg.MaxPending),
+		flushes:  make(chan chan error),
+		stopping: make(chan struct{}),
+	}
+	w.initInstruments()
+	return w
+}
+
+// initInstruments creates the batch counters; failures leave them nil and only disable metrics
+func (w *BatchWriter[T]) initInstruments() {
+	meter := otel.Meter("repository")
+
+	var err error
+	w.written, err = meter.Int64Counter("repository.batch.writes",
+		metric.WithDescription("Number of buffered writes flushed by collection and result"),
+	)
+	if err != nil {
+		logger.Error("Failed to create repository.batch.writes counter", zap.Error(err))
+	}
+	w.size, err = meter.Int64Histogram("repository.batch.size",
+		metric.WithDescription("Number of writes per flushed batch by collection"),
+	)
+	if err != nil {
+		logger.Error("Failed to create repository.batch.size histogram", zap.Error(err))
+	}
+}
+
+// Insert buffers inserting doc, blocking while MaxPending writes are buffered until a flush makes
+// room or ctx is done
+func (w *BatchWriter[T]) Insert(ctx context.Context, doc T) error {
+	return w.enqueue(ctx, mongo.NewInsertOneModel().SetDocument(doc))
+}
+
+// Update buffers applying update to the document matching filter, inserting it when upsert is set
+func (w *BatchWriter[T]) Update(ctx context.Context, filter, update interface{}, upsert bool) error {
+	return w.enqueue(ctx, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(upsert))
+}
+
+// enqueue buffers a write; the read lock keeps Stop from closing the buffer during the send, and
+// stopping wakes the send when the buffer is full so Stop can take the lock
+func (w *BatchWriter[T]) enqueue(ctx context.Context, model mongo.WriteModel) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	select {
+	case <-w.stopping:
+		return ErrWriterStopped
+	default:
+	}
+
+	select {
+	case w.writes <- model:
+		return nil
+	case <-w.stopping:
+		return ErrWriterStopped
+	case <-ctx.Done():
+		return fmt.Errorf("batch writer for %s is full: %w", w.target.Name(), ctx.Err())
+	}
+}
+
+// Start flushes the buffered writes in the background until Stop is called or ctx is done; once
+// ctx is done the writer stops as if Stop was called, rejecting further writes
+func (w *BatchWriter[T]) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		w.err = w.run(ctx)
+	}()
+}
+
+// Flush writes the buffered writes now, returning the error of the bulk write
+func (w *BatchWriter[T]) Flush(ctx context.Context) error {
+	result := make(chan error, 1)
+	select {
+	case w.flushes <- result:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop rejects further writes, flushes the buffered ones and waits for the flush or ctx to be done,
+// returning the error of the flush
+func (w *BatchWriter[T]) Stop(ctx context.Context) error {
+	w.markStopped()
+
+	if w.cancel == nil {
+		// Never started: flush in the caller
+		return w.flush(ctx, w.drain(nil))
+	}
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return w.err
+	case <-ctx.Done():
+		return fmt.Errorf("batch writer for %s did not flush: %w", w.target.Name(), ctx.Err())
+	}
+}
+
+// markStopped rejects further writes and closes the buffer, unless the writer is already stopped
+func (w *BatchWriter[T]) markStopped() {
+	w.stopOnce.Do(func() {
+		close(w.stopping)
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		close(w.writes)
+	})
+}
+
+// run batches the buffered writes, flushing full batches, batches older than Interval and the
+// batch left once the buffer is closed, and returns the error of that last flush
+func (w *BatchWriter[T]) run(ctx context.Context) error {
+	batch := make([]mongo.WriteModel, 0, w.config.Size)
+	var deadline <-chan time.Time
+
+	flush := func() error {
+		err := w.flush(context.WithoutCancel(ctx), batch)
+		batch = make([]mongo.WriteModel, 0, w.config.Size)
+		deadline = nil
+		return err
+	}
+
+	for {
+		select {
+		case model, ok := <-w.writes:
+			if !ok {
+				return flush()
+			}
+			if len(batch) == 0 {
+				deadline = w.clock.After(w.config.Interval)
+			}
+			batch = append(batch, model)
+			if len(batch) >= w.config.Size {
+				_ = flush()
+			}
+
+		case <-deadline:
+			_ = flush()
+
+		case result := <-w.flushes:
+			batch = w.drain(batch)
+			result <- flush()
+
+		case <-ctx.Done():
+			// Stop closed the buffer, or ctx was cancelled without Stop; flush what it holds
+			w.markStopped()
+			batch = w.drain(batch)
+			return flush()
+		}
+	}
+}
+
+// drain appends the writes buffered so far to batch
+func (w *BatchWriter[T]) drain(batch []mongo.WriteModel) []mongo.WriteModel {
+	for {
+		select {
+		case model, ok := <-w.writes:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, model)
+		default:
+			return batch
+		}
+	}
+}
+
+// flush writes batch in chunks of Size with unordered bulk writes, ignoring duplicate key errors
+func (w *BatchWriter[T]) flush(ctx context.Context, batch []mongo.WriteModel) error {
+	var errs []error
+	for start := 0; start < len(batch); start += w.config.Size {
+		chunk := batch[start:min(start+w.config.Size, len(batch))]
+		if err := w.write(ctx, chunk); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// write runs a bulk write of models and records its outcome
+func (w *BatchWriter[T]) write(ctx context.Context, models []mongo.WriteModel) error {
+	ctx, cancel := context.WithTimeout(ctx, w.config.Timeout)
+	defer cancel()
+
+	failed := int64(0)
+	_, err := w.target.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	switch {
+	case err == nil:
+	case errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil:
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				failed++
+			}
+		}
+		if failed == 0 {
+			err = nil
+		}
+	default:
+		failed = int64(len(models))
+	}
+
+	w.record(ctx, int64(len(models))-failed, failed)
+	if err != nil {
+		logger.Error("Failed to flush batched writes",
+			zap.String("collection", w.target.Name()),
+			zap.Int("writes", len(models)),
+			zap.Int64("failed", failed),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to flush %d writes to %s: %w", len(models), w.target.Name(), err)
+	}
+	return nil
+}
+
+// record adds a flushed batch to the batch metrics
+func (w *BatchWriter[T]) record(ctx context.Context, written, failed int64) {
+	collection := attribute.String("collection", w.target.Name())
+	if w.written != nil {
+		w.written.Add(ctx, written, metric.WithAttributes(collection, attribute.String("result", BatchWritten)))
+		if failed > 0 {
+			w.written.Add(ctx, failed, metric.WithAttributes(collection, attribute.String("result", BatchFailed)))
+		}
+	}
+	if w.size != nil {
+		w.size.Record(ctx, written+failed, metric.WithAttributes(collection))
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/testutil"
+)
+
+// fakeBulkWriter records the bulk writes it receives, failing them with err when set
+type fakeBulkWriter struct {
+	mu      sync.Mutex
+	batches [][]mongo.WriteModel
+	err     error
+}
+
+func (f *fakeBulkWriter) Name() string { return "events" }
+
+func (f *fakeBulkWriter) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, models)
+	return &mongo.BulkWriteResult{}, f.err
+}
+
+// sizes returns the number of writes of every bulk write so far
+func (f *fakeBulkWriter) sizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sizes := make([]int, len(f.batches))
+	for i, batch := range f.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+type testEvent struct {
+	Name string `bson:"name"`
+}
+
+func TestBatchWriter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Flushes full batches", func(t *testing.T) {
+		target := &fakeBulkWriter{}
+		writer := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 2})
+		writer.Start(ctx)
+
+		for _, name := range []string{"a", "b", "c"} {
+			require.NoError(t, writer.Insert(ctx, testEvent{Name: name}))
+		}
+		require.Eventually(t, func() bool { return len(target.sizes()) == 1 }, 5*time.Second, time.Millisecond)
+		assert.Equal(t, []int{2}, target.sizes())
+
+		require.NoError(t, writer.Stop(ctx))
+		assert.Equal(t, []int{2, 1}, target.sizes(), "Stop flushes the partial batch")
+		assert.ErrorIs(t, writer.Insert(ctx, testEvent{Name: "d"}), ErrWriterStopped)
+	})
+
+	t.Run("Flushes after the interval", func(t *testing.T) {
+		target := &fakeBulkWriter{}
+		clk := testutil.NewFakeClock(time.Now())
+		writer := NewBatchWriter[testEvent](target, clk, BatchWriterConfig{Size: 100, Interval: time.Second})
+		writer.Start(ctx)
+		defer writer.Stop(ctx)
+
+		require.NoError(t, writer.Update(ctx, bson.M{"name": "a"}, bson.M{"$inc": bson.M{"count": 1}}, true))
+		require.Eventually(t, func() bool { return clk.Waiters() == 1 }, 5*time.Second, time.Millisecond)
+		assert.Empty(t, target.sizes())
+
+		clk.Advance(time.Second)
+		require.Eventually(t, func() bool { return len(target.sizes()) == 1 }, 5*time.Second, time.Millisecond)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		target := &fakeBulkWriter{err: errors.New("connection reset")}
+		writer := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 100})
+		writer.Start(ctx)
+		defer writer.Stop(ctx)
+
+		require.NoError(t, writer.Insert(ctx, testEvent{Name: "a"}))
+		assert.ErrorContains(t, writer.Flush(ctx), "connection reset")
+		assert.Equal(t, []int{1}, target.sizes())
+	})
+
+	t.Run("Ignores duplicate keys", func(t *testing.T) {
+		target := &fakeBulkWriter{err: mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+			{WriteError: mongo.WriteError{Code: 11000, Message: "E11000 duplicate key error"}},
+		}}}
+		writer := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 100})
+		writer.Start(ctx)
+		defer writer.Stop(ctx)
+
+		require.NoError(t, writer.Insert(ctx, testEvent{Name: "a"}))
+		assert.NoError(t, writer.Flush(ctx))
+	})
+
+	t.Run("Blocks while full", func(t *testing.T) {
+		target := &fakeBulkWriter{}
+		writer := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 1, MaxPending: 1})
+
+		require.NoError(t, writer.Insert(ctx, testEvent{Name: "a"}))
+		timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, writer.Insert(timeout, testEvent{Name: "b"}), context.DeadlineExceeded)
+
+		require.NoError(t, writer.Stop(ctx))
+		assert.Equal(t, []int{1}, target.sizes(), "stopping a writer that was never started flushes in the caller")
+	})
+
+	t.Run("Stop returns the error of the last flush", func(t *testing.T) {
+		target := &fakeBulkWriter{err: errors.New("connection reset")}
+		writer := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 100})
+		writer.Start(ctx)
+
+		require.NoError(t, writer.Insert(ctx, testEvent{Name: "a"}))
+		assert.ErrorContains(t, writer.Stop(ctx), "failed to flush 1 writes to events: connection reset")
+		assert.Equal(t, []int{1}, target.sizes())
+
+		unstarted := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 100})
+		require.NoError(t, unstarted.Insert(ctx, testEvent{Name: "a"}))
+		assert.ErrorContains(t, unstarted.Stop(ctx), "connection reset")
+	})
+
+	t.Run("Stops under backpressure", func(t *testing.T) {
+		target := &fakeBulkWriter{}
+		clk := testutil.NewFakeClock(time.Now())
+		writer := NewBatchWriter[testEvent](target, clk, BatchWriterConfig{Size: 1, MaxPending: 1})
+		require.NoError(t, writer.Insert(ctx, testEvent{Name: "a"}))
+
+		// Writes block on the full buffer until the writer stops
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- writer.Insert(ctx, testEvent{Name: "b"})
+			}()
+		}
+
+		// Give the writes time to block on the buffer
+		time.Sleep(20 * time.Millisecond)
+
+		started, cancel := context.WithCancel(ctx)
+		cancel()
+		writer.Start(started)
+
+		timeout, cancelTimeout := context.WithTimeout(ctx, time.Second)
+		defer cancelTimeout()
+		require.NoError(t, writer.Stop(timeout))
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, ErrWriterStopped)
+			}
+		}
+	})
+
+	t.Run("Stops when the start context is done", func(t *testing.T) {
+		target := &fakeBulkWriter{}
+		writer := NewBatchWriter[testEvent](target, testutil.NewFakeClock(time.Now()), BatchWriterConfig{Size: 100})
+		started, cancel := context.WithCancel(ctx)
+		writer.Start(started)
+
+		require.NoError(t, writer.Insert(ctx, testEvent{Name: "a"}))
+		cancel()
+
+		require.Eventually(t, func() bool {
+			return errors.Is(writer.Insert(ctx, testEvent{Name: "b"}), ErrWriterStopped)
+		}, 5*time.Second, time.Millisecond, "later writes are rejected instead of buffered forever")
+		require.NoError(t, writer.Stop(ctx))
+		assert.Equal(t, []int{1}, target.sizes(), "the buffered writes are flushed")
+	})
+}
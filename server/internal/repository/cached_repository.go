@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+)
+
+// notFoundMarker is cached in place of a document that does not exist
+const notFoundMarker = "\x00notfound"
+
+// CacheConfig configures a CachedRepository
+// The zero value disables caching, so repositories behave exactly like their BaseRepository.
+type CacheConfig struct {
+	// Redis is the resource backing the cache
+	Redis resources.RedisResource
+
+	// TTL is how long found documents are cached (0 disables caching)
+	TTL time.Duration
+
+	// NegativeTTL is how long not-found lookups are cached (0 disables negative caching)
+	NegativeTTL time.Duration
+
+	// CountTTL is how long exact counts are cached (0 disables count caching)
+	CountTTL time.Duration
+
+	// KeyPrefix namespaces cache keys; defaults to "repo:<entity>:"
+	KeyPrefix string
+}
+
+// InvalidationHook is called after a cached document is invalidated
+type InvalidationHook func(ctx context.Context, id string)
+
+// CachedRepository decorates a BaseRepository with a Redis read-through cache for FindByID
+// UpdateByID and DeleteByID invalidate the cached entry. Writes by filter (UpdateOne, DeleteMany, ...)
+// cannot know the affected IDs, so callers using them must call Invalidate themselves.
+type CachedRepository[T any] struct {
+	*BaseRepository[T]
+	client      redis.Cmdable
+	ttl         time.Duration
+	negativeTTL time.Duration
+	countTTL    time.Duration
+	keyPrefix   string
+	hooks       []InvalidationHook
+}
+
+// NewCachedRepository wraps base with a cache configured by cfg
+func NewCachedRepository[T any](base *BaseRepository[T], cfg CacheConfig) *CachedRepository[T] {
+	r := &CachedRepository[T]{
+		BaseRepository: base,
+		ttl:            cfg.TTL,
+		negativeTTL:    cfg.NegativeTTL,
+		countTTL:       cfg.CountTTL,
+		keyPrefix:      cfg.KeyPrefix,
+	}
+
+	if r.keyPrefix == "" {
+		r.keyPrefix = "repo:" + base.EntityName() + ":"
+	}
+
+	if cfg.Redis != nil && (cfg.TTL > 0 || cfg.CountTTL > 0) {
+		if client, ok := cfg.Redis.Client().(*redis.Client); ok && client != nil {
+			r.client = client
+		} else {
+			logger.Warn("Redis client unavailable, repository cache disabled",
+				zap.String("entity", base.EntityName()),
+			)
+		}
+	}
+
+	return r
+}
+
+// OnInvalidate registers a hook that runs whenever an entry is invalidated,
+// e.g. to purge response caches derived from the document
+func (r *CachedRepository[T]) OnInvalidate(hook InvalidationHook) {
+	r.hooks = append(r.hooks, hook)
+}
+
+// FindByID returns the cached document when present, falling back to the database
+// Lookups with options (e.g. projections) bypass the cache since they return partial documents.
+func (r *CachedRepository[T]) FindByID(ctx context.Context, id string, opts ...*options.FindOneOptions) (*T, error) {
+	if r.client == nil || r.ttl <= 0 || len(opts) > 0 {
+		return r.BaseRepository.FindByID(ctx, id, opts...)
+	}
+
+	ctx, span := r.tracer.Start(ctx, "CachedRepository.FindByID",
+		trace.WithAttributes(
+			attribute.String("collection", r.collection.Name()),
+			attribute.String("id", id),
+		),
+	)
+	defer span.End()
+
+	key := r.key(id)
+	cached, err := r.client.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		if string(cached) == notFoundMarker {
+			return nil, ErrNotFound
+		}
+
+		var doc T
+		if err := bson.Unmarshal(cached, &doc); err == nil {
+			return &doc, nil
+		}
+		logger.WarnCtx(ctx, "Failed to decode cached document, reloading",
+			zap.String("entity", r.entityName),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+	case !errors.Is(err, redis.Nil):
+		logger.WarnCtx(ctx, "Failed to read repository cache",
+			zap.String("entity", r.entityName),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	doc, err := r.BaseRepository.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) && r.negativeTTL > 0 {
+			r.set(ctx, key, notFoundMarker, r.negativeTTL)
+		}
+		return nil, err
+	}
+
+	if raw, err := bson.Marshal(doc); err == nil {
+		r.set(ctx, key, raw, r.ttl)
+	}
+
+	return doc, nil
+}
+
+// Count returns the number of documents matching filter, caching the result for CountTTL
+// Cached counts are not invalidated by writes and may lag by up to CountTTL, which is
+// acceptable for pagination totals. Counts with options always hit the database.
+func (r *CachedRepository[T]) Count(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	if r.client == nil || r.countTTL <= 0 || len(opts) > 0 {
+		return r.BaseRepository.Count(ctx, filter, opts...)
+	}
+
+	shape, err := countKey(filter)
+	if err != nil {
+		return r.BaseRepository.Count(ctx, filter)
+	}
+	key := r.keyPrefix + "count:" + shape
+
+	if count, err := r.client.Get(ctx, key).Int64(); err == nil {
+		return count, nil
+	} else if !errors.Is(err, redis.Nil) {
+		logger.WarnCtx(ctx, "Failed to read cached count",
+			zap.String("entity", r.entityName),
+			zap.Error(err),
+		)
+	}
+
+	count, err := r.BaseRepository.Count(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	r.set(ctx, key, count, r.countTTL)
+	return count, nil
+}
+
+// countKey hashes filter into the part of a count's cache key identifying the filter
+// Map iteration order is random, so maps are marshaled with their keys sorted for equal
+// filters to share a key.
+func countKey(filter interface{}) (string, error) {
+	shape, err := bson.MarshalExtJSON(sortedFilter(filter), true, false)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(shape)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sortedFilter returns filter with every map replaced by a bson.D of its entries sorted by key
+func sortedFilter(filter interface{}) interface{} {
+	if d, ok := filter.(bson.D); ok {
+		sorted := make(bson.D, len(d))
+		for i, e := range d {
+			sorted[i] = bson.E{Key: e.Key, Value: sortedFilter(e.Value)}
+		}
+		return sorted
+	}
+
+	value := reflect.ValueOf(filter)
+	switch {
+	case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		sorted := make(bson.D, len(keys))
+		for i, key := range keys {
+			sorted[i] = bson.E{Key: key.String(), Value: sortedFilter(value.MapIndex(key).Interface())}
+		}
+		return sorted
+	case value.Kind() == reflect.Slice && (value.Type().Elem().Kind() == reflect.Interface || value.Type().Elem().Kind() == reflect.Map):
+		sorted := make(bson.A, value.Len())
+		for i := range sorted {
+			sorted[i] = sortedFilter(value.Index(i).Interface())
+		}
+		return sorted
+	}
+	return filter
+}
+
+// InsertOne inserts a document and clears any negative cache entry for its ID
+func (r *CachedRepository[T]) InsertOne(ctx context.Context, document *T) (string, error) {
+	id, err := r.BaseRepository.InsertOne(ctx, document)
+	if err != nil {
+		return "", err
+	}
+
+	r.Invalidate(ctx, id)
+	return id, nil
+}
+
+// UpdateByID updates a document and invalidates its cache entry
+func (r *CachedRepository[T]) UpdateByID(ctx context.Context, id string, update interface{}) error {
+	err := r.BaseRepository.UpdateByID(ctx, id, update)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		r.Invalidate(ctx, id)
+	}
+	return err
+}
+
+// DeleteByID deletes a document and invalidates its cache entry
+func (r *CachedRepository[T]) DeleteByID(ctx context.Context, id string) error {
+	err := r.BaseRepository.DeleteByID(ctx, id)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		r.Invalidate(ctx, id)
+	}
+	return err
+}
+
+// Invalidate removes the cache entry for id and runs the registered hooks
+func (r *CachedRepository[T]) Invalidate(ctx context.Context, id string) {
+	if r.client != nil {
+		if err := r.client.Del(ctx, r.key(id)).Err(); err != nil {
+			logger.WarnCtx(ctx, "Failed to invalidate repository cache",
+				zap.String("entity", r.entityName),
+				zap.String("id", id),
+				zap.Error(err),
+			)
+		}
+	}
+
+	for _, hook := range r.hooks {
+		hook(ctx, id)
+	}
+}
+
+// key returns the cache key for id
+func (r *CachedRepository[T]) key(id string) string {
+	return fmt.Sprintf("%s%s", r.keyPrefix, id)
+}
+
+// set writes a cache entry, logging instead of failing the request on errors
+func (r *CachedRepository[T]) set(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		logger.WarnCtx(ctx, "Failed to write repository cache",
+			zap.String("entity", r.entityName),
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
+}
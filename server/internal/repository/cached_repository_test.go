@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCountKey(t *testing.T) {
+	// key returns the count key of a filter, checking that it is the same on every call
+	key := func(t *testing.T, filter interface{}) string {
+		t.Helper()
+		first, err := countKey(filter)
+		require.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			again, err := countKey(filter)
+			require.NoError(t, err)
+			require.Equal(t, first, again, "map order must not change the key")
+		}
+		return first
+	}
+
+	filter := bson.M{
+		"status": "active",
+		"role":   bson.M{"$in": bson.A{"admin", "owner"}},
+		"$or":    []bson.M{{"deleted": false, "archived": false}, {"pinned": true}},
+		"age":    map[string]int{"$gte": 18, "$lt": 65},
+	}
+
+	t.Run("Is the same for equal filters", func(t *testing.T) {
+		assert.Equal(t, key(t, filter), key(t, bson.D{
+			{Key: "$or", Value: bson.A{bson.D{{Key: "archived", Value: false}, {Key: "deleted", Value: false}}, bson.M{"pinned": true}}},
+			{Key: "age", Value: bson.M{"$lt": 65, "$gte": 18}},
+			{Key: "role", Value: bson.D{{Key: "$in", Value: bson.A{"admin", "owner"}}}},
+			{Key: "status", Value: "active"},
+		}))
+	})
+
+	t.Run("Differs for other filters", func(t *testing.T) {
+		assert.NotEqual(t, key(t, filter), key(t, bson.M{"status": "active"}))
+		assert.NotEqual(t, key(t, bson.M{"role": bson.A{"admin", "owner"}}), key(t, bson.M{"role": bson.A{"owner", "admin"}}),
+			"array order is significant")
+	})
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// DeviceRepository stores the devices registered for push notifications
+// A token identifies a device: registering it again updates its registration.
+type DeviceRepository interface {
+	// Register stores a device of a user, or updates the registration of its token, assigning its ID
+	// and timestamps; it reports whether the device was new to the user
+	// A token registered by another user moves to this user without its topics.
+	Register(ctx context.Context, device *domain.Device) (bool, error)
+
+	// Get returns a device of a user, or ErrNotFound
+	Get(ctx context.Context, userID, id string) (*domain.Device, error)
+
+	// ListByUser returns the devices of a user, most recently registered first
+	ListByUser(ctx context.Context, userID string) ([]*domain.Device, error)
+
+	// EachSubscriber calls fn with each device subscribed to topic, stopping at the first error of fn
+	EachSubscriber(ctx context.Context, topic string, fn func(*domain.Device) error) error
+
+	// Subscribe adds topic to the topics of a device of a user, or returns ErrNotFound
+	Subscribe(ctx context.Context, userID, id, topic string) error
+
+	// Unsubscribe removes topic from the topics of a device of a user, or returns ErrNotFound
+	Unsubscribe(ctx context.Context, userID, id, topic string) error
+
+	// Delete removes a device of a user, or returns ErrNotFound
+	Delete(ctx context.Context, userID, id string) error
+
+	// DeleteByTokens removes the devices of tokens, returning how many were removed
+	DeleteByTokens(ctx context.Context, tokens []string) (int64, error)
+
+	// DeleteByUser removes every device of a user, returning how many were removed
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// deviceRepositoryImpl is the MongoDB implementation of DeviceRepository
+type deviceRepositoryImpl struct {
+	*BaseRepository[deviceDocument]
+}
+
+// deviceDocument represents the MongoDB document structure for devices
+type deviceDocument struct {
+	ID        interface{} `bson:"_id"`
+	UserID    string      `bson:"userId"`
+	Platform  string      `bson:"platform"`
+	Token     string      `bson:"token"`
+	Name      string      `bson:"name,omitempty"`
+	Topics    []string    `bson:"topics"`
+	CreatedAt time.Time   `bson:"createdAt"`
+	UpdatedAt time.Time   `bson:"updatedAt"`
+}
+
+// deviceIndexes make tokens unique and serve listing the devices of a user and of a topic
+var deviceIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "token", Value: 1}}, Options: options.Index().SetUnique(true)},
+	{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	{Keys: bson.D{{Key: "topics", Value: 1}}},
+}
+
+// NewDeviceRepository creates a new DeviceRepository storing devices in the devices collection with IDs from ids
+func NewDeviceRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) DeviceRepository {
+	dbInstance := db.(*resources.DB)
+
+	return &deviceRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[deviceDocument](BaseRepositoryConfig{
+			Collection: dbInstance.Collection("devices"),
+			EntityName: "device",
+		}, WithClock(clk), WithIDCodec(ids)),
+	}
+}
+
+// SyncCollection creates the indexes of the devices collection
+func (r *deviceRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.BaseRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, deviceIndexes); err != nil {
+		return fmt.Errorf("failed to create device indexes: %w", err)
+	}
+	return nil
+}
+
+// Register stores a device, or updates the registration of its token
+// Two registrations of a new token racing each other are settled by the unique token index: the
+// loser updates the registration of the winner.
+func (r *deviceRepositoryImpl) Register(ctx context.Context, device *domain.Device) (bool, error) {
+	for attempt := 0; ; attempt++ {
+		existing, err := r.FindOne(ctx, bson.M{"token": device.Token})
+		if err == ErrNotFound {
+			created, err := r.insert(ctx, device)
+			if err == ErrAlreadyExists && attempt == 0 {
+				continue
+			}
+			return created, err
+		}
+		if err != nil {
+			return false, err
+		}
+		return r.update(ctx, existing, device)
+	}
+}
+
+// insert stores a new device
+func (r *deviceRepositoryImpl) insert(ctx context.Context, device *domain.Device) (bool, error) {
+	id, err := r.EncodeID(r.NewID())
+	if err != nil {
+		return false, err
+	}
+	now := r.Now()
+	doc := deviceDocument{
+		ID:        id,
+		UserID:    device.UserID,
+		Platform:  device.Platform,
+		Token:     device.Token,
+		Name:      device.Name,
+		Topics:    []string{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := r.InsertOne(ctx, &doc); err != nil {
+		return false, err
+	}
+	*device = *r.toDevice(&doc)
+	return true, nil
+}
+
+// update updates the registration of an existing token for device
+func (r *deviceRepositoryImpl) update(ctx context.Context, existing *deviceDocument, device *domain.Device) (bool, error) {
+	now := r.Now()
+	set := bson.M{"platform": device.Platform, "name": device.Name, "updatedAt": now}
+	moved := existing.UserID != device.UserID
+	if moved {
+		set["userId"] = device.UserID
+		set["topics"] = []string{}
+		set["createdAt"] = now
+	}
+	if err := r.UpdateOne(ctx, bson.M{"_id": existing.ID, "token": device.Token}, bson.M{"$set": set}); err != nil {
+		return false, err
+	}
+
+	existing.Platform, existing.Name, existing.UpdatedAt = device.Platform, device.Name, now
+	if moved {
+		existing.UserID, existing.Topics, existing.CreatedAt = device.UserID, []string{}, now
+	}
+	*device = *r.toDevice(existing)
+	return moved, nil
+}
+
+// Get returns a device of a user
+func (r *deviceRepositoryImpl) Get(ctx context.Context, userID, id string) (*domain.Device, error) {
+	filter, err := r.userFilter(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := r.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDevice(doc), nil
+}
+
+// ListByUser returns the devices of a user, most recently registered first
+func (r *deviceRepositoryImpl) ListByUser(ctx context.Context, userID string) ([]*domain.Device, error) {
+	docs, err := r.Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]*domain.Device, len(docs))
+	for i := range docs {
+		devices[i] = r.toDevice(&docs[i])
+	}
+	return devices, nil
+}
+
+// EachSubscriber streams the devices subscribed to topic to fn
+func (r *deviceRepositoryImpl) EachSubscriber(ctx context.Context, topic string, fn func(*domain.Device) error) error {
+	return r.FindEach(ctx, bson.M{"topics": topic}, func(doc *deviceDocument) error {
+		return fn(r.toDevice(doc))
+	})
+}
+
+// Subscribe adds topic to the topics of a device of a user
+func (r *deviceRepositoryImpl) Subscribe(ctx context.Context, userID, id, topic string) error {
+	return r.updateTopics(ctx, userID, id, bson.M{"$addToSet": bson.M{"topics": topic}})
+}
+
+// Unsubscribe removes topic from the topics of a device of a user
+func (r *deviceRepositoryImpl) Unsubscribe(ctx context.Context, userID, id, topic string) error {
+	return r.updateTopics(ctx, userID, id, bson.M{"$pull": bson.M{"topics": topic}})
+}
+
+// updateTopics applies update to the topics of a device of a user
+func (r *deviceRepositoryImpl) updateTopics(ctx context.Context, userID, id string, update bson.M) error {
+	filter, err := r.userFilter(userID, id)
+	if err != nil {
+		return err
+	}
+	update["$set"] = bson.M{"updatedAt": r.Now()}
+	return r.UpdateOne(ctx, filter, update)
+}
+
+// Delete removes a device of a user
+func (r *deviceRepositoryImpl) Delete(ctx context.Context, userID, id string) error {
+	filter, err := r.userFilter(userID, id)
+	if err != nil {
+		return err
+	}
+	return r.DeleteOne(ctx, filter)
+}
+
+// DeleteByTokens removes the devices of tokens
+func (r *deviceRepositoryImpl) DeleteByTokens(ctx context.Context, tokens []string) (int64, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+	return r.DeleteMany(ctx, bson.M{"token": bson.M{"$in": tokens}})
+}
+
+// DeleteByUser removes every device of a user
+func (r *deviceRepositoryImpl) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	return r.DeleteMany(ctx, bson.M{"userId": userID})
+}
+
+// userFilter matches the device with id of a user; invalid IDs return ErrNotFound
+func (r *deviceRepositoryImpl) userFilter(userID, id string) (bson.M, error) {
+	filter, err := r.IDFilter(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	filter["userId"] = userID
+	return filter, nil
+}
+
+func (r *deviceRepositoryImpl) toDevice(doc *deviceDocument) *domain.Device {
+	return &domain.Device{
+		ID:        r.DecodeID(doc.ID),
+		UserID:    doc.UserID,
+		Platform:  doc.Platform,
+		Token:     doc.Token,
+		Name:      doc.Name,
+		Topics:    doc.Topics,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+)
+
+// FileInfo describes a file stored in the file repository
+type FileInfo struct {
+	ID         string
+	Filename   string
+	Length     int64
+	ChunkSize  int32
+	UploadDate time.Time
+	Metadata   map[string]interface{}
+}
+
+// FileRepository defines the interface for storing binary files such as images and attachments
+type FileRepository interface {
+	// Upload stores the contents of source under filename and returns the new file ID
+	Upload(ctx context.Context, filename string, source io.Reader, metadata map[string]interface{}) (string, error)
+
+	// OpenUploadStream returns a writer for streaming a new file; the file is committed on Close
+	OpenUploadStream(ctx context.Context, filename string, metadata map[string]interface{}) (string, io.WriteCloser, error)
+
+	// Download writes the contents of the file to dst and returns the number of bytes written
+	Download(ctx context.Context, id string, dst io.Writer) (int64, error)
+
+	// OpenDownloadStream returns a reader over the file contents along with its info
+	OpenDownloadStream(ctx context.Context, id string) (io.ReadCloser, *FileInfo, error)
+
+	// Stat returns the info of a file without reading its contents
+	Stat(ctx context.Context, id string) (*FileInfo, error)
+
+	// Delete removes a file and all of its chunks
+	Delete(ctx context.Context, id string) error
+}
+
+// fileRepositoryImpl is the GridFS implementation of FileRepository
+type fileRepositoryImpl struct {
+	bucket     *gridfs.Bucket
+	bucketName string
+	tracer     trace.Tracer
+}
+
+// NewFileRepository creates a new GridFS-backed FileRepository
+func NewFileRepository(db resources.DBResource, cfg *config.Config) (FileRepository, error) {
+	dbInstance := db.(*resources.DB)
+
+	opts := options.GridFSBucket().SetName(cfg.MongoDB.GridFSBucket)
+	if cfg.MongoDB.GridFSChunkSizeBytes > 0 {
+		opts.SetChunkSizeBytes(cfg.MongoDB.GridFSChunkSizeBytes)
+	}
+
+	bucket, err := gridfs.NewBucket(dbInstance.GetDatabase(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gridfs bucket: %w", err)
+	}
+
+	return &fileRepositoryImpl{
+		bucket:     bucket,
+		bucketName: cfg.MongoDB.GridFSBucket,
+		tracer:     otel.Tracer("repository"),
+	}, nil
+}
+
+// Upload stores the contents of source under filename and returns the new file ID
+func (r *fileRepositoryImpl) Upload(ctx context.Context, filename string, source io.Reader, metadata map[string]interface{}) (string, error) {
+	ctx, span := r.startSpan(ctx, "FileRepository.Upload", attribute.String("filename", filename))
+	defer span.End()
+
+	stream, err := r.openUploadStream(ctx, filename, metadata)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	id := stream.FileID.(primitive.ObjectID).Hex()
+
+	written, err := io.Copy(stream, source)
+	if err != nil {
+		if abortErr := stream.Abort(); abortErr != nil {
+			logger.WarnCtx(ctx, "Failed to abort upload", zap.String("id", id), zap.Error(abortErr))
+		}
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to upload file",
+			zap.String("bucket", r.bucketName),
+			zap.String("filename", filename),
+			zap.Error(err),
+		)
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	if err := stream.Close(); err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to finalize upload",
+			zap.String("bucket", r.bucketName),
+			zap.String("filename", filename),
+			zap.Error(err),
+		)
+		return "", fmt.Errorf("failed to finalize upload: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("id", id), attribute.Int64("size", written))
+	return id, nil
+}
+
+// OpenUploadStream returns a writer for streaming a new file; the file is committed on Close
+func (r *fileRepositoryImpl) OpenUploadStream(ctx context.Context, filename string, metadata map[string]interface{}) (string, io.WriteCloser, error) {
+	ctx, span := r.startSpan(ctx, "FileRepository.OpenUploadStream", attribute.String("filename", filename))
+	defer span.End()
+
+	stream, err := r.openUploadStream(ctx, filename, metadata)
+	if err != nil {
+		span.RecordError(err)
+		return "", nil, err
+	}
+
+	id := stream.FileID.(primitive.ObjectID).Hex()
+	span.SetAttributes(attribute.String("id", id))
+	return id, stream, nil
+}
+
+// openUploadStream opens a GridFS upload stream bounded by the context deadline
+func (r *fileRepositoryImpl) openUploadStream(ctx context.Context, filename string, metadata map[string]interface{}) (*gridfs.UploadStream, error) {
+	opts := options.GridFSUpload()
+	if metadata != nil {
+		opts.SetMetadata(metadata)
+	}
+
+	stream, err := r.bucket.OpenUploadStream(filename, opts)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to open upload stream",
+			zap.String("bucket", r.bucketName),
+			zap.String("filename", filename),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to open upload stream: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetWriteDeadline(deadline)
+	}
+
+	return stream, nil
+}
+
+// Download writes the contents of the file to dst and returns the number of bytes written
+func (r *fileRepositoryImpl) Download(ctx context.Context, id string, dst io.Writer) (int64, error) {
+	ctx, span := r.startSpan(ctx, "FileRepository.Download", attribute.String("id", id))
+	defer span.End()
+
+	stream, _, err := r.OpenDownloadStream(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	defer stream.Close()
+
+	written, err := io.Copy(dst, stream)
+	if err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to download file",
+			zap.String("bucket", r.bucketName),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		return written, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("size", written))
+	return written, nil
+}
+
+// OpenDownloadStream returns a reader over the file contents along with its info
+func (r *fileRepositoryImpl) OpenDownloadStream(ctx context.Context, id string) (io.ReadCloser, *FileInfo, error) {
+	ctx, span := r.startSpan(ctx, "FileRepository.OpenDownloadStream", attribute.String("id", id))
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil, ErrInvalidID
+	}
+
+	stream, err := r.bucket.OpenDownloadStream(objectID)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to open download stream",
+			zap.String("bucket", r.bucketName),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		return nil, nil, fmt.Errorf("failed to open download stream: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetReadDeadline(deadline)
+	}
+
+	return stream, toFileInfo(stream.GetFile()), nil
+}
+
+// Stat returns the info of a file without reading its contents
+func (r *fileRepositoryImpl) Stat(ctx context.Context, id string) (*FileInfo, error) {
+	ctx, span := r.startSpan(ctx, "FileRepository.Stat", attribute.String("id", id))
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	cursor, err := r.bucket.FindContext(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to find file: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to find file: %w", err)
+		}
+		return nil, ErrNotFound
+	}
+
+	var file gridfs.File
+	if err := cursor.Decode(&file); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+
+	return toFileInfo(&file), nil
+}
+
+// Delete removes a file and all of its chunks
+func (r *fileRepositoryImpl) Delete(ctx context.Context, id string) error {
+	ctx, span := r.startSpan(ctx, "FileRepository.Delete", attribute.String("id", id))
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	if err := r.bucket.DeleteContext(ctx, objectID); err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return ErrNotFound
+		}
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to delete file",
+			zap.String("bucket", r.bucketName),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// startSpan starts a span tagged with the bucket name
+func (r *fileRepositoryImpl) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("bucket", r.bucketName))
+	return r.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// toFileInfo converts a GridFS file into a FileInfo
+func toFileInfo(file *gridfs.File) *FileInfo {
+	info := &FileInfo{
+		Filename:   file.Name,
+		Length:     file.Length,
+		ChunkSize:  file.ChunkSize,
+		UploadDate: file.UploadDate,
+	}
+
+	if oid, ok := file.ID.(primitive.ObjectID); ok {
+		info.ID = oid.Hex()
+	} else {
+		info.ID = fmt.Sprintf("%v", file.ID)
+	}
+
+	if len(file.Metadata) > 0 {
+		var metadata map[string]interface{}
+		if err := bson.Unmarshal(file.Metadata, &metadata); err == nil {
+			info.Metadata = metadata
+		}
+	}
+
+	return info
+}
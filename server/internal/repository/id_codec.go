@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quizizz.com/pkg/idgen"
+)
+
+// ID codec names, as used in ID_CODECS
+const (
+	IDCodecString   = "string"
+	IDCodecObjectID = "objectid"
+)
+
+// IDCodec converts between the string IDs used by the domain and API and the _id values a
+// collection stores, so every repository treats its IDs one declared way instead of guessing
+type IDCodec interface {
+	// Name identifies the codec, e.g. in configuration
+	Name() string
+
+	// NewID returns a new ID in its string form
+	NewID() string
+
+	// Encode returns the _id value stored for id, or ErrInvalidID when id cannot be one of this
+	// collection's IDs
+	Encode(id string) (interface{}, error)
+
+	// Decode returns the string form of a stored _id value
+	Decode(value interface{}) string
+}
+
+// NewIDCodec returns the codec named name; IDs of string codecs come from ids
+func NewIDCodec(name string, ids idgen.Generator) (IDCodec, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case IDCodecString, "":
+		return StringIDCodec(ids), nil
+	case IDCodecObjectID:
+		return ObjectIDCodec(), nil
+	default:
+		return nil, fmt.Errorf("unknown ID codec %q (expected %s or %s)", name, IDCodecString, IDCodecObjectID)
+	}
+}
+
+// stringIDCodec stores IDs as the strings generated by an idgen.Generator
+type stringIDCodec struct {
+	ids idgen.Generator
+}
+
+// StringIDCodec returns a codec storing IDs from ids as plain strings
+func StringIDCodec(ids idgen.Generator) IDCodec {
+	if ids == nil {
+		ids = idgen.NewUUIDv7(nil)
+	}
+	return stringIDCodec{ids: ids}
+}
+
+func (c stringIDCodec) Name() string { return IDCodecString }
+
+func (c stringIDCodec) NewID() string { return c.ids.NewID() }
+
+func (c stringIDCodec) Encode(id string) (interface{}, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	return id, nil
+}
+
+func (c stringIDCodec) Decode(value interface{}) string {
+	return decodeID(value)
+}
+
+// objectIDCodec stores IDs as ObjectIDs, represented by their hex form
+type objectIDCodec struct{}
+
+// ObjectIDCodec returns a codec storing IDs as ObjectIDs, for collections written by the driver's defaults
+func ObjectIDCodec() IDCodec {
+	return objectIDCodec{}
+}
+
+func (objectIDCodec) Name() string { return IDCodecObjectID }
+
+func (objectIDCodec) NewID() string { return primitive.NewObjectID().Hex() }
+
+func (objectIDCodec) Encode(id string) (interface{}, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	return objectID, nil
+}
+
+func (objectIDCodec) Decode(value interface{}) string {
+	return decodeID(value)
+}
+
+// decodeID returns the string form of a stored _id of any type
+func decodeID(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// WithIDCodec declares how the collection stores document IDs; defaults to StringIDCodec with UUIDv7 IDs
+func WithIDCodec(codec IDCodec) Option {
+	return func(o *repositoryOptions) {
+		if codec != nil {
+			o.ids = codec
+		}
+	}
+}
+
+// NewID returns a new document ID from the repository's ID codec
+func (r *BaseRepository[T]) NewID() string {
+	return r.options.ids.NewID()
+}
+
+// EncodeID returns the stored _id value for id, or ErrInvalidID if id is malformed for the collection
+func (r *BaseRepository[T]) EncodeID(id string) (interface{}, error) {
+	return r.options.ids.Encode(id)
+}
+
+// DecodeID returns the string form of a stored _id value
+func (r *BaseRepository[T]) DecodeID(value interface{}) string {
+	return r.options.ids.Decode(value)
+}
+
+// IDFilter returns a filter matching the document with id, or ErrInvalidID if id is malformed
+func (r *BaseRepository[T]) IDFilter(id string) (bson.M, error) {
+	value, err := r.EncodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"_id": value}, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/idgen"
+)
+
+func TestNewIDCodec(t *testing.T) {
+	t.Run("Known codecs", func(t *testing.T) {
+		for name, want := range map[string]string{
+			"":         IDCodecString,
+			"string":   IDCodecString,
+			"ObjectID": IDCodecObjectID,
+		} {
+			codec, err := NewIDCodec(name, nil)
+			require.NoError(t, err, name)
+			assert.Equal(t, want, codec.Name(), name)
+		}
+	})
+
+	t.Run("Unknown codec", func(t *testing.T) {
+		_, err := NewIDCodec("uuid", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestStringIDCodec(t *testing.T) {
+	codec := StringIDCodec(idgen.Func(func() string { return "user-1" }))
+
+	t.Run("New IDs come from the generator", func(t *testing.T) {
+		assert.Equal(t, "user-1", codec.NewID())
+	})
+
+	t.Run("IDs are stored as is", func(t *testing.T) {
+		value, err := codec.Encode("user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", value)
+		assert.Equal(t, "user-1", codec.Decode(value))
+	})
+
+	t.Run("Hex IDs stay strings", func(t *testing.T) {
+		hex := primitive.NewObjectID().Hex()
+		value, err := codec.Encode(hex)
+		require.NoError(t, err)
+		assert.Equal(t, hex, value)
+	})
+
+	t.Run("Empty ID is invalid", func(t *testing.T) {
+		_, err := codec.Encode("")
+		assert.ErrorIs(t, err, ErrInvalidID)
+	})
+}
+
+func TestObjectIDCodec(t *testing.T) {
+	codec := ObjectIDCodec()
+
+	t.Run("Round trip", func(t *testing.T) {
+		id := codec.NewID()
+		value, err := codec.Encode(id)
+		require.NoError(t, err)
+		assert.IsType(t, primitive.ObjectID{}, value)
+		assert.Equal(t, id, codec.Decode(value))
+	})
+
+	t.Run("Non-hex IDs are invalid", func(t *testing.T) {
+		for _, id := range []string{"", "user-1", "0190c8a0-0000-7000-8000-000000000001"} {
+			_, err := codec.Encode(id)
+			assert.ErrorIs(t, err, ErrInvalidID, id)
+		}
+	})
+}
+
+func TestMockUserRepository_AssignsIDs(t *testing.T) {
+	repo := NewMockUserRepository()
+	user := &domain.User{Name: "Test User", Email: "test@example.com"}
+
+	require.NoError(t, repo.Create(context.Background(), user))
+	assert.NotEmpty(t, user.ID)
+
+	found, err := repo.GetByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, user.ID, found.ID)
+}
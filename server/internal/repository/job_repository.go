@@ -0,0 +1,528 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// Job statuses
+const (
+	// JobPending jobs wait for their RunAt time, including jobs waiting to be retried
+	JobPending = "pending"
+
+	// JobRunning jobs are claimed by a worker until their lease expires
+	JobRunning = "running"
+
+	// JobSucceeded jobs completed without error
+	JobSucceeded = "succeeded"
+
+	// JobDead jobs failed permanently or ran out of attempts
+	JobDead = "dead"
+)
+
+// MaxJobErrors is the number of failed attempts whose error a job keeps, latest last
+const MaxJobErrors = 20
+
+// JobError is the error of a failed attempt of a job
+type JobError struct {
+	Attempt int
+	Error   string
+	At      time.Time
+}
+
+// DeadJobFilter selects dead jobs; zero fields match every dead job
+type DeadJobFilter struct {
+	ID   string
+	Type string
+
+	// Before selects the jobs that died before it
+	Before time.Time
+}
+
+// JobStats counts the jobs of a type
+type JobStats struct {
+	Type string
+
+	// Pending jobs are due, Scheduled jobs wait for a later RunAt
+	Pending   int64
+	Scheduled int64
+	Running   int64
+	Dead      int64
+
+	// Succeeded counts the jobs that succeeded since a time, Failed the failed attempts since then
+	Succeeded int64
+	Failed    int64
+}
+
+// Job is a unit of background work stored in the jobs collection
+type Job struct {
+	ID          string
+	Type        string
+	Payload     []byte // JSON
+	Status      string
+
+	// Priority orders due jobs, highest first
+	Priority int
+
+	// Key deduplicates jobs: enqueuing a job with the key of a stored job fails with ErrAlreadyExists
+	Key string
+
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LockedUntil time.Time
+	LastError   string
+
+	// Errors holds the errors of the last MaxJobErrors failed attempts, oldest first
+	Errors []JobError
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// JobRepository stores background jobs and hands them out to workers
+// Claimed jobs are leased: a job whose worker dies is handed out again once its lease expires.
+type JobRepository interface {
+	// Enqueue stores a new pending job, assigning its ID; it returns ErrAlreadyExists when a job
+	// with the same Key is stored
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Claim leases the next due job of one of types for lease, highest priority then oldest RunAt
+	// first, or returns nil when none is due
+	Claim(ctx context.Context, types []string, lease time.Duration) (*Job, error)
+
+	// Complete marks a claimed job as succeeded
+	Complete(ctx context.Context, job *Job) error
+
+	// Retry releases a claimed job to run again at runAt, recording the error of the failed attempt
+	Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error
+
+	// Bury marks a claimed job as dead, recording the error of the failed attempt
+	Bury(ctx context.Context, job *Job, lastError string) error
+
+	// Get returns a job by ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// ListDead returns a page of the dead jobs matching filter, most recently dead first, and
+	// their total count
+	ListDead(ctx context.Context, filter DeadJobFilter, opts domain.ListOptions) ([]*Job, int64, error)
+
+	// Revive makes a dead job pending again to run at runAt with a fresh set of attempts, keeping its
+	// error history; it returns ErrNotFound unless the job is dead
+	Revive(ctx context.Context, id string, runAt time.Time) (*Job, error)
+
+	// DeleteDead removes the dead jobs matching filter, returning how many were removed
+	DeleteDead(ctx context.Context, filter DeadJobFilter) (int64, error)
+
+	// Stats counts the jobs of every stored type by status, with the jobs that succeeded and the
+	// attempts that failed since since
+	Stats(ctx context.Context, since time.Time) ([]*JobStats, error)
+}
+
+// jobRepositoryImpl is the MongoDB implementation of JobRepository
+type jobRepositoryImpl struct {
+	*BaseRepository[jobDocument]
+}
+
+// jobDocument represents the MongoDB document structure for jobs
+type jobDocument struct {
+	ID          interface{} `bson:"_id"`
+	Type        string      `bson:"type"`
+	Payload     string      `bson:"payload"`
+	Status      string      `bson:"status"`
+	Priority    int         `bson:"priority"`
+	Key         string      `bson:"key,omitempty"`
+	Attempts    int         `bson:"attempts"`
+	MaxAttempts int         `bson:"maxAttempts"`
+	RunAt       time.Time   `bson:"runAt"`
+	LockedUntil time.Time   `bson:"lockedUntil,omitempty"`
+	LastError   string      `bson:"lastError,omitempty"`
+
+	Errors    []jobErrorDocument `bson:"errors,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+// jobErrorDocument is the error of a failed attempt stored in a job document
+type jobErrorDocument struct {
+	Attempt int       `bson:"attempt"`
+	Error   string    `bson:"error"`
+	At      time.Time `bson:"at"`
+}
+
+// jobIndexes serve claiming due jobs, finding a type's jobs by status, listing dead jobs and
+// deduplicating keyed jobs
+var jobIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "status", Value: 1}, {Key: "priority", Value: -1}, {Key: "runAt", Value: 1}}},
+	{Keys: bson.D{{Key: "type", Value: 1}, {Key: "status", Value: 1}}},
+	{Keys: bson.D{{Key: "status", Value: 1}, {Key: "updatedAt", Value: -1}}},
+	{
+		Keys: bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"key": bson.M{"$exists": true}}),
+	},
+}
+
+// NewJobRepository creates a new JobRepository storing jobs in the jobs collection with IDs from ids
+func NewJobRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) JobRepository {
+	dbInstance := db.(*resources.DB)
+	collection := dbInstance.Collection("jobs")
+
+	return &jobRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[jobDocument](BaseRepositoryConfig{
+			Collection: collection,
+			EntityName: "job",
+		}, WithClock(clk), WithIDCodec(ids)),
+	}
+}
+
+// SyncCollection creates the indexes used to claim jobs
+func (r *jobRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.BaseRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, jobIndexes); err != nil {
+		return fmt.Errorf("failed to create job indexes: %w", err)
+	}
+	return nil
+}
+
+// Enqueue stores a new pending job, assigning its ID
+func (r *jobRepositoryImpl) Enqueue(ctx context.Context, job *Job) error {
+	now := r.Now()
+	id, err := r.EncodeID(r.NewID())
+	if err != nil {
+		return err
+	}
+	job.ID = r.DecodeID(id)
+	job.Status = JobPending
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	doc := toJobDocument(job)
+	doc.ID = id
+	_, err = r.InsertOne(ctx, &doc)
+	return err
+}
+
+// Claim leases the next due job, highest priority then oldest RunAt first
+// Running jobs whose lease expired are claimed again, counting another attempt.
+func (r *jobRepositoryImpl) Claim(ctx context.Context, types []string, lease time.Duration) (*Job, error) {
+	now := r.Now()
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": JobPending, "runAt": bson.M{"$lte": now}},
+			bson.M{"status": JobRunning, "lockedUntil": bson.M{"$lte": now}},
+		},
+	}
+	if len(types) > 0 {
+		filter["type"] = bson.M{"$in": types}
+	}
+	update := bson.M{
+		"$set": bson.M{"status": JobRunning, "lockedUntil": now.Add(lease), "updatedAt": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "runAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var doc jobDocument
+	err := r.Collection().FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return toJob(&doc), nil
+}
+
+// Complete marks a claimed job as succeeded
+func (r *jobRepositoryImpl) Complete(ctx context.Context, job *Job) error {
+	return r.release(ctx, job, bson.M{"status": JobSucceeded})
+}
+
+// Retry releases a claimed job to run again at runAt
+func (r *jobRepositoryImpl) Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error {
+	return r.release(ctx, job, bson.M{"status": JobPending, "runAt": runAt, "lastError": lastError})
+}
+
+// Bury marks a claimed job as dead
+func (r *jobRepositoryImpl) Bury(ctx context.Context, job *Job, lastError string) error {
+	return r.release(ctx, job, bson.M{"status": JobDead, "lastError": lastError})
+}
+
+// release applies set to a job still held by the claim that returned it, adding lastError to the
+// job's error history when set
+// It returns ErrNotFound when the lease expired and another worker claimed the job since.
+func (r *jobRepositoryImpl) release(ctx context.Context, job *Job, set bson.M) error {
+	now := r.Now()
+	set["updatedAt"] = now
+	filter, err := r.IDFilter(job.ID)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter["status"] = JobRunning
+	filter["attempts"] = job.Attempts
+	update := bson.M{"$set": set, "$unset": bson.M{"lockedUntil": ""}}
+	failure, failed := set["lastError"].(string)
+	if failed {
+		update["$push"] = bson.M{"errors": bson.M{
+			"$each":  bson.A{jobErrorDocument{Attempt: job.Attempts, Error: failure, At: now}},
+			"$slice": -MaxJobErrors,
+		}}
+	}
+	if err := r.UpdateOne(ctx, filter, update); err != nil {
+		return err
+	}
+	if failed {
+		job.Errors = appendJobError(job.Errors, JobError{Attempt: job.Attempts, Error: failure, At: now})
+	}
+
+	job.Status = set["status"].(string)
+	if runAt, ok := set["runAt"].(time.Time); ok {
+		job.RunAt = runAt
+	}
+	if lastError, ok := set["lastError"].(string); ok {
+		job.LastError = lastError
+	}
+	job.LockedUntil = time.Time{}
+	return nil
+}
+
+// Get returns a job by ID
+func (r *jobRepositoryImpl) Get(ctx context.Context, id string) (*Job, error) {
+	doc, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toJob(doc), nil
+}
+
+// ListDead returns a page of dead jobs, most recently dead first
+func (r *jobRepositoryImpl) ListDead(ctx context.Context, filter DeadJobFilter, opts domain.ListOptions) ([]*Job, int64, error) {
+	query, err := r.deadFilter(filter)
+	if err != nil {
+		return nil, 0, nil
+	}
+
+	total, err := r.Count(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOpts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}})
+	if opts.Offset > 0 {
+		findOpts.SetSkip(int64(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(int64(opts.Limit))
+	}
+	docs, err := r.Find(ctx, query, findOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make([]*Job, len(docs))
+	for i := range docs {
+		jobs[i] = toJob(&docs[i])
+	}
+	return jobs, total, nil
+}
+
+// Revive makes a dead job pending again
+func (r *jobRepositoryImpl) Revive(ctx context.Context, id string, runAt time.Time) (*Job, error) {
+	filter, err := r.IDFilter(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	filter["status"] = JobDead
+	update := bson.M{"$set": bson.M{
+		"status":    JobPending,
+		"attempts":  0,
+		"runAt":     runAt,
+		"updatedAt": r.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc jobDocument
+	err = r.Collection().FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revive job: %w", err)
+	}
+	return toJob(&doc), nil
+}
+
+// DeleteDead removes the dead jobs matching filter
+func (r *jobRepositoryImpl) DeleteDead(ctx context.Context, filter DeadJobFilter) (int64, error) {
+	query, err := r.deadFilter(filter)
+	if err != nil {
+		return 0, nil
+	}
+	return r.DeleteMany(ctx, query)
+}
+
+// deadFilter returns the query matching the dead jobs of filter; it fails for invalid IDs, which
+// match no job
+func (r *jobRepositoryImpl) deadFilter(filter DeadJobFilter) (bson.M, error) {
+	query := bson.M{}
+	if filter.ID != "" {
+		idFilter, err := r.IDFilter(filter.ID)
+		if err != nil {
+			return nil, err
+		}
+		query = idFilter
+	}
+	query["status"] = JobDead
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if !filter.Before.IsZero() {
+		query["updatedAt"] = bson.M{"$lt": filter.Before}
+	}
+	return query, nil
+}
+
+// Stats counts the jobs of every type with two aggregations: one over the jobs by status, one over
+// the errors recorded since since
+func (r *jobRepositoryImpl) Stats(ctx context.Context, since time.Time) ([]*JobStats, error) {
+	now := r.Now()
+	count := func(cond bson.M) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+	}
+	status := func(status string) bson.M {
+		return bson.M{"$eq": bson.A{"$status", status}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{JobPending, JobRunning, JobDead}}},
+			bson.M{"status": JobSucceeded, "updatedAt": bson.M{"$gte": since}},
+		}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$type",
+			"pending":   count(bson.M{"$and": bson.A{status(JobPending), bson.M{"$lte": bson.A{"$runAt", now}}}}),
+			"scheduled": count(bson.M{"$and": bson.A{status(JobPending), bson.M{"$gt": bson.A{"$runAt", now}}}}),
+			"running":   count(status(JobRunning)),
+			"dead":      count(status(JobDead)),
+			"succeeded": count(status(JobSucceeded)),
+		}}},
+	}
+	var counts []struct {
+		Type      string `bson:"_id"`
+		Pending   int64  `bson:"pending"`
+		Scheduled int64  `bson:"scheduled"`
+		Running   int64  `bson:"running"`
+		Dead      int64  `bson:"dead"`
+		Succeeded int64  `bson:"succeeded"`
+	}
+	if err := r.aggregate(ctx, pipeline, &counts); err != nil {
+		return nil, err
+	}
+
+	pipeline = mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"errors.at": bson.M{"$gte": since}}}},
+		{{Key: "$unwind", Value: "$errors"}},
+		{{Key: "$match", Value: bson.M{"errors.at": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$type", "failed": bson.M{"$sum": 1}}}},
+	}
+	var failures []struct {
+		Type   string `bson:"_id"`
+		Failed int64  `bson:"failed"`
+	}
+	if err := r.aggregate(ctx, pipeline, &failures); err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*JobStats)
+	stats := make([]*JobStats, 0, len(counts))
+	for _, c := range counts {
+		s := &JobStats{Type: c.Type, Pending: c.Pending, Scheduled: c.Scheduled, Running: c.Running, Dead: c.Dead, Succeeded: c.Succeeded}
+		byType[c.Type] = s
+		stats = append(stats, s)
+	}
+	for _, f := range failures {
+		s, ok := byType[f.Type]
+		if !ok {
+			s = &JobStats{Type: f.Type}
+			stats = append(stats, s)
+		}
+		s.Failed = f.Failed
+	}
+	return stats, nil
+}
+
+// aggregate runs pipeline on the jobs collection, decoding every result into results
+func (r *jobRepositoryImpl) aggregate(ctx context.Context, pipeline mongo.Pipeline, results interface{}) error {
+	cursor, err := r.Collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate job stats: %w", err)
+	}
+	if err := cursor.All(ctx, results); err != nil {
+		return fmt.Errorf("failed to decode job stats: %w", err)
+	}
+	return nil
+}
+
+// appendJobError adds a failed attempt to errors, keeping the last MaxJobErrors
+func appendJobError(errors []JobError, failure JobError) []JobError {
+	errors = append(errors, failure)
+	if len(errors) > MaxJobErrors {
+		errors = errors[len(errors)-MaxJobErrors:]
+	}
+	return errors
+}
+
+func toJob(doc *jobDocument) *Job {
+	var jobErrors []JobError
+	for _, e := range doc.Errors {
+		jobErrors = append(jobErrors, JobError{Attempt: e.Attempt, Error: e.Error, At: e.At})
+	}
+	return &Job{
+		ID:          decodeID(doc.ID),
+		Type:        doc.Type,
+		Payload:     []byte(doc.Payload),
+		Status:      doc.Status,
+		Priority:    doc.Priority,
+		Key:         doc.Key,
+		Attempts:    doc.Attempts,
+		MaxAttempts: doc.MaxAttempts,
+		RunAt:       doc.RunAt,
+		LockedUntil: doc.LockedUntil,
+		LastError:   doc.LastError,
+		Errors:      jobErrors,
+		CreatedAt:   doc.CreatedAt,
+		UpdatedAt:   doc.UpdatedAt,
+	}
+}
+
+func toJobDocument(job *Job) jobDocument {
+	return jobDocument{
+		Type:        job.Type,
+		Payload:     string(job.Payload),
+		Status:      job.Status,
+		Priority:    job.Priority,
+		Key:         job.Key,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		LockedUntil: job.LockedUntil,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"quizizz.com/pkg/clock"
+)
+
+// MockAuditRepository is an in-memory implementation of AuditRepository for testing
+type MockAuditRepository struct {
+	records []*AuditRecord
+	clock   clock.Clock
+	ids     IDCodec
+	mutex   sync.Mutex
+}
+
+// NewMockAuditRepository creates a new MockAuditRepository reading time from clk
+func NewMockAuditRepository(clk clock.Clock) *MockAuditRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockAuditRepository{clock: clk, ids: StringIDCodec(nil)}
+}
+
+// Record appends record to the log
+func (r *MockAuditRepository) Record(ctx context.Context, record *AuditRecord) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	record.ID = r.ids.NewID()
+	record.CreatedAt = r.clock.Now()
+	record.fillFromContext(ctx)
+	stored := *record
+	r.records = append(r.records, &stored)
+	return nil
+}
+
+// ListBySubject returns the records of subject, oldest first
+func (r *MockAuditRepository) ListBySubject(ctx context.Context, subject string) ([]*AuditRecord, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var records []*AuditRecord
+	for _, record := range r.records {
+		if record.Subject == subject {
+			found := *record
+			records = append(records, &found)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	return records, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/clock"
+)
+
+// MockDeviceRepository is an in-memory implementation of DeviceRepository for testing
+type MockDeviceRepository struct {
+	devices []*domain.Device
+	clock   clock.Clock
+	ids     IDCodec
+	mutex   sync.Mutex
+}
+
+// NewMockDeviceRepository creates a new MockDeviceRepository reading time from clk
+func NewMockDeviceRepository(clk clock.Clock) *MockDeviceRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockDeviceRepository{clock: clk, ids: StringIDCodec(nil)}
+}
+
+// Register stores a device, or updates the registration of its token
+func (r *MockDeviceRepository) Register(ctx context.Context, device *domain.Device) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	for _, stored := range r.devices {
+		if stored.Token != device.Token {
+			continue
+		}
+		moved := stored.UserID != device.UserID
+		if moved {
+			stored.UserID, stored.Topics, stored.CreatedAt = device.UserID, []string{}, now
+		}
+		stored.Platform, stored.Name, stored.UpdatedAt = device.Platform, device.Name, now
+		*device = *copyDevice(stored)
+		return moved, nil
+	}
+
+	device.ID = r.ids.NewID()
+	device.Topics = []string{}
+	device.CreatedAt, device.UpdatedAt = now, now
+	r.devices = append(r.devices, copyDevice(device))
+	return true, nil
+}
+
+// Get returns a device of a user
+func (r *MockDeviceRepository) Get(ctx context.Context, userID, id string) (*domain.Device, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	device := r.find(userID, id)
+	if device == nil {
+		return nil, ErrNotFound
+	}
+	return copyDevice(device), nil
+}
+
+// ListByUser returns the devices of a user, most recently registered first
+func (r *MockDeviceRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Device, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var devices []*domain.Device
+	for i := len(r.devices) - 1; i >= 0; i-- {
+		if r.devices[i].UserID == userID {
+			devices = append(devices, copyDevice(r.devices[i]))
+		}
+	}
+	sort.SliceStable(devices, func(i, j int) bool { return devices[i].CreatedAt.After(devices[j].CreatedAt) })
+	return devices, nil
+}
+
+// EachSubscriber calls fn with each device subscribed to topic
+func (r *MockDeviceRepository) EachSubscriber(ctx context.Context, topic string, fn func(*domain.Device) error) error {
+	r.mutex.Lock()
+	var devices []*domain.Device
+	for _, device := range r.devices {
+		if slices.Contains(device.Topics, topic) {
+			devices = append(devices, copyDevice(device))
+		}
+	}
+	r.mutex.Unlock()
+
+	for _, device := range devices {
+		if err := fn(device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe adds topic to the topics of a device of a user
+func (r *MockDeviceRepository) Subscribe(ctx context.Context, userID, id, topic string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	device := r.find(userID, id)
+	if device == nil {
+		return ErrNotFound
+	}
+	if !slices.Contains(device.Topics, topic) {
+		device.Topics = append(device.Topics, topic)
+	}
+	device.UpdatedAt = r.clock.Now()
+	return nil
+}
+
+// Unsubscribe removes topic from the topics of a device of a user
+func (r *MockDeviceRepository) Unsubscribe(ctx context.Context, userID, id, topic string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	device := r.find(userID, id)
+	if device == nil {
+		return ErrNotFound
+	}
+	device.Topics = slices.DeleteFunc(device.Topics, func(t string) bool { return t == topic })
+	device.UpdatedAt = r.clock.Now()
+	return nil
+}
+
+// Delete removes a device of a user
+func (r *MockDeviceRepository) Delete(ctx context.Context, userID, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(r.devices)
+	r.devices = slices.DeleteFunc(r.devices, func(d *domain.Device) bool { return d.ID == id && d.UserID == userID })
+	if len(r.devices) == n {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByTokens removes the devices of tokens
+func (r *MockDeviceRepository) DeleteByTokens(ctx context.Context, tokens []string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(r.devices)
+	r.devices = slices.DeleteFunc(r.devices, func(d *domain.Device) bool { return slices.Contains(tokens, d.Token) })
+	return int64(n - len(r.devices)), nil
+}
+
+// DeleteByUser removes every device of a user
+func (r *MockDeviceRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(r.devices)
+	r.devices = slices.DeleteFunc(r.devices, func(d *domain.Device) bool { return d.UserID == userID })
+	return int64(n - len(r.devices)), nil
+}
+
+// find returns the stored device with id of a user, or nil
+func (r *MockDeviceRepository) find(userID, id string) *domain.Device {
+	for _, device := range r.devices {
+		if device.ID == id && device.UserID == userID {
+			return device
+		}
+	}
+	return nil
+}
+
+// copyDevice returns a copy of device not sharing its topics
+func copyDevice(device *domain.Device) *domain.Device {
+	c := *device
+	c.Topics = append([]string{}, device.Topics...)
+	return &c
+}
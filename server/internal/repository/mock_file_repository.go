@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quizizz.com/pkg/clock"
+)
+
+// MockFileRepository is an in-memory implementation of FileRepository for testing
+// Files get ObjectID hex IDs like their GridFS counterparts.
+type MockFileRepository struct {
+	files map[string]*mockFile
+	clock clock.Clock
+	mutex sync.RWMutex
+}
+
+// mockFile is a file stored by MockFileRepository
+type mockFile struct {
+	info FileInfo
+	data []byte
+}
+
+// NewMockFileRepository creates a new MockFileRepository reading time from clk
+func NewMockFileRepository(clk clock.Clock) *MockFileRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockFileRepository{
+		files: make(map[string]*mockFile),
+		clock: clk,
+	}
+}
+
+// Upload stores the contents of source under filename and returns the new file ID
+func (r *MockFileRepository) Upload(ctx context.Context, filename string, source io.Reader, metadata map[string]interface{}) (string, error) {
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return "", err
+	}
+	return r.store(filename, data, metadata), nil
+}
+
+// OpenUploadStream returns a writer for streaming a new file; the file is committed on Close
+func (r *MockFileRepository) OpenUploadStream(ctx context.Context, filename string, metadata map[string]interface{}) (string, io.WriteCloser, error) {
+	id := primitive.NewObjectID().Hex()
+	return id, &mockUploadStream{commit: func(data []byte) {
+		r.storeAs(id, filename, data, metadata)
+	}}, nil
+}
+
+// Download writes the contents of the file to dst and returns the number of bytes written
+func (r *MockFileRepository) Download(ctx context.Context, id string, dst io.Writer) (int64, error) {
+	stream, _, err := r.OpenDownloadStream(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+	return io.Copy(dst, stream)
+}
+
+// OpenDownloadStream returns a reader over the file contents along with its info
+func (r *MockFileRepository) OpenDownloadStream(ctx context.Context, id string) (io.ReadCloser, *FileInfo, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	file, ok := r.files[id]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	info := file.info
+	return io.NopCloser(bytes.NewReader(file.data)), &info, nil
+}
+
+// Stat returns the info of a file without reading its contents
+func (r *MockFileRepository) Stat(ctx context.Context, id string) (*FileInfo, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	file, ok := r.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	info := file.info
+	return &info, nil
+}
+
+// Delete removes a file
+func (r *MockFileRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.files[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.files, id)
+	return nil
+}
+
+// Len returns the number of stored files
+func (r *MockFileRepository) Len() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.files)
+}
+
+// store stores a new file and returns its ID
+func (r *MockFileRepository) store(filename string, data []byte, metadata map[string]interface{}) string {
+	id := primitive.NewObjectID().Hex()
+	r.storeAs(id, filename, data, metadata)
+	return id
+}
+
+// storeAs stores a file under id
+func (r *MockFileRepository) storeAs(id, filename string, data []byte, metadata map[string]interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.files[id] = &mockFile{
+		info: FileInfo{
+			ID:         id,
+			Filename:   filename,
+			Length:     int64(len(data)),
+			UploadDate: r.clock.Now(),
+			Metadata:   metadata,
+		},
+		data: data,
+	}
+}
+
+// mockUploadStream buffers an upload until it is closed
+type mockUploadStream struct {
+	buf    bytes.Buffer
+	commit func([]byte)
+}
+
+func (s *mockUploadStream) Write(p []byte) (int, error) { return s.buf.Write(p) }
+
+func (s *mockUploadStream) Close() error {
+	s.commit(s.buf.Bytes())
+	return nil
+}
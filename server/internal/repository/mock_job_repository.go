@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/clock"
+)
+
+// MockJobRepository is an in-memory implementation of JobRepository for testing
+type MockJobRepository struct {
+	jobs  map[string]*Job
+	clock clock.Clock
+	ids   IDCodec
+	mutex sync.Mutex
+}
+
+// NewMockJobRepository creates a new MockJobRepository reading time from clk
+func NewMockJobRepository(clk clock.Clock) *MockJobRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockJobRepository{
+		jobs:  make(map[string]*Job),
+		clock: clk,
+		ids:   StringIDCodec(nil),
+	}
+}
+
+// Enqueue stores a new pending job
+func (r *MockJobRepository) Enqueue(ctx context.Context, job *Job) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job.Key != "" {
+		for _, stored := range r.jobs {
+			if stored.Key == job.Key {
+				return ErrAlreadyExists
+			}
+		}
+	}
+
+	now := r.clock.Now()
+	job.ID = r.ids.NewID()
+	job.Status = JobPending
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	r.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// Claim leases the next due job of one of types
+func (r *MockJobRepository) Claim(ctx context.Context, types []string, lease time.Duration) (*Job, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	var due []*Job
+	for _, job := range r.jobs {
+		if len(types) > 0 && !contains(types, job.Type) {
+			continue
+		}
+		pending := job.Status == JobPending && !job.RunAt.After(now)
+		expired := job.Status == JobRunning && !job.LockedUntil.After(now)
+		if pending || expired {
+			due = append(due, job)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].Priority != due[j].Priority {
+			return due[i].Priority > due[j].Priority
+		}
+		return due[i].RunAt.Before(due[j].RunAt)
+	})
+
+	job := due[0]
+	job.Status = JobRunning
+	job.LockedUntil = now.Add(lease)
+	job.Attempts++
+	job.UpdatedAt = now
+
+	return copyJob(job), nil
+}
+
+// Complete marks a claimed job as succeeded
+func (r *MockJobRepository) Complete(ctx context.Context, job *Job) error {
+	return r.release(job, func(stored *Job) {
+		stored.Status = JobSucceeded
+	})
+}
+
+// Retry releases a claimed job to run again at runAt
+func (r *MockJobRepository) Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error {
+	return r.release(job, func(stored *Job) {
+		stored.Status = JobPending
+		stored.RunAt = runAt
+		stored.LastError = lastError
+		stored.Errors = appendJobError(stored.Errors, JobError{Attempt: stored.Attempts, Error: lastError, At: r.clock.Now()})
+	})
+}
+
+// Bury marks a claimed job as dead
+func (r *MockJobRepository) Bury(ctx context.Context, job *Job, lastError string) error {
+	return r.release(job, func(stored *Job) {
+		stored.Status = JobDead
+		stored.LastError = lastError
+		stored.Errors = appendJobError(stored.Errors, JobError{Attempt: stored.Attempts, Error: lastError, At: r.clock.Now()})
+	})
+}
+
+// release applies fn to a job still held by the claim that returned it
+func (r *MockJobRepository) release(job *Job, fn func(*Job)) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.jobs[job.ID]
+	if !ok || stored.Status != JobRunning || stored.Attempts != job.Attempts {
+		return ErrNotFound
+	}
+
+	fn(stored)
+	stored.LockedUntil = time.Time{}
+	stored.UpdatedAt = r.clock.Now()
+	*job = *copyJob(stored)
+	return nil
+}
+
+// Get returns a job by ID
+func (r *MockJobRepository) Get(ctx context.Context, id string) (*Job, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyJob(job), nil
+}
+
+// ListDead returns a page of dead jobs, most recently dead first
+func (r *MockJobRepository) ListDead(ctx context.Context, filter DeadJobFilter, opts domain.ListOptions) ([]*Job, int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	dead := r.dead(filter)
+	sort.Slice(dead, func(i, j int) bool {
+		if !dead[i].UpdatedAt.Equal(dead[j].UpdatedAt) {
+			return dead[i].UpdatedAt.After(dead[j].UpdatedAt)
+		}
+		return dead[i].ID > dead[j].ID
+	})
+
+	total := int64(len(dead))
+	start := min(opts.Offset, len(dead))
+	end := len(dead)
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, len(dead))
+	}
+
+	jobs := make([]*Job, 0, end-start)
+	for _, job := range dead[start:end] {
+		jobs = append(jobs, copyJob(job))
+	}
+	return jobs, total, nil
+}
+
+// Revive makes a dead job pending again
+func (r *MockJobRepository) Revive(ctx context.Context, id string, runAt time.Time) (*Job, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok || job.Status != JobDead {
+		return nil, ErrNotFound
+	}
+	job.Status = JobPending
+	job.Attempts = 0
+	job.RunAt = runAt
+	job.UpdatedAt = r.clock.Now()
+	return copyJob(job), nil
+}
+
+// DeleteDead removes the dead jobs matching filter
+func (r *MockJobRepository) DeleteDead(ctx context.Context, filter DeadJobFilter) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	dead := r.dead(filter)
+	for _, job := range dead {
+		delete(r.jobs, job.ID)
+	}
+	return int64(len(dead)), nil
+}
+
+// Stats counts the jobs of every type by status
+func (r *MockJobRepository) Stats(ctx context.Context, since time.Time) ([]*JobStats, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	byType := make(map[string]*JobStats)
+	var stats []*JobStats
+	for _, job := range r.jobs {
+		s, ok := byType[job.Type]
+		if !ok {
+			s = &JobStats{Type: job.Type}
+			byType[job.Type] = s
+			stats = append(stats, s)
+		}
+
+		switch {
+		case job.Status == JobPending && !job.RunAt.After(now):
+			s.Pending++
+		case job.Status == JobPending:
+			s.Scheduled++
+		case job.Status == JobRunning:
+			s.Running++
+		case job.Status == JobDead:
+			s.Dead++
+		case job.Status == JobSucceeded && !job.UpdatedAt.Before(since):
+			s.Succeeded++
+		}
+		for _, e := range job.Errors {
+			if !e.At.Before(since) {
+				s.Failed++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// dead returns the dead jobs matching filter
+func (r *MockJobRepository) dead(filter DeadJobFilter) []*Job {
+	var dead []*Job
+	for _, job := range r.jobs {
+		if job.Status != JobDead ||
+			(filter.ID != "" && job.ID != filter.ID) ||
+			(filter.Type != "" && job.Type != filter.Type) ||
+			(!filter.Before.IsZero() && !job.UpdatedAt.Before(filter.Before)) {
+			continue
+		}
+		dead = append(dead, job)
+	}
+	return dead
+}
+
+func copyJob(job *Job) *Job {
+	c := *job
+	c.Errors = slices.Clone(job.Errors)
+	return &c
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
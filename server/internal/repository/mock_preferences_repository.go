@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/clock"
+)
+
+// MockPreferencesRepository is an in-memory implementation of PreferencesRepository for testing
+type MockPreferencesRepository struct {
+	preferences map[string]domain.UserPreferences
+	clock       clock.Clock
+	mutex       sync.RWMutex
+}
+
+// NewMockPreferencesRepository creates a new MockPreferencesRepository reading time from clk
+func NewMockPreferencesRepository(clk clock.Clock) *MockPreferencesRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockPreferencesRepository{
+		preferences: make(map[string]domain.UserPreferences),
+		clock:       clk,
+	}
+}
+
+// Get returns the stored preferences of a user, or nil
+func (r *MockPreferencesRepository) Get(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	prefs, ok := r.preferences[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &prefs, nil
+}
+
+// Put stores the settings of prefs if its version is still current
+func (r *MockPreferencesRepository) Put(ctx context.Context, prefs *domain.UserPreferences) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.preferences[prefs.UserID].Version != prefs.Version {
+		return ErrVersionConflict
+	}
+
+	prefs.UpdatedAt = r.clock.Now()
+	prefs.Version++
+	r.preferences[prefs.UserID] = *prefs
+	return nil
+}
+
+// Delete removes the preferences of a user
+func (r *MockPreferencesRepository) Delete(ctx context.Context, userID string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, ok := r.preferences[userID]
+	delete(r.preferences, userID)
+	return ok, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"quizizz.com/pkg/clock"
+)
+
+// MockPushReceiptRepository is an in-memory implementation of PushReceiptRepository for testing
+type MockPushReceiptRepository struct {
+	receipts []*PushReceipt
+	clock    clock.Clock
+	ids      IDCodec
+	mutex    sync.Mutex
+}
+
+// NewMockPushReceiptRepository creates a new MockPushReceiptRepository reading time from clk
+func NewMockPushReceiptRepository(clk clock.Clock) *MockPushReceiptRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockPushReceiptRepository{clock: clk, ids: StringIDCodec(nil)}
+}
+
+// Record stores receipts
+func (r *MockPushReceiptRepository) Record(ctx context.Context, receipts []*PushReceipt) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, receipt := range receipts {
+		receipt.ID = r.ids.NewID()
+		receipt.CreatedAt = r.clock.Now()
+		stored := *receipt
+		r.receipts = append(r.receipts, &stored)
+	}
+	return nil
+}
+
+// CountByStatus returns the number of receipts of a notification by status
+func (r *MockPushReceiptRepository) CountByStatus(ctx context.Context, notificationID string) (map[string]int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	counts := make(map[string]int64)
+	for _, receipt := range r.receipts {
+		if receipt.NotificationID == notificationID {
+			counts[receipt.Status]++
+		}
+	}
+	return counts, nil
+}
+
+// ListByUser returns the receipts of a user, oldest first
+func (r *MockPushReceiptRepository) ListByUser(ctx context.Context, userID string) ([]*PushReceipt, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var receipts []*PushReceipt
+	for _, receipt := range r.receipts {
+		if receipt.UserID == userID {
+			found := *receipt
+			receipts = append(receipts, &found)
+		}
+	}
+	return receipts, nil
+}
+
+// DeleteByUser removes every receipt of a user
+func (r *MockPushReceiptRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(r.receipts)
+	r.receipts = slices.DeleteFunc(r.receipts, func(receipt *PushReceipt) bool { return receipt.UserID == userID })
+	return int64(n - len(r.receipts)), nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"quizizz.com/pkg/clock"
+)
+
+// MockSagaRepository is an in-memory implementation of SagaRepository for testing
+type MockSagaRepository struct {
+	sagas map[string]*Saga
+	clock clock.Clock
+	ids   IDCodec
+	mutex sync.Mutex
+}
+
+// NewMockSagaRepository creates a new MockSagaRepository reading time from clk
+func NewMockSagaRepository(clk clock.Clock) *MockSagaRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockSagaRepository{
+		sagas: make(map[string]*Saga),
+		clock: clk,
+		ids:   StringIDCodec(nil),
+	}
+}
+
+// Create stores a new saga
+func (r *MockSagaRepository) Create(ctx context.Context, saga *Saga) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	saga.ID = r.ids.NewID()
+	saga.Version = 1
+	saga.CreatedAt = now
+	saga.UpdatedAt = now
+	r.sagas[saga.ID] = copySaga(saga)
+	return nil
+}
+
+// Get returns a saga by ID
+func (r *MockSagaRepository) Get(ctx context.Context, id string) (*Saga, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	saga, ok := r.sagas[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copySaga(saga), nil
+}
+
+// Save stores the progress of a saga if its version still matches
+func (r *MockSagaRepository) Save(ctx context.Context, saga *Saga) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.sagas[saga.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Version != saga.Version {
+		return ErrVersionConflict
+	}
+
+	saga.Version++
+	saga.UpdatedAt = r.clock.Now()
+	r.sagas[saga.ID] = copySaga(saga)
+	return nil
+}
+
+func copySaga(saga *Saga) *Saga {
+	c := *saga
+	c.Data = maps.Clone(saga.Data)
+	return &c
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"quizizz.com/pkg/clock"
+)
+
+// MockSessionRepository is an in-memory implementation of SessionRepository for testing
+type MockSessionRepository struct {
+	sessions map[string]*Session
+	clock    clock.Clock
+	ids      IDCodec
+	mutex    sync.Mutex
+}
+
+// NewMockSessionRepository creates a new MockSessionRepository reading time from clk
+func NewMockSessionRepository(clk clock.Clock) *MockSessionRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockSessionRepository{
+		sessions: make(map[string]*Session),
+		clock:    clk,
+		ids:      StringIDCodec(nil),
+	}
+}
+
+// Create stores a new session
+func (r *MockSessionRepository) Create(ctx context.Context, session *Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session.ID = r.ids.NewID()
+	session.CreatedAt = r.clock.Now()
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = session.CreatedAt
+	}
+
+	r.sessions[session.ID] = copySession(session)
+	return nil
+}
+
+// Get returns a session by ID; expired sessions are gone, as the TTL index removes them
+func (r *MockSessionRepository) Get(ctx context.Context, id string) (*Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || !r.clock.Now().Before(session.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return copySession(session), nil
+}
+
+// Rotate replaces the token hash of an active session, guarded by its current hash
+func (r *MockSessionRepository) Rotate(ctx context.Context, session *Session, currentHash, newHash string, expiresAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	stored, ok := r.sessions[session.ID]
+	if !ok || !stored.Active(now) || stored.TokenHash != currentHash {
+		return ErrNotFound
+	}
+
+	stored.PreviousTokenHashes = appendTokenHash(stored.PreviousTokenHashes, currentHash)
+	stored.TokenHash = newHash
+	stored.LastUsedAt = now
+	stored.ExpiresAt = expiresAt
+	if session.ClientIP != "" {
+		stored.ClientIP = session.ClientIP
+	}
+	if session.UserAgent != "" {
+		stored.UserAgent = session.UserAgent
+	}
+
+	*session = *copySession(stored)
+	return nil
+}
+
+// ListByUser returns the active sessions of a user, most recently used first
+func (r *MockSessionRepository) ListByUser(ctx context.Context, userID string) ([]*Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	var sessions []*Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, copySession(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// Revoke revokes an active session of a user
+func (r *MockSessionRepository) Revoke(ctx context.Context, userID, id, reason string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID || !session.Active(now) {
+		return ErrNotFound
+	}
+	session.RevokedAt = now
+	session.RevokedReason = reason
+	return nil
+}
+
+// RevokeAll revokes the active sessions of a user
+func (r *MockSessionRepository) RevokeAll(ctx context.Context, userID, reason string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.clock.Now()
+	var n int64
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Active(now) {
+			session.RevokedAt = now
+			session.RevokedReason = reason
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteByUser removes every session of a user
+func (r *MockSessionRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var n int64
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// copySession returns a copy of session not sharing its token hashes
+func copySession(session *Session) *Session {
+	c := *session
+	c.PreviousTokenHashes = append([]string(nil), session.PreviousTokenHashes...)
+	return &c
+}
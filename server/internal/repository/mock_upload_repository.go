@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/clock"
+)
+
+// MockUploadRepository is an in-memory implementation of UploadRepository for testing
+type MockUploadRepository struct {
+	uploads []*domain.Upload
+	clock   clock.Clock
+	ids     IDCodec
+	mutex   sync.Mutex
+}
+
+// NewMockUploadRepository creates a new MockUploadRepository reading time from clk
+func NewMockUploadRepository(clk clock.Clock) *MockUploadRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockUploadRepository{clock: clk, ids: StringIDCodec(nil)}
+}
+
+// Create stores a pending upload
+func (r *MockUploadRepository) Create(ctx context.Context, upload *domain.Upload) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	upload.ID = r.ids.NewID()
+	upload.Status = domain.UploadPending
+	upload.CreatedAt = r.clock.Now()
+	stored := *upload
+	r.uploads = append(r.uploads, &stored)
+	return nil
+}
+
+// Get returns an upload of a user
+func (r *MockUploadRepository) Get(ctx context.Context, userID, id string) (*domain.Upload, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	upload := r.find(userID, id)
+	if upload == nil {
+		return nil, ErrNotFound
+	}
+	c := *upload
+	return &c, nil
+}
+
+// Complete marks a pending upload of a user completed
+func (r *MockUploadRepository) Complete(ctx context.Context, userID, id, etag string) (*domain.Upload, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	upload := r.find(userID, id)
+	if upload == nil || upload.Status != domain.UploadPending {
+		return nil, ErrNotFound
+	}
+	upload.Status, upload.ETag, upload.CompletedAt = domain.UploadCompleted, etag, r.clock.Now()
+	c := *upload
+	return &c, nil
+}
+
+// Delete removes an upload of a user
+func (r *MockUploadRepository) Delete(ctx context.Context, userID, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(r.uploads)
+	r.uploads = slices.DeleteFunc(r.uploads, func(u *domain.Upload) bool { return u.ID == id && u.UserID == userID })
+	if len(r.uploads) == n {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListByUser returns the uploads of a user, most recent first
+func (r *MockUploadRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Upload, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var uploads []*domain.Upload
+	for i := len(r.uploads) - 1; i >= 0; i-- {
+		if r.uploads[i].UserID == userID {
+			c := *r.uploads[i]
+			uploads = append(uploads, &c)
+		}
+	}
+	sort.SliceStable(uploads, func(i, j int) bool { return uploads[i].CreatedAt.After(uploads[j].CreatedAt) })
+	return uploads, nil
+}
+
+// DeleteByUser removes every upload of a user
+func (r *MockUploadRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n := len(r.uploads)
+	r.uploads = slices.DeleteFunc(r.uploads, func(u *domain.Upload) bool { return u.UserID == userID })
+	return int64(n - len(r.uploads)), nil
+}
+
+// find returns the stored upload with id of a user, or nil
+func (r *MockUploadRepository) find(userID, id string) *domain.Upload {
+	for _, upload := range r.uploads {
+		if upload.ID == id && upload.UserID == userID {
+			return upload
+		}
+	}
+	return nil
+}
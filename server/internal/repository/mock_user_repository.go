@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"quizizz.com/internal/domain"
 )
@@ -16,18 +20,20 @@ var (
 // MockUserRepository is an in-memory implementation of UserRepository for testing
 type MockUserRepository struct {
 	users map[string]*domain.User
+	ids   IDCodec
 	mutex sync.RWMutex
 }
 
-// NewMockUserRepository creates a new MockUserRepository
+// NewMockUserRepository creates a new MockUserRepository assigning string UUIDv7 IDs
 func NewMockUserRepository() UserRepository {
 	return &MockUserRepository{
 		users: make(map[string]*domain.User),
+		ids:   StringIDCodec(nil),
 	}
 }
 
 // GetByID returns a user by ID
-func (r *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+func (r *MockUserRepository) GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -40,7 +46,7 @@ func (r *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.Us
 }
 
 // List returns all users
-func (r *MockUserRepository) List(ctx context.Context) ([]*domain.User, error) {
+func (r *MockUserRepository) List(ctx context.Context, fields ...string) ([]*domain.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -52,17 +58,77 @@ func (r *MockUserRepository) List(ctx context.Context) ([]*domain.User, error) {
 	return users, nil
 }
 
+// Count returns the number of users matching the filter
+func (r *MockUserRepository) Count(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if matchesFilter(user, filter) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Search returns the page of users matching the filter selected by opts, and the number of users matching it
+func (r *MockUserRepository) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := []*domain.User{}
+	for _, user := range r.users {
+		if matchesFilter(user, filter) {
+			users = append(users, user)
+		}
+	}
+	sortUsers(users, opts)
+
+	total := int64(len(users))
+	start := min(opts.Offset, len(users))
+	end := len(users)
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, end)
+	}
+
+	return users[start:end], total, nil
+}
+
+// Stream passes every user matching the filter to fn
+func (r *MockUserRepository) Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, user := range r.users {
+		if !matchesFilter(user, filter) {
+			continue
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Create adds a new user
 func (r *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	// Check if user already exists
-	if _, exists := r.users[user.ID]; exists {
+	if user.ID == "" {
+		user.ID = r.ids.NewID()
+	}
+
+	// Check if user already exists, by ID or by the unique email
+	if _, exists := r.users[user.ID]; exists || r.emailTaken(user.Email, user.ID) {
 		return ErrUserExists
 	}
 
 	// Make a copy to avoid external modifications
+	user.Version = 1
 	userCopy := *user
 	r.users[user.ID] = &userCopy
 
@@ -75,11 +141,26 @@ func (r *MockUserRepository) Update(ctx context.Context, user *domain.User) erro
 	defer r.mutex.Unlock()
 
 	// Check if user exists
-	if _, exists := r.users[user.ID]; !exists {
+	existing, exists := r.users[user.ID]
+	if !exists {
 		return ErrUserNotFound
 	}
 
-	// Make a copy to avoid external modifications
+	// Enforce the expected version when one is given
+	if user.Version > 0 && user.Version != existing.Version {
+		return ErrVersionConflict
+	}
+
+	if r.emailTaken(user.Email, user.ID) {
+		return ErrUserExists
+	}
+
+	// Make a copy to avoid external modifications; like the MongoDB update, only the profile changes
+	user.Version = existing.Version + 1
+	user.Roles = existing.Roles
+	user.Locked = existing.Locked
+	user.PasswordResetRequired = existing.PasswordResetRequired
+	user.Avatar = existing.Avatar
 	userCopy := *user
 	r.users[user.ID] = &userCopy
 
@@ -100,3 +181,137 @@ func (r *MockUserRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// SetLocked locks or unlocks a user
+func (r *MockUserRepository) SetLocked(ctx context.Context, id string, locked bool) error {
+	return r.update(id, func(user *domain.User) { user.Locked = locked })
+}
+
+// SetPasswordResetRequired sets whether a user must reset their password
+func (r *MockUserRepository) SetPasswordResetRequired(ctx context.Context, id string, required bool) error {
+	return r.update(id, func(user *domain.User) { user.PasswordResetRequired = required })
+}
+
+// SetAvatar sets or removes the avatar of a user
+func (r *MockUserRepository) SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error {
+	return r.update(id, func(user *domain.User) { user.Avatar = avatar })
+}
+
+// update applies fn to a copy of a user and stores it with the next version
+func (r *MockUserRepository) update(id string, fn func(*domain.User)) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.users[id]
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	userCopy := *existing
+	fn(&userCopy)
+	userCopy.Version++
+	r.users[id] = &userCopy
+	return nil
+}
+
+// Stats returns the user counts, the signups per UTC day since since and the recent newest signups
+func (r *MockUserRepository) Stats(ctx context.Context, since time.Time, recent int) (*domain.UserStats, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := &domain.UserStats{SignupsByDay: []domain.DailyCount{}, RecentSignups: []*domain.User{}}
+	users := make([]*domain.User, 0, len(r.users))
+	days := make(map[string]int64)
+	for _, user := range r.users {
+		users = append(users, user)
+		stats.Total++
+		if user.Locked {
+			stats.Locked++
+		}
+		if !user.CreatedAt.Before(since) {
+			days[user.CreatedAt.UTC().Format(time.DateOnly)]++
+		}
+	}
+
+	for _, date := range slices.Sorted(maps.Keys(days)) {
+		stats.SignupsByDay = append(stats.SignupsByDay, domain.DailyCount{Date: date, Count: days[date]})
+	}
+	sortUsers(users, domain.ListOptions{})
+	stats.RecentSignups = users[:min(max(recent, 0), len(users))]
+	return stats, nil
+}
+
+// matchesFilter reports whether user matches filter
+func matchesFilter(user *domain.User, filter domain.UserFilter) bool {
+	if filter.Name != "" && !strings.Contains(strings.ToLower(user.Name.String()), strings.ToLower(filter.Name)) {
+		return false
+	}
+	if filter.Email != "" && user.Email != filter.Email {
+		return false
+	}
+	if filter.Query != "" && !matchesText(user.Name.String(), filter.Query) {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && user.CreatedAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !user.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	if filter.Role != "" && !user.HasRole(filter.Role) {
+		return false
+	}
+	if filter.Locked != nil && user.Locked != *filter.Locked {
+		return false
+	}
+	return true
+}
+
+// matchesText reports whether text contains any word of query, approximating a MongoDB text search
+func matchesText(text, query string) bool {
+	words := strings.Fields(strings.ToLower(text))
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if slices.Contains(words, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortUsers sorts users like the MongoDB repository: by opts.Sort, newest first by default, then by ID
+func sortUsers(users []*domain.User, opts domain.ListOptions) {
+	descending := opts.Descending
+	compare := func(a, b *domain.User) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+	switch opts.Sort {
+	case domain.UserSortName:
+		compare = func(a, b *domain.User) int { return strings.Compare(a.Name.String(), b.Name.String()) }
+	case domain.UserSortEmail:
+		compare = func(a, b *domain.User) int { return strings.Compare(a.Email.String(), b.Email.String()) }
+	case domain.UserSortCreatedAt:
+	default:
+		descending = true
+	}
+
+	slices.SortFunc(users, func(a, b *domain.User) int {
+		c := compare(a, b)
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if descending {
+			return -c
+		}
+		return c
+	})
+}
+
+// emailTaken reports whether a user other than id has email, like the unique email index
+func (r *MockUserRepository) emailTaken(email domain.Email, id string) bool {
+	for _, user := range r.users {
+		if user.ID != id && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -95,6 +96,56 @@ func TestMockUserRepository_List(t *testing.T) {
 	})
 }
 
+func TestMockUserRepository_Search(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	repo := NewMockUserRepository()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"Ada Lovelace", "Alan Turing", "Grace Hopper", "Ada Byron"} {
+		require.NoError(t, repo.Create(ctx, &domain.User{
+			Name:      domain.UserName(name),
+			Email:     domain.Email(strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com"),
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	names := func(users []*domain.User) []string {
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name.String())
+		}
+		return names
+	}
+
+	t.Run("Newest first by default", func(t *testing.T) {
+		users, total, err := repo.Search(ctx, domain.UserFilter{}, domain.ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+		assert.Equal(t, []string{"Ada Byron", "Grace Hopper"}, names(users))
+	})
+
+	t.Run("Text search and sort", func(t *testing.T) {
+		users, total, err := repo.Search(ctx, domain.UserFilter{Query: "ada"}, domain.ListOptions{Sort: domain.UserSortName})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, []string{"Ada Byron", "Ada Lovelace"}, names(users))
+	})
+
+	t.Run("Creation time range", func(t *testing.T) {
+		filter := domain.UserFilter{CreatedAfter: start.Add(time.Hour), CreatedBefore: start.Add(3 * time.Hour)}
+		users, _, err := repo.Search(ctx, filter, domain.ListOptions{Sort: domain.UserSortCreatedAt})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Alan Turing", "Grace Hopper"}, names(users))
+	})
+
+	t.Run("Page past the end", func(t *testing.T) {
+		users, total, err := repo.Search(ctx, domain.UserFilter{}, domain.ListOptions{Offset: 10, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+		assert.Empty(t, users)
+	})
+}
+
 func TestMockUserRepository_Create(t *testing.T) {
 	// Setup
 	repo := NewMockUserRepository()
@@ -124,6 +175,15 @@ func TestMockUserRepository_Create(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, ErrUserExists, err)
 	})
+
+	// Test duplicate email
+	t.Run("Create user with existing email", func(t *testing.T) {
+		err := repo.Create(context.Background(), &domain.User{
+			Name:  "Other User",
+			Email: user.Email,
+		})
+		assert.Equal(t, ErrUserExists, err)
+	})
 }
 
 func TestMockUserRepository_Update(t *testing.T) {
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/clock"
+)
+
+// MockUserSummaryRepository is an in-memory implementation of UserSummaryRepository for testing,
+// computing summaries from the given source repositories
+type MockUserSummaryRepository struct {
+	summaries map[string]*UserSummary
+	users     UserRepository
+	sessions  SessionRepository
+	devices   DeviceRepository
+	clock     clock.Clock
+	mutex     sync.Mutex
+}
+
+// NewMockUserSummaryRepository creates a new MockUserSummaryRepository summarizing the users,
+// sessions and devices of the given repositories
+func NewMockUserSummaryRepository(users UserRepository, sessions SessionRepository, devices DeviceRepository, clk clock.Clock) *MockUserSummaryRepository {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &MockUserSummaryRepository{
+		summaries: make(map[string]*UserSummary),
+		users:     users,
+		sessions:  sessions,
+		devices:   devices,
+		clock:     clk,
+	}
+}
+
+// Get returns the summary of a user
+func (r *MockUserSummaryRepository) Get(ctx context.Context, userID string) (*UserSummary, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	summary, ok := r.summaries[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *summary
+	return &c, nil
+}
+
+// List returns a page of summaries sorted like the MongoDB implementation
+func (r *MockUserSummaryRepository) List(ctx context.Context, opts domain.ListOptions) ([]*UserSummary, int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	summaries := make([]*UserSummary, 0, len(r.summaries))
+	for _, summary := range r.summaries {
+		c := *summary
+		summaries = append(summaries, &c)
+	}
+	_, known := userSummaryFields[opts.Sort]
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if opts.Descending || !known {
+			a, b = b, a
+		}
+		switch opts.Sort {
+		case "sessions":
+			if a.Sessions != b.Sessions {
+				return a.Sessions < b.Sessions
+			}
+		case "devices":
+			if a.Devices != b.Devices {
+				return a.Devices < b.Devices
+			}
+		case "name":
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case "email":
+			if a.Email != b.Email {
+				return a.Email < b.Email
+			}
+		case "last_seen_at":
+			if !a.LastSeenAt.Equal(b.LastSeenAt) {
+				return a.LastSeenAt.Before(b.LastSeenAt)
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.UserID < b.UserID
+	})
+
+	total := int64(len(summaries))
+	start := min(opts.Offset, len(summaries))
+	end := len(summaries)
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, len(summaries))
+	}
+	return summaries[start:end], total, nil
+}
+
+// Refresh recomputes the summary of a user from the source repositories
+func (r *MockUserSummaryRepository) Refresh(ctx context.Context, userID string) error {
+	user, err := r.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.summaries, userID)
+		return nil
+	}
+
+	summary, err := r.summarize(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summaries[userID] = summary
+	return nil
+}
+
+// Rebuild recomputes every summary from the source repositories
+func (r *MockUserSummaryRepository) Rebuild(ctx context.Context) (int64, error) {
+	users, err := r.users.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	summaries := make(map[string]*UserSummary, len(users))
+	for _, user := range users {
+		summary, err := r.summarize(ctx, user)
+		if err != nil {
+			return 0, err
+		}
+		summaries[user.ID] = summary
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.summaries = summaries
+	return int64(len(summaries)), nil
+}
+
+// Delete removes the summary of a user
+func (r *MockUserSummaryRepository) Delete(ctx context.Context, userID string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.summaries[userID]; !ok {
+		return 0, nil
+	}
+	delete(r.summaries, userID)
+	return 1, nil
+}
+
+// summarize computes the summary of user
+func (r *MockUserSummaryRepository) summarize(ctx context.Context, user *domain.User) (*UserSummary, error) {
+	sessions, err := r.sessions.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := r.devices.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &UserSummary{
+		UserID:      user.ID,
+		Name:        string(user.Name),
+		Email:       string(user.Email),
+		Roles:       user.Roles,
+		Locked:      user.Locked,
+		CreatedAt:   user.CreatedAt,
+		Sessions:    int64(len(sessions)),
+		Devices:     int64(len(devices)),
+		RefreshedAt: r.clock.Now(),
+	}
+	for _, session := range sessions {
+		if session.LastUsedAt.After(summary.LastSeenAt) {
+			summary.LastSeenAt = session.LastUsedAt
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// PreferencesRepository defines the interface for user preferences data access
+// Preferences are keyed by the ID of their user; users without stored preferences have none.
+type PreferencesRepository interface {
+	// Get returns the stored preferences of a user, or nil when the user has not set any
+	Get(ctx context.Context, userID string) (*domain.UserPreferences, error)
+
+	// Put stores the settings of prefs and increments its version
+	// The write only applies if the stored version still is prefs.Version, where version 0 means
+	// no preferences are stored yet; otherwise ErrVersionConflict is returned.
+	Put(ctx context.Context, prefs *domain.UserPreferences) error
+
+	// Delete removes the preferences of a user, reporting whether there were any
+	Delete(ctx context.Context, userID string) (bool, error)
+}
+
+// preferencesRepositoryImpl is the MongoDB implementation of PreferencesRepository
+type preferencesRepositoryImpl struct {
+	*CachedRepository[preferencesDocument]
+}
+
+// preferencesDocument represents the MongoDB document structure for user preferences
+// ID is the ID of the user, encoded with the codec of the users collection.
+type preferencesDocument struct {
+	ID        interface{}               `bson:"_id"`
+	Settings  domain.PreferenceSettings `bson:"settings"`
+	UpdatedAt time.Time                 `bson:"updatedAt"`
+	Version   int64                     `bson:"version"`
+}
+
+// preferencesSchema is the $jsonSchema validator for the user_preferences collection
+// It mirrors PreferenceSettings.Validate so writes bypassing the service are held to the same rules.
+var preferencesSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"settings", "updatedAt", "version"},
+	"properties": bson.M{
+		"settings": bson.M{
+			"bsonType": "object",
+			"properties": bson.M{
+				"theme":  bson.M{"enum": toBSONArray(domain.Themes)},
+				"locale": bson.M{"bsonType": "string", "pattern": "^[a-z]{2,3}(-[A-Z]{2})?$"},
+				"notifications": bson.M{
+					"bsonType": "object",
+					"properties": bson.M{
+						"email":  bson.M{"bsonType": "bool"},
+						"push":   bson.M{"bsonType": "bool"},
+						"digest": bson.M{"enum": toBSONArray(domain.DigestOptions)},
+					},
+				},
+			},
+		},
+		"updatedAt": bson.M{"bsonType": "date"},
+		"version":   bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 1},
+	},
+}
+
+// NewPreferencesRepository creates a new PreferencesRepository storing preferences in the
+// user_preferences collection, keyed by user IDs encoded with ids
+// Get lookups are cached according to cache; pass a zero CacheConfig to disable caching.
+func NewPreferencesRepository(db resources.DBResource, cache CacheConfig, clk clock.Clock, ids IDCodec) PreferencesRepository {
+	dbInstance := db.(*resources.DB)
+
+	base := NewBaseRepositoryWithConfig[preferencesDocument](BaseRepositoryConfig{
+		Collection: dbInstance.Collection("user_preferences"),
+		EntityName: "user_preferences",
+	}, WithSchema(preferencesSchema, ValidationMode(dbInstance.Config().SchemaValidation)), WithClock(clk), WithIDCodec(ids))
+
+	return &preferencesRepositoryImpl{
+		CachedRepository: NewCachedRepository(base, cache),
+	}
+}
+
+// Get returns the stored preferences of a user
+func (r *preferencesRepositoryImpl) Get(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	doc, err := r.FindByID(ctx, userID)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &domain.UserPreferences{
+		UserID:    userID,
+		Settings:  doc.Settings,
+		UpdatedAt: doc.UpdatedAt,
+		Version:   doc.Version,
+	}, nil
+}
+
+// Put stores the settings of prefs if its version is still current
+// The first write inserts the document, so two concurrent first writes conflict on its _id.
+func (r *preferencesRepositoryImpl) Put(ctx context.Context, prefs *domain.UserPreferences) error {
+	filter, err := r.IDFilter(prefs.UserID)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	now := r.Now()
+	if prefs.Version == 0 {
+		doc := preferencesDocument{ID: filter["_id"], Settings: prefs.Settings, UpdatedAt: now, Version: 1}
+		if _, err := r.InsertOne(ctx, &doc); err != nil {
+			if err == ErrAlreadyExists {
+				return ErrVersionConflict
+			}
+			return err
+		}
+	} else {
+		filter["version"] = prefs.Version
+		err = r.UpdateOne(ctx, filter, bson.M{
+			"$set": bson.M{"settings": prefs.Settings, "updatedAt": now},
+			"$inc": bson.M{"version": 1},
+		})
+		r.Invalidate(ctx, prefs.UserID)
+		if err != nil {
+			if err == ErrNotFound {
+				return ErrVersionConflict
+			}
+			return err
+		}
+	}
+
+	prefs.UpdatedAt = now
+	prefs.Version++
+	return nil
+}
+
+// Delete removes the preferences of a user
+func (r *preferencesRepositoryImpl) Delete(ctx context.Context, userID string) (bool, error) {
+	if err := r.DeleteByID(ctx, userID); err != nil {
+		if err == ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// toBSONArray returns values as a bson.A, e.g. for a schema enum
+func toBSONArray(values []string) bson.A {
+	a := make(bson.A, len(values))
+	for i, v := range values {
+		a[i] = v
+	}
+	return a
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Projection builds an inclusion projection for the given document fields.
+// The _id field is always returned by MongoDB unless explicitly excluded.
+// Returns nil when no fields are given so callers can pass it straight to SetProjection.
+func Projection(fields ...string) interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	projection := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+	return projection
+}
+
+// MapFields translates API/domain field names into document field names using the given mapping.
+// Unknown fields are dropped so that callers cannot project arbitrary document paths.
+func MapFields(fields []string, mapping map[string]string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	mapped := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if docField, ok := mapping[field]; ok {
+			mapped = append(mapped, docField)
+		}
+	}
+	return mapped
+}
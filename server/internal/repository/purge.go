@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+)
+
+// DeletedAtField is the conventional field holding the time a document was soft-deleted
+const DeletedAtField = "deletedAt"
+
+// ArchiveSuffix is appended to a collection's name to name the collection its documents are archived to
+const ArchiveSuffix = "_archive"
+
+// Purger removes documents from a collection in batches
+type Purger interface {
+	// Name returns the name of the collection
+	Name() string
+
+	// PurgeBatch deletes up to limit documents matching filter, copying them to the archive collection
+	// first when archive is set, and returns the number of documents deleted
+	PurgeBatch(ctx context.Context, filter bson.M, limit int, archive bool) (int64, error)
+}
+
+// purger implements Purger on raw documents
+type purger struct {
+	*BaseRepository[bson.M]
+}
+
+// NewPurger creates a Purger for collection
+func NewPurger(db resources.DBResource, collection string) Purger {
+	dbInstance := db.(*resources.DB)
+
+	return &purger{
+		BaseRepository: NewBaseRepositoryWithConfig[bson.M](BaseRepositoryConfig{
+			Collection: dbInstance.Collection(collection),
+			EntityName: collection,
+		}),
+	}
+}
+
+// Name returns the name of the collection
+func (p *purger) Name() string {
+	return p.Collection().Name()
+}
+
+// PurgeBatch deletes up to limit documents matching filter in _id order
+// Archived documents are inserted with their original _id, so a batch that failed after archiving
+// is archived again without duplicates when retried.
+func (r *BaseRepository[T]) PurgeBatch(ctx context.Context, filter bson.M, limit int, archive bool) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "BaseRepository.PurgeBatch",
+		trace.WithAttributes(
+			attribute.String("collection", r.collection.Name()),
+			attribute.Int("limit", limit),
+			attribute.Bool("archive", archive),
+		),
+	)
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	if !archive {
+		opts.SetProjection(bson.M{"_id": 1})
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to find documents to purge: %w", err)
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to decode documents to purge: %w", err)
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	ids := make(bson.A, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc["_id"]
+	}
+
+	if archive {
+		if err := r.archive(ctx, docs); err != nil {
+			span.RecordError(err)
+			logger.ErrorCtx(ctx, "Failed to archive documents",
+				zap.String("collection", r.collection.Name()),
+				zap.Error(err),
+			)
+			return 0, err
+		}
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to purge documents",
+			zap.String("collection", r.collection.Name()),
+			zap.Error(err),
+		)
+		return 0, fmt.Errorf("failed to purge documents: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int64("deleted", result.DeletedCount))
+	return result.DeletedCount, nil
+}
+
+// archive copies docs to the archive collection, ignoring documents archived by an earlier attempt
+func (r *BaseRepository[T]) archive(ctx context.Context, docs []bson.M) error {
+	archive := r.collection.Database().Collection(r.collection.Name() + ArchiveSuffix)
+
+	batch := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		batch[i] = doc
+	}
+
+	_, err := archive.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+	if err != nil && !isOnlyDuplicateKeyErrors(err) {
+		return fmt.Errorf("failed to archive documents: %w", err)
+	}
+	return nil
+}
+
+// isOnlyDuplicateKeyErrors reports whether every write of a bulk insert failed on a duplicate key
+func isOnlyDuplicateKeyErrors(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// PushReceipt records the outcome of sending a push notification to a device, for analytics
+type PushReceipt struct {
+	ID string
+
+	// NotificationID is the ID of the job that sent the notification
+	NotificationID string
+
+	UserID   string
+	DeviceID string
+	Platform string
+
+	// Topic is the topic the notification was sent to, empty for notifications sent to a user
+	Topic string
+
+	// Status is one of the resources.Push* delivery statuses
+	Status string
+
+	// ProviderID is the ID the provider gave the message
+	ProviderID string
+
+	Attempts int
+	Error    string
+
+	CreatedAt time.Time
+}
+
+// PushReceiptRepository stores push receipts; receipts are never updated, and only removed by retention
+type PushReceiptRepository interface {
+	// Record stores receipts, assigning their IDs and CreatedAt
+	Record(ctx context.Context, receipts []*PushReceipt) error
+
+	// CountByStatus returns the number of receipts of a notification by status
+	CountByStatus(ctx context.Context, notificationID string) (map[string]int64, error)
+
+	// ListByUser returns the receipts of a user, oldest first
+	ListByUser(ctx context.Context, userID string) ([]*PushReceipt, error)
+
+	// DeleteByUser removes every receipt of a user, returning how many were removed
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// pushReceiptRepositoryImpl is the MongoDB implementation of PushReceiptRepository
+type pushReceiptRepositoryImpl struct {
+	*BaseRepository[pushReceiptDocument]
+}
+
+// pushReceiptDocument represents the MongoDB document structure for push receipts
+type pushReceiptDocument struct {
+	ID             interface{} `bson:"_id"`
+	NotificationID string      `bson:"notificationId"`
+	UserID         string      `bson:"userId"`
+	DeviceID       string      `bson:"deviceId"`
+	Platform       string      `bson:"platform"`
+	Topic          string      `bson:"topic,omitempty"`
+	Status         string      `bson:"status"`
+	ProviderID     string      `bson:"providerId,omitempty"`
+	Attempts       int         `bson:"attempts"`
+	Error          string      `bson:"error,omitempty"`
+	CreatedAt      time.Time   `bson:"createdAt"`
+}
+
+// pushReceiptIndexes serve summarizing the receipts of a notification and listing those of a user
+var pushReceiptIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "notificationId", Value: 1}, {Key: "status", Value: 1}}},
+	{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: 1}}},
+}
+
+// NewPushReceiptRepository creates a new PushReceiptRepository storing receipts in the push_receipts collection with IDs from ids
+func NewPushReceiptRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) PushReceiptRepository {
+	dbInstance := db.(*resources.DB)
+
+	return &pushReceiptRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[pushReceiptDocument](BaseRepositoryConfig{
+			Collection: dbInstance.Collection("push_receipts"),
+			EntityName: "push receipt",
+		}, WithClock(clk), WithIDCodec(ids)),
+	}
+}
+
+// SyncCollection creates the indexes of the push_receipts collection
+func (r *pushReceiptRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.BaseRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, pushReceiptIndexes); err != nil {
+		return fmt.Errorf("failed to create push receipt indexes: %w", err)
+	}
+	return nil
+}
+
+// Record stores receipts
+func (r *pushReceiptRepositoryImpl) Record(ctx context.Context, receipts []*PushReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	now := r.Now()
+	docs := make([]*pushReceiptDocument, len(receipts))
+	for i, receipt := range receipts {
+		id, err := r.EncodeID(r.NewID())
+		if err != nil {
+			return err
+		}
+		receipt.ID = r.DecodeID(id)
+		receipt.CreatedAt = now
+		docs[i] = &pushReceiptDocument{
+			ID:             id,
+			NotificationID: receipt.NotificationID,
+			UserID:         receipt.UserID,
+			DeviceID:       receipt.DeviceID,
+			Platform:       receipt.Platform,
+			Topic:          receipt.Topic,
+			Status:         receipt.Status,
+			ProviderID:     receipt.ProviderID,
+			Attempts:       receipt.Attempts,
+			Error:          receipt.Error,
+			CreatedAt:      now,
+		}
+	}
+	_, err := r.InsertMany(ctx, docs)
+	return err
+}
+
+// CountByStatus counts the receipts of a notification by status
+func (r *pushReceiptRepositoryImpl) CountByStatus(ctx context.Context, notificationID string) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"notificationId": notificationID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.Collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate push receipts: %w", err)
+	}
+	var results []struct {
+		Status string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode push receipt counts: %w", err)
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, result := range results {
+		counts[result.Status] = result.Count
+	}
+	return counts, nil
+}
+
+// ListByUser returns the receipts of a user, oldest first
+func (r *pushReceiptRepositoryImpl) ListByUser(ctx context.Context, userID string) ([]*PushReceipt, error) {
+	docs, err := r.Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]*PushReceipt, len(docs))
+	for i, doc := range docs {
+		receipts[i] = &PushReceipt{
+			ID:             r.DecodeID(doc.ID),
+			NotificationID: doc.NotificationID,
+			UserID:         doc.UserID,
+			DeviceID:       doc.DeviceID,
+			Platform:       doc.Platform,
+			Topic:          doc.Topic,
+			Status:         doc.Status,
+			ProviderID:     doc.ProviderID,
+			Attempts:       doc.Attempts,
+			Error:          doc.Error,
+			CreatedAt:      doc.CreatedAt,
+		}
+	}
+	return receipts, nil
+}
+
+// DeleteByUser removes every receipt of a user
+func (r *pushReceiptRepositoryImpl) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	return r.DeleteMany(ctx, bson.M{"userId": userID})
+}
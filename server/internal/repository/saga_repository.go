@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// Saga statuses
+const (
+	// SagaRunning sagas are running their steps forward
+	SagaRunning = "running"
+
+	// SagaCompensating sagas had a step fail and are undoing the completed steps, last first
+	SagaCompensating = "compensating"
+
+	// SagaCompleted sagas ran every step
+	SagaCompleted = "completed"
+
+	// SagaCompensated sagas failed and undid every completed step
+	SagaCompensated = "compensated"
+
+	// SagaFailed sagas failed to compensate and need manual attention
+	SagaFailed = "failed"
+)
+
+// Saga is the persisted state of a running workflow, stored in the sagas collection
+type Saga struct {
+	ID       string
+	Workflow string
+	Status   string
+
+	// Step is the number of steps completed, and not compensated yet, from the first one
+	Step int
+
+	// Data is shared by the steps of the saga, e.g. the IDs a step created that its compensation removes
+	Data map[string]string
+
+	// Error is the error of the step that failed, or of the compensation that failed
+	Error string
+
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SagaRepository stores sagas
+type SagaRepository interface {
+	// Create stores a new saga, assigning its ID and version
+	Create(ctx context.Context, saga *Saga) error
+
+	// Get returns a saga by ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*Saga, error)
+
+	// Save stores the status, step, data and error of a saga and increments its version
+	// It returns ErrVersionConflict when the saga was saved since it was read.
+	Save(ctx context.Context, saga *Saga) error
+}
+
+// sagaRepositoryImpl is the MongoDB implementation of SagaRepository
+type sagaRepositoryImpl struct {
+	*BaseRepository[sagaDocument]
+}
+
+// sagaDocument represents the MongoDB document structure for sagas
+type sagaDocument struct {
+	ID        interface{}       `bson:"_id"`
+	Workflow  string            `bson:"workflow"`
+	Status    string            `bson:"status"`
+	Step      int               `bson:"step"`
+	Data      map[string]string `bson:"data,omitempty"`
+	Error     string            `bson:"error,omitempty"`
+	Version   int64             `bson:"version"`
+	CreatedAt time.Time         `bson:"createdAt"`
+	UpdatedAt time.Time         `bson:"updatedAt"`
+}
+
+// sagaIndexes serve finding the sagas of a workflow by status, e.g. those that failed
+var sagaIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workflow", Value: 1}, {Key: "status", Value: 1}, {Key: "updatedAt", Value: 1}}},
+}
+
+// NewSagaRepository creates a new SagaRepository storing sagas in the sagas collection with IDs from ids
+func NewSagaRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) SagaRepository {
+	dbInstance := db.(*resources.DB)
+
+	return &sagaRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[sagaDocument](BaseRepositoryConfig{
+			Collection: dbInstance.Collection("sagas"),
+			EntityName: "saga",
+		}, WithClock(clk), WithIDCodec(ids)),
+	}
+}
+
+// SyncCollection creates the indexes of the sagas collection
+func (r *sagaRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.BaseRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, sagaIndexes); err != nil {
+		return fmt.Errorf("failed to create saga indexes: %w", err)
+	}
+	return nil
+}
+
+// Create stores a new saga
+func (r *sagaRepositoryImpl) Create(ctx context.Context, saga *Saga) error {
+	now := r.Now()
+	id, err := r.EncodeID(r.NewID())
+	if err != nil {
+		return err
+	}
+	saga.ID = r.DecodeID(id)
+	saga.Version = 1
+	saga.CreatedAt = now
+	saga.UpdatedAt = now
+
+	_, err = r.InsertOne(ctx, &sagaDocument{
+		ID:        id,
+		Workflow:  saga.Workflow,
+		Status:    saga.Status,
+		Step:      saga.Step,
+		Data:      saga.Data,
+		Error:     saga.Error,
+		Version:   saga.Version,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	return err
+}
+
+// Get returns a saga by ID
+func (r *sagaRepositoryImpl) Get(ctx context.Context, id string) (*Saga, error) {
+	doc, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Saga{
+		ID:        decodeID(doc.ID),
+		Workflow:  doc.Workflow,
+		Status:    doc.Status,
+		Step:      doc.Step,
+		Data:      doc.Data,
+		Error:     doc.Error,
+		Version:   doc.Version,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}, nil
+}
+
+// Save stores the progress of a saga if its version still matches
+func (r *sagaRepositoryImpl) Save(ctx context.Context, saga *Saga) error {
+	now := r.Now()
+	filter, err := r.IDFilter(saga.ID)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter["version"] = saga.Version
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":    saga.Status,
+			"step":      saga.Step,
+			"data":      saga.Data,
+			"error":     saga.Error,
+			"updatedAt": now,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	if err := r.UpdateOne(ctx, filter, update); err != nil {
+		if err != ErrNotFound {
+			return err
+		}
+		if exists, _ := r.Exists(ctx, bson.M{"_id": filter["_id"]}); exists {
+			return ErrVersionConflict
+		}
+		return ErrNotFound
+	}
+
+	saga.Version++
+	saga.UpdatedAt = now
+	return nil
+}
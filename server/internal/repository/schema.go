@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// ValidationMode controls how MongoDB enforces a collection's JSON Schema
+type ValidationMode string
+
+const (
+	// ValidationStrict rejects writes that do not match the schema
+	ValidationStrict ValidationMode = "strict"
+	// ValidationWarn accepts invalid writes but logs them in the MongoDB server log
+	ValidationWarn ValidationMode = "warn"
+	// ValidationOff leaves the collection validator untouched
+	ValidationOff ValidationMode = "off"
+)
+
+// errCodeNamespaceNotFound is returned by collMod when the collection does not exist yet
+const errCodeNamespaceNotFound = 26
+
+// collectionSchema describes the JSON Schema declared for a collection
+type collectionSchema struct {
+	schema bson.M
+	mode   ValidationMode
+}
+
+// WithSchema declares a $jsonSchema validator for the collection, applied by SyncCollection via collMod
+// Unknown modes fall back to ValidationWarn so a typo never starts rejecting writes.
+func WithSchema(schema bson.M, mode ValidationMode) Option {
+	switch mode {
+	case ValidationStrict, ValidationWarn, ValidationOff:
+	default:
+		logger.Warn("Unknown schema validation mode, falling back to warn", zap.String("mode", string(mode)))
+		mode = ValidationWarn
+	}
+
+	return func(o *repositoryOptions) {
+		o.schema = &collectionSchema{schema: schema, mode: mode}
+	}
+}
+
+// validationAction returns the MongoDB validationAction for the mode
+func (s *collectionSchema) validationAction() string {
+	if s.mode == ValidationStrict {
+		return "error"
+	}
+	return "warn"
+}
+
+// applySchema sets the declared validator on the collection, creating the collection if needed
+func (r *BaseRepository[T]) applySchema(ctx context.Context) error {
+	schema := r.options.schema
+	if schema == nil || schema.mode == ValidationOff {
+		return nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "BaseRepository.ApplySchema",
+		trace.WithAttributes(
+			attribute.String("collection", r.collection.Name()),
+			attribute.String("mode", string(schema.mode)),
+		),
+	)
+	defer span.End()
+
+	validator := bson.M{"$jsonSchema": schema.schema}
+	cmd := bson.D{
+		{Key: "collMod", Value: r.collection.Name()},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "strict"},
+		{Key: "validationAction", Value: schema.validationAction()},
+	}
+
+	err := r.collection.Database().RunCommand(ctx, cmd).Err()
+
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == errCodeNamespaceNotFound {
+		opts := options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("strict").
+			SetValidationAction(schema.validationAction())
+		err = r.collection.Database().CreateCollection(ctx, r.collection.Name(), opts)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to apply collection schema",
+			zap.String("collection", r.collection.Name()),
+			zap.String("mode", string(schema.mode)),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to apply schema to %s: %w", r.collection.Name(), err)
+	}
+
+	logger.InfoCtx(ctx, "Applied collection schema",
+		zap.String("collection", r.collection.Name()),
+		zap.String("mode", string(schema.mode)),
+	)
+
+	return nil
+}
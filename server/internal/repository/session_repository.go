@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// MaxPreviousTokenHashes bounds the rotated-out token hashes kept per session to detect reuse
+const MaxPreviousTokenHashes = 20
+
+// Session is a device's sign-in, holding the refresh token issued to it
+// Refresh tokens are never stored; a session keeps the hash of the current token's secret and of the
+// tokens rotated out, so presenting an old token is recognized as reuse.
+type Session struct {
+	ID     string
+	UserID string
+
+	// Device is the name the client gave the device, e.g. "Pixel 8"
+	Device    string
+	UserAgent string
+	ClientIP  string
+
+	// TokenHash is the hash of the current refresh token's secret
+	TokenHash string
+
+	// PreviousTokenHashes are the hashes of the tokens rotated out, most recent last
+	PreviousTokenHashes []string
+
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+
+	// RevokedAt is when the session was revoked, zero while it is active
+	RevokedAt     time.Time
+	RevokedReason string
+}
+
+// Active reports whether the session is neither revoked nor expired at now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
+
+// SessionRepository stores sessions; they are removed once expired, revoked ones included
+type SessionRepository interface {
+	// Create stores a new session, assigning its ID and CreatedAt
+	Create(ctx context.Context, session *Session) error
+
+	// Get returns a session by ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*Session, error)
+
+	// Rotate replaces the token hash of an active session holding currentHash with newHash, moving
+	// currentHash to the previous ones and extending the session to expiresAt
+	// It returns ErrNotFound when the session is no longer active or its token was rotated since.
+	Rotate(ctx context.Context, session *Session, currentHash, newHash string, expiresAt time.Time) error
+
+	// ListByUser returns the active sessions of a user, most recently used first
+	ListByUser(ctx context.Context, userID string) ([]*Session, error)
+
+	// Revoke revokes an active session of a user for reason, or returns ErrNotFound
+	Revoke(ctx context.Context, userID, id, reason string) error
+
+	// RevokeAll revokes the active sessions of a user for reason, returning how many were revoked
+	RevokeAll(ctx context.Context, userID, reason string) (int64, error)
+
+	// DeleteByUser removes every session of a user, returning how many were removed
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// sessionRepositoryImpl is the MongoDB implementation of SessionRepository
+type sessionRepositoryImpl struct {
+	*BaseRepository[sessionDocument]
+}
+
+// sessionDocument represents the MongoDB document structure for sessions
+type sessionDocument struct {
+	ID                  interface{} `bson:"_id"`
+	UserID              string      `bson:"userId"`
+	Device              string      `bson:"device,omitempty"`
+	UserAgent           string      `bson:"userAgent,omitempty"`
+	ClientIP            string      `bson:"clientIp,omitempty"`
+	TokenHash           string      `bson:"tokenHash"`
+	PreviousTokenHashes []string    `bson:"previousTokenHashes,omitempty"`
+	CreatedAt           time.Time   `bson:"createdAt"`
+	LastUsedAt          time.Time   `bson:"lastUsedAt"`
+	RevokedAt           time.Time   `bson:"revokedAt,omitempty"`
+	RevokedReason       string      `bson:"revokedReason,omitempty"`
+	Expiring            `bson:",inline"`
+}
+
+// sessionIndexes serve listing the sessions of a user
+var sessionIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "lastUsedAt", Value: -1}}},
+}
+
+// NewSessionRepository creates a new SessionRepository storing sessions in the sessions collection with IDs from ids
+func NewSessionRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) SessionRepository {
+	dbInstance := db.(*resources.DB)
+
+	return &sessionRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[sessionDocument](BaseRepositoryConfig{
+			Collection: dbInstance.Collection("sessions"),
+			EntityName: "session",
+		}, WithClock(clk), WithIDCodec(ids), WithTTL(ExpiresAtField, 0)),
+	}
+}
+
+// SyncCollection creates the TTL index and the indexes used to list sessions
+func (r *sessionRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.BaseRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, sessionIndexes); err != nil {
+		return fmt.Errorf("failed to create session indexes: %w", err)
+	}
+	return nil
+}
+
+// Create stores a new session
+func (r *sessionRepositoryImpl) Create(ctx context.Context, session *Session) error {
+	id, err := r.EncodeID(r.NewID())
+	if err != nil {
+		return err
+	}
+	session.ID = r.DecodeID(id)
+	session.CreatedAt = r.Now()
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = session.CreatedAt
+	}
+
+	doc := toSessionDocument(session)
+	doc.ID = id
+	_, err = r.InsertOne(ctx, &doc)
+	return err
+}
+
+// Get returns a session by ID
+func (r *sessionRepositoryImpl) Get(ctx context.Context, id string) (*Session, error) {
+	doc, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.toSession(doc), nil
+}
+
+// Rotate replaces the token hash of an active session, guarded by its current hash
+func (r *sessionRepositoryImpl) Rotate(ctx context.Context, session *Session, currentHash, newHash string, expiresAt time.Time) error {
+	now := r.Now()
+	filter, err := r.IDFilter(session.ID)
+	if err != nil {
+		return ErrNotFound
+	}
+	filter["tokenHash"] = currentHash
+	filter["revokedAt"] = bson.M{"$exists": false}
+	filter[ExpiresAtField] = bson.M{"$gt": now}
+
+	set := bson.M{"tokenHash": newHash, "lastUsedAt": now, ExpiresAtField: expiresAt}
+	if session.ClientIP != "" {
+		set["clientIp"] = session.ClientIP
+	}
+	if session.UserAgent != "" {
+		set["userAgent"] = session.UserAgent
+	}
+	update := bson.M{
+		"$set":  set,
+		"$push": bson.M{"previousTokenHashes": bson.M{"$each": bson.A{currentHash}, "$slice": -MaxPreviousTokenHashes}},
+	}
+	if err := r.UpdateOne(ctx, filter, update); err != nil {
+		return err
+	}
+
+	session.PreviousTokenHashes = appendTokenHash(session.PreviousTokenHashes, currentHash)
+	session.TokenHash = newHash
+	session.LastUsedAt = now
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+// ListByUser returns the active sessions of a user, most recently used first
+func (r *sessionRepositoryImpl) ListByUser(ctx context.Context, userID string) ([]*Session, error) {
+	docs, err := r.Find(ctx, r.activeFilter(userID), options.Find().SetSort(bson.D{{Key: "lastUsedAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*Session, len(docs))
+	for i := range docs {
+		sessions[i] = r.toSession(&docs[i])
+	}
+	return sessions, nil
+}
+
+// Revoke revokes an active session of a user
+func (r *sessionRepositoryImpl) Revoke(ctx context.Context, userID, id, reason string) error {
+	filter, err := r.IDFilter(id)
+	if err != nil {
+		return ErrNotFound
+	}
+	for key, value := range r.activeFilter(userID) {
+		filter[key] = value
+	}
+	return r.UpdateOne(ctx, filter, r.revocation(reason))
+}
+
+// RevokeAll revokes the active sessions of a user
+func (r *sessionRepositoryImpl) RevokeAll(ctx context.Context, userID, reason string) (int64, error) {
+	return r.UpdateMany(ctx, r.activeFilter(userID), r.revocation(reason))
+}
+
+// DeleteByUser removes every session of a user
+func (r *sessionRepositoryImpl) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	return r.DeleteMany(ctx, bson.M{"userId": userID})
+}
+
+// activeFilter matches the active sessions of a user
+func (r *sessionRepositoryImpl) activeFilter(userID string) bson.M {
+	return bson.M{
+		"userId":       userID,
+		"revokedAt":    bson.M{"$exists": false},
+		ExpiresAtField: bson.M{"$gt": r.Now()},
+	}
+}
+
+// revocation returns the update revoking sessions for reason
+func (r *sessionRepositoryImpl) revocation(reason string) bson.M {
+	return bson.M{"$set": bson.M{"revokedAt": r.Now(), "revokedReason": reason}}
+}
+
+func (r *sessionRepositoryImpl) toSession(doc *sessionDocument) *Session {
+	return &Session{
+		ID:                  r.DecodeID(doc.ID),
+		UserID:              doc.UserID,
+		Device:              doc.Device,
+		UserAgent:           doc.UserAgent,
+		ClientIP:            doc.ClientIP,
+		TokenHash:           doc.TokenHash,
+		PreviousTokenHashes: doc.PreviousTokenHashes,
+		CreatedAt:           doc.CreatedAt,
+		LastUsedAt:          doc.LastUsedAt,
+		ExpiresAt:           doc.ExpiresAt,
+		RevokedAt:           doc.RevokedAt,
+		RevokedReason:       doc.RevokedReason,
+	}
+}
+
+func toSessionDocument(session *Session) sessionDocument {
+	return sessionDocument{
+		UserID:              session.UserID,
+		Device:              session.Device,
+		UserAgent:           session.UserAgent,
+		ClientIP:            session.ClientIP,
+		TokenHash:           session.TokenHash,
+		PreviousTokenHashes: session.PreviousTokenHashes,
+		CreatedAt:           session.CreatedAt,
+		LastUsedAt:          session.LastUsedAt,
+		RevokedAt:           session.RevokedAt,
+		RevokedReason:       session.RevokedReason,
+		Expiring:            Expiring{ExpiresAt: session.ExpiresAt},
+	}
+}
+
+// appendTokenHash appends hash to hashes, keeping the last MaxPreviousTokenHashes
+func appendTokenHash(hashes []string, hash string) []string {
+	hashes = append(hashes, hash)
+	if len(hashes) > MaxPreviousTokenHashes {
+		hashes = hashes[len(hashes)-MaxPreviousTokenHashes:]
+	}
+	return hashes
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// ExpiresAtField is the conventional field holding the time a document expires
+const ExpiresAtField = "expiresAt"
+
+// errCodeIndexOptionsConflict is returned when an index exists with the same keys but different options
+const errCodeIndexOptionsConflict = 85
+
+// Expiring can be embedded inline in documents stored in a TTL collection
+//
+//	type sessionDocument struct {
+//		ID                   primitive.ObjectID `bson:"_id,omitempty"`
+//		repository.Expiring `bson:",inline"`
+//	}
+type Expiring struct {
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// ExpireIn sets the document to expire ttl after now, usually the repository's Now()
+func (e *Expiring) ExpireIn(now time.Time, ttl time.Duration) {
+	e.ExpiresAt = now.Add(ttl)
+}
+
+// ttlIndex describes the TTL index declared for a collection
+type ttlIndex struct {
+	field       string
+	expireAfter time.Duration
+}
+
+// WithTTL declares a TTL index on field so MongoDB removes documents expireAfter past the time stored in it
+// Use WithTTL(ExpiresAtField, 0) to expire each document exactly at its expiresAt time
+func WithTTL(field string, expireAfter time.Duration) Option {
+	return func(o *repositoryOptions) {
+		o.ttl = &ttlIndex{field: field, expireAfter: expireAfter}
+	}
+}
+
+// name returns the name of the TTL index
+func (t *ttlIndex) name() string {
+	return t.field + "_ttl"
+}
+
+// model returns the index model for the TTL index
+func (t *ttlIndex) model() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: t.field, Value: 1}},
+		Options: options.Index().
+			SetName(t.name()).
+			SetExpireAfterSeconds(int32(t.expireAfter / time.Second)),
+	}
+}
+
+// ensureTTLIndex creates the declared TTL index, updating its expiry if it already exists with a different one
+func (r *BaseRepository[T]) ensureTTLIndex(ctx context.Context) error {
+	if r.options.ttl == nil {
+		return nil
+	}
+	ttl := r.options.ttl
+
+	ctx, span := r.tracer.Start(ctx, "BaseRepository.EnsureTTLIndex",
+		trace.WithAttributes(
+			attribute.String("collection", r.collection.Name()),
+			attribute.String("field", ttl.field),
+			attribute.Int64("expire_after_seconds", int64(ttl.expireAfter/time.Second)),
+		),
+	)
+	defer span.End()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, ttl.model())
+	if err == nil {
+		return nil
+	}
+
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Code != errCodeIndexOptionsConflict {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to create TTL index",
+			zap.String("collection", r.collection.Name()),
+			zap.String("field", ttl.field),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to create TTL index: %w", err)
+	}
+
+	// The index exists with a different expiry; update it in place instead of rebuilding it
+	cmd := bson.D{
+		{Key: "collMod", Value: r.collection.Name()},
+		{Key: "index", Value: bson.D{
+			{Key: "keyPattern", Value: bson.D{{Key: ttl.field, Value: 1}}},
+			{Key: "expireAfterSeconds", Value: int32(ttl.expireAfter / time.Second)},
+		}},
+	}
+	if err := r.collection.Database().RunCommand(ctx, cmd).Err(); err != nil {
+		span.RecordError(err)
+		logger.ErrorCtx(ctx, "Failed to update TTL index",
+			zap.String("collection", r.collection.Name()),
+			zap.String("field", ttl.field),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to update TTL index: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Updated TTL index expiry",
+		zap.String("collection", r.collection.Name()),
+		zap.String("field", ttl.field),
+		zap.Duration("expire_after", ttl.expireAfter),
+	)
+
+	return nil
+}
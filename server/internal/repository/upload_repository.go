@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// UploadRepository stores the uploads of files sent straight to object storage
+type UploadRepository interface {
+	// Create stores a pending upload, assigning its ID and creation time
+	Create(ctx context.Context, upload *domain.Upload) error
+
+	// Get returns an upload of a user, or ErrNotFound
+	Get(ctx context.Context, userID, id string) (*domain.Upload, error)
+
+	// Complete marks a pending upload of a user completed with the entity tag of its object, or
+	// returns ErrNotFound when it is not pending
+	Complete(ctx context.Context, userID, id, etag string) (*domain.Upload, error)
+
+	// Delete removes an upload of a user, or returns ErrNotFound
+	Delete(ctx context.Context, userID, id string) error
+
+	// ListByUser returns the uploads of a user, most recent first
+	ListByUser(ctx context.Context, userID string) ([]*domain.Upload, error)
+
+	// DeleteByUser removes every upload of a user, returning how many were removed
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// uploadRepositoryImpl is the MongoDB implementation of UploadRepository
+type uploadRepositoryImpl struct {
+	*BaseRepository[uploadDocument]
+}
+
+// uploadDocument represents the MongoDB document structure for uploads
+type uploadDocument struct {
+	ID          interface{} `bson:"_id"`
+	UserID      string      `bson:"userId"`
+	Key         string      `bson:"key"`
+	Filename    string      `bson:"filename"`
+	ContentType string      `bson:"contentType"`
+	Size        int64       `bson:"size"`
+	Status      string      `bson:"status"`
+	ETag        string      `bson:"etag,omitempty"`
+	CreatedAt   time.Time   `bson:"createdAt"`
+	ExpiresAt   time.Time   `bson:"expiresAt"`
+	CompletedAt time.Time   `bson:"completedAt,omitempty"`
+}
+
+// uploadIndexes serve listing the uploads of a user and purging abandoned pending uploads
+var uploadIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}}},
+}
+
+// NewUploadRepository creates a new UploadRepository storing uploads in the uploads collection with IDs from ids
+func NewUploadRepository(db resources.DBResource, clk clock.Clock, ids IDCodec) UploadRepository {
+	dbInstance := db.(*resources.DB)
+
+	return &uploadRepositoryImpl{
+		BaseRepository: NewBaseRepositoryWithConfig[uploadDocument](BaseRepositoryConfig{
+			Collection: dbInstance.Collection("uploads"),
+			EntityName: "upload",
+		}, WithClock(clk), WithIDCodec(ids)),
+	}
+}
+
+// SyncCollection creates the indexes of the uploads collection
+func (r *uploadRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.BaseRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, uploadIndexes); err != nil {
+		return fmt.Errorf("failed to create upload indexes: %w", err)
+	}
+	return nil
+}
+
+// Create stores a pending upload
+func (r *uploadRepositoryImpl) Create(ctx context.Context, upload *domain.Upload) error {
+	id, err := r.EncodeID(r.NewID())
+	if err != nil {
+		return err
+	}
+	doc := uploadDocument{
+		ID:          id,
+		UserID:      upload.UserID,
+		Key:         upload.Key,
+		Filename:    upload.Filename,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Status:      domain.UploadPending,
+		CreatedAt:   r.Now(),
+		ExpiresAt:   upload.ExpiresAt,
+	}
+	if _, err := r.InsertOne(ctx, &doc); err != nil {
+		return err
+	}
+	*upload = *r.toUpload(&doc)
+	return nil
+}
+
+// Get returns an upload of a user
+func (r *uploadRepositoryImpl) Get(ctx context.Context, userID, id string) (*domain.Upload, error) {
+	filter, err := r.userFilter(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := r.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return r.toUpload(doc), nil
+}
+
+// Complete marks a pending upload of a user completed
+func (r *uploadRepositoryImpl) Complete(ctx context.Context, userID, id, etag string) (*domain.Upload, error) {
+	filter, err := r.userFilter(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	pending := bson.M{"status": domain.UploadPending}
+	for key, value := range filter {
+		pending[key] = value
+	}
+	update := bson.M{"$set": bson.M{"status": domain.UploadCompleted, "etag": etag, "completedAt": r.Now()}}
+	if err := r.UpdateOne(ctx, pending, update); err != nil {
+		return nil, err
+	}
+
+	doc, err := r.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return r.toUpload(doc), nil
+}
+
+// Delete removes an upload of a user
+func (r *uploadRepositoryImpl) Delete(ctx context.Context, userID, id string) error {
+	filter, err := r.userFilter(userID, id)
+	if err != nil {
+		return err
+	}
+	return r.DeleteOne(ctx, filter)
+}
+
+// ListByUser returns the uploads of a user, most recent first
+func (r *uploadRepositoryImpl) ListByUser(ctx context.Context, userID string) ([]*domain.Upload, error) {
+	docs, err := r.Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := make([]*domain.Upload, len(docs))
+	for i := range docs {
+		uploads[i] = r.toUpload(&docs[i])
+	}
+	return uploads, nil
+}
+
+// DeleteByUser removes every upload of a user
+func (r *uploadRepositoryImpl) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	return r.DeleteMany(ctx, bson.M{"userId": userID})
+}
+
+// userFilter matches the upload with id of a user; invalid IDs return ErrNotFound
+func (r *uploadRepositoryImpl) userFilter(userID, id string) (bson.M, error) {
+	filter, err := r.IDFilter(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	filter["userId"] = userID
+	return filter, nil
+}
+
+func (r *uploadRepositoryImpl) toUpload(doc *uploadDocument) *domain.Upload {
+	return &domain.Upload{
+		ID:          r.DecodeID(doc.ID),
+		UserID:      doc.UserID,
+		Key:         doc.Key,
+		Filename:    doc.Filename,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		Status:      doc.Status,
+		ETag:        doc.ETag,
+		CreatedAt:   doc.CreatedAt,
+		ExpiresAt:   doc.ExpiresAt,
+		CompletedAt: doc.CompletedAt,
+	}
+}
@@ -2,57 +2,132 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"quizizz.com/internal/domain"
 	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
 )
 
 // UserRepository defines the interface for user data access
 type UserRepository interface {
-	GetByID(ctx context.Context, id string) (*domain.User, error)
-	List(ctx context.Context) ([]*domain.User, error)
+	GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error)
+	List(ctx context.Context, fields ...string) ([]*domain.User, error)
+	Count(ctx context.Context, filter domain.UserFilter) (int64, error)
+	Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error)
+	Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error
 	Create(ctx context.Context, user *domain.User) error
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id string) error
+
+	// SetLocked locks or unlocks a user, returning ErrUserNotFound for unknown users
+	SetLocked(ctx context.Context, id string, locked bool) error
+
+	// SetPasswordResetRequired sets whether a user must reset their password, returning ErrUserNotFound
+	// for unknown users
+	SetPasswordResetRequired(ctx context.Context, id string, required bool) error
+
+	// Stats returns the user counts, the signups per day since since and the recent newest signups
+	Stats(ctx context.Context, since time.Time, recent int) (*domain.UserStats, error)
+
+	// SetAvatar sets the avatar of a user, or removes it when avatar is nil, returning ErrUserNotFound
+	// for unknown users
+	SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error
 }
 
 // userRepositoryImpl is the MongoDB implementation of UserRepository
 type userRepositoryImpl struct {
-	*BaseRepository[userDocument]
+	*CachedRepository[userDocument]
 	db *resources.DB
 }
 
 // userDocument represents the MongoDB document structure for users
+// ID holds the value produced by the repository's IDCodec (a string or an ObjectID); names and
+// emails are validated as they are decoded.
 type userDocument struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Name      string             `bson:"name"`
-	Email     string             `bson:"email"`
-	CreatedAt time.Time          `bson:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt"`
+	ID        interface{}     `bson:"_id,omitempty"`
+	Name      domain.UserName `bson:"name"`
+	Email     domain.Email    `bson:"email"`
+	CreatedAt time.Time       `bson:"createdAt"`
+	UpdatedAt time.Time       `bson:"updatedAt"`
+	Version   int64           `bson:"version"`
+	Roles     []string        `bson:"roles,omitempty"`
+	Locked    bool            `bson:"locked,omitempty"`
+
+	PasswordResetRequired bool `bson:"passwordResetRequired,omitempty"`
+
+	Avatar *domain.Avatar `bson:"avatar,omitempty"`
+}
+
+// userFields maps domain field names to userDocument field names for projections
+var userFields = map[string]string{
+	"id":         "_id",
+	"name":       "name",
+	"email":      "email",
+	"created_at": "createdAt",
+	"updated_at": "updatedAt",
+	"roles":      "roles",
+	"locked":     "locked",
+	"avatar":     "avatar",
+}
+
+// userSchema is the $jsonSchema validator for the users collection
+var userSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"name", "email", "createdAt", "updatedAt"},
+	"properties": bson.M{
+		"name":      bson.M{"bsonType": "string", "minLength": 1},
+		"email":     bson.M{"bsonType": "string", "pattern": "^[^@\\s]+@[^@\\s]+$"},
+		"createdAt": bson.M{"bsonType": "date"},
+		"updatedAt": bson.M{"bsonType": "date"},
+		"version":   bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+		"roles":     bson.M{"bsonType": "array", "items": bson.M{"bsonType": "string"}},
+		"locked":    bson.M{"bsonType": "bool"},
+
+		"passwordResetRequired": bson.M{"bsonType": "bool"},
+		"avatar": bson.M{
+			"bsonType": "object",
+			"required": bson.A{"version", "variants"},
+			"properties": bson.M{
+				"version":  bson.M{"bsonType": "string"},
+				"variants": bson.M{"bsonType": "object", "additionalProperties": bson.M{"bsonType": "string"}},
+			},
+		},
+	},
 }
 
 // NewUserRepository creates a new UserRepository
-func NewUserRepository(db resources.DBResource) UserRepository {
+// GetByID lookups are cached according to cache; pass a zero CacheConfig to disable caching.
+// IDs are stored as declared by ids; users created without an ID get a new one from it.
+func NewUserRepository(db resources.DBResource, cache CacheConfig, clk clock.Clock, ids IDCodec) UserRepository {
 	dbInstance := db.(*resources.DB)
 	collection := dbInstance.Collection("users")
 
+	base := NewBaseRepositoryWithConfig[userDocument](BaseRepositoryConfig{
+		Collection: collection,
+		EntityName: "user",
+	}, WithSchema(userSchema, ValidationMode(dbInstance.Config().SchemaValidation)), WithClock(clk), WithIDCodec(ids))
+
 	return &userRepositoryImpl{
-		BaseRepository: NewBaseRepositoryWithConfig[userDocument](BaseRepositoryConfig{
-			Collection: collection,
-			EntityName: "user",
-		}),
-		db: dbInstance,
+		CachedRepository: NewCachedRepository(base, cache),
+		db:               dbInstance,
 	}
 }
 
 // GetByID returns a user by ID
-func (r *userRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	doc, err := r.FindByID(ctx, id)
+// When fields are given, only those fields are fetched from the database and the cache is bypassed
+func (r *userRepositoryImpl) GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error) {
+	var opts []*options.FindOneOptions
+	if projection := Projection(MapFields(fields, userFields)...); projection != nil {
+		opts = append(opts, options.FindOne().SetProjection(projection))
+	}
+
+	doc, err := r.FindByID(ctx, id, opts...)
 	if err != nil {
 		if err == ErrNotFound {
 			return nil, nil
@@ -60,59 +135,139 @@ func (r *userRepositoryImpl) GetByID(ctx context.Context, id string) (*domain.Us
 		return nil, err
 	}
 
-	return toUser(doc), nil
+	return r.toUser(doc), nil
 }
 
 // List returns all users
-func (r *userRepositoryImpl) List(ctx context.Context) ([]*domain.User, error) {
-	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+// When fields are given, only those fields are fetched from the database
+func (r *userRepositoryImpl) List(ctx context.Context, fields ...string) ([]*domain.User, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetProjection(Projection(MapFields(fields, userFields)...))
 
 	docs, err := r.FindAll(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return toUsers(docs), nil
+	return r.toUsers(docs), nil
+}
+
+// Count returns the number of users matching the filter
+func (r *userRepositoryImpl) Count(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	return r.CachedRepository.Count(ctx, toUserQuery(filter))
+}
+
+// Search returns the page of users matching the filter selected by opts, and the number of users matching it
+// Users are sorted by opts.Sort, newest first by default, with the ID breaking ties so pages are stable.
+func (r *userRepositoryImpl) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	query := toUserQuery(filter)
+
+	total, err := r.CachedRepository.Count(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 || int64(opts.Offset) >= total {
+		return []*domain.User{}, total, nil
+	}
+
+	findOpts := options.Find().SetSort(toUserSort(opts))
+	if opts.Offset > 0 {
+		findOpts.SetSkip(int64(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(int64(opts.Limit))
+	}
+
+	docs, err := r.Find(ctx, query, findOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return r.toUsers(docs), total, nil
+}
+
+// Stream passes every user matching the filter to fn, oldest first, without loading them all in memory
+func (r *userRepositoryImpl) Stream(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	return r.FindEach(ctx, toUserQuery(filter), func(doc *userDocument) error {
+		return fn(r.toUser(doc))
+	}, opts)
 }
 
 // Create adds a new user
+// The unique email index rejects duplicates, returning ErrUserExists; checking beforehand would race
+// with concurrent creates.
 func (r *userRepositoryImpl) Create(ctx context.Context, user *domain.User) error {
-	if exists, _ := r.Exists(ctx, bson.M{"email": user.Email}); exists {
-		return ErrUserExists
+	if user.ID == "" {
+		user.ID = r.NewID()
+	}
+
+	id, err := r.EncodeID(user.ID)
+	if err != nil {
+		return err
 	}
 
+	now := r.Now()
 	doc := toDocument(user)
-	doc.CreatedAt = time.Now()
-	doc.UpdatedAt = time.Now()
+	doc.ID = id
+	doc.CreatedAt = now
+	doc.UpdatedAt = now
+	doc.Version = 1
 
-	id, err := r.InsertOne(ctx, &doc)
-	if err != nil {
+	if _, err := r.InsertOne(ctx, &doc); err != nil {
 		return err
 	}
 
-	user.ID = id
 	user.CreatedAt = doc.CreatedAt
 	user.UpdatedAt = doc.UpdatedAt
+	user.Version = doc.Version
 
 	return nil
 }
 
-// Update updates an existing user
+// Update updates an existing user and increments its version
+// When user.Version is set, the update only applies if the stored version still matches;
+// otherwise ErrVersionConflict is returned. Taking another user's email returns ErrUserExists.
 func (r *userRepositoryImpl) Update(ctx context.Context, user *domain.User) error {
+	now := r.Now()
 	update := bson.M{
-		"name":      user.Name,
-		"email":     user.Email,
-		"updatedAt": time.Now(),
+		"$set": bson.M{
+			"name":      user.Name,
+			"email":     user.Email,
+			"updatedAt": now,
+		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	if err := r.UpdateByID(ctx, user.ID, update); err != nil {
-		if err == ErrNotFound {
-			return ErrUserNotFound
+	filter, err := r.IDFilter(user.ID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.Version > 0 {
+		filter["version"] = user.Version
+	}
+
+	err = r.UpdateOne(ctx, filter, update)
+	r.Invalidate(ctx, user.ID)
+	if err != nil {
+		if err == ErrAlreadyExists {
+			return ErrUserExists
 		}
-		return err
+		if err != ErrNotFound {
+			return err
+		}
+		if user.Version > 0 {
+			if exists, _ := r.Exists(ctx, bson.M{"_id": filter["_id"]}); exists {
+				return ErrVersionConflict
+			}
+		}
+		return ErrUserNotFound
 	}
 
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = now
+	user.Version++
 	return nil
 }
 
@@ -127,10 +282,148 @@ func (r *userRepositoryImpl) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// SetLocked locks or unlocks a user
+func (r *userRepositoryImpl) SetLocked(ctx context.Context, id string, locked bool) error {
+	return r.setFlag(ctx, id, "locked", locked)
+}
+
+// SetPasswordResetRequired sets whether a user must reset their password
+func (r *userRepositoryImpl) SetPasswordResetRequired(ctx context.Context, id string, required bool) error {
+	return r.setFlag(ctx, id, "passwordResetRequired", required)
+}
+
+// SetAvatar sets or removes the avatar of a user, incrementing its version
+func (r *userRepositoryImpl) SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error {
+	filter, err := r.IDFilter(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	update := bson.M{
+		"$set": bson.M{"updatedAt": r.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if avatar != nil {
+		update["$set"].(bson.M)["avatar"] = avatar
+	} else {
+		update["$unset"] = bson.M{"avatar": ""}
+	}
+
+	err = r.UpdateOne(ctx, filter, update)
+	r.Invalidate(ctx, id)
+	if err == ErrNotFound {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// setFlag sets or unsets a boolean field of a user, incrementing its version
+// Unset flags are removed rather than stored as false, like omitempty does on creation.
+func (r *userRepositoryImpl) setFlag(ctx context.Context, id, field string, value bool) error {
+	filter, err := r.IDFilter(id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	update := bson.M{
+		"$set": bson.M{"updatedAt": r.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if value {
+		update["$set"].(bson.M)[field] = true
+	} else {
+		update["$unset"] = bson.M{field: ""}
+	}
+
+	err = r.UpdateOne(ctx, filter, update)
+	r.Invalidate(ctx, id)
+	if err == ErrNotFound {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// userStatsResult is the result of the user stats aggregation
+type userStatsResult struct {
+	Total []struct {
+		Count int64 `bson:"count"`
+	} `bson:"total"`
+	Locked []struct {
+		Count int64 `bson:"count"`
+	} `bson:"locked"`
+	SignupsByDay []struct {
+		Date  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	} `bson:"signupsByDay"`
+	RecentSignups []userDocument `bson:"recentSignups"`
+}
+
+// Stats computes the user stats in a single $facet aggregation over the users collection
+// Days are grouped in UTC; the createdAt index serves the signup facets.
+func (r *userRepositoryImpl) Stats(ctx context.Context, since time.Time, recent int) (*domain.UserStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$facet", Value: bson.M{
+			"total":  bson.A{bson.M{"$count": "count"}},
+			"locked": bson.A{bson.M{"$match": bson.M{"locked": true}}, bson.M{"$count": "count"}},
+			"signupsByDay": bson.A{
+				bson.M{"$match": bson.M{"createdAt": bson.M{"$gte": since}}},
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$createdAt", "timezone": "UTC"}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			"recentSignups": bson.A{
+				bson.M{"$sort": bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+				bson.M{"$limit": max(recent, 1)},
+			},
+		}}},
+	}
+
+	cursor, err := r.Collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate user stats: %w", err)
+	}
+	var results []userStatsResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode user stats: %w", err)
+	}
+
+	stats := &domain.UserStats{SignupsByDay: []domain.DailyCount{}, RecentSignups: []*domain.User{}}
+	if len(results) == 0 {
+		return stats, nil
+	}
+	result := results[0]
+	if len(result.Total) > 0 {
+		stats.Total = result.Total[0].Count
+	}
+	if len(result.Locked) > 0 {
+		stats.Locked = result.Locked[0].Count
+	}
+	for _, day := range result.SignupsByDay {
+		stats.SignupsByDay = append(stats.SignupsByDay, domain.DailyCount{Date: day.Date, Count: day.Count})
+	}
+	if recent > 0 {
+		stats.RecentSignups = r.toUsers(result.RecentSignups)
+	}
+	return stats, nil
+}
+
+// SyncCollection applies the schema and creates the indexes of the users collection
+func (r *userRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.CachedRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	return r.ensureIndexes(ctx)
+}
+
 // EnsureIndexes creates necessary indexes for the users collection
 func (r *userRepositoryImpl) EnsureIndexes() error {
-	ctx := context.Background()
+	return r.ensureIndexes(context.Background())
+}
 
+// ensureIndexes creates the unique email index Create and Update rely on, and the indexes serving searches
+func (r *userRepositoryImpl) ensureIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "email", Value: 1}},
@@ -139,6 +432,16 @@ func (r *userRepositoryImpl) EnsureIndexes() error {
 		{
 			Keys: bson.D{{Key: "createdAt", Value: -1}},
 		},
+		{
+			Keys: bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "name", Value: "text"}},
+		},
+		{
+			Keys:    bson.D{{Key: "roles", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	return r.db.EnsureIndexes(ctx, "users", indexes)
@@ -146,37 +449,87 @@ func (r *userRepositoryImpl) EnsureIndexes() error {
 
 // Conversion helpers
 
-func toUser(doc *userDocument) *domain.User {
+func toUserQuery(filter domain.UserFilter) bson.M {
+	query := bson.M{}
+	if filter.Name != "" {
+		query["name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Name), "$options": "i"}
+	}
+	if filter.Email != "" {
+		query["email"] = filter.Email
+	}
+	if filter.Query != "" {
+		query["$text"] = bson.M{"$search": filter.Query}
+	}
+	if !filter.CreatedAfter.IsZero() || !filter.CreatedBefore.IsZero() {
+		createdAt := bson.M{}
+		if !filter.CreatedAfter.IsZero() {
+			createdAt["$gte"] = filter.CreatedAfter
+		}
+		if !filter.CreatedBefore.IsZero() {
+			createdAt["$lt"] = filter.CreatedBefore
+		}
+		query["createdAt"] = createdAt
+	}
+	if filter.Role != "" {
+		query["roles"] = filter.Role
+	}
+	if filter.Locked != nil {
+		if *filter.Locked {
+			query["locked"] = true
+		} else {
+			query["locked"] = bson.M{"$ne": true}
+		}
+	}
+	return query
+}
+
+// toUserSort returns the sort of opts, newest first by default, breaking ties by ID
+func toUserSort(opts domain.ListOptions) bson.D {
+	field, ok := userFields[opts.Sort]
+	if !ok {
+		return bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}
+	}
+
+	direction := 1
+	if opts.Descending {
+		direction = -1
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+func (r *userRepositoryImpl) toUser(doc *userDocument) *domain.User {
 	return &domain.User{
-		ID:        doc.ID.Hex(),
+		ID:        r.DecodeID(doc.ID),
 		Name:      doc.Name,
 		Email:     doc.Email,
 		CreatedAt: doc.CreatedAt,
 		UpdatedAt: doc.UpdatedAt,
+		Version:   doc.Version,
+		Roles:     doc.Roles,
+		Locked:    doc.Locked,
+
+		PasswordResetRequired: doc.PasswordResetRequired,
+		Avatar:                doc.Avatar,
 	}
 }
 
-func toUsers(docs []userDocument) []*domain.User {
+func (r *userRepositoryImpl) toUsers(docs []userDocument) []*domain.User {
 	users := make([]*domain.User, len(docs))
 	for i := range docs {
-		users[i] = toUser(&docs[i])
+		users[i] = r.toUser(&docs[i])
 	}
 	return users
 }
 
 func toDocument(user *domain.User) userDocument {
-	doc := userDocument{
+	return userDocument{
 		Name:      user.Name,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
-	}
+		Roles:     user.Roles,
+		Locked:    user.Locked,
 
-	if user.ID != "" {
-		if objectID, err := primitive.ObjectIDFromHex(user.ID); err == nil {
-			doc.ID = objectID
-		}
+		PasswordResetRequired: user.PasswordResetRequired,
 	}
-
-	return doc
 }
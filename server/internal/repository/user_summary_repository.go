@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// UserSummaryCollection is the collection of the user summary read model
+const UserSummaryCollection = "user_summaries"
+
+// UserSummary is a denormalized read model of a user with counts of their related documents,
+// refreshed from the users, sessions and devices collections
+type UserSummary struct {
+	UserID    string
+	Name      string
+	Email     string
+	Roles     []string
+	Locked    bool
+	CreatedAt time.Time
+
+	// Sessions counts the active sessions of the user, and LastSeenAt is when one was last used
+	Sessions   int64
+	LastSeenAt time.Time
+
+	// Devices counts the devices registered for push notifications
+	Devices int64
+
+	// RefreshedAt is when the summary was last computed
+	RefreshedAt time.Time
+}
+
+// UserSummaryRepository stores the user summary read model
+// Summaries are only written by Refresh and Rebuild, which recompute them from their sources.
+type UserSummaryRepository interface {
+	// Get returns the summary of a user, or ErrNotFound
+	Get(ctx context.Context, userID string) (*UserSummary, error)
+
+	// List returns a page of summaries sorted by opts (name, email, created_at, sessions, devices
+	// or last_seen_at; newest first by default) and their total count
+	List(ctx context.Context, opts domain.ListOptions) ([]*UserSummary, int64, error)
+
+	// Refresh recomputes the summary of a user, removing it when the user no longer exists
+	Refresh(ctx context.Context, userID string) error
+
+	// Rebuild recomputes every summary, removing those of users that no longer exist, and returns
+	// the number of summaries written
+	Rebuild(ctx context.Context) (int64, error)
+
+	// Delete removes the summary of a user, returning how many were removed
+	Delete(ctx context.Context, userID string) (int64, error)
+}
+
+// userSummaryRepositoryImpl is the MongoDB implementation of UserSummaryRepository
+type userSummaryRepositoryImpl struct {
+	*CachedRepository[userSummaryDocument]
+	users *mongo.Collection
+}
+
+// userSummaryDocument represents the MongoDB document structure for user summaries; its _id is the
+// _id of the user
+type userSummaryDocument struct {
+	ID          interface{} `bson:"_id"`
+	Name        string      `bson:"name"`
+	Email       string      `bson:"email"`
+	Roles       []string    `bson:"roles,omitempty"`
+	Locked      bool        `bson:"locked,omitempty"`
+	CreatedAt   time.Time   `bson:"createdAt"`
+	Sessions    int64       `bson:"sessions"`
+	LastSeenAt  time.Time   `bson:"lastSeenAt,omitempty"`
+	Devices     int64       `bson:"devices"`
+	RefreshedAt time.Time   `bson:"refreshedAt"`
+}
+
+// userSummaryFields maps the sort fields of the list to userSummaryDocument field names
+var userSummaryFields = map[string]string{
+	"name":         "name",
+	"email":        "email",
+	"created_at":   "createdAt",
+	"sessions":     "sessions",
+	"devices":      "devices",
+	"last_seen_at": "lastSeenAt",
+}
+
+// userSummaryIndexes serve the sorts of the list and removing stale summaries
+var userSummaryIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+	{Keys: bson.D{{Key: "sessions", Value: -1}}},
+	{Keys: bson.D{{Key: "lastSeenAt", Value: -1}}},
+	{Keys: bson.D{{Key: "refreshedAt", Value: 1}}},
+}
+
+// NewUserSummaryRepository creates a new UserSummaryRepository keyed by user IDs encoded with ids
+// Get lookups and list totals are cached according to cache; pass a zero CacheConfig to disable
+// caching. Refresh and Delete invalidate the summary of their user, while summaries rewritten by
+// Rebuild are served from the cache until it expires.
+func NewUserSummaryRepository(db resources.DBResource, cache CacheConfig, clk clock.Clock, ids IDCodec) UserSummaryRepository {
+	dbInstance := db.(*resources.DB)
+
+	base := NewBaseRepositoryWithConfig[userSummaryDocument](BaseRepositoryConfig{
+		Collection: dbInstance.Collection(UserSummaryCollection),
+		EntityName: "user_summary",
+	}, WithClock(clk), WithIDCodec(ids))
+
+	return &userSummaryRepositoryImpl{
+		CachedRepository: NewCachedRepository(base, cache),
+		users:            dbInstance.Collection("users"),
+	}
+}
+
+// SyncCollection creates the indexes of the list sorts
+func (r *userSummaryRepositoryImpl) SyncCollection(ctx context.Context) error {
+	if err := r.CachedRepository.SyncCollection(ctx); err != nil {
+		return err
+	}
+	if _, err := r.Collection().Indexes().CreateMany(ctx, userSummaryIndexes); err != nil {
+		return fmt.Errorf("failed to create user summary indexes: %w", err)
+	}
+	return nil
+}
+
+// Get returns the summary of a user
+func (r *userSummaryRepositoryImpl) Get(ctx context.Context, userID string) (*UserSummary, error) {
+	doc, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.toUserSummary(doc), nil
+}
+
+// List returns a page of summaries
+func (r *userSummaryRepositoryImpl) List(ctx context.Context, opts domain.ListOptions) ([]*UserSummary, int64, error) {
+	total, err := r.Count(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 || int64(opts.Offset) >= total {
+		return []*UserSummary{}, total, nil
+	}
+
+	findOpts := options.Find().SetSort(toUserSummarySort(opts))
+	if opts.Offset > 0 {
+		findOpts.SetSkip(int64(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(int64(opts.Limit))
+	}
+	docs, err := r.Find(ctx, bson.M{}, findOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	summaries := make([]*UserSummary, len(docs))
+	for i := range docs {
+		summaries[i] = r.toUserSummary(&docs[i])
+	}
+	return summaries, total, nil
+}
+
+// Refresh recomputes the summary of a user with the summary pipeline restricted to the user
+func (r *userSummaryRepositoryImpl) Refresh(ctx context.Context, userID string) error {
+	filter, err := r.IDFilter(userID)
+	if err != nil {
+		return nil
+	}
+
+	now := r.refreshTime()
+	if err := r.merge(ctx, filter, now); err != nil {
+		return err
+	}
+
+	// The pipeline wrote nothing when the user is gone, leaving an older summary behind
+	filter["refreshedAt"] = bson.M{"$lt": now}
+	_, err = r.DeleteMany(ctx, filter)
+	r.Invalidate(ctx, userID)
+	return err
+}
+
+// Rebuild recomputes every summary with the summary pipeline over all users
+func (r *userSummaryRepositoryImpl) Rebuild(ctx context.Context) (int64, error) {
+	now := r.refreshTime()
+	if err := r.merge(ctx, bson.M{}, now); err != nil {
+		return 0, err
+	}
+	if _, err := r.DeleteMany(ctx, bson.M{"refreshedAt": bson.M{"$lt": now}}); err != nil {
+		return 0, err
+	}
+	return r.Count(ctx, bson.M{"refreshedAt": now})
+}
+
+// Delete removes the summary of a user
+func (r *userSummaryRepositoryImpl) Delete(ctx context.Context, userID string) (int64, error) {
+	filter, err := r.IDFilter(userID)
+	if err != nil {
+		return 0, nil
+	}
+	deleted, err := r.DeleteMany(ctx, filter)
+	r.Invalidate(ctx, userID)
+	return deleted, err
+}
+
+// merge computes the summaries of the users matching filter and merges them into the summary
+// collection, stamped with now
+// Sessions and devices reference users by the string form of their _id.
+func (r *userSummaryRepositoryImpl) merge(ctx context.Context, filter bson.M, now time.Time) error {
+	userID := bson.M{"$eq": bson.A{"$userId", "$$userId"}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "sessions",
+			"let":  bson.M{"userId": bson.M{"$toString": "$_id"}},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"$expr":     userID,
+					"revokedAt": bson.M{"$exists": false},
+					"expiresAt": bson.M{"$gt": now},
+				}}},
+				{{Key: "$group", Value: bson.M{
+					"_id":        nil,
+					"count":      bson.M{"$sum": 1},
+					"lastSeenAt": bson.M{"$max": "$lastUsedAt"},
+				}}},
+			},
+			"as": "sessions",
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "devices",
+			"let":  bson.M{"userId": bson.M{"$toString": "$_id"}},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": userID}}},
+				{{Key: "$count", Value: "count"}},
+			},
+			"as": "devices",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"name":        1,
+			"email":       1,
+			"roles":       1,
+			"locked":      1,
+			"createdAt":   1,
+			"sessions":    bson.M{"$ifNull": bson.A{bson.M{"$first": "$sessions.count"}, 0}},
+			"lastSeenAt":  bson.M{"$first": "$sessions.lastSeenAt"},
+			"devices":     bson.M{"$ifNull": bson.A{bson.M{"$first": "$devices.count"}, 0}},
+			"refreshedAt": now,
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           UserSummaryCollection,
+			"on":             "_id",
+			"whenMatched":    "replace",
+			"whenNotMatched": "insert",
+		}}},
+	}
+
+	cursor, err := r.users.Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to refresh user summaries: %w", err)
+	}
+	return cursor.Close(ctx)
+}
+
+// refreshTime returns the time stamping refreshed summaries, truncated to the millisecond precision
+// of BSON dates so stale summaries can be told apart by comparing it
+func (r *userSummaryRepositoryImpl) refreshTime() time.Time {
+	return r.Now().Truncate(time.Millisecond)
+}
+
+// toUserSummarySort returns the sort of opts, newest users first by default
+func toUserSummarySort(opts domain.ListOptions) bson.D {
+	field, ok := userSummaryFields[opts.Sort]
+	if !ok {
+		return bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}
+	}
+
+	direction := 1
+	if opts.Descending {
+		direction = -1
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+func (r *userSummaryRepositoryImpl) toUserSummary(doc *userSummaryDocument) *UserSummary {
+	return &UserSummary{
+		UserID:      r.DecodeID(doc.ID),
+		Name:        doc.Name,
+		Email:       doc.Email,
+		Roles:       doc.Roles,
+		Locked:      doc.Locked,
+		CreatedAt:   doc.CreatedAt,
+		Sessions:    doc.Sessions,
+		LastSeenAt:  doc.LastSeenAt,
+		Devices:     doc.Devices,
+		RefreshedAt: doc.RefreshedAt,
+	}
+}
@@ -3,11 +3,13 @@ package resources
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -58,7 +60,7 @@ func (d *DB) Connect(ctx context.Context) error {
 		SetMaxPoolSize(d.config.MaxPoolSize).
 		SetMinPoolSize(d.config.MinPoolSize).
 		SetServerSelectionTimeout(d.config.ConnectTimeout).
-		SetMonitor(otelmongo.NewMonitor())
+		SetMonitor(newQueryMonitor(d.config.SlowQueryThreshold))
 
 	// Connect to MongoDB
 	client, err := mongo.Connect(connectCtx, clientOptions)
@@ -134,9 +136,78 @@ func (d *DB) GetClient() *mongo.Client {
 	return d.client
 }
 
+// Config returns the MongoDB configuration of this resource
+func (d *DB) Config() config.MongoDBConfig {
+	return d.config
+}
+
 // Collection returns a handle to a MongoDB collection
+// Read preference and read concern defaults from config are applied to the handle
 func (d *DB) Collection(name string) *mongo.Collection {
-	return d.database.Collection(name)
+	return d.database.Collection(name, d.collectionOptions(name))
+}
+
+// collectionOptions builds the collection-level read defaults for the named collection
+func (d *DB) collectionOptions(name string) *options.CollectionOptions {
+	opts := options.Collection()
+
+	mode := d.config.ReadPreference
+	if override, ok := d.config.CollectionReadPreferences[name]; ok {
+		mode = override
+	}
+	if mode != "" {
+		rp, err := ParseReadPreference(mode, d.config.MaxStaleness)
+		if err != nil {
+			logger.Warn("Invalid read preference, using driver default",
+				zap.String("collection", name),
+				zap.String("readPreference", mode),
+				zap.Error(err),
+			)
+		} else {
+			opts.SetReadPreference(rp)
+		}
+	}
+
+	if d.config.ReadConcern != "" {
+		rc, err := ParseReadConcern(d.config.ReadConcern)
+		if err != nil {
+			logger.Warn("Invalid read concern, using driver default",
+				zap.String("collection", name),
+				zap.String("readConcern", d.config.ReadConcern),
+				zap.Error(err),
+			)
+		} else {
+			opts.SetReadConcern(rc)
+		}
+	}
+
+	return opts
+}
+
+// ParseReadPreference converts a read preference mode name into a ReadPref
+// maxStaleness is ignored for primary reads, where it is not allowed
+func ParseReadPreference(mode string, maxStaleness time.Duration) (*readpref.ReadPref, error) {
+	parsed, err := readpref.ModeFromString(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []readpref.Option
+	if maxStaleness > 0 && parsed != readpref.PrimaryMode {
+		opts = append(opts, readpref.WithMaxStaleness(maxStaleness))
+	}
+
+	return readpref.New(parsed, opts...)
+}
+
+// ParseReadConcern converts a read concern level name into a ReadConcern
+func ParseReadConcern(level string) (*readconcern.ReadConcern, error) {
+	switch level {
+	case "local", "available", "majority", "linearizable", "snapshot":
+		return &readconcern.ReadConcern{Level: level}, nil
+	default:
+		return nil, fmt.Errorf("unknown read concern level %q", level)
+	}
 }
 
 // WithContext creates a new traced context for database operations
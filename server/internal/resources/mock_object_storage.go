@@ -0,0 +1,108 @@
+package resources
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MockObjectStorage is a mock implementation of ObjectStorageResource for testing
+// Presigned URLs point to mock.invalid; Put stores objects as a client uploading to them would.
+type MockObjectStorage struct {
+	connected bool
+
+	mu      sync.Mutex
+	objects map[string]ObjectInfo
+}
+
+// NewMockObjectStorage creates a new MockObjectStorage resource
+func NewMockObjectStorage() *MockObjectStorage {
+	return &MockObjectStorage{objects: make(map[string]ObjectInfo)}
+}
+
+// Connect simulates creating the client
+func (s *MockObjectStorage) Connect(ctx context.Context) error {
+	s.connected = true
+	return nil
+}
+
+// Close simulates releasing the client
+func (s *MockObjectStorage) Close(ctx context.Context) error {
+	s.connected = false
+	return nil
+}
+
+// Ping simulates checking the bucket
+func (s *MockObjectStorage) Ping(ctx context.Context) error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+	return nil
+}
+
+// Name returns the name of the resource
+func (s *MockObjectStorage) Name() string {
+	return "mock-object-storage"
+}
+
+// PresignPut returns an unsigned request for key
+func (s *MockObjectStorage) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (*PresignedRequest, error) {
+	if !s.connected {
+		return nil, ErrResourceNotConnected
+	}
+	return &PresignedRequest{
+		Method: http.MethodPut,
+		URL:    "https://mock.invalid/" + url.PathEscape(key),
+		Headers: map[string]string{
+			"Content-Type":   contentType,
+			"Content-Length": strconv.FormatInt(size, 10),
+		},
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// Stat returns the object stored at key
+func (s *MockObjectStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if !s.connected {
+		return nil, ErrResourceNotConnected
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return &info, nil
+}
+
+// Delete deletes the object stored at key
+func (s *MockObjectStorage) Delete(ctx context.Context, key string) error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// Put stores data at key as an upload to a presigned URL would
+func (s *MockObjectStorage) Put(key, contentType string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := md5.Sum(data)
+	s.objects[key] = ObjectInfo{Key: key, Size: int64(len(data)), ContentType: contentType, ETag: hex.EncodeToString(sum[:])}
+}
+
+// Exists reports whether an object is stored at key
+func (s *MockObjectStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[key]
+	return ok
+}
@@ -0,0 +1,96 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"quizizz.com/internal/config"
+)
+
+// MockPush is a mock implementation of PushResource for testing
+// It delivers every message except those to tokens marked invalid or failing, and records them all.
+type MockPush struct {
+	connected bool
+	config    config.PushConfig
+
+	mu       sync.Mutex
+	sent     []PushMessage
+	invalid  map[string]bool
+	failures map[string]bool
+}
+
+// NewMockPush creates a new MockPush resource
+func NewMockPush(cfg *config.Config) *MockPush {
+	return &MockPush{
+		config:   cfg.Push,
+		invalid:  make(map[string]bool),
+		failures: make(map[string]bool),
+	}
+}
+
+// Connect simulates creating the provider clients
+func (p *MockPush) Connect(ctx context.Context) error {
+	p.connected = true
+	return nil
+}
+
+// Close simulates releasing the provider clients
+func (p *MockPush) Close(ctx context.Context) error {
+	p.connected = false
+	return nil
+}
+
+// Ping simulates checking the providers
+func (p *MockPush) Ping(ctx context.Context) error {
+	if !p.connected {
+		return ErrResourceNotConnected
+	}
+	return nil
+}
+
+// Name returns the name of the resource
+func (p *MockPush) Name() string {
+	return "mock-push"
+}
+
+// Send records messages and returns their simulated results
+func (p *MockPush) Send(ctx context.Context, messages []PushMessage) []PushResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := make([]PushResult, len(messages))
+	for i, msg := range messages {
+		p.sent = append(p.sent, msg)
+		switch {
+		case p.invalid[msg.Token]:
+			results[i] = PushResult{Status: PushInvalidToken, Attempts: 1, Error: "provider responded 410: Unregistered"}
+		case p.failures[msg.Token]:
+			results[i] = PushResult{Status: PushFailed, Attempts: p.config.MaxRetries + 1, Error: "provider responded 503: Unavailable"}
+		default:
+			results[i] = PushResult{Status: PushDelivered, Attempts: 1, ProviderID: fmt.Sprintf("mock-%d", len(p.sent))}
+		}
+	}
+	return results
+}
+
+// InvalidateToken makes messages to token fail as sent to an invalid token
+func (p *MockPush) InvalidateToken(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalid[token] = true
+}
+
+// FailToken makes messages to token fail with a transient error
+func (p *MockPush) FailToken(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures[token] = true
+}
+
+// Sent returns the messages sent so far
+func (p *MockPush) Sent() []PushMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PushMessage(nil), p.sent...)
+}
@@ -0,0 +1,264 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// MockSearch is a mock implementation of SearchResource for testing
+// It keeps indices in memory and evaluates typed queries against their documents; Fail makes
+// every call fail as when the cluster is unavailable.
+type MockSearch struct {
+	connected bool
+
+	mu        sync.Mutex
+	templates map[string]IndexTemplate
+	indices   map[string]map[string]json.RawMessage
+	aliases   map[string]string
+	failure   error
+}
+
+// NewMockSearch creates a new MockSearch resource
+func NewMockSearch() *MockSearch {
+	return &MockSearch{
+		templates: make(map[string]IndexTemplate),
+		indices:   make(map[string]map[string]json.RawMessage),
+		aliases:   make(map[string]string),
+	}
+}
+
+// Connect simulates creating the client
+func (s *MockSearch) Connect(ctx context.Context) error {
+	s.connected = true
+	return nil
+}
+
+// Close simulates releasing the client
+func (s *MockSearch) Close(ctx context.Context) error {
+	s.connected = false
+	return nil
+}
+
+// Ping simulates checking the cluster
+func (s *MockSearch) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.check()
+}
+
+// Name returns the name of the resource
+func (s *MockSearch) Name() string {
+	return "mock-search"
+}
+
+// PutIndexTemplate records the template
+func (s *MockSearch) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return err
+	}
+	s.templates[name] = template
+	return nil
+}
+
+// CreateIndex creates an empty index, failing when it exists
+func (s *MockSearch) CreateIndex(ctx context.Context, index string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return err
+	}
+	if _, ok := s.indices[index]; ok {
+		return &SearchError{StatusCode: http.StatusBadRequest, Type: "resource_already_exists_exception", Reason: index}
+	}
+	s.indices[index] = make(map[string]json.RawMessage)
+	return nil
+}
+
+// DeleteIndex deletes an index and the alias pointing to it
+func (s *MockSearch) DeleteIndex(ctx context.Context, index string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return err
+	}
+	delete(s.indices, index)
+	for alias, target := range s.aliases {
+		if target == index {
+			delete(s.aliases, alias)
+		}
+	}
+	return nil
+}
+
+// AliasIndices returns the index alias points to
+func (s *MockSearch) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	if index, ok := s.aliases[alias]; ok {
+		return []string{index}, nil
+	}
+	return nil, nil
+}
+
+// SetAlias points alias to index
+func (s *MockSearch) SetAlias(ctx context.Context, alias, index string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return err
+	}
+	if _, ok := s.indices[index]; !ok {
+		return &SearchError{StatusCode: http.StatusNotFound, Type: "index_not_found_exception", Reason: index}
+	}
+	s.aliases[alias] = index
+	return nil
+}
+
+// Bulk applies operations, creating the indices they write to like clusters do
+func (s *MockSearch) Bulk(ctx context.Context, operations []BulkOperation) ([]BulkResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(operations))
+	for i, op := range operations {
+		results[i] = BulkResult{ID: op.ID, Status: http.StatusOK}
+		index := s.resolve(op.Index)
+		switch op.Action {
+		case BulkIndex:
+			doc, err := json.Marshal(op.Document)
+			if err != nil {
+				results[i] = BulkResult{ID: op.ID, Status: http.StatusBadRequest, Error: err.Error()}
+				continue
+			}
+			if s.indices[index] == nil {
+				s.indices[index] = make(map[string]json.RawMessage)
+			}
+			s.indices[index][op.ID] = doc
+		case BulkDelete:
+			if _, ok := s.indices[index][op.ID]; !ok {
+				results[i].Status = http.StatusNotFound
+			}
+			delete(s.indices[index], op.ID)
+		default:
+			results[i] = BulkResult{ID: op.ID, Status: http.StatusBadRequest, Error: fmt.Sprintf("unknown action %q", op.Action)}
+		}
+	}
+	return results, nil
+}
+
+// Search evaluates request against the documents of index
+// Results are sorted by request.Sort only; relevance is not scored.
+func (s *MockSearch) Search(ctx context.Context, index string, request SearchRequest) (*SearchResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	docs, ok := s.indices[s.resolve(index)]
+	if !ok {
+		return nil, &SearchError{StatusCode: http.StatusNotFound, Type: "index_not_found_exception", Reason: index}
+	}
+
+	query := request.Query
+	if query == nil {
+		query = MatchAll()
+	}
+	type match struct {
+		hit SearchHit
+		doc map[string]interface{}
+	}
+	var matches []match
+	for id, source := range docs {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return nil, err
+		}
+		if query.matches(doc) {
+			matches = append(matches, match{hit: SearchHit{ID: id, Source: source}, doc: doc})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		for _, order := range request.Sort {
+			a, b := fmt.Sprint(lookup(matches[i].doc, order.Field)), fmt.Sprint(lookup(matches[j].doc, order.Field))
+			if a == b {
+				continue
+			}
+			return (a < b) != order.Descending
+		}
+		return matches[i].hit.ID < matches[j].hit.ID
+	})
+
+	response := &SearchResponse{Total: int64(len(matches)), Hits: []SearchHit{}}
+	start := min(request.From, len(matches))
+	end := len(matches)
+	if request.Size > 0 {
+		end = min(start+request.Size, end)
+	}
+	for _, m := range matches[start:end] {
+		response.Hits = append(response.Hits, m.hit)
+	}
+	return response, nil
+}
+
+// Fail makes every call return err, or succeed again when err is nil
+func (s *MockSearch) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = err
+}
+
+// Templates returns the names of the index templates
+func (s *MockSearch) Templates() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Documents returns the IDs of the documents of index, or of the index of an alias
+func (s *MockSearch) Documents(index string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.indices[s.resolve(index)]))
+	for id := range s.indices[s.resolve(index)] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// check returns the error calls fail with
+func (s *MockSearch) check() error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+	if s.failure != nil {
+		return s.failure
+	}
+	return nil
+}
+
+// resolve returns the index alias points to, or alias when it is not one
+func (s *MockSearch) resolve(alias string) string {
+	if index, ok := s.aliases[alias]; ok {
+		return index
+	}
+	return alias
+}
@@ -0,0 +1,216 @@
+package resources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// redactedValue replaces literal values in logged query filters
+const redactedValue = "?"
+
+// commandFilterKeys maps command names to the fields holding their query shape
+var commandFilterKeys = map[string][]string{
+	"find":          {"filter", "sort", "projection"},
+	"count":         {"query"},
+	"distinct":      {"key", "query"},
+	"aggregate":     {"pipeline"},
+	"findAndModify": {"query", "sort"},
+	"update":        {"updates"},
+	"delete":        {"deletes"},
+}
+
+// startedCommand holds what we need from a started event until the command finishes
+type startedCommand struct {
+	collection string
+	filter     string
+}
+
+// queryMonitor records per-command metrics and logs slow queries
+// It wraps the otelmongo monitor so tracing keeps working alongside it
+type queryMonitor struct {
+	tracing       *event.CommandMonitor
+	slowThreshold time.Duration
+	started       sync.Map // map[int64]startedCommand keyed by request ID
+
+	duration metric.Float64Histogram
+	commands metric.Int64Counter
+	slow     metric.Int64Counter
+}
+
+// newQueryMonitor creates a command monitor combining tracing, metrics, and slow query logging
+func newQueryMonitor(slowThreshold time.Duration) *event.CommandMonitor {
+	meter := otel.Meter("mongodb")
+
+	m := &queryMonitor{
+		tracing:       otelmongo.NewMonitor(),
+		slowThreshold: slowThreshold,
+	}
+
+	var err error
+	m.duration, err = meter.Float64Histogram("mongodb.command.duration",
+		metric.WithDescription("Duration of MongoDB commands"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create mongodb duration histogram", zap.Error(err))
+	}
+
+	m.commands, err = meter.Int64Counter("mongodb.commands",
+		metric.WithDescription("Number of MongoDB commands by collection and operation"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create mongodb command counter", zap.Error(err))
+	}
+
+	m.slow, err = meter.Int64Counter("mongodb.slow_commands",
+		metric.WithDescription("Number of MongoDB commands exceeding the slow query threshold"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create mongodb slow command counter", zap.Error(err))
+	}
+
+	return &event.CommandMonitor{
+		Started:   m.onStarted,
+		Succeeded: m.onSucceeded,
+		Failed:    m.onFailed,
+	}
+}
+
+// onStarted remembers the collection and sanitized filter of a command
+func (m *queryMonitor) onStarted(ctx context.Context, evt *event.CommandStartedEvent) {
+	m.tracing.Started(ctx, evt)
+
+	cmd := startedCommand{}
+	if evt.CommandName == "getMore" {
+		cmd.collection, _ = evt.Command.Lookup("collection").StringValueOK()
+	} else if value, err := evt.Command.IndexErr(0); err == nil {
+		cmd.collection, _ = value.Value().StringValueOK()
+	}
+
+	// Only capture the query shape when it may be logged
+	if m.slowThreshold > 0 {
+		cmd.filter = sanitizeCommand(evt.CommandName, evt.Command)
+	}
+
+	m.started.Store(evt.RequestID, cmd)
+}
+
+// onSucceeded records a successful command
+func (m *queryMonitor) onSucceeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	m.tracing.Succeeded(ctx, evt)
+	m.record(ctx, evt.CommandFinishedEvent, "ok")
+}
+
+// onFailed records a failed command
+func (m *queryMonitor) onFailed(ctx context.Context, evt *event.CommandFailedEvent) {
+	m.tracing.Failed(ctx, evt)
+	m.record(ctx, evt.CommandFinishedEvent, "error")
+}
+
+// record exports metrics for a finished command and logs it if it was slow
+func (m *queryMonitor) record(ctx context.Context, evt event.CommandFinishedEvent, status string) {
+	value, ok := m.started.LoadAndDelete(evt.RequestID)
+	if !ok {
+		return
+	}
+	cmd := value.(startedCommand)
+
+	// Connection handshakes and heartbeats are not tied to a collection
+	if cmd.collection == "" {
+		return
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("collection", cmd.collection),
+		attribute.String("operation", evt.CommandName),
+		attribute.String("status", status),
+	)
+
+	if m.duration != nil {
+		m.duration.Record(ctx, evt.Duration.Seconds(), attrs)
+	}
+	if m.commands != nil {
+		m.commands.Add(ctx, 1, attrs)
+	}
+
+	if m.slowThreshold <= 0 || evt.Duration < m.slowThreshold {
+		return
+	}
+
+	if m.slow != nil {
+		m.slow.Add(ctx, 1, attrs)
+	}
+
+	logger.WarnCtx(ctx, "Slow MongoDB query",
+		zap.String("database", evt.DatabaseName),
+		zap.String("collection", cmd.collection),
+		zap.String("operation", evt.CommandName),
+		zap.String("status", status),
+		zap.Duration("duration", evt.Duration),
+		zap.Duration("threshold", m.slowThreshold),
+		zap.String("filter", cmd.filter),
+	)
+}
+
+// sanitizeCommand extracts the query shape of a command as extended JSON with all literal values redacted
+func sanitizeCommand(commandName string, command bson.Raw) string {
+	keys, ok := commandFilterKeys[commandName]
+	if !ok {
+		return ""
+	}
+
+	shape := bson.D{}
+	for _, key := range keys {
+		value, err := command.LookupErr(key)
+		if err != nil {
+			continue
+		}
+
+		var decoded interface{}
+		if err := value.Unmarshal(&decoded); err != nil {
+			continue
+		}
+
+		// Sort, projection and distinct keys describe the query shape, not user data
+		if key != "sort" && key != "projection" && key != "key" {
+			decoded = redact(decoded)
+		}
+		shape = append(shape, bson.E{Key: key, Value: decoded})
+	}
+
+	out, err := bson.MarshalExtJSON(shape, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// redact replaces literal values with a placeholder while keeping field names and operators
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		out := make(bson.D, 0, len(v))
+		for _, elem := range v {
+			out = append(out, bson.E{Key: elem.Key, Value: redact(elem.Value)})
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, 0, len(v))
+		for _, elem := range v {
+			out = append(out, redact(elem))
+		}
+		return out
+	default:
+		return redactedValue
+	}
+}
@@ -0,0 +1,324 @@
+package resources
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/httpclient"
+)
+
+// ErrObjectNotFound is returned when an object does not exist in the bucket
+var ErrObjectNotFound = errors.New("object not found")
+
+// Signature Version 4 constants
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4Service     = "s3"
+	sigV4Terminator  = "aws4_request"
+	sigV4DateLayout  = "20060102"
+	sigV4TimeLayout  = "20060102T150405Z"
+	sigV4Unsigned    = "UNSIGNED-PAYLOAD"
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// ObjectInfo describes an object stored in the bucket
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string
+}
+
+// PresignedRequest is a request a client can send to the bucket without credentials until it
+// expires; it must be sent with exactly the given method and headers
+type PresignedRequest struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// ObjectStorageResource defines the interface for S3-compatible object storage
+type ObjectStorageResource interface {
+	Resource
+
+	// PresignPut returns a request uploading an object of exactly size bytes of contentType at key,
+	// valid for ttl
+	PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (*PresignedRequest, error)
+
+	// Stat returns the object stored at key, or ErrObjectNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// Delete deletes the object stored at key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// ObjectStorage implements the ObjectStorageResource interface over the S3 REST API, signing
+// requests with query string Signature Version 4 so that the same signing serves presigned
+// uploads and the requests of the server
+type ObjectStorage struct {
+	config    config.UploadsConfig
+	endpoint  *url.URL
+	client    *httpclient.Client
+	now       func() time.Time
+	connected bool
+}
+
+// NewObjectStorage creates a new ObjectStorage resource
+func NewObjectStorage(cfg *config.Config) ObjectStorageResource {
+	return &ObjectStorage{config: cfg.Uploads, now: time.Now}
+}
+
+// Connect creates the client of the storage API; the bucket is not called, so that the
+// application starts while storage is unavailable
+func (s *ObjectStorage) Connect(ctx context.Context) error {
+	endpoint, err := url.Parse(s.config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return fmt.Errorf("invalid object storage endpoint %q", s.config.Endpoint)
+	}
+	if s.config.Bucket == "" {
+		return errors.New("object storage bucket is not set")
+	}
+	s.endpoint = endpoint
+
+	clientConfig := httpclient.DefaultConfig(s.config.Endpoint).
+		WithServiceName("object-storage").
+		WithBreakerKeyFunc(httpclient.BreakerKeyByPath)
+	if s.config.Timeout > 0 {
+		clientConfig.WithRequestTimeout(s.config.Timeout)
+	}
+	client, err := httpclient.New(clientConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create object storage client: %w", err)
+	}
+	s.client = client
+	s.connected = true
+	return nil
+}
+
+// Close releases the connections of the client
+func (s *ObjectStorage) Close(ctx context.Context) error {
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.connected = false
+	return nil
+}
+
+// Ping checks that the bucket exists and the credentials can reach it
+func (s *ObjectStorage) Ping(ctx context.Context) error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+	resp, err := s.do(ctx, http.MethodHead, "")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bucket %s responded %d", s.config.Bucket, resp.StatusCode)
+	}
+	return nil
+}
+
+// Name returns the name of the resource
+func (s *ObjectStorage) Name() string {
+	return "object-storage"
+}
+
+// PresignPut signs a PUT request whose content type and length are part of the signature, so
+// storage rejects uploads of another type or size
+func (s *ObjectStorage) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (*PresignedRequest, error) {
+	if !s.connected {
+		return nil, ErrResourceNotConnected
+	}
+	headers := map[string]string{
+		"Content-Type":   contentType,
+		"Content-Length": strconv.FormatInt(size, 10),
+	}
+	now := s.now().UTC()
+	signed, err := s.presign(http.MethodPut, key, headers, now, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignedRequest{
+		Method:    http.MethodPut,
+		URL:       signed,
+		Headers:   headers,
+		ExpiresAt: now.Add(ttl.Truncate(time.Second)),
+	}, nil
+}
+
+// Stat sends a HEAD request for the object
+func (s *ObjectStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if !s.connected {
+		return nil, ErrResourceNotConnected
+	}
+	resp, err := s.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrObjectNotFound
+	default:
+		return nil, fmt.Errorf("object storage responded %d to HEAD %s", resp.StatusCode, key)
+	}
+
+	size, err := strconv.ParseInt(resp.Headers.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length of object %s: %w", key, err)
+	}
+	return &ObjectInfo{
+		Key:         key,
+		Size:        size,
+		ContentType: resp.Headers.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Headers.Get("ETag"), `"`),
+	}, nil
+}
+
+// Delete sends a DELETE request for the object
+func (s *ObjectStorage) Delete(ctx context.Context, key string) error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("object storage responded %d to DELETE %s", resp.StatusCode, key)
+	}
+	return nil
+}
+
+// do sends a request for key, or for the bucket when key is empty, through a URL presigned for a
+// minute
+func (s *ObjectStorage) do(ctx context.Context, method, key string) (*httpclient.Response, error) {
+	signed, err := s.presign(method, key, nil, s.now().UTC(), time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.Request(ctx, method, signed, nil)
+}
+
+// presign returns the URL of a request for key signed at now for ttl, with headers signed too
+func (s *ObjectStorage) presign(method, key string, headers map[string]string, now time.Time, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > maxPresignExpiry {
+		return "", fmt.Errorf("presigned URLs must expire within %s", maxPresignExpiry)
+	}
+
+	host, path := s.endpoint.Host, "/"+key
+	if s.config.PathStyle {
+		path = "/" + s.config.Bucket + path
+		if key == "" {
+			path = "/" + s.config.Bucket
+		}
+	} else {
+		host = s.config.Bucket + "." + host
+	}
+
+	canonicalHeaders := map[string]string{"host": host}
+	for name, value := range headers {
+		canonicalHeaders[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names := make([]string, 0, len(canonicalHeaders))
+	for name := range canonicalHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var headerLines strings.Builder
+	for _, name := range names {
+		headerLines.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	date := now.Format(sigV4DateLayout)
+	scope := strings.Join([]string{date, s.config.Region, sigV4Service, sigV4Terminator}, "/")
+	query := url.Values{
+		"X-Amz-Algorithm":     {sigV4Algorithm},
+		"X-Amz-Credential":    {s.config.AccessKeyID + "/" + scope},
+		"X-Amz-Date":          {now.Format(sigV4TimeLayout)},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl / time.Second))},
+		"X-Amz-SignedHeaders": {signedHeaders},
+	}
+	canonicalQuery := encodeSigV4Query(query)
+	canonicalPath := escapeSigV4(path, false)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalPath,
+		canonicalQuery,
+		headerLines.String(),
+		signedHeaders,
+		sigV4Unsigned,
+	}, "\n")
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		now.Format(sigV4TimeLayout),
+		scope,
+		hex.EncodeToString(digest[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	for _, part := range []string{s.config.Region, sigV4Service, sigV4Terminator} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return s.endpoint.Scheme + "://" + host + canonicalPath + "?" + canonicalQuery + "&X-Amz-Signature=" + signature, nil
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// encodeSigV4Query returns the canonical query string of values, sorted and escaped
+func encodeSigV4Query(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range values[key] {
+			pairs = append(pairs, escapeSigV4(key, true)+"="+escapeSigV4(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapeSigV4 percent-encodes every byte but the unreserved characters, and slashes unless
+// encodeSlash is set, as Signature Version 4 requires
+func escapeSigV4(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,248 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+)
+
+// Push platforms, each delivered to by one provider
+const (
+	PushPlatformAndroid = "android"
+	PushPlatformWeb     = "web"
+	PushPlatformIOS     = "ios"
+)
+
+// Push delivery statuses
+const (
+	// PushDelivered means the provider accepted the message for delivery
+	PushDelivered = "delivered"
+
+	// PushInvalidToken means the device token is no longer valid, e.g. the app was uninstalled;
+	// the device should not be sent to again
+	PushInvalidToken = "invalid_token"
+
+	// PushFailed means the message could not be delivered, after retries for transient errors
+	PushFailed = "failed"
+)
+
+// ErrNoPushProvider is returned for messages to a platform whose provider is not configured
+var ErrNoPushProvider = errors.New("no push provider for platform")
+
+// PushMessage is a notification sent to one device
+type PushMessage struct {
+	Platform string
+	Token    string
+	Title    string
+	Body     string
+	Data     map[string]string
+
+	// CollapseKey lets a message replace an undelivered one with the same key
+	CollapseKey string
+}
+
+// PushResult is the outcome of sending a PushMessage
+type PushResult struct {
+	Status string
+
+	// ProviderID is the ID the provider gave the message, for tracing it with the provider
+	ProviderID string
+
+	Attempts int
+	Error    string
+}
+
+// PushResource defines the interface for push notification resources
+type PushResource interface {
+	Resource
+
+	// Send delivers messages in concurrent batches, retrying those failing with transient errors
+	// It returns the result of each message, in order.
+	Send(ctx context.Context, messages []PushMessage) []PushResult
+}
+
+// pushProvider delivers messages to the devices of its platforms
+type pushProvider interface {
+	// send delivers a message, returning the ID the provider gave it or a *pushError
+	send(ctx context.Context, msg *PushMessage) (string, error)
+
+	close()
+}
+
+// pushError is a delivery error classified by a provider
+type pushError struct {
+	statusCode   int
+	reason       string
+	invalidToken bool
+	retryable    bool
+}
+
+func (e *pushError) Error() string {
+	return fmt.Sprintf("provider responded %d: %s", e.statusCode, e.reason)
+}
+
+// Push implements the PushResource interface over FCM and APNs
+type Push struct {
+	config    config.PushConfig
+	providers map[string]pushProvider
+	connected bool
+	tracer    trace.Tracer
+}
+
+// NewPush creates a new Push resource
+func NewPush(cfg *config.Config) PushResource {
+	return &Push{
+		config: cfg.Push,
+		tracer: otel.Tracer("push"),
+	}
+}
+
+// Connect creates the clients of the configured providers
+func (p *Push) Connect(ctx context.Context) error {
+	providers := make(map[string]pushProvider)
+	if p.config.FCMProjectID != "" {
+		fcm, err := newFCMProvider(p.config)
+		if err != nil {
+			return fmt.Errorf("failed to create FCM client: %w", err)
+		}
+		providers[PushPlatformAndroid] = fcm
+		providers[PushPlatformWeb] = fcm
+	}
+	if p.config.APNsTopic != "" {
+		apns, err := newAPNsProvider(p.config)
+		if err != nil {
+			return fmt.Errorf("failed to create APNs client: %w", err)
+		}
+		providers[PushPlatformIOS] = apns
+	}
+	if len(providers) == 0 {
+		logger.WarnCtx(ctx, "No push provider configured, push notifications will fail")
+	}
+
+	p.providers = providers
+	p.connected = true
+	return nil
+}
+
+// Close releases the connections of the providers
+func (p *Push) Close(ctx context.Context) error {
+	closed := make(map[pushProvider]bool, len(p.providers))
+	for _, provider := range p.providers {
+		if !closed[provider] {
+			provider.close()
+			closed[provider] = true
+		}
+	}
+	p.connected = false
+	return nil
+}
+
+// Ping reports whether the providers were set up; they are not called, as they have no cheap
+// endpoint to check
+func (p *Push) Ping(ctx context.Context) error {
+	if !p.connected {
+		return ErrResourceNotConnected
+	}
+	return nil
+}
+
+// Name returns the name of the resource
+func (p *Push) Name() string {
+	return "push"
+}
+
+// Send delivers messages in batches of the configured size
+func (p *Push) Send(ctx context.Context, messages []PushMessage) []PushResult {
+	ctx, span := p.tracer.Start(ctx, "Push.Send", trace.WithAttributes(attribute.Int("push.messages", len(messages))))
+	defer span.End()
+
+	batchSize := max(p.config.BatchSize, 1)
+	results := make([]PushResult, len(messages))
+	for start := 0; start < len(messages); start += batchSize {
+		end := min(start+batchSize, len(messages))
+		p.sendBatch(ctx, messages[start:end], results[start:end])
+	}
+	return results
+}
+
+// sendBatch sends messages concurrently, then retries those that failed with a transient error
+// after an exponential backoff
+func (p *Push) sendBatch(ctx context.Context, messages []PushMessage, results []PushResult) {
+	pending := make([]int, len(messages))
+	for i := range pending {
+		pending[i] = i
+	}
+	retryable := make([]bool, len(messages))
+	backoff := p.config.RetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		var wg sync.WaitGroup
+		for _, i := range pending {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], retryable[i] = p.deliver(ctx, &messages[i], results[i].Attempts+1)
+			}(i)
+		}
+		wg.Wait()
+
+		var retry []int
+		for _, i := range pending {
+			if retryable[i] {
+				retry = append(retry, i)
+			}
+		}
+		if len(retry) == 0 || attempt >= p.config.MaxRetries {
+			return
+		}
+
+		logger.WarnCtx(ctx, "Retrying push messages", zap.Int("messages", len(retry)), zap.Int("attempt", attempt+1))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		pending = retry
+		backoff *= 2
+	}
+}
+
+// deliver sends a message once, reporting whether a failure is worth retrying
+func (p *Push) deliver(ctx context.Context, msg *PushMessage, attempt int) (PushResult, bool) {
+	result := PushResult{Attempts: attempt}
+
+	provider, ok := p.providers[msg.Platform]
+	if !ok {
+		result.Status = PushFailed
+		result.Error = fmt.Sprintf("%s: %q", ErrNoPushProvider, msg.Platform)
+		return result, false
+	}
+
+	id, err := provider.send(ctx, msg)
+	if err == nil {
+		result.Status = PushDelivered
+		result.ProviderID = id
+		return result, false
+	}
+
+	result.Status = PushFailed
+	result.Error = err.Error()
+	var perr *pushError
+	if !errors.As(err, &perr) {
+		// The request did not get a response
+		return result, ctx.Err() == nil
+	}
+	if perr.invalidToken {
+		result.Status = PushInvalidToken
+	}
+	return result, perr.retryable
+}
@@ -0,0 +1,209 @@
+package resources
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/httpclient"
+)
+
+// apnsTokenTTL is how long a provider token is used; APNs rejects tokens older than an hour and
+// throttles tokens renewed more often than every 20 minutes
+const apnsTokenTTL = 50 * time.Minute
+
+// apnsInvalidTokenReasons are the APNs reasons of tokens that will never be valid again
+var apnsInvalidTokenReasons = map[string]bool{
+	"BadDeviceToken":         true,
+	"Unregistered":           true,
+	"DeviceTokenNotForTopic": true,
+}
+
+// apnsProvider delivers to iOS devices through APNs
+type apnsProvider struct {
+	client *httpclient.Client
+	topic  string
+	tokens *apnsTokenSource
+}
+
+// apnsTokenSource signs and caches the provider tokens authorizing requests to APNs
+type apnsTokenSource struct {
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// newAPNsProvider creates an APNs provider authorized by the configured signing key
+func newAPNsProvider(cfg config.PushConfig) (*apnsProvider, error) {
+	key, err := loadAPNsKey(cfg.APNsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.APNsKeyID == "" || cfg.APNsTeamID == "" {
+		return nil, errors.New("APNs key ID and team ID are required")
+	}
+	tokens := &apnsTokenSource{key: key, keyID: cfg.APNsKeyID, teamID: cfg.APNsTeamID}
+
+	clientConfig := httpclient.DefaultConfig(cfg.APNsBaseURL).
+		WithServiceName("push->apns").
+		WithRetryEnabled(false).
+		WithCircuitBreakerEnabled(false).
+		Use(httpclient.BearerToken(tokens.Token))
+	if cfg.Timeout > 0 {
+		clientConfig.WithRequestTimeout(cfg.Timeout)
+	}
+
+	client, err := httpclient.New(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &apnsProvider{client: client, topic: cfg.APNsTopic, tokens: tokens}, nil
+}
+
+// loadAPNsKey reads a .p8 token signing key
+func loadAPNsKey(file string) (*ecdsa.PrivateKey, error) {
+	if file == "" {
+		return nil, errors.New("no APNs key file")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("APNs key file has no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("APNs key is not an ECDSA key")
+	}
+	return key, nil
+}
+
+// send delivers a message, returning the apns-id of the notification
+func (p *apnsProvider) send(ctx context.Context, msg *PushMessage) (string, error) {
+	body := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range msg.Data {
+		if key != "aps" {
+			body[key] = value
+		}
+	}
+
+	opts := []httpclient.RequestOption{
+		httpclient.WithHeader("apns-topic", p.topic),
+		httpclient.WithHeader("apns-push-type", "alert"),
+		httpclient.WithHeader("apns-priority", "10"),
+	}
+	if msg.CollapseKey != "" {
+		opts = append(opts, httpclient.WithHeader("apns-collapse-id", msg.CollapseKey))
+	}
+
+	resp, err := p.client.Request(ctx, http.MethodPost, "/3/device/"+url.PathEscape(msg.Token), body, opts...)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp.Headers.Get("apns-id"), nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(resp.Body, &failure)
+	if failure.Reason == "" {
+		failure.Reason = http.StatusText(resp.StatusCode)
+	}
+
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	if failure.Reason == "ExpiredProviderToken" {
+		p.tokens.invalidate()
+		retryable = true
+	}
+	return "", &pushError{
+		statusCode:   resp.StatusCode,
+		reason:       failure.Reason,
+		invalidToken: apnsInvalidTokenReasons[failure.Reason],
+		retryable:    retryable,
+	}
+}
+
+func (p *apnsProvider) close() {
+	p.client.Close()
+}
+
+// Token returns the current provider token, signing a new one when it is due
+func (s *apnsTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Sub(s.issuedAt) < apnsTokenTTL {
+		return s.token, nil
+	}
+	token, err := s.sign(now)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.issuedAt = token, now
+	return token, nil
+}
+
+// invalidate makes the next request sign a new token
+func (s *apnsTokenSource) invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+// sign creates an ES256 provider token issued at now
+func (s *apnsTokenSource) sign(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "ES256", "kid": s.keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{"iss": s.teamID, "iat": now.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// JWS encodes the signature as the fixed-size r and s concatenated
+	size := (s.key.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	sig.FillBytes(signature[size:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
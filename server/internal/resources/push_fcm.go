@@ -0,0 +1,185 @@
+package resources
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/httpclient"
+)
+
+// fcmScope is the OAuth2 scope of the FCM HTTP v1 API
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// fcmInvalidTokenCodes are the FCM error codes of tokens that will never be valid again
+var fcmInvalidTokenCodes = map[string]bool{
+	"UNREGISTERED":       true,
+	"SENDER_ID_MISMATCH": true,
+}
+
+// fcmProvider delivers to Android and web devices through the FCM HTTP v1 API
+type fcmProvider struct {
+	client *httpclient.Client
+	path   string
+}
+
+// serviceAccount holds the fields of a Google service account key used to authorize requests
+type serviceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// fcmMessage is the body of an FCM send request
+type fcmMessage struct {
+	Message struct {
+		Token        string            `json:"token"`
+		Notification fcmNotification   `json:"notification"`
+		Data         map[string]string `json:"data,omitempty"`
+		Android      *fcmAndroid       `json:"android,omitempty"`
+	} `json:"message"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type fcmAndroid struct {
+	CollapseKey string `json:"collapse_key,omitempty"`
+}
+
+// fcmErrorResponse is the body of an FCM error response
+type fcmErrorResponse struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// newFCMProvider creates an FCM provider authorized by the configured service account
+// Retries and circuit breaking are left to Push, which retries each message on its own.
+func newFCMProvider(cfg config.PushConfig) (*fcmProvider, error) {
+	account, key, err := loadServiceAccount(cfg.FCMCredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig := httpclient.DefaultConfig(cfg.FCMBaseURL).
+		WithServiceName("push->fcm").
+		WithRetryEnabled(false).
+		WithCircuitBreakerEnabled(false)
+	if cfg.Timeout > 0 {
+		clientConfig.WithRequestTimeout(cfg.Timeout)
+	}
+	clientConfig.OAuth2 = &httpclient.OAuth2Config{
+		TokenURL:  account.TokenURI,
+		GrantType: httpclient.GrantJWTBearer,
+		Assertion: &httpclient.JWTAssertion{
+			Issuer:     account.ClientEmail,
+			Audience:   account.TokenURI,
+			KeyID:      account.PrivateKeyID,
+			PrivateKey: key,
+			Claims:     map[string]interface{}{"scope": fcmScope},
+		},
+	}
+
+	client, err := httpclient.New(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &fcmProvider{
+		client: client,
+		path:   fmt.Sprintf("/v1/projects/%s/messages:send", cfg.FCMProjectID),
+	}, nil
+}
+
+// loadServiceAccount reads a service account key file
+func loadServiceAccount(file string) (*serviceAccount, *rsa.PrivateKey, error) {
+	if file == "" {
+		return nil, nil, errors.New("no service account credentials file")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, nil, fmt.Errorf("invalid service account credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, nil, errors.New("service account credentials have no client email or token URI")
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, nil, errors.New("service account credentials have no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("service account private key is not an RSA key")
+	}
+	return &account, key, nil
+}
+
+// send delivers a message, returning the FCM message name
+func (p *fcmProvider) send(ctx context.Context, msg *PushMessage) (string, error) {
+	var body fcmMessage
+	body.Message.Token = msg.Token
+	body.Message.Notification = fcmNotification{Title: msg.Title, Body: msg.Body}
+	body.Message.Data = msg.Data
+	if msg.CollapseKey != "" && msg.Platform == PushPlatformAndroid {
+		body.Message.Android = &fcmAndroid{CollapseKey: msg.CollapseKey}
+	}
+
+	resp, err := p.client.Request(ctx, http.MethodPost, p.path, body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusOK {
+		var sent struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal(resp.Body, &sent)
+		return sent.Name, nil
+	}
+
+	var failure fcmErrorResponse
+	_ = json.Unmarshal(resp.Body, &failure)
+	reason := failure.Error.Status
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode != "" {
+			reason = detail.ErrorCode
+		}
+	}
+	if reason == "" {
+		reason = http.StatusText(resp.StatusCode)
+	}
+
+	return "", &pushError{
+		statusCode:   resp.StatusCode,
+		reason:       reason,
+		invalidToken: fcmInvalidTokenCodes[reason],
+		retryable:    resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+}
+
+func (p *fcmProvider) close() {
+	p.client.Close()
+}
@@ -55,6 +55,8 @@ func (r *Redis) Connect(ctx context.Context) error {
 		ReadTimeout:  r.config.Timeout,
 		WriteTimeout: r.config.Timeout,
 	})
+	client.AddHook(newCommandHook())
+	client.AddHook(newSlowLogHook(r.config.SlowCommandThreshold))
 
 	r.client = client
 
@@ -0,0 +1,186 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// maxKeyPrefixSegments caps the segments of the key prefixes recorded with cache lookups
+const maxKeyPrefixSegments = 2
+
+// cacheLookups are the commands whose nil replies are cache misses
+var cacheLookups = map[string]bool{
+	"get":    true,
+	"getex":  true,
+	"getdel": true,
+	"hget":   true,
+}
+
+// commandHook records per-command metrics of a Redis client, and the hits and misses of cache
+// lookups by key prefix
+type commandHook struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	lookups  metric.Int64Counter
+}
+
+// newCommandHook creates a hook recording command metrics; failures leave instruments nil and
+// only disable them
+func newCommandHook() *commandHook {
+	meter := otel.Meter("redis")
+	h := &commandHook{}
+
+	var err error
+	h.duration, err = meter.Float64Histogram("redis.command.duration",
+		metric.WithDescription("Duration of Redis commands and pipelines"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create redis duration histogram", zap.Error(err))
+	}
+
+	h.errors, err = meter.Int64Counter("redis.command.errors",
+		metric.WithDescription("Number of failed Redis commands by command"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create redis error counter", zap.Error(err))
+	}
+
+	h.lookups, err = meter.Int64Counter("redis.cache.lookups",
+		metric.WithDescription("Number of Redis cache lookups by key prefix and result"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create redis cache lookup counter", zap.Error(err))
+	}
+
+	return h
+}
+
+// DialHook passes dials through
+func (h *commandHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook records a command
+func (h *commandHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.recordDuration(ctx, cmd.Name(), time.Since(start), err)
+		h.record(ctx, cmd)
+		return err
+	}
+}
+
+// ProcessPipelineHook records a pipeline as a whole, and the result of each of its commands
+func (h *commandHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.recordDuration(ctx, "pipeline", time.Since(start), err)
+		for _, cmd := range cmds {
+			h.record(ctx, cmd)
+		}
+		return err
+	}
+}
+
+// recordDuration records how long command took
+func (h *commandHook) recordDuration(ctx context.Context, command string, duration time.Duration, err error) {
+	if h.duration == nil {
+		return
+	}
+	status := "ok"
+	if isCommandError(err) {
+		status = "error"
+	}
+	h.duration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("command", command),
+		attribute.String("status", status),
+	))
+}
+
+// record counts the failure of cmd, or the hit or miss of a cache lookup
+func (h *commandHook) record(ctx context.Context, cmd redis.Cmder) {
+	err := cmd.Err()
+	if isCommandError(err) {
+		if h.errors != nil {
+			h.errors.Add(ctx, 1, metric.WithAttributes(attribute.String("command", cmd.Name())))
+		}
+		return
+	}
+	if h.lookups == nil || !cacheLookups[cmd.Name()] {
+		return
+	}
+
+	result := "hit"
+	if errors.Is(err, redis.Nil) {
+		result = "miss"
+	}
+	h.lookups.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("prefix", keyPrefix(commandKey(cmd))),
+		attribute.String("result", result),
+	))
+}
+
+// isCommandError reports whether err is a failure; nil replies are not
+func isCommandError(err error) bool {
+	return err != nil && !errors.Is(err, redis.Nil)
+}
+
+// commandKey returns the first key of cmd, or "" when it has none
+func commandKey(cmd redis.Cmder) string {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return ""
+	}
+	return fmt.Sprint(args[1])
+}
+
+// keyPrefix returns the namespace of key, safe to record as a metric attribute: up to
+// maxKeyPrefixSegments leading colon-separated segments of letters, underscores and dashes, e.g.
+// repo:users for repo:users:42
+// The segment after the last colon, and any segment holding digits or other characters, is never
+// kept, so that IDs, emails and hashes do not become values of the attribute. Identifiers made only
+// of letters cannot be told from namespaces, so keys must not hold them in leading segments:
+// session:alice:tok records session:alice, one attribute value per user.
+func keyPrefix(key string) string {
+	segments := strings.Split(key, ":")
+	var prefix []string
+	for _, segment := range segments[:len(segments)-1] {
+		if len(prefix) == maxKeyPrefixSegments || !isNamespace(segment) {
+			break
+		}
+		prefix = append(prefix, segment)
+	}
+	if len(prefix) == 0 {
+		return "none"
+	}
+	return strings.Join(prefix, ":")
+}
+
+// isNamespace reports whether segment is a non-empty run of letters, underscores and dashes
+func isNamespace(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	for _, c := range segment {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
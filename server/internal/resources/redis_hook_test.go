@@ -0,0 +1,127 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "Keeps namespaces", key: "repo:users:42", want: "repo:users"},
+		{name: "Keeps at most two segments", key: "cache:http:quizzes:list", want: "cache:http"},
+		{name: "Keeps underscores and dashes", key: "rate_limit:login-ip:1.2.3.4", want: "rate_limit:login-ip"},
+		{name: "Drops numeric IDs", key: "users:42:profile", want: "users"},
+		{name: "Drops UUIDs", key: "sessions:0190a6b2-7c1e-7f3a-9b4d-2f6c8e1a5d3b:tokens", want: "sessions"},
+		{name: "Drops emails", key: "throttle:ada@example.com:failures", want: "throttle"},
+		{name: "Drops hashes", key: "cache:5d41402abc4b2a76b9719d911017c592:body", want: "cache"},
+		{name: "Drops base64 values", key: "tokens:dGVzdA==:meta", want: "tokens"},
+		{name: "Stops at the first identifier", key: "users:42:profile:avatar", want: "users"},
+		{name: "Never keeps the last segment", key: "users:profile", want: "users"},
+		{name: "Records single-segment keys as none", key: "leaderboard", want: "none"},
+		{name: "Records keys starting with an identifier as none", key: "42:users", want: "none"},
+		{name: "Records empty segments as none", key: ":users:42", want: "none"},
+		{name: "Records empty keys as none", key: "", want: "none"},
+		{name: "Keeps letters-only identifiers", key: "session:alice:tok", want: "session:alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, keyPrefix(tt.key))
+		})
+	}
+}
+
+// newTestHook creates a command hook recording to a manual reader
+func newTestHook(t *testing.T) (*commandHook, *sdkmetric.ManualReader) {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+	return newCommandHook(), reader
+}
+
+// counterValues returns the values of a counter by the value of one of its attributes
+func counterValues(t *testing.T, reader *sdkmetric.ManualReader, name string, attrs ...attribute.Key) map[string]int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+
+	values := make(map[string]int64)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				var label string
+				for i, key := range attrs {
+					value, _ := point.Attributes.Value(key)
+					if i > 0 {
+						label += " "
+					}
+					label += value.AsString()
+				}
+				values[label] += point.Value
+			}
+		}
+	}
+	return values
+}
+
+func TestCommandHook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Records pipelined lookups by key prefix", func(t *testing.T) {
+		hook, reader := newTestHook(t)
+
+		hit := redis.NewStringCmd(ctx, "get", "repo:users:42")
+		miss := redis.NewStringCmd(ctx, "get", "repo:users:43")
+		hashMiss := redis.NewStringCmd(ctx, "hget", "throttle:ada@example.com:failures", "count")
+		write := redis.NewStatusCmd(ctx, "set", "repo:users:42", "{}")
+		failed := redis.NewStringCmd(ctx, "get", "repo:quizzes:1")
+
+		pipeline := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+			hit.SetVal("{}")
+			miss.SetErr(redis.Nil)
+			hashMiss.SetErr(redis.Nil)
+			write.SetVal("OK")
+			failed.SetErr(errors.New("READONLY"))
+			return failed.Err()
+		})
+		require.Error(t, pipeline(ctx, []redis.Cmder{hit, miss, hashMiss, write, failed}))
+
+		assert.Equal(t, map[string]int64{
+			"repo:users hit":  1,
+			"repo:users miss": 1,
+			"throttle miss":   1,
+		}, counterValues(t, reader, "redis.cache.lookups", "prefix", "result"))
+		assert.Equal(t, map[string]int64{"get": 1}, counterValues(t, reader, "redis.command.errors", "command"))
+	})
+
+	t.Run("Records single commands", func(t *testing.T) {
+		hook, reader := newTestHook(t)
+
+		process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+			cmd.SetErr(redis.Nil)
+			return cmd.Err()
+		})
+		assert.ErrorIs(t, process(ctx, redis.NewStringCmd(ctx, "get", "leaderboard")), redis.Nil)
+
+		assert.Equal(t, map[string]int64{"none miss": 1}, counterValues(t, reader, "redis.cache.lookups", "prefix", "result"))
+		assert.Empty(t, counterValues(t, reader, "redis.command.errors", "command"))
+	})
+}
@@ -0,0 +1,106 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// slowLogHook logs Redis commands exceeding a latency threshold, to catch hot keys and big values,
+// and counts the commands that time out
+type slowLogHook struct {
+	threshold time.Duration
+	timeouts  metric.Int64Counter
+}
+
+// newSlowLogHook creates a hook logging commands slower than threshold (0 disables logging)
+func newSlowLogHook(threshold time.Duration) *slowLogHook {
+	h := &slowLogHook{threshold: threshold}
+
+	var err error
+	h.timeouts, err = otel.Meter("redis").Int64Counter("redis.command.timeouts",
+		metric.WithDescription("Number of Redis commands that timed out by command"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create redis timeout counter", zap.Error(err))
+	}
+	return h
+}
+
+// DialHook passes dials through
+func (h *slowLogHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook logs a slow command
+func (h *slowLogHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		duration := time.Since(start)
+
+		h.countTimeout(ctx, cmd.Name(), err)
+		if h.threshold > 0 && duration >= h.threshold {
+			logger.WarnCtx(ctx, "Slow Redis command",
+				zap.String("command", cmd.Name()),
+				zap.String("keyPrefix", keyPrefix(commandKey(cmd))),
+				zap.Duration("duration", duration),
+				zap.Duration("threshold", h.threshold),
+				zap.Bool("failed", isCommandError(err)),
+			)
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook logs a slow pipeline with the commands it sent
+func (h *slowLogHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		duration := time.Since(start)
+
+		for _, cmd := range cmds {
+			h.countTimeout(ctx, cmd.Name(), cmd.Err())
+		}
+		if h.threshold > 0 && duration >= h.threshold {
+			commands := make([]string, 0, len(cmds))
+			for _, cmd := range cmds {
+				commands = append(commands, cmd.Name()+" "+keyPrefix(commandKey(cmd)))
+			}
+			logger.WarnCtx(ctx, "Slow Redis pipeline",
+				zap.Strings("commands", commands),
+				zap.Duration("duration", duration),
+				zap.Duration("threshold", h.threshold),
+				zap.Bool("failed", isCommandError(err)),
+			)
+		}
+		return err
+	}
+}
+
+// countTimeout counts err when it is a timeout
+func (h *slowLogHook) countTimeout(ctx context.Context, command string, err error) {
+	if h.timeouts != nil && isTimeout(err) {
+		h.timeouts.Add(ctx, 1, metric.WithAttributes(attribute.String("command", command)))
+	}
+}
+
+// isTimeout reports whether err is a deadline exceeded by the context or the connection
+func isTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -19,6 +19,30 @@ var (
 type Resources struct {
 	DB    DBResource
 	Redis RedisResource
+
+	// Push delivers push notifications; it is optional
+	Push PushResource
+
+	// Search is the Elasticsearch or OpenSearch cluster; it is optional
+	Search SearchResource
+
+	// Storage is the S3-compatible bucket uploads go to; it is optional
+	Storage ObjectStorageResource
+}
+
+// list returns the resources that are set
+func (r *Resources) list() []Resource {
+	list := []Resource{r.DB, r.Redis}
+	if r.Push != nil {
+		list = append(list, r.Push)
+	}
+	if r.Search != nil {
+		list = append(list, r.Search)
+	}
+	if r.Storage != nil {
+		list = append(list, r.Storage)
+	}
+	return list
 }
 
 // resourceInitResult holds the result of a resource initialization
@@ -46,10 +70,11 @@ type Resource interface {
 
 // HealthCheck performs a health check on a resource
 type HealthCheck struct {
-	Name    string    `json:"name"`
-	Status  string    `json:"status"`
-	Message string    `json:"message,omitempty"`
-	Time    time.Time `json:"time"`
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Message  string    `json:"message,omitempty"`
+	Optional bool      `json:"optional,omitempty"`
+	Time     time.Time `json:"time"`
 }
 
 // CheckHealth checks the health of a resource
@@ -103,10 +128,7 @@ func InitResources(ctx context.Context, resources *Resources) error {
 	logger.Info("Initializing resources concurrently")
 
 	// Create a list of all resources to initialize
-	resourcesList := []Resource{
-		resources.DB,
-		resources.Redis,
-	}
+	resourcesList := resources.list()
 
 	// Channel to collect initialization results
 	resultsChan := make(chan resourceInitResult, len(resourcesList))
@@ -182,10 +204,7 @@ func CloseResources(ctx context.Context, resources *Resources) {
 	logger.Info("Closing resources")
 
 	// Create a list of all resources to close
-	resourcesList := []Resource{
-		resources.DB,
-		resources.Redis,
-	}
+	resourcesList := resources.list()
 
 	// Channel to collect close results
 	resultsChan := make(chan resourceInitResult, len(resourcesList))
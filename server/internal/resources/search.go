@@ -0,0 +1,376 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/pkg/httpclient"
+)
+
+// Bulk actions
+const (
+	BulkIndex  = "index"
+	BulkDelete = "delete"
+)
+
+// IndexTemplate holds the settings and mappings applied to the indices matching its patterns
+// when they are created
+type IndexTemplate struct {
+	IndexPatterns []string
+	Priority      int
+	Settings      map[string]interface{}
+	Mappings      map[string]interface{}
+}
+
+// BulkOperation is an operation of a bulk request
+type BulkOperation struct {
+	// Action is BulkIndex, which creates or replaces the document, or BulkDelete
+	Action   string
+	Index    string
+	ID       string
+	Document interface{}
+}
+
+// BulkResult is the outcome of a BulkOperation
+type BulkResult struct {
+	ID     string
+	Status int
+	Error  string
+}
+
+// Failed reports whether the operation failed; deleting a missing document does not
+func (r BulkResult) Failed() bool {
+	return r.Error != ""
+}
+
+// SearchRequest is a search of an index
+type SearchRequest struct {
+	Query Query
+	Sort  []Sort
+	From  int
+	Size  int
+}
+
+// SearchHit is a document matching a search
+type SearchHit struct {
+	ID     string
+	Source json.RawMessage
+}
+
+// SearchResponse holds a page of the documents matching a search and the number of documents
+// matching it
+type SearchResponse struct {
+	Total int64
+	Hits  []SearchHit
+}
+
+// SearchError is an error response of the search cluster
+type SearchError struct {
+	StatusCode int
+	Type       string
+	Reason     string
+}
+
+func (e *SearchError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("search cluster responded %d", e.StatusCode)
+	}
+	return fmt.Sprintf("search cluster responded %d: %s: %s", e.StatusCode, e.Type, e.Reason)
+}
+
+// SearchResource defines the interface for Elasticsearch and OpenSearch resources
+// Indices are usually reached through an alias, so that they can be rebuilt and swapped without
+// changing the name searches use.
+type SearchResource interface {
+	Resource
+
+	// PutIndexTemplate creates or replaces the index template named name
+	PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error
+
+	// CreateIndex creates an index, with the settings and mappings of the templates it matches
+	CreateIndex(ctx context.Context, index string) error
+
+	// DeleteIndex deletes an index; deleting a missing index is not an error
+	DeleteIndex(ctx context.Context, index string) error
+
+	// AliasIndices returns the indices alias points to, none when it does not exist
+	AliasIndices(ctx context.Context, alias string) ([]string, error)
+
+	// SetAlias atomically points alias to index only
+	SetAlias(ctx context.Context, alias, index string) error
+
+	// Bulk sends operations in one request and returns the result of each, in order
+	// Failures of single operations are reported in their results, not as an error.
+	Bulk(ctx context.Context, operations []BulkOperation) ([]BulkResult, error)
+
+	// Search returns the documents of index, or of the indices of an alias, matching request
+	Search(ctx context.Context, index string, request SearchRequest) (*SearchResponse, error)
+}
+
+// Search implements the SearchResource interface over the REST API common to Elasticsearch and
+// OpenSearch
+type Search struct {
+	config    config.SearchConfig
+	client    *httpclient.Client
+	options   []httpclient.RequestOption
+	connected bool
+}
+
+// NewSearch creates a new Search resource
+func NewSearch(cfg *config.Config) SearchResource {
+	return &Search{config: cfg.Search}
+}
+
+// Connect creates the client of the cluster; the cluster is not called, so that the application
+// starts, falling back to MongoDB searches, while the cluster is unavailable
+func (s *Search) Connect(ctx context.Context) error {
+	clientConfig := httpclient.DefaultConfig(s.config.URL).
+		WithServiceName("search").
+		WithBreakerKeyFunc(httpclient.BreakerKeyByPath)
+	if s.config.Timeout > 0 {
+		clientConfig.WithRequestTimeout(s.config.Timeout)
+	}
+
+	client, err := httpclient.New(clientConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create search client: %w", err)
+	}
+	s.client = client
+	if s.config.Username != "" {
+		s.options = []httpclient.RequestOption{httpclient.WithBasicAuth(s.config.Username, s.config.Password)}
+	}
+	s.connected = true
+	return nil
+}
+
+// Close releases the connections of the client
+func (s *Search) Close(ctx context.Context) error {
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.connected = false
+	return nil
+}
+
+// Ping checks the health of the cluster, failing when it is red
+func (s *Search) Ping(ctx context.Context) error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/_cluster/health", nil, &health); err != nil {
+		return err
+	}
+	if health.Status == "red" {
+		return fmt.Errorf("search cluster status is %s", health.Status)
+	}
+	return nil
+}
+
+// Name returns the name of the resource
+func (s *Search) Name() string {
+	return "search"
+}
+
+// PutIndexTemplate creates or replaces a composable index template
+func (s *Search) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	body := map[string]interface{}{
+		"index_patterns": template.IndexPatterns,
+		"priority":       template.Priority,
+		"template": map[string]interface{}{
+			"settings": template.Settings,
+			"mappings": template.Mappings,
+		},
+	}
+	return s.do(ctx, http.MethodPut, "/_index_template/"+url.PathEscape(name), body, nil)
+}
+
+// CreateIndex creates an index
+func (s *Search) CreateIndex(ctx context.Context, index string) error {
+	return s.do(ctx, http.MethodPut, "/"+url.PathEscape(index), nil, nil)
+}
+
+// DeleteIndex deletes an index
+func (s *Search) DeleteIndex(ctx context.Context, index string) error {
+	err := s.do(ctx, http.MethodDelete, "/"+url.PathEscape(index), nil, nil)
+	if isSearchStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+// AliasIndices returns the indices alias points to
+func (s *Search) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	var aliases map[string]json.RawMessage
+	err := s.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, &aliases)
+	if isSearchStatus(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(aliases))
+	for index := range aliases {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// SetAlias removes alias from every index and adds it to index in one request
+func (s *Search) SetAlias(ctx context.Context, alias, index string) error {
+	body := map[string]interface{}{
+		"actions": []map[string]interface{}{
+			{"remove": map[string]interface{}{"index": "*", "alias": alias, "must_exist": false}},
+			{"add": map[string]interface{}{"index": index, "alias": alias}},
+		},
+	}
+	return s.do(ctx, http.MethodPost, "/_aliases", body, nil)
+}
+
+// bulkResponse is the body of a bulk response
+type bulkResponse struct {
+	Items []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// Bulk sends operations as an NDJSON bulk request
+func (s *Search) Bulk(ctx context.Context, operations []BulkOperation) ([]BulkResult, error) {
+	if len(operations) == 0 {
+		return nil, nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, op := range operations {
+		action := map[string]interface{}{op.Action: map[string]string{"_index": op.Index, "_id": op.ID}}
+		if err := encoder.Encode(action); err != nil {
+			return nil, err
+		}
+		if op.Action == BulkIndex {
+			if err := encoder.Encode(op.Document); err != nil {
+				return nil, fmt.Errorf("failed to encode document %s: %w", op.ID, err)
+			}
+		}
+	}
+
+	var resp bulkResponse
+	opts := []httpclient.RequestOption{httpclient.WithHeader("Content-Type", "application/x-ndjson")}
+	if err := s.do(ctx, http.MethodPost, "/_bulk", body.Bytes(), &resp, opts...); err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(operations))
+	for i, op := range operations {
+		results[i] = BulkResult{ID: op.ID}
+		if i >= len(resp.Items) {
+			results[i].Error = "missing from the bulk response"
+			continue
+		}
+		item := resp.Items[i][op.Action]
+		results[i].Status = item.Status
+		if item.Error != nil {
+			results[i].Error = item.Error.Type + ": " + item.Error.Reason
+		}
+	}
+	return results, nil
+}
+
+// searchResponse is the body of a search response
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// Search runs request, counting every matching document
+func (s *Search) Search(ctx context.Context, index string, request SearchRequest) (*SearchResponse, error) {
+	query := request.Query
+	if query == nil {
+		query = MatchAll()
+	}
+	body := map[string]interface{}{
+		"query":            query.Source(),
+		"from":             request.From,
+		"track_total_hits": true,
+	}
+	if request.Size > 0 {
+		body["size"] = request.Size
+	}
+	if len(request.Sort) > 0 {
+		sorts := make([]map[string]interface{}, len(request.Sort))
+		for i, sort := range request.Sort {
+			sorts[i] = sort.Source()
+		}
+		body["sort"] = sorts
+	}
+
+	var resp searchResponse
+	if err := s.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResponse{Total: resp.Hits.Total.Value, Hits: make([]SearchHit, len(resp.Hits.Hits))}
+	for i, hit := range resp.Hits.Hits {
+		result.Hits[i] = SearchHit{ID: hit.ID, Source: hit.Source}
+	}
+	return result, nil
+}
+
+// do sends a request and decodes the response into target, when given, returning a *SearchError
+// for error responses
+func (s *Search) do(ctx context.Context, method, path string, body, target interface{}, opts ...httpclient.RequestOption) error {
+	if !s.connected {
+		return ErrResourceNotConnected
+	}
+
+	resp, err := s.client.Request(ctx, method, path, body, append(opts, s.options...)...)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(resp.Body, &failure)
+		return &SearchError{StatusCode: resp.StatusCode, Type: failure.Error.Type, Reason: failure.Error.Reason}
+	}
+	if target == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, target); err != nil {
+		return fmt.Errorf("invalid response to %s %s: %w", method, strings.SplitN(path, "?", 2)[0], err)
+	}
+	return nil
+}
+
+// isSearchStatus reports whether err is an error response with status
+func isSearchStatus(err error, status int) bool {
+	var searchErr *SearchError
+	return errors.As(err, &searchErr) && searchErr.StatusCode == status
+}
@@ -0,0 +1,210 @@
+package resources
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Query is a typed search query
+// Source returns its query DSL; matches evaluates it against a document the way MockSearch needs,
+// approximating the analysis of text fields by splitting on spaces and ignoring case.
+type Query interface {
+	Source() map[string]interface{}
+	matches(doc map[string]interface{}) bool
+}
+
+// Sort orders search results by a field
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+// Source returns the DSL of the sort
+func (s Sort) Source() map[string]interface{} {
+	order := "asc"
+	if s.Descending {
+		order = "desc"
+	}
+	return map[string]interface{}{s.Field: map[string]interface{}{"order": order}}
+}
+
+// MatchAll matches every document
+func MatchAll() Query {
+	return matchAllQuery{}
+}
+
+type matchAllQuery struct{}
+
+func (matchAllQuery) Source() map[string]interface{} {
+	return map[string]interface{}{"match_all": map[string]interface{}{}}
+}
+
+func (matchAllQuery) matches(doc map[string]interface{}) bool {
+	return true
+}
+
+// Match matches the documents whose text field holds any word of text
+func Match(field, text string) Query {
+	return matchQuery{field: field, text: text}
+}
+
+type matchQuery struct {
+	field, text string
+}
+
+func (q matchQuery) Source() map[string]interface{} {
+	return map[string]interface{}{"match": map[string]interface{}{q.field: map[string]interface{}{"query": q.text}}}
+}
+
+func (q matchQuery) matches(doc map[string]interface{}) bool {
+	words := strings.Fields(strings.ToLower(fmt.Sprint(lookup(doc, q.field))))
+	for _, word := range strings.Fields(strings.ToLower(q.text)) {
+		if slices.Contains(words, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Term matches the documents whose keyword field, or one of its values, equals value
+func Term(field string, value interface{}) Query {
+	return termQuery{field: field, value: value}
+}
+
+type termQuery struct {
+	field string
+	value interface{}
+}
+
+func (q termQuery) Source() map[string]interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{q.field: q.value}}
+}
+
+func (q termQuery) matches(doc map[string]interface{}) bool {
+	want := fmt.Sprint(q.value)
+	switch value := lookup(doc, q.field).(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, v := range value {
+			if fmt.Sprint(v) == want {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(value) == want
+	}
+}
+
+// Contains matches the documents whose keyword field contains text, ignoring case
+func Contains(field, text string) Query {
+	return containsQuery{field: field, text: text}
+}
+
+type containsQuery struct {
+	field, text string
+}
+
+func (q containsQuery) Source() map[string]interface{} {
+	return map[string]interface{}{"wildcard": map[string]interface{}{q.field: map[string]interface{}{
+		"value":            "*" + escapeWildcard(q.text) + "*",
+		"case_insensitive": true,
+	}}}
+}
+
+func (q containsQuery) matches(doc map[string]interface{}) bool {
+	value := lookup(doc, q.field)
+	return value != nil && strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(q.text))
+}
+
+// escapeWildcard escapes the characters of text that wildcard queries interpret
+func escapeWildcard(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(text)
+}
+
+// DateRange matches the documents whose date field is at or after from and strictly before to;
+// a zero bound is left open
+func DateRange(field string, from, to time.Time) Query {
+	return dateRangeQuery{field: field, from: from, to: to}
+}
+
+type dateRangeQuery struct {
+	field    string
+	from, to time.Time
+}
+
+func (q dateRangeQuery) Source() map[string]interface{} {
+	bounds := map[string]interface{}{}
+	if !q.from.IsZero() {
+		bounds["gte"] = q.from.UTC().Format(time.RFC3339Nano)
+	}
+	if !q.to.IsZero() {
+		bounds["lt"] = q.to.UTC().Format(time.RFC3339Nano)
+	}
+	return map[string]interface{}{"range": map[string]interface{}{q.field: bounds}}
+}
+
+func (q dateRangeQuery) matches(doc map[string]interface{}) bool {
+	value, ok := lookup(doc, q.field).(string)
+	if !ok {
+		return false
+	}
+	date, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return false
+	}
+	return (q.from.IsZero() || !date.Before(q.from)) && (q.to.IsZero() || date.Before(q.to))
+}
+
+// Bool combines queries: documents match when they match every Must and Filter query and no
+// MustNot query; only Must queries contribute to the relevance score
+type Bool struct {
+	Must    []Query
+	Filter  []Query
+	MustNot []Query
+}
+
+// Source returns the DSL of the query
+func (q Bool) Source() map[string]interface{} {
+	clauses := map[string]interface{}{}
+	for name, queries := range map[string][]Query{"must": q.Must, "filter": q.Filter, "must_not": q.MustNot} {
+		if len(queries) == 0 {
+			continue
+		}
+		sources := make([]map[string]interface{}, len(queries))
+		for i, query := range queries {
+			sources[i] = query.Source()
+		}
+		clauses[name] = sources
+	}
+	return map[string]interface{}{"bool": clauses}
+}
+
+func (q Bool) matches(doc map[string]interface{}) bool {
+	for _, query := range append(slices.Clone(q.Must), q.Filter...) {
+		if !query.matches(doc) {
+			return false
+		}
+	}
+	for _, query := range q.MustNot {
+		if query.matches(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value of field in doc; the sub-fields of multi-fields, e.g. name.keyword,
+// hold the value of their parent
+func lookup(doc map[string]interface{}, field string) interface{} {
+	if value, ok := doc[field]; ok {
+		return value
+	}
+	if parent, _, ok := strings.Cut(field, "."); ok {
+		return doc[parent]
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// Package retention removes data past its retention period
+//
+// Collections declare a Policy of Rules selecting expired documents, either by age or by how long
+// ago they were soft-deleted. The Enforcer runs as a periodic job on the job queue and purges or
+// archives matching documents in batches, pausing between batches so a large backlog does not
+// overload the database.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// JobType is the job type enforcing the retention policies
+const JobType = "retention.purge"
+
+// Action is what happens to expired documents
+type Action string
+
+const (
+	// ActionPurge deletes expired documents
+	ActionPurge Action = "purge"
+
+	// ActionArchive moves expired documents to the collection's archive collection
+	ActionArchive Action = "archive"
+)
+
+// Rule selects the documents of a collection that expired
+type Rule struct {
+	// Name identifies the rule in logs and metrics
+	Name string
+
+	// Field is the time field documents expire by
+	Field string
+
+	// MaxAge is how long documents are kept past Field; rules with no MaxAge are disabled
+	MaxAge time.Duration
+
+	// Filter restricts the rule to matching documents, e.g. to jobs in a final status
+	Filter bson.M
+
+	// Action is applied to expired documents, ActionPurge by default
+	Action Action
+}
+
+// OlderThan returns a rule purging documents whose field is older than maxAge
+func OlderThan(field string, maxAge time.Duration) Rule {
+	return Rule{Name: field + "_older_than", Field: field, MaxAge: maxAge, Action: ActionPurge}
+}
+
+// SoftDeletedOlderThan returns a rule purging documents soft-deleted more than maxAge ago
+func SoftDeletedOlderThan(maxAge time.Duration) Rule {
+	return Rule{Name: "soft_deleted", Field: repository.DeletedAtField, MaxAge: maxAge, Action: ActionPurge}
+}
+
+// Archive returns a copy of the rule archiving expired documents instead of deleting them
+func (r Rule) Archive() Rule {
+	r.Action = ActionArchive
+	return r
+}
+
+// filter returns the filter matching documents expired at now
+func (r Rule) filter(now time.Time) bson.M {
+	filter := bson.M{r.Field: bson.M{"$lt": now.Add(-r.MaxAge)}}
+	for key, value := range r.Filter {
+		filter[key] = value
+	}
+	return filter
+}
+
+// Policy holds the retention rules of a collection
+type Policy struct {
+	Collection repository.Purger
+	Rules      []Rule
+}
+
+// Result is the outcome of enforcing a rule
+type Result struct {
+	Collection string `json:"collection"`
+	Rule       string `json:"rule"`
+	Action     Action `json:"action"`
+	Removed    int64  `json:"removed"`
+}
+
+// Enforcer applies the retention policies
+type Enforcer struct {
+	policies []Policy
+	clock    clock.Clock
+	config   config.RetentionConfig
+
+	removed metric.Int64Counter
+}
+
+// NewEnforcer creates an Enforcer of policies and registers its job on queue,
+// scheduled every cfg.Interval when cfg.Enabled is set
+func NewEnforcer(policies []Policy, queue *jobs.Queue, clk clock.Clock, cfg config.RetentionConfig) *Enforcer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.MaxBatches <= 0 {
+		cfg.MaxBatches = 1
+	}
+
+	e := &Enforcer{
+		policies: policies,
+		clock:    clk,
+		config:   cfg,
+	}
+	e.initInstruments()
+
+	queue.Register(JobType, func(ctx context.Context, job *repository.Job) error {
+		_, err := e.Run(ctx)
+		return err
+	})
+	if cfg.Enabled {
+		queue.Every(JobType, cfg.Interval)
+	}
+	return e
+}
+
+// initInstruments creates the retention counter; failures leave it nil and only disable metrics
+func (e *Enforcer) initInstruments() {
+	var err error
+	e.removed, err = otel.Meter("retention").Int64Counter("retention.removed",
+		metric.WithDescription("Number of documents removed by retention by collection, rule and action"),
+	)
+	if err != nil {
+		logger.Error("Failed to create retention.removed counter", zap.Error(err))
+	}
+}
+
+// Run enforces every rule once, returning what each removed
+// A failing rule does not stop the others; their errors are returned together.
+func (e *Enforcer) Run(ctx context.Context) ([]Result, error) {
+	var results []Result
+	var errs []error
+	for _, policy := range e.policies {
+		for _, rule := range policy.Rules {
+			if rule.MaxAge <= 0 {
+				continue
+			}
+
+			result, err := e.enforce(ctx, policy.Collection, rule)
+			results = append(results, result)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w", result.Collection, rule.Name, err))
+			}
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// enforce removes the documents expired under rule, at most MaxBatches batches of BatchSize
+func (e *Enforcer) enforce(ctx context.Context, collection repository.Purger, rule Rule) (Result, error) {
+	action := rule.Action
+	if action == "" {
+		action = ActionPurge
+	}
+	result := Result{Collection: collection.Name(), Rule: rule.Name, Action: action}
+	filter := rule.filter(e.clock.Now())
+
+	log := logger.With(
+		zap.String("collection", result.Collection),
+		zap.String("rule", rule.Name),
+		zap.String("action", string(action)),
+	)
+
+	for batch := 0; batch < e.config.MaxBatches; batch++ {
+		if batch > 0 && e.config.BatchDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-e.clock.After(e.config.BatchDelay):
+			}
+		}
+
+		n, err := collection.PurgeBatch(ctx, filter, e.config.BatchSize, action == ActionArchive)
+		result.Removed += n
+		e.record(ctx, result, n)
+		if err != nil {
+			log.Error("Failed to enforce retention", zap.Int64("removed", result.Removed), zap.Error(err))
+			return result, err
+		}
+		if n < int64(e.config.BatchSize) {
+			break
+		}
+	}
+
+	if result.Removed > 0 {
+		log.Info("Retention enforced", zap.Int64("removed", result.Removed))
+	}
+	return result, nil
+}
+
+// record adds removed documents to the retention metrics
+func (e *Enforcer) record(ctx context.Context, result Result, n int64) {
+	if e.removed == nil || n == 0 {
+		return
+	}
+	e.removed.Add(ctx, n, metric.WithAttributes(
+		attribute.String("collection", result.Collection),
+		attribute.String("rule", result.Rule),
+		attribute.String("action", string(result.Action)),
+	))
+}
@@ -0,0 +1,186 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var testConfig = config.RetentionConfig{
+	Enabled:    true,
+	Interval:   time.Hour,
+	BatchSize:  10,
+	MaxBatches: 3,
+}
+
+// fakePurger is a repository.Purger holding a number of expired documents
+type fakePurger struct {
+	name      string
+	remaining int64
+	err       error
+
+	filters []bson.M
+	archive []bool
+}
+
+func (p *fakePurger) Name() string { return p.name }
+
+func (p *fakePurger) PurgeBatch(ctx context.Context, filter bson.M, limit int, archive bool) (int64, error) {
+	p.filters = append(p.filters, filter)
+	p.archive = append(p.archive, archive)
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	n := min(int64(limit), p.remaining)
+	p.remaining -= n
+	return n, nil
+}
+
+func newTestEnforcer(cfg config.RetentionConfig, policies ...Policy) (*Enforcer, *jobs.Queue, *testutil.FakeClock) {
+	clk := testutil.NewFakeClock(testTime)
+	queue := jobs.NewQueue(repository.NewMockJobRepository(clk), clk, config.JobsConfig{MaxAttempts: 1})
+	return NewEnforcer(policies, queue, clk, cfg), queue, clk
+}
+
+func TestRule_filter(t *testing.T) {
+	t.Run("Age", func(t *testing.T) {
+		rule := OlderThan("createdAt", time.Hour)
+		assert.Equal(t, bson.M{"createdAt": bson.M{"$lt": testTime.Add(-time.Hour)}}, rule.filter(testTime))
+		assert.Equal(t, ActionPurge, rule.Action)
+		assert.Equal(t, ActionArchive, rule.Archive().Action)
+	})
+
+	t.Run("Soft-deleted", func(t *testing.T) {
+		rule := SoftDeletedOlderThan(time.Hour)
+		assert.Equal(t, bson.M{"deletedAt": bson.M{"$lt": testTime.Add(-time.Hour)}}, rule.filter(testTime))
+	})
+
+	t.Run("Extra filter", func(t *testing.T) {
+		rule := Rule{Field: "updatedAt", MaxAge: time.Hour, Filter: bson.M{"status": "dead"}}
+		assert.Equal(t, bson.M{
+			"updatedAt": bson.M{"$lt": testTime.Add(-time.Hour)},
+			"status":    "dead",
+		}, rule.filter(testTime))
+	})
+}
+
+func TestEnforcer_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Purges in batches", func(t *testing.T) {
+		sessions := &fakePurger{name: "sessions", remaining: 25}
+		enforcer, _, _ := newTestEnforcer(testConfig, Policy{
+			Collection: sessions,
+			Rules:      []Rule{OlderThan("createdAt", time.Hour)},
+		})
+
+		results, err := enforcer.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []Result{{Collection: "sessions", Rule: "createdAt_older_than", Action: ActionPurge, Removed: 25}}, results)
+		assert.Len(t, sessions.filters, 3, "the last batch is short")
+		assert.Equal(t, []bool{false, false, false}, sessions.archive)
+	})
+
+	t.Run("Batches are bounded per run", func(t *testing.T) {
+		sessions := &fakePurger{name: "sessions", remaining: 100}
+		enforcer, _, _ := newTestEnforcer(testConfig, Policy{
+			Collection: sessions,
+			Rules:      []Rule{OlderThan("createdAt", time.Hour)},
+		})
+
+		results, err := enforcer.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(30), results[0].Removed)
+		assert.Equal(t, int64(70), sessions.remaining, "the rest is left for the next run")
+	})
+
+	t.Run("Archives", func(t *testing.T) {
+		audit := &fakePurger{name: "audit_log", remaining: 1}
+		enforcer, _, _ := newTestEnforcer(testConfig, Policy{
+			Collection: audit,
+			Rules:      []Rule{OlderThan("createdAt", time.Hour).Archive()},
+		})
+
+		results, err := enforcer.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, ActionArchive, results[0].Action)
+		assert.Equal(t, []bool{true}, audit.archive)
+	})
+
+	t.Run("Disabled rules are skipped", func(t *testing.T) {
+		sessions := &fakePurger{name: "sessions", remaining: 1}
+		enforcer, _, _ := newTestEnforcer(testConfig, Policy{
+			Collection: sessions,
+			Rules:      []Rule{OlderThan("createdAt", 0)},
+		})
+
+		results, err := enforcer.Run(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		assert.Empty(t, sessions.filters)
+	})
+
+	t.Run("Failures do not stop other rules", func(t *testing.T) {
+		broken := &fakePurger{name: "broken", err: errors.New("boom")}
+		sessions := &fakePurger{name: "sessions", remaining: 1}
+		enforcer, _, _ := newTestEnforcer(testConfig,
+			Policy{Collection: broken, Rules: []Rule{OlderThan("createdAt", time.Hour)}},
+			Policy{Collection: sessions, Rules: []Rule{SoftDeletedOlderThan(time.Hour)}},
+		)
+
+		results, err := enforcer.Run(ctx)
+		assert.ErrorContains(t, err, "broken/createdAt_older_than: boom")
+		require.Len(t, results, 2)
+		assert.Equal(t, int64(1), results[1].Removed)
+	})
+
+	t.Run("Pauses between batches", func(t *testing.T) {
+		cfg := testConfig
+		cfg.BatchDelay = time.Second
+		sessions := &fakePurger{name: "sessions", remaining: 15}
+		enforcer, _, clk := newTestEnforcer(cfg, Policy{
+			Collection: sessions,
+			Rules:      []Rule{OlderThan("createdAt", time.Hour)},
+		})
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := enforcer.Run(ctx)
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool { return clk.Waiters() == 1 }, 5*time.Second, time.Millisecond)
+		clk.Advance(time.Second)
+		require.NoError(t, <-done)
+		assert.Equal(t, int64(0), sessions.remaining)
+	})
+}
+
+func TestEnforcer_Job(t *testing.T) {
+	ctx := context.Background()
+	sessions := &fakePurger{name: "sessions", remaining: 1}
+	_, queue, _ := newTestEnforcer(testConfig, Policy{
+		Collection: sessions,
+		Rules:      []Rule{OlderThan("createdAt", time.Hour)},
+	})
+
+	_, err := queue.Enqueue(ctx, JobType, nil)
+	require.NoError(t, err)
+
+	processed, err := queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+	assert.Equal(t, int64(0), sessions.remaining)
+}
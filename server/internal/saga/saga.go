@@ -0,0 +1,343 @@
+// Package saga coordinates workflows spanning several services as sagas
+//
+// A Workflow is a sequence of Steps, each with an optional compensation undoing it. Start persists
+// a saga and runs it on the job queue: steps run in order, and the saga's progress is saved after
+// each one, so a saga whose worker dies resumes from its last completed step once the job's lease
+// expires. A step failing with a jobs.Permanent error, or on the job's last attempt, makes the saga
+// compensate: the completed steps are undone, last first. A saga whose compensation fails for good
+// is marked failed for manual attention.
+//
+// Steps and compensations may run more than once (e.g. when a worker dies after a step but before
+// saving the saga), so they must be idempotent.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// JobType is the job type running sagas
+const JobType = "saga.run"
+
+// ErrUnknownWorkflow is returned when starting a workflow that was not defined
+var ErrUnknownWorkflow = errors.New("unknown workflow")
+
+// Phases of a step, used as the "phase" metric attribute
+const (
+	PhaseAction     = "action"
+	PhaseCompensate = "compensate"
+)
+
+// Results of running a step, used as the "result" metric attribute
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// State is the state of a saga passed to its steps
+type State struct {
+	// ID is the ID of the saga, e.g. to derive idempotency keys of downstream calls
+	ID string
+
+	// Data is shared by the steps and saved after each one
+	Data map[string]string
+}
+
+// Action runs a step, or compensates it
+type Action func(ctx context.Context, state *State) error
+
+// Step is a step of a workflow
+type Step struct {
+	// Name identifies the step in logs and metrics
+	Name string
+
+	// Action runs the step
+	Action Action
+
+	// Compensate undoes the step once a later step failed; steps with nothing to undo leave it nil
+	Compensate Action
+}
+
+// Workflow is a named sequence of steps
+type Workflow struct {
+	Name  string
+	Steps []Step
+}
+
+// payload is the payload of a saga job
+type payload struct {
+	SagaID string `json:"saga_id"`
+}
+
+// Coordinator defines workflows and runs their sagas
+type Coordinator struct {
+	repo  repository.SagaRepository
+	queue *jobs.Queue
+	clock clock.Clock
+
+	mu        sync.RWMutex
+	workflows map[string]Workflow
+
+	steps    metric.Int64Counter
+	duration metric.Float64Histogram
+	finished metric.Int64Counter
+}
+
+// NewCoordinator creates a Coordinator storing sagas in repo and registers its job on queue
+func NewCoordinator(repo repository.SagaRepository, queue *jobs.Queue, clk clock.Clock) *Coordinator {
+	c := &Coordinator{
+		repo:      repo,
+		queue:     queue,
+		clock:     clk,
+		workflows: make(map[string]Workflow),
+	}
+	c.initInstruments()
+
+	queue.Register(JobType, c.run)
+	return c
+}
+
+// initInstruments creates the saga counters; failures leave them nil and only disable metrics
+func (c *Coordinator) initInstruments() {
+	meter := otel.Meter("saga")
+
+	var err error
+	c.steps, err = meter.Int64Counter("saga.steps",
+		metric.WithDescription("Number of saga step runs by workflow, step, phase and result"),
+	)
+	if err != nil {
+		logger.Error("Failed to create saga.steps counter", zap.Error(err))
+	}
+	c.duration, err = meter.Float64Histogram("saga.step.duration",
+		metric.WithDescription("Duration of saga step runs by workflow, step, phase and result"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Error("Failed to create saga.step.duration histogram", zap.Error(err))
+	}
+	c.finished, err = meter.Int64Counter("saga.finished",
+		metric.WithDescription("Number of sagas finished by workflow and status"),
+	)
+	if err != nil {
+		logger.Error("Failed to create saga.finished counter", zap.Error(err))
+	}
+}
+
+// Define registers a workflow; define workflows before starting the job workers, so sagas
+// resumed after a restart find theirs
+func (c *Coordinator) Define(workflow Workflow) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.workflows[workflow.Name] = workflow
+}
+
+// Start persists a saga of workflow with data and enqueues running it
+func (c *Coordinator) Start(ctx context.Context, workflow string, data map[string]string) (*repository.Saga, error) {
+	if _, ok := c.workflow(workflow); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWorkflow, workflow)
+	}
+	if data == nil {
+		data = make(map[string]string)
+	}
+
+	saga := &repository.Saga{Workflow: workflow, Status: repository.SagaRunning, Data: data}
+	if err := c.repo.Create(ctx, saga); err != nil {
+		return nil, err
+	}
+	if _, err := c.queue.Enqueue(ctx, JobType, payload{SagaID: saga.ID}); err != nil {
+		return nil, err
+	}
+
+	logger.InfoCtx(ctx, "Saga started", zap.String("sagaId", saga.ID), zap.String("workflow", workflow))
+	return saga, nil
+}
+
+// Get returns a saga by ID, or repository.ErrNotFound
+func (c *Coordinator) Get(ctx context.Context, id string) (*repository.Saga, error) {
+	return c.repo.Get(ctx, id)
+}
+
+// run runs a saga job, resuming the saga where it stopped
+func (c *Coordinator) run(ctx context.Context, job *repository.Job) error {
+	var p payload
+	if err := jobs.Decode(job, &p); err != nil {
+		return err
+	}
+
+	saga, err := c.repo.Get(ctx, p.SagaID)
+	if err == repository.ErrNotFound {
+		return jobs.Permanent(fmt.Errorf("saga %s not found", p.SagaID))
+	}
+	if err != nil {
+		return err
+	}
+	workflow, ok := c.workflow(saga.Workflow)
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("%w: %s", ErrUnknownWorkflow, saga.Workflow))
+	}
+
+	state := &State{ID: saga.ID, Data: saga.Data}
+	if state.Data == nil {
+		state.Data = make(map[string]string)
+	}
+	lastAttempt := job.Attempts >= job.MaxAttempts
+
+	switch saga.Status {
+	case repository.SagaRunning:
+		return c.forward(ctx, saga, workflow, state, lastAttempt)
+	case repository.SagaCompensating:
+		return c.compensate(ctx, saga, workflow, state, lastAttempt)
+	}
+	return nil
+}
+
+// forward runs the remaining steps of saga, saving it after each one
+// A step failing for good makes the saga compensate in a new job, with attempts of its own.
+func (c *Coordinator) forward(ctx context.Context, saga *repository.Saga, workflow Workflow, state *State, lastAttempt bool) error {
+	log := logger.With(zap.String("sagaId", saga.ID), zap.String("workflow", saga.Workflow))
+
+	for saga.Step < len(workflow.Steps) {
+		step := workflow.Steps[saga.Step]
+		err := c.runStep(ctx, workflow.Name, step.Name, PhaseAction, step.Action, state)
+		if err != nil {
+			if !lastAttempt && !jobs.IsPermanent(err) {
+				return err
+			}
+
+			log.Warn("Saga step failed, compensating", zap.String("step", step.Name), zap.Error(err))
+			saga.Status = repository.SagaCompensating
+			saga.Error = fmt.Sprintf("%s: %v", step.Name, err)
+			if err := c.save(ctx, saga); err != nil {
+				return err
+			}
+			_, err = c.queue.Enqueue(ctx, JobType, payload{SagaID: saga.ID})
+			return err
+		}
+
+		saga.Step++
+		saga.Data = state.Data
+		if err := c.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	saga.Status = repository.SagaCompleted
+	if err := c.save(ctx, saga); err != nil {
+		return err
+	}
+	c.finish(ctx, saga)
+	log.Info("Saga completed")
+	return nil
+}
+
+// compensate undoes the completed steps of saga, last first, saving it after each one
+// A compensation failing for good marks the saga failed and dead-letters the job.
+func (c *Coordinator) compensate(ctx context.Context, saga *repository.Saga, workflow Workflow, state *State, lastAttempt bool) error {
+	log := logger.With(zap.String("sagaId", saga.ID), zap.String("workflow", saga.Workflow))
+
+	for saga.Step > 0 {
+		step := workflow.Steps[saga.Step-1]
+		if step.Compensate != nil {
+			err := c.runStep(ctx, workflow.Name, step.Name, PhaseCompensate, step.Compensate, state)
+			if err != nil {
+				if !lastAttempt && !jobs.IsPermanent(err) {
+					return err
+				}
+
+				saga.Status = repository.SagaFailed
+				saga.Error = fmt.Sprintf("compensating %s: %v (after %s)", step.Name, err, saga.Error)
+				if err := c.save(ctx, saga); err != nil {
+					return err
+				}
+				c.finish(ctx, saga)
+				log.Error("Saga failed to compensate", zap.String("step", step.Name), zap.Error(err))
+				return jobs.Permanent(fmt.Errorf("saga %s failed to compensate %s: %w", saga.ID, step.Name, err))
+			}
+		}
+
+		saga.Step--
+		saga.Data = state.Data
+		if err := c.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	saga.Status = repository.SagaCompensated
+	if err := c.save(ctx, saga); err != nil {
+		return err
+	}
+	c.finish(ctx, saga)
+	log.Info("Saga compensated", zap.String("error", saga.Error))
+	return nil
+}
+
+// runStep runs the action of a step in phase and records it in the step metrics
+func (c *Coordinator) runStep(ctx context.Context, workflow, step, phase string, action Action, state *State) (err error) {
+	start := c.clock.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("saga step panicked: %v", r)
+		}
+
+		result := ResultSucceeded
+		if err != nil {
+			result = ResultFailed
+		}
+		c.record(ctx, workflow, step, phase, result, c.clock.Now().Sub(start))
+	}()
+	return action(ctx, state)
+}
+
+// save persists the progress of saga; a version conflict means another worker runs it, so the
+// job is retried and resumes from the progress that worker saved
+func (c *Coordinator) save(ctx context.Context, saga *repository.Saga) error {
+	if err := c.repo.Save(ctx, saga); err != nil {
+		return fmt.Errorf("failed to save saga %s: %w", saga.ID, err)
+	}
+	return nil
+}
+
+// record adds a step run to the step metrics
+func (c *Coordinator) record(ctx context.Context, workflow, step, phase, result string, elapsed time.Duration) {
+	attrs := metric.WithAttributes(
+		attribute.String("workflow", workflow),
+		attribute.String("step", step),
+		attribute.String("phase", phase),
+		attribute.String("result", result),
+	)
+	if c.steps != nil {
+		c.steps.Add(ctx, 1, attrs)
+	}
+	if c.duration != nil {
+		c.duration.Record(ctx, elapsed.Seconds(), attrs)
+	}
+}
+
+// finish counts a saga reaching a final status
+func (c *Coordinator) finish(ctx context.Context, saga *repository.Saga) {
+	if c.finished != nil {
+		c.finished.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("workflow", saga.Workflow),
+			attribute.String("status", saga.Status),
+		))
+	}
+}
+
+func (c *Coordinator) workflow(name string) (Workflow, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	workflow, ok := c.workflows[name]
+	return workflow, ok
+}
@@ -0,0 +1,201 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+type testEnv struct {
+	coordinator *Coordinator
+	queue       *jobs.Queue
+	jobs        *repository.MockJobRepository
+	clock       *testutil.FakeClock
+
+	// calls lists the actions run, e.g. "create" or "undo create"
+	calls []string
+}
+
+func newTestEnv() *testEnv {
+	clk := testutil.NewFakeClock(testTime)
+	env := &testEnv{
+		jobs:  repository.NewMockJobRepository(clk),
+		clock: clk,
+	}
+	env.queue = jobs.NewQueue(env.jobs, clk, config.JobsConfig{MaxAttempts: 3, RetryBackoff: time.Second})
+	env.coordinator = NewCoordinator(repository.NewMockSagaRepository(clk), env.queue, clk)
+	return env
+}
+
+// step returns a step recording its calls; fail decides whether its action fails on a call
+func (env *testEnv) step(name string, fail func() error) Step {
+	return Step{
+		Name: name,
+		Action: func(ctx context.Context, state *State) error {
+			env.calls = append(env.calls, name)
+			if fail != nil {
+				if err := fail(); err != nil {
+					return err
+				}
+			}
+			state.Data[name] = "done"
+			return nil
+		},
+		Compensate: func(ctx context.Context, state *State) error {
+			env.calls = append(env.calls, "undo "+name)
+			delete(state.Data, name)
+			return nil
+		},
+	}
+}
+
+// drain runs the due jobs, advancing the clock past retry backoffs, until none is left
+func (env *testEnv) drain(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		processed, err := env.queue.RunOnce(context.Background())
+		require.NoError(t, err)
+		if !processed {
+			env.clock.Advance(time.Minute)
+			processed, err = env.queue.RunOnce(context.Background())
+			require.NoError(t, err)
+			if !processed {
+				return
+			}
+		}
+	}
+}
+
+func TestCoordinator_Start(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Runs the steps in order", func(t *testing.T) {
+		env := newTestEnv()
+		env.coordinator.Define(Workflow{Name: "signup", Steps: []Step{
+			env.step("create", nil),
+			env.step("email", nil),
+			env.step("index", nil),
+		}})
+
+		saga, err := env.coordinator.Start(ctx, "signup", map[string]string{"userId": "user-1"})
+		require.NoError(t, err)
+		env.drain(t)
+
+		assert.Equal(t, []string{"create", "email", "index"}, env.calls)
+		stored, err := env.coordinator.Get(ctx, saga.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.SagaCompleted, stored.Status)
+		assert.Equal(t, 3, stored.Step)
+		assert.Equal(t, map[string]string{"userId": "user-1", "create": "done", "email": "done", "index": "done"}, stored.Data)
+	})
+
+	t.Run("Unknown workflow", func(t *testing.T) {
+		env := newTestEnv()
+
+		_, err := env.coordinator.Start(ctx, "missing", nil)
+		assert.ErrorIs(t, err, ErrUnknownWorkflow)
+	})
+}
+
+func TestCoordinator_Resume(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv()
+	failures := 1
+	env.coordinator.Define(Workflow{Name: "signup", Steps: []Step{
+		env.step("create", nil),
+		env.step("email", func() error {
+			if failures > 0 {
+				failures--
+				return errors.New("mail server unavailable")
+			}
+			return nil
+		}),
+	}})
+
+	saga, err := env.coordinator.Start(ctx, "signup", nil)
+	require.NoError(t, err)
+	env.drain(t)
+
+	assert.Equal(t, []string{"create", "email", "email"}, env.calls, "the retry resumes after the completed step")
+	stored, err := env.coordinator.Get(ctx, saga.ID)
+	require.NoError(t, err)
+	assert.Equal(t, repository.SagaCompleted, stored.Status)
+}
+
+func TestCoordinator_Compensate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Permanent failure undoes the completed steps", func(t *testing.T) {
+		env := newTestEnv()
+		env.coordinator.Define(Workflow{Name: "signup", Steps: []Step{
+			env.step("create", nil),
+			{Name: "notify", Action: func(ctx context.Context, state *State) error {
+				env.calls = append(env.calls, "notify")
+				return nil
+			}},
+			env.step("webhook", func() error { return jobs.Permanent(errors.New("endpoint gone")) }),
+		}})
+
+		saga, err := env.coordinator.Start(ctx, "signup", nil)
+		require.NoError(t, err)
+		env.drain(t)
+
+		assert.Equal(t, []string{"create", "notify", "webhook", "undo create"}, env.calls)
+		stored, err := env.coordinator.Get(ctx, saga.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.SagaCompensated, stored.Status)
+		assert.Equal(t, 0, stored.Step)
+		assert.Equal(t, "webhook: endpoint gone", stored.Error)
+		assert.Empty(t, stored.Data)
+	})
+
+	t.Run("Failing every attempt compensates", func(t *testing.T) {
+		env := newTestEnv()
+		env.coordinator.Define(Workflow{Name: "signup", Steps: []Step{
+			env.step("create", nil),
+			env.step("index", func() error { return errors.New("search unavailable") }),
+		}})
+
+		saga, err := env.coordinator.Start(ctx, "signup", nil)
+		require.NoError(t, err)
+		env.drain(t)
+
+		assert.Equal(t, []string{"create", "index", "index", "index", "undo create"}, env.calls)
+		stored, err := env.coordinator.Get(ctx, saga.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.SagaCompensated, stored.Status)
+	})
+
+	t.Run("Failed compensation marks the saga failed", func(t *testing.T) {
+		env := newTestEnv()
+		env.coordinator.Define(Workflow{Name: "signup", Steps: []Step{
+			{
+				Name:   "create",
+				Action: func(ctx context.Context, state *State) error { return nil },
+				Compensate: func(ctx context.Context, state *State) error {
+					return jobs.Permanent(errors.New("user already active"))
+				},
+			},
+			env.step("index", func() error { return jobs.Permanent(errors.New("invalid document")) }),
+		}})
+
+		saga, err := env.coordinator.Start(ctx, "signup", nil)
+		require.NoError(t, err)
+		env.drain(t)
+
+		stored, err := env.coordinator.Get(ctx, saga.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.SagaFailed, stored.Status)
+		assert.Equal(t, 1, stored.Step, "the step that could not be undone stays completed")
+		assert.Contains(t, stored.Error, "compensating create: user already active")
+	})
+}
@@ -0,0 +1,324 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+)
+
+// ReindexJobType is the job type reindexing users
+const ReindexJobType = "search.reindex"
+
+// Results of indexing a document, used as the "result" metric attribute
+const (
+	IndexSucceeded = "succeeded"
+	IndexFailed    = "failed"
+)
+
+// Indexer keeps the users index up to date from the domain events of user writes
+//
+// Handle only records the IDs of the changed users; they are indexed in bulk once BulkSize are
+// pending or FlushInterval elapsed, from the user stored at that time, so repeated changes of a
+// user are indexed once and deleted users are removed. IDs whose bulk request fails are kept for
+// the next flush; documents the cluster rejects are logged and counted, and the next Reindex
+// catches up with them.
+type Indexer struct {
+	index *Users
+	users repository.UserRepository
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	shadow  string
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	indexed metric.Int64Counter
+}
+
+// NewIndexer creates an Indexer of the users stored in users and registers its reindex job on
+// queue; call Start to index in the background
+func NewIndexer(index *Users, users repository.UserRepository, queue *jobs.Queue) *Indexer {
+	i := &Indexer{
+		index:  index,
+		users:  users,
+		queued: make(map[string]bool),
+		wake:   make(chan struct{}, 1),
+	}
+	i.initInstruments()
+
+	queue.Register(ReindexJobType, i.runReindex)
+	return i
+}
+
+// initInstruments creates the indexing counter; failures leave it nil and only disable metrics
+func (i *Indexer) initInstruments() {
+	var err error
+	i.indexed, err = otel.Meter("search").Int64Counter("search.indexed",
+		metric.WithDescription("Number of documents indexed by index and result"),
+	)
+	if err != nil {
+		logger.Error("Failed to create search.indexed counter", zap.Error(err))
+	}
+}
+
+// Handle records the user a domain event is about for indexing; events of other types, and every
+// event while search is disabled, are ignored
+func (i *Indexer) Handle(ctx context.Context, event *events.Event) error {
+	if !i.index.Enabled() {
+		return nil
+	}
+
+	var userID string
+	switch payload := event.Payload.(type) {
+	case *events.UserCreatedV1:
+		userID = payload.UserID
+	case *events.UserUpdatedV1:
+		userID = payload.UserID
+	case *events.UserDeletedV1:
+		userID = payload.UserID
+	default:
+		return nil
+	}
+	i.Add(userID)
+	return nil
+}
+
+// Add records users for indexing, waking the background flush once a bulk request is full
+func (i *Indexer) Add(userIDs ...string) {
+	if i.queue(userIDs) {
+		select {
+		case i.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// queue records users for indexing, reporting whether a bulk request is full
+func (i *Indexer) queue(userIDs []string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, id := range userIDs {
+		if !i.queued[id] {
+			i.queued[id] = true
+			i.pending = append(i.pending, id)
+		}
+	}
+	return len(i.pending) >= i.bulkSize()
+}
+
+// Start indexes the pending users in the background until Stop is called
+func (i *Indexer) Start(ctx context.Context) {
+	if !i.index.Enabled() {
+		return
+	}
+	ctx, i.cancel = context.WithCancel(ctx)
+	i.done = make(chan struct{})
+
+	go func() {
+		defer close(i.done)
+		if err := i.index.Setup(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to set up the users index; searches fall back to MongoDB until it is", zap.Error(err))
+		}
+
+		ticker := i.index.clock.NewTicker(i.flushInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			case <-i.wake:
+			}
+			if err := i.Flush(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("Failed to index users", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// Stop stops indexing in the background, then indexes the pending users until ctx is done
+func (i *Indexer) Stop(ctx context.Context) error {
+	if i.cancel == nil {
+		return nil
+	}
+	i.cancel()
+
+	select {
+	case <-i.done:
+	case <-ctx.Done():
+		return fmt.Errorf("search indexer did not stop: %w", ctx.Err())
+	}
+	return i.Flush(ctx)
+}
+
+// Flush indexes the pending users in bulk requests of BulkSize users
+func (i *Indexer) Flush(ctx context.Context) error {
+	for {
+		i.mu.Lock()
+		batch := i.pending[:min(len(i.pending), i.bulkSize())]
+		i.pending = i.pending[len(batch):]
+		for _, id := range batch {
+			delete(i.queued, id)
+		}
+		shadow := i.shadow
+		i.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := i.indexUsers(ctx, batch, shadow); err != nil {
+			// Keep the batch for the next flush, without waking it while the cluster fails
+			i.queue(batch)
+			return err
+		}
+	}
+}
+
+// indexUsers indexes the users with ids as they are stored, removing the deleted ones, in the
+// index of the alias and in shadow when it is set
+func (i *Indexer) indexUsers(ctx context.Context, ids []string, shadow string) error {
+	operations := make([]resources.BulkOperation, 0, len(ids))
+	for _, id := range ids {
+		user, err := i.users.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to load user %s: %w", id, err)
+		}
+		operations = append(operations, userOperation(i.index.alias, id, user))
+		if shadow != "" {
+			operations = append(operations, userOperation(shadow, id, user))
+		}
+	}
+	return i.bulk(ctx, operations)
+}
+
+// userOperation returns the operation indexing user, or deleting it when it was deleted
+func userOperation(index, id string, user *domain.User) resources.BulkOperation {
+	if user == nil {
+		return resources.BulkOperation{Action: resources.BulkDelete, Index: index, ID: id}
+	}
+	return resources.BulkOperation{Action: resources.BulkIndex, Index: index, ID: id, Document: NewUserDocument(user)}
+}
+
+// bulk sends operations, logging and counting the documents the cluster rejects
+func (i *Indexer) bulk(ctx context.Context, operations []resources.BulkOperation) error {
+	results, err := i.index.search.Bulk(ctx, operations)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+			logger.WarnCtx(ctx, "Failed to index user", zap.String("user_id", result.ID), zap.String("error", result.Error))
+		}
+	}
+	if i.indexed != nil {
+		index := attribute.String("index", i.index.alias)
+		i.indexed.Add(ctx, int64(len(results)-failed), metric.WithAttributes(index, attribute.String("result", IndexSucceeded)))
+		if failed > 0 {
+			i.indexed.Add(ctx, int64(failed), metric.WithAttributes(index, attribute.String("result", IndexFailed)))
+		}
+	}
+	return nil
+}
+
+// Reindex fills a new index with every stored user, then points the alias to it and deletes the
+// previous indices, returning the number of users indexed
+// Users changed meanwhile are indexed in both the previous and the new index, so searches see
+// them before and after the swap.
+func (i *Indexer) Reindex(ctx context.Context) (int64, error) {
+	if err := i.index.Setup(ctx); err != nil {
+		return 0, err
+	}
+	previous, err := i.index.search.AliasIndices(ctx, i.index.alias)
+	if err != nil {
+		return 0, err
+	}
+	index, err := i.index.createIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	i.mu.Lock()
+	i.shadow = index
+	i.mu.Unlock()
+	defer func() {
+		i.mu.Lock()
+		i.shadow = ""
+		i.mu.Unlock()
+	}()
+
+	var indexed int64
+	batch := make([]resources.BulkOperation, 0, i.bulkSize())
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := i.bulk(ctx, batch); err != nil {
+			return err
+		}
+		indexed += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+	err = i.users.Stream(ctx, domain.UserFilter{}, func(user *domain.User) error {
+		batch = append(batch, userOperation(index, user.ID, user))
+		if len(batch) < i.bulkSize() {
+			return nil
+		}
+		return send()
+	})
+	if err == nil {
+		err = send()
+	}
+	if err != nil {
+		_ = i.index.search.DeleteIndex(context.WithoutCancel(ctx), index)
+		return indexed, fmt.Errorf("failed to fill index %s: %w", index, err)
+	}
+
+	if err := i.index.search.SetAlias(ctx, i.index.alias, index); err != nil {
+		return indexed, err
+	}
+	for _, old := range previous {
+		if err := i.index.search.DeleteIndex(ctx, old); err != nil {
+			logger.WarnCtx(ctx, "Failed to delete previous index", zap.String("index", old), zap.Error(err))
+		}
+	}
+	logger.InfoCtx(ctx, "Users reindexed", zap.String("index", index), zap.Int64("users", indexed))
+	return indexed, nil
+}
+
+// runReindex is the handler of reindex jobs
+func (i *Indexer) runReindex(ctx context.Context, job *repository.Job) error {
+	_, err := i.Reindex(ctx)
+	return err
+}
+
+// bulkSize returns the number of users per bulk request
+func (i *Indexer) bulkSize() int {
+	return max(i.index.config.BulkSize, 1)
+}
+
+// flushInterval returns the longest users stay pending
+func (i *Indexer) flushInterval() time.Duration {
+	if i.index.config.FlushInterval <= 0 {
+		return time.Second
+	}
+	return i.index.config.FlushInterval
+}
@@ -0,0 +1,217 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/idgen"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var testConfig = config.SearchConfig{Enabled: true, IndexPrefix: "test", BulkSize: 2, FlushInterval: time.Second}
+
+var testIDs = idgen.Func(func() string { return "event-1" })
+
+type testEnv struct {
+	cluster *resources.MockSearch
+	clock   *testutil.FakeClock
+	users   repository.UserRepository
+	queue   *jobs.Queue
+	index   *Users
+	indexer *Indexer
+}
+
+func newTestEnv(t *testing.T, cfg config.SearchConfig) *testEnv {
+	t.Helper()
+	env := &testEnv{
+		cluster: resources.NewMockSearch(),
+		clock:   testutil.NewFakeClock(testTime),
+		users:   repository.NewMockUserRepository(),
+	}
+	require.NoError(t, env.cluster.Connect(context.Background()))
+	env.queue = jobs.NewQueue(repository.NewMockJobRepository(env.clock), env.clock, config.JobsConfig{MaxAttempts: 1})
+	env.index = NewUsers(env.cluster, env.clock, cfg)
+	env.indexer = NewIndexer(env.index, env.users, env.queue)
+	return env
+}
+
+// createUser stores a user and records it for indexing like its user.created event
+func (env *testEnv) createUser(t *testing.T, id, name string, createdAt time.Time) {
+	t.Helper()
+	user := &domain.User{ID: id, Name: domain.UserName(name), Email: domain.Email(id + "@example.com"), CreatedAt: createdAt}
+	require.NoError(t, env.users.Create(context.Background(), user))
+	env.handle(t, events.UserCreated(user))
+}
+
+// handle passes an event of payload to the indexer
+func (env *testEnv) handle(t *testing.T, payload events.Payload) {
+	t.Helper()
+	event, err := events.New(testIDs, testTime, payload)
+	require.NoError(t, err)
+	require.NoError(t, env.indexer.Handle(context.Background(), event))
+}
+
+func TestUserQuery(t *testing.T) {
+	locked := true
+	request := UserQuery(domain.UserFilter{
+		Query:        "ada",
+		Email:        "ada@example.com",
+		CreatedAfter: testTime,
+		Locked:       &locked,
+	}, domain.ListOptions{Sort: domain.UserSortName, Offset: 20, Limit: 10})
+
+	source, err := json.Marshal(request.Query.Source())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool": {
+		"must": [{"match": {"name": {"query": "ada"}}}],
+		"filter": [
+			{"term": {"email": "ada@example.com"}},
+			{"range": {"created_at": {"gte": "2024-01-02T03:04:05Z"}}},
+			{"term": {"locked": true}}
+		]
+	}}`, string(source))
+	assert.Equal(t, []resources.Sort{{Field: "name.keyword"}, {Field: "id"}}, request.Sort)
+	assert.Equal(t, 20, request.From)
+	assert.Equal(t, 10, request.Size)
+
+	t.Run("Defaults to newest first", func(t *testing.T) {
+		request := UserQuery(domain.UserFilter{}, domain.ListOptions{})
+		assert.Equal(t, resources.Sort{Field: "created_at", Descending: true}, request.Sort[0])
+		assert.Equal(t, maxResultWindow, request.Size)
+	})
+}
+
+func TestUsers_Setup(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testConfig)
+
+	require.NoError(t, env.index.Setup(ctx))
+	assert.Equal(t, []string{"test-users"}, env.cluster.Templates())
+	indices, err := env.cluster.AliasIndices(ctx, "test-users")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-users-20240102030405"}, indices)
+
+	// Setting up again keeps the index
+	env.clock.Advance(time.Hour)
+	require.NoError(t, env.index.Setup(ctx))
+	indices, err = env.cluster.AliasIndices(ctx, "test-users")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-users-20240102030405"}, indices)
+}
+
+func TestIndexer_Flush(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Indexes changed users", func(t *testing.T) {
+		env := newTestEnv(t, testConfig)
+		require.NoError(t, env.index.Setup(ctx))
+		env.createUser(t, "user-1", "Ada Lovelace", testTime)
+		env.createUser(t, "user-2", "Alan Turing", testTime.Add(time.Hour))
+		env.createUser(t, "user-3", "Grace Hopper", testTime.Add(2*time.Hour))
+
+		require.NoError(t, env.indexer.Flush(ctx))
+		assert.Equal(t, []string{"user-1", "user-2", "user-3"}, env.cluster.Documents("test-users"))
+
+		users, total, err := env.index.Search(ctx, domain.UserFilter{Query: "alan"}, domain.ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "user-2", users[0].ID)
+		assert.Equal(t, domain.Email("user-2@example.com"), users[0].Email)
+
+		users, total, err = env.index.Search(ctx, domain.UserFilter{}, domain.ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, "user-3", users[0].ID, "newest first")
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("Removes deleted users", func(t *testing.T) {
+		env := newTestEnv(t, testConfig)
+		require.NoError(t, env.index.Setup(ctx))
+		env.createUser(t, "user-1", "Ada Lovelace", testTime)
+		require.NoError(t, env.indexer.Flush(ctx))
+
+		require.NoError(t, env.users.Delete(ctx, "user-1"))
+		env.handle(t, events.UserDeleted("user-1", testTime))
+		require.NoError(t, env.indexer.Flush(ctx))
+		assert.Empty(t, env.cluster.Documents("test-users"))
+	})
+
+	t.Run("Keeps users for the next flush while the cluster fails", func(t *testing.T) {
+		env := newTestEnv(t, testConfig)
+		require.NoError(t, env.index.Setup(ctx))
+		env.createUser(t, "user-1", "Ada Lovelace", testTime)
+
+		env.cluster.Fail(errors.New("unavailable"))
+		assert.Error(t, env.indexer.Flush(ctx))
+
+		env.cluster.Fail(nil)
+		require.NoError(t, env.indexer.Flush(ctx))
+		assert.Equal(t, []string{"user-1"}, env.cluster.Documents("test-users"))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		env := newTestEnv(t, config.SearchConfig{IndexPrefix: "test"})
+		env.createUser(t, "user-1", "Ada Lovelace", testTime)
+
+		require.NoError(t, env.indexer.Flush(ctx))
+		assert.Empty(t, env.cluster.Documents("test-users"))
+
+		_, _, err := env.index.Search(ctx, domain.UserFilter{}, domain.ListOptions{})
+		assert.ErrorIs(t, err, ErrDisabled)
+	})
+}
+
+func TestIndexer_Start(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testConfig)
+
+	env.indexer.Start(ctx)
+	env.createUser(t, "user-1", "Ada Lovelace", testTime)
+	env.createUser(t, "user-2", "Alan Turing", testTime)
+
+	// A full bulk request is sent without waiting for the flush interval
+	require.Eventually(t, func() bool {
+		return len(env.cluster.Documents("test-users")) == 2
+	}, 5*time.Second, time.Millisecond)
+
+	// Stopping indexes the pending users
+	env.createUser(t, "user-3", "Grace Hopper", testTime)
+	require.NoError(t, env.indexer.Stop(ctx))
+	assert.Equal(t, []string{"user-1", "user-2", "user-3"}, env.cluster.Documents("test-users"))
+}
+
+func TestIndexer_Reindex(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, testConfig)
+	require.NoError(t, env.index.Setup(ctx))
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		require.NoError(t, env.users.Create(ctx, &domain.User{ID: id, Name: "Test User", Email: domain.Email(id + "@example.com")}))
+	}
+
+	env.clock.Advance(time.Hour)
+	_, err := env.queue.Enqueue(ctx, ReindexJobType, nil)
+	require.NoError(t, err)
+	processed, err := env.queue.RunOnce(ctx)
+	require.NoError(t, err)
+	require.True(t, processed)
+
+	indices, err := env.cluster.AliasIndices(ctx, "test-users")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-users-20240102040405"}, indices)
+	assert.Equal(t, []string{"user-1", "user-2", "user-3"}, env.cluster.Documents("test-users"))
+	assert.Empty(t, env.cluster.Documents("test-users-20240102030405"), "the previous index is deleted")
+}
@@ -0,0 +1,238 @@
+// Package search answers user searches from an Elasticsearch or OpenSearch index
+//
+// Users are indexed in the index an alias points to: Setup puts the index template and creates
+// the first index, and Reindex, which runs as a job, fills a new index from MongoDB then swaps
+// the alias, so mappings can change without downtime. The Indexer keeps the index up to date from
+// domain events, sending bulk requests. Searches go through the typed queries of resources; callers fall back to MongoDB
+// when search is disabled or the cluster fails.
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// ErrDisabled is returned by searches while search is disabled
+var ErrDisabled = errors.New("search is disabled")
+
+// usersIndex is the name of the users index, before the prefix
+const usersIndex = "users"
+
+// maxResultWindow is the default limit on the results of a search, which unlimited searches get
+const maxResultWindow = 10000
+
+// indexVersionLayout names the indices behind an alias by their creation time
+const indexVersionLayout = "20060102150405"
+
+// UserDocument is the indexed form of a user
+type UserDocument struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Roles     []string  `json:"roles,omitempty"`
+	Locked    bool      `json:"locked"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+
+	Avatar *domain.Avatar `json:"avatar,omitempty"`
+}
+
+// NewUserDocument returns the indexed form of user
+func NewUserDocument(user *domain.User) *UserDocument {
+	return &UserDocument{
+		ID:        user.ID,
+		Name:      string(user.Name),
+		Email:     string(user.Email),
+		Roles:     user.Roles,
+		Locked:    user.Locked,
+		CreatedAt: user.CreatedAt.UTC(),
+		UpdatedAt: user.UpdatedAt.UTC(),
+		Version:   user.Version,
+		Avatar:    user.Avatar,
+	}
+}
+
+// user returns the user doc holds
+func (doc *UserDocument) user() *domain.User {
+	return &domain.User{
+		ID:        doc.ID,
+		Name:      domain.UserName(doc.Name),
+		Email:     domain.Email(doc.Email),
+		Roles:     doc.Roles,
+		Locked:    doc.Locked,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+		Version:   doc.Version,
+		Avatar:    doc.Avatar,
+	}
+}
+
+// usersTemplate is the template of the users indices; name is a text field with a keyword
+// sub-field for sorting and substring matches, and avatars are stored without being indexed
+var usersTemplate = resources.IndexTemplate{
+	Settings: map[string]interface{}{
+		"number_of_shards":   1,
+		"number_of_replicas": 1,
+	},
+	Mappings: map[string]interface{}{
+		"dynamic": "strict",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{"type": "keyword"},
+			"name": map[string]interface{}{
+				"type":   "text",
+				"fields": map[string]interface{}{"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256}},
+			},
+			"email":      map[string]interface{}{"type": "keyword"},
+			"roles":      map[string]interface{}{"type": "keyword"},
+			"locked":     map[string]interface{}{"type": "boolean"},
+			"created_at": map[string]interface{}{"type": "date"},
+			"updated_at": map[string]interface{}{"type": "date"},
+			"version":    map[string]interface{}{"type": "long"},
+			"avatar":     map[string]interface{}{"type": "object", "enabled": false},
+		},
+	},
+}
+
+// userSortFields maps user sort fields to index fields
+var userSortFields = map[string]string{
+	domain.UserSortName:      "name.keyword",
+	domain.UserSortEmail:     "email",
+	domain.UserSortCreatedAt: "created_at",
+}
+
+// Users is the index of users
+type Users struct {
+	search resources.SearchResource
+	clock  clock.Clock
+	config config.SearchConfig
+	alias  string
+}
+
+// NewUsers creates the index of users kept in search, named after cfg.IndexPrefix
+func NewUsers(search resources.SearchResource, clk clock.Clock, cfg config.SearchConfig) *Users {
+	alias := usersIndex
+	if cfg.IndexPrefix != "" {
+		alias = cfg.IndexPrefix + "-" + usersIndex
+	}
+	return &Users{
+		search: search,
+		clock:  clk,
+		config: cfg,
+		alias:  alias,
+	}
+}
+
+// Enabled reports whether searches use the index
+func (u *Users) Enabled() bool {
+	return u != nil && u.config.Enabled && u.search != nil
+}
+
+// Alias returns the alias searches and writes go through
+func (u *Users) Alias() string {
+	return u.alias
+}
+
+// Setup puts the index template and, when the alias points to no index, creates one behind it
+func (u *Users) Setup(ctx context.Context) error {
+	template := usersTemplate
+	template.IndexPatterns = []string{u.alias + "-*"}
+	if err := u.search.PutIndexTemplate(ctx, u.alias, template); err != nil {
+		return fmt.Errorf("failed to put the %s index template: %w", u.alias, err)
+	}
+
+	indices, err := u.search.AliasIndices(ctx, u.alias)
+	if err != nil {
+		return err
+	}
+	if len(indices) > 0 {
+		return nil
+	}
+	index, err := u.createIndex(ctx)
+	if err != nil {
+		return err
+	}
+	return u.search.SetAlias(ctx, u.alias, index)
+}
+
+// createIndex creates a new index behind the alias, named after the current time
+func (u *Users) createIndex(ctx context.Context) (string, error) {
+	index := u.alias + "-" + u.clock.Now().UTC().Format(indexVersionLayout)
+	if err := u.search.CreateIndex(ctx, index); err != nil {
+		return "", fmt.Errorf("failed to create index %s: %w", index, err)
+	}
+	return index, nil
+}
+
+// Search returns the page of users matching the filter selected by opts, and the number of users
+// matching it, sorted like the MongoDB search
+// It returns ErrDisabled while search is disabled.
+func (u *Users) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	if !u.Enabled() {
+		return nil, 0, ErrDisabled
+	}
+
+	resp, err := u.search.Search(ctx, u.alias, UserQuery(filter, opts))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*domain.User, len(resp.Hits))
+	for i, hit := range resp.Hits {
+		var doc UserDocument
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return nil, 0, fmt.Errorf("invalid user document %s: %w", hit.ID, err)
+		}
+		users[i] = doc.user()
+	}
+	return users, resp.Total, nil
+}
+
+// UserQuery returns the search request of the users matching filter, selected by opts
+// Users are sorted by opts.Sort, newest first by default, with the ID breaking ties so pages are
+// stable.
+func UserQuery(filter domain.UserFilter, opts domain.ListOptions) resources.SearchRequest {
+	var query resources.Bool
+	if text := strings.TrimSpace(filter.Query); text != "" {
+		query.Must = append(query.Must, resources.Match("name", text))
+	}
+	if filter.Name != "" {
+		query.Filter = append(query.Filter, resources.Contains("name.keyword", filter.Name))
+	}
+	if filter.Email != "" {
+		query.Filter = append(query.Filter, resources.Term("email", string(filter.Email)))
+	}
+	if !filter.CreatedAfter.IsZero() || !filter.CreatedBefore.IsZero() {
+		query.Filter = append(query.Filter, resources.DateRange("created_at", filter.CreatedAfter, filter.CreatedBefore))
+	}
+	if filter.Role != "" {
+		query.Filter = append(query.Filter, resources.Term("roles", filter.Role))
+	}
+	if filter.Locked != nil {
+		query.Filter = append(query.Filter, resources.Term("locked", *filter.Locked))
+	}
+
+	size := opts.Limit
+	if size <= 0 {
+		size = maxResultWindow
+	}
+	sort := resources.Sort{Field: "created_at", Descending: true}
+	if field, ok := userSortFields[opts.Sort]; ok {
+		sort = resources.Sort{Field: field, Descending: opts.Descending}
+	}
+	return resources.SearchRequest{
+		Query: query,
+		Sort:  []resources.Sort{sort, {Field: "id"}},
+		From:  opts.Offset,
+		Size:  size,
+	}
+}
@@ -0,0 +1,225 @@
+// Package seed loads fixture documents into MongoDB collections with upsert semantics
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultKeys are the natural keys documents are matched on, by collection
+// Collections without one are matched on _id.
+var DefaultKeys = map[string]string{
+	"users":   "email",
+	"quizzes": "code",
+}
+
+// ErrNoKey is returned for a document missing the field it is matched on
+var ErrNoKey = errors.New("fixture document has no value for its key")
+
+// Result reports what loading a collection's fixtures changed
+type Result struct {
+	Collection string
+	Inserted   int64
+	Updated    int64
+	Unchanged  int64
+}
+
+// Loader upserts fixture files into a database
+type Loader struct {
+	db   *mongo.Database
+	keys map[string]string
+	now  func() time.Time
+}
+
+// NewLoader creates a Loader for db; keys override DefaultKeys per collection
+func NewLoader(db *mongo.Database, keys map[string]string, now func() time.Time) *Loader {
+	merged := make(map[string]string, len(DefaultKeys)+len(keys))
+	for collection, key := range DefaultKeys {
+		merged[collection] = key
+	}
+	for collection, key := range keys {
+		merged[collection] = key
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+	return &Loader{db: db, keys: merged, now: now}
+}
+
+// Files returns the fixture files of dir by collection (the file name without extension)
+// Only .json, .yaml and .yml files are considered; when only is not empty, other collections are skipped.
+func Files(dir string, only []string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures directory: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(only))
+	for _, collection := range only {
+		wanted[collection] = true
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		collection := strings.TrimSuffix(entry.Name(), ext)
+		if len(wanted) > 0 && !wanted[collection] {
+			continue
+		}
+		if existing, ok := files[collection]; ok {
+			return nil, fmt.Errorf("collection %s has several fixture files: %s and %s", collection, existing, entry.Name())
+		}
+		files[collection] = filepath.Join(dir, entry.Name())
+	}
+	return files, nil
+}
+
+// Collections returns the collections of files in a stable order
+func Collections(files map[string]string) []string {
+	collections := make([]string, 0, len(files))
+	for collection := range files {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+// LoadFile upserts the documents of a fixture file into collection
+func (l *Loader) LoadFile(ctx context.Context, collection, path string) (*Result, error) {
+	docs, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.Load(ctx, collection, docs)
+}
+
+// Load upserts docs into collection, matching existing documents on the collection's key
+// createdAt and updatedAt default to the current time; createdAt is only written on insert.
+func (l *Loader) Load(ctx context.Context, collection string, docs []bson.M) (*Result, error) {
+	key := l.keys[collection]
+	if key == "" {
+		key = "_id"
+	}
+
+	result := &Result{Collection: collection}
+	if len(docs) == 0 {
+		return result, nil
+	}
+
+	now := l.now()
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for i, doc := range docs {
+		value, ok := doc[key]
+		if !ok || value == nil || value == "" {
+			return nil, fmt.Errorf("%s document %d: %w (%s)", collection, i, ErrNoKey, key)
+		}
+
+		createdAt, ok := doc["createdAt"]
+		if !ok {
+			createdAt = now
+		}
+		delete(doc, "createdAt")
+		if _, ok := doc["updatedAt"]; !ok {
+			doc["updatedAt"] = now
+		}
+
+		update := bson.M{"$set": doc, "$setOnInsert": bson.M{"createdAt": createdAt}}
+		if key != "_id" {
+			// The _id of an existing document is immutable, so it is only set when inserting
+			if id, ok := doc["_id"]; ok {
+				delete(doc, "_id")
+				update["$setOnInsert"].(bson.M)["_id"] = id
+			}
+		} else {
+			delete(doc, "_id")
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{key: value}).
+			SetUpdate(update).
+			SetUpsert(true))
+	}
+
+	res, err := l.db.Collection(collection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert %s fixtures: %w", collection, err)
+	}
+
+	result.Inserted = res.UpsertedCount
+	result.Updated = res.ModifiedCount
+	result.Unchanged = int64(len(docs)) - res.UpsertedCount - res.ModifiedCount
+	return result, nil
+}
+
+// ReadFile reads the documents of a fixture file
+// JSON fixtures are MongoDB Extended JSON arrays ({"$oid": ...}, {"$date": ...}); YAML fixtures are
+// sequences of mappings whose timestamps become dates. In both, 24 character hex _id values become
+// ObjectIDs and RFC 3339 strings become dates.
+func ReadFile(path string) ([]bson.M, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	var docs []bson.M
+	switch filepath.Ext(path) {
+	case ".json":
+		// Extended JSON only parses documents at the top level, so the array is wrapped in one
+		var wrapper struct {
+			Docs []bson.M `bson:"docs"`
+		}
+		wrapped := append(append([]byte(`{"docs":`), data...), '}')
+		if err := bson.UnmarshalExtJSON(wrapped, false, &wrapper); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		docs = wrapper.Docs
+	default:
+		var raw []map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for _, item := range raw {
+			docs = append(docs, bson.M(item))
+		}
+	}
+
+	for _, doc := range docs {
+		normalize(doc)
+	}
+	return docs, nil
+}
+
+// normalize converts ObjectID-looking _id values and RFC 3339 strings in place
+func normalize(doc bson.M) {
+	for field, value := range doc {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if field == "_id" {
+			if id, err := primitive.ObjectIDFromHex(s); err == nil {
+				doc[field] = id
+			}
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			doc[field] = t
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/auth/impersonation"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// Admin errors
+var (
+	// ErrOwnAccount is returned when an administrator targets their own account with an operation
+	// meant for others, e.g. locking themselves out
+	ErrOwnAccount = errors.New("operation not allowed on your own account")
+
+	// ErrCannotImpersonate is returned when impersonating a locked user or a user holding roles,
+	// which would escalate the impersonator's privileges
+	ErrCannotImpersonate = errors.New("user cannot be impersonated")
+)
+
+// Audit actions recorded for admin operations
+const (
+	AuditPasswordResetForced  = "admin.password_reset_forced"
+	AuditUserLocked           = "admin.user_locked"
+	AuditUserUnlocked         = "admin.user_unlocked"
+	AuditImpersonationStarted = "admin.impersonation_started"
+)
+
+// AdminService implements the user management operations of administrators
+// Every operation changing a user is recorded in the audit log as done by the administrator.
+type AdminService interface {
+	// ListUsers returns the page of users matching filter selected by opts, and the number of matches
+	ListUsers(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error)
+
+	// ForcePasswordReset makes a user reset their password at their next sign-in, revoking their sessions
+	ForcePasswordReset(ctx context.Context, actorID, userID string) error
+
+	// Lock locks a user out, revoking their sessions
+	Lock(ctx context.Context, actorID, userID, reason string) error
+
+	// Unlock lifts the lock of a user
+	Unlock(ctx context.Context, actorID, userID string) error
+
+	// Impersonate returns a short-lived token letting an administrator act as a user, with its claims;
+	// it returns impersonation.ErrDisabled when impersonation is not configured
+	Impersonate(ctx context.Context, actorID, userID string) (string, impersonation.Claims, error)
+
+	// Stats returns the user counts, the signups per day of the configured period and the newest users
+	Stats(ctx context.Context) (*domain.UserStats, error)
+}
+
+// adminService implements the AdminService interface
+type adminService struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	tokens   *impersonation.Tokens
+	audit    repository.AuditRepository
+	clock    clock.Clock
+	cfg      config.AdminConfig
+}
+
+// NewAdminService creates a new AdminService
+func NewAdminService(users repository.UserRepository, sessions repository.SessionRepository, tokens *impersonation.Tokens,
+	audit repository.AuditRepository, clk clock.Clock, cfg *config.Config) AdminService {
+	return &adminService{
+		users:    users,
+		sessions: sessions,
+		tokens:   tokens,
+		audit:    audit,
+		clock:    clk,
+		cfg:      cfg.Admin,
+	}
+}
+
+// ListUsers returns the page of users matching filter
+func (s *adminService) ListUsers(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	return s.users.Search(ctx, filter, opts)
+}
+
+// ForcePasswordReset flags a user for a password reset and revokes their sessions, so the reset
+// happens at once rather than when their refresh token expires
+func (s *adminService) ForcePasswordReset(ctx context.Context, actorID, userID string) error {
+	if err := s.users.SetPasswordResetRequired(ctx, userID, true); err != nil {
+		return s.userError(err)
+	}
+	revoked, err := s.revokeSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Password reset forced", zap.String("userId", userID), zap.String("actorId", actorID))
+	return s.record(ctx, AuditPasswordResetForced, actorID, userID, map[string]interface{}{"sessionsRevoked": revoked})
+}
+
+// Lock locks a user out and revokes their sessions
+func (s *adminService) Lock(ctx context.Context, actorID, userID, reason string) error {
+	if actorID == userID {
+		return ErrOwnAccount
+	}
+	if err := s.users.SetLocked(ctx, userID, true); err != nil {
+		return s.userError(err)
+	}
+	revoked, err := s.revokeSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("User locked", zap.String("userId", userID), zap.String("actorId", actorID))
+	return s.record(ctx, AuditUserLocked, actorID, userID, map[string]interface{}{"reason": reason, "sessionsRevoked": revoked})
+}
+
+// Unlock lifts the lock of a user
+func (s *adminService) Unlock(ctx context.Context, actorID, userID string) error {
+	if err := s.users.SetLocked(ctx, userID, false); err != nil {
+		return s.userError(err)
+	}
+
+	logger.Info("User unlocked", zap.String("userId", userID), zap.String("actorId", actorID))
+	return s.record(ctx, AuditUserUnlocked, actorID, userID, nil)
+}
+
+// Impersonate issues an impersonation token, recording it in the audit log
+// The token is not a session: it cannot be refreshed, and the requests made with it are audited.
+func (s *adminService) Impersonate(ctx context.Context, actorID, userID string) (string, impersonation.Claims, error) {
+	if actorID == userID {
+		return "", impersonation.Claims{}, ErrOwnAccount
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return "", impersonation.Claims{}, err
+	}
+	if user == nil {
+		return "", impersonation.Claims{}, ErrUserNotFound
+	}
+	if user.Locked || len(user.Roles) > 0 {
+		return "", impersonation.Claims{}, ErrCannotImpersonate
+	}
+
+	token, claims, err := s.tokens.Issue(actorID, userID)
+	if err != nil {
+		return "", impersonation.Claims{}, err
+	}
+
+	logger.Warn("Impersonation started", zap.String("userId", userID), zap.String("actorId", actorID),
+		zap.String("tokenId", claims.ID))
+	details := map[string]interface{}{"tokenId": claims.ID, "expiresAt": claims.Expires()}
+	if err := s.record(ctx, AuditImpersonationStarted, actorID, userID, details); err != nil {
+		return "", impersonation.Claims{}, err
+	}
+	return token, claims, nil
+}
+
+// Stats returns the user stats of the configured period, which ends today and starts at midnight UTC
+func (s *adminService) Stats(ctx context.Context) (*domain.UserStats, error) {
+	today := s.clock.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-max(s.cfg.StatsDays, 1))
+	return s.users.Stats(ctx, since, s.cfg.StatsRecentSignups)
+}
+
+// revokeSessions revokes every active session of a user on behalf of an administrator
+func (s *adminService) revokeSessions(ctx context.Context, userID string) (int64, error) {
+	revoked, err := s.sessions.RevokeAll(ctx, userID, RevokedByAdmin)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to revoke sessions", zap.String("userId", userID), zap.Error(err))
+	}
+	return revoked, err
+}
+
+// userError converts the repository error of a user write into a service error
+func (s *adminService) userError(err error) error {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// record appends an audit record of an action of an administrator on a user
+func (s *adminService) record(ctx context.Context, action, actorID, userID string, details map[string]interface{}) error {
+	err := s.audit.Record(ctx, &repository.AuditRecord{Action: action, Subject: userID, UserID: actorID, Details: details})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to record admin audit event", zap.String("action", action), zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/auth/impersonation"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+type adminTestEnv struct {
+	service  AdminService
+	users    repository.UserRepository
+	sessions SessionService
+	tokens   *impersonation.Tokens
+	audit    *repository.MockAuditRepository
+}
+
+func newAdminTestEnv(t *testing.T) *adminTestEnv {
+	clk := testutil.NewFakeClock(testTime)
+	cfg := &config.Config{
+		Sessions: config.SessionConfig{RefreshTokenTTL: 24 * time.Hour},
+		Admin: config.AdminConfig{
+			StatsDays:               7,
+			StatsRecentSignups:      2,
+			ImpersonationSigningKey: "test-key",
+			ImpersonationTTL:        15 * time.Minute,
+		},
+	}
+	sessionRepo := repository.NewMockSessionRepository(clk)
+	env := &adminTestEnv{
+		users: repository.NewMockUserRepository(),
+		audit: repository.NewMockAuditRepository(clk),
+	}
+	env.sessions = NewSessionService(sessionRepo, env.audit, clk, cfg)
+	env.tokens = impersonation.NewTokens(cfg, clk)
+	env.service = NewAdminService(env.users, sessionRepo, env.tokens, env.audit, clk, cfg)
+
+	ctx := context.Background()
+	for _, user := range []*domain.User{
+		{ID: "admin-1", Name: "Ada", Email: "ada@example.com", Roles: []string{"admin"}, CreatedAt: testTime.AddDate(0, 0, -30)},
+		{ID: "user-1", Name: "Uma", Email: "uma@example.com", CreatedAt: testTime.AddDate(0, 0, -2)},
+		{ID: "user-2", Name: "Ugo", Email: "ugo@example.com", CreatedAt: testTime.AddDate(0, 0, -2).Add(time.Hour)},
+		{ID: "user-3", Name: "Una", Email: "una@example.com", CreatedAt: testTime},
+	} {
+		require.NoError(t, env.users.Create(ctx, user))
+	}
+	return env
+}
+
+func TestAdminService_Lock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Locks the user and revokes their sessions", func(t *testing.T) {
+		env := newAdminTestEnv(t)
+		_, _, err := env.sessions.Issue(ctx, "user-1", "Pixel 8", "")
+		require.NoError(t, err)
+
+		require.NoError(t, env.service.Lock(ctx, "admin-1", "user-1", "abuse"))
+
+		user, _ := env.users.GetByID(ctx, "user-1")
+		assert.True(t, user.Locked)
+		sessions, _ := env.sessions.List(ctx, "user-1")
+		assert.Empty(t, sessions)
+
+		records, _ := env.audit.ListBySubject(ctx, "user-1")
+		require.Len(t, records, 1)
+		assert.Equal(t, AuditUserLocked, records[0].Action)
+		assert.Equal(t, "admin-1", records[0].UserID)
+		assert.Equal(t, "abuse", records[0].Details["reason"])
+
+		require.NoError(t, env.service.Unlock(ctx, "admin-1", "user-1"))
+		user, _ = env.users.GetByID(ctx, "user-1")
+		assert.False(t, user.Locked)
+	})
+
+	t.Run("Rejected targets", func(t *testing.T) {
+		env := newAdminTestEnv(t)
+		assert.ErrorIs(t, env.service.Lock(ctx, "admin-1", "admin-1", ""), ErrOwnAccount)
+		assert.ErrorIs(t, env.service.Lock(ctx, "admin-1", "missing", ""), ErrUserNotFound)
+	})
+}
+
+func TestAdminService_ForcePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	env := newAdminTestEnv(t)
+	_, _, err := env.sessions.Issue(ctx, "user-1", "", "")
+	require.NoError(t, err)
+
+	require.NoError(t, env.service.ForcePasswordReset(ctx, "admin-1", "user-1"))
+
+	user, _ := env.users.GetByID(ctx, "user-1")
+	assert.True(t, user.PasswordResetRequired)
+	sessions, _ := env.sessions.List(ctx, "user-1")
+	assert.Empty(t, sessions)
+
+	records, _ := env.audit.ListBySubject(ctx, "user-1")
+	require.Len(t, records, 1)
+	assert.Equal(t, AuditPasswordResetForced, records[0].Action)
+}
+
+func TestAdminService_Impersonate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Issues an audited token", func(t *testing.T) {
+		env := newAdminTestEnv(t)
+		token, claims, err := env.service.Impersonate(ctx, "admin-1", "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, testTime.Add(15*time.Minute).Unix(), claims.ExpiresAt)
+
+		verified, err := env.tokens.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "admin-1", verified.Actor)
+		assert.Equal(t, "user-1", verified.Subject)
+
+		records, _ := env.audit.ListBySubject(ctx, "user-1")
+		require.Len(t, records, 1)
+		assert.Equal(t, AuditImpersonationStarted, records[0].Action)
+		assert.Equal(t, "admin-1", records[0].UserID)
+		assert.Equal(t, claims.ID, records[0].Details["tokenId"])
+	})
+
+	t.Run("Rejected targets", func(t *testing.T) {
+		env := newAdminTestEnv(t)
+		require.NoError(t, env.service.Lock(ctx, "admin-1", "user-2", ""))
+
+		for userID, want := range map[string]error{
+			"admin-1": ErrOwnAccount,
+			"missing": ErrUserNotFound,
+			"user-2":  ErrCannotImpersonate,
+		} {
+			_, _, err := env.service.Impersonate(ctx, "admin-1", userID)
+			assert.ErrorIs(t, err, want, userID)
+		}
+		_, _, err := env.service.Impersonate(ctx, "user-1", "admin-1")
+		assert.ErrorIs(t, err, ErrCannotImpersonate, "users holding roles")
+	})
+}
+
+func TestAdminService_Stats(t *testing.T) {
+	env := newAdminTestEnv(t)
+	require.NoError(t, env.service.Lock(context.Background(), "admin-1", "user-1", ""))
+
+	stats, err := env.service.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stats.Total)
+	assert.Equal(t, int64(1), stats.Locked)
+
+	day := func(offset int) string { return testTime.AddDate(0, 0, offset).Format(time.DateOnly) }
+	assert.Equal(t, []domain.DailyCount{{Date: day(-2), Count: 2}, {Date: day(0), Count: 1}}, stats.SignupsByDay)
+
+	require.Len(t, stats.RecentSignups, 2)
+	assert.Equal(t, "user-3", stats.RecentSignups[0].ID)
+	assert.Equal(t, "user-2", stats.RecentSignups[1].ID)
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/imaging"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// AvatarJobType is the job type turning an uploaded image into the variants of an avatar
+const AvatarJobType = "avatar.process"
+
+// Avatar errors
+var (
+	// ErrInvalidImage is wrapped by the errors of uploads that are not images of a supported format
+	// and size
+	ErrInvalidImage = errors.New("invalid image")
+
+	ErrAvatarNotFound = errors.New("avatar not found")
+)
+
+// AvatarService manages the profile images of users
+// Uploads are stored as they are and processed by a background job, which replaces the avatar of the
+// user with WebP variants of the configured sizes once they are all stored.
+type AvatarService interface {
+	// Upload stores an image uploaded by a user and enqueues its processing, returning the job
+	// Unknown users return ErrUserNotFound; files that are not images of a supported format and
+	// size return an error wrapping ErrInvalidImage.
+	Upload(ctx context.Context, userID string, upload io.Reader) (*repository.Job, error)
+
+	// Open returns a reader over the variant of the given size of a user's avatar, or ErrAvatarNotFound
+	Open(ctx context.Context, userID, size string) (io.ReadCloser, *repository.FileInfo, error)
+
+	// Delete removes the avatar of a user, or returns ErrAvatarNotFound
+	Delete(ctx context.Context, userID string) error
+}
+
+// avatarPayload is the payload of an avatar processing job
+type avatarPayload struct {
+	UserID string `json:"user_id"`
+	FileID string `json:"file_id"`
+}
+
+// avatarService implements the AvatarService interface
+type avatarService struct {
+	userRepo repository.UserRepository
+	files    repository.FileRepository
+	queue    *jobs.Queue
+	clock    clock.Clock
+	cfg      config.AvatarConfig
+}
+
+// NewAvatarService creates a new AvatarService and registers the processing job handler on queue
+func NewAvatarService(
+	userRepo repository.UserRepository,
+	files repository.FileRepository,
+	queue *jobs.Queue,
+	clk clock.Clock,
+	cfg *config.Config,
+) AvatarService {
+	s := &avatarService{
+		userRepo: userRepo,
+		files:    files,
+		queue:    queue,
+		clock:    clk,
+		cfg:      cfg.Avatars,
+	}
+	queue.Register(AvatarJobType, s.process)
+	return s
+}
+
+// Upload stores an uploaded image and enqueues its processing
+// Only the header of the image is checked here; decoding it is left to the job.
+func (s *avatarService) Upload(ctx context.Context, userID string, upload io.Reader) (*repository.Job, error) {
+	if _, err := s.user(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(upload, s.cfg.MaxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.cfg.MaxUploadBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidImage, s.cfg.MaxUploadBytes)
+	}
+	format, err := imaging.Inspect(data, s.cfg.MaxPixels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+
+	fileID, err := s.files.Upload(ctx, "avatars/"+userID+"/original", bytes.NewReader(data), map[string]interface{}{
+		"userId": userID,
+		"format": format,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.queue.Enqueue(ctx, AvatarJobType, avatarPayload{UserID: userID, FileID: fileID})
+	if err != nil {
+		logger.Error("Failed to enqueue avatar processing", zap.String("userId", userID), zap.Error(err))
+		s.deleteFiles(ctx, fileID)
+		return nil, err
+	}
+
+	logger.Info("Avatar uploaded", zap.String("userId", userID), zap.String("jobId", job.ID), zap.String("format", format))
+	return job, nil
+}
+
+// Open returns a reader over a variant of a user's avatar
+func (s *avatarService) Open(ctx context.Context, userID, size string) (io.ReadCloser, *repository.FileInfo, error) {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Avatar == nil || user.Avatar.Variants[size] == "" {
+		return nil, nil, ErrAvatarNotFound
+	}
+
+	stream, info, err := s.files.OpenDownloadStream(ctx, user.Avatar.Variants[size])
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrAvatarNotFound
+	}
+	return stream, info, err
+}
+
+// Delete removes the avatar of a user and its stored variants
+func (s *avatarService) Delete(ctx context.Context, userID string) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Avatar == nil {
+		return ErrAvatarNotFound
+	}
+
+	if err := s.userRepo.SetAvatar(ctx, userID, nil); err != nil {
+		return err
+	}
+	s.deleteFiles(ctx, variantFiles(user.Avatar)...)
+
+	logger.Info("Avatar deleted", zap.String("userId", userID))
+	return nil
+}
+
+// process runs an avatar processing job, replacing the user's avatar with variants of the upload
+// A retry after the avatar was set only cleans up; uploads that cannot be decoded are dead-lettered.
+func (s *avatarService) process(ctx context.Context, job *repository.Job) error {
+	var payload avatarPayload
+	if err := jobs.Decode(job, &payload); err != nil {
+		return err
+	}
+	if payload.UserID == "" || payload.FileID == "" {
+		return jobs.Permanent(fmt.Errorf("avatar job %s has no user or file ID", job.ID))
+	}
+
+	user, err := s.user(ctx, payload.UserID)
+	if err != nil {
+		if err == ErrUserNotFound {
+			s.deleteFiles(ctx, payload.FileID)
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	if user.Avatar != nil && user.Avatar.Version == payload.FileID {
+		s.deleteFiles(ctx, payload.FileID)
+		return nil
+	}
+
+	original, _, err := s.files.OpenDownloadStream(ctx, payload.FileID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return jobs.Permanent(fmt.Errorf("avatar upload %s is gone", payload.FileID))
+		}
+		return err
+	}
+	img, _, err := imaging.Decode(original, s.cfg.MaxPixels)
+	original.Close()
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrTooLarge) {
+			s.deleteFiles(ctx, payload.FileID)
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	avatar := &domain.Avatar{
+		Version:   payload.FileID,
+		Variants:  make(map[string]string, len(s.cfg.Sizes)),
+		UpdatedAt: s.clock.Now(),
+	}
+	for _, size := range s.cfg.Sizes {
+		id, err := s.storeVariant(ctx, payload.UserID, img, size)
+		if err != nil {
+			s.deleteFiles(ctx, variantFiles(avatar)...)
+			return err
+		}
+		avatar.Variants[strconv.Itoa(size)] = id
+	}
+
+	if err := s.userRepo.SetAvatar(ctx, payload.UserID, avatar); err != nil {
+		s.deleteFiles(ctx, variantFiles(avatar)...)
+		if err == repository.ErrUserNotFound {
+			s.deleteFiles(ctx, payload.FileID)
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	// The previous variants and the upload are no longer referenced
+	s.deleteFiles(ctx, append(variantFiles(user.Avatar), payload.FileID)...)
+
+	logger.Info("Avatar processed", zap.String("userId", payload.UserID), zap.String("jobId", job.ID), zap.Int("variants", len(avatar.Variants)))
+	return nil
+}
+
+// storeVariant stores the square WebP variant of img of size, returning its file ID
+func (s *avatarService) storeVariant(ctx context.Context, userID string, img image.Image, size int) (string, error) {
+	data, err := imaging.EncodeWebP(imaging.Thumbnail(img, size))
+	if err != nil {
+		return "", err
+	}
+	return s.files.Upload(ctx, fmt.Sprintf("avatars/%s/%d.webp", userID, size), bytes.NewReader(data), map[string]interface{}{
+		"userId":      userID,
+		"size":        size,
+		"contentType": imaging.ContentType,
+	})
+}
+
+// user returns a user, or ErrUserNotFound
+func (s *avatarService) user(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// deleteFiles deletes stored files, logging failures: an unreferenced file is only wasted space
+func (s *avatarService) deleteFiles(ctx context.Context, ids ...string) {
+	for _, id := range ids {
+		if err := s.files.Delete(ctx, id); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			logger.Warn("Failed to delete avatar file", zap.String("fileId", id), zap.Error(err))
+		}
+	}
+}
+
+// variantFiles returns the file IDs of the variants of avatar, which may be nil
+func variantFiles(avatar *domain.Avatar) []string {
+	if avatar == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(avatar.Variants))
+	for _, id := range avatar.Variants {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+type avatarTestEnv struct {
+	service AvatarService
+	users   repository.UserRepository
+	files   *repository.MockFileRepository
+	jobs    *jobs.Queue
+	user    *domain.User
+}
+
+func newAvatarTestEnv(t *testing.T) *avatarTestEnv {
+	clk := testutil.NewFakeClock(testTime)
+	env := &avatarTestEnv{
+		users: repository.NewMockUserRepository(),
+		files: repository.NewMockFileRepository(clk),
+		user:  &domain.User{Name: "Test User", Email: "test@example.com"},
+	}
+	require.NoError(t, env.users.Create(context.Background(), env.user))
+
+	env.jobs = jobs.NewQueue(repository.NewMockJobRepository(clk), clk, config.JobsConfig{MaxAttempts: 3})
+	cfg := &config.Config{Avatars: config.AvatarConfig{Sizes: []int{32, 64}, MaxUploadBytes: 1 << 20, MaxPixels: 1 << 20}}
+	env.service = NewAvatarService(env.users, env.files, env.jobs, clk, cfg)
+	return env
+}
+
+// testPNG returns a w x h PNG image
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestAvatarService_Upload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Processes the upload into variants", func(t *testing.T) {
+		env := newAvatarTestEnv(t)
+
+		job, err := env.service.Upload(ctx, env.user.ID, bytes.NewReader(testPNG(t, 120, 80)))
+		require.NoError(t, err)
+		assert.Equal(t, AvatarJobType, job.Type)
+
+		processed, err := env.jobs.RunOnce(ctx)
+		require.NoError(t, err)
+		require.True(t, processed)
+
+		user, err := env.users.GetByID(ctx, env.user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, user.Avatar)
+		assert.Len(t, user.Avatar.Variants, 2)
+		assert.Equal(t, testTime, user.Avatar.UpdatedAt)
+		assert.Equal(t, 2, env.files.Len(), "the upload is deleted once processed")
+
+		stream, _, err := env.service.Open(ctx, env.user.ID, "64")
+		require.NoError(t, err)
+		defer stream.Close()
+		data, err := io.ReadAll(stream)
+		require.NoError(t, err)
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "webp", format)
+		assert.Equal(t, 64, cfg.Width)
+		assert.Equal(t, 64, cfg.Height)
+	})
+
+	t.Run("Replaces the previous avatar", func(t *testing.T) {
+		env := newAvatarTestEnv(t)
+		for i := 0; i < 2; i++ {
+			_, err := env.service.Upload(ctx, env.user.ID, bytes.NewReader(testPNG(t, 40, 40)))
+			require.NoError(t, err)
+			_, err = env.jobs.RunOnce(ctx)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 2, env.files.Len())
+	})
+
+	t.Run("Rejects files that are not images", func(t *testing.T) {
+		env := newAvatarTestEnv(t)
+
+		_, err := env.service.Upload(ctx, env.user.ID, strings.NewReader("GIF89a but not really"))
+		assert.ErrorIs(t, err, ErrInvalidImage)
+
+		_, err = env.service.Upload(ctx, env.user.ID, bytes.NewReader(testPNG(t, 2048, 1024)))
+		assert.ErrorIs(t, err, ErrInvalidImage, "too many pixels")
+		assert.Equal(t, 0, env.files.Len())
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		env := newAvatarTestEnv(t)
+
+		_, err := env.service.Upload(ctx, "missing", bytes.NewReader(testPNG(t, 8, 8)))
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("User deleted before processing", func(t *testing.T) {
+		env := newAvatarTestEnv(t)
+		_, err := env.service.Upload(ctx, env.user.ID, bytes.NewReader(testPNG(t, 8, 8)))
+		require.NoError(t, err)
+		require.NoError(t, env.users.Delete(ctx, env.user.ID))
+
+		_, err = env.jobs.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, env.files.Len())
+	})
+}
+
+func TestAvatarService_Delete(t *testing.T) {
+	ctx := context.Background()
+	env := newAvatarTestEnv(t)
+
+	assert.ErrorIs(t, env.service.Delete(ctx, env.user.ID), ErrAvatarNotFound)
+
+	_, err := env.service.Upload(ctx, env.user.ID, bytes.NewReader(testPNG(t, 16, 16)))
+	require.NoError(t, err)
+	_, err = env.jobs.RunOnce(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, env.service.Delete(ctx, env.user.ID))
+	assert.Equal(t, 0, env.files.Len())
+	_, _, err = env.service.Open(ctx, env.user.ID, "32")
+	assert.ErrorIs(t, err, ErrAvatarNotFound)
+}
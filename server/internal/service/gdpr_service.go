@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/gdpr"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// ErasureJobType is the job type erasing a user's data
+const ErasureJobType = "gdpr.erase"
+
+// Audit actions recorded for GDPR requests
+const (
+	AuditErasureRequested = "gdpr.erasure_requested"
+	AuditErased           = "gdpr.erased"
+)
+
+// GDPRService handles data subject requests: exporting and erasing a user's personal data
+type GDPRService interface {
+	// Export returns the user's data across all registered collections, or ErrUserNotFound
+	Export(ctx context.Context, userID string) (*gdpr.Export, error)
+
+	// RequestErasure enqueues the erasure of the user's data and returns its job, or ErrUserNotFound
+	RequestErasure(ctx context.Context, userID string) (*repository.Job, error)
+}
+
+// erasurePayload is the payload of an erasure job
+type erasurePayload struct {
+	UserID      string    `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// gdprService implements the GDPRService interface
+type gdprService struct {
+	userRepo repository.UserRepository
+	registry *gdpr.Registry
+	queue    *jobs.Queue
+	audit    repository.AuditRepository
+	clock    clock.Clock
+}
+
+// NewGDPRService creates a new GDPRService and registers the erasure job handler on queue
+func NewGDPRService(
+	userRepo repository.UserRepository,
+	registry *gdpr.Registry,
+	queue *jobs.Queue,
+	audit repository.AuditRepository,
+	clk clock.Clock,
+) GDPRService {
+	s := &gdprService{
+		userRepo: userRepo,
+		registry: registry,
+		queue:    queue,
+		audit:    audit,
+		clock:    clk,
+	}
+	queue.Register(ErasureJobType, s.erase)
+	return s
+}
+
+// Export returns the user's data across all registered collections
+func (s *gdprService) Export(ctx context.Context, userID string) (*gdpr.Export, error) {
+	logger.Debug("Exporting user data", zap.String("userId", userID))
+
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	export := &gdpr.Export{
+		UserID:      userID,
+		ExportedAt:  s.clock.Now(),
+		Collections: make(map[string]interface{}),
+	}
+	for _, c := range s.registry.Collections() {
+		data, err := c.Export(ctx, userID)
+		if err != nil {
+			logger.Error("Failed to export user data", zap.String("userId", userID), zap.String("collection", c.Name()), zap.Error(err))
+			return nil, fmt.Errorf("failed to export %s: %w", c.Name(), err)
+		}
+		if data != nil {
+			export.Collections[c.Name()] = data
+		}
+	}
+
+	logger.Info("User data exported", zap.String("userId", userID), zap.Int("collections", len(export.Collections)))
+	return export, nil
+}
+
+// RequestErasure enqueues the erasure of the user's data, recording the request in the audit log
+func (s *gdprService) RequestErasure(ctx context.Context, userID string) (*repository.Job, error) {
+	logger.Debug("Requesting user data erasure", zap.String("userId", userID))
+
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.queue.Enqueue(ctx, ErasureJobType, erasurePayload{UserID: userID, RequestedAt: s.clock.Now()})
+	if err != nil {
+		logger.Error("Failed to enqueue user data erasure", zap.String("userId", userID), zap.Error(err))
+		return nil, err
+	}
+
+	err = s.audit.Record(ctx, &repository.AuditRecord{
+		Action:  AuditErasureRequested,
+		Subject: userID,
+		Details: map[string]interface{}{"jobId": job.ID},
+	})
+	if err != nil {
+		logger.Error("Failed to record erasure request", zap.String("userId", userID), zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("User data erasure requested", zap.String("userId", userID), zap.String("jobId", job.ID))
+	return job, nil
+}
+
+// erase runs an erasure job, erasing collections in reverse registration order
+// It only records the erasure once every collection succeeded; a failure retries the whole job,
+// re-erasing the collections already done, which erase nothing more.
+func (s *gdprService) erase(ctx context.Context, job *repository.Job) error {
+	var payload erasurePayload
+	if err := jobs.Decode(job, &payload); err != nil {
+		return err
+	}
+	if payload.UserID == "" {
+		return jobs.Permanent(fmt.Errorf("erasure job %s has no user ID", job.ID))
+	}
+
+	collections := s.registry.Collections()
+	erased := make(map[string]interface{}, len(collections))
+	for i := len(collections) - 1; i >= 0; i-- {
+		c := collections[i]
+		n, err := c.Erase(ctx, payload.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to erase %s: %w", c.Name(), err)
+		}
+		erased[c.Name()] = n
+	}
+
+	err := s.audit.Record(ctx, &repository.AuditRecord{
+		Action:  AuditErased,
+		Subject: payload.UserID,
+		Details: map[string]interface{}{
+			"jobId":       job.ID,
+			"requestedAt": payload.RequestedAt,
+			"erased":      erased,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record erasure: %w", err)
+	}
+
+	logger.Info("User data erased", zap.String("userId", payload.UserID), zap.String("jobId", job.ID))
+	return nil
+}
+
+// ensureUser returns ErrUserNotFound unless the user exists
+func (s *gdprService) ensureUser(ctx context.Context, userID string) error {
+	if userID == "" {
+		return ErrInvalidUser
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
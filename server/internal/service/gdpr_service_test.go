@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/gdpr"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+// fakeCollection is a gdpr.Collection recording the erasures it runs
+type fakeCollection struct {
+	name   string
+	data   interface{}
+	err    error
+	erased *[]string
+}
+
+func (c fakeCollection) Name() string { return c.name }
+
+func (c fakeCollection) Export(ctx context.Context, userID string) (interface{}, error) {
+	return c.data, c.err
+}
+
+func (c fakeCollection) Erase(ctx context.Context, userID string) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	*c.erased = append(*c.erased, c.name)
+	return 2, nil
+}
+
+type gdprTestEnv struct {
+	service GDPRService
+	users   repository.UserRepository
+	jobs    *jobs.Queue
+	audit   *repository.MockAuditRepository
+	user    *domain.User
+	erased  []string
+}
+
+func newGDPRTestEnv(t *testing.T, collections ...func(env *gdprTestEnv) gdpr.Collection) *gdprTestEnv {
+	clk := testutil.NewFakeClock(testTime)
+	env := &gdprTestEnv{
+		users: repository.NewMockUserRepository(),
+		audit: repository.NewMockAuditRepository(clk),
+		user:  &domain.User{Name: "Test User", Email: "test@example.com"},
+	}
+	require.NoError(t, env.users.Create(context.Background(), env.user))
+
+	registry := gdpr.NewRegistry(gdpr.Users(env.users))
+	for _, c := range collections {
+		registry.Register(c(env))
+	}
+
+	env.jobs = jobs.NewQueue(repository.NewMockJobRepository(clk), clk, config.JobsConfig{MaxAttempts: 3})
+	env.service = NewGDPRService(env.users, registry, env.jobs, env.audit, clk)
+	return env
+}
+
+func quizzes(env *gdprTestEnv) gdpr.Collection {
+	return fakeCollection{name: "quizzes", data: []string{"quiz-1"}, erased: &env.erased}
+}
+
+func TestGDPRService_Export(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Collects every collection", func(t *testing.T) {
+		env := newGDPRTestEnv(t, quizzes, func(env *gdprTestEnv) gdpr.Collection {
+			return fakeCollection{name: "empty", erased: &env.erased}
+		})
+
+		export, err := env.service.Export(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, env.user.ID, export.UserID)
+		assert.Equal(t, testTime, export.ExportedAt)
+		assert.Equal(t, env.user, export.Collections["users"])
+		assert.Equal(t, []string{"quiz-1"}, export.Collections["quizzes"])
+		assert.NotContains(t, export.Collections, "empty")
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		env := newGDPRTestEnv(t)
+
+		_, err := env.service.Export(ctx, "missing")
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+
+	t.Run("Collection error", func(t *testing.T) {
+		env := newGDPRTestEnv(t, func(env *gdprTestEnv) gdpr.Collection {
+			return fakeCollection{name: "broken", err: errors.New("boom")}
+		})
+
+		_, err := env.service.Export(ctx, env.user.ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestGDPRService_RequestErasure(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Erases asynchronously with an audit trail", func(t *testing.T) {
+		env := newGDPRTestEnv(t, quizzes)
+
+		job, err := env.service.RequestErasure(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ErasureJobType, job.Type)
+		assert.Equal(t, repository.JobPending, job.Status)
+
+		// Nothing is erased until the job runs
+		user, err := env.users.GetByID(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, user)
+
+		processed, err := env.jobs.RunOnce(ctx)
+		require.NoError(t, err)
+		require.True(t, processed)
+
+		user, err = env.users.GetByID(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, user)
+		assert.Equal(t, []string{"quizzes"}, env.erased, "data referring to the user is erased first")
+
+		records, err := env.audit.ListBySubject(ctx, env.user.ID)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, AuditErasureRequested, records[0].Action)
+		assert.Equal(t, job.ID, records[0].Details["jobId"])
+		assert.Equal(t, AuditErased, records[1].Action)
+		assert.Equal(t, map[string]interface{}{"users": int64(1), "quizzes": int64(2)}, records[1].Details["erased"])
+	})
+
+	t.Run("Failures are retried", func(t *testing.T) {
+		env := newGDPRTestEnv(t, func(env *gdprTestEnv) gdpr.Collection {
+			return fakeCollection{name: "broken", err: errors.New("boom")}
+		})
+
+		job, err := env.service.RequestErasure(ctx, env.user.ID)
+		require.NoError(t, err)
+
+		_, err = env.jobs.RunOnce(ctx)
+		require.NoError(t, err)
+
+		stored, err := env.jobs.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobPending, stored.Status)
+		assert.Contains(t, stored.LastError, "broken")
+
+		records, err := env.audit.ListBySubject(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.Len(t, records, 1, "the erasure is only recorded once complete")
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		env := newGDPRTestEnv(t)
+
+		_, err := env.service.RequestErasure(ctx, "missing")
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+)
+
+// PushJobType is the job type sending a push notification to the devices of a user or a topic
+const PushJobType = "push.send"
+
+// Notification errors
+var (
+	ErrDeviceNotFound       = errors.New("device not found")
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationService manages the devices users register for push notifications and sends them
+// Notifications are sent by a background job, only to users who have push notifications on in
+// their preferences; devices whose token the provider rejects as invalid are removed.
+type NotificationService interface {
+	// RegisterDevice registers a device of a user, or updates the registration of its token,
+	// reporting whether the device was new to the user
+	// Invalid registrations return an error wrapping domain.ErrInvalidDevice.
+	RegisterDevice(ctx context.Context, userID string, device *domain.Device) (bool, error)
+
+	// ListDevices returns the devices of a user, most recently registered first
+	ListDevices(ctx context.Context, userID string) ([]*domain.Device, error)
+
+	// UnregisterDevice removes a device of a user, or returns ErrDeviceNotFound
+	UnregisterDevice(ctx context.Context, userID, deviceID string) error
+
+	// Subscribe subscribes a device of a user to topic
+	// Invalid topics and subscriptions past domain.MaxDeviceTopics return an error wrapping
+	// domain.ErrInvalidDevice.
+	Subscribe(ctx context.Context, userID, deviceID, topic string) error
+
+	// Unsubscribe unsubscribes a device of a user from topic
+	Unsubscribe(ctx context.Context, userID, deviceID, topic string) error
+
+	// NotifyUser enqueues sending a notification to the devices of a user, returning the job; its
+	// ID identifies the notification
+	NotifyUser(ctx context.Context, userID string, notification *domain.Notification) (*repository.Job, error)
+
+	// NotifyTopic enqueues sending a notification to the devices subscribed to topic, returning the job
+	NotifyTopic(ctx context.Context, topic string, notification *domain.Notification) (*repository.Job, error)
+
+	// Status returns the job sending a notification and the receipts of its deliveries counted by
+	// status, or ErrNotificationNotFound
+	Status(ctx context.Context, notificationID string) (*repository.Job, map[string]int64, error)
+}
+
+// pushPayload is the payload of a push job; it has either a user ID or a topic
+type pushPayload struct {
+	UserID       string              `json:"user_id,omitempty"`
+	Topic        string              `json:"topic,omitempty"`
+	Notification domain.Notification `json:"notification"`
+}
+
+// notificationService implements the NotificationService interface
+type notificationService struct {
+	devices     repository.DeviceRepository
+	receipts    repository.PushReceiptRepository
+	userRepo    repository.UserRepository
+	preferences repository.PreferencesRepository
+	push        resources.PushResource
+	queue       *jobs.Queue
+	batchSize   int
+}
+
+// NewNotificationService creates a new NotificationService and registers the push job handler on queue
+func NewNotificationService(
+	devices repository.DeviceRepository,
+	receipts repository.PushReceiptRepository,
+	userRepo repository.UserRepository,
+	preferences repository.PreferencesRepository,
+	push resources.PushResource,
+	queue *jobs.Queue,
+	cfg *config.Config,
+) NotificationService {
+	s := &notificationService{
+		devices:     devices,
+		receipts:    receipts,
+		userRepo:    userRepo,
+		preferences: preferences,
+		push:        push,
+		queue:       queue,
+		batchSize:   max(cfg.Push.BatchSize, 1),
+	}
+	queue.Register(PushJobType, s.send)
+	return s
+}
+
+// RegisterDevice registers a device of a user
+func (s *notificationService) RegisterDevice(ctx context.Context, userID string, device *domain.Device) (bool, error) {
+	if err := device.Validate(); err != nil {
+		return false, err
+	}
+	if err := s.checkUser(ctx, userID); err != nil {
+		return false, err
+	}
+
+	device.UserID = userID
+	created, err := s.devices.Register(ctx, device)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		logger.InfoCtx(ctx, "Device registered", zap.String("userId", userID), zap.String("deviceId", device.ID), zap.String("platform", device.Platform))
+	}
+	return created, nil
+}
+
+// ListDevices returns the devices of a user
+func (s *notificationService) ListDevices(ctx context.Context, userID string) ([]*domain.Device, error) {
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.devices.ListByUser(ctx, userID)
+}
+
+// UnregisterDevice removes a device of a user
+func (s *notificationService) UnregisterDevice(ctx context.Context, userID, deviceID string) error {
+	err := s.devices.Delete(ctx, userID, deviceID)
+	if err == repository.ErrNotFound {
+		return ErrDeviceNotFound
+	}
+	return err
+}
+
+// Subscribe subscribes a device of a user to topic
+func (s *notificationService) Subscribe(ctx context.Context, userID, deviceID, topic string) error {
+	if err := domain.ValidateTopic(topic); err != nil {
+		return err
+	}
+
+	device, err := s.devices.Get(ctx, userID, deviceID)
+	if err == repository.ErrNotFound {
+		return ErrDeviceNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if slices.Contains(device.Topics, topic) {
+		return nil
+	}
+	if len(device.Topics) >= domain.MaxDeviceTopics {
+		return fmt.Errorf("%w: a device subscribes to at most %d topics", domain.ErrInvalidDevice, domain.MaxDeviceTopics)
+	}
+
+	err = s.devices.Subscribe(ctx, userID, deviceID, topic)
+	if err == repository.ErrNotFound {
+		return ErrDeviceNotFound
+	}
+	return err
+}
+
+// Unsubscribe unsubscribes a device of a user from topic
+func (s *notificationService) Unsubscribe(ctx context.Context, userID, deviceID, topic string) error {
+	err := s.devices.Unsubscribe(ctx, userID, deviceID, topic)
+	if err == repository.ErrNotFound {
+		return ErrDeviceNotFound
+	}
+	return err
+}
+
+// NotifyUser enqueues sending a notification to the devices of a user
+func (s *notificationService) NotifyUser(ctx context.Context, userID string, notification *domain.Notification) (*repository.Job, error) {
+	if err := notification.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.queue.Enqueue(ctx, PushJobType, pushPayload{UserID: userID, Notification: *notification})
+}
+
+// NotifyTopic enqueues sending a notification to the devices subscribed to topic
+func (s *notificationService) NotifyTopic(ctx context.Context, topic string, notification *domain.Notification) (*repository.Job, error) {
+	if err := domain.ValidateTopic(topic); err != nil {
+		return nil, err
+	}
+	if err := notification.Validate(); err != nil {
+		return nil, err
+	}
+	return s.queue.Enqueue(ctx, PushJobType, pushPayload{Topic: topic, Notification: *notification})
+}
+
+// Status returns the job of a notification and its receipts
+func (s *notificationService) Status(ctx context.Context, notificationID string) (*repository.Job, map[string]int64, error) {
+	job, err := s.queue.Get(ctx, notificationID)
+	if err == repository.ErrNotFound || (err == nil && job.Type != PushJobType) {
+		return nil, nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	receipts, err := s.receipts.CountByStatus(ctx, notificationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, receipts, nil
+}
+
+// send runs a push job, delivering the notification in batches
+// Delivery is at least once: a job retried after some batches were sent sends them again.
+func (s *notificationService) send(ctx context.Context, job *repository.Job) error {
+	var payload pushPayload
+	if err := jobs.Decode(job, &payload); err != nil {
+		return err
+	}
+	if (payload.UserID == "") == (payload.Topic == "") {
+		return jobs.Permanent(fmt.Errorf("push job %s must have either a user or a topic", job.ID))
+	}
+
+	var delivered int
+	deliver := func(devices []*domain.Device) {
+		delivered += s.deliver(ctx, job.ID, payload, devices)
+	}
+
+	if payload.UserID != "" {
+		enabled, err := s.pushEnabled(ctx, payload.UserID)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			devices, err := s.devices.ListByUser(ctx, payload.UserID)
+			if err != nil {
+				return err
+			}
+			deliver(devices)
+		}
+	} else {
+		// Preferences are looked up once per user, as users subscribe from several devices
+		enabled := make(map[string]bool)
+		var batch []*domain.Device
+		err := s.devices.EachSubscriber(ctx, payload.Topic, func(device *domain.Device) error {
+			on, ok := enabled[device.UserID]
+			if !ok {
+				var err error
+				if on, err = s.pushEnabled(ctx, device.UserID); err != nil {
+					return err
+				}
+				enabled[device.UserID] = on
+			}
+			if on {
+				batch = append(batch, device)
+			}
+			if len(batch) == s.batchSize {
+				deliver(batch)
+				batch = nil
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		deliver(batch)
+	}
+
+	logger.InfoCtx(ctx, "Push notification sent",
+		zap.String("jobId", job.ID),
+		zap.String("userId", payload.UserID),
+		zap.String("topic", payload.Topic),
+		zap.Int("delivered", delivered),
+	)
+	return nil
+}
+
+// deliver sends a notification to devices, records their receipts and removes the devices whose
+// token is invalid, returning the number delivered
+// Failing to record receipts or remove devices is logged: retrying the job would notify again.
+func (s *notificationService) deliver(ctx context.Context, notificationID string, payload pushPayload, devices []*domain.Device) int {
+	if len(devices) == 0 {
+		return 0
+	}
+
+	messages := make([]resources.PushMessage, len(devices))
+	for i, device := range devices {
+		messages[i] = resources.PushMessage{
+			Platform:    device.Platform,
+			Token:       device.Token,
+			Title:       payload.Notification.Title,
+			Body:        payload.Notification.Body,
+			Data:        payload.Notification.Data,
+			CollapseKey: payload.Notification.CollapseKey,
+		}
+	}
+	results := s.push.Send(ctx, messages)
+
+	delivered := 0
+	receipts := make([]*repository.PushReceipt, len(devices))
+	var invalid []string
+	for i, device := range devices {
+		result := results[i]
+		receipts[i] = &repository.PushReceipt{
+			NotificationID: notificationID,
+			UserID:         device.UserID,
+			DeviceID:       device.ID,
+			Platform:       device.Platform,
+			Topic:          payload.Topic,
+			Status:         result.Status,
+			ProviderID:     result.ProviderID,
+			Attempts:       result.Attempts,
+			Error:          result.Error,
+		}
+		switch result.Status {
+		case resources.PushDelivered:
+			delivered++
+		case resources.PushInvalidToken:
+			invalid = append(invalid, device.Token)
+		}
+	}
+
+	if err := s.receipts.Record(ctx, receipts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to record push receipts", zap.String("notificationId", notificationID), zap.Error(err))
+	}
+	if len(invalid) > 0 {
+		removed, err := s.devices.DeleteByTokens(ctx, invalid)
+		if err != nil {
+			logger.ErrorCtx(ctx, "Failed to remove devices with invalid tokens", zap.Error(err))
+		} else {
+			logger.InfoCtx(ctx, "Removed devices with invalid tokens", zap.Int64("devices", removed))
+		}
+	}
+	return delivered
+}
+
+// pushEnabled reports whether a user has push notifications on
+func (s *notificationService) pushEnabled(ctx context.Context, userID string) (bool, error) {
+	prefs, err := s.preferences.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return prefs.Effective().Notifications.Push, nil
+}
+
+// checkUser returns ErrUserNotFound unless a user exists
+func (s *notificationService) checkUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
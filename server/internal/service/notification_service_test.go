@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/testutil"
+)
+
+type notificationTestEnv struct {
+	service     NotificationService
+	users       repository.UserRepository
+	devices     *repository.MockDeviceRepository
+	receipts    *repository.MockPushReceiptRepository
+	preferences *repository.MockPreferencesRepository
+	push        *resources.MockPush
+	jobs        *jobs.Queue
+	user        *domain.User
+}
+
+func newNotificationTestEnv(t *testing.T) *notificationTestEnv {
+	clk := testutil.NewFakeClock(testTime)
+	cfg := &config.Config{Push: config.PushConfig{BatchSize: 2, MaxRetries: 2}}
+	env := &notificationTestEnv{
+		users:       repository.NewMockUserRepository(),
+		devices:     repository.NewMockDeviceRepository(clk),
+		receipts:    repository.NewMockPushReceiptRepository(clk),
+		preferences: repository.NewMockPreferencesRepository(clk),
+		push:        resources.NewMockPush(cfg),
+		user:        &domain.User{Name: "Test User", Email: "test@example.com"},
+	}
+	require.NoError(t, env.users.Create(context.Background(), env.user))
+
+	env.jobs = jobs.NewQueue(repository.NewMockJobRepository(clk), clk, config.JobsConfig{MaxAttempts: 3})
+	env.service = NewNotificationService(env.devices, env.receipts, env.users, env.preferences, env.push, env.jobs, cfg)
+	return env
+}
+
+// register registers a device of user with token
+func (env *notificationTestEnv) register(t *testing.T, userID, platform, token string) *domain.Device {
+	device := &domain.Device{Platform: platform, Token: token}
+	_, err := env.service.RegisterDevice(context.Background(), userID, device)
+	require.NoError(t, err)
+	return device
+}
+
+// run processes the next job
+func (env *notificationTestEnv) run(t *testing.T) {
+	processed, err := env.jobs.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.True(t, processed)
+}
+
+func TestNotificationService_RegisterDevice(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Registers and re-registers a token", func(t *testing.T) {
+		env := newNotificationTestEnv(t)
+
+		device := &domain.Device{Platform: "ios", Token: "token-1", Name: "iPhone"}
+		created, err := env.service.RegisterDevice(ctx, env.user.ID, device)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEmpty(t, device.ID)
+
+		again := &domain.Device{Platform: "ios", Token: "token-1", Name: "Ada's iPhone"}
+		created, err = env.service.RegisterDevice(ctx, env.user.ID, again)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, device.ID, again.ID)
+
+		devices, err := env.service.ListDevices(ctx, env.user.ID)
+		require.NoError(t, err)
+		require.Len(t, devices, 1)
+		assert.Equal(t, "Ada's iPhone", devices[0].Name)
+	})
+
+	t.Run("Moves a token to another user without its topics", func(t *testing.T) {
+		env := newNotificationTestEnv(t)
+		device := env.register(t, env.user.ID, "android", "shared")
+		require.NoError(t, env.service.Subscribe(ctx, env.user.ID, device.ID, "news"))
+
+		other := &domain.User{Name: "Other", Email: "other@example.com"}
+		require.NoError(t, env.users.Create(ctx, other))
+		moved := env.register(t, other.ID, "android", "shared")
+		assert.Empty(t, moved.Topics)
+
+		devices, err := env.service.ListDevices(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+
+	t.Run("Rejects invalid devices and unknown users", func(t *testing.T) {
+		env := newNotificationTestEnv(t)
+
+		_, err := env.service.RegisterDevice(ctx, env.user.ID, &domain.Device{Platform: "palm", Token: "token"})
+		assert.ErrorIs(t, err, domain.ErrInvalidDevice)
+
+		_, err = env.service.RegisterDevice(ctx, "missing", &domain.Device{Platform: "web", Token: "token"})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestNotificationService_Topics(t *testing.T) {
+	ctx := context.Background()
+	env := newNotificationTestEnv(t)
+	device := env.register(t, env.user.ID, "web", "token-1")
+
+	require.NoError(t, env.service.Subscribe(ctx, env.user.ID, device.ID, "news"))
+	require.NoError(t, env.service.Subscribe(ctx, env.user.ID, device.ID, "news"), "subscribing twice is a no-op")
+	stored, err := env.devices.Get(ctx, env.user.ID, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"news"}, stored.Topics)
+
+	assert.ErrorIs(t, env.service.Subscribe(ctx, env.user.ID, device.ID, "no spaces"), domain.ErrInvalidDevice)
+	assert.ErrorIs(t, env.service.Subscribe(ctx, "someone-else", device.ID, "news"), ErrDeviceNotFound)
+
+	require.NoError(t, env.service.Unsubscribe(ctx, env.user.ID, device.ID, "news"))
+	stored, err = env.devices.Get(ctx, env.user.ID, device.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Topics)
+
+	require.NoError(t, env.service.UnregisterDevice(ctx, env.user.ID, device.ID))
+	assert.ErrorIs(t, env.service.UnregisterDevice(ctx, env.user.ID, device.ID), ErrDeviceNotFound)
+}
+
+func TestNotificationService_NotifyUser(t *testing.T) {
+	ctx := context.Background()
+	notification := &domain.Notification{Title: "Quiz ready", Body: "Your quiz is ready", Data: map[string]string{"quizId": "42"}}
+
+	t.Run("Sends to every device and records receipts", func(t *testing.T) {
+		env := newNotificationTestEnv(t)
+		env.register(t, env.user.ID, "ios", "ios-token")
+		env.register(t, env.user.ID, "android", "android-token")
+		env.register(t, env.user.ID, "web", "stale-token")
+		env.push.InvalidateToken("stale-token")
+
+		job, err := env.service.NotifyUser(ctx, env.user.ID, notification)
+		require.NoError(t, err)
+		env.run(t)
+
+		sent := env.push.Sent()
+		require.Len(t, sent, 3)
+		assert.Equal(t, "Quiz ready", sent[0].Title)
+		assert.Equal(t, "42", sent[0].Data["quizId"])
+
+		sending, receipts, err := env.service.Status(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, repository.JobSucceeded, sending.Status)
+		assert.Equal(t, map[string]int64{resources.PushDelivered: 2, resources.PushInvalidToken: 1}, receipts)
+
+		devices, err := env.service.ListDevices(ctx, env.user.ID)
+		require.NoError(t, err)
+		assert.Len(t, devices, 2, "the device with an invalid token is removed")
+	})
+
+	t.Run("Skips users who turned push notifications off", func(t *testing.T) {
+		env := newNotificationTestEnv(t)
+		env.register(t, env.user.ID, "ios", "ios-token")
+		off := false
+		require.NoError(t, env.preferences.Put(ctx, &domain.UserPreferences{
+			UserID:   env.user.ID,
+			Settings: domain.PreferenceSettings{Notifications: domain.NotificationSettings{Push: &off}},
+		}))
+
+		_, err := env.service.NotifyUser(ctx, env.user.ID, notification)
+		require.NoError(t, err)
+		env.run(t)
+
+		assert.Empty(t, env.push.Sent())
+	})
+
+	t.Run("Rejects invalid notifications and unknown users", func(t *testing.T) {
+		env := newNotificationTestEnv(t)
+
+		_, err := env.service.NotifyUser(ctx, env.user.ID, &domain.Notification{})
+		assert.ErrorIs(t, err, domain.ErrInvalidNotification)
+
+		_, err = env.service.NotifyUser(ctx, "missing", notification)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestNotificationService_NotifyTopic(t *testing.T) {
+	ctx := context.Background()
+	env := newNotificationTestEnv(t)
+
+	// Five subscribers across two users, sent in batches of two; the second user has push off
+	for _, token := range []string{"a", "b", "c"} {
+		device := env.register(t, env.user.ID, "android", token)
+		require.NoError(t, env.service.Subscribe(ctx, env.user.ID, device.ID, "news"))
+	}
+	other := &domain.User{Name: "Other", Email: "other@example.com"}
+	require.NoError(t, env.users.Create(ctx, other))
+	off := false
+	require.NoError(t, env.preferences.Put(ctx, &domain.UserPreferences{
+		UserID:   other.ID,
+		Settings: domain.PreferenceSettings{Notifications: domain.NotificationSettings{Push: &off}},
+	}))
+	for _, token := range []string{"d", "e"} {
+		device := env.register(t, other.ID, "ios", token)
+		require.NoError(t, env.service.Subscribe(ctx, other.ID, device.ID, "news"))
+	}
+	env.register(t, env.user.ID, "web", "unsubscribed")
+	env.push.FailToken("c")
+
+	job, err := env.service.NotifyTopic(ctx, "news", &domain.Notification{Title: "Breaking"})
+	require.NoError(t, err)
+	env.run(t)
+
+	assert.Len(t, env.push.Sent(), 3)
+	_, receipts, err := env.service.Status(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{resources.PushDelivered: 2, resources.PushFailed: 1}, receipts)
+
+	_, err = env.service.NotifyTopic(ctx, "not a topic", &domain.Notification{Title: "Breaking"})
+	assert.ErrorIs(t, err, domain.ErrInvalidDevice)
+
+	_, _, err = env.service.Status(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotificationNotFound)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+)
+
+// PreferencesService manages the settings of users
+// Users who never set preferences get the defaults; stored settings are merged over the defaults
+// when read, so only the settings a user chose are kept.
+type PreferencesService interface {
+	// Get returns the preferences of a user; users without stored preferences get ones at version 0
+	// holding no settings. Unknown users return ErrUserNotFound.
+	Get(ctx context.Context, userID string) (*domain.UserPreferences, error)
+
+	// Update replaces the settings of a user, returning the stored preferences
+	// When expectedVersion is set, the write only applies if the preferences are still at that
+	// version; otherwise, or when they change concurrently, ErrVersionConflict is returned.
+	// Invalid settings return an error wrapping domain.ErrInvalidPreferences.
+	Update(ctx context.Context, userID string, settings domain.PreferenceSettings, expectedVersion *int64) (*domain.UserPreferences, error)
+}
+
+// preferencesService implements the PreferencesService interface
+type preferencesService struct {
+	repo     repository.PreferencesRepository
+	userRepo repository.UserRepository
+}
+
+// NewPreferencesService creates a new PreferencesService
+func NewPreferencesService(repo repository.PreferencesRepository, userRepo repository.UserRepository) PreferencesService {
+	return &preferencesService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// Get returns the preferences of a user
+func (s *preferencesService) Get(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		prefs = &domain.UserPreferences{UserID: userID}
+	}
+	return prefs, nil
+}
+
+// Update replaces the settings of a user
+func (s *preferencesService) Update(ctx context.Context, userID string, settings domain.PreferenceSettings, expectedVersion *int64) (*domain.UserPreferences, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && *expectedVersion != current.Version {
+		return nil, ErrVersionConflict
+	}
+
+	prefs := &domain.UserPreferences{UserID: userID, Settings: settings, Version: current.Version}
+	if err := s.repo.Put(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// checkUser returns ErrUserNotFound unless a user exists
+func (s *preferencesService) checkUser(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
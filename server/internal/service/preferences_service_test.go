@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+func newPreferencesTestService(t *testing.T) (PreferencesService, *domain.User) {
+	users := repository.NewMockUserRepository()
+	user := &domain.User{Name: "Ada", Email: "ada@example.com"}
+	require.NoError(t, users.Create(context.Background(), user))
+
+	repo := repository.NewMockPreferencesRepository(testutil.NewFakeClock(testTime))
+	return NewPreferencesService(repo, users), user
+}
+
+func TestPreferencesService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Defaults without stored preferences", func(t *testing.T) {
+		service, user := newPreferencesTestService(t)
+
+		prefs, err := service.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), prefs.Version)
+		assert.Equal(t, domain.DefaultPreferences(), prefs.Effective())
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		service, _ := newPreferencesTestService(t)
+
+		_, err := service.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestPreferencesService_Update(t *testing.T) {
+	ctx := context.Background()
+	dark, daily := "dark", "daily"
+
+	t.Run("Stores settings merged over the defaults", func(t *testing.T) {
+		service, user := newPreferencesTestService(t)
+
+		prefs, err := service.Update(ctx, user.ID, domain.PreferenceSettings{Theme: &dark}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), prefs.Version)
+		assert.Equal(t, testTime, prefs.UpdatedAt)
+
+		stored, err := service.Get(ctx, user.ID)
+		require.NoError(t, err)
+		effective := stored.Effective()
+		assert.Equal(t, "dark", effective.Theme)
+		assert.Equal(t, "en", effective.Locale)
+
+		// A replacement drops the settings it omits
+		_, err = service.Update(ctx, user.ID, domain.PreferenceSettings{
+			Notifications: domain.NotificationSettings{Digest: &daily},
+		}, nil)
+		require.NoError(t, err)
+		stored, err = service.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "system", stored.Effective().Theme)
+		assert.Equal(t, "daily", stored.Effective().Notifications.Digest)
+		assert.Equal(t, int64(2), stored.Version)
+	})
+
+	t.Run("Expected version", func(t *testing.T) {
+		service, user := newPreferencesTestService(t)
+		stale := int64(0)
+
+		_, err := service.Update(ctx, user.ID, domain.PreferenceSettings{Theme: &dark}, &stale)
+		require.NoError(t, err)
+
+		_, err = service.Update(ctx, user.ID, domain.PreferenceSettings{}, &stale)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		service, user := newPreferencesTestService(t)
+		theme := "neon"
+
+		_, err := service.Update(ctx, user.ID, domain.PreferenceSettings{Theme: &theme}, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidPreferences)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		service, _ := newPreferencesTestService(t)
+
+		_, err := service.Update(ctx, "missing", domain.PreferenceSettings{}, nil)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clientip"
+	"quizizz.com/pkg/clock"
+)
+
+// Session errors
+var (
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrInvalidRefreshToken is returned for malformed, unknown, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenReused is returned when a refresh token already rotated out is presented again,
+	// which means it leaked; the session is revoked
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// Audit actions recorded for sessions
+const (
+	AuditSessionRevoked     = "session.revoked"
+	AuditSessionTokenReused = "session.token_reused"
+)
+
+// Reasons sessions are revoked for
+const (
+	RevokedByUser     = "user"
+	RevokedTokenReuse = "token_reused"
+	RevokedEvicted    = "evicted"
+	RevokedByAdmin    = "admin"
+)
+
+// SessionService issues and rotates the refresh tokens of user sessions
+// A refresh token is "<session ID>.<secret>"; every refresh replaces it, and presenting a replaced
+// token again revokes the session, as either the client or an attacker holds a stolen copy.
+type SessionService interface {
+	// Issue starts a session of a user on the device named device, e.g. "Pixel 8", returning it with
+	// its refresh token
+	Issue(ctx context.Context, userID, device, userAgent string) (*repository.Session, string, error)
+
+	// Refresh rotates a refresh token, returning the session and the token replacing it
+	Refresh(ctx context.Context, token, userAgent string) (*repository.Session, string, error)
+
+	// List returns the active sessions of a user, most recently used first
+	List(ctx context.Context, userID string) ([]*repository.Session, error)
+
+	// Revoke revokes a session of a user, or returns ErrSessionNotFound
+	Revoke(ctx context.Context, userID, sessionID string) error
+
+	// RevokeAll revokes every active session of a user, returning how many were revoked
+	RevokeAll(ctx context.Context, userID string) (int64, error)
+}
+
+// sessionService implements the SessionService interface
+type sessionService struct {
+	repo  repository.SessionRepository
+	audit repository.AuditRepository
+	clock clock.Clock
+	cfg   config.SessionConfig
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(repo repository.SessionRepository, audit repository.AuditRepository, clk clock.Clock, cfg *config.Config) SessionService {
+	return &sessionService{
+		repo:  repo,
+		audit: audit,
+		clock: clk,
+		cfg:   cfg.Sessions,
+	}
+}
+
+// Issue starts a session, revoking the least recently used ones beyond the per-user cap
+func (s *sessionService) Issue(ctx context.Context, userID, device, userAgent string) (*repository.Session, string, error) {
+	secret, err := newTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	session := &repository.Session{
+		UserID:    userID,
+		Device:    device,
+		UserAgent: userAgent,
+		ClientIP:  clientip.FromContext(ctx),
+		TokenHash: hashTokenSecret(secret),
+		ExpiresAt: s.clock.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		logger.ErrorCtx(ctx, "Failed to create session", zap.String("userId", userID), zap.Error(err))
+		return nil, "", err
+	}
+	logger.Info("Session issued", zap.String("userId", userID), zap.String("sessionId", session.ID))
+
+	if err := s.evict(ctx, userID); err != nil {
+		logger.ErrorCtx(ctx, "Failed to evict sessions over the cap", zap.String("userId", userID), zap.Error(err))
+	}
+	return session, formatRefreshToken(session.ID, secret), nil
+}
+
+// Refresh rotates a refresh token
+// A token matching one rotated out of its session, or losing a concurrent rotation, is reuse.
+func (s *sessionService) Refresh(ctx context.Context, token, userAgent string) (*repository.Session, string, error) {
+	sessionID, secret, ok := parseRefreshToken(token)
+	if !ok {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	session, err := s.repo.Get(ctx, sessionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	hash := hashTokenSecret(secret)
+	if !tokenHashEqual(hash, session.TokenHash) {
+		for _, previous := range session.PreviousTokenHashes {
+			if tokenHashEqual(hash, previous) {
+				return nil, "", s.reused(ctx, session)
+			}
+		}
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if !session.Active(s.clock.Now()) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	newSecret, err := newTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	session.ClientIP = clientip.FromContext(ctx)
+	session.UserAgent = userAgent
+	err = s.repo.Rotate(ctx, session, hash, hashTokenSecret(newSecret), s.clock.Now().Add(s.cfg.RefreshTokenTTL))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "", s.reused(ctx, session)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to rotate refresh token", zap.String("sessionId", session.ID), zap.Error(err))
+		return nil, "", err
+	}
+
+	logger.Debug("Refresh token rotated", zap.String("userId", session.UserID), zap.String("sessionId", session.ID))
+	return session, formatRefreshToken(session.ID, newSecret), nil
+}
+
+// List returns the active sessions of a user
+func (s *sessionService) List(ctx context.Context, userID string) ([]*repository.Session, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Revoke revokes a session of a user, recording it in the audit log
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	err := s.repo.Revoke(ctx, userID, sessionID, RevokedByUser)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to revoke session", zap.String("sessionId", sessionID), zap.Error(err))
+		return err
+	}
+
+	logger.Info("Session revoked", zap.String("userId", userID), zap.String("sessionId", sessionID))
+	return s.record(ctx, AuditSessionRevoked, userID, map[string]interface{}{"sessionId": sessionID, "reason": RevokedByUser})
+}
+
+// RevokeAll revokes every active session of a user, recording it in the audit log
+func (s *sessionService) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	n, err := s.repo.RevokeAll(ctx, userID, RevokedByUser)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to revoke sessions", zap.String("userId", userID), zap.Error(err))
+		return 0, err
+	}
+
+	logger.Info("Sessions revoked", zap.String("userId", userID), zap.Int64("count", n))
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.record(ctx, AuditSessionRevoked, userID, map[string]interface{}{"count": n, "reason": RevokedByUser})
+}
+
+// reused revokes a session whose refresh token was presented after being rotated out
+func (s *sessionService) reused(ctx context.Context, session *repository.Session) error {
+	logger.WarnCtx(ctx, "Refresh token reused, revoking session",
+		zap.String("userId", session.UserID), zap.String("sessionId", session.ID))
+
+	err := s.repo.Revoke(ctx, session.UserID, session.ID, RevokedTokenReuse)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		logger.ErrorCtx(ctx, "Failed to revoke session of a reused token", zap.String("sessionId", session.ID), zap.Error(err))
+		return err
+	}
+	if err := s.record(ctx, AuditSessionTokenReused, session.UserID, map[string]interface{}{"sessionId": session.ID}); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// evict revokes the least recently used sessions of a user beyond the per-user cap
+func (s *sessionService) evict(ctx context.Context, userID string) error {
+	if s.cfg.MaxPerUser <= 0 {
+		return nil
+	}
+	sessions, err := s.repo.ListByUser(ctx, userID)
+	if err != nil || len(sessions) <= s.cfg.MaxPerUser {
+		return err
+	}
+
+	for _, session := range sessions[s.cfg.MaxPerUser:] {
+		if err := s.repo.Revoke(ctx, userID, session.ID, RevokedEvicted); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		logger.Info("Session evicted", zap.String("userId", userID), zap.String("sessionId", session.ID))
+	}
+	return nil
+}
+
+// record appends an audit record of a session action on a user
+func (s *sessionService) record(ctx context.Context, action, userID string, details map[string]interface{}) error {
+	err := s.audit.Record(ctx, &repository.AuditRecord{Action: action, Subject: userID, Details: details})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to record session audit event", zap.String("action", action), zap.Error(err))
+	}
+	return err
+}
+
+// newTokenSecret returns a random refresh token secret
+func newTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashTokenSecret returns the hash of a refresh token secret stored in its session
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenHashEqual compares token hashes in constant time
+func tokenHashEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// formatRefreshToken returns the refresh token of a session secret
+func formatRefreshToken(sessionID, secret string) string {
+	return sessionID + "." + secret
+}
+
+// parseRefreshToken splits a refresh token into its session ID and secret
+func parseRefreshToken(token string) (string, string, bool) {
+	sessionID, secret, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", false
+	}
+	return sessionID, secret, true
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+type sessionTestEnv struct {
+	service SessionService
+	repo    *repository.MockSessionRepository
+	audit   *repository.MockAuditRepository
+	clock   *testutil.FakeClock
+}
+
+func newSessionTestEnv(maxPerUser int) *sessionTestEnv {
+	clk := testutil.NewFakeClock(testTime)
+	env := &sessionTestEnv{
+		repo:  repository.NewMockSessionRepository(clk),
+		audit: repository.NewMockAuditRepository(clk),
+		clock: clk,
+	}
+	cfg := &config.Config{Sessions: config.SessionConfig{RefreshTokenTTL: 24 * time.Hour, MaxPerUser: maxPerUser}}
+	env.service = NewSessionService(env.repo, env.audit, clk, cfg)
+	return env
+}
+
+func TestSessionService_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Rotates the token and extends the session", func(t *testing.T) {
+		env := newSessionTestEnv(0)
+		issued, token, err := env.service.Issue(ctx, "user-1", "Pixel 8", "app/1.0")
+		require.NoError(t, err)
+		assert.Equal(t, testTime.Add(24*time.Hour), issued.ExpiresAt)
+
+		env.clock.Advance(time.Hour)
+		session, rotated, err := env.service.Refresh(ctx, token, "app/1.1")
+		require.NoError(t, err)
+		assert.NotEqual(t, token, rotated)
+		assert.Equal(t, issued.ID, session.ID)
+		assert.Equal(t, "Pixel 8", session.Device)
+		assert.Equal(t, "app/1.1", session.UserAgent)
+		assert.Equal(t, testTime.Add(25*time.Hour), session.ExpiresAt)
+
+		// The rotated token refreshes in turn
+		_, _, err = env.service.Refresh(ctx, rotated, "")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reused token revokes the session", func(t *testing.T) {
+		env := newSessionTestEnv(0)
+		issued, token, err := env.service.Issue(ctx, "user-1", "", "")
+		require.NoError(t, err)
+		_, rotated, err := env.service.Refresh(ctx, token, "")
+		require.NoError(t, err)
+
+		_, _, err = env.service.Refresh(ctx, token, "")
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+		// The legitimate holder of the rotated token is signed out too
+		_, _, err = env.service.Refresh(ctx, rotated, "")
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		sessions, err := env.service.List(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+
+		records, err := env.audit.ListBySubject(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, AuditSessionTokenReused, records[0].Action)
+		assert.Equal(t, issued.ID, records[0].Details["sessionId"])
+	})
+
+	t.Run("Invalid tokens", func(t *testing.T) {
+		env := newSessionTestEnv(0)
+		issued, token, err := env.service.Issue(ctx, "user-1", "", "")
+		require.NoError(t, err)
+
+		for _, invalid := range []string{"", "no-separator", issued.ID + ".wrong", "unknown." + token} {
+			_, _, err := env.service.Refresh(ctx, invalid, "")
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken, invalid)
+		}
+
+		env.clock.Advance(25 * time.Hour)
+		_, _, err = env.service.Refresh(ctx, token, "")
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Revokes one session", func(t *testing.T) {
+		env := newSessionTestEnv(0)
+		first, token, err := env.service.Issue(ctx, "user-1", "laptop", "")
+		require.NoError(t, err)
+		second, _, err := env.service.Issue(ctx, "user-1", "phone", "")
+		require.NoError(t, err)
+
+		require.NoError(t, env.service.Revoke(ctx, "user-1", first.ID))
+
+		sessions, err := env.service.List(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, second.ID, sessions[0].ID)
+
+		_, _, err = env.service.Refresh(ctx, token, "")
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+		records, err := env.audit.ListBySubject(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, AuditSessionRevoked, records[0].Action)
+	})
+
+	t.Run("Other users' sessions are not found", func(t *testing.T) {
+		env := newSessionTestEnv(0)
+		session, _, err := env.service.Issue(ctx, "user-1", "", "")
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, env.service.Revoke(ctx, "user-2", session.ID), ErrSessionNotFound)
+		assert.ErrorIs(t, env.service.Revoke(ctx, "user-1", "missing"), ErrSessionNotFound)
+	})
+
+	t.Run("Revokes every session", func(t *testing.T) {
+		env := newSessionTestEnv(0)
+		for i := 0; i < 3; i++ {
+			_, _, err := env.service.Issue(ctx, "user-1", "", "")
+			require.NoError(t, err)
+		}
+		_, _, err := env.service.Issue(ctx, "user-2", "", "")
+		require.NoError(t, err)
+
+		n, err := env.service.RevokeAll(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		sessions, err := env.service.List(ctx, "user-2")
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+	})
+}
+
+func TestSessionService_Issue(t *testing.T) {
+	ctx := context.Background()
+	env := newSessionTestEnv(2)
+
+	oldest, _, err := env.service.Issue(ctx, "user-1", "oldest", "")
+	require.NoError(t, err)
+	for _, name := range []string{"middle", "newest"} {
+		env.clock.Advance(time.Minute)
+		_, _, err := env.service.Issue(ctx, "user-1", name, "")
+		require.NoError(t, err)
+	}
+
+	sessions, err := env.service.List(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "newest", sessions[0].Device)
+	assert.Equal(t, "middle", sessions[1].Device)
+	assert.ErrorIs(t, env.service.Revoke(ctx, "user-1", oldest.ID), ErrSessionNotFound)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
+)
+
+// Upload errors
+var (
+	ErrUploadsDisabled = errors.New("uploads are disabled")
+	ErrUploadNotFound  = errors.New("upload not found")
+
+	// ErrUploadIncomplete is returned when confirming an upload whose object is not in the bucket yet
+	ErrUploadIncomplete = errors.New("upload is not complete")
+
+	// ErrUploadMismatch is returned when the uploaded object does not match the presigned constraints;
+	// the object and the upload are deleted
+	ErrUploadMismatch = errors.New("uploaded object does not match the upload")
+)
+
+// UploadService issues presigned URLs uploading files straight to object storage and registers the
+// uploaded objects
+// Presign stores a pending upload and signs a PUT request bound to its content type and size; once
+// the client uploaded the file, Confirm checks the object against them and completes the upload.
+// File bytes never go through the API servers.
+type UploadService interface {
+	// Presign validates an upload of a user, stores it as pending and returns the request uploading
+	// its file
+	// Invalid uploads, and uploads of types or sizes the configuration does not allow, return an
+	// error wrapping domain.ErrInvalidUpload.
+	Presign(ctx context.Context, userID string, upload *domain.Upload) (*resources.PresignedRequest, error)
+
+	// Confirm checks the uploaded object of a pending upload of a user and completes it; confirming
+	// a completed upload returns it
+	Confirm(ctx context.Context, userID, id string) (*domain.Upload, error)
+
+	// Get returns an upload of a user, or ErrUploadNotFound
+	Get(ctx context.Context, userID, id string) (*domain.Upload, error)
+}
+
+// uploadService implements the UploadService interface
+type uploadService struct {
+	uploads repository.UploadRepository
+	storage resources.ObjectStorageResource
+	ids     idgen.Generator
+	clock   clock.Clock
+	cfg     config.UploadsConfig
+}
+
+// NewUploadService creates a new UploadService storing files in storage, which is nil while
+// uploads are disabled
+func NewUploadService(
+	uploads repository.UploadRepository,
+	storage resources.ObjectStorageResource,
+	ids idgen.Generator,
+	clk clock.Clock,
+	cfg *config.Config,
+) UploadService {
+	return &uploadService{
+		uploads: uploads,
+		storage: storage,
+		ids:     ids,
+		clock:   clk,
+		cfg:     cfg.Uploads,
+	}
+}
+
+// Presign stores a pending upload under a key of its own and presigns its PUT request
+func (s *uploadService) Presign(ctx context.Context, userID string, upload *domain.Upload) (*resources.PresignedRequest, error) {
+	if !s.enabled() {
+		return nil, ErrUploadsDisabled
+	}
+	if err := upload.Validate(); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(s.cfg.ContentTypes, upload.MediaType()) {
+		return nil, fmt.Errorf("%w: %s files cannot be uploaded", domain.ErrInvalidUpload, upload.MediaType())
+	}
+	if s.cfg.MaxSize > 0 && upload.Size > s.cfg.MaxSize {
+		return nil, fmt.Errorf("%w: files must not exceed %d bytes", domain.ErrInvalidUpload, s.cfg.MaxSize)
+	}
+
+	upload.UserID = userID
+	upload.Key = s.cfg.KeyPrefix + userID + "/" + s.ids.NewID()
+	upload.ExpiresAt = s.clock.Now().Add(s.cfg.URLTTL)
+	if err := s.uploads.Create(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	request, err := s.storage.PresignPut(ctx, upload.Key, upload.ContentType, upload.Size, s.cfg.URLTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign upload %s: %w", upload.ID, err)
+	}
+	return request, nil
+}
+
+// Confirm checks that the object of an upload has the size and content type it was presigned for
+func (s *uploadService) Confirm(ctx context.Context, userID, id string) (*domain.Upload, error) {
+	if !s.enabled() {
+		return nil, ErrUploadsDisabled
+	}
+	upload, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status == domain.UploadCompleted {
+		return upload, nil
+	}
+
+	object, err := s.storage.Stat(ctx, upload.Key)
+	if errors.Is(err, resources.ErrObjectNotFound) {
+		return nil, ErrUploadIncomplete
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if object.Size != upload.Size || !sameMediaType(object.ContentType, upload.MediaType()) {
+		logger.WarnCtx(ctx, "Uploaded object does not match its upload",
+			zap.String("uploadId", upload.ID),
+			zap.Int64("size", object.Size),
+			zap.String("contentType", object.ContentType),
+		)
+		if err := s.storage.Delete(ctx, upload.Key); err != nil {
+			return nil, fmt.Errorf("failed to delete mismatched object of upload %s: %w", upload.ID, err)
+		}
+		if err := s.uploads.Delete(ctx, userID, id); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, ErrUploadMismatch
+	}
+
+	completed, err := s.uploads.Complete(ctx, userID, id, object.ETag)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Completed by a concurrent confirmation
+		return s.Get(ctx, userID, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.InfoCtx(ctx, "Upload completed", zap.String("userId", userID), zap.String("uploadId", id), zap.Int64("size", completed.Size))
+	return completed, nil
+}
+
+// Get returns an upload of a user
+func (s *uploadService) Get(ctx context.Context, userID, id string) (*domain.Upload, error) {
+	upload, err := s.uploads.Get(ctx, userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUploadNotFound
+	}
+	return upload, err
+}
+
+// enabled reports whether uploads are enabled and object storage is configured
+func (s *uploadService) enabled() bool {
+	return s.cfg.Enabled && s.storage != nil
+}
+
+// sameMediaType reports whether contentType, with or without parameters, has mediaType
+func sameMediaType(contentType, mediaType string) bool {
+	upload := domain.Upload{ContentType: contentType}
+	return upload.MediaType() == mediaType
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/resources"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/idgen"
+)
+
+type uploadTestEnv struct {
+	service UploadService
+	uploads *repository.MockUploadRepository
+	storage *resources.MockObjectStorage
+}
+
+func newUploadTestEnv(t *testing.T) *uploadTestEnv {
+	clk := testutil.NewFakeClock(testTime)
+	cfg := &config.Config{Uploads: config.UploadsConfig{
+		Enabled:      true,
+		KeyPrefix:    "uploads/",
+		URLTTL:       15 * time.Minute,
+		MaxSize:      1024,
+		ContentTypes: []string{"image/png", "application/pdf"},
+	}}
+	env := &uploadTestEnv{
+		uploads: repository.NewMockUploadRepository(clk),
+		storage: resources.NewMockObjectStorage(),
+	}
+	require.NoError(t, env.storage.Connect(context.Background()))
+	env.service = NewUploadService(env.uploads, env.storage, idgen.Func(func() string { return "object-1" }), clk, cfg)
+	return env
+}
+
+// presign presigns an upload of a PNG file of size bytes for user-1
+func (env *uploadTestEnv) presign(t *testing.T, size int64) *domain.Upload {
+	upload := &domain.Upload{Filename: "photo.png", ContentType: "image/png", Size: size}
+	_, err := env.service.Presign(context.Background(), "user-1", upload)
+	require.NoError(t, err)
+	return upload
+}
+
+func TestUploadService_Presign(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores a pending upload", func(t *testing.T) {
+		env := newUploadTestEnv(t)
+		upload := &domain.Upload{Filename: "photo.png", ContentType: "image/png", Size: 512}
+
+		request, err := env.service.Presign(ctx, "user-1", upload)
+		require.NoError(t, err)
+		assert.Equal(t, "PUT", request.Method)
+		assert.Equal(t, map[string]string{"Content-Type": "image/png", "Content-Length": "512"}, request.Headers)
+
+		assert.Equal(t, "uploads/user-1/object-1", upload.Key)
+		assert.Equal(t, domain.UploadPending, upload.Status)
+		assert.Equal(t, testTime.Add(15*time.Minute), upload.ExpiresAt)
+
+		stored, err := env.service.Get(ctx, "user-1", upload.ID)
+		require.NoError(t, err)
+		assert.Equal(t, upload.Key, stored.Key)
+	})
+
+	t.Run("Rejects disallowed uploads", func(t *testing.T) {
+		env := newUploadTestEnv(t)
+		for name, upload := range map[string]*domain.Upload{
+			"content type": {Filename: "clip.mp4", ContentType: "video/mp4", Size: 512},
+			"size":         {Filename: "photo.png", ContentType: "image/png", Size: 2048},
+			"filename":     {Filename: "../photo.png", ContentType: "image/png", Size: 512},
+		} {
+			_, err := env.service.Presign(ctx, "user-1", upload)
+			assert.ErrorIs(t, err, domain.ErrInvalidUpload, name)
+		}
+		uploads, err := env.uploads.ListByUser(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Empty(t, uploads)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		service := NewUploadService(repository.NewMockUploadRepository(nil), nil, idgen.Func(func() string { return "object-1" }), nil, &config.Config{})
+		_, err := service.Presign(ctx, "user-1", &domain.Upload{Filename: "photo.png", ContentType: "image/png", Size: 512})
+		assert.ErrorIs(t, err, ErrUploadsDisabled)
+	})
+}
+
+func TestUploadService_Confirm(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Completes uploaded objects", func(t *testing.T) {
+		env := newUploadTestEnv(t)
+		upload := env.presign(t, 4)
+		env.storage.Put(upload.Key, "image/png", []byte("test"))
+
+		completed, err := env.service.Confirm(ctx, "user-1", upload.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.UploadCompleted, completed.Status)
+		assert.Equal(t, "098f6bcd4621d373cade4e832627b4f6", completed.ETag)
+		assert.Equal(t, testTime, completed.CompletedAt)
+
+		// Confirming again returns the completed upload
+		again, err := env.service.Confirm(ctx, "user-1", upload.ID)
+		require.NoError(t, err)
+		assert.Equal(t, completed, again)
+	})
+
+	t.Run("Waits for the object", func(t *testing.T) {
+		env := newUploadTestEnv(t)
+		upload := env.presign(t, 4)
+
+		_, err := env.service.Confirm(ctx, "user-1", upload.ID)
+		assert.ErrorIs(t, err, ErrUploadIncomplete)
+	})
+
+	t.Run("Deletes mismatched objects", func(t *testing.T) {
+		env := newUploadTestEnv(t)
+		upload := env.presign(t, 4)
+		env.storage.Put(upload.Key, "text/html", []byte("test"))
+
+		_, err := env.service.Confirm(ctx, "user-1", upload.ID)
+		assert.ErrorIs(t, err, ErrUploadMismatch)
+		assert.False(t, env.storage.Exists(upload.Key))
+		_, err = env.service.Get(ctx, "user-1", upload.ID)
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+	})
+
+	t.Run("Uploads of other users are not found", func(t *testing.T) {
+		env := newUploadTestEnv(t)
+		upload := env.presign(t, 4)
+		env.storage.Put(upload.Key, "image/png", []byte("test"))
+
+		_, err := env.service.Confirm(ctx, "user-2", upload.ID)
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+	})
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
+)
+
+// publishingUserService decorates the writes of a UserService with publishing user events
+type publishingUserService struct {
+	UserService
+	publisher events.Publisher
+	ids       idgen.Generator
+	clock     clock.Clock
+}
+
+// NewPublishingUserService decorates users so that successful creates, updates and deletes publish
+// the matching user event to publisher; reads are passed through
+// Events are published after the write, so a failed publish is logged but does not fail the write:
+// consumers catch up from their own rebuilds.
+func NewPublishingUserService(users UserService, publisher events.Publisher, ids idgen.Generator, clk clock.Clock) UserService {
+	return &publishingUserService{
+		UserService: users,
+		publisher:   publisher,
+		ids:         ids,
+		clock:       clk,
+	}
+}
+
+// Create creates a user and publishes user.created
+func (s *publishingUserService) Create(ctx context.Context, user *domain.User) error {
+	if err := s.UserService.Create(ctx, user); err != nil {
+		return err
+	}
+	s.publish(ctx, events.UserCreated(user))
+	return nil
+}
+
+// Update updates a user and publishes user.updated
+func (s *publishingUserService) Update(ctx context.Context, user *domain.User) error {
+	if err := s.UserService.Update(ctx, user); err != nil {
+		return err
+	}
+	payload := events.UserUpdated(user)
+	if payload.UpdatedAt.IsZero() {
+		payload.UpdatedAt = s.clock.Now()
+	}
+	s.publish(ctx, payload)
+	return nil
+}
+
+// Delete deletes a user and publishes user.deleted
+func (s *publishingUserService) Delete(ctx context.Context, id string) error {
+	if err := s.UserService.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.publish(ctx, events.UserDeleted(id, s.clock.Now()))
+	return nil
+}
+
+// publish publishes an event of payload, logging failures
+func (s *publishingUserService) publish(ctx context.Context, payload events.Payload) {
+	event, err := events.New(s.ids, s.clock.Now(), payload)
+	if err == nil {
+		err = s.publisher.Publish(ctx, event)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish user event",
+			zap.String("eventType", payload.EventType()),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/idgen"
+)
+
+// recordingPublisher records the events published to it, failing with err when set
+type recordingPublisher struct {
+	events []*events.Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *events.Event) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func newPublishingTestService() (UserService, *recordingPublisher) {
+	clk := testutil.NewFakeClock(testTime)
+	publisher := &recordingPublisher{}
+	ids := idgen.Func(func() string { return "event-1" })
+	return NewPublishingUserService(NewUserService(repository.NewMockUserRepository(), clk), publisher, ids, clk), publisher
+}
+
+func TestPublishingUserService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Publishes writes", func(t *testing.T) {
+		service, publisher := newPublishingTestService()
+
+		user := &domain.User{Name: "Ada", Email: "ada@example.com"}
+		require.NoError(t, service.Create(ctx, user))
+		user.Name = "Ada Lovelace"
+		require.NoError(t, service.Update(ctx, user))
+		require.NoError(t, service.Delete(ctx, user.ID))
+
+		require.Len(t, publisher.events, 3)
+		assert.Equal(t, events.UserCreatedType, publisher.events[0].Type)
+		assert.Equal(t, user.ID, publisher.events[0].Payload.(*events.UserCreatedV1).UserID)
+		assert.Equal(t, domain.UserName("Ada Lovelace"), publisher.events[1].Payload.(*events.UserUpdatedV1).Name)
+		assert.Equal(t, &events.UserDeletedV1{UserID: user.ID, DeletedAt: testTime}, publisher.events[2].Payload)
+	})
+
+	t.Run("Failed writes publish nothing", func(t *testing.T) {
+		service, publisher := newPublishingTestService()
+
+		assert.ErrorIs(t, service.Delete(ctx, "missing"), ErrUserNotFound)
+		assert.ErrorIs(t, service.Create(ctx, &domain.User{Name: "Ada", Email: "ada"}), ErrInvalidUser)
+		assert.Empty(t, publisher.events)
+	})
+
+	t.Run("Failed publishes do not fail the write", func(t *testing.T) {
+		service, publisher := newPublishingTestService()
+		publisher.err = errors.New("bus unavailable")
+
+		user := &domain.User{Name: "Ada", Email: "ada@example.com"}
+		require.NoError(t, service.Create(ctx, user))
+
+		stored, err := service.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored)
+	})
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/metrics"
+)
+
+// instrumentedUserService decorates the writes of a UserService with business metrics
+type instrumentedUserService struct {
+	UserService
+	clock clock.Clock
+
+	created  *metrics.Counter
+	deleted  *metrics.Counter
+	duration *metrics.Histogram
+}
+
+// NewInstrumentedUserService decorates users so that creates, updates and deletes are counted and
+// timed on registry by outcome; reads are passed through
+func NewInstrumentedUserService(users UserService, registry *metrics.Registry, clk clock.Clock) UserService {
+	return &instrumentedUserService{
+		UserService: users,
+		clock:       clk,
+		created:     registry.Counter("users.created", "Number of users created"),
+		deleted:     registry.Counter("users.deleted", "Number of users deleted"),
+		duration: registry.Histogram("users.write.duration",
+			"Duration of user creates, updates and deletes by operation and outcome", "s"),
+	}
+}
+
+// Create creates a user, counting it once created
+func (s *instrumentedUserService) Create(ctx context.Context, user *domain.User) error {
+	start := s.clock.Now()
+	err := s.UserService.Create(ctx, user)
+	s.record(ctx, "create", start, err)
+	if err == nil {
+		s.created.Inc(ctx)
+	}
+	return err
+}
+
+// Update updates a user
+func (s *instrumentedUserService) Update(ctx context.Context, user *domain.User) error {
+	start := s.clock.Now()
+	err := s.UserService.Update(ctx, user)
+	s.record(ctx, "update", start, err)
+	return err
+}
+
+// Delete deletes a user, counting it once deleted
+func (s *instrumentedUserService) Delete(ctx context.Context, id string) error {
+	start := s.clock.Now()
+	err := s.UserService.Delete(ctx, id)
+	s.record(ctx, "delete", start, err)
+	if err == nil {
+		s.deleted.Inc(ctx)
+	}
+	return err
+}
+
+// record records the duration of a write since start
+func (s *instrumentedUserService) record(ctx context.Context, operation string, start time.Time, err error) {
+	s.duration.Record(ctx, s.clock.Since(start).Seconds(),
+		attribute.String("operation", operation),
+		attribute.String("outcome", writeOutcome(err)),
+	)
+}
+
+// writeOutcome classifies the error of a write into a bounded set of outcomes
+func writeOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrInvalidUser):
+		return "invalid"
+	case errors.Is(err, ErrUserNotFound):
+		return "not_found"
+	case errors.Is(err, ErrEmailAlreadyExists), errors.Is(err, ErrVersionConflict):
+		return "conflict"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/metrics"
+)
+
+func newInstrumentedTestService() (UserService, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	registry := metrics.NewRegistry(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	clk := testutil.NewFakeClock(testTime)
+	return NewInstrumentedUserService(NewUserService(repository.NewMockUserRepository(), clk), registry, clk), reader
+}
+
+// collect returns the data points of the metrics read by reader, by metric name
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+
+	collected := make(map[string]metricdata.Aggregation)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			collected[m.Name] = m.Data
+		}
+	}
+	return collected
+}
+
+// histogramCount returns the number of values recorded by a histogram with the given attributes
+func histogramCount(data metricdata.Aggregation, attrs ...attribute.KeyValue) uint64 {
+	set := attribute.NewSet(attrs...)
+	for _, point := range data.(metricdata.Histogram[float64]).DataPoints {
+		if point.Attributes.Equals(&set) {
+			return point.Count
+		}
+	}
+	return 0
+}
+
+func TestInstrumentedUserService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Counts successful writes", func(t *testing.T) {
+		service, reader := newInstrumentedTestService()
+
+		user := &domain.User{Name: "Ada", Email: "ada@example.com"}
+		require.NoError(t, service.Create(ctx, user))
+		require.NoError(t, service.Update(ctx, user))
+		require.NoError(t, service.Delete(ctx, user.ID))
+
+		collected := collect(t, reader)
+		assert.Equal(t, int64(1), collected["users.created"].(metricdata.Sum[int64]).DataPoints[0].Value)
+		assert.Equal(t, int64(1), collected["users.deleted"].(metricdata.Sum[int64]).DataPoints[0].Value)
+		for _, operation := range []string{"create", "update", "delete"} {
+			assert.Equal(t, uint64(1), histogramCount(collected["users.write.duration"],
+				attribute.String("operation", operation), attribute.String("outcome", "ok")), operation)
+		}
+	})
+
+	t.Run("Times failed writes by outcome without counting them", func(t *testing.T) {
+		service, reader := newInstrumentedTestService()
+
+		assert.ErrorIs(t, service.Create(ctx, &domain.User{Name: "Ada", Email: "ada"}), ErrInvalidUser)
+		assert.ErrorIs(t, service.Delete(ctx, "missing"), ErrUserNotFound)
+		require.NoError(t, service.Create(ctx, &domain.User{Name: "Ada", Email: "ada@example.com"}))
+		assert.ErrorIs(t, service.Create(ctx, &domain.User{Name: "Ada", Email: "ada@example.com"}), ErrEmailAlreadyExists)
+
+		collected := collect(t, reader)
+		assert.Equal(t, int64(1), collected["users.created"].(metricdata.Sum[int64]).DataPoints[0].Value)
+		assert.NotContains(t, collected, "users.deleted")
+
+		duration := collected["users.write.duration"]
+		assert.Equal(t, uint64(1), histogramCount(duration, attribute.String("operation", "create"), attribute.String("outcome", "invalid")))
+		assert.Equal(t, uint64(1), histogramCount(duration, attribute.String("operation", "create"), attribute.String("outcome", "conflict")))
+		assert.Equal(t, uint64(1), histogramCount(duration, attribute.String("operation", "delete"), attribute.String("outcome", "not_found")))
+	})
+
+	t.Run("Declaring a metric again returns it", func(t *testing.T) {
+		registry := metrics.NewRegistry(sdkmetric.NewMeterProvider().Meter("test"))
+		assert.Same(t, registry.Counter("users.created", "Number of users created"), registry.Counter("users.created", ""))
+	})
+}
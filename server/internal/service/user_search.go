@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/search"
+)
+
+// UserIndex answers user searches from a search index; search.Users implements it
+type UserIndex interface {
+	Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error)
+}
+
+// indexedUserService decorates the searches of a UserService with answering them from an index
+type indexedUserService struct {
+	UserService
+	index UserIndex
+}
+
+// NewIndexedUserService decorates users so that searches are answered from index, falling back to
+// the searches of users while the index is disabled or failing; other calls are passed through
+// The index is updated asynchronously, so its results can lag behind the latest writes.
+func NewIndexedUserService(users UserService, index UserIndex) UserService {
+	return &indexedUserService{
+		UserService: users,
+		index:       index,
+	}
+}
+
+// Search retrieves a page of the users matching the filter from the index and the number of users
+// matching it
+func (s *indexedUserService) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	if err := validateSearch(filter, opts); err != nil {
+		return nil, 0, err
+	}
+
+	users, total, err := s.index.Search(ctx, filter, opts)
+	if err == nil {
+		return users, total, nil
+	}
+	if !errors.Is(err, search.ErrDisabled) {
+		logger.WarnCtx(ctx, "Failed to search the user index, falling back to MongoDB", zap.Error(err))
+	}
+	return s.UserService.Search(ctx, filter, opts)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/search"
+	"quizizz.com/internal/testutil"
+)
+
+// fakeUserIndex answers searches with users, failing with err when set
+type fakeUserIndex struct {
+	users    []*domain.User
+	err      error
+	searched int
+}
+
+func (i *fakeUserIndex) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	i.searched++
+	if i.err != nil {
+		return nil, 0, i.err
+	}
+	return i.users, int64(len(i.users)), nil
+}
+
+func newIndexedTestService(t *testing.T, index UserIndex) UserService {
+	t.Helper()
+	repo := repository.NewMockUserRepository()
+	require.NoError(t, repo.Create(context.Background(), &domain.User{ID: "stored", Name: "Ada", Email: "ada@example.com"}))
+	return NewIndexedUserService(NewUserService(repo, testutil.NewFakeClock(testTime)), index)
+}
+
+func TestIndexedUserService_Search(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Answers from the index", func(t *testing.T) {
+		index := &fakeUserIndex{users: []*domain.User{{ID: "indexed"}}}
+		users, total, err := newIndexedTestService(t, index).Search(ctx, domain.UserFilter{}, domain.ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "indexed", users[0].ID)
+	})
+
+	for name, err := range map[string]error{"Disabled": search.ErrDisabled, "Failing": errors.New("unavailable")} {
+		t.Run(name+" index falls back to MongoDB", func(t *testing.T) {
+			index := &fakeUserIndex{err: err}
+			users, total, err := newIndexedTestService(t, index).Search(ctx, domain.UserFilter{}, domain.ListOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			assert.Equal(t, "stored", users[0].ID)
+		})
+	}
+
+	t.Run("Validates before searching", func(t *testing.T) {
+		index := &fakeUserIndex{}
+		_, _, err := newIndexedTestService(t, index).Search(ctx, domain.UserFilter{}, domain.ListOptions{Sort: "password"})
+		assert.ErrorIs(t, err, ErrInvalidUser)
+		assert.Zero(t, index.searched)
+	})
+}
@@ -3,23 +3,53 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 
 	"go.uber.org/zap"
 	"quizizz.com/internal/domain"
 	"quizizz.com/internal/logger"
 	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
 )
 
 // Common errors
 var (
-	ErrUserNotFound = errors.New("user not found")
-	ErrInvalidUser  = errors.New("invalid user data")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrInvalidUser    = errors.New("invalid user data")
+	ErrExportTooLarge = errors.New("export exceeds the maximum number of rows")
+
+	// ErrVersionConflict is returned when a user was modified since the version the caller read
+	ErrVersionConflict = repository.ErrVersionConflict
+
+	// ErrEmailAlreadyExists is returned when creating or updating a user with another user's email
+	ErrEmailAlreadyExists error = &ConflictError{Field: "email"}
 )
 
+// ConflictError is returned when a write would duplicate a value that is unique across users
+type ConflictError struct {
+	// Field is the unique field, e.g. email
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("a user with this %s already exists", e.Field)
+}
+
+// Unwrap makes conflicts match repository.ErrAlreadyExists
+func (e *ConflictError) Unwrap() error {
+	return repository.ErrAlreadyExists
+}
+
+// MaxExportRows caps the number of users a single export may return
+const MaxExportRows = 100000
+
 // UserService defines the interface for user-related business logic
 type UserService interface {
-	GetByID(ctx context.Context, id string) (*domain.User, error)
-	List(ctx context.Context) ([]*domain.User, error)
+	GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error)
+	List(ctx context.Context, fields ...string) ([]*domain.User, error)
+	Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error)
+	Export(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error
 	Create(ctx context.Context, user *domain.User) error
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id string) error
@@ -28,24 +58,26 @@ type UserService interface {
 // userService implements the UserService interface
 type userService struct {
 	userRepo repository.UserRepository
+	clock    clock.Clock
 }
 
 // NewUserService creates a new UserService
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, clk clock.Clock) UserService {
 	return &userService{
 		userRepo: userRepo,
+		clock:    clk,
 	}
 }
 
-// GetByID retrieves a user by ID
-func (s *userService) GetByID(ctx context.Context, id string) (*domain.User, error) {
+// GetByID retrieves a user by ID, optionally restricted to the given fields
+func (s *userService) GetByID(ctx context.Context, id string, fields ...string) (*domain.User, error) {
 	logger.Debug("Getting user by ID", zap.String("userId", id))
 
 	if id == "" {
 		return nil, ErrInvalidUser
 	}
 
-	user, err := s.userRepo.GetByID(ctx, id)
+	user, err := s.userRepo.GetByID(ctx, id, fields...)
 	if err != nil {
 		logger.Error("Failed to get user", zap.String("userId", id), zap.Error(err))
 		return nil, err
@@ -58,11 +90,11 @@ func (s *userService) GetByID(ctx context.Context, id string) (*domain.User, err
 	return user, nil
 }
 
-// List retrieves all users
-func (s *userService) List(ctx context.Context) ([]*domain.User, error) {
+// List retrieves all users, optionally restricted to the given fields
+func (s *userService) List(ctx context.Context, fields ...string) ([]*domain.User, error) {
 	logger.Debug("Listing users")
 
-	users, err := s.userRepo.List(ctx)
+	users, err := s.userRepo.List(ctx, fields...)
 	if err != nil {
 		logger.Error("Failed to list users", zap.Error(err))
 		return nil, err
@@ -71,21 +103,96 @@ func (s *userService) List(ctx context.Context) ([]*domain.User, error) {
 	return users, nil
 }
 
+// Search retrieves a page of the users matching the filter and the number of users matching it
+func (s *userService) Search(ctx context.Context, filter domain.UserFilter, opts domain.ListOptions) ([]*domain.User, int64, error) {
+	logger.Debug("Searching users", zap.String("sort", opts.Sort), zap.Int("offset", opts.Offset), zap.Int("limit", opts.Limit))
+
+	if err := validateSearch(filter, opts); err != nil {
+		return nil, 0, err
+	}
+
+	users, total, err := s.userRepo.Search(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Failed to search users", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// validateSearch returns ErrInvalidUser for unknown sort fields and empty creation ranges
+func validateSearch(filter domain.UserFilter, opts domain.ListOptions) error {
+	if opts.Sort != "" && !slices.Contains(domain.UserSortFields, opts.Sort) {
+		return fmt.Errorf("%w: cannot sort by %s", ErrInvalidUser, opts.Sort)
+	}
+	if !filter.CreatedAfter.IsZero() && !filter.CreatedBefore.IsZero() && !filter.CreatedAfter.Before(filter.CreatedBefore) {
+		return fmt.Errorf("%w: createdAfter must be before createdBefore", ErrInvalidUser)
+	}
+	return nil
+}
+
+// Export streams every user matching the filter to fn
+// It returns ErrExportTooLarge without calling fn when more than MaxExportRows users match.
+func (s *userService) Export(ctx context.Context, filter domain.UserFilter, fn func(*domain.User) error) error {
+	logger.Debug("Exporting users")
+
+	count, err := s.userRepo.Count(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to count users for export", zap.Error(err))
+		return err
+	}
+
+	if count > MaxExportRows {
+		logger.Warn("User export too large", zap.Int64("count", count), zap.Int("max", MaxExportRows))
+		return ErrExportTooLarge
+	}
+
+	// Users created after the count are still capped so the response stays bounded
+	var exported int64
+	err = s.userRepo.Stream(ctx, filter, func(user *domain.User) error {
+		if exported >= MaxExportRows {
+			return ErrExportTooLarge
+		}
+		exported++
+		return fn(user)
+	})
+	if err != nil {
+		logger.Error("Failed to export users", zap.Int64("exported", exported), zap.Error(err))
+		return err
+	}
+
+	logger.Info("Users exported", zap.Int64("count", exported))
+	return nil
+}
+
 // Create creates a new user
 func (s *userService) Create(ctx context.Context, user *domain.User) error {
-	logger.Debug("Creating user", zap.String("userName", user.Name))
+	logger.Debug("Creating user", zap.Stringer("userName", user.Name))
 
-	if user.Name == "" || user.Email == "" {
-		return ErrInvalidUser
+	if err := user.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidUser, err)
+	}
+
+	// Assign the timestamps the caller left unset; the repository assigns IDs in its collection's format
+	now := s.clock.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
 	}
 
 	err := s.userRepo.Create(ctx, user)
+	if errors.Is(err, repository.ErrUserExists) {
+		logger.Warn("User email already exists", zap.Stringer("userName", user.Name))
+		return ErrEmailAlreadyExists
+	}
 	if err != nil {
 		logger.Error("Failed to create user", zap.Error(err))
 		return err
 	}
 
-	logger.Info("User created", zap.String("userId", user.ID), zap.String("userName", user.Name))
+	logger.Info("User created", zap.String("userId", user.ID), zap.Stringer("userName", user.Name))
 	return nil
 }
 
@@ -96,6 +203,9 @@ func (s *userService) Update(ctx context.Context, user *domain.User) error {
 	if user.ID == "" {
 		return ErrInvalidUser
 	}
+	if err := user.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidUser, err)
+	}
 
 	// Check if user exists
 	existingUser, err := s.userRepo.GetByID(ctx, user.ID)
@@ -109,6 +219,10 @@ func (s *userService) Update(ctx context.Context, user *domain.User) error {
 	}
 
 	err = s.userRepo.Update(ctx, user)
+	if errors.Is(err, repository.ErrUserExists) {
+		logger.Warn("User email already exists", zap.String("userId", user.ID))
+		return ErrEmailAlreadyExists
+	}
 	if err != nil {
 		logger.Error("Failed to update user", zap.String("userId", user.ID), zap.Error(err))
 		return err
@@ -8,6 +8,7 @@ import (
 
 	"quizizz.com/internal/domain"
 	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
 )
 
 // Benchmark tests for UserService
@@ -19,7 +20,7 @@ func BenchmarkUserService_GetByID(b *testing.B) {
 	// Setup
 	ctx := context.Background()
 	repo := repository.NewMockUserRepository()
-	service := NewUserService(repo)
+	service := NewUserService(repo, clock.New())
 
 	// Create test user
 	user := &domain.User{
@@ -51,14 +52,14 @@ func BenchmarkUserService_List(b *testing.B) {
 	// Setup
 	ctx := context.Background()
 	repo := repository.NewMockUserRepository()
-	service := NewUserService(repo)
+	service := NewUserService(repo, clock.New())
 
 	// Create test users
 	for i := 0; i < 100; i++ {
 		user := &domain.User{
 			ID:        "bench-user-" + strconv.Itoa(i),
-			Name:      "Benchmark User " + strconv.Itoa(i),
-			Email:     "bench" + strconv.Itoa(i) + "@example.com",
+			Name:      domain.UserName("Benchmark User " + strconv.Itoa(i)),
+			Email:     domain.Email("bench" + strconv.Itoa(i) + "@example.com"),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
@@ -85,7 +86,7 @@ func BenchmarkUserService_Create(b *testing.B) {
 	// Setup
 	ctx := context.Background()
 	repo := repository.NewMockUserRepository()
-	service := NewUserService(repo)
+	service := NewUserService(repo, clock.New())
 
 	// Run benchmark
 	b.ResetTimer()
@@ -93,7 +94,7 @@ func BenchmarkUserService_Create(b *testing.B) {
 		user := &domain.User{
 			ID:        "bench-create-" + strconv.Itoa(i),
 			Name:      "Benchmark Create User",
-			Email:     "benchcreate" + strconv.Itoa(i) + "@example.com",
+			Email:     domain.Email("benchcreate" + strconv.Itoa(i) + "@example.com"),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
@@ -112,7 +113,7 @@ func BenchmarkUserService_Update(b *testing.B) {
 	// Setup
 	ctx := context.Background()
 	repo := repository.NewMockUserRepository()
-	service := NewUserService(repo)
+	service := NewUserService(repo, clock.New())
 
 	// Create test user
 	user := &domain.User{
@@ -133,8 +134,8 @@ func BenchmarkUserService_Update(b *testing.B) {
 		// Create a new user with updated fields
 		updatedUser := &domain.User{
 			ID:        "bench-update",
-			Name:      "Updated Name " + strconv.Itoa(i),
-			Email:     "updated" + strconv.Itoa(i) + "@example.com",
+			Name:      domain.UserName("Updated Name " + strconv.Itoa(i)),
+			Email:     domain.Email("updated" + strconv.Itoa(i) + "@example.com"),
 			CreatedAt: user.CreatedAt,
 			UpdatedAt: time.Now(),
 		}
@@ -154,7 +155,7 @@ func BenchmarkUserService_Delete(b *testing.B) {
 		// This is a simpler benchmark that recreates and deletes a single user repeatedly
 		ctx := context.Background()
 		repo := repository.NewMockUserRepository()
-		service := NewUserService(repo)
+		service := NewUserService(repo, clock.New())
 
 		// Run benchmark
 		b.ResetTimer()
@@ -188,15 +189,15 @@ func BenchmarkUserService_Delete(b *testing.B) {
 		// Setup
 		ctx := context.Background()
 		repo := repository.NewMockUserRepository()
-		service := NewUserService(repo)
+		service := NewUserService(repo, clock.New())
 
 		// Create many users before starting the benchmark
 		for i := 0; i < b.N; i++ {
 			userId := "bench-delete-multi-" + strconv.Itoa(i)
 			user := &domain.User{
 				ID:        userId,
-				Name:      "Benchmark Delete User " + strconv.Itoa(i),
-				Email:     "benchdelete" + strconv.Itoa(i) + "@example.com",
+				Name:      domain.UserName("Benchmark Delete User " + strconv.Itoa(i)),
+				Email:     domain.Email("benchdelete" + strconv.Itoa(i) + "@example.com"),
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			}
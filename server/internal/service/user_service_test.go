@@ -8,48 +8,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"quizizz.com/internal/domain"
+	"quizizz.com/internal/mocks"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
 )
 
-// MockUserRepo is a mock implementation of the UserRepository for testing
-type MockUserRepo struct {
-	mock.Mock
-}
-
-func (m *MockUserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	args := m.Called(ctx, id)
-
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-
-	return args.Get(0).(*domain.User), args.Error(1)
-}
-
-func (m *MockUserRepo) List(ctx context.Context) ([]*domain.User, error) {
-	args := m.Called(ctx)
-
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-
-	return args.Get(0).([]*domain.User), args.Error(1)
-}
-
-func (m *MockUserRepo) Create(ctx context.Context, user *domain.User) error {
-	args := m.Called(ctx, user)
-	return args.Error(0)
-}
-
-func (m *MockUserRepo) Update(ctx context.Context, user *domain.User) error {
-	args := m.Called(ctx, user)
-	return args.Error(0)
-}
-
-func (m *MockUserRepo) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
+// testTime is the time of the fake clock the services under test are created with
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
 
 func TestUserService_GetByID(t *testing.T) {
 	// Create test context
@@ -57,7 +24,7 @@ func TestUserService_GetByID(t *testing.T) {
 
 	t.Run("Valid user", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Test User",
@@ -70,7 +37,7 @@ func TestUserService_GetByID(t *testing.T) {
 		mockRepo.On("GetByID", ctx, "test-id").Return(user, nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.GetByID(ctx, "test-id")
@@ -83,10 +50,10 @@ func TestUserService_GetByID(t *testing.T) {
 
 	t.Run("Empty ID", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.GetByID(ctx, "")
@@ -100,13 +67,13 @@ func TestUserService_GetByID(t *testing.T) {
 
 	t.Run("User not found", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 
 		// Set expectations
 		mockRepo.On("GetByID", ctx, "non-existent").Return(nil, nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.GetByID(ctx, "non-existent")
@@ -118,16 +85,41 @@ func TestUserService_GetByID(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Email already exists", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		user := &domain.User{
+			Name:  "Test User",
+			Email: "taken@example.com",
+		}
+
+		// The unique email index rejects the insert
+		mockRepo.On("Create", ctx, user).Return(repository.ErrUserExists)
+
+		// Create service with mock
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		err := service.Create(ctx, user)
+
+		// Assertions
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+		var conflict *ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "email", conflict.Field)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Repository error", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		repoErr := errors.New("repository error")
 
 		// Set expectations
 		mockRepo.On("GetByID", ctx, "test-id").Return(nil, repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.GetByID(ctx, "test-id")
@@ -146,7 +138,7 @@ func TestUserService_List(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		users := []*domain.User{
 			{
 				ID:        "test-id-1",
@@ -168,7 +160,7 @@ func TestUserService_List(t *testing.T) {
 		mockRepo.On("List", ctx).Return(users, nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.List(ctx)
@@ -181,14 +173,14 @@ func TestUserService_List(t *testing.T) {
 
 	t.Run("Empty list", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		users := []*domain.User{}
 
 		// Set expectations
 		mockRepo.On("List", ctx).Return(users, nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.List(ctx)
@@ -201,14 +193,14 @@ func TestUserService_List(t *testing.T) {
 
 	t.Run("Repository error", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		repoErr := errors.New("repository error")
 
 		// Set expectations
 		mockRepo.On("List", ctx).Return(nil, repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		result, err := service.List(ctx)
@@ -221,13 +213,132 @@ func TestUserService_List(t *testing.T) {
 	})
 }
 
+func TestUserService_Search(t *testing.T) {
+	// Create test context
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		filter := domain.UserFilter{Query: "ada"}
+		opts := domain.ListOptions{Sort: domain.UserSortName, Limit: 10}
+		users := []*domain.User{{ID: "user-1", Name: "Ada"}}
+
+		// Set expectations
+		mockRepo.On("Search", ctx, filter, opts).Return(users, int64(1), nil)
+
+		// Create service with mock
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		result, total, err := service.Search(ctx, filter, opts)
+
+		// Assertions
+		require.NoError(t, err)
+		assert.Equal(t, users, result)
+		assert.Equal(t, int64(1), total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown sort field", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		_, _, err := service.Search(ctx, domain.UserFilter{}, domain.ListOptions{Sort: "password"})
+
+		// Assertions
+		assert.ErrorIs(t, err, ErrInvalidUser)
+		mockRepo.AssertNotCalled(t, "Search")
+	})
+
+	t.Run("Empty time range", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		filter := domain.UserFilter{CreatedAfter: testTime, CreatedBefore: testTime}
+		_, _, err := service.Search(ctx, filter, domain.ListOptions{})
+
+		// Assertions
+		assert.ErrorIs(t, err, ErrInvalidUser)
+		mockRepo.AssertNotCalled(t, "Search")
+	})
+}
+
+func TestUserService_Export(t *testing.T) {
+	// Create test context
+	ctx := context.Background()
+	filter := domain.UserFilter{Name: "test"}
+
+	t.Run("Success", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		users := []*domain.User{
+			{ID: "test-id-1", Name: "Test User 1", Email: "test1@example.com"},
+			{ID: "test-id-2", Name: "Test User 2", Email: "test2@example.com"},
+		}
+
+		// Set expectations
+		mockRepo.On("Count", ctx, filter).Return(int64(len(users)), nil)
+		mockRepo.EXPECT().Stream(ctx, filter, mock.Anything).
+			RunAndReturn(func(_ context.Context, _ domain.UserFilter, fn func(*domain.User) error) error {
+				for _, user := range users {
+					if err := fn(user); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+		// Create service with mock
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		var exported []*domain.User
+		err := service.Export(ctx, filter, func(user *domain.User) error {
+			exported = append(exported, user)
+			return nil
+		})
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, users, exported)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Too many rows", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+
+		// Set expectations
+		mockRepo.On("Count", ctx, filter).Return(int64(MaxExportRows+1), nil)
+
+		// Create service with mock
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		err := service.Export(ctx, filter, func(user *domain.User) error {
+			t.Fatal("fn must not be called")
+			return nil
+		})
+
+		// Assertions
+		assert.Equal(t, ErrExportTooLarge, err)
+		mockRepo.AssertNotCalled(t, "Stream", ctx, filter)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestUserService_Create(t *testing.T) {
 	// Create test context
 	ctx := context.Background()
 
 	t.Run("Success", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Test User",
@@ -240,7 +351,7 @@ func TestUserService_Create(t *testing.T) {
 		mockRepo.On("Create", ctx, user).Return(nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Create(ctx, user)
@@ -252,7 +363,7 @@ func TestUserService_Create(t *testing.T) {
 
 	t.Run("Missing name", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Email:     "test@example.com",
@@ -261,20 +372,21 @@ func TestUserService_Create(t *testing.T) {
 		}
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Create(ctx, user)
 
 		// Assertions
 		assert.Error(t, err)
-		assert.Equal(t, ErrInvalidUser, err)
+		assert.ErrorIs(t, err, ErrInvalidUser)
+		assert.ErrorIs(t, err, domain.ErrInvalidUserName)
 		mockRepo.AssertNotCalled(t, "Create")
 	})
 
 	t.Run("Missing email", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Test User",
@@ -283,20 +395,21 @@ func TestUserService_Create(t *testing.T) {
 		}
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Create(ctx, user)
 
 		// Assertions
 		assert.Error(t, err)
-		assert.Equal(t, ErrInvalidUser, err)
+		assert.ErrorIs(t, err, ErrInvalidUser)
+		assert.ErrorIs(t, err, domain.ErrInvalidEmail)
 		mockRepo.AssertNotCalled(t, "Create")
 	})
 
 	t.Run("Repository error", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Test User",
@@ -310,7 +423,7 @@ func TestUserService_Create(t *testing.T) {
 		mockRepo.On("Create", ctx, user).Return(repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Create(ctx, user)
@@ -320,6 +433,31 @@ func TestUserService_Create(t *testing.T) {
 		assert.Equal(t, repoErr, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Assigns timestamps from the clock", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		user := &domain.User{
+			Name:  "Test User",
+			Email: "test@example.com",
+		}
+
+		// Set expectations
+		mockRepo.On("Create", ctx, user).Return(nil)
+
+		// Create service with a fake clock
+		clk := testutil.NewFakeClock(testTime)
+		service := NewUserService(mockRepo, clk)
+
+		// Call service
+		err := service.Create(ctx, user)
+
+		// Assertions
+		assert.NoError(t, err)
+		assert.Equal(t, testTime, user.CreatedAt)
+		assert.Equal(t, testTime, user.UpdatedAt)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestUserService_Update(t *testing.T) {
@@ -328,7 +466,7 @@ func TestUserService_Update(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Updated User",
@@ -342,7 +480,7 @@ func TestUserService_Update(t *testing.T) {
 		mockRepo.On("Update", ctx, user).Return(nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Update(ctx, user)
@@ -352,9 +490,33 @@ func TestUserService_Update(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Email already exists", func(t *testing.T) {
+		// Setup mock
+		mockRepo := new(mocks.UserRepository)
+		user := &domain.User{
+			ID:    "test-id",
+			Name:  "Updated User",
+			Email: "taken@example.com",
+		}
+
+		// Set expectations
+		mockRepo.On("GetByID", ctx, "test-id").Return(user, nil)
+		mockRepo.On("Update", ctx, user).Return(repository.ErrUserExists)
+
+		// Create service with mock
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
+
+		// Call service
+		err := service.Update(ctx, user)
+
+		// Assertions
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Empty ID", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			Name:      "Updated User",
 			Email:     "updated@example.com",
@@ -363,7 +525,7 @@ func TestUserService_Update(t *testing.T) {
 		}
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Update(ctx, user)
@@ -377,7 +539,7 @@ func TestUserService_Update(t *testing.T) {
 
 	t.Run("User not found", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Updated User",
@@ -390,7 +552,7 @@ func TestUserService_Update(t *testing.T) {
 		mockRepo.On("GetByID", ctx, "test-id").Return(nil, nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Update(ctx, user)
@@ -404,7 +566,7 @@ func TestUserService_Update(t *testing.T) {
 
 	t.Run("Repository error during get", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Updated User",
@@ -418,7 +580,7 @@ func TestUserService_Update(t *testing.T) {
 		mockRepo.On("GetByID", ctx, "test-id").Return(nil, repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Update(ctx, user)
@@ -432,7 +594,7 @@ func TestUserService_Update(t *testing.T) {
 
 	t.Run("Repository error during update", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Updated User",
@@ -447,7 +609,7 @@ func TestUserService_Update(t *testing.T) {
 		mockRepo.On("Update", ctx, user).Return(repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Update(ctx, user)
@@ -465,7 +627,7 @@ func TestUserService_Delete(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Test User",
@@ -479,7 +641,7 @@ func TestUserService_Delete(t *testing.T) {
 		mockRepo.On("Delete", ctx, "test-id").Return(nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Delete(ctx, "test-id")
@@ -491,10 +653,10 @@ func TestUserService_Delete(t *testing.T) {
 
 	t.Run("Empty ID", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Delete(ctx, "")
@@ -508,13 +670,13 @@ func TestUserService_Delete(t *testing.T) {
 
 	t.Run("User not found", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 
 		// Set expectations
 		mockRepo.On("GetByID", ctx, "test-id").Return(nil, nil)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Delete(ctx, "test-id")
@@ -528,14 +690,14 @@ func TestUserService_Delete(t *testing.T) {
 
 	t.Run("Repository error during get", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		repoErr := errors.New("repository error")
 
 		// Set expectations
 		mockRepo.On("GetByID", ctx, "test-id").Return(nil, repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Delete(ctx, "test-id")
@@ -549,7 +711,7 @@ func TestUserService_Delete(t *testing.T) {
 
 	t.Run("Repository error during delete", func(t *testing.T) {
 		// Setup mock
-		mockRepo := new(MockUserRepo)
+		mockRepo := new(mocks.UserRepository)
 		user := &domain.User{
 			ID:        "test-id",
 			Name:      "Test User",
@@ -564,7 +726,7 @@ func TestUserService_Delete(t *testing.T) {
 		mockRepo.On("Delete", ctx, "test-id").Return(repoErr)
 
 		// Create service with mock
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testutil.NewFakeClock(testTime))
 
 		// Call service
 		err := service.Delete(ctx, "test-id")
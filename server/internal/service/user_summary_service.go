@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+)
+
+// UserSummarySortFields lists the fields user summaries can be sorted by
+var UserSummarySortFields = []string{
+	domain.UserSortName, domain.UserSortEmail, domain.UserSortCreatedAt, "sessions", "devices", "last_seen_at",
+}
+
+// UserSummaryService answers reads of users with their related counts from the user summary read
+// model, so it never aggregates the users, sessions and devices collections per request
+// Summaries lag writes until the view maintainer refreshed them.
+type UserSummaryService interface {
+	// Get returns the summary of a user, or ErrUserNotFound
+	Get(ctx context.Context, userID string) (*repository.UserSummary, error)
+
+	// List returns a page of summaries sorted by one of UserSummarySortFields, and their total count
+	List(ctx context.Context, opts domain.ListOptions) ([]*repository.UserSummary, int64, error)
+}
+
+// userSummaryService implements the UserSummaryService interface
+type userSummaryService struct {
+	repo repository.UserSummaryRepository
+}
+
+// NewUserSummaryService creates a new UserSummaryService reading the summaries of repo
+func NewUserSummaryService(repo repository.UserSummaryRepository) UserSummaryService {
+	return &userSummaryService{repo: repo}
+}
+
+// Get returns the summary of a user, computing it when the read model has not caught up with a new
+// user yet
+func (s *userSummaryService) Get(ctx context.Context, userID string) (*repository.UserSummary, error) {
+	if userID == "" {
+		return nil, ErrInvalidUser
+	}
+
+	summary, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		if err := s.repo.Refresh(ctx, userID); err != nil {
+			return nil, err
+		}
+		summary, err = s.repo.Get(ctx, userID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return summary, err
+}
+
+// List returns a page of summaries
+func (s *userSummaryService) List(ctx context.Context, opts domain.ListOptions) ([]*repository.UserSummary, int64, error) {
+	return s.repo.List(ctx, opts)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+)
+
+func newUserSummaryTestService(t *testing.T) (UserSummaryService, repository.UserRepository, *repository.MockUserSummaryRepository) {
+	clk := testutil.NewFakeClock(testTime)
+	users := repository.NewMockUserRepository()
+	sessions := repository.NewMockSessionRepository(clk)
+	require.NoError(t, users.Create(context.Background(), &domain.User{ID: "user-1", Name: "Ada", Email: "ada@example.com", CreatedAt: testTime}))
+	require.NoError(t, sessions.Create(context.Background(), &repository.Session{UserID: "user-1", LastUsedAt: testTime, ExpiresAt: testTime.Add(time.Hour)}))
+
+	summaries := repository.NewMockUserSummaryRepository(users, sessions, repository.NewMockDeviceRepository(clk), clk)
+	return NewUserSummaryService(summaries), users, summaries
+}
+
+func TestUserSummaryService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Computes a summary the view has not caught up with", func(t *testing.T) {
+		service, _, _ := newUserSummaryTestService(t)
+
+		summary, err := service.Get(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", summary.Name)
+		assert.Equal(t, int64(1), summary.Sessions)
+		assert.Equal(t, testTime, summary.LastSeenAt)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		service, _, _ := newUserSummaryTestService(t)
+
+		_, err := service.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("Missing ID", func(t *testing.T) {
+		service, _, _ := newUserSummaryTestService(t)
+
+		_, err := service.Get(ctx, "")
+		assert.ErrorIs(t, err, ErrInvalidUser)
+	})
+}
+
+func TestUserSummaryService_List(t *testing.T) {
+	ctx := context.Background()
+	service, users, summaries := newUserSummaryTestService(t)
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "user-2", Name: "Grace", Email: "grace@example.com", CreatedAt: testTime.Add(time.Minute)}))
+	_, err := summaries.Rebuild(ctx)
+	require.NoError(t, err)
+
+	page, total, err := service.List(ctx, domain.ListOptions{Sort: "sessions", Descending: true, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, page, 1)
+	assert.Equal(t, "user-1", page[0].UserID)
+
+	page, _, err = service.List(ctx, domain.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "user-2", page[0].UserID, "newest users come first by default")
+}
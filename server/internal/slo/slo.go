@@ -0,0 +1,185 @@
+// Package slo lets routes declare service level objectives and exports the metrics to alert on them
+//
+// Every tracked request is counted as good or bad against each objective of its route
+// (slo.requests), the objectives' targets are exported (slo.target), and burn rates over the
+// standard multi-window alerting windows are computed in process (slo.burn_rate). A burn rate of 1
+// spends the error budget exactly over the SLO period; multi-window alerts typically page on a
+// 14.4x burn over both 1h and 5m, and ticket on a 6x burn over both 6h and 30m.
+package slo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Objective declares the latency and availability objectives of a group of routes
+type Objective struct {
+	// Name identifies the objective in metrics, e.g. "users_read"
+	Name string
+
+	// AvailabilityTarget is the share of requests that must not fail with a 5xx (e.g. 0.999); 0 disables it
+	AvailabilityTarget float64
+
+	// Latency is the duration within which a request counts as fast
+	Latency time.Duration
+
+	// LatencyTarget is the share of requests that must be faster than Latency (e.g. 0.99); 0 disables it
+	LatencyTarget float64
+}
+
+// Objective kinds, used as the "kind" metric attribute
+const (
+	KindAvailability = "availability"
+	KindLatency      = "latency"
+)
+
+// Windows are the burn-rate windows reported as slo.burn_rate
+var Windows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+// tracker counts the good and bad requests of one objective
+type tracker struct {
+	objective    Objective
+	availability *window
+	latency      *window
+}
+
+// registry holds the trackers by objective name, so routes registered in several API versions share them
+var registry = struct {
+	sync.Mutex
+	trackers map[string]*tracker
+}{trackers: make(map[string]*tracker)}
+
+// instruments are created once and shared by all objectives
+var (
+	instrumentsOnce sync.Once
+	requests        metric.Int64Counter
+)
+
+// Track returns a middleware counting requests against objective
+// Objectives with the same name share their counters.
+func Track(objective Objective) gin.HandlerFunc {
+	t := trackerFor(objective)
+	attrs := func(kind, result string) metric.MeasurementOption {
+		return metric.WithAttributes(
+			attribute.String("slo", objective.Name),
+			attribute.String("kind", kind),
+			attribute.String("result", result),
+		)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		duration := time.Since(start)
+
+		ctx := c.Request.Context()
+		failed := c.Writer.Status() >= http.StatusInternalServerError
+
+		if objective.AvailabilityTarget > 0 {
+			t.availability.record(!failed)
+			if requests != nil {
+				requests.Add(ctx, 1, attrs(KindAvailability, result(!failed)))
+			}
+		}
+
+		// Failed requests only count against availability, so one outage is not paid twice
+		if objective.LatencyTarget > 0 && !failed {
+			fast := duration <= objective.Latency
+			t.latency.record(fast)
+			if requests != nil {
+				requests.Add(ctx, 1, attrs(KindLatency, result(fast)))
+			}
+		}
+	}
+}
+
+// result names the outcome of a request
+func result(good bool) string {
+	if good {
+		return "good"
+	}
+	return "bad"
+}
+
+// trackerFor returns the tracker of objective, creating it on first use
+func trackerFor(objective Objective) *tracker {
+	instrumentsOnce.Do(initInstruments)
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if t, ok := registry.trackers[objective.Name]; ok {
+		return t
+	}
+	t := &tracker{
+		objective:    objective,
+		availability: newWindow(),
+		latency:      newWindow(),
+	}
+	registry.trackers[objective.Name] = t
+	return t
+}
+
+// initInstruments creates the request counter and the target and burn-rate gauges
+func initInstruments() {
+	meter := otel.Meter("slo")
+
+	var err error
+	requests, err = meter.Int64Counter("slo.requests",
+		metric.WithDescription("Number of requests counted against SLOs by objective, kind and result"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create SLO request counter", zap.Error(err))
+	}
+
+	target, err := meter.Float64ObservableGauge("slo.target",
+		metric.WithDescription("Target share of good requests by objective and kind"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create SLO target gauge", zap.Error(err))
+		return
+	}
+
+	burnRate, err := meter.Float64ObservableGauge("slo.burn_rate",
+		metric.WithDescription("Error budget burn rate by objective, kind and window"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create SLO burn rate gauge", zap.Error(err))
+		return
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		registry.Lock()
+		defer registry.Unlock()
+
+		now := time.Now()
+		for _, t := range registry.trackers {
+			observe := func(kind string, goal float64, w *window) {
+				if goal <= 0 {
+					return
+				}
+				base := []attribute.KeyValue{attribute.String("slo", t.objective.Name), attribute.String("kind", kind)}
+				o.ObserveFloat64(target, goal, metric.WithAttributes(base...))
+				for _, size := range Windows {
+					attrs := append(base[:2:2], attribute.String("window", size.String()))
+					o.ObserveFloat64(burnRate, w.burnRate(now, size, goal), metric.WithAttributes(attrs...))
+				}
+			}
+			observe(KindAvailability, t.objective.AvailabilityTarget, t.availability)
+			observe(KindLatency, t.objective.LatencyTarget, t.latency)
+		}
+		return nil
+	}, target, burnRate)
+	if err != nil {
+		logger.Warn("Failed to register SLO gauge callback", zap.Error(err))
+	}
+}
@@ -0,0 +1,68 @@
+package slo
+
+import (
+	"sync"
+	"time"
+)
+
+// bucketSize is the resolution of burn-rate windows
+const bucketSize = time.Minute
+
+// bucket counts the requests of one minute
+type bucket struct {
+	minute int64
+	good   int64
+	bad    int64
+}
+
+// window keeps per-minute good and bad counts over the longest burn-rate window
+type window struct {
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+func newWindow() *window {
+	longest := Windows[len(Windows)-1]
+	return &window{buckets: make([]bucket, int(longest/bucketSize))}
+}
+
+// record counts a request in the current minute
+func (w *window) record(good bool) {
+	minute := time.Now().Unix() / int64(bucketSize.Seconds())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := &w.buckets[minute%int64(len(w.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	if good {
+		b.good++
+	} else {
+		b.bad++
+	}
+}
+
+// burnRate returns the rate at which the error budget of target was spent over the last size
+// 1 means the budget lasts exactly the SLO period; 0 is returned when there was no traffic.
+func (w *window) burnRate(now time.Time, size time.Duration, target float64) float64 {
+	current := now.Unix() / int64(bucketSize.Seconds())
+	oldest := current - int64(size/bucketSize) + 1
+
+	w.mu.Lock()
+	var good, bad int64
+	for _, b := range w.buckets {
+		if b.minute >= oldest && b.minute <= current {
+			good += b.good
+			bad += b.bad
+		}
+	}
+	w.mu.Unlock()
+
+	total := good + bad
+	if total == 0 || target >= 1 {
+		return 0
+	}
+	return (float64(bad) / float64(total)) / (1 - target)
+}
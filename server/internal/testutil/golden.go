@@ -0,0 +1,125 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites golden files with the current output instead of comparing against them:
+//
+//	go test ./internal/api/handlers/user/... -update
+var update = flag.Bool("update", false, "update golden files")
+
+// VolatileFields are JSON keys whose values differ between runs and are replaced in snapshots
+var VolatileFields = []string{
+	"id", "_id", "request_id", "requestId", "trace_id", "traceId",
+	"created_at", "createdAt", "updated_at", "updatedAt",
+}
+
+// Placeholders for volatile values found by pattern rather than by key
+var volatilePatterns = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`^[0-9a-f]{24}$`), "<object-id>"},
+	{regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`), "<uuid>"},
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`), "<timestamp>"},
+}
+
+// Golden compares got with the golden file testdata/<name>.golden of the test's package
+// Run the tests with -update to create or rewrite the file instead.
+func Golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755), "Failed to create golden directory")
+		require.NoError(t, os.WriteFile(path, got, 0o644), "Failed to write golden file: %s", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "Failed to read golden file %s (run with -update to create it)", path)
+	assert.Equal(t, string(want), string(got), "Output differs from golden file %s (run with -update to accept it)", path)
+}
+
+// AssertResponseSnapshot compares a JSON response with the golden file testdata/<name>.golden
+// The snapshot holds the status code and the indented body with volatile fields normalized: values of
+// VolatileFields and ignoreFields keys become "<key>", and ObjectIDs, UUIDs and timestamps elsewhere
+// become placeholders.
+func AssertResponseSnapshot(t *testing.T, w *httptest.ResponseRecorder, name string, ignoreFields ...string) {
+	t.Helper()
+
+	body, err := NormalizeJSON(w.Body.Bytes(), ignoreFields...)
+	require.NoError(t, err, "Failed to normalize response body")
+
+	var snapshot bytes.Buffer
+	encoder := json.NewEncoder(&snapshot)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(map[string]interface{}{
+		"status": w.Code,
+		"body":   json.RawMessage(body),
+	})
+	require.NoError(t, err, "Failed to marshal response snapshot")
+
+	Golden(t, name, snapshot.Bytes())
+}
+
+// NormalizeJSON replaces volatile values of a JSON document so it can be compared across runs
+// Keys are sorted, so the result is stable for equal documents.
+func NormalizeJSON(data []byte, ignoreFields ...string) ([]byte, error) {
+	fields := make(map[string]bool, len(VolatileFields)+len(ignoreFields))
+	for _, field := range append(VolatileFields, ignoreFields...) {
+		fields[field] = true
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var normalized bytes.Buffer
+	encoder := json.NewEncoder(&normalized)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(normalizeValue(document, fields)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(normalized.Bytes(), []byte("\n")), nil
+}
+
+// normalizeValue replaces volatile values in place at any depth
+func normalizeValue(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if fields[key] && field != nil {
+				v[key] = "<" + key + ">"
+			} else {
+				v[key] = normalizeValue(field, fields)
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = normalizeValue(elem, fields)
+		}
+	case string:
+		for _, volatile := range volatilePatterns {
+			if volatile.pattern.MatchString(v) {
+				return volatile.placeholder
+			}
+		}
+	}
+	return value
+}
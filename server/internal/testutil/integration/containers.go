@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+	"quizizz.com/internal/config"
+)
+
+// Container images used by the containers backend
+const (
+	mongoImage = "mongo:7"
+	redisImage = "redis:7-alpine"
+)
+
+// Names of the reused containers; every test package of a run shares them
+const (
+	mongoContainerName = "stride-integration-mongo"
+	redisContainerName = "stride-integration-redis"
+)
+
+// containerStartTimeout bounds pulling and starting both containers
+const containerStartTimeout = 2 * time.Minute
+
+// backends holds the addresses of the shared MongoDB and Redis containers
+type backends struct {
+	mongoURI  string
+	redisHost string
+	redisPort string
+}
+
+var (
+	backendsOnce sync.Once
+	shared       *backends
+	backendsErr  error
+)
+
+// startBackends starts (or reuses) the MongoDB and Redis containers once per test process
+// Containers are reused across packages and removed by the testcontainers reaper (Ryuk) once
+// the last test process using them exits.
+func startBackends(t *testing.T) *backends {
+	t.Helper()
+
+	backendsOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), containerStartTimeout)
+		defer cancel()
+		shared, backendsErr = runBackends(ctx)
+	})
+	require.NoError(t, backendsErr, "Failed to start integration containers (is Docker running?)")
+
+	return shared
+}
+
+// UseContainers points cfg at the shared MongoDB and Redis containers, starting them if needed,
+// with a MongoDB database unique to t
+// Use it to run components other than the Setup router (e.g. the full app) against real backends.
+func UseContainers(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	startBackends(t).apply(cfg, testDatabase(t))
+}
+
+// runBackends starts the containers and resolves their addresses
+func runBackends(ctx context.Context) (*backends, error) {
+	mongo, err := mongodb.Run(ctx, mongoImage, reuse(mongoContainerName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start MongoDB container: %w", err)
+	}
+	mongoURI, err := mongo.ConnectionString(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MongoDB connection string: %w", err)
+	}
+
+	redis, err := tcredis.Run(ctx, redisImage, reuse(redisContainerName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start Redis container: %w", err)
+	}
+	redisHost, err := redis.Host(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Redis host: %w", err)
+	}
+	redisPort, err := redis.MappedPort(ctx, "6379/tcp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Redis port: %w", err)
+	}
+
+	return &backends{
+		mongoURI:  mongoURI,
+		redisHost: redisHost,
+		redisPort: redisPort.Port(),
+	}, nil
+}
+
+// reuse names a container so later test processes attach to it instead of starting another
+func reuse(name string) testcontainers.CustomizeRequestOption {
+	return testcontainers.CustomizeRequest(testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{Name: name},
+		Reuse:            true,
+	})
+}
+
+// apply points cfg at the containers, using database for this test's MongoDB data
+func (b *backends) apply(cfg *config.Config, database string) {
+	cfg.MongoDB.URI = b.mongoURI
+	cfg.MongoDB.Database = database
+	cfg.Redis.Host = b.redisHost
+	cfg.Redis.Port = b.redisPort
+}
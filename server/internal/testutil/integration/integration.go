@@ -3,21 +3,50 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	"quizizz.com/internal/api"
+	"quizizz.com/internal/api/handlers"
+	"quizizz.com/internal/api/handlers/admin"
+	"quizizz.com/internal/api/handlers/csrf"
+	"quizizz.com/internal/api/handlers/lockout"
+	"quizizz.com/internal/api/handlers/ping"
+	"quizizz.com/internal/api/handlers/uploads"
+	"quizizz.com/internal/auth/impersonation"
+	"quizizz.com/internal/auth/rbac"
+	"quizizz.com/internal/auth/throttle"
 	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/gdpr"
+	"quizizz.com/internal/jobs"
 	"quizizz.com/internal/logger"
+	"quizizz.com/internal/modules"
 	"quizizz.com/internal/repository"
 	"quizizz.com/internal/resources"
 	"quizizz.com/internal/service"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
 	"quizizz.com/pkg/middleware"
 )
 
+// BackendEnv selects the backends of Setup: "mock" (default) or "containers", which runs real
+// MongoDB and Redis in Docker via testcontainers
+const BackendEnv = "INTEGRATION_BACKEND"
+
+// Backends selectable with BackendEnv
+const (
+	BackendMock       = "mock"
+	BackendContainers = "containers"
+)
+
 // TestEnv holds the test environment for integration tests
 type TestEnv struct {
 	Router      *gin.Engine
@@ -26,10 +55,31 @@ type TestEnv struct {
 	AppService  service.AppService
 	UserService service.UserService
 	UserRepo    repository.UserRepository
-	Cleanup     func()
+	GDPRService service.GDPRService
+	Sessions    service.SessionService
+	Clock       clock.Clock
+	IDs         idgen.Generator
+
+	// Jobs is the in-memory job queue; no workers run, so tests process jobs with Jobs.RunOnce
+	Jobs  *jobs.Queue
+	Audit repository.AuditRepository
+
+	// Push records the push notifications sent instead of calling FCM or APNs
+	Push *resources.MockPush
+
+	Cleanup func()
+}
+
+// Backend returns the backend selected by BackendEnv
+func Backend() string {
+	if os.Getenv(BackendEnv) == BackendContainers {
+		return BackendContainers
+	}
+	return BackendMock
 }
 
 // Setup sets up the test environment for integration tests
+// With INTEGRATION_BACKEND=containers, each call gets its own database on the shared MongoDB container.
 func Setup(t *testing.T) *TestEnv {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
@@ -40,27 +90,74 @@ func Setup(t *testing.T) *TestEnv {
 	// Load configuration
 	cfg := loadTestConfig(t)
 
-	// Create test resources
-	res := setupTestResources(t, cfg)
-
-	// Create repositories
-	// Initialize user repository
-	// For integration tests, use MongoDB repository with test database
-	// userRepo := repository.NewMongoUserRepository(resources.DB)
-	// For now, use mock repository
-	userRepo := repository.NewMockUserRepository()
+	// Create test resources and the user repository on the selected backend
+	clk := clock.New()
+	ids := idgen.NewUUIDv7(clk)
+	var (
+		res      *resources.Resources
+		userRepo repository.UserRepository
+		cleanup  func()
+	)
+	if Backend() == BackendContainers {
+		res, userRepo, cleanup = setupContainerBackend(t, cfg, clk, ids)
+	} else {
+		res = setupTestResources(t, cfg)
+		userRepo = repository.NewMockUserRepository()
+		cleanup = func() { closeTestResources(t, res) }
+	}
 
 	// Create services
 	appService := service.NewAppService(cfg)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, clk)
+
+	// Background jobs and the audit log stay in memory on every backend
+	queue := jobs.NewQueue(repository.NewMockJobRepository(clk), clk, cfg.Jobs)
+	audit := repository.NewMockAuditRepository(clk)
+	sessionRepo := repository.NewMockSessionRepository(clk)
+	sessionService := service.NewSessionService(sessionRepo, audit, clk, cfg)
+	preferencesRepo := repository.NewMockPreferencesRepository(clk)
+	preferencesService := service.NewPreferencesService(preferencesRepo, userRepo)
+	files := repository.NewMockFileRepository(clk)
+	avatarService := service.NewAvatarService(userRepo, files, queue, clk, cfg)
+	push := resources.NewMockPush(cfg)
+	require.NoError(t, push.Connect(context.Background()))
+	devices := repository.NewMockDeviceRepository(clk)
+	receipts := repository.NewMockPushReceiptRepository(clk)
+	notificationService := service.NewNotificationService(devices, receipts, userRepo, preferencesRepo, push, queue, cfg)
+	summaries := repository.NewMockUserSummaryRepository(userRepo, sessionRepo, devices, clk)
+	uploadRepo := repository.NewMockUploadRepository(clk)
+	storage := resources.NewMockObjectStorage()
+	require.NoError(t, storage.Connect(context.Background()))
+	gdprService := service.NewGDPRService(userRepo, gdpr.NewRegistry(gdpr.Users(userRepo), gdpr.Sessions(sessionRepo),
+		gdpr.Preferences(preferencesRepo), gdpr.Avatars(userRepo, files), gdpr.Devices(devices), gdpr.PushReceipts(receipts),
+		gdpr.UserSummaries(summaries), gdpr.Uploads(uploadRepo, storage)),
+		queue, audit, clk)
 
-	apiHandler := api.NewHandler(appService, userService)
+	tokens := impersonation.NewTokens(cfg, clk)
+	registry := modules.New(
+		ping.NewModule(ping.NewHandler(handlers.NewBaseHandler(appService))),
+		csrf.NewModule(csrf.NewHandler(handlers.NewBaseHandler(appService), cfg), cfg),
+		lockout.NewModule(lockout.NewHandler(handlers.NewBaseHandler(appService),
+			throttle.New(throttle.NewMemoryStore(clk), audit, cfg.LoginThrottle))),
+		admin.NewModule(admin.NewHandler(handlers.NewBaseHandler(appService),
+			service.NewAdminService(userRepo, sessionRepo, tokens, audit, clk, cfg), notificationService, queue),
+			rbac.NewAuthorizer(userRepo, cfg), tokens, audit),
+		uploads.NewModule(uploads.NewHandler(handlers.NewBaseHandler(appService),
+			service.NewUploadService(uploadRepo, storage, ids, clk, cfg))),
+	)
+	apiHandler := api.NewHandler(appService, userService, gdprService, sessionService, preferencesService, avatarService, notificationService,
+		service.NewUserSummaryService(summaries), nil, nil, nil, registry, nil)
 
 	// Create router
 	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(middleware.NoMethod())
 	router.Use(middleware.RequestID())
-	router.Use(middleware.Logger())
-	router.Use(middleware.Recovery())
+	router.Use(middleware.ClientIP())
+	router.Use(middleware.Correlation())
+	router.Use(middleware.Logger(middleware.LoggerConfig{}))
+	router.Use(middleware.Recovery(nil))
+	router.Use(registry.Middleware()...)
 
 	// Register routes
 	apiHandler.RegisterRoutes(router)
@@ -73,12 +170,84 @@ func Setup(t *testing.T) *TestEnv {
 		AppService:  appService,
 		UserService: userService,
 		UserRepo:    userRepo,
-		Cleanup: func() {
-			closeTestResources(t, res)
-		},
+		GDPRService: gdprService,
+		Sessions:    sessionService,
+		Clock:       clk,
+		IDs:         ids,
+		Jobs:        queue,
+		Audit:       audit,
+		Push:        push,
+		Cleanup:     cleanup,
 	}
 }
 
+// SeedUsers creates n realistic users (see testutil.SeedUsers) through the user service and returns them
+func (e *TestEnv) SeedUsers(t *testing.T, n int) []*domain.User {
+	t.Helper()
+
+	users := testutil.SeedUsers(t, n)
+	for _, user := range users {
+		require.NoError(t, e.UserService.Create(context.Background(), user), "Failed to seed user %s", user.Email)
+	}
+	return users
+}
+
+// setupContainerBackend connects to the MongoDB and Redis containers and creates the MongoDB user
+// repository on a fresh database, with its schema and indexes applied
+// The returned cleanup drops the database and closes the connections.
+func setupContainerBackend(t *testing.T, cfg *config.Config, clk clock.Clock, ids idgen.Generator) (*resources.Resources, repository.UserRepository, func()) {
+	UseContainers(t, cfg)
+
+	res := &resources.Resources{
+		DB:    resources.NewDB(cfg),
+		Redis: resources.NewRedis(cfg),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := resources.InitResources(ctx, res)
+	require.NoError(t, err, "Failed to connect to integration containers")
+
+	userRepo := repository.NewUserRepository(res.DB, repository.CacheConfig{}, clk, repository.StringIDCodec(ids))
+	migrate(ctx, t, userRepo)
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := res.DB.(*resources.DB).GetDatabase().Drop(ctx); err != nil {
+			t.Logf("Failed to drop test database %s: %v", cfg.MongoDB.Database, err)
+		}
+		resources.CloseResources(ctx, res)
+	}
+	return res, userRepo, cleanup
+}
+
+// migrate applies a repository's declared schema and indexes, as the application does on startup
+func migrate(ctx context.Context, t *testing.T, repo interface{}) {
+	if syncer, ok := repo.(repository.CollectionSyncer); ok {
+		require.NoError(t, syncer.SyncCollection(ctx), "Failed to sync collection")
+	}
+	if indexer, ok := repo.(interface{ EnsureIndexes() error }); ok {
+		require.NoError(t, indexer.EnsureIndexes(), "Failed to ensure indexes")
+	}
+}
+
+// testDatabase returns a database name unique to the test, within MongoDB's 63 character limit
+func testDatabase(t *testing.T) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, t.Name())
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	return fmt.Sprintf("it_%s_%d", name, time.Now().UnixNano())
+}
+
 // loadTestConfig loads the test configuration
 func loadTestConfig(t *testing.T) *config.Config {
 	// Set test environment variables if needed
@@ -88,6 +257,9 @@ func loadTestConfig(t *testing.T) *config.Config {
 	// Load configuration
 	cfg := config.NewConfig()
 	require.NotNil(t, cfg, "Failed to load test configuration")
+	if cfg.Admin.ImpersonationSigningKey == "" {
+		cfg.Admin.ImpersonationSigningKey = "integration-impersonation-key"
+	}
 
 	return cfg
 }
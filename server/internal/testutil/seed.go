@@ -0,0 +1,52 @@
+package testutil
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"quizizz.com/internal/domain"
+	"quizizz.com/pkg/idgen"
+)
+
+// seedEpoch is the earliest creation time of seeded users
+var seedEpoch = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SeedUsers returns n users with realistic names and unique emails, for load and integration tests
+// Names, emails and creation times are derived from the test name, so a test sees the same users on every run.
+// IDs are new UUIDv7s, as the application generates by default.
+func SeedUsers(t *testing.T, n int) []*domain.User {
+	t.Helper()
+
+	hash := fnv.New64a()
+	hash.Write([]byte(t.Name()))
+	faker := gofakeit.New(hash.Sum64())
+	ids := idgen.NewUUIDv7(nil)
+
+	users := make([]*domain.User, n)
+	for i := range users {
+		first, last := faker.FirstName(), faker.LastName()
+		created := faker.DateRange(seedEpoch, seedEpoch.AddDate(1, 0, 0)).UTC()
+		users[i] = &domain.User{
+			ID:        ids.NewID(),
+			Name:      domain.UserName(first + " " + last),
+			Email:     domain.Email(fmt.Sprintf("%s.%s.%d@example.com", emailPart(first), emailPart(last), i+1)),
+			CreatedAt: created,
+			UpdatedAt: created,
+		}
+	}
+	return users
+}
+
+// emailPart lowercases a name and drops characters not allowed in the local part of an email
+func emailPart(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(name))
+}
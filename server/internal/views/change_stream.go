@@ -0,0 +1,130 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/resources"
+	"quizizz.com/pkg/clock"
+)
+
+// changeStreamUnsupported is the error code of opening a change stream on a standalone server
+const changeStreamUnsupported = 40573
+
+// Backoff between attempts to reopen a failed change stream
+const (
+	minReopenDelay = time.Second
+	maxReopenDelay = time.Minute
+)
+
+// ChangeStreams is a Source following MongoDB change streams, which need a replica set
+type ChangeStreams struct {
+	db    *mongo.Database
+	clock clock.Clock
+}
+
+// NewChangeStreams creates a Source following the change streams of the database of db
+func NewChangeStreams(db resources.DBResource, clk clock.Clock) *ChangeStreams {
+	return &ChangeStreams{
+		db:    db.(*resources.DB).GetDatabase(),
+		clock: clk,
+	}
+}
+
+// changeEvent is the part of a change stream event a Change is built from
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	Namespace     struct {
+		Collection string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID interface{} `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument bson.M `bson:"fullDocument"`
+}
+
+// Watch follows the changes of collections, reopening the stream where it left off when it fails
+// It gives up when the server does not support change streams.
+func (s *ChangeStreams) Watch(ctx context.Context, collections []string, apply func(Change) error) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"ns.coll":       bson.M{"$in": collections},
+			"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+		}}},
+	}
+
+	var resumeToken bson.Raw
+	delay := minReopenDelay
+	for {
+		opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+		if resumeToken != nil {
+			opts.SetResumeAfter(resumeToken)
+		}
+
+		err := s.follow(ctx, pipeline, opts, apply, func(token bson.Raw) {
+			resumeToken = token
+			delay = minReopenDelay
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Code == changeStreamUnsupported {
+			return fmt.Errorf("change streams are not supported: %w", err)
+		}
+
+		logger.Warn("Change stream failed, reopening", zap.Duration("delay", delay), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.clock.After(delay):
+		}
+		delay = min(2*delay, maxReopenDelay)
+	}
+}
+
+// follow opens a change stream and applies its changes until it fails, passing the resume token
+// of every applied change to resumed
+func (s *ChangeStreams) follow(ctx context.Context, pipeline mongo.Pipeline, opts *options.ChangeStreamOptions, apply func(Change) error, resumed func(bson.Raw)) error {
+	stream, err := s.db.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	for stream.Next(ctx) {
+		var event changeEvent
+		if err := stream.Decode(&event); err != nil {
+			return fmt.Errorf("failed to decode change: %w", err)
+		}
+		if err := apply(toChange(event)); err != nil {
+			return err
+		}
+		resumed(stream.ResumeToken())
+	}
+	return stream.Err()
+}
+
+// toChange returns the Change of a change stream event
+func toChange(event changeEvent) Change {
+	operation := OperationUpdate
+	switch event.OperationType {
+	case "insert":
+		operation = OperationInsert
+	case "delete":
+		operation = OperationDelete
+	}
+	return Change{
+		Collection: event.Namespace.Collection,
+		Operation:  operation,
+		DocumentID: event.DocumentKey.ID,
+		Document:   event.FullDocument,
+	}
+}
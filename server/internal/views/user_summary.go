@@ -0,0 +1,67 @@
+package views
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quizizz.com/internal/repository"
+)
+
+// UserSummaries is the name of the user summary view
+const UserSummaries = repository.UserSummaryCollection
+
+// UserSummaryView is the view of user summaries, refreshed when a user, one of their sessions or
+// one of their devices changes
+type UserSummaryView struct {
+	repo repository.UserSummaryRepository
+}
+
+// NewUserSummaryView creates the view of the summaries stored in repo
+func NewUserSummaryView(repo repository.UserSummaryRepository) *UserSummaryView {
+	return &UserSummaryView{repo: repo}
+}
+
+// Name returns the name of the view
+func (v *UserSummaryView) Name() string {
+	return UserSummaries
+}
+
+// Sources returns the collections summaries are computed from
+func (v *UserSummaryView) Sources() []string {
+	return []string{"users", "sessions", "devices"}
+}
+
+// Keys returns the ID of the user a change is about
+// Deleted sessions and devices carry no user ID; the next rebuild catches up with them.
+func (v *UserSummaryView) Keys(change Change) []string {
+	var userID interface{}
+	if change.Collection == "users" {
+		userID = change.DocumentID
+	} else if change.Document != nil {
+		userID = change.Document["userId"]
+	}
+
+	switch id := userID.(type) {
+	case string:
+		if id != "" {
+			return []string{id}
+		}
+	case primitive.ObjectID:
+		return []string{id.Hex()}
+	case nil:
+	default:
+		return []string{fmt.Sprint(id)}
+	}
+	return nil
+}
+
+// Refresh recomputes the summary of a user
+func (v *UserSummaryView) Refresh(ctx context.Context, userID string) error {
+	return v.repo.Refresh(ctx, userID)
+}
+
+// Rebuild recomputes every summary
+func (v *UserSummaryView) Rebuild(ctx context.Context) (int64, error) {
+	return v.repo.Rebuild(ctx)
+}
@@ -0,0 +1,334 @@
+// Package views maintains denormalized read models
+//
+// A View is a collection computed from source collections, e.g. user summaries counting the
+// sessions and devices of each user, so list endpoints read it instead of aggregating the sources
+// on every request. The Maintainer refreshes the documents of a view affected by a Change of its
+// sources: changes come from MongoDB change streams, or from domain events passed to Handle. The
+// refreshes run as jobs on the job queue, and full rebuilds run on a cron schedule to catch up
+// changes that were missed, e.g. while change streams were unavailable. Rebuild backfills a view
+// from scratch, which is what the views command does.
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/repository"
+	"quizizz.com/pkg/clock"
+)
+
+// Job types refreshing and rebuilding views
+const (
+	RefreshJobType = "views.refresh"
+	RebuildJobType = "views.rebuild"
+)
+
+// ErrUnknownView is returned when rebuilding a view that was not registered
+var ErrUnknownView = errors.New("unknown view")
+
+// Operations of a change
+const (
+	OperationInsert = "insert"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Change is a change of a document of a source collection
+type Change struct {
+	Collection string
+	Operation  string
+
+	// DocumentID is the _id of the changed document
+	DocumentID interface{}
+
+	// Document is the document after the change; it is nil for deletes, and for changes from
+	// events, which only carry DocumentID
+	Document bson.M
+}
+
+// View is a read model computed from source collections
+// Refresh and Rebuild may run more than once for the same change, so they must be idempotent.
+type View interface {
+	// Name identifies the view in jobs, logs and metrics
+	Name() string
+
+	// Sources are the collections the view is computed from
+	Sources() []string
+
+	// Keys returns the keys of the view documents affected by change, e.g. the user IDs
+	Keys(change Change) []string
+
+	// Refresh recomputes the view document of key
+	Refresh(ctx context.Context, key string) error
+
+	// Rebuild recomputes the whole view and returns the number of documents it holds
+	Rebuild(ctx context.Context) (int64, error)
+}
+
+// Source streams the changes of collections
+type Source interface {
+	// Watch calls apply for every change of collections until ctx is done or the stream fails
+	Watch(ctx context.Context, collections []string, apply func(Change) error) error
+}
+
+// refreshPayload is the payload of a refresh job
+type refreshPayload struct {
+	View string `json:"view"`
+	Key  string `json:"key"`
+}
+
+// rebuildPayload is the payload of a rebuild job; an empty View rebuilds every view
+type rebuildPayload struct {
+	View string `json:"view,omitempty"`
+}
+
+// Result is the outcome of rebuilding a view
+type Result struct {
+	View      string        `json:"view"`
+	Documents int64         `json:"documents"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// Maintainer keeps views up to date with their sources
+type Maintainer struct {
+	views  []View
+	queue  *jobs.Queue
+	source Source
+	clock  clock.Clock
+	config config.ViewsConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	refreshed metric.Int64Counter
+}
+
+// NewMaintainer creates a Maintainer of views refreshed from source and registers its jobs on
+// queue, scheduling rebuilds on cfg.RebuildSchedule when cfg.Enabled is set
+// A nil source leaves views to events and rebuilds.
+func NewMaintainer(views []View, queue *jobs.Queue, source Source, clk clock.Clock, cfg config.ViewsConfig) (*Maintainer, error) {
+	m := &Maintainer{
+		views:  views,
+		queue:  queue,
+		source: source,
+		clock:  clk,
+		config: cfg,
+	}
+	m.initInstruments()
+
+	queue.Register(RefreshJobType, m.runRefresh)
+	queue.Register(RebuildJobType, m.runRebuild)
+	if cfg.Enabled && cfg.RebuildSchedule != "" {
+		if err := queue.Cron(RebuildJobType, cfg.RebuildSchedule); err != nil {
+			return nil, fmt.Errorf("invalid VIEWS_REBUILD_SCHEDULE: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// initInstruments creates the refresh counter; failures leave it nil and only disable metrics
+func (m *Maintainer) initInstruments() {
+	var err error
+	m.refreshed, err = otel.Meter("views").Int64Counter("views.refreshed",
+		metric.WithDescription("Number of view documents refreshed by view"),
+	)
+	if err != nil {
+		logger.Error("Failed to create views.refreshed counter", zap.Error(err))
+	}
+}
+
+// Views returns the names of the views, in the order they were given
+func (m *Maintainer) Views() []string {
+	names := make([]string, len(m.views))
+	for i, view := range m.views {
+		names[i] = view.Name()
+	}
+	return names
+}
+
+// Apply enqueues refreshing the view documents affected by change
+func (m *Maintainer) Apply(ctx context.Context, change Change) error {
+	if !m.config.Enabled {
+		return nil
+	}
+
+	for _, view := range m.views {
+		if !slices.Contains(view.Sources(), change.Collection) {
+			continue
+		}
+		for _, key := range view.Keys(change) {
+			if _, err := m.queue.Enqueue(ctx, RefreshJobType, refreshPayload{View: view.Name(), Key: key}); err != nil {
+				return fmt.Errorf("failed to enqueue refreshing %s %s: %w", view.Name(), key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Handle applies the change a domain event describes; events of other types are ignored
+func (m *Maintainer) Handle(ctx context.Context, event *events.Event) error {
+	change, ok := changeOf(event)
+	if !ok {
+		return nil
+	}
+	return m.Apply(ctx, change)
+}
+
+// changeOf returns the change of the document event is about
+func changeOf(event *events.Event) (Change, bool) {
+	switch payload := event.Payload.(type) {
+	case *events.UserCreatedV1:
+		return Change{Collection: "users", Operation: OperationInsert, DocumentID: payload.UserID}, true
+	case *events.UserUpdatedV1:
+		return Change{Collection: "users", Operation: OperationUpdate, DocumentID: payload.UserID}, true
+	case *events.UserDeletedV1:
+		return Change{Collection: "users", Operation: OperationDelete, DocumentID: payload.UserID}, true
+	default:
+		return Change{}, false
+	}
+}
+
+// Rebuild recomputes the named views, or every view when no name is given
+func (m *Maintainer) Rebuild(ctx context.Context, names ...string) ([]Result, error) {
+	views := m.views
+	if len(names) > 0 {
+		views = make([]View, 0, len(names))
+		for _, name := range names {
+			view := m.view(name)
+			if view == nil {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownView, name)
+			}
+			views = append(views, view)
+		}
+	}
+
+	results := make([]Result, 0, len(views))
+	for _, view := range views {
+		start := m.clock.Now()
+		documents, err := view.Rebuild(ctx)
+		if err != nil {
+			return results, fmt.Errorf("failed to rebuild %s: %w", view.Name(), err)
+		}
+
+		result := Result{View: view.Name(), Documents: documents, Elapsed: m.clock.Since(start)}
+		logger.InfoCtx(ctx, "View rebuilt",
+			zap.String("view", result.View),
+			zap.Int64("documents", result.Documents),
+			zap.Duration("elapsed", result.Elapsed),
+		)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Start follows the changes of the sources of the views until Stop is called, when enabled and
+// a source is configured
+func (m *Maintainer) Start(ctx context.Context) {
+	if !m.config.Enabled || !m.config.ChangeStreams || m.source == nil || len(m.views) == 0 {
+		return
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	var collections []string
+	for _, view := range m.views {
+		for _, source := range view.Sources() {
+			if !slices.Contains(collections, source) {
+				collections = append(collections, source)
+			}
+		}
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		logger.Info("Following changes of view sources", zap.Strings("collections", collections))
+		err := m.source.Watch(ctx, collections, func(change Change) error {
+			return m.Apply(ctx, change)
+		})
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Stopped following changes of view sources; views are only refreshed by events and rebuilds", zap.Error(err))
+		}
+	}()
+}
+
+// Stop stops following changes and waits for the change in flight or ctx to be done
+func (m *Maintainer) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("view maintainer did not stop: %w", ctx.Err())
+	}
+}
+
+// runRefresh is the handler of refresh jobs
+func (m *Maintainer) runRefresh(ctx context.Context, job *repository.Job) error {
+	var payload refreshPayload
+	if err := jobs.Decode(job, &payload); err != nil {
+		return err
+	}
+	view := m.view(payload.View)
+	if view == nil {
+		return jobs.Permanent(fmt.Errorf("%w: %s", ErrUnknownView, payload.View))
+	}
+
+	if err := view.Refresh(ctx, payload.Key); err != nil {
+		return err
+	}
+	if m.refreshed != nil {
+		m.refreshed.Add(ctx, 1, metric.WithAttributes(attribute.String("view", view.Name())))
+	}
+	return nil
+}
+
+// runRebuild is the handler of rebuild jobs
+func (m *Maintainer) runRebuild(ctx context.Context, job *repository.Job) error {
+	var payload rebuildPayload
+	if err := jobs.Decode(job, &payload); err != nil {
+		return err
+	}
+
+	var names []string
+	if payload.View != "" {
+		names = []string{payload.View}
+	}
+	_, err := m.Rebuild(ctx, names...)
+	if errors.Is(err, ErrUnknownView) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// view returns the view named name, or nil
+func (m *Maintainer) view(name string) View {
+	for _, view := range m.views {
+		if view.Name() == name {
+			return view
+		}
+	}
+	return nil
+}
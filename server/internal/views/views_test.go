@@ -0,0 +1,256 @@
+package views
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/repository"
+	"quizizz.com/internal/testutil"
+	"quizizz.com/pkg/idgen"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var testConfig = config.ViewsConfig{Enabled: true, ChangeStreams: true}
+
+var testIDs = idgen.Func(func() string { return "event-1" })
+
+// fakeSource is a Source replaying changes, then waiting for ctx to be done
+type fakeSource struct {
+	changes     []Change
+	collections []string
+}
+
+func (s *fakeSource) Watch(ctx context.Context, collections []string, apply func(Change) error) error {
+	s.collections = collections
+	for _, change := range s.changes {
+		if err := apply(change); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+type testEnv struct {
+	maintainer *Maintainer
+	queue      *jobs.Queue
+	users      repository.UserRepository
+	sessions   *repository.MockSessionRepository
+	devices    *repository.MockDeviceRepository
+	summaries  *repository.MockUserSummaryRepository
+}
+
+func newTestEnv(t *testing.T, cfg config.ViewsConfig, source Source) *testEnv {
+	t.Helper()
+	clk := testutil.NewFakeClock(testTime)
+	env := &testEnv{
+		queue:    jobs.NewQueue(repository.NewMockJobRepository(clk), clk, config.JobsConfig{MaxAttempts: 1}),
+		users:    repository.NewMockUserRepository(),
+		sessions: repository.NewMockSessionRepository(clk),
+		devices:  repository.NewMockDeviceRepository(clk),
+	}
+	env.summaries = repository.NewMockUserSummaryRepository(env.users, env.sessions, env.devices, clk)
+
+	var err error
+	env.maintainer, err = NewMaintainer([]View{NewUserSummaryView(env.summaries)}, env.queue, source, clk, cfg)
+	require.NoError(t, err)
+	return env
+}
+
+// createUser stores a user with a session and a device
+func (env *testEnv) createUser(t *testing.T, id string) {
+	t.Helper()
+	ctx := context.Background()
+	require.NoError(t, env.users.Create(ctx, &domain.User{ID: id, Name: "Test User", Email: domain.Email(id + "@example.com"), CreatedAt: testTime}))
+	require.NoError(t, env.sessions.Create(ctx, &repository.Session{
+		UserID:     id,
+		LastUsedAt: testTime,
+		ExpiresAt:  testTime.Add(time.Hour),
+	}))
+	_, err := env.devices.Register(ctx, &domain.Device{UserID: id, Platform: "android", Token: "token-" + id})
+	require.NoError(t, err)
+}
+
+// runJobs processes the due jobs
+func (env *testEnv) runJobs(t *testing.T) {
+	t.Helper()
+	for {
+		processed, err := env.queue.RunOnce(context.Background())
+		require.NoError(t, err)
+		if !processed {
+			return
+		}
+	}
+}
+
+func TestUserSummaryView_Keys(t *testing.T) {
+	view := NewUserSummaryView(nil)
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name   string
+		change Change
+		want   []string
+	}{
+		{"User", Change{Collection: "users", DocumentID: "user-1"}, []string{"user-1"}},
+		{"User with ObjectID", Change{Collection: "users", DocumentID: id}, []string{id.Hex()}},
+		{"Session", Change{Collection: "sessions", DocumentID: "session-1", Document: bson.M{"userId": "user-1"}}, []string{"user-1"}},
+		{"Deleted device", Change{Collection: "devices", Operation: OperationDelete, DocumentID: "device-1"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, view.Keys(tt.change))
+		})
+	}
+}
+
+func TestMaintainer_Apply(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Refreshes affected summaries", func(t *testing.T) {
+		env := newTestEnv(t, testConfig, nil)
+		env.createUser(t, "user-1")
+		env.createUser(t, "user-2")
+
+		require.NoError(t, env.maintainer.Apply(ctx, Change{Collection: "sessions", Operation: OperationInsert, Document: bson.M{"userId": "user-1"}}))
+		require.NoError(t, env.maintainer.Apply(ctx, Change{Collection: "audit_log", Operation: OperationInsert, Document: bson.M{"userId": "user-2"}}))
+		env.runJobs(t)
+
+		summary, err := env.summaries.Get(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Test User", summary.Name)
+		assert.Equal(t, int64(1), summary.Sessions)
+		assert.Equal(t, int64(1), summary.Devices)
+		assert.Equal(t, testTime, summary.LastSeenAt)
+
+		_, err = env.summaries.Get(ctx, "user-2")
+		assert.ErrorIs(t, err, repository.ErrNotFound, "changes of other collections are ignored")
+	})
+
+	t.Run("Removes summaries of deleted users", func(t *testing.T) {
+		env := newTestEnv(t, testConfig, nil)
+		env.createUser(t, "user-1")
+		require.NoError(t, env.summaries.Refresh(ctx, "user-1"))
+
+		require.NoError(t, env.users.Delete(ctx, "user-1"))
+		event, err := events.New(testIDs, testTime, events.UserDeleted("user-1", testTime))
+		require.NoError(t, err)
+		require.NoError(t, env.maintainer.Handle(ctx, event))
+		env.runJobs(t)
+
+		_, err = env.summaries.Get(ctx, "user-1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		env := newTestEnv(t, config.ViewsConfig{}, nil)
+		env.createUser(t, "user-1")
+
+		require.NoError(t, env.maintainer.Apply(ctx, Change{Collection: "users", Operation: OperationInsert, DocumentID: "user-1"}))
+		env.runJobs(t)
+
+		_, err := env.summaries.Get(ctx, "user-1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestMaintainer_Rebuild(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Every view", func(t *testing.T) {
+		env := newTestEnv(t, testConfig, nil)
+		env.createUser(t, "user-1")
+		env.createUser(t, "user-2")
+
+		results, err := env.maintainer.Rebuild(ctx)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, UserSummaries, results[0].View)
+		assert.Equal(t, int64(2), results[0].Documents)
+
+		summaries, total, err := env.summaries.List(ctx, domain.ListOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, summaries, 2)
+	})
+
+	t.Run("Unknown view", func(t *testing.T) {
+		env := newTestEnv(t, testConfig, nil)
+
+		_, err := env.maintainer.Rebuild(ctx, "quizzes")
+		assert.ErrorIs(t, err, ErrUnknownView)
+	})
+
+	t.Run("Job", func(t *testing.T) {
+		env := newTestEnv(t, testConfig, nil)
+		env.createUser(t, "user-1")
+
+		_, err := env.queue.Enqueue(ctx, RebuildJobType, rebuildPayload{View: UserSummaries})
+		require.NoError(t, err)
+		env.runJobs(t)
+
+		_, err = env.summaries.Get(ctx, "user-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invalid schedule", func(t *testing.T) {
+		clk := testutil.NewFakeClock(testTime)
+		queue := jobs.NewQueue(repository.NewMockJobRepository(clk), clk, config.JobsConfig{})
+
+		_, err := NewMaintainer(nil, queue, nil, clk, config.ViewsConfig{Enabled: true, RebuildSchedule: "every day"})
+		assert.Error(t, err)
+	})
+}
+
+func TestMaintainer_Start(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Follows changes", func(t *testing.T) {
+		source := &fakeSource{changes: []Change{{Collection: "users", Operation: OperationInsert, DocumentID: "user-1"}}}
+		env := newTestEnv(t, testConfig, source)
+		env.createUser(t, "user-1")
+
+		env.maintainer.Start(ctx)
+		require.Eventually(t, func() bool {
+			processed, err := env.queue.RunOnce(ctx)
+			return processed && err == nil
+		}, 5*time.Second, time.Millisecond)
+		require.NoError(t, env.maintainer.Stop(ctx))
+
+		assert.Equal(t, []string{"users", "sessions", "devices"}, source.collections)
+		_, err := env.summaries.Get(ctx, "user-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Without change streams", func(t *testing.T) {
+		source := &fakeSource{}
+		env := newTestEnv(t, config.ViewsConfig{Enabled: true}, source)
+
+		env.maintainer.Start(ctx)
+		require.NoError(t, env.maintainer.Stop(ctx))
+		assert.Nil(t, source.collections)
+	})
+}
+
+func TestChangeOf(t *testing.T) {
+	user := &domain.User{ID: "user-1", Name: "Test User", Email: "test@example.com", CreatedAt: testTime, UpdatedAt: testTime}
+
+	created, err := events.New(testIDs, testTime, events.UserCreated(user))
+	require.NoError(t, err)
+	change, ok := changeOf(created)
+	require.True(t, ok)
+	assert.Equal(t, Change{Collection: "users", Operation: OperationInsert, DocumentID: "user-1"}, change)
+
+	_, ok = changeOf(&events.Event{Type: "quiz.created", Version: 1})
+	assert.False(t, ok)
+}
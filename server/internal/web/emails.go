@@ -0,0 +1,33 @@
+package web
+
+// WelcomeEmail is the data of the welcome email
+type WelcomeEmail struct {
+	Name      string
+	SignInURL string
+}
+
+// PasswordResetEmail is the data of the email sent when a password reset is forced
+type PasswordResetEmail struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn string
+}
+
+// AccountLockedEmail is the data of the email sent when an account is locked
+type AccountLockedEmail struct {
+	Name   string
+	Reason string
+}
+
+// previews holds the sample data the email templates are previewed with, by template name
+var previews = map[string]interface{}{
+	"welcome":        WelcomeEmail{Name: "Ada Lovelace", SignInURL: "https://example.com/sign-in"},
+	"password_reset": PasswordResetEmail{Name: "Ada Lovelace", ResetURL: "https://example.com/reset?token=sample", ExpiresIn: "24 hours"},
+	"account_locked": AccountLockedEmail{Name: "Ada Lovelace", Reason: "Suspicious activity"},
+}
+
+// PreviewData returns the sample data of an email template
+func PreviewData(email string) (interface{}, bool) {
+	data, ok := previews[email]
+	return data, ok
+}
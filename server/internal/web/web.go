@@ -0,0 +1,132 @@
+// Package web renders the server-rendered HTML pages and emails from templates embedded in the binary
+//
+// Templates live in templates/: a page or email defines "title" (or "subject" for emails) and
+// "content", and is rendered inside its layout, pages in layouts/base.html and emails in
+// layouts/email.html, with every partial of partials/ available. Static assets are served from
+// static/.
+package web
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"quizizz.com/internal/config"
+)
+
+//go:embed templates static
+var files embed.FS
+
+// Template directories and the layout their templates are rendered in
+var layouts = map[string]string{
+	"pages":  "base",
+	"emails": "email",
+}
+
+// View is the data templates are executed with: the name of the application and the data of the page
+type View struct {
+	App  string
+	Page interface{}
+}
+
+// Link is the data of the button partial
+type Link struct {
+	URL   string
+	Label string
+}
+
+// funcs are the functions available to templates
+var funcs = template.FuncMap{
+	"formatTime": func(t time.Time) string { return t.UTC().Format("Jan 2, 2006 15:04:05 MST") },
+	"year":       func() int { return time.Now().Year() },
+	"link":       func(url, label string) Link { return Link{URL: url, Label: label} },
+	"percent":    func(ratio float64) string { return strconv.FormatFloat(ratio*100, 'f', 2, 64) + "%" },
+}
+
+// Renderer renders the embedded pages and emails
+type Renderer struct {
+	app       string
+	previews  bool
+	templates map[string]*template.Template
+}
+
+// NewRenderer parses the embedded templates, each with its layout and the partials
+func NewRenderer(cfg *config.Config) (*Renderer, error) {
+	r := &Renderer{
+		app:       cfg.AppName,
+		previews:  cfg.Pages.EmailPreviews,
+		templates: make(map[string]*template.Template),
+	}
+	for dir := range layouts {
+		names, err := fs.Glob(files, "templates/"+dir+"/*.html")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			tmpl, err := template.New(path.Base(name)).Funcs(funcs).ParseFS(files,
+				"templates/layouts/*.html", "templates/partials/*.html", name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+			}
+			r.templates[dir+"/"+strings.TrimSuffix(path.Base(name), ".html")] = tmpl
+		}
+	}
+	return r, nil
+}
+
+// Render renders a template, e.g. pages/status or emails/welcome, with the data of its page
+// The template is fully rendered before anything is written, so failures leave w untouched.
+func (r *Renderer) Render(w io.Writer, name string, page interface{}) error {
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return fmt.Errorf("unknown template %s", name)
+	}
+	layout := layouts[strings.SplitN(name, "/", 2)[0]]
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, layout, View{App: r.app, Page: page}); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Has reports whether a template exists
+func (r *Renderer) Has(name string) bool {
+	_, ok := r.templates[name]
+	return ok
+}
+
+// Emails returns the names of the email templates, without their directory, sorted
+func (r *Renderer) Emails() []string {
+	var names []string
+	for name := range r.templates {
+		if email, ok := strings.CutPrefix(name, "emails/"); ok {
+			names = append(names, email)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Previews reports whether the email previews are served
+func (r *Renderer) Previews() bool {
+	return r.previews
+}
+
+// Static returns the static assets
+func Static() fs.FS {
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		panic(err)
+	}
+	return static
+}
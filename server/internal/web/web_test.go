@@ -0,0 +1,75 @@
+package web
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/config"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	renderer, err := NewRenderer(&config.Config{AppName: "test-app"})
+	require.NoError(t, err)
+	return renderer
+}
+
+func TestRenderer_Render(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	t.Run("Renders pages in their layout", func(t *testing.T) {
+		var buf bytes.Buffer
+		page := struct {
+			Healthy   bool
+			Version   string
+			CheckedAt time.Time
+			Checks    []struct{ Name, Status, Message string }
+			Uptime    map[string]float64
+			Incidents map[string]bool
+		}{
+			Version:   "1.0.0",
+			Checks:    []struct{ Name, Status, Message string }{{"mongodb", "error", "<timeout>"}},
+			Uptime:    map[string]float64{"mongodb": 0.9875},
+			Incidents: map[string]bool{"mongodb": true},
+		}
+
+		require.NoError(t, renderer.Render(&buf, "pages/status", page))
+		html := buf.String()
+		assert.Contains(t, html, "<title>Status · test-app</title>")
+		assert.Contains(t, html, "Some systems are degraded")
+		assert.Contains(t, html, "&lt;timeout&gt;", "data is escaped")
+		assert.Contains(t, html, "<td>98.75%</td>")
+		assert.Contains(t, html, `<span class="badge incident">incident</span>`)
+		assert.Contains(t, html, `<a class="brand" href="/status">test-app</a>`, "partials are included")
+	})
+
+	t.Run("Renders emails with their sample data", func(t *testing.T) {
+		for _, email := range renderer.Emails() {
+			data, ok := PreviewData(email)
+			require.True(t, ok, "%s has sample data", email)
+
+			var buf bytes.Buffer
+			require.NoError(t, renderer.Render(&buf, "emails/"+email, data), email)
+			assert.Contains(t, buf.String(), "Ada Lovelace", email)
+		}
+	})
+
+	t.Run("Unknown templates", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, renderer.Render(&buf, "pages/missing", nil))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestRenderer_Emails(t *testing.T) {
+	assert.Equal(t, []string{"account_locked", "password_reset", "welcome"}, newTestRenderer(t).Emails())
+}
+
+func TestStatic(t *testing.T) {
+	_, err := fs.Stat(Static(), "style.css")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,167 @@
+// Package capture records sanitized request/response pairs so production traffic can be replayed
+// against another environment
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Exchange is a captured request and the response it received
+type Exchange struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+
+	Status         int           `json:"status"`
+	ResponseHeader http.Header   `json:"responseHeader,omitempty"`
+	ResponseBody   string        `json:"responseBody,omitempty"`
+	Duration       time.Duration `json:"duration"`
+
+	// Truncated is set when a body exceeded the capture limit and was cut short
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Sink stores captured exchanges
+type Sink interface {
+	Write(exchange *Exchange) error
+	Close() error
+}
+
+// FileSink appends exchanges to a file as newline-delimited JSON
+type FileSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewFileSink opens (or creates) path for appending exchanges
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture file: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	return &FileSink{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+// Write appends an exchange to the file
+func (s *FileSink) Write(exchange *Exchange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(exchange)
+}
+
+// Close flushes buffered exchanges and closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flushErr := s.writer.Flush()
+	closeErr := s.file.Close()
+	return errors.Join(flushErr, closeErr)
+}
+
+// Read calls fn with every exchange of a newline-delimited JSON capture, stopping at the first error
+func Read(r io.Reader, fn func(exchange *Exchange) error) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var exchange Exchange
+		if err := decoder.Decode(&exchange); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode captured exchange: %w", err)
+		}
+		if err := fn(&exchange); err != nil {
+			return err
+		}
+	}
+}
+
+// Recorder writes exchanges to a sink in the background so requests never wait on it
+// Exchanges are dropped while the queue is full.
+type Recorder struct {
+	sink    Sink
+	queue   chan *Exchange
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+}
+
+// NewRecorder creates a Recorder buffering up to queueSize exchanges for sink
+func NewRecorder(sink Sink, queueSize int) *Recorder {
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+
+	r := &Recorder{
+		sink:  sink,
+		queue: make(chan *Exchange, queueSize),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record queues an exchange, dropping it when the queue is full or the recorder is closed
+func (r *Recorder) Record(exchange *Exchange) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.queue <- exchange:
+	default:
+		if dropped := r.dropped.Add(1); dropped%1000 == 1 {
+			logger.Warn("Capture queue full, dropping exchanges", zap.Int64("dropped", dropped))
+		}
+	}
+}
+
+// Close writes the queued exchanges and closes the sink
+func (r *Recorder) Close() error {
+	r.once.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.queue)
+		r.mu.Unlock()
+	})
+	<-r.done
+	return r.sink.Close()
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for exchange := range r.queue {
+		if err := r.sink.Write(exchange); err != nil {
+			logger.Warn("Failed to write captured exchange", zap.String("path", exchange.Path), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package certs provides TLS certificates reloaded from disk when they are renewed
+package certs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Reloader serves a certificate and key loaded from PEM files, reloading them when either file changes
+// Renewed certificates are picked up by new connections without restarting the server.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewReloader loads the certificate of certFile and keyFile, checking for changes at most every interval
+// A zero interval loads them once.
+func NewReloader(certFile, keyFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, for use as tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.maybeReload()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// maybeReload reloads the certificate when the interval elapsed and the files changed
+// A renewal that fails to load keeps the previous certificate in use.
+func (r *Reloader) maybeReload() {
+	if r.interval <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	if time.Since(r.checkedAt) < r.interval {
+		r.mu.Unlock()
+		return
+	}
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		logger.Error("Failed to check TLS certificate", zap.String("cert", r.certFile), zap.Error(err))
+		return
+	}
+
+	r.mu.RLock()
+	changed := modTime.After(r.modTime)
+	r.mu.RUnlock()
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		logger.Error("Failed to reload TLS certificate", zap.String("cert", r.certFile), zap.Error(err))
+		return
+	}
+	logger.Info("Reloaded TLS certificate", zap.String("cert", r.certFile))
+}
+
+// load reads the certificate and key files
+func (r *Reloader) load() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+	return nil
+}
+
+// latestModTime returns the latest modification time of the certificate and key files
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
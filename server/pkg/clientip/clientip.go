@@ -0,0 +1,52 @@
+// Package clientip resolves and propagates the IP of the client behind trusted proxies
+package clientip
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers carrying the client IP set by common proxies and CDNs
+const (
+	HeaderForwardedFor   = "X-Forwarded-For"
+	HeaderRealIP         = "X-Real-IP"
+	HeaderCFConnectingIP = "CF-Connecting-IP"
+)
+
+// Configure makes engine read the client IP from headers, in order, on requests from trustedProxies
+// trustedProxies are IPs or CIDRs; headers of other peers are ignored, so clients cannot spoof their
+// IP. Without trusted proxies the peer's address is the client IP.
+func Configure(engine *gin.Engine, trustedProxies, headers []string) error {
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return err
+	}
+	engine.RemoteIPHeaders = headers
+	return nil
+}
+
+// contextKey is the type of the context key holding the client IP
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying ip
+func WithContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromContext returns the client IP carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
+
+// Resolve returns the client IP of the request of c, as stored by the ClientIP middleware or
+// resolved by gin from the engine's trusted proxies
+func Resolve(c *gin.Context) string {
+	if ip := FromContext(c.Request.Context()); ip != "" {
+		return ip
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,37 @@
+package clientip
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	assert.NoError(t, Configure(gin.New(), []string{"10.0.0.1", "10.1.0.0/16", "fd00::/8"}, []string{HeaderForwardedFor}))
+	assert.Error(t, Configure(gin.New(), []string{"not-a-proxy"}, []string{HeaderForwardedFor}))
+}
+
+func TestResolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(ctx context.Context) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		c.Request.RemoteAddr = "203.0.113.7:52100"
+		return c
+	}
+
+	t.Run("Prefers the IP stored in the context", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.1", Resolve(newContext(WithContext(context.Background(), "198.51.100.1"))))
+	})
+
+	t.Run("Falls back to the peer address", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.7", Resolve(newContext(context.Background())))
+	})
+}
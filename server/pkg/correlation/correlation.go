@@ -0,0 +1,257 @@
+// Package correlation carries the identifiers that tie a unit of work to the requests and
+// messages that caused it, across HTTP calls, queues and background jobs
+package correlation
+
+import (
+	"context"
+	"net/http"
+
+	"quizizz.com/pkg/requestid"
+)
+
+// Header and message attribute names used by the carriers
+const (
+	HeaderRequestID     = requestid.Header
+	HeaderCorrelationID = "X-Correlation-ID"
+	HeaderCausationID   = "X-Causation-ID"
+	HeaderUserID        = "X-User-ID"
+	HeaderTenantID      = "X-Tenant-ID"
+	HeaderActorID       = "X-Actor-ID"
+)
+
+// IDs identifies a unit of work and its place in a larger flow
+type IDs struct {
+	// RequestID identifies this unit of work (an HTTP request, a consumed message, a job run)
+	RequestID string
+
+	// CorrelationID is shared by every unit of work in a flow; it starts as the first request ID
+	CorrelationID string
+
+	// CausationID is the request ID of the unit of work that directly caused this one
+	CausationID string
+
+	// UserID is the user on whose behalf the work is done
+	UserID string
+
+	// TenantID is the tenant the work belongs to
+	TenantID string
+
+	// ActorID is the user actually doing the work when it is not UserID, i.e. an administrator
+	// impersonating UserID; empty otherwise
+	ActorID string
+}
+
+// Impersonated reports whether the work is done by an actor impersonating the user
+func (ids IDs) Impersonated() bool {
+	return ids.ActorID != "" && ids.ActorID != ids.UserID
+}
+
+// WithoutIdentity returns ids without the user, tenant and actor, keeping only the IDs that
+// trace the flow; use it before sending IDs to services that must not learn who made a request
+func (ids IDs) WithoutIdentity() IDs {
+	return IDs{RequestID: ids.RequestID, CorrelationID: ids.CorrelationID, CausationID: ids.CausationID}
+}
+
+// contextKey is the type of the context key holding IDs
+type contextKey struct{}
+
+// WithIDs returns a copy of ctx carrying ids
+// The request ID is also stored for packages that only read requestid.FromContext.
+func WithIDs(ctx context.Context, ids IDs) context.Context {
+	if ids.RequestID != "" {
+		ctx = requestid.WithContext(ctx, ids.RequestID)
+	}
+	return context.WithValue(ctx, contextKey{}, ids)
+}
+
+// FromContext returns the IDs carried by ctx
+// The correlation ID defaults to the request ID, so a flow is traceable from its first request.
+func FromContext(ctx context.Context) IDs {
+	if ctx == nil {
+		return IDs{}
+	}
+
+	ids, _ := ctx.Value(contextKey{}).(IDs)
+	if requestID := requestid.FromContext(ctx); requestID != "" {
+		ids.RequestID = requestID
+	}
+	if ids.CorrelationID == "" {
+		ids.CorrelationID = ids.RequestID
+	}
+	return ids
+}
+
+// Caused returns the IDs for asynchronous work triggered by the unit of work in ctx
+// The correlation ID is kept, the current request ID becomes the causation ID, and the
+// request ID is left empty for the consumer to assign when it picks the work up.
+func Caused(ctx context.Context) IDs {
+	ids := FromContext(ctx)
+	if ids.RequestID != "" {
+		ids.CausationID = ids.RequestID
+	}
+	ids.RequestID = ""
+	return ids
+}
+
+// Carrier reads and writes named string attributes of a message
+// It matches the OpenTelemetry TextMapCarrier, so the same adapters serve both.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+	Keys() []string
+}
+
+// fields maps each attribute name to the IDs field it holds
+func (ids *IDs) fields() map[string]*string {
+	return map[string]*string{
+		HeaderRequestID:     &ids.RequestID,
+		HeaderCorrelationID: &ids.CorrelationID,
+		HeaderCausationID:   &ids.CausationID,
+		HeaderUserID:        &ids.UserID,
+		HeaderTenantID:      &ids.TenantID,
+		HeaderActorID:       &ids.ActorID,
+	}
+}
+
+// Inject writes ids to carrier, skipping empty IDs and attributes the carrier already has
+func Inject(ids IDs, carrier Carrier) {
+	for key, value := range ids.fields() {
+		if *value != "" && carrier.Get(key) == "" {
+			carrier.Set(key, *value)
+		}
+	}
+}
+
+// InjectContext writes the IDs carried by ctx to carrier
+// Use it for synchronous calls; enqueue asynchronous work with Inject(Caused(ctx), carrier).
+func InjectContext(ctx context.Context, carrier Carrier) {
+	Inject(FromContext(ctx), carrier)
+}
+
+// Extract reads IDs from carrier
+// Invalid request IDs are dropped; a missing one is generated, since every unit of work needs one.
+func Extract(carrier Carrier) IDs {
+	var ids IDs
+	for key, value := range ids.fields() {
+		*value = carrier.Get(key)
+	}
+
+	if !requestid.Valid(ids.RequestID) {
+		ids.RequestID = requestid.New()
+	}
+	if !requestid.Valid(ids.CorrelationID) {
+		ids.CorrelationID = ids.RequestID
+	}
+	if ids.CausationID != "" && !requestid.Valid(ids.CausationID) {
+		ids.CausationID = ""
+	}
+	return ids
+}
+
+// ExtractContext reads IDs from carrier into a copy of ctx
+func ExtractContext(ctx context.Context, carrier Carrier) context.Context {
+	return WithIDs(ctx, Extract(carrier))
+}
+
+// HeaderCarrier adapts HTTP headers to Carrier
+type HeaderCarrier http.Header
+
+func (hc HeaderCarrier) Get(key string) string {
+	return http.Header(hc).Get(key)
+}
+
+func (hc HeaderCarrier) Set(key, value string) {
+	http.Header(hc).Set(key, value)
+}
+
+func (hc HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for key := range hc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// MapCarrier adapts string maps, such as job metadata or Kafka headers collected into a map, to Carrier
+type MapCarrier map[string]string
+
+func (mc MapCarrier) Get(key string) string {
+	return mc[key]
+}
+
+func (mc MapCarrier) Set(key, value string) {
+	mc[key] = value
+}
+
+func (mc MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for key := range mc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ValuesCarrier adapts Redis stream and hash field maps (as used by XADD and HSET) to Carrier
+type ValuesCarrier map[string]interface{}
+
+func (vc ValuesCarrier) Get(key string) string {
+	switch value := vc[key].(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return ""
+	}
+}
+
+func (vc ValuesCarrier) Set(key, value string) {
+	vc[key] = value
+}
+
+func (vc ValuesCarrier) Keys() []string {
+	keys := make([]string, 0, len(vc))
+	for key := range vc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// KafkaHeader is the key/value shape of Kafka record headers in the common Go clients
+type KafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// KafkaHeaders adapts a slice of Kafka record headers to Carrier
+// Convert to and from the client's header type when producing and consuming.
+type KafkaHeaders struct {
+	Headers *[]KafkaHeader
+}
+
+func (kh KafkaHeaders) Get(key string) string {
+	for _, header := range *kh.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (kh KafkaHeaders) Set(key, value string) {
+	for i, header := range *kh.Headers {
+		if header.Key == key {
+			(*kh.Headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*kh.Headers = append(*kh.Headers, KafkaHeader{Key: key, Value: []byte(value)})
+}
+
+func (kh KafkaHeaders) Keys() []string {
+	keys := make([]string, 0, len(*kh.Headers))
+	for _, header := range *kh.Headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
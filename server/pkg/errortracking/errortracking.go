@@ -0,0 +1,84 @@
+// Package errortracking reports panics, server errors and error logs to an error tracking service
+package errortracking
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of a reported message
+type Level string
+
+// Message severities
+const (
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+	LevelFatal   Level = "fatal"
+)
+
+// Reporter sends errors to an error tracking service
+// Implementations must be safe for concurrent use and must not block the caller on delivery.
+type Reporter interface {
+	// CaptureException reports err, e.g. the cause of a 5xx response
+	CaptureException(err error, scope Scope)
+
+	// CaptureMessage reports a message, e.g. an error log entry
+	CaptureMessage(message string, level Level, scope Scope)
+
+	// CapturePanic reports a recovered panic; it must be called from the deferred function that
+	// recovered it, so the stack trace still includes the panicking frames
+	CapturePanic(recovered interface{}, scope Scope)
+
+	// Flush waits up to timeout for reported errors to be delivered, returning false if some were not
+	Flush(timeout time.Duration) bool
+}
+
+// Nop is a Reporter discarding every error, used when no error tracking service is configured
+type Nop struct{}
+
+// CaptureException discards err
+func (Nop) CaptureException(error, Scope) {}
+
+// CaptureMessage discards the message
+func (Nop) CaptureMessage(string, Level, Scope) {}
+
+// CapturePanic discards the panic
+func (Nop) CapturePanic(interface{}, Scope) {}
+
+// Flush returns true immediately
+func (Nop) Flush(time.Duration) bool { return true }
+
+// holder wraps the default reporter, as atomic.Value needs values of a single concrete type
+type holder struct {
+	Reporter
+}
+
+// defaultReporter holds the reporter used by the package-level functions
+var defaultReporter atomic.Value
+
+// SetDefault sets the reporter used by the package-level functions; nil restores Nop
+func SetDefault(r Reporter) {
+	if r == nil {
+		r = Nop{}
+	}
+	defaultReporter.Store(holder{r})
+}
+
+// Default returns the reporter used by the package-level functions, Nop until SetDefault is called
+func Default() Reporter {
+	if h, ok := defaultReporter.Load().(holder); ok {
+		return h.Reporter
+	}
+	return Nop{}
+}
+
+// CaptureException reports err to the default reporter with the scope of ctx
+func CaptureException(ctx context.Context, err error) {
+	Default().CaptureException(err, ScopeFromContext(ctx))
+}
+
+// CaptureMessage reports a message to the default reporter with the scope of ctx
+func CaptureMessage(ctx context.Context, message string, level Level) {
+	Default().CaptureMessage(message, level, ScopeFromContext(ctx))
+}
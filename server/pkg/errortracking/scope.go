@@ -0,0 +1,57 @@
+package errortracking
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+	"quizizz.com/pkg/correlation"
+)
+
+// Scope describes the request and user an error happened for
+type Scope struct {
+	// Request is the HTTP request being served, if any
+	Request *http.Request
+
+	RequestID     string
+	CorrelationID string
+	TraceID       string
+
+	// UserID and TenantID identify whom the failed work was done for
+	UserID   string
+	TenantID string
+
+	// Tags are indexed key/value pairs errors can be searched by
+	Tags map[string]string
+
+	// Extra is additional unindexed data, e.g. the fields of a log entry
+	Extra map[string]interface{}
+}
+
+// requestKey is the context key holding the HTTP request being served
+type requestKey struct{}
+
+// WithRequest returns a copy of ctx carrying req, so errors reported with it describe the request
+func WithRequest(ctx context.Context, req *http.Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// ScopeFromContext returns the scope of ctx: its request, correlation IDs and trace
+func ScopeFromContext(ctx context.Context) Scope {
+	if ctx == nil {
+		return Scope{}
+	}
+
+	ids := correlation.FromContext(ctx)
+	scope := Scope{
+		RequestID:     ids.RequestID,
+		CorrelationID: ids.CorrelationID,
+		UserID:        ids.UserID,
+		TenantID:      ids.TenantID,
+	}
+	scope.Request, _ = ctx.Value(requestKey{}).(*http.Request)
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		scope.TraceID = spanCtx.TraceID().String()
+	}
+	return scope
+}
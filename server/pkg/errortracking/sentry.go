@@ -0,0 +1,96 @@
+package errortracking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// SentryConfig configures the Sentry reporter
+type SentryConfig struct {
+	// DSN is the Sentry project's client key URL
+	DSN string
+
+	// Environment and Release tag every event
+	Environment string
+	Release     string
+
+	// SampleRate is the fraction of errors sent (0.0 - 1.0); 0 sends them all
+	SampleRate float64
+}
+
+// Sentry reports errors to Sentry
+type Sentry struct {
+	hub *sentry.Hub
+}
+
+// NewSentry creates a Reporter sending errors to the Sentry project of cfg.DSN
+func NewSentry(cfg SentryConfig) (*Sentry, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: cfg.Environment,
+		Release:     cfg.Release,
+		SampleRate:  cfg.SampleRate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sentry client: %w", err)
+	}
+
+	return &Sentry{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// CaptureException reports err with the stack trace of the calling goroutine
+func (s *Sentry) CaptureException(err error, scope Scope) {
+	s.scopedHub(scope).CaptureException(err)
+}
+
+// CaptureMessage reports message at level
+func (s *Sentry) CaptureMessage(message string, level Level, scope Scope) {
+	hub := s.scopedHub(scope)
+	hub.Scope().SetLevel(sentry.Level(level))
+	hub.CaptureMessage(message)
+}
+
+// CapturePanic reports a recovered panic with the stack trace of the calling goroutine
+func (s *Sentry) CapturePanic(recovered interface{}, scope Scope) {
+	ctx := context.Background()
+	if scope.Request != nil {
+		ctx = scope.Request.Context()
+	}
+	s.scopedHub(scope).RecoverWithContext(ctx, recovered)
+}
+
+// Flush waits up to timeout for queued events to be sent
+func (s *Sentry) Flush(timeout time.Duration) bool {
+	return s.hub.Flush(timeout)
+}
+
+// scopedHub returns a hub whose scope carries the request, user and tags of scope
+func (s *Sentry) scopedHub(scope Scope) *sentry.Hub {
+	hub := s.hub.Clone()
+	sentryScope := hub.Scope()
+
+	if scope.Request != nil {
+		sentryScope.SetRequest(scope.Request)
+	}
+	if scope.UserID != "" {
+		sentryScope.SetUser(sentry.User{ID: scope.UserID})
+	}
+	for key, value := range map[string]string{
+		"request_id":     scope.RequestID,
+		"correlation_id": scope.CorrelationID,
+		"trace_id":       scope.TraceID,
+		"tenant_id":      scope.TenantID,
+	} {
+		if value != "" {
+			sentryScope.SetTag(key, value)
+		}
+	}
+	sentryScope.SetTags(scope.Tags)
+	if len(scope.Extra) > 0 {
+		sentryScope.SetContext("details", sentry.Context(scope.Extra))
+	}
+	return hub
+}
@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// DefaultMaxBreakers bounds the number of per-endpoint circuit breakers of a client
+const DefaultMaxBreakers = 100
+
+// breakerKeyContextKey is the context key holding a caller-specified circuit breaker key
+type breakerKeyContextKey struct{}
+
+// WithBreakerKey returns a copy of ctx making requests use the circuit breaker named key
+// Use it to group endpoints that fail together, or to keep IDs in paths from creating a breaker each.
+func WithBreakerKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, breakerKeyContextKey{}, key)
+}
+
+// BreakerKeyByPath keys circuit breakers by request path
+func BreakerKeyByPath(_, path string) string {
+	return path
+}
+
+// BreakerKeyByMethodAndPath keys circuit breakers by request method and path
+func BreakerKeyByMethodAndPath(method, path string) string {
+	return method + " " + path
+}
+
+// breakerRegistry holds the circuit breakers of a client, created on first use with shared settings
+// Once max breakers exist, requests for new keys share the client-wide fallback breaker.
+type breakerRegistry struct {
+	settings gobreaker.Settings
+	max      int
+
+	mu       sync.RWMutex
+	breakers map[string]*gobreaker.CircuitBreaker
+	fallback *gobreaker.CircuitBreaker
+}
+
+// newBreakerRegistry creates a registry whose breakers use settings and are named after settings.Name
+func newBreakerRegistry(settings gobreaker.Settings, max int) *breakerRegistry {
+	if max <= 0 {
+		max = DefaultMaxBreakers
+	}
+
+	return &breakerRegistry{
+		settings: settings,
+		max:      max,
+		breakers: make(map[string]*gobreaker.CircuitBreaker),
+		fallback: gobreaker.NewCircuitBreaker(settings),
+	}
+}
+
+// get returns the breaker for key, creating it if the registry has room
+// An empty key selects the fallback breaker.
+func (r *breakerRegistry) get(key string) *gobreaker.CircuitBreaker {
+	if key == "" {
+		return r.fallback
+	}
+
+	r.mu.RLock()
+	breaker, ok := r.breakers[key]
+	r.mu.RUnlock()
+	if ok {
+		return breaker
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if breaker, ok := r.breakers[key]; ok {
+		return breaker
+	}
+	if len(r.breakers) >= r.max {
+		logger.Warn("Circuit breaker limit reached, using the shared breaker",
+			zap.String("name", r.settings.Name),
+			zap.String("key", key),
+			zap.Int("max", r.max),
+		)
+		return r.fallback
+	}
+
+	settings := r.settings
+	settings.Name = r.settings.Name + ":" + key
+	breaker = gobreaker.NewCircuitBreaker(settings)
+	r.breakers[key] = breaker
+	return breaker
+}
+
+// all returns every breaker of the registry, the fallback first and the others sorted by key
+func (r *breakerRegistry) all() []*gobreaker.CircuitBreaker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.breakers))
+	for key := range r.breakers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	breakers := make([]*gobreaker.CircuitBreaker, 0, len(keys)+1)
+	breakers = append(breakers, r.fallback)
+	for _, key := range keys {
+		breakers = append(breakers, r.breakers[key])
+	}
+	return breakers
+}
+
+// BreakerState describes the state of one circuit breaker
+type BreakerState struct {
+	Name   string
+	State  gobreaker.State
+	Counts gobreaker.Counts
+}
+
+// BreakerStates returns the state of all circuit breakers of the client
+// It returns nil when circuit breaking is disabled.
+func (c *Client) BreakerStates() []BreakerState {
+	if !c.config.CircuitBreaker.Enabled {
+		return nil
+	}
+
+	breakers := c.breakers.all()
+	states := make([]BreakerState, 0, len(breakers))
+	for _, breaker := range breakers {
+		states = append(states, BreakerState{
+			Name:   breaker.Name(),
+			State:  breaker.State(),
+			Counts: breaker.Counts(),
+		})
+	}
+	return states
+}
+
+// breakerFor returns the circuit breaker guarding a request
+func (c *Client) breakerFor(ctx context.Context, method, path string) *gobreaker.CircuitBreaker {
+	if key, ok := ctx.Value(breakerKeyContextKey{}).(string); ok && key != "" {
+		return c.breakers.get(key)
+	}
+
+	if c.config.CircuitBreaker.KeyFunc == nil {
+		return c.breakers.fallback
+	}
+	return c.breakers.get(c.config.CircuitBreaker.KeyFunc(method, path))
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("A failing endpoint opens its own breaker only", func(t *testing.T) {
+		var reports atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/reports" {
+				reports.Add(1)
+				dropConnection(w)
+				return
+			}
+			_, _ = w.Write([]byte("{}"))
+		}, withBreakers)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/reports", nil)
+			assert.Error(t, err)
+		}
+		_, err := client.Get(ctx, "/reports", nil)
+		assert.ErrorIs(t, err, gobreaker.ErrOpenState)
+		assert.Equal(t, int32(2), reports.Load())
+
+		_, err = client.Get(ctx, "/quizzes", nil)
+		assert.NoError(t, err)
+
+		states := make(map[string]gobreaker.State)
+		for _, state := range client.BreakerStates() {
+			states[state.Name] = state.State
+		}
+		assert.Equal(t, map[string]gobreaker.State{
+			"quizzes":          gobreaker.StateClosed,
+			"quizzes:/quizzes": gobreaker.StateClosed,
+			"quizzes:/reports": gobreaker.StateOpen,
+		}, states)
+	})
+
+	t.Run("Requests with the same breaker key share a breaker", func(t *testing.T) {
+		var healthy atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/users/u3" {
+				healthy.Add(1)
+				_, _ = w.Write([]byte("{}"))
+				return
+			}
+			dropConnection(w)
+		}, withBreakers)
+
+		_, err := client.Request(ctx, http.MethodGet, "/users/u1", nil, WithBreaker("users"))
+		assert.Error(t, err)
+		_, err = client.Get(WithBreakerKey(ctx, "users"), "/users/u2", nil)
+		assert.Error(t, err)
+
+		_, err = client.Request(ctx, http.MethodGet, "/users/u3", nil, WithBreaker("users"))
+		assert.ErrorIs(t, err, gobreaker.ErrOpenState)
+		assert.Equal(t, int32(0), healthy.Load())
+	})
+
+	t.Run("Disabled breakers report no state", func(t *testing.T) {
+		client := newTestServer(t, http.NotFound)
+		assert.Nil(t, client.BreakerStates())
+	})
+}
+
+func TestBreakerRegistry(t *testing.T) {
+	t.Run("Creates one breaker per key", func(t *testing.T) {
+		registry := newBreakerRegistry(gobreaker.Settings{Name: "quizzes"}, 10)
+
+		breaker := registry.get("/a")
+		assert.Same(t, breaker, registry.get("/a"))
+		assert.NotSame(t, breaker, registry.get("/b"))
+		assert.Equal(t, "quizzes:/a", breaker.Name())
+		assert.Same(t, registry.fallback, registry.get(""))
+	})
+
+	t.Run("Falls back to the shared breaker beyond max", func(t *testing.T) {
+		registry := newBreakerRegistry(gobreaker.Settings{Name: "quizzes"}, 2)
+		registry.get("/a")
+		registry.get("/b")
+
+		assert.Same(t, registry.fallback, registry.get("/c"))
+		require.Len(t, registry.all(), 3)
+		assert.Equal(t, "quizzes", registry.all()[0].Name())
+	})
+}
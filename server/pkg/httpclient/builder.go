@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RequestBuilder assembles a request step by step
+//
+//	var user User
+//	err := client.NewRequest().
+//		Method(http.MethodGet).
+//		Path("/users/{id}", id).
+//		Query("expand", "profile").
+//		Into(ctx, &user)
+type RequestBuilder struct {
+	client *Client
+	method string
+	path   string
+	body   interface{}
+	opts   []RequestOption
+	err    error
+}
+
+// NewRequest starts building a GET request to the client's base URL
+func (c *Client) NewRequest() *RequestBuilder {
+	return &RequestBuilder{
+		client: c,
+		method: http.MethodGet,
+	}
+}
+
+// Method sets the HTTP method
+func (b *RequestBuilder) Method(method string) *RequestBuilder {
+	b.method = method
+	return b
+}
+
+// Path sets the request path, replacing each {placeholder} in order with the
+// path-escaped value of the corresponding parameter; values of ".", ".." or holding a slash fail
+// the request
+func (b *RequestBuilder) Path(template string, params ...interface{}) *RequestBuilder {
+	path, err := expandPath(template, params)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.path = path
+	return b
+}
+
+// Query adds values for a query parameter
+func (b *RequestBuilder) Query(key string, values ...string) *RequestBuilder {
+	b.opts = append(b.opts, WithQuery(key, values...))
+	return b
+}
+
+// Header sets a request header
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.opts = append(b.opts, WithHeader(key, value))
+	return b
+}
+
+// JSON sets the body, which is sent JSON-encoded
+func (b *RequestBuilder) JSON(body interface{}) *RequestBuilder {
+	b.body = body
+	return b
+}
+
+// Options adds request options such as WithTimeout or WithoutRetry
+func (b *RequestBuilder) Options(opts ...RequestOption) *RequestBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Do sends the request
+func (b *RequestBuilder) Do(ctx context.Context) (*Response, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.client.Request(ctx, b.method, b.path, b.body, b.opts...)
+}
+
+// Into sends the request and decodes a successful JSON response into target
+// Error responses are returned as *StatusError without decoding.
+func (b *RequestBuilder) Into(ctx context.Context, target interface{}) error {
+	resp, err := b.Do(ctx)
+	if err != nil {
+		return err
+	}
+	return b.client.decodeJSON(resp, target)
+}
+
+// expandPath replaces the {placeholders} of template with params, in order
+// Values that are dot segments or hold slashes are rejected, so that a parameter cannot leave the
+// path of its route.
+func expandPath(template string, params []interface{}) (string, error) {
+	var b strings.Builder
+	rest := template
+	used := 0
+
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in path %q", template)
+		}
+
+		if used >= len(params) {
+			return "", fmt.Errorf("missing value for placeholder %s in path %q", rest[start:start+end+1], template)
+		}
+
+		value := fmt.Sprint(params[used])
+		if value == "." || value == ".." || strings.Contains(value, "/") {
+			return "", fmt.Errorf("invalid value %q for placeholder %s in path %q", value, rest[start:start+end+1], template)
+		}
+
+		b.WriteString(rest[:start])
+		b.WriteString(url.PathEscape(value))
+		used++
+		rest = rest[start+end+1:]
+	}
+
+	if used != len(params) {
+		return "", fmt.Errorf("path %q has %d placeholders but %d values were given", template, used, len(params))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/pkg/httpclient/httpclienttest"
+)
+
+func TestExpandPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   []interface{}
+		want     string
+		wantErr  bool
+	}{
+		{name: "Replaces placeholders in order", template: "/users/{id}/posts/{post}", params: []interface{}{"u1", 42}, want: "/users/u1/posts/42"},
+		{name: "Escapes values", template: "/files/{name}", params: []interface{}{"a b?c"}, want: "/files/a%20b%3Fc"},
+		{name: "Rejects parent segments", template: "/users/{id}", params: []interface{}{".."}, wantErr: true},
+		{name: "Rejects current segments", template: "/users/{id}", params: []interface{}{"."}, wantErr: true},
+		{name: "Rejects slashes", template: "/users/{id}", params: []interface{}{"../admin/stats"}, wantErr: true},
+		{name: "Keeps dots within values", template: "/files/{name}", params: []interface{}{"..config"}, want: "/files/..config"},
+		{name: "Rejects missing values", template: "/users/{id}", wantErr: true},
+		{name: "Rejects extra values", template: "/users", params: []interface{}{"u1"}, wantErr: true},
+		{name: "Rejects unterminated placeholders", template: "/users/{id", params: []interface{}{"u1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPath(tt.template, tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_CreateURL(t *testing.T) {
+	client := newTestClient(t, "https://api.example.com/api", httpclienttest.NewMockTransport())
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: "https://api.example.com/api"},
+		{path: "/users/u1", want: "https://api.example.com/api/users/u1"},
+		{path: "users?limit=10", want: "https://api.example.com/api/users?limit=10"},
+		{path: "/files/a%2Fb", want: "https://api.example.com/api/files/a%2Fb"},
+		{path: "/users/%2E%2E/admin", want: "https://api.example.com/api/users/%2E%2E/admin"},
+		{path: "https://other.example.com/x", want: "https://other.example.com/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, client.createURL(tt.path))
+		})
+	}
+}
+
+func TestRequestBuilder_PathTraversal(t *testing.T) {
+	ctx := context.Background()
+	transport := httpclienttest.NewMockTransport()
+	transport.On(http.MethodGet, "").Respond(http.StatusOK, `{}`)
+	client := newTestClient(t, "https://api.example.com/api", transport)
+
+	_, err := client.NewRequest().Path("/users/{id}", "../admin/stats").Do(ctx)
+	assert.Error(t, err)
+	_, err = client.NewRequest().Path("/users/{id}/stats", "..").Do(ctx)
+	assert.Error(t, err)
+	assert.Empty(t, transport.Calls(), "no request leaves its route")
+
+	_, err = client.NewRequest().Path("/users/{id}", "u1").Do(ctx)
+	require.NoError(t, err)
+	transport.AssertCalled(t, http.MethodGet, "/api/users/u1")
+}
@@ -0,0 +1,400 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// CacheConfig enables caching GET responses according to their Cache-Control headers
+type CacheConfig struct {
+	// Store holds cached responses (NewMemoryCacheStore or NewRedisCacheStore)
+	Store CacheStore
+
+	// StaleRetention is how long expired entries are kept for revalidation with ETag/Last-Modified
+	StaleRetention time.Duration
+}
+
+// CachedResponse is a stored response
+type CachedResponse struct {
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+	StoredAt  time.Time   `json:"storedAt"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+
+	// Vary holds the values the request had for the headers named by the response's Vary header
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// fresh reports whether the response may be used without revalidation
+func (r *CachedResponse) fresh(now time.Time) bool {
+	return now.Before(r.ExpiresAt)
+}
+
+// matches reports whether req has the values of the headers the response varies by
+func (r *CachedResponse) matches(req *http.Request) bool {
+	for name, value := range r.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// CacheStore persists cached responses
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool)
+	Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+}
+
+// Cache returns a middleware serving GET responses from cfg.Store while they are fresh and
+// revalidating stale ones with If-None-Match / If-Modified-Since
+// Responses marked no-store, and requests sent with Cache-Control: no-cache or no-store, bypass the cache.
+// The cache is shared by every caller of the client, so private responses are never stored, and
+// a response is only reused for requests with the same values of the headers it varies by
+// (e.g. the X-User-ID injected from the request context).
+func Cache(cfg CacheConfig) Middleware {
+	if cfg.StaleRetention <= 0 {
+		cfg.StaleRetention = 24 * time.Hour
+	}
+	requests, err := otel.Meter("httpclient").Int64Counter("httpclient.cache.requests",
+		metric.WithDescription("Number of cacheable requests by result (hit, miss, revalidated)"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient cache counter", zap.Error(err))
+	}
+
+	record := func(ctx context.Context, host, result string) {
+		if requests != nil {
+			requests.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("host", host),
+				attribute.String("result", result),
+			))
+		}
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requestDirectives := parseCacheControl(req.Header.Get("Cache-Control"))
+			if req.Method != http.MethodGet || requestDirectives.has("no-store") {
+				return next.RoundTrip(req)
+			}
+
+			ctx := req.Context()
+			key := cacheKey(req)
+			now := time.Now()
+
+			cached, ok := cfg.Store.Get(ctx, key)
+			if ok && !cached.matches(req) {
+				// The entry belongs to another variant, whose validators do not apply either
+				cached, ok = nil, false
+			}
+			if ok && cached.fresh(now) && !requestDirectives.has("no-cache") {
+				record(ctx, req.URL.Host, "hit")
+				return cached.response(req), nil
+			}
+
+			// Revalidate a stale entry instead of downloading it again
+			if ok {
+				req = req.Clone(ctx)
+				if etag := cached.Header.Get("ETag"); etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+					req.Header.Set("If-Modified-Since", lastModified)
+				}
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok && resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				record(ctx, req.URL.Host, "revalidated")
+
+				// The 304 carries the current freshness information
+				for _, name := range []string{"Cache-Control", "Expires", "ETag", "Last-Modified", "Date"} {
+					if value := resp.Header.Get(name); value != "" {
+						cached.Header.Set(name, value)
+					}
+				}
+				cached.StoredAt = now
+				cached.ExpiresAt = now.Add(freshness(cached.Header, now))
+				cfg.Store.Set(ctx, key, cached, time.Until(cached.ExpiresAt)+cfg.StaleRetention)
+				return cached.response(req), nil
+			}
+
+			record(ctx, req.URL.Host, "miss")
+			return storeResponse(ctx, cfg, key, req, resp, now)
+		})
+	}
+}
+
+// storeResponse caches resp when its status and directives allow it and returns an unread copy
+func storeResponse(ctx context.Context, cfg CacheConfig, key string, req *http.Request, resp *http.Response, now time.Time) (*http.Response, error) {
+	directives := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if resp.StatusCode != http.StatusOK || directives.has("no-store") || directives.has("private") {
+		return resp, nil
+	}
+	vary, ok := varyValues(req, resp.Header)
+	if !ok {
+		return resp, nil
+	}
+
+	ttl := freshness(resp.Header, now)
+	revalidatable := resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+	if ttl <= 0 && !revalidatable {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	cfg.Store.Set(ctx, key, &CachedResponse{
+		Status:    resp.StatusCode,
+		Header:    resp.Header.Clone(),
+		Body:      body,
+		StoredAt:  now,
+		ExpiresAt: now.Add(ttl),
+		Vary:      vary,
+	}, ttl+cfg.StaleRetention)
+	return resp, nil
+}
+
+// varyValues returns the values req has for the headers named by the Vary header of a response,
+// or false when the response varies by something other than request headers (Vary: *)
+func varyValues(req *http.Request, header http.Header) (map[string]string, bool) {
+	var values map[string]string
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			switch name {
+			case "":
+				continue
+			case "*":
+				return nil, false
+			}
+			if values == nil {
+				values = make(map[string]string)
+			}
+			values[name] = req.Header.Get(name)
+		}
+	}
+	return values, true
+}
+
+// response builds an HTTP response for req from the cached entry
+func (r *CachedResponse) response(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(time.Since(r.StoredAt).Seconds())))
+	return &http.Response{
+		Status:        strconv.Itoa(r.Status) + " " + http.StatusText(r.Status),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// cacheDirectives are the parsed directives of a Cache-Control header
+type cacheDirectives map[string]string
+
+// parseCacheControl parses a Cache-Control header
+func parseCacheControl(header string) cacheDirectives {
+	directives := make(cacheDirectives)
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+func (d cacheDirectives) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// freshness returns how long a response may be used without revalidation
+func freshness(header http.Header, now time.Time) time.Duration {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if directives.has("no-cache") || directives.has("no-store") {
+		return 0
+	}
+
+	if maxAge, err := strconv.Atoi(directives["max-age"]); err == nil {
+		age, _ := strconv.Atoi(header.Get("Age"))
+		return time.Duration(maxAge-age) * time.Second
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		if at, err := http.ParseTime(expires); err == nil {
+			return at.Sub(now)
+		}
+	}
+	return 0
+}
+
+// cacheKey identifies a cached response by URL, Accept header and credentials
+// Credentials are hashed so responses for different callers never mix.
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Header.Get("Accept")))
+	hash.Write([]byte{0})
+	hash.Write([]byte(req.Header.Get("Authorization")))
+	return req.URL.String() + "#" + hex.EncodeToString(hash.Sum(nil)[:12])
+}
+
+// MemoryCacheStore keeps cached responses in process memory
+// When full, expired entries are dropped first, then the entry closest to expiry.
+type MemoryCacheStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+// memoryCacheEntry is a cached response and the time it leaves the store
+type memoryCacheEntry struct {
+	resp     *CachedResponse
+	removeAt time.Time
+}
+
+// NewMemoryCacheStore creates an in-memory store holding up to maxEntries responses
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get implements CacheStore
+func (s *MemoryCacheStore) Get(_ context.Context, key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.removeAt) {
+		delete(s.entries, key)
+		return nil, false
+	}
+
+	copied := *entry.resp
+	copied.Header = entry.resp.Header.Clone()
+	return &copied, true
+}
+
+// Set implements CacheStore
+func (s *MemoryCacheStore) Set(_ context.Context, key string, resp *CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = memoryCacheEntry{resp: resp, removeAt: time.Now().Add(ttl)}
+}
+
+// Delete implements CacheStore
+func (s *MemoryCacheStore) Delete(_ context.Context, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// evict makes room for one entry; callers hold mu
+func (s *MemoryCacheStore) evict() {
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range s.entries {
+		if now.After(entry.removeAt) {
+			delete(s.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.removeAt.Before(oldest) {
+			oldestKey, oldest = key, entry.removeAt
+		}
+	}
+	if len(s.entries) >= s.maxEntries && oldestKey != "" {
+		delete(s.entries, oldestKey)
+	}
+}
+
+// RedisCacheStore keeps cached responses in Redis, shared by all instances of a service
+type RedisCacheStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisCacheStore creates a store keeping entries under prefix
+func NewRedisCacheStore(client redis.Cmdable, prefix string) *RedisCacheStore {
+	return &RedisCacheStore{client: client, prefix: prefix}
+}
+
+// Get implements CacheStore, treating Redis errors as misses
+func (s *RedisCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool) {
+	raw, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.WarnCtx(ctx, "Failed to read httpclient cache", zap.Error(err))
+		}
+		return nil, false
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// Set implements CacheStore, logging instead of failing on errors
+func (s *RedisCacheStore) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	if err := s.client.Set(ctx, s.prefix+key, raw, ttl).Err(); err != nil {
+		logger.WarnCtx(ctx, "Failed to write httpclient cache", zap.Error(err))
+	}
+}
+
+// Delete implements CacheStore
+func (s *RedisCacheStore) Delete(ctx context.Context, key string) {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		logger.WarnCtx(ctx, "Failed to delete httpclient cache entry", zap.Error(err))
+	}
+}
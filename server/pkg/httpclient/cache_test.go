@@ -0,0 +1,238 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/pkg/httpclient/httpclienttest"
+)
+
+// newCachingClient creates a test client caching responses in memory
+func newCachingClient(t *testing.T, transport http.RoundTripper) *Client {
+	return newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+		cfg.Cache = &CacheConfig{Store: NewMemoryCacheStore(10)}
+	})
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Serves fresh responses from the cache", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Body:   []byte(`{"quizzes":[]}`),
+		})
+		client := newCachingClient(t, transport)
+
+		for i := 0; i < 3; i++ {
+			resp, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, `{"quizzes":[]}`, string(resp.Body))
+		}
+		assert.Equal(t, 1, transport.CallCount(http.MethodGet, "/quizzes"))
+	})
+
+	t.Run("Does not cache no-store responses", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"no-store, max-age=60"}},
+		})
+		client := newCachingClient(t, transport)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, transport.CallCount(http.MethodGet, "/quizzes"))
+	})
+
+	t.Run("Does not cache errors or other methods", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusServiceUnavailable,
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+		})
+		transport.On(http.MethodPost, "/quizzes").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+		})
+		client := newCachingClient(t, transport)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+			_, err = client.Post(ctx, "/quizzes", map[string]string{}, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, transport.CallCount(http.MethodGet, "/quizzes"))
+		assert.Equal(t, 2, transport.CallCount(http.MethodPost, "/quizzes"))
+	})
+
+	t.Run("Requests with no-cache bypass fresh entries", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+		})
+		client := newCachingClient(t, transport)
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		_, err = client.Get(ctx, "/quizzes", map[string]string{"Cache-Control": "no-cache"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, transport.CallCount(http.MethodGet, "/quizzes"))
+	})
+
+	t.Run("Revalidates stale entries", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").Once().RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}},
+			Body:   []byte(`{"version":1}`),
+		})
+		transport.On(http.MethodGet, "/quizzes").WithHeader("If-None-Match", `"v1"`).Respond(http.StatusNotModified, "")
+		client := newCachingClient(t, transport)
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"version":1}`, string(resp.Body))
+		assert.Equal(t, 2, transport.CallCount(http.MethodGet, "/quizzes"))
+	})
+
+	t.Run("Responses for other credentials are not shared", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/me").WithHeader("Authorization", "Bearer a").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Body:   []byte(`{"user":"a"}`),
+		})
+		transport.On(http.MethodGet, "/me").WithHeader("Authorization", "Bearer b").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Body:   []byte(`{"user":"b"}`),
+		})
+		client := newCachingClient(t, transport)
+
+		first, err := client.Get(ctx, "/me", map[string]string{"Authorization": "Bearer a"})
+		require.NoError(t, err)
+		second, err := client.Get(ctx, "/me", map[string]string{"Authorization": "Bearer b"})
+		require.NoError(t, err)
+
+		assert.Equal(t, `{"user":"a"}`, string(first.Body))
+		assert.Equal(t, `{"user":"b"}`, string(second.Body))
+	})
+
+	t.Run("Responses are only reused for the values of the headers they vary by", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		for _, user := range []string{"a", "b"} {
+			transport.On(http.MethodGet, "/me").WithHeader("X-User-ID", user).RespondWith(httpclienttest.MockResponse{
+				Status: http.StatusOK,
+				Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept-Encoding, X-User-ID"}},
+				Body:   []byte(`{"user":"` + user + `"}`),
+			})
+		}
+		client := newCachingClient(t, transport)
+
+		for _, user := range []string{"a", "b", "b", "a"} {
+			resp, err := client.Get(ctx, "/me", map[string]string{"X-User-ID": user})
+			require.NoError(t, err)
+			assert.Equal(t, `{"user":"`+user+`"}`, string(resp.Body))
+		}
+		assert.Equal(t, 3, transport.CallCount(http.MethodGet, "/me"), "only the last request matches the stored variant")
+	})
+
+	t.Run("Does not cache private responses or responses varying by anything", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/me").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"private, max-age=60"}},
+		})
+		transport.On(http.MethodGet, "/quizzes").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}},
+		})
+		client := newCachingClient(t, transport)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/me", nil)
+			require.NoError(t, err)
+			_, err = client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, transport.CallCount(http.MethodGet, "/me"))
+		assert.Equal(t, 2, transport.CallCount(http.MethodGet, "/quizzes"))
+	})
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "max-age", header: http.Header{"Cache-Control": {"public, max-age=60"}}, want: time.Minute},
+		{name: "max-age minus age", header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"15"}}, want: 45 * time.Second},
+		{name: "Expires", header: http.Header{"Expires": {now.Add(time.Hour).Format(http.TimeFormat)}}, want: time.Hour},
+		{name: "max-age over Expires", header: http.Header{"Cache-Control": {"max-age=5"}, "Expires": {now.Add(time.Hour).Format(http.TimeFormat)}}, want: 5 * time.Second},
+		{name: "no-cache", header: http.Header{"Cache-Control": {"no-cache, max-age=60"}}, want: 0},
+		{name: "No directives", header: http.Header{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, freshness(tt.header, now))
+		})
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Evicts the entry closest to expiry when full", func(t *testing.T) {
+		store := NewMemoryCacheStore(2)
+		store.Set(ctx, "short", &CachedResponse{Status: http.StatusOK}, time.Minute)
+		store.Set(ctx, "long", &CachedResponse{Status: http.StatusOK}, time.Hour)
+		store.Set(ctx, "new", &CachedResponse{Status: http.StatusOK}, time.Hour)
+
+		_, ok := store.Get(ctx, "short")
+		assert.False(t, ok)
+		_, ok = store.Get(ctx, "long")
+		assert.True(t, ok)
+		_, ok = store.Get(ctx, "new")
+		assert.True(t, ok)
+	})
+
+	t.Run("Expired entries are misses", func(t *testing.T) {
+		store := NewMemoryCacheStore(2)
+		store.Set(ctx, "gone", &CachedResponse{Status: http.StatusOK}, -time.Second)
+
+		_, ok := store.Get(ctx, "gone")
+		assert.False(t, ok)
+	})
+
+	t.Run("Returned entries are copies", func(t *testing.T) {
+		store := NewMemoryCacheStore(2)
+		store.Set(ctx, "key", &CachedResponse{Status: http.StatusOK, Header: http.Header{"Etag": {`"v1"`}}}, time.Hour)
+
+		entry, ok := store.Get(ctx, "key")
+		require.True(t, ok)
+		entry.Header.Set("ETag", `"v2"`)
+
+		entry, _ = store.Get(ctx, "key")
+		assert.Equal(t, `"v1"`, entry.Header.Get("ETag"))
+	})
+}
@@ -4,12 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
-	"path"
 	"strings"
 	"time"
 
@@ -23,20 +23,41 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/correlation"
+	"quizizz.com/pkg/requestid"
 )
 
 // HeaderRequestID is the header name for request ID
-const HeaderRequestID = "X-Request-ID"
+const HeaderRequestID = requestid.Header
 
 // Client is a robust HTTP client with enhanced features
 type Client struct {
-	config       *Config
-	httpClient   *http.Client
+	config     *Config
+	httpClient *http.Client
+	// streamClient sends requests whose body is read by the caller, without RequestTimeout
+	streamClient *http.Client
 	baseURL      *url.URL
-	breaker      *gobreaker.CircuitBreaker
-	retryBackOff backoff.BackOff
+	breakers     *breakerRegistry
 	serviceName  string
 	tracer       trace.Tracer
+	metrics      *clientMetrics
+	budget       *retryBudget
+	balancer     *loadBalancer
+	cancel       context.CancelFunc
+}
+
+// API is the request interface of Client
+// Code calling downstream services can depend on it instead of *Client so tests can substitute a mock.
+type API interface {
+	Get(ctx context.Context, urlPath string, headers map[string]string) (*Response, error)
+	Post(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*Response, error)
+	Put(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*Response, error)
+	Delete(ctx context.Context, urlPath string, headers map[string]string) (*Response, error)
+	Patch(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*Response, error)
+	GetJSON(ctx context.Context, urlPath string, headers map[string]string, target interface{}) error
+	PostJSON(ctx context.Context, urlPath string, body, target interface{}, headers map[string]string) error
+	PutJSON(ctx context.Context, urlPath string, body, target interface{}, headers map[string]string) error
+	Request(ctx context.Context, method, urlPath string, body interface{}, opts ...RequestOption) (*Response, error)
 }
 
 // Response wraps an HTTP response
@@ -55,10 +76,65 @@ func New(cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
 
-	transport := createTransport(cfg)
+	// Background work such as certificate reloading stops when the client is closed
+	lifetime, cancel := context.WithCancel(context.Background())
+
+	var transport http.RoundTripper
+	if cfg.RoundTripper != nil {
+		transport = cfg.RoundTripper
+	} else {
+		transport, err = createTransport(lifetime, cfg)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	// Authentication runs first and signing last, so the signature covers every header
+	middlewares := cfg.Middlewares
+	if cfg.OAuth2 != nil {
+		middlewares = append([]Middleware{OAuth2(NewOAuth2TokenSource(*cfg.OAuth2, transport))}, middlewares...)
+	}
+	// Endpoints are picked before signing, since signatures cover the host
+	var balancer *loadBalancer
+	if cfg.LoadBalancer != nil {
+		balancer, err = newLoadBalancer(*cfg.LoadBalancer)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		middlewares = append(middlewares[:len(middlewares):len(middlewares)], balancer.middleware())
+	}
+	if cfg.Signer != nil {
+		middlewares = append(middlewares[:len(middlewares):len(middlewares)], Sign(cfg.Signer))
+	}
+	// Debug logging sees requests exactly as they are sent
+	if cfg.Debug {
+		middlewares = append(middlewares[:len(middlewares):len(middlewares)], newExchangeLogger(cfg.Logging).middleware())
+	}
+	// The cache runs before everything else so hits never reach authentication or the network,
+	// followed by the limiter so queued requests hold no connection
+	if cfg.Concurrency != nil && cfg.Concurrency.MaxConcurrent > 0 {
+		middlewares = append([]Middleware{newLimiter(*cfg.Concurrency).middleware()}, middlewares...)
+	}
+	if cfg.Cache != nil && cfg.Cache.Store != nil {
+		middlewares = append([]Middleware{Cache(*cfg.Cache)}, middlewares...)
+	}
+
+	jar := cfg.CookieJar
+	if jar == nil && cfg.Cookies {
+		jar, err = newCookieJar()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+		}
+	}
+
 	httpClient := &http.Client{
-		Transport: transport,
-		Timeout:   cfg.Timeouts.RequestTimeout,
+		Transport:     chainMiddleware(transport, middlewares),
+		Timeout:       cfg.Timeouts.RequestTimeout,
+		Jar:           jar,
+		CheckRedirect: checkRedirect(cfg.Redirect),
 	}
 
 	var cbSettings gobreaker.Settings
@@ -79,17 +155,9 @@ func New(cfg *Config) (*Client, error) {
 		}
 	}
 
-	var retryBackOff backoff.BackOff
-	if cfg.Retry.Enabled {
-		exponentialBackOff := backoff.NewExponentialBackOff()
-		exponentialBackOff.InitialInterval = cfg.Retry.InitialInterval
-		exponentialBackOff.MaxInterval = cfg.Retry.MaxInterval
-		exponentialBackOff.MaxElapsedTime = cfg.Retry.MaxElapsedTime
-		exponentialBackOff.Multiplier = cfg.Retry.Multiplier
-		retryBackOff = backoff.WithMaxRetries(exponentialBackOff, uint64(cfg.Retry.MaxRetries))
-	} else {
-		retryBackOff = &backoff.StopBackOff{}
-	}
+	// RequestTimeout also bounds reading the body, which streamed responses leave to the caller
+	streamClient := *httpClient
+	streamClient.Timeout = 0
 
 	// Get tracer
 	tracer := otel.GetTracerProvider().Tracer(cfg.ServiceName)
@@ -97,26 +165,50 @@ func New(cfg *Config) (*Client, error) {
 	client := &Client{
 		config:       cfg,
 		httpClient:   httpClient,
+		streamClient: &streamClient,
 		baseURL:      baseURL,
-		breaker:      gobreaker.NewCircuitBreaker(cbSettings),
-		retryBackOff: retryBackOff,
+		breakers:     newBreakerRegistry(cbSettings, cfg.CircuitBreaker.MaxBreakers),
 		serviceName:  cfg.ServiceName,
 		tracer:       tracer,
+		budget:       newRetryBudget(cfg.Retry.Budget),
+		balancer:     balancer,
+		cancel:       cancel,
 	}
+	client.metrics = newClientMetrics(client)
 
 	return client, nil
 }
 
+// newRetryBackOff creates the backoff schedule of a retry policy
+// Backoffs are stateful, so each call gets its own.
+func newRetryBackOff(ctx context.Context, retry RetryConfig) backoff.BackOff {
+	if !retry.Enabled {
+		return &backoff.StopBackOff{}
+	}
+
+	exponentialBackOff := backoff.NewExponentialBackOff()
+	exponentialBackOff.InitialInterval = retry.InitialInterval
+	exponentialBackOff.MaxInterval = retry.MaxInterval
+	exponentialBackOff.MaxElapsedTime = retry.MaxElapsedTime
+	exponentialBackOff.Multiplier = retry.Multiplier
+	return backoff.WithContext(backoff.WithMaxRetries(exponentialBackOff, uint64(retry.MaxRetries)), ctx)
+}
+
 // createTransport creates an HTTP transport with configured settings
-func createTransport(cfg *Config) *http.Transport {
+func createTransport(ctx context.Context, cfg *Config) (*http.Transport, error) {
 	dialer := &net.Dialer{
 		Timeout:   cfg.Timeouts.DialTimeout,
 		KeepAlive: cfg.Timeouts.DialKeepAlive,
 	}
 
+	dialContext := dialer.DialContext
+	if cfg.Transport.DNSCacheTTL > 0 {
+		dialContext = newDNSCache(cfg.Transport.DNSCacheTTL, dialer).DialContext
+	}
+
 	transport := &http.Transport{
 		Proxy:                 getProxyFunc(cfg),
-		DialContext:           dialer.DialContext,
+		DialContext:           dialContext,
 		MaxIdleConns:          cfg.Transport.MaxIdleConns,
 		MaxIdleConnsPerHost:   cfg.Transport.MaxIdleConnsPerHost,
 		MaxConnsPerHost:       cfg.Transport.MaxConnsPerHost,
@@ -129,7 +221,29 @@ func createTransport(cfg *Config) *http.Transport {
 		ForceAttemptHTTP2:     true,
 	}
 
-	return transport
+	if cfg.Transport.TLS != nil {
+		tlsConfig, err := buildTLSConfig(ctx, cfg.Transport.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport, nil
+}
+
+// newRequestID generates a request ID with the configured generator
+func (c *Client) newRequestID(ctx context.Context) string {
+	if c.config.IDGenerator != nil {
+		return c.config.IDGenerator(ctx)
+	}
+	return RandomIDs(ctx)
+}
+
+// Close stops the client's background work and closes idle connections
+func (c *Client) Close() {
+	c.cancel()
+	c.httpClient.CloseIdleConnections()
 }
 
 // getProxyFunc returns a proxy function if a proxy URL is configured
@@ -149,65 +263,78 @@ func getProxyFunc(cfg *Config) func(*http.Request) (*url.URL, error) {
 
 // Get performs a GET request
 func (c *Client) Get(ctx context.Context, urlPath string, headers map[string]string) (*Response, error) {
-	return c.Request(ctx, http.MethodGet, urlPath, nil, headers)
+	return c.Request(ctx, http.MethodGet, urlPath, nil, WithHeaders(headers))
 }
 
 // Post performs a POST request
 func (c *Client) Post(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*Response, error) {
-	return c.Request(ctx, http.MethodPost, urlPath, body, headers)
+	return c.Request(ctx, http.MethodPost, urlPath, body, WithHeaders(headers))
 }
 
 // Put performs a PUT request
 func (c *Client) Put(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*Response, error) {
-	return c.Request(ctx, http.MethodPut, urlPath, body, headers)
+	return c.Request(ctx, http.MethodPut, urlPath, body, WithHeaders(headers))
 }
 
 // Delete performs a DELETE request
 func (c *Client) Delete(ctx context.Context, urlPath string, headers map[string]string) (*Response, error) {
-	return c.Request(ctx, http.MethodDelete, urlPath, nil, headers)
+	return c.Request(ctx, http.MethodDelete, urlPath, nil, WithHeaders(headers))
 }
 
 // Patch performs a PATCH request
 func (c *Client) Patch(ctx context.Context, urlPath string, body interface{}, headers map[string]string) (*Response, error) {
-	return c.Request(ctx, http.MethodPatch, urlPath, body, headers)
+	return c.Request(ctx, http.MethodPatch, urlPath, body, WithHeaders(headers))
 }
 
 // GetJSON performs a GET request and unmarshals the response into the given target
+// Error responses are returned as *StatusError and never unmarshalled.
 func (c *Client) GetJSON(ctx context.Context, urlPath string, headers map[string]string, target interface{}) error {
 	resp, err := c.Get(ctx, urlPath, headers)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(resp.Body, target)
+	return c.decodeJSON(resp, target)
 }
 
 // PostJSON performs a POST request and unmarshals the response into the given target
+// Error responses are returned as *StatusError and never unmarshalled.
 func (c *Client) PostJSON(ctx context.Context, urlPath string, body, target interface{}, headers map[string]string) error {
 	resp, err := c.Post(ctx, urlPath, body, headers)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(resp.Body, target)
+	return c.decodeJSON(resp, target)
 }
 
 // PutJSON performs a PUT request and unmarshals the response into the given target
+// Error responses are returned as *StatusError and never unmarshalled.
 func (c *Client) PutJSON(ctx context.Context, urlPath string, body, target interface{}, headers map[string]string) error {
 	resp, err := c.Put(ctx, urlPath, body, headers)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(resp.Body, target)
+	return c.decodeJSON(resp, target)
 }
 
 // Request performs an HTTP request with retries and circuit breaking
-func (c *Client) Request(ctx context.Context, method, urlPath string, body interface{}, headers map[string]string) (*Response, error) {
-	// Ensure we have headers map initialized
-	if headers == nil {
-		headers = make(map[string]string)
-	}
+// Options override the client configuration for this call only.
+func (c *Client) Request(ctx context.Context, method, urlPath string, body interface{}, opts ...RequestOption) (*Response, error) {
+	options := newRequestOptions(opts)
+	ctx, cancel := options.context(ctx)
+	defer cancel()
+	retry := options.retryPolicy(method, c.config.Retry)
+
+	// A streamed body can only be sent again if it can be rewound
+	rewind, replayable := bodyRewinder(body)
+	if !replayable {
+		retry.Enabled = false
+	} else if reader, ok := body.(io.Reader); ok {
+		body = newUncloseableReader(reader)
+	}
+	c.budget.deposit()
 
 	// Resolve the full URL
 	fullURL := c.createURL(urlPath)
@@ -233,14 +360,23 @@ func (c *Client) Request(ctx context.Context, method, urlPath string, body inter
 	)
 	defer span.End()
 
+	attempts := 0
 	requestFunc := func() (*Response, error) {
-		return c.doRequest(ctx, method, urlPath, body, headers)
+		attempts++
+		if attempts > 1 {
+			c.metrics.recordRetry(ctx, c.serviceName, method, parsedURL.Host)
+			if err := rewind(); err != nil {
+				return nil, fmt.Errorf("error rewinding request body: %w", err)
+			}
+		}
+		return c.doRequest(ctx, method, urlPath, body, options)
 	}
 
 	// Apply circuit breaker pattern
 	if c.config.CircuitBreaker.Enabled {
-		result, err := c.breaker.Execute(func() (interface{}, error) {
-			return c.executeWithRetries(ctx, requestFunc)
+		breaker := c.breakerFor(ctx, method, parsedURL.Path)
+		result, err := breaker.Execute(func() (interface{}, error) {
+			return c.executeWithRetries(ctx, retry, requestFunc)
 		})
 
 		if err != nil {
@@ -253,7 +389,7 @@ func (c *Client) Request(ctx context.Context, method, urlPath string, body inter
 	}
 
 	// Just use retries without circuit breaker
-	response, err := c.executeWithRetries(ctx, requestFunc)
+	response, err := c.executeWithRetries(ctx, retry, requestFunc)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -261,23 +397,8 @@ func (c *Client) Request(ctx context.Context, method, urlPath string, body inter
 	return response, err
 }
 
-// generateID generates a unique ID for request tracking
-func generateID() string {
-	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), randomString(8))
-}
-
-// randomString generates a random string of the specified length
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(b)
-}
-
 // executeWithRetries performs a request with retries based on the configured backoff
-func (c *Client) executeWithRetries(ctx context.Context, requestFunc func() (*Response, error)) (*Response, error) {
+func (c *Client) executeWithRetries(ctx context.Context, retry RetryConfig, requestFunc func() (*Response, error)) (*Response, error) {
 	var response *Response
 	var err error
 	var statusCode int
@@ -285,20 +406,36 @@ func (c *Client) executeWithRetries(ctx context.Context, requestFunc func() (*Re
 	// Track attempt count for logging
 	attempt := 0
 
-	if !c.config.Retry.Enabled {
+	if !retry.Enabled {
 		return requestFunc()
 	}
 
+	retryBackOff := &hintedBackOff{BackOff: newRetryBackOff(ctx, retry), max: retry.MaxRetryAfter}
+
+	// retryable checks the retry budget before a failed attempt is retried
+	retryable := func(failure error) error {
+		if attempt <= retry.MaxRetries && !c.budget.withdraw() {
+			c.metrics.recordBudgetExhausted(ctx, c.serviceName)
+			logger.WarnCtx(ctx, "Retry budget exhausted, not retrying", zap.Int("attempt", attempt))
+			return backoff.Permanent(failure)
+		}
+		return failure
+	}
+
 	operation := func() error {
 		attempt++
 		span := trace.SpanFromContext(ctx)
 
 		response, err = requestFunc()
 		if err != nil {
+			// Retrying a request the limiter turned away only adds load to the queue
+			if errors.Is(err, ErrQueueFull) || errors.Is(err, ErrQueueTimeout) {
+				return backoff.Permanent(err)
+			}
 			logger.WarnCtx(ctx, "Request retry due to error",
 				zap.Error(err),
 				zap.Int("attempt", attempt),
-				zap.Int("maxAttempts", c.config.Retry.MaxRetries+1),
+				zap.Int("maxAttempts", retry.MaxRetries+1),
 			)
 			span.AddEvent("retry",
 				trace.WithAttributes(
@@ -306,15 +443,15 @@ func (c *Client) executeWithRetries(ctx context.Context, requestFunc func() (*Re
 					attribute.String("error", err.Error()),
 				),
 			)
-			return err
+			return retryable(err)
 		}
 
 		statusCode = response.StatusCode
-		if c.config.Retry.ShouldRetry != nil && c.config.Retry.ShouldRetry(nil, statusCode) {
+		if retry.ShouldRetry != nil && retry.ShouldRetry(nil, statusCode) {
 			logger.WarnCtx(ctx, "Request retry due to status code",
 				zap.Int("statusCode", statusCode),
 				zap.Int("attempt", attempt),
-				zap.Int("maxAttempts", c.config.Retry.MaxRetries+1),
+				zap.Int("maxAttempts", retry.MaxRetries+1),
 			)
 			span.AddEvent("retry",
 				trace.WithAttributes(
@@ -322,17 +459,20 @@ func (c *Client) executeWithRetries(ctx context.Context, requestFunc func() (*Re
 					attribute.Int("statusCode", statusCode),
 				),
 			)
-			return fmt.Errorf("request failed with status code %d", statusCode)
+			if retry.RespectRetryAfter {
+				retryBackOff.hint = retryAfter(response.Headers, time.Now())
+			}
+			return retryable(fmt.Errorf("request failed with status code %d", statusCode))
 		}
 
 		return nil
 	}
 
-	retryErr := backoff.Retry(operation, c.retryBackOff)
+	retryErr := backoff.Retry(operation, retryBackOff)
 	if retryErr != nil {
 		logger.ErrorCtx(ctx, "Request failed after all retries",
 			zap.Error(retryErr),
-			zap.Int("maxAttempts", c.config.Retry.MaxRetries+1),
+			zap.Int("maxAttempts", retry.MaxRetries+1),
 		)
 		return response, retryErr
 	}
@@ -341,70 +481,20 @@ func (c *Client) executeWithRetries(ctx context.Context, requestFunc func() (*Re
 }
 
 // doRequest performs a single HTTP request
-func (c *Client) doRequest(ctx context.Context, method, urlPath string, body interface{}, headers map[string]string) (*Response, error) {
-	// Create the URL
-	fullURL := c.createURL(urlPath)
-
-	// Create the request body if needed
-	var bodyReader io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			logger.ErrorCtx(ctx, "Error marshaling request body", zap.Error(err))
-			return nil, fmt.Errorf("error marshaling request body: %w", err)
-		}
-		bodyReader = bytes.NewBuffer(jsonBody)
-
-		// Add body details to the current span
-		span := trace.SpanFromContext(ctx)
-		span.SetAttributes(semconv.HTTPRequestContentLengthKey.Int(len(jsonBody)))
-	}
-
-	// Create the request
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+func (c *Client) doRequest(ctx context.Context, method, urlPath string, body interface{}, options *requestOptions) (*Response, error) {
+	req, err := c.buildRequest(ctx, method, urlPath, body, options)
 	if err != nil {
-		logger.ErrorCtx(ctx, "Error creating request", zap.Error(err))
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Add default headers
-	for key, value := range c.config.DefaultHeaders {
-		req.Header.Set(key, value)
-	}
-
-	// Add custom headers
-	for key, value := range headers {
-		req.Header.Set(key, value)
+		return nil, err
 	}
-
-	// Set request ID if not present
-	if req.Header.Get(HeaderRequestID) == "" {
-		requestID := generateID()
-		req.Header.Set(HeaderRequestID, requestID)
-	}
-
-	// Inject OpenTelemetry context into headers
-	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
-
-	requestID := req.Header.Get(HeaderRequestID)
-
-	// Add request attributes to the current span
 	span := trace.SpanFromContext(ctx)
-	if requestID != "" {
-		span.SetAttributes(attribute.String("request.id", requestID))
-	}
-
-	// Log the request
-	logger.InfoCtx(ctx, "HTTP request",
-		zap.String("method", req.Method),
-		zap.String("url", req.URL.String()),
-	)
+	requestID := req.Header.Get(HeaderRequestID)
 
 	startTime := time.Now()
 
 	// Perform the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.metrics.recordRequest(ctx, c.serviceName, req.Method, req.URL.Host, 0, time.Since(startTime))
 		logger.ErrorCtx(ctx, "Error performing request",
 			zap.Error(err),
 			zap.String("method", req.Method),
@@ -422,6 +512,7 @@ func (c *Client) doRequest(ctx context.Context, method, urlPath string, body int
 	}
 
 	duration := time.Since(startTime)
+	c.metrics.recordRequest(ctx, c.serviceName, req.Method, req.URL.Host, resp.StatusCode, duration)
 
 	// Update span with response information
 	span.SetAttributes(
@@ -465,6 +556,104 @@ func (c *Client) doRequest(ctx context.Context, method, urlPath string, body int
 	return response, nil
 }
 
+// requestBody returns the reader for a request body
+// Readers are streamed as is, byte slices and strings sent verbatim, and other values JSON encoded.
+func requestBody(body interface{}) (io.Reader, int, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, 0, nil
+	case uncloseableReader:
+		return b, int(b.size), nil
+	case io.Reader:
+		return b, -1, nil
+	case []byte:
+		return bytes.NewReader(b), len(b), nil
+	case string:
+		return strings.NewReader(b), len(b), nil
+	default:
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error marshaling request body: %w", err)
+		}
+		return bytes.NewReader(jsonBody), len(jsonBody), nil
+	}
+}
+
+// buildRequest creates the HTTP request for one attempt, with headers, IDs and trace context set
+func (c *Client) buildRequest(ctx context.Context, method, urlPath string, body interface{}, options *requestOptions) (*http.Request, error) {
+	// Create the URL
+	fullURL := c.createURL(urlPath)
+
+	// Create the request body if needed
+	bodyReader, bodySize, err := requestBody(body)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Error marshaling request body", zap.Error(err))
+		return nil, err
+	}
+	if bodySize >= 0 && bodyReader != nil {
+		// Add body details to the current span
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(semconv.HTTPRequestContentLengthKey.Int(bodySize))
+	}
+
+	// Create the request
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Error creating request", zap.Error(err))
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if req.ContentLength == 0 && bodySize > 0 {
+		// Readers of a known length are sent with a Content-Length instead of chunked
+		req.ContentLength = int64(bodySize)
+	}
+
+	// Add default headers
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+
+	// Add custom headers, query parameters and credentials
+	for key, value := range options.headers {
+		req.Header.Set(key, value)
+	}
+	options.apply(req)
+
+	// Propagate request, correlation and causation IDs from the context unless set explicitly,
+	// and the identity of the caller only to services trusted with it
+	ids := correlation.FromContext(ctx)
+	if !c.config.PropagateIdentity {
+		ids = ids.WithoutIdentity()
+	}
+	correlation.Inject(ids, correlation.HeaderCarrier(req.Header))
+
+	// Set request ID if not present
+	if req.Header.Get(HeaderRequestID) == "" {
+		req.Header.Set(HeaderRequestID, c.newRequestID(ctx))
+	}
+
+	// Inject OpenTelemetry context into headers; baggage is always propagated, even with tracing disabled
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if req.Header.Get("Baggage") == "" {
+		propagation.Baggage{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	requestID := req.Header.Get(HeaderRequestID)
+
+	// Add request attributes to the current span
+	span := trace.SpanFromContext(ctx)
+	if requestID != "" {
+		span.SetAttributes(attribute.String("request.id", requestID))
+	}
+
+	// Log the request
+	logger.InfoCtx(ctx, "HTTP request",
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.String()),
+	)
+
+	return req, nil
+}
+
 // createURL creates a full URL from the base URL and path
 func (c *Client) createURL(urlPath string) string {
 	if urlPath == "" {
@@ -479,8 +668,29 @@ func (c *Client) createURL(urlPath string) string {
 	// Create a copy of the base URL
 	u := *c.baseURL
 
-	// Join the base path and the requested path
-	u.Path = path.Join(u.Path, urlPath)
+	// Append the requested path to the base path, keeping escaped path segments and the query
+	// string; paths are not cleaned, so that decoded segments cannot resolve to other routes
+	ref, err := url.Parse(urlPath)
+	if err != nil {
+		u.Path = joinURLPath(u.Path, urlPath)
+		u.RawPath = ""
+		return u.String()
+	}
+
+	u.RawPath = ""
+	if ref.RawPath != "" {
+		u.RawPath = joinURLPath(c.baseURL.EscapedPath(), ref.RawPath)
+	}
+	u.Path = joinURLPath(u.Path, ref.Path)
+	u.RawQuery = ref.RawQuery
 
 	return u.String()
 }
+
+// joinURLPath appends p to base with a single slash between them
+func joinURLPath(base, p string) string {
+	if p == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(p, "/")
+}
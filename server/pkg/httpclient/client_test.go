@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer starts a server answering requests with handler until the test ends
+func startServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestServer starts a server answering requests with handler and returns a client of it,
+// without retries or circuit breaking unless configure enables them
+func newTestServer(t *testing.T, handler http.HandlerFunc, configure ...func(*Config)) *Client {
+	t.Helper()
+	cfg := DefaultConfig(startServer(t, handler).URL)
+	cfg.Retry.Enabled = false
+	cfg.CircuitBreaker.Enabled = false
+	cfg.Tracing = false
+	for _, fn := range configure {
+		fn(cfg)
+	}
+	client, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	return client
+}
+
+// newTestClient creates a client of baseURL sending requests to transport, without retries or
+// circuit breaking unless configure enables them
+func newTestClient(t *testing.T, baseURL string, transport http.RoundTripper, configure ...func(*Config)) *Client {
+	t.Helper()
+	cfg := DefaultConfig(baseURL)
+	cfg.RoundTripper = transport
+	cfg.Retry.Enabled = false
+	cfg.CircuitBreaker.Enabled = false
+	cfg.Tracing = false
+	for _, fn := range configure {
+		fn(cfg)
+	}
+	client, err := New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	return client
+}
+
+// dropConnection closes the connection of a request without answering it, failing the request
+func dropConnection(w http.ResponseWriter) {
+	if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// respondJSON answers a request with status and v encoded as JSON
+func respondJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// fastRetries enables the default retry policy without waiting between attempts or a budget
+func fastRetries(cfg *Config) {
+	cfg.Retry = DefaultConfig(cfg.BaseURL).Retry
+	cfg.Retry.InitialInterval = time.Millisecond
+	cfg.Retry.MaxInterval = time.Millisecond
+	cfg.Retry.Budget = nil
+}
+
+// withBreakers enables circuit breakers opening after two consecutive failures
+func withBreakers(cfg *Config) {
+	cfg.CircuitBreaker = DefaultConfig(cfg.BaseURL).CircuitBreaker
+	cfg.CircuitBreaker.Name = "quizzes"
+	cfg.CircuitBreaker.ReadyToTrip = func(counts gobreaker.Counts) bool {
+		return counts.ConsecutiveFailures >= 2
+	}
+}
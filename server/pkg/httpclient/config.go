@@ -3,6 +3,7 @@
 package httpclient
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/sony/gobreaker"
@@ -30,6 +31,15 @@ type RetryConfig struct {
 
 	// ShouldRetry is a function that determines if a request should be retried
 	ShouldRetry func(err error, statusCode int) bool
+
+	// RespectRetryAfter waits as long as Retry-After or RateLimit-Reset headers ask before retrying
+	RespectRetryAfter bool
+
+	// MaxRetryAfter is the longest server-requested delay honored; longer ones end retrying (0 honors any)
+	MaxRetryAfter time.Duration
+
+	// Budget limits retries to a share of the client's requests; nil disables the limit
+	Budget *RetryBudgetConfig
 }
 
 // CircuitBreakerConfig holds configuration for the circuit breaker
@@ -51,11 +61,19 @@ type CircuitBreakerConfig struct {
 
 	// ReadyToTrip is a function that determines if the circuit breaker should trip
 	ReadyToTrip func(counts gobreaker.Counts) bool
+
+	// KeyFunc selects the breaker of a request, so a failing endpoint does not trip the
+	// breaker for the whole base URL; nil uses a single breaker for the client
+	KeyFunc func(method, path string) string
+
+	// MaxBreakers bounds the number of per-key breakers; further keys share the client-wide breaker
+	MaxBreakers int
 }
 
 // TimeoutConfig holds configuration for various timeouts
 type TimeoutConfig struct {
-	// RequestTimeout is the maximum time for the whole request
+	// RequestTimeout is the maximum time for the whole request, including reading its body
+	// Streamed requests (DoStream, DownloadFile) are not bound by it.
 	RequestTimeout time.Duration
 
 	// DialTimeout is the maximum time for connecting to the server
@@ -97,6 +115,13 @@ type TransportConfig struct {
 
 	// ProxyURL is the URL of the proxy to use
 	ProxyURL string
+
+	// DNSCacheTTL caches hostname resolutions and spreads connections over all returned
+	// addresses, re-resolving after the TTL; 0 resolves on every dial
+	DNSCacheTTL time.Duration
+
+	// TLS configures server verification and client certificates; nil uses Go's defaults
+	TLS *TLSConfig
 }
 
 // Config holds all configuration options for the HTTP client
@@ -111,23 +136,67 @@ type Config struct {
 	// DefaultHeaders are headers that will be included in all requests
 	DefaultHeaders map[string]string
 
+	// IDGenerator creates request IDs for requests without one in their context; defaults to RandomIDs
+	IDGenerator IDGenerator
+
 	// Timeouts configuration
 	Timeouts TimeoutConfig
 
 	// Transport configuration
 	Transport TransportConfig
 
+	// RoundTripper replaces the transport built from Transport, e.g. with an httpclienttest mock
+	// Middlewares, authentication and signing still wrap it.
+	RoundTripper http.RoundTripper
+
 	// Retry configuration
 	Retry RetryConfig
 
 	// CircuitBreaker configuration
 	CircuitBreaker CircuitBreakerConfig
 
+	// OAuth2 enables fetching and injecting OAuth2 access tokens on every request
+	OAuth2 *OAuth2Config
+
+	// Signer signs every request attempt (e.g. AWSSigV4Signer or HMACSigner); nil disables signing
+	Signer Signer
+
+	// LoadBalancer spreads requests over several endpoints instead of BaseURL's host; nil disables it
+	LoadBalancer *LoadBalancerConfig
+
+	// Concurrency bounds the requests in flight and queues the rest; nil leaves them unbounded
+	Concurrency *ConcurrencyConfig
+
+	// Redirect controls how redirects are followed
+	Redirect RedirectConfig
+
+	// Cookies keeps cookies set by servers and sends them on later requests, for stateful APIs
+	Cookies bool
+
+	// CookieJar replaces the in-memory jar created when Cookies is set
+	CookieJar http.CookieJar
+
+	// Cache enables caching GET responses per their Cache-Control headers; nil disables caching
+	Cache *CacheConfig
+
+	// Middlewares wrap the transport, in order, around every request attempt (see Use)
+	Middlewares []Middleware
+
+	// IsError classifies response statuses as errors for the JSON helpers; nil uses DefaultIsError
+	IsError func(statusCode int) bool
+
 	// Tracing determines if tracing is enabled
 	Tracing bool
 
-	// Debug enables verbose logging
+	// PropagateIdentity sends the user, tenant and actor IDs of the request context
+	// (X-User-ID, X-Tenant-ID, X-Actor-ID); enable it only for trusted internal services
+	PropagateIdentity bool
+
+	// Debug enables verbose logging, including redacted headers and bodies as configured by Logging
 	Debug bool
+
+	// Logging controls the request and response details logged in debug mode
+	Logging LoggingConfig
 }
 
 // DefaultConfig returns a default configuration
@@ -169,6 +238,12 @@ func DefaultConfig(baseURL string) *Config {
 				}
 				return statusCode >= 500 || statusCode == 0 || statusCode == 429
 			},
+			RespectRetryAfter: true,
+			MaxRetryAfter:     30 * time.Second,
+			Budget: &RetryBudgetConfig{
+				Ratio:               0.2,
+				MinRetriesPerSecond: 10,
+			},
 		},
 		CircuitBreaker: CircuitBreakerConfig{
 			Enabled:     true,
@@ -180,9 +255,14 @@ func DefaultConfig(baseURL string) *Config {
 				failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
 				return counts.Requests >= 10 && failureRatio >= 0.6
 			},
+			KeyFunc:     BreakerKeyByPath,
+			MaxBreakers: DefaultMaxBreakers,
 		},
 		Tracing: true,
 		Debug:   false,
+		Logging: LoggingConfig{
+			MaxBodySize: 4096,
+		},
 	}
 }
 
@@ -231,6 +311,18 @@ func (c *Config) WithCircuitBreakerEnabled(enabled bool) *Config {
 	return c
 }
 
+// WithBreakerKeyFunc sets how requests are assigned to circuit breakers (nil uses one breaker)
+func (c *Config) WithBreakerKeyFunc(keyFunc func(method, path string) string) *Config {
+	c.CircuitBreaker.KeyFunc = keyFunc
+	return c
+}
+
+// WithIdentityPropagation sets whether the user, tenant and actor IDs of the context are sent
+func (c *Config) WithIdentityPropagation(enabled bool) *Config {
+	c.PropagateIdentity = enabled
+	return c
+}
+
 // WithDebug enables or disables debug logging
 func (c *Config) WithDebug(debug bool) *Config {
 	c.Debug = debug
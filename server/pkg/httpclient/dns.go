@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsCache resolves hostnames at most once per ttl and rotates connections over the returned addresses
+type dnsCache struct {
+	ttl      time.Duration
+	resolver *net.Resolver
+	dialer   *net.Dialer
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+// dnsEntry is the cached resolution of a host
+type dnsEntry struct {
+	addrs     []string
+	expiresAt time.Time
+	next      atomic.Uint32
+}
+
+// newDNSCache creates a cache dialing through dialer
+func newDNSCache(ttl time.Duration, dialer *net.Dialer) *dnsCache {
+	return &dnsCache{
+		ttl:      ttl,
+		resolver: net.DefaultResolver,
+		dialer:   dialer,
+		entries:  make(map[string]*dnsEntry),
+	}
+}
+
+// lookup returns the cached addresses of host, resolving it again once they expire
+// A failed refresh keeps serving the previous addresses.
+func (d *dnsCache) lookup(ctx context.Context, host string) (*dnsEntry, error) {
+	d.mu.Lock()
+	entry, ok := d.entries[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	addrs, err := d.resolver.LookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		if ok {
+			return entry, nil
+		}
+		return nil, err
+	}
+
+	fresh := &dnsEntry{addrs: addrs, expiresAt: time.Now().Add(d.ttl)}
+	d.mu.Lock()
+	d.entries[host] = fresh
+	d.mu.Unlock()
+	return fresh, nil
+}
+
+// DialContext dials addr using cached addresses, trying each in turn until one connects
+func (d *dnsCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	entry, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	start := int(entry.next.Add(1))
+	var lastErr error
+	for i := range entry.addrs {
+		ip := entry.addrs[(start+i)%len(entry.addrs)]
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
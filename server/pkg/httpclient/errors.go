@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxErrorSnippet is the number of body bytes quoted in StatusError messages
+const maxErrorSnippet = 256
+
+// StatusError is returned for responses whose status the client classifies as an error
+// The complete response stays available for callers that need more than the summary.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+	Headers    http.Header
+	RequestID  string
+	Response   *Response
+}
+
+// APIError is the error returned by the typed JSON helpers
+type APIError = StatusError
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unexpected status code %d", e.StatusCode)
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request %s)", e.RequestID)
+	}
+	if snippet := e.Snippet(); snippet != "" {
+		fmt.Fprintf(&b, ": %s", snippet)
+	}
+	return b.String()
+}
+
+// Snippet returns the start of the body on a single line, for logs and error messages
+func (e *StatusError) Snippet() string {
+	body := e.Body
+	truncated := false
+	if len(body) > maxErrorSnippet {
+		body = body[:maxErrorSnippet]
+		truncated = true
+	}
+
+	// Drop a rune cut in half by the truncation
+	for len(body) > 0 && !utf8.Valid(body) {
+		body = body[:len(body)-1]
+	}
+
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if truncated {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// Decode unmarshals the error body into target, for APIs that return structured errors
+func (e *StatusError) Decode(target interface{}) error {
+	return json.Unmarshal(e.Body, target)
+}
+
+// newStatusError wraps a response whose status is classified as an error
+func newStatusError(resp *Response) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Body:       resp.Body,
+		Headers:    resp.Headers,
+		RequestID:  resp.RequestID,
+		Response:   resp,
+	}
+}
+
+// DefaultIsError classifies every status outside 2xx as an error
+func DefaultIsError(statusCode int) bool {
+	return statusCode < 200 || statusCode > 299
+}
+
+// CheckStatus returns a *StatusError when the client classifies the response status as an error
+func (c *Client) CheckStatus(resp *Response) error {
+	isError := c.config.IsError
+	if isError == nil {
+		isError = DefaultIsError
+	}
+
+	if isError(resp.StatusCode) {
+		return newStatusError(resp)
+	}
+	return nil
+}
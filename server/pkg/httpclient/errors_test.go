@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusError(t *testing.T) {
+	t.Run("Describes the status, request and body", func(t *testing.T) {
+		err := newStatusError(&Response{StatusCode: http.StatusBadRequest, RequestID: "req-1", Body: []byte("{\n  \"error\": \"invalid quiz\"\n}")})
+		assert.Equal(t, `unexpected status code 400 (request req-1): { "error": "invalid quiz" }`, err.Error())
+	})
+
+	t.Run("Omits empty parts", func(t *testing.T) {
+		err := newStatusError(&Response{StatusCode: http.StatusBadGateway})
+		assert.Equal(t, "unexpected status code 502", err.Error())
+	})
+
+	t.Run("Truncates long bodies without splitting runes", func(t *testing.T) {
+		body := strings.Repeat("a", maxErrorSnippet-1) + "é and more"
+		err := newStatusError(&Response{StatusCode: http.StatusInternalServerError, Body: []byte(body)})
+		assert.Equal(t, strings.Repeat("a", maxErrorSnippet-1)+"...", err.Snippet())
+	})
+
+	t.Run("Decodes structured bodies", func(t *testing.T) {
+		err := newStatusError(&Response{StatusCode: http.StatusConflict, Body: []byte(`{"code":"QUIZ_EXISTS"}`)})
+
+		var body struct {
+			Code string `json:"code"`
+		}
+		require.NoError(t, err.Decode(&body))
+		assert.Equal(t, "QUIZ_EXISTS", body.Code)
+	})
+}
+
+func TestCheckStatus(t *testing.T) {
+	t.Run("Classifies statuses outside 2xx as errors by default", func(t *testing.T) {
+		client := newTestServer(t, http.NotFound)
+
+		assert.NoError(t, client.CheckStatus(&Response{StatusCode: http.StatusNoContent}))
+		for _, status := range []int{http.StatusNotModified, http.StatusNotFound, http.StatusServiceUnavailable} {
+			var statusErr *StatusError
+			require.ErrorAs(t, client.CheckStatus(&Response{StatusCode: status}), &statusErr)
+			assert.Equal(t, status, statusErr.StatusCode)
+		}
+	})
+
+	t.Run("Uses IsError when configured", func(t *testing.T) {
+		client := newTestServer(t, http.NotFound, func(cfg *Config) {
+			cfg.IsError = func(statusCode int) bool { return statusCode >= 500 }
+		})
+
+		assert.NoError(t, client.CheckStatus(&Response{StatusCode: http.StatusNotFound}))
+		assert.Error(t, client.CheckStatus(&Response{StatusCode: http.StatusBadGateway}))
+	})
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// FilePart is a file sent in a multipart/form-data request
+type FilePart struct {
+	// FieldName is the form field holding the file
+	FieldName string
+
+	// FileName is the file name reported to the server
+	FileName string
+
+	// ContentType defaults to application/octet-stream
+	ContentType string
+
+	// Reader supplies the content, which is streamed without buffering
+	Reader io.Reader
+}
+
+// PostForm sends fields as an application/x-www-form-urlencoded POST request
+func (c *Client) PostForm(ctx context.Context, urlPath string, fields url.Values, opts ...RequestOption) (*Response, error) {
+	opts = append(opts, WithHeader("Content-Type", "application/x-www-form-urlencoded"))
+	return c.Request(ctx, http.MethodPost, urlPath, fields.Encode(), opts...)
+}
+
+// PostMultipart sends fields and files as a multipart/form-data POST request
+// The body is produced while it is sent, so large files are never held in memory;
+// as with other streamed bodies, the request is not retried.
+func (c *Client) PostMultipart(ctx context.Context, urlPath string, fields map[string]string, files []FilePart, opts ...RequestOption) (*Response, error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fields, files))
+	}()
+
+	opts = append(opts, WithHeader("Content-Type", writer.FormDataContentType()))
+	resp, err := c.Request(ctx, http.MethodPost, urlPath, pr, opts...)
+
+	// Unblock the writer if the request ended before consuming the whole body
+	pr.CloseWithError(io.ErrClosedPipe)
+	return resp, err
+}
+
+// writeMultipart writes the form fields, in name order, followed by the files
+func writeMultipart(writer *multipart.Writer, fields map[string]string, files []FilePart) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return fmt.Errorf("error writing form field %s: %w", name, err)
+		}
+	}
+
+	for _, file := range files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(file.FieldName), escapeQuotes(file.FileName)))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return fmt.Errorf("error creating form file %s: %w", file.FieldName, err)
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return fmt.Errorf("error writing form file %s: %w", file.FieldName, err)
+		}
+	}
+
+	return writer.Close()
+}
+
+// quoteEscaper escapes the characters multipart headers require escaping
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes a value for use in a quoted header parameter
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostForm(t *testing.T) {
+	var contentType, body string
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		contentType, body = r.Header.Get("Content-Type"), string(raw)
+	})
+
+	_, err := client.PostForm(context.Background(), "/login", url.Values{"user": {"ada"}, "scope": {"read write"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "application/x-www-form-urlencoded", contentType)
+	assert.Equal(t, "scope=read+write&user=ada", body)
+}
+
+func TestPostMultipart(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Sends fields and files", func(t *testing.T) {
+		var contentType string
+		var body []byte
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		resp, err := client.PostMultipart(ctx, "/imports",
+			map[string]string{"format": "csv", "dryRun": "true"},
+			[]FilePart{
+				{FieldName: "file", FileName: `quiz "1".csv`, ContentType: "text/csv", Reader: bytes.NewReader([]byte("id\n1\n"))},
+				{FieldName: "attachment", FileName: "notes.bin", Reader: bytes.NewReader([]byte{0, 1, 2})},
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/form-data", mediaType)
+
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		type part struct{ name, fileName, contentType, content string }
+		var parts []part
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(p)
+			require.NoError(t, err)
+			parts = append(parts, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(content)})
+		}
+
+		assert.Equal(t, []part{
+			{name: "dryRun", content: "true"},
+			{name: "format", content: "csv"},
+			{name: "file", fileName: `quiz "1".csv`, contentType: "text/csv", content: "id\n1\n"},
+			{name: "attachment", fileName: "notes.bin", contentType: "application/octet-stream", content: "\x00\x01\x02"},
+		}, parts)
+	})
+
+	t.Run("Fails when a file cannot be read", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		_, err := client.PostMultipart(ctx, "/imports", nil, []FilePart{
+			{FieldName: "file", FileName: "quiz.csv", Reader: io.MultiReader(bytes.NewReader([]byte("id\n")), failingReader{})},
+		})
+		assert.ErrorContains(t, err, "error writing form file file")
+	})
+}
+
+// failingReader is a reader failing on every read, like a file on a broken disk
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read error")
+}
@@ -0,0 +1,334 @@
+// Package httpclienttest provides transports for testing code built on httpclient without real servers
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TestingT is the subset of testing.TB used by the assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call is a request received by a MockTransport
+type Call struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// MockResponse is a scripted response
+type MockResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	Err    error
+}
+
+// Expectation matches requests and scripts the responses to them
+type Expectation struct {
+	method   string
+	path     string
+	matchers []func(*Call) bool
+
+	responses []MockResponse
+	times     int
+	calls     int
+}
+
+// WithQuery requires the query parameter key to have value
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.matchers = append(e.matchers, func(call *Call) bool {
+		values, err := parseQuery(call.Query)
+		return err == nil && values.Get(key) == value
+	})
+	return e
+}
+
+// WithHeader requires the header key to have value
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.matchers = append(e.matchers, func(call *Call) bool {
+		return call.Header.Get(key) == value
+	})
+	return e
+}
+
+// WithBody requires the body to contain substring
+func (e *Expectation) WithBody(substring string) *Expectation {
+	e.matchers = append(e.matchers, func(call *Call) bool {
+		return bytes.Contains(call.Body, []byte(substring))
+	})
+	return e
+}
+
+// WithJSONBody requires the body to be JSON equal to v
+func (e *Expectation) WithJSONBody(v interface{}) *Expectation {
+	expected, err := normalizeJSON(v)
+	e.matchers = append(e.matchers, func(call *Call) bool {
+		var actual interface{}
+		if err != nil || json.Unmarshal(call.Body, &actual) != nil {
+			return false
+		}
+		actualJSON, _ := json.Marshal(actual)
+		return bytes.Equal(actualJSON, expected)
+	})
+	return e
+}
+
+// Matching adds a custom matcher
+func (e *Expectation) Matching(match func(*Call) bool) *Expectation {
+	e.matchers = append(e.matchers, match)
+	return e
+}
+
+// Respond scripts a response; successive calls script successive responses and the last one repeats
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.responses = append(e.responses, MockResponse{Status: status, Body: []byte(body)})
+	return e
+}
+
+// RespondJSON scripts a JSON response
+func (e *Expectation) RespondJSON(status int, v interface{}) *Expectation {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return e.RespondError(err)
+	}
+	e.responses = append(e.responses, MockResponse{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   body,
+	})
+	return e
+}
+
+// RespondWith scripts a fully specified response
+func (e *Expectation) RespondWith(resp MockResponse) *Expectation {
+	e.responses = append(e.responses, resp)
+	return e
+}
+
+// RespondError scripts a transport error, as if the server could not be reached
+func (e *Expectation) RespondError(err error) *Expectation {
+	e.responses = append(e.responses, MockResponse{Err: err})
+	return e
+}
+
+// Times limits how often the expectation matches; 0 (the default) means without limit
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Once limits the expectation to a single match
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// matches reports whether call satisfies the expectation and it has matches left
+func (e *Expectation) matches(call *Call) bool {
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+	if e.method != "" && e.method != call.Method {
+		return false
+	}
+	if e.path != "" && e.path != call.Path {
+		return false
+	}
+	for _, match := range e.matchers {
+		if !match(call) {
+			return false
+		}
+	}
+	return true
+}
+
+// next returns the response for the current call
+func (e *Expectation) next() MockResponse {
+	e.calls++
+	if len(e.responses) == 0 {
+		return MockResponse{Status: http.StatusOK}
+	}
+	index := e.calls - 1
+	if index >= len(e.responses) {
+		index = len(e.responses) - 1
+	}
+	return e.responses[index]
+}
+
+// MockTransport is an http.RoundTripper answering requests from scripted expectations
+// Use it as httpclient.Config.RoundTripper. Expectations are matched in the order they were added.
+type MockTransport struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+}
+
+// NewMockTransport creates a mock transport without expectations
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+// On adds an expectation for requests with method to path; empty values match any
+func (m *MockTransport) On(method, path string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &Expectation{method: method, path: path}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// RoundTrip implements http.RoundTripper
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call, err := newCall(req)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, *call)
+	var matched *Expectation
+	for _, e := range m.expectations {
+		if e.matches(call) {
+			matched = e
+			break
+		}
+	}
+	var scripted MockResponse
+	if matched != nil {
+		scripted = matched.next()
+	}
+	m.mu.Unlock()
+
+	if matched == nil {
+		return nil, fmt.Errorf("httpclienttest: no expectation matches %s %s", call.Method, call.Path)
+	}
+	if scripted.Err != nil {
+		return nil, scripted.Err
+	}
+	return newResponse(req, scripted.Status, scripted.Header, scripted.Body), nil
+}
+
+// Calls returns the requests received so far
+func (m *MockTransport) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns the number of requests received with method to path
+func (m *MockTransport) CallCount(method, path string) int {
+	count := 0
+	for _, call := range m.Calls() {
+		if call.Method == method && call.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertCalled fails the test unless a request with method to path was received
+func (m *MockTransport) AssertCalled(t TestingT, method, path string) bool {
+	t.Helper()
+	if m.CallCount(method, path) == 0 {
+		t.Errorf("httpclienttest: expected a call to %s %s", method, path)
+		return false
+	}
+	return true
+}
+
+// AssertExpectations fails the test for every expectation that was not matched as often as required
+func (m *MockTransport) AssertExpectations(t TestingT) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for _, e := range m.expectations {
+		want := e.times
+		if want == 0 {
+			want = 1
+		}
+		if e.calls < want {
+			t.Errorf("httpclienttest: expected %s %s to be called %d time(s), got %d",
+				orAny(e.method), orAny(e.path), want, e.calls)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// newCall captures a request, leaving its body readable
+func newCall(req *http.Request) (*Call, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpclienttest: error reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return &Call{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Header: req.Header.Clone(),
+		Body:   body,
+	}, nil
+}
+
+// newResponse builds the response to req
+func newResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// normalizeJSON encodes v in a canonical form for comparison
+func normalizeJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(decoded)
+}
+
+// orAny describes an empty matcher value in messages
+func orAny(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "*"
+	}
+	return value
+}
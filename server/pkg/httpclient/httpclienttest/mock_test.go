@@ -0,0 +1,166 @@
+package httpclienttest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT collects assertion failures instead of failing the test
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// send performs a request to the example API through transport and returns the status and body
+func send(t *testing.T, transport http.RoundTripper, method, target, body string, header http.Header) (int, string, error) {
+	t.Helper()
+	return sendTo(t, transport, "https://api.example.com", method, target, body, header)
+}
+
+func TestMockTransport(t *testing.T) {
+	t.Run("Matches method, path, query, header and body", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On(http.MethodPost, "/quizzes").
+			WithQuery("draft", "true").
+			WithHeader("X-Tenant", "acme").
+			WithBody("Fractions").
+			Respond(http.StatusCreated, `{"id":"q1"}`)
+		transport.On("", "").Respond(http.StatusTeapot, "")
+
+		status, body, err := send(t, transport, http.MethodPost, "/quizzes?draft=true", `{"title":"Fractions"}`,
+			http.Header{"X-Tenant": {"acme"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, `{"id":"q1"}`, body)
+
+		for _, target := range []string{"/quizzes", "/quizzes?draft=false"} {
+			status, _, err = send(t, transport, http.MethodPost, target, `{"title":"Fractions"}`, http.Header{"X-Tenant": {"acme"}})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusTeapot, status, target)
+		}
+		status, _, err = send(t, transport, http.MethodPost, "/quizzes?draft=true", `{"title":"Fractions"}`, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTeapot, status)
+		status, _, err = send(t, transport, http.MethodPost, "/quizzes?draft=true", `{"title":"Algebra"}`, http.Header{"X-Tenant": {"acme"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTeapot, status)
+	})
+
+	t.Run("Matches JSON bodies regardless of formatting", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On(http.MethodPut, "/quizzes/q1").
+			WithJSONBody(map[string]interface{}{"title": "Fractions", "public": true}).
+			Respond(http.StatusOK, "")
+
+		_, _, err := send(t, transport, http.MethodPut, "/quizzes/q1", "{\n  \"public\": true,\n  \"title\": \"Fractions\"\n}", nil)
+		assert.NoError(t, err)
+		_, _, err = send(t, transport, http.MethodPut, "/quizzes/q1", `{"title":"Fractions"}`, nil)
+		assert.ErrorContains(t, err, "no expectation matches PUT /quizzes/q1")
+	})
+
+	t.Run("Plays scripted responses in order and repeats the last", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").
+			Respond(http.StatusServiceUnavailable, "").
+			RespondJSON(http.StatusOK, []string{"q1"})
+
+		var statuses []int
+		for i := 0; i < 3; i++ {
+			status, _, err := send(t, transport, http.MethodGet, "/quizzes", "", nil)
+			require.NoError(t, err)
+			statuses = append(statuses, status)
+		}
+		assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK}, statuses)
+	})
+
+	t.Run("Answers 200 without scripted responses", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On(http.MethodGet, "/health")
+
+		status, body, err := send(t, transport, http.MethodGet, "/health", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, body)
+	})
+
+	t.Run("Falls through to later expectations once used up", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").Once().Respond(http.StatusOK, "first")
+		transport.On(http.MethodGet, "/quizzes").Respond(http.StatusOK, "later")
+
+		_, first, err := send(t, transport, http.MethodGet, "/quizzes", "", nil)
+		require.NoError(t, err)
+		_, second, err := send(t, transport, http.MethodGet, "/quizzes", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "first", first)
+		assert.Equal(t, "later", second)
+	})
+
+	t.Run("Returns scripted transport errors", func(t *testing.T) {
+		refused := errors.New("connection refused")
+		transport := NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").RespondError(refused)
+
+		_, _, err := send(t, transport, http.MethodGet, "/quizzes", "", nil)
+		assert.ErrorIs(t, err, refused)
+	})
+
+	t.Run("Records calls", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On("", "")
+
+		_, _, err := send(t, transport, http.MethodPost, "/quizzes?draft=true", "payload", http.Header{"X-Tenant": {"acme"}})
+		require.NoError(t, err)
+		_, _, err = send(t, transport, http.MethodGet, "/quizzes", "", nil)
+		require.NoError(t, err)
+
+		calls := transport.Calls()
+		require.Len(t, calls, 2)
+		assert.Equal(t, http.MethodPost, calls[0].Method)
+		assert.Equal(t, "/quizzes", calls[0].Path)
+		assert.Equal(t, "draft=true", calls[0].Query)
+		assert.Equal(t, "acme", calls[0].Header.Get("X-Tenant"))
+		assert.Equal(t, "payload", string(calls[0].Body))
+		assert.Equal(t, 1, transport.CallCount(http.MethodPost, "/quizzes"))
+		assert.Equal(t, 1, transport.CallCount(http.MethodGet, "/quizzes"))
+		assert.Equal(t, 0, transport.CallCount(http.MethodDelete, "/quizzes"))
+	})
+
+	t.Run("Reports unmet expectations", func(t *testing.T) {
+		transport := NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").Times(2)
+		transport.On(http.MethodDelete, "")
+
+		_, _, err := send(t, transport, http.MethodGet, "/quizzes", "", nil)
+		require.NoError(t, err)
+
+		rt := &recordingT{}
+		assert.False(t, transport.AssertExpectations(rt))
+		assert.False(t, transport.AssertCalled(rt, http.MethodDelete, "/quizzes/q1"))
+		assert.Equal(t, []string{
+			"httpclienttest: expected GET /quizzes to be called 2 time(s), got 1",
+			"httpclienttest: expected DELETE * to be called 1 time(s), got 0",
+			"httpclienttest: expected a call to DELETE /quizzes/q1",
+		}, rt.errors)
+
+		_, _, err = send(t, transport, http.MethodGet, "/quizzes", "", nil)
+		require.NoError(t, err)
+		_, _, err = send(t, transport, http.MethodDelete, "/quizzes/q1", "", nil)
+		require.NoError(t, err)
+
+		rt = &recordingT{}
+		assert.True(t, transport.AssertExpectations(rt))
+		assert.True(t, transport.AssertCalled(rt, http.MethodDelete, "/quizzes/q1"))
+		assert.Empty(t, rt.errors)
+	})
+}
@@ -0,0 +1,205 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Mode selects whether a Recorder records real traffic or replays fixtures
+type Mode int
+
+const (
+	// ModeReplay answers requests from the fixture file and fails on unknown requests
+	ModeReplay Mode = iota
+
+	// ModeRecord forwards requests to the real transport and records them
+	ModeRecord
+)
+
+// RecordEnvVar switches ModeFromEnv to recording when set to "1" or "true"
+const RecordEnvVar = "HTTPCLIENT_RECORD"
+
+// ModeFromEnv returns ModeRecord when RecordEnvVar is set, and ModeReplay otherwise
+func ModeFromEnv() Mode {
+	switch os.Getenv(RecordEnvVar) {
+	case "1", "true":
+		return ModeRecord
+	default:
+		return ModeReplay
+	}
+}
+
+// redactedHeaders are never written to fixtures
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Amz-Security-Token"}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the persisted form of a request
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the persisted form of a response
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Recorder is a VCR-style http.RoundTripper: in record mode it captures real traffic to a
+// fixture file, in replay mode it answers requests from that file
+// Requests are matched on method, URL (path and query) and body; each interaction replays once.
+type Recorder struct {
+	fixture string
+	mode    Mode
+	real    http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder creates a recorder for the fixture file
+// In replay mode the fixture must exist; in record mode requests go to real (http.DefaultTransport if nil).
+func NewRecorder(fixture string, mode Mode, real http.RoundTripper) (*Recorder, error) {
+	if real == nil {
+		real = http.DefaultTransport
+	}
+	r := &Recorder{fixture: fixture, mode: mode, real: real}
+
+	if mode == ModeReplay {
+		raw, err := os.ReadFile(fixture)
+		if err != nil {
+			return nil, fmt.Errorf("httpclienttest: error reading fixture: %w", err)
+		}
+		if err := json.Unmarshal(raw, &r.interactions); err != nil {
+			return nil, fmt.Errorf("httpclienttest: error decoding fixture: %w", err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	call, err := newCall(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, call)
+	}
+	return r.replay(req, call)
+}
+
+// record forwards req and keeps the interaction
+func (r *Recorder) record(req *http.Request, call *Call) (*http.Response, error) {
+	resp, err := r.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpclienttest: error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: call.Method,
+			URL:    requestURI(call),
+			Header: redact(call.Header),
+			Body:   string(call.Body),
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: redact(resp.Header),
+			Body:   string(body),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay answers req with the first unused matching interaction
+func (r *Recorder) replay(req *http.Request, call *Call) (*http.Response, error) {
+	uri := requestURI(call)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		recorded := interaction.Request
+		if r.used[i] || recorded.Method != call.Method || recorded.URL != uri || recorded.Body != string(call.Body) {
+			continue
+		}
+		r.used[i] = true
+		return newResponse(req, interaction.Response.Status, interaction.Response.Header, []byte(interaction.Response.Body)), nil
+	}
+
+	return nil, fmt.Errorf("httpclienttest: no recorded interaction for %s %s in %s", call.Method, uri, r.fixture)
+}
+
+// Save writes the recorded interactions to the fixture file; it does nothing in replay mode
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	raw, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("httpclienttest: error encoding fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.fixture), 0o755); err != nil {
+		return fmt.Errorf("httpclienttest: error creating fixture directory: %w", err)
+	}
+	if err := os.WriteFile(r.fixture, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("httpclienttest: error writing fixture: %w", err)
+	}
+	return nil
+}
+
+// requestURI returns the path and query of a call, the host-independent part used for matching
+func requestURI(call *Call) string {
+	u := url.URL{Path: call.Path, RawQuery: call.Query}
+	return u.RequestURI()
+}
+
+// redact copies header without credentials
+func redact(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	clean := header.Clone()
+	for _, name := range redactedHeaders {
+		clean.Del(name)
+	}
+	return clean
+}
+
+// parseQuery parses a raw query string
+func parseQuery(raw string) (url.Values, error) {
+	return url.ParseQuery(raw)
+}
@@ -0,0 +1,161 @@
+package httpclienttest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModeFromEnv(t *testing.T) {
+	for value, expected := range map[string]Mode{"": ModeReplay, "0": ModeReplay, "1": ModeRecord, "true": ModeRecord} {
+		t.Setenv(RecordEnvVar, value)
+		assert.Equal(t, expected, ModeFromEnv(), value)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("Records real traffic without credentials and replays it", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Header().Set("X-Served-By", "origin")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + string(body)))
+		}))
+		defer server.Close()
+
+		fixture := filepath.Join(t.TempDir(), "fixtures", "quizzes.json")
+		recorder, err := NewRecorder(fixture, ModeRecord, nil)
+		require.NoError(t, err)
+
+		status, body, err := sendTo(t, recorder, server.URL, http.MethodPost, "/quizzes?draft=true", "Fractions",
+			http.Header{"Authorization": {"Bearer token"}, "X-Tenant": {"acme"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, "POST /quizzes?draft=true Fractions", body)
+		require.NoError(t, recorder.Save())
+
+		raw, err := os.ReadFile(fixture)
+		require.NoError(t, err)
+		var interactions []Interaction
+		require.NoError(t, json.Unmarshal(raw, &interactions))
+		require.Len(t, interactions, 1)
+		assert.Equal(t, "/quizzes?draft=true", interactions[0].Request.URL)
+		assert.Equal(t, "Fractions", interactions[0].Request.Body)
+		assert.Empty(t, interactions[0].Request.Header.Get("Authorization"))
+		assert.Equal(t, "acme", interactions[0].Request.Header.Get("X-Tenant"))
+		assert.Empty(t, interactions[0].Response.Header.Get("Set-Cookie"))
+		assert.Equal(t, "origin", interactions[0].Response.Header.Get("X-Served-By"))
+
+		server.Close()
+		replay, err := NewRecorder(fixture, ModeReplay, nil)
+		require.NoError(t, err)
+
+		status, body, err = sendTo(t, replay, "https://elsewhere.example.com", http.MethodPost, "/quizzes?draft=true", "Fractions", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, "POST /quizzes?draft=true Fractions", body)
+	})
+
+	t.Run("Replays each interaction once", func(t *testing.T) {
+		fixture := writeFixture(t, []Interaction{
+			{Request: RecordedRequest{Method: http.MethodGet, URL: "/quizzes"}, Response: RecordedResponse{Status: http.StatusOK, Body: "first"}},
+			{Request: RecordedRequest{Method: http.MethodGet, URL: "/quizzes"}, Response: RecordedResponse{Status: http.StatusOK, Body: "second"}},
+		})
+		recorder, err := NewRecorder(fixture, ModeReplay, nil)
+		require.NoError(t, err)
+
+		var bodies []string
+		for i := 0; i < 2; i++ {
+			_, body, err := sendTo(t, recorder, "https://api.example.com", http.MethodGet, "/quizzes", "", nil)
+			require.NoError(t, err)
+			bodies = append(bodies, body)
+		}
+		assert.Equal(t, []string{"first", "second"}, bodies)
+
+		_, _, err = sendTo(t, recorder, "https://api.example.com", http.MethodGet, "/quizzes", "", nil)
+		assert.ErrorContains(t, err, "no recorded interaction for GET /quizzes")
+	})
+
+	t.Run("Matches on method, query and body", func(t *testing.T) {
+		fixture := writeFixture(t, []Interaction{
+			{Request: RecordedRequest{Method: http.MethodPost, URL: "/quizzes?draft=true", Body: "Fractions"}, Response: RecordedResponse{Status: http.StatusCreated}},
+		})
+		recorder, err := NewRecorder(fixture, ModeReplay, nil)
+		require.NoError(t, err)
+
+		for _, request := range []struct{ method, target, body string }{
+			{http.MethodPut, "/quizzes?draft=true", "Fractions"},
+			{http.MethodPost, "/quizzes", "Fractions"},
+			{http.MethodPost, "/quizzes?draft=true", "Algebra"},
+		} {
+			_, _, err := sendTo(t, recorder, "https://api.example.com", request.method, request.target, request.body, nil)
+			assert.Error(t, err, request)
+		}
+	})
+
+	t.Run("Requires the fixture in replay mode", func(t *testing.T) {
+		_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil)
+		assert.ErrorContains(t, err, "error reading fixture")
+
+		invalid := filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(invalid, []byte("not json"), 0o644))
+		_, err = NewRecorder(invalid, ModeReplay, nil)
+		assert.ErrorContains(t, err, "error decoding fixture")
+	})
+
+	t.Run("Save does nothing in replay mode", func(t *testing.T) {
+		fixture := writeFixture(t, nil)
+		before, err := os.ReadFile(fixture)
+		require.NoError(t, err)
+
+		recorder, err := NewRecorder(fixture, ModeReplay, nil)
+		require.NoError(t, err)
+		require.NoError(t, recorder.Save())
+
+		after, err := os.ReadFile(fixture)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+}
+
+// sendTo performs a request against baseURL through transport and returns the status and body
+func sendTo(t *testing.T, transport http.RoundTripper, baseURL, method, target, body string, header http.Header) (int, string, error) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, baseURL+target, reader)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(raw), nil
+}
+
+// writeFixture writes interactions to a fixture file in a temporary directory
+func writeFixture(t *testing.T, interactions []Interaction) string {
+	t.Helper()
+	raw, err := json.Marshal(interactions)
+	require.NoError(t, err)
+	fixture := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, os.WriteFile(fixture, raw, 0o644))
+	return fixture
+}
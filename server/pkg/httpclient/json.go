@@ -0,0 +1,55 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Call describes a request sent by the typed helpers
+type Call struct {
+	Method  string
+	Path    string
+	Body    interface{}
+	Options []RequestOption
+}
+
+// DoJSON sends call and decodes a successful JSON response into a T
+// Error responses are returned as *APIError and never decoded into T.
+func DoJSON[T any](ctx context.Context, client *Client, call Call) (T, error) {
+	var result T
+
+	resp, err := client.Request(ctx, call.Method, call.Path, call.Body, call.Options...)
+	if err != nil {
+		return result, err
+	}
+
+	err = client.decodeJSON(resp, &result)
+	return result, err
+}
+
+// GetJSONAs performs a GET request and decodes a successful JSON response into a T
+func GetJSONAs[T any](ctx context.Context, client *Client, urlPath string, opts ...RequestOption) (T, error) {
+	return DoJSON[T](ctx, client, Call{Method: http.MethodGet, Path: urlPath, Options: opts})
+}
+
+// PostJSONAs performs a POST request and decodes a successful JSON response into a T
+func PostJSONAs[T any](ctx context.Context, client *Client, urlPath string, body interface{}, opts ...RequestOption) (T, error) {
+	return DoJSON[T](ctx, client, Call{Method: http.MethodPost, Path: urlPath, Body: body, Options: opts})
+}
+
+// decodeJSON decodes a successful response into target, returning *StatusError for error statuses
+// Empty bodies (e.g. 204 No Content) leave target untouched.
+func (c *Client) decodeJSON(resp *Response, target interface{}) error {
+	if err := c.CheckStatus(resp); err != nil {
+		return err
+	}
+	if target == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, target); err != nil {
+		return fmt.Errorf("error decoding response body: %w", err)
+	}
+	return nil
+}
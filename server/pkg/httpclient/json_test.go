@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testQuiz struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+func TestJSONHelpers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Decodes successful responses", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("fields") != "title" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			respondJSON(w, http.StatusOK, testQuiz{ID: "q1", Title: "Algebra"})
+		})
+
+		quiz, err := GetJSONAs[testQuiz](ctx, client, "/quizzes/q1", WithQuery("fields", "title"))
+		require.NoError(t, err)
+		assert.Equal(t, testQuiz{ID: "q1", Title: "Algebra"}, quiz)
+	})
+
+	t.Run("Sends bodies as JSON", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			var quiz testQuiz
+			if err := json.NewDecoder(r.Body).Decode(&quiz); err != nil || quiz.Title != "Algebra" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			quiz.ID = "q1"
+			respondJSON(w, http.StatusCreated, quiz)
+		})
+
+		quiz, err := PostJSONAs[testQuiz](ctx, client, "/quizzes", testQuiz{Title: "Algebra"})
+		require.NoError(t, err)
+		assert.Equal(t, "q1", quiz.ID)
+
+		var created testQuiz
+		require.NoError(t, client.PostJSON(ctx, "/quizzes", testQuiz{Title: "Algebra"}, &created, nil))
+		assert.Equal(t, "q1", created.ID)
+	})
+
+	t.Run("Never decodes error responses", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			respondJSON(w, http.StatusNotFound, map[string]string{"id": "error-id"})
+		})
+
+		quiz, err := GetJSONAs[testQuiz](ctx, client, "/quizzes/q1")
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Empty(t, quiz.ID)
+
+		var target testQuiz
+		assert.ErrorAs(t, client.GetJSON(ctx, "/quizzes/q1", nil, &target), &apiErr)
+		assert.Empty(t, target.ID)
+	})
+
+	t.Run("Leaves the target untouched for empty bodies", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		target := testQuiz{ID: "kept"}
+		require.NoError(t, client.PutJSON(ctx, "/quizzes/q1", testQuiz{Title: "Algebra"}, &target, nil))
+		assert.Equal(t, "kept", target.ID)
+	})
+
+	t.Run("Reports invalid JSON", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("<html>"))
+		})
+
+		_, err := DoJSON[testQuiz](ctx, client, Call{Method: http.MethodGet, Path: "/quizzes/q1"})
+		assert.ErrorContains(t, err, "error decoding response body")
+	})
+}
@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// ErrQueueFull is returned when a request finds the concurrency limiter's queue full
+var ErrQueueFull = errors.New("httpclient: concurrency limit reached and queue is full")
+
+// ErrQueueTimeout is returned when a request waited QueueTimeout without getting a slot
+var ErrQueueTimeout = errors.New("httpclient: timed out waiting for a concurrency slot")
+
+// ConcurrencyConfig bounds the number of requests a client has in flight
+type ConcurrencyConfig struct {
+	// MaxConcurrent is the maximum number of requests in flight
+	MaxConcurrent int
+
+	// MaxQueue is the maximum number of requests waiting for a slot; further requests fail with ErrQueueFull
+	MaxQueue int
+
+	// QueueTimeout is the longest a request waits for a slot before failing with ErrQueueTimeout (0 waits
+	// until the request context ends)
+	QueueTimeout time.Duration
+
+	// PerHost applies the limits to each host separately instead of to the whole client
+	PerHost bool
+}
+
+// semaphore is a slot pool with a bounded wait queue
+type semaphore struct {
+	slots  chan struct{}
+	queued atomic.Int64
+}
+
+// limiter hands out concurrency slots to request attempts
+type limiter struct {
+	cfg ConcurrencyConfig
+
+	mu    sync.Mutex
+	hosts map[string]*semaphore
+
+	inFlight  metric.Int64UpDownCounter
+	queued    metric.Int64UpDownCounter
+	rejected  metric.Int64Counter
+	queueWait metric.Float64Histogram
+}
+
+// newLimiter creates a limiter and its instruments
+func newLimiter(cfg ConcurrencyConfig) *limiter {
+	l := &limiter{cfg: cfg, hosts: make(map[string]*semaphore)}
+	meter := otel.Meter("httpclient")
+
+	var err error
+	l.inFlight, err = meter.Int64UpDownCounter("httpclient.concurrency.in_flight",
+		metric.WithDescription("Number of outgoing requests holding a concurrency slot"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient in-flight counter", zap.Error(err))
+	}
+
+	l.queued, err = meter.Int64UpDownCounter("httpclient.concurrency.queued",
+		metric.WithDescription("Number of outgoing requests waiting for a concurrency slot"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient queue counter", zap.Error(err))
+	}
+
+	l.rejected, err = meter.Int64Counter("httpclient.concurrency.rejected",
+		metric.WithDescription("Number of outgoing requests rejected by the concurrency limiter by reason"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient rejection counter", zap.Error(err))
+	}
+
+	l.queueWait, err = meter.Float64Histogram("httpclient.concurrency.queue_wait",
+		metric.WithDescription("Time outgoing requests waited for a concurrency slot"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient queue wait histogram", zap.Error(err))
+	}
+
+	return l
+}
+
+// semaphoreFor returns the semaphore limiting requests to host
+func (l *limiter) semaphoreFor(host string) *semaphore {
+	if !l.cfg.PerHost {
+		host = ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.hosts[host]
+	if !ok {
+		sem = &semaphore{slots: make(chan struct{}, l.cfg.MaxConcurrent)}
+		l.hosts[host] = sem
+	}
+	return sem
+}
+
+// acquire waits for a slot for host, returning the function releasing it
+func (l *limiter) acquire(ctx context.Context, host string) (func(), error) {
+	sem := l.semaphoreFor(host)
+	hostAttr := metric.WithAttributes(attribute.String("host", host))
+
+	release := func() {
+		<-sem.slots
+		if l.inFlight != nil {
+			l.inFlight.Add(ctx, -1, hostAttr)
+		}
+	}
+
+	// Fast path: a slot is free
+	select {
+	case sem.slots <- struct{}{}:
+		if l.inFlight != nil {
+			l.inFlight.Add(ctx, 1, hostAttr)
+		}
+		return release, nil
+	default:
+	}
+
+	if sem.queued.Add(1) > int64(l.cfg.MaxQueue) {
+		sem.queued.Add(-1)
+		l.reject(ctx, host, "queue_full")
+		return nil, ErrQueueFull
+	}
+	defer sem.queued.Add(-1)
+
+	if l.queued != nil {
+		l.queued.Add(ctx, 1, hostAttr)
+		defer l.queued.Add(ctx, -1, hostAttr)
+	}
+
+	var timeout <-chan time.Time
+	if l.cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(l.cfg.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	start := time.Now()
+	defer func() {
+		if l.queueWait != nil {
+			l.queueWait.Record(ctx, time.Since(start).Seconds(), hostAttr)
+		}
+	}()
+
+	select {
+	case sem.slots <- struct{}{}:
+		if l.inFlight != nil {
+			l.inFlight.Add(ctx, 1, hostAttr)
+		}
+		return release, nil
+	case <-timeout:
+		l.reject(ctx, host, "queue_timeout")
+		return nil, ErrQueueTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reject records a rejected request
+func (l *limiter) reject(ctx context.Context, host, reason string) {
+	logger.WarnCtx(ctx, "Outgoing request rejected by concurrency limiter",
+		zap.String("host", host),
+		zap.String("reason", reason),
+	)
+	if l.rejected != nil {
+		l.rejected.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("host", host),
+			attribute.String("reason", reason),
+		))
+	}
+}
+
+// middleware holds a slot for the duration of each request attempt
+// The slot is released once the response body is closed, so streamed responses count as in flight.
+func (l *limiter) middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			release, err := l.acquire(req.Context(), req.URL.Host)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		})
+	}
+}
+
+// releasingBody releases a concurrency slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Queued requests get released slots", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1, MaxQueue: 1})
+		release, err := l.acquire(ctx, "a")
+		require.NoError(t, err)
+
+		acquired := make(chan func())
+		go func() {
+			next, err := l.acquire(ctx, "a")
+			assert.NoError(t, err)
+			acquired <- next
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("acquired a slot while none was free")
+		case <-time.After(20 * time.Millisecond):
+		}
+		release()
+		(<-acquired)()
+	})
+
+	t.Run("Rejects requests when the queue is full", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1})
+		release, err := l.acquire(ctx, "a")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.acquire(ctx, "a")
+		assert.ErrorIs(t, err, ErrQueueFull)
+	})
+
+	t.Run("Gives up after QueueTimeout", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond})
+		release, err := l.acquire(ctx, "a")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.acquire(ctx, "a")
+		assert.ErrorIs(t, err, ErrQueueTimeout)
+	})
+
+	t.Run("Stops waiting when the context ends", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1, MaxQueue: 1})
+		release, err := l.acquire(ctx, "a")
+		require.NoError(t, err)
+		defer release()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = l.acquire(cancelled, "a")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Limits hosts separately when PerHost is set", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1, PerHost: true})
+		release, err := l.acquire(ctx, "a")
+		require.NoError(t, err)
+		defer release()
+
+		other, err := l.acquire(ctx, "b")
+		require.NoError(t, err)
+		other()
+
+		_, err = l.acquire(ctx, "a")
+		assert.ErrorIs(t, err, ErrQueueFull)
+	})
+
+	t.Run("Shares the limit across hosts by default", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1})
+		release, err := l.acquire(ctx, "a")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.acquire(ctx, "b")
+		assert.ErrorIs(t, err, ErrQueueFull)
+	})
+
+	t.Run("Holds the slot until the response body is closed", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1})
+		transport := l.middleware()(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/quizzes", nil)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		_, err = transport.RoundTrip(req)
+		assert.ErrorIs(t, err, ErrQueueFull)
+
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, resp.Body.Close())
+		resp, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("Releases the slot when the request fails", func(t *testing.T) {
+		l := newLimiter(ConcurrencyConfig{MaxConcurrent: 1})
+		transport := l.middleware()(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}))
+
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/quizzes", nil)
+		for i := 0; i < 2; i++ {
+			_, err := transport.RoundTrip(req)
+			assert.EqualError(t, err, "connection refused")
+		}
+	})
+
+	t.Run("Clients do not retry rejected requests", func(t *testing.T) {
+		started, unblock := make(chan struct{}), make(chan struct{})
+		calls := 0
+		client := newTestClient(t, "https://api.example.com", RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			close(started)
+			<-unblock
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+		}), fastRetries, func(cfg *Config) {
+			cfg.Concurrency = &ConcurrencyConfig{MaxConcurrent: 1}
+		})
+
+		done := make(chan error)
+		go func() {
+			_, err := client.Get(ctx, "/slow", nil)
+			done <- err
+		}()
+		<-started
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		assert.ErrorIs(t, err, ErrQueueFull)
+
+		close(unblock)
+		assert.NoError(t, <-done)
+		assert.Equal(t, 1, calls)
+	})
+}
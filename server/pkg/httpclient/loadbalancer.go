@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// LoadBalanceStrategy selects the endpoint serving a request
+type LoadBalanceStrategy string
+
+// Supported load balancing strategies
+const (
+	RoundRobin   LoadBalanceStrategy = "round_robin"
+	LeastPending LoadBalanceStrategy = "least_pending"
+)
+
+// LoadBalancerConfig spreads requests over several instances of a service without an external load balancer
+type LoadBalancerConfig struct {
+	// Endpoints are the scheme and host of each instance (e.g. http://10.0.0.1:8080);
+	// the path of BaseURL is kept
+	Endpoints []string
+
+	// Strategy selects endpoints; defaults to RoundRobin
+	Strategy LoadBalanceStrategy
+
+	// FailureThreshold is the number of consecutive failures (errors or 5xx) that eject an endpoint
+	FailureThreshold int
+
+	// EjectionDuration is how long an ejected endpoint receives no requests
+	EjectionDuration time.Duration
+}
+
+// EndpointState describes a load balanced endpoint
+type EndpointState struct {
+	URL      string
+	Pending  int64
+	Failures int
+	Ejected  bool
+}
+
+// balancedEndpoint tracks the load and health of one endpoint
+type balancedEndpoint struct {
+	url     *url.URL
+	pending atomic.Int64
+
+	mu           sync.Mutex
+	failures     int
+	ejectedUntil time.Time
+}
+
+// available reports whether the endpoint is not ejected
+func (e *balancedEndpoint) available(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.ejectedUntil)
+}
+
+// loadBalancer picks endpoints and ejects failing ones
+type loadBalancer struct {
+	cfg       LoadBalancerConfig
+	endpoints []*balancedEndpoint
+	next      atomic.Uint64
+}
+
+// newLoadBalancer parses the configured endpoints
+func newLoadBalancer(cfg LoadBalancerConfig) (*loadBalancer, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("load balancer needs at least one endpoint")
+	}
+	if cfg.Strategy == "" {
+		cfg.Strategy = RoundRobin
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.EjectionDuration <= 0 {
+		cfg.EjectionDuration = 30 * time.Second
+	}
+
+	lb := &loadBalancer{cfg: cfg}
+	for _, raw := range cfg.Endpoints {
+		endpoint, err := url.Parse(raw)
+		if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid load balancer endpoint %q", raw)
+		}
+		lb.endpoints = append(lb.endpoints, &balancedEndpoint{url: endpoint})
+	}
+	return lb, nil
+}
+
+// pick selects the endpoint for the next request
+// When every endpoint is ejected, all of them are considered again rather than failing requests.
+func (lb *loadBalancer) pick() *balancedEndpoint {
+	now := time.Now()
+	candidates := make([]*balancedEndpoint, 0, len(lb.endpoints))
+	for _, endpoint := range lb.endpoints {
+		if endpoint.available(now) {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = lb.endpoints
+	}
+
+	offset := int(lb.next.Add(1) % uint64(len(candidates)))
+	if lb.cfg.Strategy != LeastPending {
+		return candidates[offset]
+	}
+
+	// Start at a rotating offset so ties are spread instead of always hitting the first endpoint
+	best := candidates[offset]
+	for i := 1; i < len(candidates); i++ {
+		candidate := candidates[(offset+i)%len(candidates)]
+		if candidate.pending.Load() < best.pending.Load() {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// report records the outcome of a request to endpoint, ejecting it after too many failures
+func (lb *loadBalancer) report(endpoint *balancedEndpoint, failed bool) {
+	endpoint.mu.Lock()
+	defer endpoint.mu.Unlock()
+
+	if !failed {
+		endpoint.failures = 0
+		return
+	}
+
+	endpoint.failures++
+	if endpoint.failures >= lb.cfg.FailureThreshold {
+		endpoint.failures = 0
+		endpoint.ejectedUntil = time.Now().Add(lb.cfg.EjectionDuration)
+		logger.Warn("Ejected failing endpoint",
+			zap.String("endpoint", endpoint.url.String()),
+			zap.Duration("duration", lb.cfg.EjectionDuration),
+		)
+	}
+}
+
+// states returns the state of every endpoint
+func (lb *loadBalancer) states() []EndpointState {
+	now := time.Now()
+	states := make([]EndpointState, 0, len(lb.endpoints))
+	for _, endpoint := range lb.endpoints {
+		endpoint.mu.Lock()
+		states = append(states, EndpointState{
+			URL:      endpoint.url.String(),
+			Pending:  endpoint.pending.Load(),
+			Failures: endpoint.failures,
+			Ejected:  now.Before(endpoint.ejectedUntil),
+		})
+		endpoint.mu.Unlock()
+	}
+	return states
+}
+
+// middleware sends each request attempt to the picked endpoint, so retries can land on another instance
+func (lb *loadBalancer) middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint := lb.pick()
+
+			req = req.Clone(req.Context())
+			req.URL.Scheme = endpoint.url.Scheme
+			req.URL.Host = endpoint.url.Host
+			req.Host = endpoint.url.Host
+
+			endpoint.pending.Add(1)
+			defer endpoint.pending.Add(-1)
+
+			resp, err := next.RoundTrip(req)
+			lb.report(endpoint, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+			return resp, err
+		})
+	}
+}
+
+// Endpoints returns the state of the load balanced endpoints, or nil without load balancing
+func (c *Client) Endpoints() []EndpointState {
+	if c.balancer == nil {
+		return nil
+	}
+	return c.balancer.states()
+}
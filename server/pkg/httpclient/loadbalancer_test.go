@@ -0,0 +1,142 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hostRecorder is a transport answering with the status of each host and recording the hosts
+// and paths requests were sent to
+type hostRecorder struct {
+	mu       sync.Mutex
+	hosts    []string
+	paths    []string
+	statuses map[string]int
+}
+
+func (r *hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.hosts = append(r.hosts, req.URL.Host)
+	r.paths = append(r.paths, req.URL.Path)
+	status, ok := r.statuses[req.URL.Host]
+	r.mu.Unlock()
+
+	if !ok {
+		status = http.StatusOK
+	}
+	if status == 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func TestLoadBalancer(t *testing.T) {
+	ctx := context.Background()
+	endpoints := []string{"http://10.0.0.1:8080", "http://10.0.0.2:8080", "http://10.0.0.3:8080"}
+
+	t.Run("Round robin spreads requests and keeps the base path", func(t *testing.T) {
+		transport := &hostRecorder{}
+		client := newTestClient(t, "https://quizzes.internal/api/v1", transport, func(cfg *Config) {
+			cfg.LoadBalancer = &LoadBalancerConfig{Endpoints: endpoints}
+		})
+
+		for i := 0; i < 6; i++ {
+			resp, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		counts := make(map[string]int)
+		for _, host := range transport.hosts {
+			counts[host]++
+		}
+		assert.Equal(t, map[string]int{"10.0.0.1:8080": 2, "10.0.0.2:8080": 2, "10.0.0.3:8080": 2}, counts)
+		assert.Equal(t, "/api/v1/quizzes", transport.paths[0])
+	})
+
+	t.Run("Ejects endpoints after consecutive failures", func(t *testing.T) {
+		transport := &hostRecorder{statuses: map[string]int{"10.0.0.2:8080": http.StatusBadGateway}}
+		client := newTestClient(t, "https://quizzes.internal", transport, func(cfg *Config) {
+			cfg.LoadBalancer = &LoadBalancerConfig{Endpoints: endpoints, FailureThreshold: 2, EjectionDuration: time.Minute}
+		})
+
+		for i := 0; i < 6; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+		}
+		transport.hosts = nil
+		for i := 0; i < 4; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+		}
+
+		assert.NotContains(t, transport.hosts, "10.0.0.2:8080")
+		for _, state := range client.Endpoints() {
+			assert.Equal(t, state.URL == "http://10.0.0.2:8080", state.Ejected, state.URL)
+		}
+	})
+
+	t.Run("Transport errors count as failures", func(t *testing.T) {
+		lb, err := newLoadBalancer(LoadBalancerConfig{Endpoints: endpoints[:1], FailureThreshold: 1})
+		require.NoError(t, err)
+
+		transport := &hostRecorder{statuses: map[string]int{"10.0.0.1:8080": 0}}
+		req, _ := http.NewRequest(http.MethodGet, "https://quizzes.internal/quizzes", nil)
+		_, err = lb.middleware()(transport).RoundTrip(req)
+		assert.Error(t, err)
+		assert.True(t, lb.states()[0].Ejected)
+	})
+
+	t.Run("Uses ejected endpoints when all are ejected", func(t *testing.T) {
+		lb, err := newLoadBalancer(LoadBalancerConfig{Endpoints: endpoints[:2], FailureThreshold: 1})
+		require.NoError(t, err)
+		for _, endpoint := range lb.endpoints {
+			lb.report(endpoint, true)
+		}
+
+		assert.NotNil(t, lb.pick())
+	})
+
+	t.Run("Successes reset the failure count", func(t *testing.T) {
+		lb, err := newLoadBalancer(LoadBalancerConfig{Endpoints: endpoints[:1], FailureThreshold: 2})
+		require.NoError(t, err)
+		endpoint := lb.endpoints[0]
+
+		lb.report(endpoint, true)
+		lb.report(endpoint, false)
+		lb.report(endpoint, true)
+		assert.False(t, lb.states()[0].Ejected)
+		assert.Equal(t, 1, lb.states()[0].Failures)
+	})
+
+	t.Run("Least pending picks the least loaded endpoint", func(t *testing.T) {
+		lb, err := newLoadBalancer(LoadBalancerConfig{Endpoints: endpoints, Strategy: LeastPending})
+		require.NoError(t, err)
+		lb.endpoints[0].pending.Store(3)
+		lb.endpoints[1].pending.Store(1)
+		lb.endpoints[2].pending.Store(2)
+
+		for i := 0; i < 3; i++ {
+			assert.Same(t, lb.endpoints[1], lb.pick())
+		}
+	})
+
+	t.Run("Rejects invalid endpoints", func(t *testing.T) {
+		_, err := newLoadBalancer(LoadBalancerConfig{})
+		assert.Error(t, err)
+		_, err = newLoadBalancer(LoadBalancerConfig{Endpoints: []string{"10.0.0.1:8080"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("Clients without load balancing have no endpoints", func(t *testing.T) {
+		client := newTestClient(t, "https://quizzes.internal", &hostRecorder{})
+		assert.Nil(t, client.Endpoints())
+	})
+}
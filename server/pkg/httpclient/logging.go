@@ -0,0 +1,157 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/redact"
+)
+
+// LoggingConfig controls the request and response details logged when Config.Debug is set
+type LoggingConfig struct {
+	// MaxBodySize caps the number of body bytes logged; bodies are truncated beyond it (0 logs no bodies)
+	MaxBodySize int
+
+	// ContentTypes lists the media types whose bodies are logged; defaults to JSON, form, XML and text
+	ContentTypes []string
+
+	// RedactHeaders lists headers logged as [REDACTED], in addition to DefaultRedactHeaders
+	RedactHeaders []string
+
+	// RedactFields lists JSON and form field names (case-insensitive) whose values are logged as
+	// [REDACTED], in addition to DefaultRedactFields; query parameters are redacted the same way
+	RedactFields []string
+
+	// RedactPatterns replace matches in logged bodies, e.g. card numbers in free text
+	RedactPatterns []*regexp.Regexp
+}
+
+// DefaultRedactHeaders are always redacted from logs
+var DefaultRedactHeaders = redact.DefaultHeaders
+
+// DefaultRedactFields are body fields and query parameters always redacted from logs
+var DefaultRedactFields = redact.DefaultFields
+
+// defaultLoggedContentTypes are the media types whose bodies are logged by default
+var defaultLoggedContentTypes = []string{
+	"application/json", "application/x-www-form-urlencoded", "application/xml", "text/plain", "text/xml",
+}
+
+// exchangeLogger logs outgoing requests and their responses with secrets redacted
+type exchangeLogger struct {
+	cfg          LoggingConfig
+	redactor     *redact.Redactor
+	contentTypes map[string]bool
+}
+
+// newExchangeLogger creates the logger of a logging configuration
+func newExchangeLogger(cfg LoggingConfig) *exchangeLogger {
+	l := &exchangeLogger{
+		cfg:          cfg,
+		redactor:     redact.New(cfg.RedactHeaders, cfg.RedactFields, cfg.RedactPatterns),
+		contentTypes: make(map[string]bool),
+	}
+	contentTypes := cfg.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultLoggedContentTypes
+	}
+	for _, contentType := range contentTypes {
+		l.contentTypes[contentType] = true
+	}
+	return l
+}
+
+// middleware logs every request attempt as it is sent, after authentication and signing
+func (l *exchangeLogger) middleware() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+
+			fields := []zap.Field{
+				zap.String("method", req.Method),
+				zap.String("url", l.redactor.URL(req.URL)),
+				zap.Any("headers", l.redactor.Header(req.Header)),
+			}
+			if body, ok := l.requestBody(req); ok {
+				fields = append(fields, zap.String("body", body))
+			}
+			logger.InfoCtx(ctx, "HTTP request detail", fields...)
+
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = []zap.Field{
+				zap.Int("statusCode", resp.StatusCode),
+				zap.Any("headers", l.redactor.Header(resp.Header)),
+			}
+			if body, ok := l.responseBody(resp); ok {
+				fields = append(fields, zap.String("body", body))
+			}
+			logger.InfoCtx(ctx, "HTTP response detail", fields...)
+
+			return resp, nil
+		})
+	}
+}
+
+// requestBody returns the loggable part of the request body without consuming it
+// Streamed bodies that cannot be read twice are not logged.
+func (l *exchangeLogger) requestBody(req *http.Request) (string, bool) {
+	if req.Body == nil || req.GetBody == nil || !l.loggable(req.Header) {
+		return "", false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+
+	prefix, _ := io.ReadAll(io.LimitReader(body, int64(l.cfg.MaxBodySize)+1))
+	return l.formatBody(prefix, req.Header.Get("Content-Type")), true
+}
+
+// responseBody returns the loggable part of the response body, leaving the body intact for the caller
+func (l *exchangeLogger) responseBody(resp *http.Response) (string, bool) {
+	if resp.Body == nil || !l.loggable(resp.Header) {
+		return "", false
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(resp.Body, int64(l.cfg.MaxBodySize)+1))
+	resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(prefix), resp.Body), Closer: resp.Body}
+	if err != nil {
+		return "", false
+	}
+	return l.formatBody(prefix, resp.Header.Get("Content-Type")), true
+}
+
+// prefixedBody replays an already read prefix before the rest of a body
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// loggable reports whether bodies with these headers are logged
+func (l *exchangeLogger) loggable(header http.Header) bool {
+	if l.cfg.MaxBodySize <= 0 {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && l.contentTypes[mediaType]
+}
+
+// formatBody redacts a body prefix and marks it when it was truncated
+// Truncated JSON and form bodies cannot be parsed, so only patterns are redacted in them.
+func (l *exchangeLogger) formatBody(prefix []byte, contentType string) string {
+	if len(prefix) > l.cfg.MaxBodySize {
+		return l.redactor.Text(string(prefix[:l.cfg.MaxBodySize])) + "...(truncated)"
+	}
+	return l.redactor.Body(prefix, contentType)
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// clientMetrics holds the instruments recording a client's outgoing requests
+// Instruments come from the global meter provider, so they are exported on the Prometheus endpoint.
+type clientMetrics struct {
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+	retries  metric.Int64Counter
+
+	budgetExhausted metric.Int64Counter
+}
+
+// newClientMetrics creates the request instruments and a gauge reporting the circuit breaker
+// states of c, labelled with the client's service name
+func newClientMetrics(c *Client) *clientMetrics {
+	meter := otel.Meter("httpclient")
+	m := &clientMetrics{}
+
+	var err error
+	m.requests, err = meter.Int64Counter("httpclient.requests",
+		metric.WithDescription("Number of outgoing HTTP requests by service, method, host and status class"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient request counter", zap.Error(err))
+	}
+
+	m.duration, err = meter.Float64Histogram("httpclient.request.duration",
+		metric.WithDescription("Duration of outgoing HTTP requests"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient duration histogram", zap.Error(err))
+	}
+
+	m.retries, err = meter.Int64Counter("httpclient.retries",
+		metric.WithDescription("Number of retried outgoing HTTP requests"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient retry counter", zap.Error(err))
+	}
+
+	m.budgetExhausted, err = meter.Int64Counter("httpclient.retry_budget_exhausted",
+		metric.WithDescription("Number of retries skipped because the retry budget was exhausted"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create httpclient retry budget counter", zap.Error(err))
+	}
+
+	if c.config.CircuitBreaker.Enabled {
+		_, err = meter.Int64ObservableGauge("httpclient.circuit_breaker.state",
+			metric.WithDescription("Circuit breaker state (0 closed, 1 half-open, 2 open)"),
+			metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+				for _, breaker := range c.breakers.all() {
+					o.Observe(breakerStateValue(breaker.State()),
+						metric.WithAttributes(
+							attribute.String("service", c.serviceName),
+							attribute.String("breaker", breaker.Name()),
+						),
+					)
+				}
+				return nil
+			}),
+		)
+		if err != nil {
+			logger.Warn("Failed to create httpclient circuit breaker gauge", zap.Error(err))
+		}
+	}
+
+	return m
+}
+
+// recordRequest records one attempt of an outgoing request; statusCode 0 means it failed before a response
+func (m *clientMetrics) recordRequest(ctx context.Context, service, method, host string, statusCode int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("method", method),
+		attribute.String("host", host),
+		attribute.String("status_class", statusClass(statusCode)),
+	)
+	if m.requests != nil {
+		m.requests.Add(ctx, 1, attrs)
+	}
+	if m.duration != nil {
+		m.duration.Record(ctx, duration.Seconds(), attrs)
+	}
+}
+
+// recordRetry counts a retried attempt
+func (m *clientMetrics) recordRetry(ctx context.Context, service, method, host string) {
+	if m == nil || m.retries == nil {
+		return
+	}
+
+	m.retries.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("service", service),
+		attribute.String("method", method),
+		attribute.String("host", host),
+	))
+}
+
+// recordBudgetExhausted counts a retry skipped for lack of retry budget
+func (m *clientMetrics) recordBudgetExhausted(ctx context.Context, service string) {
+	if m == nil || m.budgetExhausted == nil {
+		return
+	}
+
+	m.budgetExhausted.Add(ctx, 1, metric.WithAttributes(attribute.String("service", service)))
+}
+
+// statusClass groups status codes into 2xx, 3xx, 4xx, 5xx, or "error" when no response was received
+func statusClass(statusCode int) string {
+	if statusCode < 100 {
+		return "error"
+	}
+	return strconv.Itoa(statusCode/100) + "xx"
+}
+
+// breakerStateValue maps a circuit breaker state to its gauge value
+func breakerStateValue(state gobreaker.State) int64 {
+	switch state {
+	case gobreaker.StateHalfOpen:
+		return 1
+	case gobreaker.StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,203 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// withMetrics installs a global meter provider read by the returned reader until the test ends
+// Clients read the global provider when they are created, so create them afterwards.
+func withMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+	return reader
+}
+
+// collectMetrics returns the data of the metrics read by reader, by metric name
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &data))
+
+	collected := make(map[string]metricdata.Aggregation)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			collected[m.Name] = m.Data
+		}
+	}
+	return collected
+}
+
+// sumValue returns the value of a counter with the given attributes
+func sumValue(data metricdata.Aggregation, attrs ...attribute.KeyValue) int64 {
+	set := attribute.NewSet(attrs...)
+	sum, _ := data.(metricdata.Sum[int64])
+	for _, point := range sum.DataPoints {
+		if point.Attributes.Equals(&set) {
+			return point.Value
+		}
+	}
+	return 0
+}
+
+// gaugeValue returns the value of a gauge with the given attributes
+func gaugeValue(data metricdata.Aggregation, attrs ...attribute.KeyValue) int64 {
+	set := attribute.NewSet(attrs...)
+	gauge, _ := data.(metricdata.Gauge[int64])
+	for _, point := range gauge.DataPoints {
+		if point.Attributes.Equals(&set) {
+			return point.Value
+		}
+	}
+	return -1
+}
+
+// requestAttributes are the attributes of requests of the quizzes service to the server of client
+func requestAttributes(client *Client, method, class string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("service", "quizzes"),
+		attribute.String("method", method),
+		attribute.String("host", client.baseURL.Host),
+		attribute.String("status_class", class),
+	}
+}
+
+// quizzesService names the client's service in metrics
+func quizzesService(cfg *Config) {
+	cfg.ServiceName = "quizzes"
+}
+
+func TestClientMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Counts and times requests by status class", func(t *testing.T) {
+		reader := withMetrics(t)
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost:
+				dropConnection(w)
+			case r.URL.Path == "/missing":
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				_, _ = w.Write([]byte("[]"))
+			}
+		}, quizzesService)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.NoError(t, err)
+		}
+		resp, err := client.Get(ctx, "/missing", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_, err = client.Post(ctx, "/quizzes", nil, nil)
+		require.Error(t, err)
+
+		collected := collectMetrics(t, reader)
+		requests := collected["httpclient.requests"]
+		assert.Equal(t, int64(2), sumValue(requests, requestAttributes(client, http.MethodGet, "2xx")...))
+		assert.Equal(t, int64(1), sumValue(requests, requestAttributes(client, http.MethodGet, "4xx")...))
+		assert.Equal(t, int64(1), sumValue(requests, requestAttributes(client, http.MethodPost, "error")...))
+
+		set := attribute.NewSet(requestAttributes(client, http.MethodGet, "2xx")...)
+		var count uint64
+		for _, point := range collected["httpclient.request.duration"].(metricdata.Histogram[float64]).DataPoints {
+			if point.Attributes.Equals(&set) {
+				count = point.Count
+			}
+		}
+		assert.Equal(t, uint64(2), count)
+	})
+
+	t.Run("Counts every attempt and retry", func(t *testing.T) {
+		reader := withMetrics(t)
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("[]"))
+		}, quizzesService, fastRetries)
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+
+		collected := collectMetrics(t, reader)
+		assert.Equal(t, int64(2), sumValue(collected["httpclient.requests"], requestAttributes(client, http.MethodGet, "5xx")...))
+		assert.Equal(t, int64(1), sumValue(collected["httpclient.requests"], requestAttributes(client, http.MethodGet, "2xx")...))
+		assert.Equal(t, int64(2), sumValue(collected["httpclient.retries"],
+			attribute.String("service", "quizzes"),
+			attribute.String("method", http.MethodGet),
+			attribute.String("host", client.baseURL.Host),
+		))
+	})
+
+	t.Run("Counts retries skipped for lack of budget", func(t *testing.T) {
+		reader := withMetrics(t)
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, quizzesService, fastRetries, func(cfg *Config) {
+			cfg.Retry.Budget = &RetryBudgetConfig{}
+		})
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.Error(t, err)
+		}
+
+		collected := collectMetrics(t, reader)
+		assert.Equal(t, int64(2), sumValue(collected["httpclient.retry_budget_exhausted"], attribute.String("service", "quizzes")))
+	})
+
+	t.Run("Reports circuit breaker states", func(t *testing.T) {
+		reader := withMetrics(t)
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			dropConnection(w)
+		}, quizzesService, withBreakers)
+
+		service := attribute.String("service", "quizzes")
+		state := func() (int64, int64) {
+			gauge := collectMetrics(t, reader)["httpclient.circuit_breaker.state"]
+			return gaugeValue(gauge, service, attribute.String("breaker", "quizzes")),
+				gaugeValue(gauge, service, attribute.String("breaker", "quizzes:/quizzes"))
+		}
+
+		base, _ := state()
+		assert.Equal(t, int64(0), base)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/quizzes", nil)
+			require.Error(t, err)
+		}
+
+		base, endpoint := state()
+		assert.Equal(t, int64(0), base)
+		assert.Equal(t, int64(2), endpoint)
+	})
+}
+
+func TestStatusClass(t *testing.T) {
+	for statusCode, want := range map[int]string{0: "error", 200: "2xx", 204: "2xx", 302: "3xx", 404: "4xx", 503: "5xx"} {
+		assert.Equal(t, want, statusClass(statusCode), statusCode)
+	}
+}
+
+func TestBreakerStateValue(t *testing.T) {
+	assert.Equal(t, int64(0), breakerStateValue(gobreaker.StateClosed))
+	assert.Equal(t, int64(1), breakerStateValue(gobreaker.StateHalfOpen))
+	assert.Equal(t, int64(2), breakerStateValue(gobreaker.StateOpen))
+}
@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Middleware wraps the transport of a client to observe or modify every attempt of every request
+// Typical uses are injecting credentials, signing requests, and rewriting responses.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripFunc adapts an ordinary function to http.RoundTripper
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use appends middleware to the client's chain; the first middleware added sees requests first
+func (c *Config) Use(mw ...Middleware) *Config {
+	c.Middlewares = append(c.Middlewares, mw...)
+	return c
+}
+
+// chainMiddleware wraps transport with middlewares so that middlewares[0] is outermost
+func chainMiddleware(transport http.RoundTripper, middlewares []Middleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		transport = middlewares[i](transport)
+	}
+	return transport
+}
+
+// SetHeader returns a middleware setting a header on every request unless it is already set
+func SetHeader(key, value string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(key) != "" {
+				return next.RoundTrip(req)
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(key, value)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// BearerToken returns a middleware authenticating requests with a token from source
+func BearerToken(source func(ctx context.Context) (string, error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := source(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("error getting bearer token: %w", err)
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer "+token)
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traceMiddleware appends name to the X-Chain header of requests
+func traceMiddleware(name string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Add("X-Chain", name)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Runs middlewares in the order they were added", func(t *testing.T) {
+		var chain []string
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			chain = r.Header.Values("X-Chain")
+		}, func(cfg *Config) {
+			cfg.Use(traceMiddleware("first")).Use(traceMiddleware("second"), traceMiddleware("third"))
+		})
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "first,second,third", strings.Join(chain, ","))
+	})
+
+	t.Run("Middlewares see every attempt", func(t *testing.T) {
+		attempts := 0
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}, fastRetries, func(cfg *Config) {
+			cfg.Use(func(next http.RoundTripper) http.RoundTripper {
+				return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+					attempts++
+					return next.RoundTrip(req)
+				})
+			})
+		})
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("SetHeader keeps headers set by the request", func(t *testing.T) {
+		var tenants []string
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			tenants = append(tenants, r.Header.Get("X-Tenant"))
+		}, func(cfg *Config) {
+			cfg.Use(SetHeader("X-Tenant", "default"))
+		})
+
+		_, err := client.Get(ctx, "/a", nil)
+		require.NoError(t, err)
+		_, err = client.Get(ctx, "/b", map[string]string{"X-Tenant": "t1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"default", "t1"}, tenants)
+	})
+
+	t.Run("BearerToken authenticates requests", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}, func(cfg *Config) {
+			cfg.Use(BearerToken(func(context.Context) (string, error) { return "token-1", nil }))
+		})
+
+		resp, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("BearerToken fails requests without a token", func(t *testing.T) {
+		called := false
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}, func(cfg *Config) {
+			cfg.Use(BearerToken(func(context.Context) (string, error) { return "", errors.New("vault sealed") }))
+		})
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		assert.ErrorContains(t, err, "vault sealed")
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,341 @@
+package httpclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"quizizz.com/internal/logger"
+)
+
+// OAuth2 grant types supported by OAuth2TokenSource
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantJWTBearer         = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+// DefaultTokenRefreshBefore is how long before expiry tokens are refreshed in the background
+const DefaultTokenRefreshBefore = time.Minute
+
+// OAuth2Config configures fetching access tokens from an OAuth2 token endpoint
+type OAuth2Config struct {
+	// TokenURL is the token endpoint
+	TokenURL string
+
+	// GrantType is GrantClientCredentials (default) or GrantJWTBearer
+	GrantType string
+
+	// ClientID and ClientSecret authenticate the client (sent with HTTP basic authentication)
+	ClientID     string
+	ClientSecret string
+
+	// Scopes requested for the token
+	Scopes []string
+
+	// Params are extra form parameters sent to the token endpoint (e.g. audience)
+	Params map[string]string
+
+	// Assertion signs the JWT presented with GrantJWTBearer
+	Assertion *JWTAssertion
+
+	// RefreshBefore is how long before expiry a token is refreshed in the background
+	RefreshBefore time.Duration
+
+	// Timeout bounds each call to the token endpoint
+	Timeout time.Duration
+}
+
+// JWTAssertion describes the self-signed RS256 JWT exchanged for a token with GrantJWTBearer
+type JWTAssertion struct {
+	Issuer     string
+	Subject    string
+	Audience   string
+	KeyID      string
+	PrivateKey *rsa.PrivateKey
+	TTL        time.Duration
+
+	// Claims are added to the registered claims, e.g. the scope Google expects in the assertion
+	Claims map[string]interface{}
+}
+
+// Token is an access token and its expiry
+type Token struct {
+	AccessToken string
+	TokenType   string
+	ExpiresAt   time.Time
+}
+
+// valid reports whether the token can still be used for at least margin
+func (t *Token) valid(margin time.Duration) bool {
+	return t != nil && t.AccessToken != "" && (t.ExpiresAt.IsZero() || time.Until(t.ExpiresAt) > margin)
+}
+
+// TokenSource provides access tokens
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// OAuth2TokenSource fetches, caches and proactively refreshes OAuth2 access tokens
+// Concurrent callers share a single in-flight token request.
+type OAuth2TokenSource struct {
+	config     OAuth2Config
+	httpClient *http.Client
+	group      singleflight.Group
+	failures   metric.Int64Counter
+
+	mu    sync.RWMutex
+	token *Token
+}
+
+// NewOAuth2TokenSource creates a token source calling the token endpoint through transport
+// A nil transport uses http.DefaultTransport.
+func NewOAuth2TokenSource(cfg OAuth2Config, transport http.RoundTripper) *OAuth2TokenSource {
+	if cfg.GrantType == "" {
+		cfg.GrantType = GrantClientCredentials
+	}
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = DefaultTokenRefreshBefore
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	failures, err := otel.Meter("httpclient").Int64Counter("httpclient.oauth2.refresh_failures",
+		metric.WithDescription("Number of failed OAuth2 token requests"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create oauth2 refresh failure counter", zap.Error(err))
+	}
+
+	return &OAuth2TokenSource{
+		config:     cfg,
+		httpClient: &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		failures:   failures,
+	}
+}
+
+// Token returns a valid access token, fetching one if the cached token expired
+// Tokens close to expiry are still returned while a refresh runs in the background.
+func (s *OAuth2TokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.RLock()
+	token := s.token
+	s.mu.RUnlock()
+
+	if token.valid(s.config.RefreshBefore) {
+		return token, nil
+	}
+
+	if token.valid(0) {
+		go func() {
+			_, _ = s.refresh(context.Background())
+		}()
+		return token, nil
+	}
+
+	return s.refresh(ctx)
+}
+
+// Invalidate drops the cached token, e.g. after the server rejected it
+func (s *OAuth2TokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
+
+// refresh fetches a new token, sharing the request with concurrent callers
+func (s *OAuth2TokenSource) refresh(ctx context.Context) (*Token, error) {
+	result, err, _ := s.group.Do("token", func() (interface{}, error) {
+		token, err := s.fetch(ctx)
+		if err != nil {
+			if s.failures != nil {
+				s.failures.Add(ctx, 1, metric.WithAttributes(attribute.String("token_url", s.config.TokenURL)))
+			}
+			logger.WarnCtx(ctx, "Failed to fetch OAuth2 token",
+				zap.String("tokenURL", s.config.TokenURL),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.token = token
+		s.mu.Unlock()
+		return token, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Token), nil
+}
+
+// tokenResponse is the token endpoint's success response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// fetch requests a token from the token endpoint
+func (s *OAuth2TokenSource) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {s.config.GrantType}}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	for key, value := range s.config.Params {
+		form.Set(key, value)
+	}
+
+	if s.config.GrantType == GrantJWTBearer {
+		if s.config.Assertion == nil {
+			return nil, errors.New("jwt bearer grant requires an assertion")
+		}
+		assertion, err := s.config.Assertion.sign(time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("error signing assertion: %w", err)
+		}
+		form.Set("assertion", assertion)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.config.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error reading token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(&Response{StatusCode: resp.StatusCode, Headers: resp.Header, Body: body})
+	}
+
+	var parsed tokenResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("error decoding token response: %w", err)
+	}
+	if parsed.AccessToken == "" {
+		return nil, errors.New("token response has no access token")
+	}
+
+	token := &Token{
+		AccessToken: parsed.AccessToken,
+		TokenType:   parsed.TokenType,
+	}
+	if token.TokenType == "" || strings.EqualFold(token.TokenType, "bearer") {
+		token.TokenType = "Bearer"
+	}
+	if parsed.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(parsed.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// sign creates the RS256 JWT assertion
+func (a *JWTAssertion) sign(now time.Time) (string, error) {
+	if a.PrivateKey == nil {
+		return "", errors.New("assertion has no private key")
+	}
+
+	ttl := a.TTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if a.KeyID != "" {
+		header["kid"] = a.KeyID
+	}
+	claims := map[string]interface{}{
+		"iss": a.Issuer,
+		"sub": a.Subject,
+		"aud": a.Audience,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	for key, value := range a.Claims {
+		if _, ok := claims[key]; !ok {
+			claims[key] = value
+		}
+	}
+
+	encodedHeader, err := encodeJWTSegment(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeJWTSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// encodeJWTSegment encodes a JWT header or claims set
+func encodeJWTSegment(value interface{}) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// OAuth2 returns a middleware authenticating every request with a token from source
+// A 401 response invalidates the cached token when source supports it, so the next request fetches a new one.
+func OAuth2(source TokenSource) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := source.Token(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("error getting oauth2 token: %w", err)
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", token.TokenType+" "+token.AccessToken)
+
+			resp, err := next.RoundTrip(req)
+			if err == nil && resp.StatusCode == http.StatusUnauthorized {
+				if invalidator, ok := source.(interface{ Invalidate() }); ok {
+					invalidator.Invalidate()
+				}
+			}
+			return resp, err
+		})
+	}
+}
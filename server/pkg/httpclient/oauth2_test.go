@@ -0,0 +1,242 @@
+package httpclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2TokenSource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Fetches client credentials tokens and caches them", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies []string
+		server := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, r)
+			bodies = append(bodies, string(body))
+			respondJSON(w, http.StatusOK, map[string]interface{}{
+				"access_token": "token-1", "token_type": "bearer", "expires_in": 3600,
+			})
+		})
+		source := NewOAuth2TokenSource(OAuth2Config{
+			TokenURL:     server.URL + "/oauth/token",
+			ClientID:     "quizzes",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"read", "write"},
+			Params:       map[string]string{"audience": "https://api.example.com"},
+		}, nil)
+
+		for i := 0; i < 3; i++ {
+			token, err := source.Token(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "token-1", token.AccessToken)
+			assert.Equal(t, "Bearer", token.TokenType)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+		}
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		form, err := url.ParseQuery(bodies[0])
+		require.NoError(t, err)
+		assert.Equal(t, url.Values{
+			"grant_type": {GrantClientCredentials},
+			"scope":      {"read write"},
+			"audience":   {"https://api.example.com"},
+		}, form)
+		assert.Equal(t, "Basic cXVpenplczpzM2NyZXQ=", requests[0].Header.Get("Authorization"))
+	})
+
+	t.Run("Concurrent callers share one token request", func(t *testing.T) {
+		var requests atomic.Int32
+		unblock := make(chan struct{})
+		server := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			<-unblock
+			_, _ = w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+		})
+		source := NewOAuth2TokenSource(OAuth2Config{TokenURL: server.URL}, nil)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := source.Token(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, "token-1", token.AccessToken)
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(unblock)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Refreshes tokens close to expiry in the background", func(t *testing.T) {
+		var requests atomic.Int32
+		server := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				respondJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token-1", "expires_in": 30})
+				return
+			}
+			respondJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token-2", "expires_in": 3600})
+		})
+		source := NewOAuth2TokenSource(OAuth2Config{TokenURL: server.URL}, nil)
+
+		token, err := source.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", token.AccessToken)
+
+		// The token expires within RefreshBefore, so it is returned while a new one is fetched
+		token, err = source.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-1", token.AccessToken)
+
+		assert.Eventually(t, func() bool {
+			token, err := source.Token(ctx)
+			return err == nil && token.AccessToken == "token-2"
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("Invalidate forces a new token", func(t *testing.T) {
+		var requests atomic.Int32
+		server := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			respondJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token-1"})
+		})
+		source := NewOAuth2TokenSource(OAuth2Config{TokenURL: server.URL}, nil)
+
+		_, err := source.Token(ctx)
+		require.NoError(t, err)
+		source.Invalidate()
+		_, err = source.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("Reports token endpoint errors", func(t *testing.T) {
+		var requests atomic.Int32
+		server := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"token_type":"bearer"}`))
+		})
+		source := NewOAuth2TokenSource(OAuth2Config{TokenURL: server.URL}, nil)
+
+		_, err := source.Token(ctx)
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+
+		_, err = source.Token(ctx)
+		assert.EqualError(t, err, "token response has no access token")
+	})
+
+	t.Run("Exchanges a signed JWT with the JWT bearer grant", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		var form url.Values
+		server := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			form, _ = url.ParseQuery(string(body))
+			respondJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token-1"})
+		})
+		tokenURL := server.URL + "/oauth/token"
+		source := NewOAuth2TokenSource(OAuth2Config{
+			TokenURL:  tokenURL,
+			GrantType: GrantJWTBearer,
+			Assertion: &JWTAssertion{
+				Issuer:     "quizzes@example.com",
+				Audience:   tokenURL,
+				KeyID:      "key-1",
+				PrivateKey: key,
+				Claims:     map[string]interface{}{"scope": "read", "iss": "ignored"},
+			},
+		}, nil)
+
+		_, err = source.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, GrantJWTBearer, form.Get("grant_type"))
+
+		parts := strings.Split(form.Get("assertion"), ".")
+		require.Len(t, parts, 3)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+		var header, claims map[string]interface{}
+		decodeJWTSegment(t, parts[0], &header)
+		decodeJWTSegment(t, parts[1], &claims)
+		assert.Equal(t, map[string]interface{}{"alg": "RS256", "typ": "JWT", "kid": "key-1"}, header)
+		assert.Equal(t, "quizzes@example.com", claims["iss"])
+		assert.Equal(t, tokenURL, claims["aud"])
+		assert.Equal(t, "read", claims["scope"])
+		assert.Equal(t, float64(5*60), claims["exp"].(float64)-claims["iat"].(float64))
+	})
+
+	t.Run("JWT bearer grants need an assertion", func(t *testing.T) {
+		server := startServer(t, http.NotFound)
+		source := NewOAuth2TokenSource(OAuth2Config{TokenURL: server.URL, GrantType: GrantJWTBearer}, nil)
+		_, err := source.Token(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestOAuth2Middleware(t *testing.T) {
+	ctx := context.Background()
+
+	var tokens atomic.Int32
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/oauth/token" && tokens.Add(1) == 1:
+			respondJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token-1"})
+		case r.URL.Path == "/oauth/token":
+			respondJSON(w, http.StatusOK, map[string]interface{}{"access_token": "token-2"})
+		case r.Header.Get("Authorization") != "Bearer token-2":
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}, func(cfg *Config) {
+		cfg.OAuth2 = &OAuth2Config{TokenURL: cfg.BaseURL + "/oauth/token"}
+	})
+
+	// A rejected token is dropped, so the next request is sent with a new one
+	resp, err := client.Get(ctx, "/quizzes", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = client.Get(ctx, "/quizzes", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), tokens.Load())
+}
+
+// decodeJWTSegment decodes a JWT header or claims set into v
+func decodeJWTSegment(t *testing.T, segment string, v interface{}) {
+	t.Helper()
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, v))
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestOption customizes a single request
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the per-request overrides of the client configuration
+type requestOptions struct {
+	headers    map[string]string
+	query      url.Values
+	timeout    time.Duration
+	retry      *RetryConfig
+	idempotent bool
+	breakerKey string
+	username   string
+	password   string
+	basicAuth  bool
+}
+
+// newRequestOptions applies opts over the defaults
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{
+		headers: make(map[string]string),
+		query:   make(url.Values),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHeader sets a request header, overriding the client's default headers
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers[key] = value
+	}
+}
+
+// WithHeaders sets several request headers
+func WithHeaders(headers map[string]string) RequestOption {
+	return func(o *requestOptions) {
+		for key, value := range headers {
+			o.headers[key] = value
+		}
+	}
+}
+
+// WithQuery adds values for a query parameter
+func WithQuery(key string, values ...string) RequestOption {
+	return func(o *requestOptions) {
+		for _, value := range values {
+			o.query.Add(key, value)
+		}
+	}
+}
+
+// WithQueryParams adds all the given query parameters
+func WithQueryParams(params url.Values) RequestOption {
+	return func(o *requestOptions) {
+		for key, values := range params {
+			for _, value := range values {
+				o.query.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithTimeout bounds the whole call, retries included
+// Each attempt is still bounded by the client's RequestTimeout.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry replaces the client's retry policy for the call
+func WithRetry(retry RetryConfig) RequestOption {
+	return func(o *requestOptions) {
+		o.retry = &retry
+	}
+}
+
+// WithoutRetry disables retries for the call
+func WithoutRetry() RequestOption {
+	return func(o *requestOptions) {
+		o.retry = &RetryConfig{Enabled: false}
+	}
+}
+
+// Idempotent marks a call as safe to retry even though its method is not idempotent (POST, PATCH)
+func Idempotent() RequestOption {
+	return func(o *requestOptions) {
+		o.idempotent = true
+	}
+}
+
+// WithIdempotencyKey sends an Idempotency-Key header and marks the call as safe to retry
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers["Idempotency-Key"] = key
+		o.idempotent = true
+	}
+}
+
+// WithBasicAuth authenticates the call with HTTP basic authentication
+func WithBasicAuth(username, password string) RequestOption {
+	return func(o *requestOptions) {
+		o.username = username
+		o.password = password
+		o.basicAuth = true
+	}
+}
+
+// WithBreaker makes the call use the circuit breaker named key (see WithBreakerKey)
+func WithBreaker(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.breakerKey = key
+	}
+}
+
+// retryPolicy returns the retry policy of a call to method
+// Methods that are not idempotent are only retried when the call is marked idempotent.
+func (o *requestOptions) retryPolicy(method string, defaults RetryConfig) RetryConfig {
+	retry := defaults
+	if o.retry != nil {
+		retry = *o.retry
+	}
+
+	if !o.idempotent && !isIdempotent(method) {
+		retry.Enabled = false
+	}
+	return retry
+}
+
+// context applies the call's timeout and breaker key to ctx
+func (o *requestOptions) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.breakerKey != "" {
+		ctx = WithBreakerKey(ctx, o.breakerKey)
+	}
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, func() {}
+}
+
+// apply sets the call's query parameters and credentials on req
+func (o *requestOptions) apply(req *http.Request) {
+	if len(o.query) > 0 {
+		query := req.URL.Query()
+		for key, values := range o.query {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	if o.basicAuth {
+		req.SetBasicAuth(o.username, o.password)
+	}
+}
+
+// isIdempotent reports whether requests with method may safely be repeated
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
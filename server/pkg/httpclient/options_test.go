@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Sets headers, query parameters and credentials", func(t *testing.T) {
+		var received *http.Request
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			_, _ = w.Write([]byte("{}"))
+		})
+
+		_, err := client.Request(ctx, http.MethodGet, "/quizzes?page=2", nil,
+			WithHeader("Accept", "text/csv"),
+			WithHeaders(map[string]string{"X-Tenant": "t1", "X-Trace": "on"}),
+			WithQuery("tag", "math", "science"),
+			WithQueryParams(url.Values{"sort": {"title"}}),
+			WithBasicAuth("quizzes", "s3cret"),
+		)
+		require.NoError(t, err)
+
+		require.NotNil(t, received)
+		assert.Equal(t, "text/csv", received.Header.Get("Accept"), "options override default headers")
+		assert.Equal(t, "t1", received.Header.Get("X-Tenant"))
+		assert.Equal(t, "on", received.Header.Get("X-Trace"))
+		assert.Equal(t, "page=2&sort=title&tag=math&tag=science", received.URL.RawQuery)
+		assert.Equal(t, "Basic cXVpenplczpzM2NyZXQ=", received.Header.Get("Authorization"))
+	})
+
+	t.Run("WithTimeout bounds the call", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		})
+
+		start := time.Now()
+		_, err := client.Request(ctx, http.MethodGet, "/slow", nil, WithTimeout(20*time.Millisecond))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("WithoutRetry and WithRetry override the client policy", func(t *testing.T) {
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, fastRetries)
+
+		_, err := client.Request(ctx, http.MethodGet, "/quizzes", nil, WithoutRetry())
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+
+		retry := client.config.Retry
+		retry.MaxRetries = 1
+		_, err = client.Request(ctx, http.MethodGet, "/quizzes", nil, WithRetry(retry))
+		assert.Error(t, err)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+	defaults := RetryConfig{Enabled: true, MaxRetries: 3}
+
+	tests := []struct {
+		name   string
+		method string
+		opts   []RequestOption
+		want   bool
+	}{
+		{name: "GET", method: http.MethodGet, want: true},
+		{name: "PUT", method: http.MethodPut, want: true},
+		{name: "DELETE", method: http.MethodDelete, want: true},
+		{name: "POST", method: http.MethodPost, want: false},
+		{name: "PATCH", method: http.MethodPatch, want: false},
+		{name: "Idempotent POST", method: http.MethodPost, opts: []RequestOption{Idempotent()}, want: true},
+		{name: "POST with an idempotency key", method: http.MethodPost, opts: []RequestOption{WithIdempotencyKey("k")}, want: true},
+		{name: "GET without retry", method: http.MethodGet, opts: []RequestOption{WithoutRetry()}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newRequestOptions(tt.opts).retryPolicy(tt.method, defaults).Enabled)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrCrossHostRedirect is returned when a redirect to another host is forbidden by the redirect policy
+var ErrCrossHostRedirect = errors.New("httpclient: cross-host redirect forbidden")
+
+// AuthForwarding decides whether credential headers follow redirects
+type AuthForwarding string
+
+// Supported auth forwarding policies
+const (
+	// ForwardAuthSameHost keeps Authorization and Cookie headers for redirects within the original
+	// host (and its subdomains), Go's default
+	ForwardAuthSameHost AuthForwarding = "same_host"
+
+	// ForwardAuthAlways copies Authorization to every redirect target, for APIs redirecting to trusted hosts
+	ForwardAuthAlways AuthForwarding = "always"
+
+	// ForwardAuthNever drops Authorization on every redirect
+	ForwardAuthNever AuthForwarding = "never"
+)
+
+// RedirectConfig controls how redirects are followed
+type RedirectConfig struct {
+	// Disabled returns redirect responses to the caller instead of following them
+	Disabled bool
+
+	// MaxRedirects is the maximum number of redirects followed; defaults to 10
+	MaxRedirects int
+
+	// ForbidCrossHost fails requests redirected to a host other than the original one
+	ForbidCrossHost bool
+
+	// AuthForwarding decides whether credentials follow redirects; defaults to ForwardAuthSameHost
+	AuthForwarding AuthForwarding
+}
+
+// checkRedirect builds the http.Client CheckRedirect function of a redirect policy
+func checkRedirect(cfg RedirectConfig) func(req *http.Request, via []*http.Request) error {
+	maxRedirects := cfg.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = 10
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		if cfg.Disabled {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("httpclient: stopped after %d redirects", maxRedirects)
+		}
+
+		original := via[0]
+		if cfg.ForbidCrossHost && req.URL.Host != original.URL.Host {
+			return fmt.Errorf("%w: %s to %s", ErrCrossHostRedirect, original.URL.Host, req.URL.Host)
+		}
+
+		switch cfg.AuthForwarding {
+		case ForwardAuthAlways:
+			if auth := original.Header.Get("Authorization"); auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+		case ForwardAuthNever:
+			req.Header.Del("Authorization")
+		}
+		return nil
+	}
+}
+
+// newCookieJar creates an in-memory cookie jar scoped by public suffix
+func newCookieJar() (http.CookieJar, error) {
+	return cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+}
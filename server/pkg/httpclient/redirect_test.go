@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/pkg/httpclient/httpclienttest"
+)
+
+// redirectTo scripts a redirect response to location
+func redirectTo(location string) httpclienttest.MockResponse {
+	return httpclienttest.MockResponse{Status: http.StatusFound, Header: http.Header{"Location": {location}}}
+}
+
+func TestRedirects(t *testing.T) {
+	ctx := context.Background()
+	auth := map[string]string{"Authorization": "Bearer token"}
+
+	t.Run("Follows redirects", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes/q1").RespondWith(redirectTo("/v2/quizzes/q1"))
+		transport.On(http.MethodGet, "/v2/quizzes/q1").Respond(http.StatusOK, `{"id":"q1"}`)
+		client := newTestClient(t, "https://api.example.com", transport)
+
+		resp, err := client.Get(ctx, "/quizzes/q1", auth)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"id":"q1"}`, string(resp.Body))
+		assert.Equal(t, "Bearer token", transport.Calls()[1].Header.Get("Authorization"), "same host keeps credentials")
+	})
+
+	t.Run("Returns redirects when disabled", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes/q1").RespondWith(redirectTo("/v2/quizzes/q1"))
+		client := newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+			cfg.Redirect.Disabled = true
+		})
+
+		resp, err := client.Get(ctx, "/quizzes/q1", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.Equal(t, "/v2/quizzes/q1", resp.Headers.Get("Location"))
+		assert.Len(t, transport.Calls(), 1)
+	})
+
+	t.Run("Stops after MaxRedirects", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/loop").RespondWith(redirectTo("/loop"))
+		client := newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+			cfg.Redirect.MaxRedirects = 3
+		})
+
+		_, err := client.Get(ctx, "/loop", nil)
+		assert.ErrorContains(t, err, "stopped after 3 redirects")
+		assert.Len(t, transport.Calls(), 4)
+	})
+
+	t.Run("Forbids cross-host redirects", func(t *testing.T) {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes/q1").RespondWith(redirectTo("https://evil.example.org/quizzes/q1"))
+		client := newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+			cfg.Redirect.ForbidCrossHost = true
+		})
+
+		_, err := client.Get(ctx, "/quizzes/q1", auth)
+		assert.ErrorIs(t, err, ErrCrossHostRedirect)
+		assert.Len(t, transport.Calls(), 1)
+	})
+
+	tests := []struct {
+		name       string
+		forwarding AuthForwarding
+		location   string
+		want       string
+	}{
+		{name: "Drops credentials on cross-host redirects by default", location: "https://cdn.example.org/files/1", want: ""},
+		{name: "Keeps credentials on same-host redirects by default", location: "/files/1", want: "Bearer token"},
+		{name: "Always forwards credentials when configured", forwarding: ForwardAuthAlways, location: "https://cdn.example.org/files/1", want: "Bearer token"},
+		{name: "Never forwards credentials when configured", forwarding: ForwardAuthNever, location: "/files/1", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := httpclienttest.NewMockTransport()
+			transport.On(http.MethodGet, "/quizzes/q1/export").RespondWith(redirectTo(tt.location))
+			transport.On(http.MethodGet, "/files/1").Respond(http.StatusOK, "id\n")
+			client := newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+				cfg.Redirect.AuthForwarding = tt.forwarding
+			})
+
+			_, err := client.Get(ctx, "/quizzes/q1/export", auth)
+			require.NoError(t, err)
+
+			calls := transport.Calls()
+			require.Len(t, calls, 2)
+			assert.Equal(t, tt.want, calls[1].Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestCookies(t *testing.T) {
+	ctx := context.Background()
+
+	newCookieTransport := func() *httpclienttest.MockTransport {
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodPost, "/login").RespondWith(httpclienttest.MockResponse{
+			Status: http.StatusOK,
+			Header: http.Header{"Set-Cookie": {"session=s1; Path=/"}},
+		})
+		transport.On(http.MethodGet, "/me").Respond(http.StatusOK, "{}")
+		return transport
+	}
+
+	t.Run("Ignores cookies by default", func(t *testing.T) {
+		transport := newCookieTransport()
+		client := newTestClient(t, "https://api.example.com", transport)
+
+		_, err := client.Post(ctx, "/login", nil, nil)
+		require.NoError(t, err)
+		_, err = client.Get(ctx, "/me", nil)
+		require.NoError(t, err)
+
+		assert.Empty(t, transport.Calls()[1].Header.Get("Cookie"))
+	})
+
+	t.Run("Sends cookies set by the server when enabled", func(t *testing.T) {
+		transport := newCookieTransport()
+		client := newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+			cfg.Cookies = true
+		})
+
+		_, err := client.Post(ctx, "/login", nil, nil)
+		require.NoError(t, err)
+		_, err = client.Get(ctx, "/me", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "session=s1", transport.Calls()[1].Header.Get("Cookie"))
+	})
+
+	t.Run("Uses the configured jar", func(t *testing.T) {
+		jar, err := newCookieJar()
+		require.NoError(t, err)
+		base, err := url.Parse("https://api.example.com")
+		require.NoError(t, err)
+		jar.SetCookies(base, []*http.Cookie{{Name: "tenant", Value: "acme"}})
+
+		transport := newCookieTransport()
+		client := newTestClient(t, "https://api.example.com", transport, func(cfg *Config) {
+			cfg.CookieJar = jar
+		})
+
+		_, err = client.Get(ctx, "/me", nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, "tenant=acme", transport.Calls()[0].Header.Get("Cookie"))
+	})
+}
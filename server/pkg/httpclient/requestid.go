@@ -0,0 +1,25 @@
+package httpclient
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"quizizz.com/pkg/requestid"
+)
+
+// IDGenerator creates the request ID of an outgoing request that carries none from its context
+type IDGenerator func(ctx context.Context) string
+
+// RandomIDs generates random UUIDv7 request IDs, the default
+func RandomIDs(context.Context) string {
+	return requestid.New()
+}
+
+// TraceIDs uses the trace ID of the active span as request ID, so logs and traces share one
+// identifier, and falls back to RandomIDs outside a trace
+func TraceIDs(ctx context.Context) string {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	return RandomIDs(ctx)
+}
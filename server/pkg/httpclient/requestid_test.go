@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"quizizz.com/pkg/correlation"
+	"quizizz.com/pkg/httpclient/httpclienttest"
+	"quizizz.com/pkg/requestid"
+)
+
+func TestRequestIDs(t *testing.T) {
+	ctx := context.Background()
+
+	// sentRequestID sends a request and returns the request ID it carried
+	sentRequestID := func(t *testing.T, ctx context.Context, headers map[string]string, configure ...func(*Config)) string {
+		t.Helper()
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").Respond(http.StatusOK, "[]")
+		client := newTestClient(t, "https://api.example.com", transport, configure...)
+
+		resp, err := client.Get(ctx, "/quizzes", headers)
+		require.NoError(t, err)
+		id := transport.Calls()[0].Header.Get(HeaderRequestID)
+		assert.Equal(t, id, resp.RequestID)
+		return id
+	}
+
+	t.Run("Generates UUIDv7 request IDs by default", func(t *testing.T) {
+		id := sentRequestID(t, ctx, nil)
+
+		parsed, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+		assert.NotEqual(t, id, sentRequestID(t, ctx, nil))
+	})
+
+	t.Run("Uses the configured generator", func(t *testing.T) {
+		type tenantKey struct{}
+		generator := func(ctx context.Context) string {
+			return "req-" + ctx.Value(tenantKey{}).(string)
+		}
+
+		id := sentRequestID(t, context.WithValue(ctx, tenantKey{}, "acme"), nil, func(cfg *Config) {
+			cfg.IDGenerator = generator
+		})
+		assert.Equal(t, "req-acme", id)
+	})
+
+	t.Run("Propagates the request ID of the context", func(t *testing.T) {
+		id := sentRequestID(t, requestid.WithContext(ctx, "incoming-1"), nil, func(cfg *Config) {
+			cfg.IDGenerator = func(context.Context) string { return "generated" }
+		})
+		assert.Equal(t, "incoming-1", id)
+	})
+
+	t.Run("Keeps an explicit request ID", func(t *testing.T) {
+		id := sentRequestID(t, requestid.WithContext(ctx, "incoming-1"), map[string]string{HeaderRequestID: "explicit-1"})
+		assert.Equal(t, "explicit-1", id)
+	})
+}
+
+func TestCorrelationIDs(t *testing.T) {
+	ctx := correlation.WithIDs(context.Background(), correlation.IDs{
+		RequestID:     "req-1",
+		CorrelationID: "corr-1",
+		CausationID:   "cause-1",
+		UserID:        "user-1",
+		TenantID:      "tenant-1",
+		ActorID:       "admin-1",
+	})
+
+	// sentHeaders sends a request and returns the headers it carried
+	sentHeaders := func(t *testing.T, configure ...func(*Config)) http.Header {
+		t.Helper()
+		transport := httpclienttest.NewMockTransport()
+		transport.On(http.MethodGet, "/quizzes").Respond(http.StatusOK, "[]")
+		client := newTestClient(t, "https://api.example.com", transport, configure...)
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		return transport.Calls()[0].Header
+	}
+
+	t.Run("Propagates only the flow IDs by default", func(t *testing.T) {
+		header := sentHeaders(t)
+		assert.Equal(t, "req-1", header.Get(correlation.HeaderRequestID))
+		assert.Equal(t, "corr-1", header.Get(correlation.HeaderCorrelationID))
+		assert.Equal(t, "cause-1", header.Get(correlation.HeaderCausationID))
+		for _, name := range []string{correlation.HeaderUserID, correlation.HeaderTenantID, correlation.HeaderActorID} {
+			assert.Empty(t, header.Get(name), name)
+		}
+	})
+
+	t.Run("Propagates the identity when enabled", func(t *testing.T) {
+		header := sentHeaders(t, func(cfg *Config) { cfg.WithIdentityPropagation(true) })
+		assert.Equal(t, "user-1", header.Get(correlation.HeaderUserID))
+		assert.Equal(t, "tenant-1", header.Get(correlation.HeaderTenantID))
+		assert.Equal(t, "admin-1", header.Get(correlation.HeaderActorID))
+	})
+}
+
+func TestTraceIDs(t *testing.T) {
+	t.Run("Uses the trace ID of the active span", func(t *testing.T) {
+		traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		}))
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceIDs(ctx))
+	})
+
+	t.Run("Falls back to random IDs outside a trace", func(t *testing.T) {
+		id := TraceIDs(context.Background())
+
+		_, err := uuid.Parse(id)
+		assert.NoError(t, err)
+		assert.NotEqual(t, id, TraceIDs(context.Background()))
+	})
+}
@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// RetryBudgetConfig bounds retries to a share of the client's traffic, so a struggling
+// dependency is not hit by a retry storm
+type RetryBudgetConfig struct {
+	// Ratio is the number of retries each request earns (0.2 allows 20% extra traffic)
+	Ratio float64
+
+	// MinRetriesPerSecond keeps retries possible at low traffic
+	MinRetriesPerSecond int
+}
+
+// retryBudget is a token bucket filled by requests and the passage of time, and drained by retries
+type retryBudget struct {
+	ratio        float64
+	minPerSecond float64
+	capacity     float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRetryBudget creates a budget, or returns nil when cfg is nil
+func newRetryBudget(cfg *RetryBudgetConfig) *retryBudget {
+	if cfg == nil {
+		return nil
+	}
+
+	minPerSecond := float64(cfg.MinRetriesPerSecond)
+	capacity := math.Max(1, minPerSecond+100*cfg.Ratio)
+	return &retryBudget{
+		ratio:        cfg.Ratio,
+		minPerSecond: minPerSecond,
+		capacity:     capacity,
+		tokens:       capacity,
+		last:         time.Now(),
+	}
+}
+
+// deposit credits the budget for a new request
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens = math.Min(b.capacity, b.tokens+b.ratio)
+}
+
+// withdraw reports whether a retry may be made, taking its cost from the budget
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill adds the tokens earned by time since the last update; callers hold mu
+func (b *retryBudget) refill() {
+	now := time.Now()
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.minPerSecond)
+	b.last = now
+}
+
+// hintedBackOff waits at least as long as the server asked before the next attempt
+type hintedBackOff struct {
+	backoff.BackOff
+
+	// hint is the delay requested by the last response
+	hint time.Duration
+
+	// max is the longest requested delay honored; longer ones stop retrying
+	max time.Duration
+}
+
+// NextBackOff implements backoff.BackOff
+func (b *hintedBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	hint := b.hint
+	b.hint = 0
+
+	if next == backoff.Stop || hint <= next {
+		return next
+	}
+	if b.max > 0 && hint > b.max {
+		return backoff.Stop
+	}
+	return hint
+}
+
+// retryAfter returns the delay a response asks clients to wait before retrying
+// Retry-After (seconds or an HTTP date) takes precedence over RateLimit-Reset and
+// X-RateLimit-Reset (seconds, or a Unix timestamp for the latter).
+func retryAfter(headers http.Header, now time.Time) time.Duration {
+	if value := headers.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil && at.After(now) {
+			return at.Sub(now)
+		}
+	}
+
+	for _, header := range []string{"RateLimit-Reset", "X-RateLimit-Reset"} {
+		seconds, err := strconv.ParseInt(headers.Get(header), 10, 64)
+		if err != nil || seconds <= 0 {
+			continue
+		}
+		// Values this large are timestamps rather than durations
+		if seconds > 1_000_000_000 {
+			if at := time.Unix(seconds, 0); at.After(now) {
+				return at.Sub(now)
+			}
+			continue
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	return 0
+}
+
+// bodyRewinder returns a function preparing body to be sent again, and whether that is possible
+// Values encoded per attempt (structs, byte slices, strings) always are; readers only when they can seek.
+func bodyRewinder(body interface{}) (func() error, bool) {
+	reader, ok := body.(io.Reader)
+	if !ok {
+		return func() error { return nil }, true
+	}
+
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return nil, false
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, false
+	}
+	return func() error {
+		_, err := seeker.Seek(start, io.SeekStart)
+		return err
+	}, true
+}
+
+// uncloseableReader hides the Close method of a replayable body, which the transport
+// would otherwise call after the first attempt
+// Wrapping also hides the reader's type, from which the request learns the body length, so size
+// holds the number of bytes left to send, or -1 when unknown.
+type uncloseableReader struct {
+	io.Reader
+	size int64
+}
+
+// newUncloseableReader wraps a seekable reader, measuring the bytes left from its position
+func newUncloseableReader(reader io.Reader) uncloseableReader {
+	wrapped := uncloseableReader{Reader: reader, size: -1}
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return wrapped
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return wrapped
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if _, seekErr := seeker.Seek(start, io.SeekStart); err == nil && seekErr == nil && end >= start {
+		wrapped.size = end - start
+	}
+	return wrapped
+}
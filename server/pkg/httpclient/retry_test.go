@@ -0,0 +1,213 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Retries failed idempotent requests", func(t *testing.T) {
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}, fastRetries)
+
+		resp, err := client.Get(ctx, "/quizzes", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("Stops after MaxRetries", func(t *testing.T) {
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, fastRetries)
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		assert.Error(t, err)
+		assert.Equal(t, int32(4), attempts.Load())
+	})
+
+	t.Run("Does not retry POST unless marked idempotent", func(t *testing.T) {
+		var keys []string
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, fastRetries)
+
+		resp, err := client.Post(ctx, "/quizzes", map[string]string{}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, keys, 1)
+
+		_, err = client.Request(ctx, http.MethodPost, "/quizzes", map[string]string{}, WithIdempotencyKey("key-1"))
+		assert.Error(t, err)
+		require.Len(t, keys, 5)
+		assert.Equal(t, "key-1", keys[4])
+	})
+
+	t.Run("Stops when the server asks to wait longer than MaxRetryAfter", func(t *testing.T) {
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, fastRetries)
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("Stops when the budget is exhausted", func(t *testing.T) {
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, fastRetries, func(cfg *Config) {
+			// The budget starts with a single retry and earns no more
+			cfg.Retry.Budget = &RetryBudgetConfig{}
+		})
+
+		_, err := client.Get(ctx, "/quizzes", nil)
+		assert.Error(t, err)
+		assert.Equal(t, int32(2), attempts.Load())
+
+		_, err = client.Get(ctx, "/quizzes", nil)
+		assert.Error(t, err)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("Resends seekable bodies from their start", func(t *testing.T) {
+		var bodies []string
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}, fastRetries)
+
+		body := bytes.NewReader([]byte("content"))
+		resp, err := client.Request(ctx, http.MethodPut, "/files/a", body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"content", "content"}, bodies)
+	})
+
+	t.Run("Does not retry bodies that cannot be rewound", func(t *testing.T) {
+		var attempts atomic.Int32
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, fastRetries)
+
+		body := io.MultiReader(bytes.NewReader([]byte("content")))
+		resp, err := client.Request(ctx, http.MethodPut, "/files/a", body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
+
+func TestRetryBudget(t *testing.T) {
+	t.Run("Nil budgets allow every retry", func(t *testing.T) {
+		var budget *retryBudget
+		budget.deposit()
+		assert.True(t, budget.withdraw())
+		assert.Nil(t, newRetryBudget(nil))
+	})
+
+	t.Run("Requests earn retries at the ratio", func(t *testing.T) {
+		budget := newRetryBudget(&RetryBudgetConfig{Ratio: 0.5})
+		budget.tokens = 0
+
+		budget.deposit()
+		assert.False(t, budget.withdraw())
+		budget.deposit()
+		assert.True(t, budget.withdraw())
+		assert.False(t, budget.withdraw())
+	})
+
+	t.Run("Tokens are capped", func(t *testing.T) {
+		budget := newRetryBudget(&RetryBudgetConfig{Ratio: 0.2, MinRetriesPerSecond: 10})
+		assert.Equal(t, 30.0, budget.capacity)
+
+		for i := 0; i < 1000; i++ {
+			budget.deposit()
+		}
+		assert.LessOrEqual(t, budget.tokens, budget.capacity)
+	})
+
+	t.Run("Time earns MinRetriesPerSecond", func(t *testing.T) {
+		budget := newRetryBudget(&RetryBudgetConfig{MinRetriesPerSecond: 10})
+		budget.tokens = 0
+		budget.last = time.Now().Add(-time.Second)
+
+		assert.True(t, budget.withdraw())
+		assert.InDelta(t, 9, budget.tokens, 0.1)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers http.Header
+		want    time.Duration
+	}{
+		{name: "Seconds", headers: http.Header{"Retry-After": {"30"}}, want: 30 * time.Second},
+		{name: "HTTP date", headers: http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, want: time.Minute},
+		{name: "Past HTTP date", headers: http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, want: 0},
+		{name: "RateLimit-Reset", headers: http.Header{"Ratelimit-Reset": {"10"}}, want: 10 * time.Second},
+		{name: "X-RateLimit-Reset timestamp", headers: http.Header{"X-Ratelimit-Reset": {strconv.FormatInt(now.Add(time.Hour).Unix(), 10)}}, want: time.Hour},
+		{name: "Retry-After first", headers: http.Header{"Retry-After": {"5"}, "Ratelimit-Reset": {"10"}}, want: 5 * time.Second},
+		{name: "Invalid", headers: http.Header{"Retry-After": {"soon"}}, want: 0},
+		{name: "None", headers: http.Header{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryAfter(tt.headers, now))
+		})
+	}
+}
+
+func TestHintedBackOff(t *testing.T) {
+	t.Run("Waits at least as long as asked", func(t *testing.T) {
+		b := &hintedBackOff{BackOff: backoff.NewConstantBackOff(time.Millisecond), max: time.Minute}
+		b.hint = 5 * time.Second
+		assert.Equal(t, 5*time.Second, b.NextBackOff())
+
+		// Hints apply to a single attempt
+		assert.Equal(t, time.Millisecond, b.NextBackOff())
+	})
+
+	t.Run("Keeps longer backoffs", func(t *testing.T) {
+		b := &hintedBackOff{BackOff: backoff.NewConstantBackOff(time.Second), hint: time.Millisecond}
+		assert.Equal(t, time.Second, b.NextBackOff())
+	})
+
+	t.Run("Stops for hints over max", func(t *testing.T) {
+		b := &hintedBackOff{BackOff: backoff.NewConstantBackOff(time.Millisecond), hint: time.Hour, max: time.Minute}
+		assert.Equal(t, backoff.Stop, b.NextBackOff())
+	})
+}
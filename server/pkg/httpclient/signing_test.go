@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/pkg/httpclient/httpclienttest"
+)
+
+// Credentials and time of the AWS Signature Version 4 test suite
+var (
+	suiteSigner = AWSSigV4Signer{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+	}
+	suiteTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+)
+
+func TestAWSSigV4Signer_TestSuite(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		contentType   string
+		body          string
+		signedHeaders string
+		signature     string
+	}{
+		{
+			name: "get-vanilla", method: http.MethodGet, url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "get-vanilla-query-order-key-case", method: http.MethodGet, url: "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name: "get-vanilla-empty-query-key", method: http.MethodGet, url: "https://example.amazonaws.com/?Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb",
+		},
+		{
+			name: "get-slash-dot-slash", method: http.MethodGet, url: "https://example.amazonaws.com/./",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "get-relative-relative", method: http.MethodGet, url: "https://example.amazonaws.com/example1/example2/../..",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "get-slashes", method: http.MethodGet, url: "https://example.amazonaws.com//example//",
+			signedHeaders: "host;x-amz-date",
+			signature:     "9a624bd73a37c9a373b5312afbebe7a714a789de108f0bdfe846570885f57e84",
+		},
+		{
+			name: "post-vanilla", method: http.MethodPost, url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name: "post-x-www-form-urlencoded", method: http.MethodPost, url: "https://example.amazonaws.com/",
+			contentType: "application/x-www-form-urlencoded", body: "Param1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			signer := suiteSigner
+			require.NoError(t, signer.Sign(req, []byte(tt.body), suiteTime))
+
+			assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+			assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+				"SignedHeaders="+tt.signedHeaders+", Signature="+tt.signature, req.Header.Get("Authorization"))
+		})
+	}
+}
+
+func TestAWSSigV4Signer_S3(t *testing.T) {
+	signer := AWSSigV4Signer{AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "us-east-1", Service: "s3", SessionToken: "token"}
+	req, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/a%20b/c.txt", strings.NewReader("data"))
+	require.NoError(t, err)
+
+	require.NoError(t, signer.Sign(req, []byte("data"), suiteTime))
+	sum := sha256.Sum256([]byte("data"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), req.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+	assert.Contains(t, req.Header.Get("Authorization"),
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+}
+
+func TestCanonicalURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		service string
+		want    string
+	}{
+		{name: "Root", path: "/", service: "execute-api", want: "/"},
+		{name: "Encodes segments twice", path: "/documents%20and%20settings/", service: "execute-api", want: "/documents%2520and%2520settings/"},
+		{name: "Encodes reserved characters twice", path: "/users/a%2Fb@c", service: "execute-api", want: "/users/a%252Fb%2540c"},
+		{name: "Removes dot and empty segments", path: "//a/./b/../c", service: "execute-api", want: "/a/c"},
+		{name: "Encodes S3 segments once", path: "/documents%20and%20settings/", service: "s3", want: "/documents%20and%20settings/"},
+		{name: "Keeps S3 paths as sent", path: "/a//./b", service: "s3", want: "/a//./b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse("https://example.amazonaws.com" + tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, canonicalURI(u, tt.service))
+		})
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	signer := &HMACSigner{KeyID: "key-1", Secret: []byte("secret")}
+	now := time.Unix(1700000000, 0)
+
+	t.Run("Signs method, URI, timestamp and body hash", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "https://hooks.example.com/events?source=app", nil)
+		require.NoError(t, err)
+		require.NoError(t, signer.Sign(req, []byte(`{"a":1}`), now))
+
+		sum := sha256.Sum256([]byte(`{"a":1}`))
+		bodyHash := hex.EncodeToString(sum[:])
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("POST\n/events?source=app\n1700000000\n" + bodyHash))
+
+		assert.Equal(t, "1700000000", req.Header.Get("X-Timestamp"))
+		assert.Equal(t, bodyHash, req.Header.Get("X-Content-SHA256"))
+		assert.Equal(t, "keyId=key-1,signature="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Signature"))
+	})
+
+	t.Run("Uses the configured headers", func(t *testing.T) {
+		custom := &HMACSigner{KeyID: "key-1", Secret: []byte("secret"), SignatureHeader: "Sig", TimestampHeader: "Ts", BodyHashHeader: "Digest"}
+		req, err := http.NewRequest(http.MethodGet, "https://hooks.example.com/", nil)
+		require.NoError(t, err)
+		require.NoError(t, custom.Sign(req, nil, now))
+
+		assert.NotEmpty(t, req.Header.Get("Sig"))
+		assert.Equal(t, "1700000000", req.Header.Get("Ts"))
+		assert.NotEmpty(t, req.Header.Get("Digest"))
+		assert.Empty(t, req.Header.Get("X-Signature"))
+	})
+
+	t.Run("Signatures differ by body", func(t *testing.T) {
+		first, _ := http.NewRequest(http.MethodPost, "https://hooks.example.com/", nil)
+		second, _ := http.NewRequest(http.MethodPost, "https://hooks.example.com/", nil)
+		require.NoError(t, signer.Sign(first, []byte("a"), now))
+		require.NoError(t, signer.Sign(second, []byte("b"), now))
+		assert.NotEqual(t, first.Header.Get("X-Signature"), second.Header.Get("X-Signature"))
+	})
+}
+
+func TestSign_KeepsBodyReadable(t *testing.T) {
+	transport := httpclienttest.NewMockTransport()
+	transport.On(http.MethodPost, "/events").Respond(http.StatusOK, `{}`)
+	client := newTestClient(t, "https://hooks.example.com", transport, func(cfg *Config) {
+		cfg.Signer = &HMACSigner{KeyID: "key-1", Secret: []byte("secret")}
+	})
+
+	resp, err := client.Post(context.Background(), "/events", map[string]string{"message": "hello"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	calls := transport.Calls()
+	require.Len(t, calls, 1)
+	assert.NotEmpty(t, calls[0].Header.Get("X-Signature"))
+	assert.JSONEq(t, `{"message":"hello"}`, string(calls[0].Body), "the body is sent after being read for signing")
+}
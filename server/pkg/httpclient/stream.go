@@ -0,0 +1,226 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// ErrChecksumMismatch is returned when a downloaded file does not match the expected checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// StreamResponse is a response whose body is read by the caller instead of being buffered
+// The caller must close Body.
+type StreamResponse struct {
+	StatusCode    int
+	Headers       http.Header
+	Body          io.ReadCloser
+	ContentLength int64
+	RequestID     string
+}
+
+// DoStream performs a request and returns the response without reading its body
+// Streamed requests pass through the circuit breaker but are not retried, and the body
+// of a failed response is left for the caller to inspect. A body given as io.Reader is
+// streamed to the server as is. RequestTimeout does not apply, since reading the body can
+// take arbitrarily long: bound the call with the context or WithTimeout instead.
+func (c *Client) DoStream(ctx context.Context, method, urlPath string, body interface{}, opts ...RequestOption) (*StreamResponse, error) {
+	options := newRequestOptions(opts)
+	ctx, cancel := options.context(ctx)
+
+	perform := func() (interface{}, error) {
+		req, err := c.buildRequest(ctx, method, urlPath, body, options)
+		if err != nil {
+			return nil, err
+		}
+
+		startTime := time.Now()
+		resp, err := c.streamClient.Do(req)
+		if err != nil {
+			c.metrics.recordRequest(ctx, c.serviceName, req.Method, req.URL.Host, 0, time.Since(startTime))
+			logger.ErrorCtx(ctx, "Error performing request",
+				zap.Error(err),
+				zap.String("method", req.Method),
+				zap.String("url", req.URL.String()),
+			)
+			return nil, fmt.Errorf("error performing request: %w", err)
+		}
+		c.metrics.recordRequest(ctx, c.serviceName, req.Method, req.URL.Host, resp.StatusCode, time.Since(startTime))
+
+		streamResp := &StreamResponse{
+			StatusCode:    resp.StatusCode,
+			Headers:       resp.Header,
+			Body:          &cancelOnClose{ReadCloser: resp.Body, cancel: cancel},
+			ContentLength: resp.ContentLength,
+			RequestID:     req.Header.Get(HeaderRequestID),
+		}
+
+		// Let the breaker count server errors as failures while still handing the response back
+		if resp.StatusCode >= 500 {
+			return streamResp, newStatusError(&Response{
+				StatusCode: resp.StatusCode,
+				Headers:    resp.Header,
+				RequestID:  streamResp.RequestID,
+			})
+		}
+		return streamResp, nil
+	}
+
+	var result interface{}
+	var err error
+	if c.config.CircuitBreaker.Enabled {
+		breakerPath := urlPath
+		if parsed, parseErr := url.Parse(c.createURL(urlPath)); parseErr == nil {
+			breakerPath = parsed.Path
+		}
+		result, err = c.breakerFor(ctx, method, breakerPath).Execute(perform)
+	} else {
+		result, err = perform()
+	}
+
+	if streamResp, ok := result.(*StreamResponse); ok && streamResp != nil {
+		return streamResp, nil
+	}
+	cancel()
+	return nil, err
+}
+
+// Upload streams r to the server as the request body with the given content type
+// Readers that can seek (files, bytes.Reader) are sent with their length and retried from the
+// position they had, like other requests; other readers can only be consumed once and are
+// sent chunked, without retries.
+func (c *Client) Upload(ctx context.Context, method, urlPath string, r io.Reader, contentType string, opts ...RequestOption) (*Response, error) {
+	opts = append(opts, WithHeader("Content-Type", contentType))
+	return c.Request(ctx, method, urlPath, r, opts...)
+}
+
+// DownloadOption customizes DownloadFile
+type DownloadOption func(*downloadOptions)
+
+// downloadOptions holds the settings of a download
+type downloadOptions struct {
+	progress func(written, total int64)
+	sha256   string
+	request  []RequestOption
+}
+
+// WithProgress reports download progress; total is -1 when the server did not send a length
+func WithProgress(progress func(written, total int64)) DownloadOption {
+	return func(o *downloadOptions) {
+		o.progress = progress
+	}
+}
+
+// WithSHA256 verifies the downloaded file against a hex encoded SHA-256 checksum
+func WithSHA256(checksum string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.sha256 = checksum
+	}
+}
+
+// WithDownloadRequestOptions applies request options to the download request
+func WithDownloadRequestOptions(opts ...RequestOption) DownloadOption {
+	return func(o *downloadOptions) {
+		o.request = append(o.request, opts...)
+	}
+}
+
+// DownloadFile streams the response of a GET request to the file dst and returns its size
+// The file is written next to dst and only moved into place once it is complete and its checksum
+// matches, so dst never holds a partial download.
+func (c *Client) DownloadFile(ctx context.Context, urlPath, dst string, opts ...DownloadOption) (int64, error) {
+	options := &downloadOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	resp, err := c.DoStream(ctx, http.MethodGet, urlPath, nil, options.request...)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSnippet))
+		return 0, newStatusError(&Response{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Headers,
+			Body:       body,
+			RequestID:  resp.RequestID,
+		})
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.part")
+	if err != nil {
+		return 0, fmt.Errorf("error creating download file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var hasher hash.Hash
+	var writer io.Writer = tmp
+	if options.sha256 != "" {
+		hasher = sha256.New()
+		writer = io.MultiWriter(tmp, hasher)
+	}
+	if options.progress != nil {
+		writer = &progressWriter{writer: writer, total: resp.ContentLength, progress: options.progress}
+	}
+
+	written, err := io.Copy(writer, resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, fmt.Errorf("error downloading file: %w", err)
+	}
+
+	if hasher != nil {
+		if actual := hex.EncodeToString(hasher.Sum(nil)); actual != options.sha256 {
+			return written, fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, options.sha256, actual)
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return written, fmt.Errorf("error moving download into place: %w", err)
+	}
+	return written, nil
+}
+
+// progressWriter reports the number of bytes written through it
+type progressWriter struct {
+	writer   io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	w.progress(w.written, w.total)
+	return n, err
+}
+
+// cancelOnClose releases a request's context once its streamed body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,196 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoStream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Hands the unread body to the caller and ends the request when it is closed", func(t *testing.T) {
+		ended := make(chan struct{})
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			_, _ = w.Write([]byte("id,name\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			close(ended)
+		})
+
+		resp, err := client.DoStream(ctx, http.MethodGet, "/exports/1", nil, WithTimeout(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv", resp.Headers.Get("Content-Type"))
+		assert.NotEmpty(t, resp.RequestID)
+
+		line := make([]byte, len("id,name\n"))
+		_, err = io.ReadFull(resp.Body, line)
+		require.NoError(t, err)
+		assert.Equal(t, "id,name\n", string(line))
+		select {
+		case <-ended:
+			t.Fatal("the request ended while the body was read")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		require.NoError(t, resp.Body.Close())
+		select {
+		case <-ended:
+		case <-time.After(time.Second):
+			t.Fatal("closing the body did not end the request")
+		}
+	})
+
+	t.Run("Returns server errors as responses", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream down"))
+		})
+
+		resp, err := client.DoStream(ctx, http.MethodGet, "/exports/1", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "upstream down", string(body))
+	})
+
+	t.Run("Uploads readers as the request body", func(t *testing.T) {
+		var bodies, contentTypes []string
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, fastRetries)
+
+		resp, err := client.Upload(ctx, http.MethodPut, "/files/a.csv", io.MultiReader(strings.NewReader("id\n1\n")), "text/csv")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		require.Len(t, bodies, 1, "uploads are not retried")
+		assert.Equal(t, "id\n1\n", bodies[0])
+		assert.Equal(t, "text/csv", contentTypes[0])
+	})
+
+	t.Run("Retries seekable uploads and sends their length", func(t *testing.T) {
+		var lengths []int64
+		var bodies []string
+		client := newTestClient(t, "https://api.example.com", RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			lengths = append(lengths, req.ContentLength)
+			bodies = append(bodies, string(body))
+
+			status := http.StatusOK
+			if len(bodies) == 1 {
+				status = http.StatusServiceUnavailable
+			}
+			return &http.Response{StatusCode: status, Body: http.NoBody, Request: req}, nil
+		}), fastRetries)
+
+		reader := strings.NewReader("skip,id\n1\n")
+		_, err := reader.Seek(5, io.SeekStart)
+		require.NoError(t, err)
+
+		resp, err := client.Upload(ctx, http.MethodPut, "/files/a.csv", reader, "text/csv")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"id\n1\n", "id\n1\n"}, bodies, "retries resend the body from its position")
+		assert.Equal(t, []int64{5, 5}, lengths)
+	})
+}
+
+func TestDownloadFile(t *testing.T) {
+	ctx := context.Background()
+	content := strings.Repeat("quiz data\n", 1000)
+	sum := sha256.Sum256([]byte(content))
+	checksum := hex.EncodeToString(sum[:])
+
+	newDownloadClient := func(t *testing.T) *Client {
+		return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/exports/missing" {
+				respondJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write([]byte(content))
+		})
+	}
+
+	t.Run("Writes the file and reports progress", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "export.csv")
+		var lastWritten, lastTotal int64
+
+		written, err := newDownloadClient(t).DownloadFile(ctx, "/exports/1", dst,
+			WithSHA256(checksum),
+			WithProgress(func(written, total int64) { lastWritten, lastTotal = written, total }),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), written)
+		assert.Equal(t, int64(len(content)), lastWritten)
+		assert.Equal(t, int64(len(content)), lastTotal)
+
+		data, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("Is not bound by the request timeout", func(t *testing.T) {
+		client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("id,name\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(500 * time.Millisecond)
+			_, _ = w.Write([]byte("1,Quiz\n"))
+		}, func(cfg *Config) {
+			cfg.Timeouts.RequestTimeout = 200 * time.Millisecond
+		})
+
+		dst := filepath.Join(t.TempDir(), "slow.csv")
+		written, err := client.DownloadFile(ctx, "/exports/1.csv", dst)
+		require.NoError(t, err)
+		assert.Equal(t, int64(15), written)
+	})
+
+	t.Run("Leaves no file when the checksum does not match", func(t *testing.T) {
+		dir := t.TempDir()
+		dst := filepath.Join(dir, "export.csv")
+
+		_, err := newDownloadClient(t).DownloadFile(ctx, "/exports/1", dst, WithSHA256(strings.Repeat("0", 64)))
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+		assertEmptyDir(t, dir)
+	})
+
+	t.Run("Returns failed responses as status errors", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := newDownloadClient(t).DownloadFile(ctx, "/exports/missing", filepath.Join(dir, "export.csv"))
+		var statusErr *StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		assert.Contains(t, string(statusErr.Body), "not found")
+		assertEmptyDir(t, dir)
+	})
+}
+
+// assertEmptyDir fails the test when dir holds files, e.g. a partial download
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// TLSConfig configures server verification and client certificates (mTLS)
+type TLSConfig struct {
+	// CertFile and KeyFile hold the PEM client certificate and key presented to servers
+	CertFile string
+	KeyFile  string
+
+	// CAFile holds a PEM bundle of CAs trusted in addition to the system pool
+	CAFile string
+
+	// ServerName overrides the name used to verify the server certificate
+	ServerName string
+
+	// MinVersion is the lowest accepted TLS version; defaults to TLS 1.2
+	MinVersion uint16
+
+	// InsecureSkipVerify disables server certificate verification; only for local development
+	InsecureSkipVerify bool
+
+	// ReloadInterval is how often the certificate files are checked for changes; 0 disables reloading
+	ReloadInterval time.Duration
+}
+
+// certReloader serves the client certificate, reloading it when the files change
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// newCertReloader loads the certificate, failing when it cannot be read
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the certificate again when either file changed since the last load
+func (r *certReloader) reload() error {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat client certificate: %w", err)
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && !modTime.After(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// watch reloads the certificate every interval until ctx is done
+// Failed reloads keep the previous certificate, e.g. while files are half written during rotation.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reload(); err != nil {
+				logger.Warn("Failed to reload client certificate", zap.String("cert", r.certFile), zap.Error(err))
+			}
+		}
+	}
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// latestModTime returns the most recent modification time of files
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// buildTLSConfig creates the transport's TLS configuration; certificate reloading stops with ctx
+func buildTLSConfig(ctx context.Context, cfg *TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         cfg.MinVersion,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // opt-in for local development
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	if cfg.InsecureSkipVerify {
+		logger.Warn("TLS certificate verification is disabled for HTTP client")
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+		if cfg.ReloadInterval > 0 {
+			go reloader.watch(ctx, cfg.ReloadInterval)
+		}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCert writes a self-signed client certificate for commonName to dir, returning the
+// paths of its certificate and key files
+func writeTestCert(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// commonName returns the subject of the leaf of cert
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+// touch moves the modification time of files forward, as a rotation writing them later would
+func touch(t *testing.T, at time.Time, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		require.NoError(t, os.Chtimes(file, at, at))
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Defaults to TLS 1.2", func(t *testing.T) {
+		tlsConfig, err := buildTLSConfig(ctx, &TLSConfig{ServerName: "api.internal"})
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+		assert.Equal(t, "api.internal", tlsConfig.ServerName)
+		assert.Nil(t, tlsConfig.RootCAs)
+		assert.Nil(t, tlsConfig.GetClientCertificate)
+	})
+
+	t.Run("Rejects unreadable or empty CA bundles", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := buildTLSConfig(ctx, &TLSConfig{CAFile: filepath.Join(dir, "missing.pem")})
+		assert.Error(t, err)
+
+		empty := filepath.Join(dir, "empty.pem")
+		require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+		_, err = buildTLSConfig(ctx, &TLSConfig{CAFile: empty})
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects unreadable client certificates", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := buildTLSConfig(ctx, &TLSConfig{CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Presents the client certificate to servers trusted through the CA bundle", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		server.StartTLS()
+		defer server.Close()
+
+		dir := t.TempDir()
+		caFile := filepath.Join(dir, "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+		certFile, keyFile := writeTestCert(t, dir, "quizzes-service")
+
+		cfg := DefaultConfig(server.URL)
+		cfg.Retry.Enabled = false
+		cfg.CircuitBreaker.Enabled = false
+		cfg.Tracing = false
+		cfg.Transport.TLS = &TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}
+		client, err := New(cfg)
+		require.NoError(t, err)
+		defer client.Close()
+
+		resp, err := client.Get(ctx, "/", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "quizzes-service", string(resp.Body))
+	})
+}
+
+func TestCertReloader(t *testing.T) {
+	t.Run("Reloads changed certificates", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCert(t, dir, "first")
+		reloader, err := newCertReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		writeTestCert(t, dir, "second")
+		touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+		require.NoError(t, reloader.reload())
+
+		cert, err := reloader.GetClientCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "second", commonName(t, cert))
+	})
+
+	t.Run("Skips unchanged files", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCert(t, dir, "first")
+		reloader, err := newCertReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		// Content changed without a newer modification time is not read
+		at := reloader.modTime
+		writeTestCert(t, dir, "second")
+		touch(t, at, certFile, keyFile)
+		require.NoError(t, reloader.reload())
+
+		cert, _ := reloader.GetClientCertificate(nil)
+		assert.Equal(t, "first", commonName(t, cert))
+	})
+
+	t.Run("Keeps the certificate when a reload fails", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCert(t, dir, "first")
+		reloader, err := newCertReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(keyFile, []byte("half written"), 0o600))
+		touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+		assert.Error(t, reloader.reload())
+
+		cert, _ := reloader.GetClientCertificate(nil)
+		assert.Equal(t, "first", commonName(t, cert))
+	})
+
+	t.Run("Watches the files until the context ends", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCert(t, dir, "first")
+		reloader, err := newCertReloader(certFile, keyFile)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			reloader.watch(ctx, 5*time.Millisecond)
+			close(stopped)
+		}()
+
+		writeTestCert(t, dir, "second")
+		touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+		assert.Eventually(t, func() bool {
+			cert, _ := reloader.GetClientCertificate(nil)
+			return commonName(t, cert) == "second"
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+		<-stopped
+	})
+}
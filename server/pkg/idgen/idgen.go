@@ -0,0 +1,136 @@
+// Package idgen generates unique string IDs for domain entities with a pluggable strategy
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"quizizz.com/pkg/clock"
+)
+
+// Strategies selectable by name with New
+const (
+	StrategyUUIDv7 = "uuidv7"
+	StrategyULID   = "ulid"
+	StrategyNanoID = "nanoid"
+)
+
+// DefaultNanoIDSize is the length of NanoIDs, giving about as many random bits as a UUID
+const DefaultNanoIDSize = 21
+
+// Generator creates unique IDs
+// Implementations are safe for concurrent use.
+type Generator interface {
+	// NewID returns a new unique ID
+	NewID() string
+}
+
+// Func adapts a function to a Generator, e.g. for deterministic IDs in tests
+type Func func() string
+
+// NewID calls f
+func (f Func) NewID() string {
+	return f()
+}
+
+// New returns the generator for strategy, reading time from clk where the strategy embeds it
+func New(strategy string, clk clock.Clock) (Generator, error) {
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case StrategyUUIDv7, "":
+		return NewUUIDv7(clk), nil
+	case StrategyULID:
+		return NewULID(clk), nil
+	case StrategyNanoID:
+		return NewNanoID(DefaultNanoIDSize), nil
+	default:
+		return nil, fmt.Errorf("unknown ID strategy %q (expected %s, %s or %s)", strategy, StrategyUUIDv7, StrategyULID, StrategyNanoID)
+	}
+}
+
+// uuidV7 generates RFC 9562 version 7 UUIDs: a millisecond timestamp followed by random bits,
+// so IDs sort by creation millisecond and index well
+type uuidV7 struct {
+	clock clock.Clock
+}
+
+// NewUUIDv7 returns a generator of time-ordered UUIDs, e.g. 01920d6e-8f3a-7c4b-9d2e-5f6a7b8c9d0e
+func NewUUIDv7(clk clock.Clock) Generator {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return uuidV7{clock: clk}
+}
+
+func (g uuidV7) NewID() string {
+	var id uuid.UUID
+	if _, err := io.ReadFull(rand.Reader, id[6:]); err != nil {
+		panic(fmt.Sprintf("idgen: failed to read random bytes: %v", err))
+	}
+
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(g.clock.Now().UnixMilli()))
+	copy(id[:6], ms[2:])
+
+	id[6] = id[6]&0x0f | 0x70 // version 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 9562 variant
+	return id.String()
+}
+
+// ulidGenerator generates ULIDs, monotonic within a millisecond
+type ulidGenerator struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	entropy *ulid.MonotonicEntropy
+}
+
+// NewULID returns a generator of lexicographically sortable ULIDs, e.g. 01J8F3QW5X2Y7Z9A0B1C2D3E4F
+func NewULID(clk clock.Clock) Generator {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &ulidGenerator{
+		clock:   clk,
+		entropy: ulid.Monotonic(rand.Reader, 0),
+	}
+}
+
+func (g *ulidGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return ulid.MustNew(ulid.Timestamp(g.clock.Now()), g.entropy).String()
+}
+
+// nanoIDAlphabet is the URL-safe alphabet of NanoIDs; its 64 symbols map to 6 random bits each
+const nanoIDAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// nanoID generates random URL-safe IDs of a fixed size
+type nanoID struct {
+	size int
+}
+
+// NewNanoID returns a generator of random URL-safe IDs of size characters, e.g. V1StGXR8_Z5jdHi6B-myT
+// IDs carry no timestamp, so they do not sort by creation time.
+func NewNanoID(size int) Generator {
+	if size <= 0 {
+		size = DefaultNanoIDSize
+	}
+	return nanoID{size: size}
+}
+
+func (g nanoID) NewID() string {
+	buf := make([]byte, g.size)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		panic(fmt.Sprintf("idgen: failed to read random bytes: %v", err))
+	}
+	for i, b := range buf {
+		buf[i] = nanoIDAlphabet[b&63]
+	}
+	return string(buf)
+}
@@ -0,0 +1,64 @@
+// Package listener creates server listeners that survive restarts without dropping connections
+//
+// Two deployment styles are supported. With SO_REUSEPORT, the new process binds the same port while
+// the old one is still serving, then the old one is stopped and drains its connections. With socket
+// activation (systemd or a supervisor speaking its protocol), the listening socket is held by the
+// supervisor and handed to each process in turn, so it is never closed across restarts.
+package listener
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// firstInheritedFD is the file descriptor of the first socket passed with socket activation
+const firstInheritedFD = 3
+
+// Config selects how listeners are created
+type Config struct {
+	// ReusePort sets SO_REUSEPORT so another process can listen on the same port
+	ReusePort bool
+}
+
+// Inherited returns the listening socket passed to the process with socket activation, or nil
+// Only the first socket is used. The activation variables are cleared, so the socket is returned
+// once and child processes do not claim it.
+func Inherited() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(firstInheritedFD, "listener")
+	defer file.Close()
+
+	l, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use inherited listener: %w", err)
+	}
+	return l, nil
+}
+
+// Listen listens on the TCP address addr
+func Listen(ctx context.Context, addr string, cfg Config) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	if cfg.ReusePort {
+		lc.Control = reusePort
+	}
+
+	l, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return l, nil
+}
@@ -0,0 +1,119 @@
+// Package metrics declares the business metrics of the services: counters and histograms of domain
+// operations, such as users created or quizzes completed
+//
+// Metrics are declared by name on a Registry, usually Default, and declaring a name again returns
+// the same metric, so services declare what they record where they record it. Measurements are
+// recorded with the context of the operation: when it carries a sampled span, the trace is linked
+// to the measurement as an exemplar, and served with the metrics in the OpenMetrics format.
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Default is the registry of the business metrics exported with the other metrics of the service
+var Default = NewRegistry(otel.Meter("business"))
+
+// Registry declares metrics on a meter, once per name
+type Registry struct {
+	meter metric.Meter
+
+	mu         sync.Mutex
+	counters   map[string]*Counter
+	histograms map[string]*Histogram
+}
+
+// NewRegistry creates a Registry declaring metrics on meter
+func NewRegistry(meter metric.Meter) *Registry {
+	return &Registry{
+		meter:      meter,
+		counters:   make(map[string]*Counter),
+		histograms: make(map[string]*Histogram),
+	}
+}
+
+// Counter returns the counter named name, declaring it on first use
+// Names are dotted, e.g. users.created, and exported as users_created_total.
+func (r *Registry) Counter(name, description string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if counter, ok := r.counters[name]; ok {
+		return counter
+	}
+	counter := &Counter{}
+	var err error
+	counter.counter, err = r.meter.Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		logger.Error("Failed to create business counter", zap.String("metric", name), zap.Error(err))
+	}
+	r.counters[name] = counter
+	return counter
+}
+
+// Histogram returns the histogram named name, in unit, declaring it on first use
+func (r *Registry) Histogram(name, description, unit string) *Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if histogram, ok := r.histograms[name]; ok {
+		return histogram
+	}
+	histogram := &Histogram{}
+	var err error
+	histogram.histogram, err = r.meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit(unit))
+	if err != nil {
+		logger.Error("Failed to create business histogram", zap.String("metric", name), zap.Error(err))
+	}
+	r.histograms[name] = histogram
+	return histogram
+}
+
+// NewCounter returns the counter named name of Default
+func NewCounter(name, description string) *Counter {
+	return Default.Counter(name, description)
+}
+
+// NewHistogram returns the histogram named name of Default
+func NewHistogram(name, description, unit string) *Histogram {
+	return Default.Histogram(name, description, unit)
+}
+
+// Counter counts occurrences of a business operation
+// A counter whose creation failed records nothing.
+type Counter struct {
+	counter metric.Int64Counter
+}
+
+// Add adds n to the counter
+// Attributes must have a bounded set of values, e.g. an outcome, never an ID.
+func (c *Counter) Add(ctx context.Context, n int64, attrs ...attribute.KeyValue) {
+	if c.counter != nil {
+		c.counter.Add(ctx, n, metric.WithAttributes(attrs...))
+	}
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc(ctx context.Context, attrs ...attribute.KeyValue) {
+	c.Add(ctx, 1, attrs...)
+}
+
+// Histogram records the distribution of a value of business operations, e.g. their duration
+// A histogram whose creation failed records nothing.
+type Histogram struct {
+	histogram metric.Float64Histogram
+}
+
+// Record records value
+func (h *Histogram) Record(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	if h.histogram != nil {
+		h.histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	"quizizz.com/pkg/correlation"
+	appotel "quizizz.com/pkg/otel"
+)
+
+// HeaderClientVersion is the request header carrying the calling app's version
+const HeaderClientVersion = "X-Client-Version"
+
+// Baggage is a middleware that puts the user, tenant, impersonating actor and client app version of the request into
+// OTEL baggage, so they are recorded on every span and propagated by httpclient to downstream services
+// Incoming baggage is kept, but for the members the server sets, which callers cannot claim; it must
+// run after Correlation and, when tracing is enabled, OTEL.
+func Baggage() gin.HandlerFunc {
+	propagator := propagation.Baggage{}
+
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx = appotel.WithoutReservedBaggage(ctx)
+		ids := correlation.FromContext(ctx)
+
+		ctx = appotel.WithBaggage(ctx, map[string]string{
+			appotel.BaggageUserID:        ids.UserID,
+			appotel.BaggageTenantID:      ids.TenantID,
+			appotel.BaggageActorID:       ids.ActorID,
+			appotel.BaggageClientVersion: c.GetHeader(HeaderClientVersion),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
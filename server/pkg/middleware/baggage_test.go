@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+	"quizizz.com/pkg/correlation"
+	appotel "quizizz.com/pkg/otel"
+)
+
+func TestBaggage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// serve runs Baggage for a request authenticated as ids and returns the baggage handlers see
+	serve := func(ids correlation.IDs, header string) baggage.Baggage {
+		var bag baggage.Baggage
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(correlation.WithIDs(c.Request.Context(), ids))
+		}, Baggage())
+		router.GET("/", func(c *gin.Context) { bag = baggage.FromContext(c.Request.Context()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Baggage", header)
+		req.Header.Set(HeaderClientVersion, "2.1.0")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return bag
+	}
+
+	t.Run("Replaces the reserved members claimed by the caller", func(t *testing.T) {
+		bag := serve(correlation.IDs{UserID: "user-1"}, "user.id=admin,tenant.id=other,actor.id=admin,client.version=9.9.9,feature=beta")
+
+		assert.Equal(t, "user-1", bag.Member(appotel.BaggageUserID).Value())
+		assert.Equal(t, "2.1.0", bag.Member(appotel.BaggageClientVersion).Value())
+		assert.Empty(t, bag.Member(appotel.BaggageTenantID).Value(), "the request has no tenant")
+		assert.Empty(t, bag.Member(appotel.BaggageActorID).Value(), "the request is not impersonated")
+		assert.Equal(t, "beta", bag.Member("feature").Value(), "other members are kept")
+	})
+
+	t.Run("Anonymous callers cannot claim a user", func(t *testing.T) {
+		bag := serve(correlation.IDs{}, "user.id=admin")
+		assert.Empty(t, bag.Member(appotel.BaggageUserID).Value())
+	})
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// CachePolicy declares how clients and shared caches may cache a route's responses
+type CachePolicy struct {
+	// MaxAge is how long clients may reuse a response (0 sends no-cache)
+	MaxAge time.Duration
+
+	// SharedMaxAge overrides MaxAge for shared caches such as CDNs (s-maxage)
+	SharedMaxAge time.Duration
+
+	// Private forbids shared caches from storing the response
+	Private bool
+
+	// NoStore forbids caching entirely; other fields are ignored
+	NoStore bool
+
+	// Vary lists request headers that select between cached representations
+	Vary []string
+
+	// ServerTTL enables the Redis response cache for the route (0 disables it)
+	ServerTTL time.Duration
+
+	// Key derives the server-side cache key; defaults to KeyByURL
+	Key CacheKeyFunc
+
+	// Deduplicate collapses concurrent identical requests to the route into one execution
+	Deduplicate bool
+}
+
+// CacheControl returns the Cache-Control header value for the policy
+func (p CachePolicy) CacheControl() string {
+	if p.NoStore {
+		return "no-store"
+	}
+
+	directives := []string{"public"}
+	if p.Private {
+		directives[0] = "private"
+	}
+
+	if p.MaxAge > 0 {
+		directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge.Seconds())))
+	} else {
+		directives = append(directives, "no-cache")
+	}
+
+	if p.SharedMaxAge > 0 && !p.Private {
+		directives = append(directives, "s-maxage="+strconv.Itoa(int(p.SharedMaxAge.Seconds())))
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl returns a middleware setting Cache-Control, Expires and Vary from policy
+func CacheControl(policy CachePolicy) gin.HandlerFunc {
+	cacheControl := policy.CacheControl()
+	vary := strings.Join(policy.Vary, ", ")
+
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", cacheControl)
+		if policy.MaxAge > 0 && !policy.NoStore {
+			c.Header("Expires", time.Now().Add(policy.MaxAge).UTC().Format(http.TimeFormat))
+		}
+		if vary != "" {
+			c.Header("Vary", vary)
+		}
+
+		c.Next()
+	}
+}
+
+// CacheKeyFunc derives the response cache key for a request
+type CacheKeyFunc func(c *gin.Context) string
+
+// KeyByURL keys responses by method, path and (sorted) query string
+func KeyByURL(c *gin.Context) string {
+	return c.Request.Method + ":" + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+}
+
+// KeyByURLAndHeaders keys responses by URL and the values of the given request headers
+// Use it for routes whose representation varies by header (e.g. Accept-Language).
+// Header values are hashed so credentials never appear in cache keys.
+func KeyByURLAndHeaders(headers ...string) CacheKeyFunc {
+	return func(c *gin.Context) string {
+		hash := sha256.New()
+		for _, header := range headers {
+			fmt.Fprintf(hash, "%s=%s\n", strings.ToLower(header), c.GetHeader(header))
+		}
+		return KeyByURL(c) + "#" + hex.EncodeToString(hash.Sum(nil)[:16])
+	}
+}
+
+// cachedResponse is the representation of a response stored in Redis
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	ETag        string `json:"etag,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// ResponseCache is a Redis-backed full-response cache for expensive idempotent GETs
+type ResponseCache struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewResponseCache creates a ResponseCache storing entries under prefix
+// A nil client yields a cache whose middleware is a no-op.
+func NewResponseCache(client redis.Cmdable, prefix string) *ResponseCache {
+	return &ResponseCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Middleware returns a middleware caching successful responses for ttl using key
+// A CacheTTL middleware earlier in the chain overrides ttl; routes with no ttl are never cached.
+// Hits carry the request ID of the request they answer in the meta block of their envelope,
+// not that of the request that filled the cache.
+func (rc *ResponseCache) Middleware(ttl time.Duration, key CacheKeyFunc) gin.HandlerFunc {
+	if key == nil {
+		key = KeyByURL
+	}
+
+	return func(c *gin.Context) {
+		ttl := ttl
+		if override, ok := c.Get(cacheTTLKey); ok && ttl > 0 {
+			ttl = override.(time.Duration)
+		}
+		if rc == nil || rc.client == nil || ttl <= 0 || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		cacheKey := rc.prefix + key(c)
+
+		if cached, ok := rc.get(ctx, cacheKey); ok {
+			if cached.ETag != "" {
+				c.Header("ETag", cached.ETag)
+			}
+			c.Header("X-Cache", "HIT")
+			c.Data(cached.Status, cached.ContentType, withRequestID(cached.Body, c.GetString(requestIDKey)))
+			c.Abort()
+			return
+		}
+
+		c.Header("X-Cache", "MISS")
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() != http.StatusOK || writer.body.Len() == 0 {
+			return
+		}
+
+		entry := cachedResponse{
+			Status:      http.StatusOK,
+			ContentType: writer.Header().Get("Content-Type"),
+			ETag:        writer.Header().Get("ETag"),
+			Body:        writer.body.Bytes(),
+		}
+		rc.set(ctx, cacheKey, entry, ttl)
+	}
+}
+
+// Purge removes every cached variant of GET responses for path
+func (rc *ResponseCache) Purge(ctx context.Context, path string) error {
+	if rc == nil || rc.client == nil {
+		return nil
+	}
+
+	pattern := rc.prefix + http.MethodGet + ":" + path + "?*"
+	iter := rc.client.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan response cache: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := rc.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to purge response cache: %w", err)
+	}
+	return nil
+}
+
+// get reads a cached response, treating Redis errors as misses
+func (rc *ResponseCache) get(ctx context.Context, key string) (*cachedResponse, bool) {
+	raw, err := rc.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.WarnCtx(ctx, "Failed to read response cache", zap.String("key", key), zap.Error(err))
+		}
+		return nil, false
+	}
+
+	var cached cachedResponse
+	if err := json.Unmarshal(raw, &cached); err != nil {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// set stores a response, logging instead of failing the request on errors
+func (rc *ResponseCache) set(ctx context.Context, key string, entry cachedResponse, ttl time.Duration) {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := rc.client.Set(ctx, key, raw, ttl).Err(); err != nil {
+		logger.WarnCtx(ctx, "Failed to write response cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// recordingWriter copies the response body while writing it through
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
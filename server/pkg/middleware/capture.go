@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/pkg/capture"
+	"quizizz.com/pkg/redact"
+	"quizizz.com/pkg/requestid"
+)
+
+// CaptureConfig controls which requests are captured and how much of them is kept
+type CaptureConfig struct {
+	// Recorder receives the captured exchanges
+	Recorder *capture.Recorder
+
+	// SampleRate is the fraction of requests captured (0.0 - 1.0)
+	SampleRate float64
+
+	// MaxBodySize caps the bytes kept of each request and response body (default 64KiB)
+	MaxBodySize int
+
+	// Redactor removes secrets from headers, query strings and bodies; defaults redact.New(nil, nil, nil)
+	Redactor *redact.Redactor
+
+	// Skip excludes paths from capture, e.g. health checks and metrics
+	Skip []string
+}
+
+// Capture returns a middleware recording a sample of sanitized request/response pairs for replay
+func Capture(cfg CaptureConfig) gin.HandlerFunc {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 64 << 10
+	}
+	if cfg.Redactor == nil {
+		cfg.Redactor = redact.New(nil, nil, nil)
+	}
+	skip := make(map[string]bool, len(cfg.Skip))
+	for _, path := range cfg.Skip {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if cfg.Recorder == nil || skip[c.Request.URL.Path] || rand.Float64() >= cfg.SampleRate {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body []byte
+		truncated := false
+		if c.Request.Body != nil {
+			// Read one byte past the limit to know whether the body was cut short
+			prefix, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(cfg.MaxBodySize)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(prefix), c.Request.Body), c.Request.Body}
+			body, truncated = clip(prefix, cfg.MaxBodySize)
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer, limit: cfg.MaxBodySize}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		redactor := cfg.Redactor
+		exchange := &capture.Exchange{
+			Time:           start.UTC(),
+			RequestID:      requestid.FromContext(c.Request.Context()),
+			Method:         c.Request.Method,
+			Path:           c.Request.URL.Path,
+			Query:          redactor.Values(c.Request.URL.Query()).Encode(),
+			Header:         redactor.Header(c.Request.Header),
+			Status:         writer.Status(),
+			ResponseHeader: redactor.Header(writer.Header()),
+			Duration:       time.Since(start),
+			Truncated:      truncated || writer.truncated,
+		}
+		if len(body) > 0 {
+			exchange.Body = captureBody(redactor, body, truncated, c.ContentType())
+		}
+		if writer.body.Len() > 0 {
+			exchange.ResponseBody = captureBody(redactor, writer.body.Bytes(), writer.truncated, writer.Header().Get("Content-Type"))
+		}
+		cfg.Recorder.Record(exchange)
+	}
+}
+
+// captureBody redacts a captured body; truncated bodies cannot be parsed, so only patterns are redacted
+func captureBody(redactor *redact.Redactor, body []byte, truncated bool, contentType string) string {
+	if truncated {
+		return redactor.Text(string(body))
+	}
+	return redactor.Body(body, contentType)
+}
+
+// clip returns data cut to limit bytes and whether it was longer
+func clip(data []byte, limit int) ([]byte, bool) {
+	if len(data) > limit {
+		return data[:limit], true
+	}
+	return data, false
+}
+
+// capturingWriter copies up to limit bytes of the response body while writing it through
+type capturingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// keep copies as much of data as fits under the limit
+func (w *capturingWriter) keep(data []byte) {
+	remaining := w.limit - w.body.Len()
+	if len(data) > remaining {
+		data = data[:remaining]
+		w.truncated = true
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"quizizz.com/pkg/clientip"
+)
+
+// ClientIP is a middleware putting the client IP of the request into its context, for code
+// without access to the gin context such as services recording audit events
+// The IP is resolved by gin from the engine's trusted proxies (see clientip.Configure).
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(clientip.WithContext(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/pkg/clientip"
+)
+
+func newClientIPTestRouter(t *testing.T, trustedProxies, headers []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, clientip.Configure(router, trustedProxies, headers))
+	router.Use(ClientIP())
+	router.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, clientip.FromContext(c.Request.Context()))
+	})
+	return router
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "fd00::/8"}
+	headers := []string{clientip.HeaderCFConnectingIP, clientip.HeaderForwardedFor, clientip.HeaderRealIP}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     map[string]string
+		want       string
+	}{
+		{
+			name:       "Uses the peer address without headers",
+			remoteAddr: "203.0.113.7:52100",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Ignores headers spoofed by untrusted peers",
+			remoteAddr: "203.0.113.7:52100",
+			header: map[string]string{
+				clientip.HeaderForwardedFor:   "198.51.100.1",
+				clientip.HeaderRealIP:         "198.51.100.2",
+				clientip.HeaderCFConnectingIP: "198.51.100.3",
+			},
+			want: "203.0.113.7",
+		},
+		{
+			name:       "Reads the client from a trusted proxy",
+			remoteAddr: "10.0.0.5:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Skips trusted hops of multi-hop chains",
+			remoteAddr: "10.0.0.5:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "203.0.113.7, 10.1.0.1, 10.2.0.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Stops at the first untrusted hop of a chain",
+			remoteAddr: "10.0.0.5:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "198.51.100.1, 203.0.113.7, 10.1.0.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Prefers headers in configured order",
+			remoteAddr: "10.0.0.5:52100",
+			header: map[string]string{
+				clientip.HeaderCFConnectingIP: "203.0.113.7",
+				clientip.HeaderForwardedFor:   "198.51.100.1",
+			},
+			want: "203.0.113.7",
+		},
+		{
+			name:       "Falls back to later headers",
+			remoteAddr: "10.0.0.5:52100",
+			header:     map[string]string{clientip.HeaderRealIP: "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Ignores invalid header values",
+			remoteAddr: "10.0.0.5:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "not-an-ip"},
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "Uses IPv6 peer addresses",
+			remoteAddr: "[2001:db8::7]:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "2001:db8::1"},
+			want:       "2001:db8::7",
+		},
+		{
+			name:       "Reads IPv6 clients from IPv6 proxies",
+			remoteAddr: "[fd00::5]:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "2001:db8::7, fd00::9"},
+			want:       "2001:db8::7",
+		},
+		{
+			name:       "Reads IPv4 clients from IPv6 proxies",
+			remoteAddr: "[fd00::5]:52100",
+			header:     map[string]string{clientip.HeaderForwardedFor: "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+	}
+
+	router := newClientIPTestRouter(t, proxies, headers)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+
+	t.Run("Ignores headers without trusted proxies", func(t *testing.T) {
+		router := newClientIPTestRouter(t, nil, headers)
+
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.5:52100"
+		req.Header.Set(clientip.HeaderForwardedFor, "203.0.113.7")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "10.0.0.5", w.Body.String())
+	})
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"quizizz.com/pkg/correlation"
+	"quizizz.com/pkg/requestid"
+)
+
+// Correlation is a middleware that joins the request to the flow named by the caller's
+// X-Correlation-ID (or starts a new flow) and stores the IDs in the request context
+// It must run after RequestID. User and tenant IDs are not taken from headers; they are
+// set by authentication.
+func Correlation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		ids := correlation.FromContext(ctx)
+
+		if correlationID := c.GetHeader(correlation.HeaderCorrelationID); requestid.Valid(correlationID) {
+			ids.CorrelationID = correlationID
+		}
+		if causationID := c.GetHeader(correlation.HeaderCausationID); requestid.Valid(causationID) {
+			ids.CausationID = causationID
+		}
+
+		c.Set("correlationID", ids.CorrelationID)
+		c.Request = c.Request.WithContext(correlation.WithIDs(ctx, ids))
+		c.Header(correlation.HeaderCorrelationID, ids.CorrelationID)
+
+		c.Next()
+	}
+}
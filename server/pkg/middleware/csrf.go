@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// csrfTokenBytes is the number of random bytes of a CSRF token
+const csrfTokenBytes = 32
+
+// CSRFConfig configures the double-submit CSRF protection of cookie-authenticated requests
+type CSRFConfig struct {
+	// SessionCookie is the cookie authenticating browser clients; requests without it, e.g. those
+	// sending bearer tokens, cannot be forged by another site and are not checked
+	SessionCookie string
+
+	// CookieName is the cookie holding the CSRF token (default "csrf_token")
+	CookieName string
+
+	// HeaderName is the request header echoing the token on unsafe requests (default "X-CSRF-Token")
+	HeaderName string
+
+	// SameSite is the SameSite attribute of the token cookie (default Lax)
+	SameSite http.SameSite
+
+	// Secure restricts the token cookie to HTTPS
+	Secure bool
+
+	// MaxAge is the lifetime of the token cookie (default 12h)
+	MaxAge time.Duration
+}
+
+// withDefaults returns cfg with its unset fields defaulted
+func (cfg CSRFConfig) withDefaults() CSRFConfig {
+	if cfg.CookieName == "" {
+		cfg.CookieName = "csrf_token"
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 12 * time.Hour
+	}
+	return cfg
+}
+
+// CSRF returns a middleware rejecting unsafe requests authenticated by the session cookie unless
+// they echo the token of the CSRF cookie in the CSRF header
+// Another site can make a browser send the cookies but cannot read them, so it cannot set the header.
+// Tokens are issued by IssueCSRFToken.
+func CSRF(cfg CSRFConfig) gin.HandlerFunc {
+	cfg = cfg.withDefaults()
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+		if _, err := c.Cookie(cfg.SessionCookie); err != nil {
+			c.Next()
+			return
+		}
+
+		cookie, err := c.Cookie(cfg.CookieName)
+		header := c.GetHeader(cfg.HeaderName)
+		if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			abortWithError(c, http.StatusForbidden, "CSRF_TOKEN_INVALID", "Missing or invalid CSRF token")
+			return
+		}
+		c.Next()
+	}
+}
+
+// IssueCSRFToken returns the CSRF token of the client, setting a new token cookie when it has none
+// Clients send the token back in the CSRF header of their unsafe requests.
+func IssueCSRFToken(c *gin.Context, cfg CSRFConfig) (string, error) {
+	cfg = cfg.withDefaults()
+	if token, err := c.Cookie(cfg.CookieName); err == nil && len(token) == base64.RawURLEncoding.EncodedLen(csrfTokenBytes) {
+		return token, nil
+	}
+
+	b := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(cfg.MaxAge.Seconds()),
+		Secure:   cfg.Secure,
+		HttpOnly: true,
+		SameSite: cfg.SameSite,
+	})
+	return token, nil
+}
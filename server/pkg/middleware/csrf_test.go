@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCSRFTestRouter(cfg CSRFConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CSRF(cfg))
+	router.Any("/quizzes", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/csrf", func(c *gin.Context) {
+		token, err := IssueCSRFToken(c, cfg)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, token)
+	})
+	return router
+}
+
+func TestCSRF(t *testing.T) {
+	cfg := CSRFConfig{SessionCookie: "session"}
+	router := newCSRFTestRouter(cfg)
+
+	send := func(method string, cookies map[string]string, header string) int {
+		req := httptest.NewRequest(method, "/quizzes", nil)
+		for name, value := range cookies {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Safe methods pass without a token", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace} {
+			assert.Equal(t, http.StatusNoContent, send(method, map[string]string{"session": "s"}, ""), method)
+		}
+	})
+
+	t.Run("Requests without the session cookie are not checked", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, send(http.MethodPost, nil, ""))
+	})
+
+	t.Run("Missing token cookie", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPost, map[string]string{"session": "s"}, "token"))
+	})
+
+	t.Run("Missing header", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodDelete, map[string]string{"session": "s", "csrf_token": "token"}, ""))
+	})
+
+	t.Run("Header not matching the cookie", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(http.MethodPut, map[string]string{"session": "s", "csrf_token": "token"}, "other"))
+	})
+
+	t.Run("Empty token cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/quizzes", nil)
+		req.Header.Set("Cookie", "session=s; csrf_token=")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Matching header", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, send(http.MethodPatch, map[string]string{"session": "s", "csrf_token": "token"}, "token"))
+	})
+}
+
+func TestIssueCSRFToken(t *testing.T) {
+	t.Run("Sets the token cookie", func(t *testing.T) {
+		router := newCSRFTestRouter(CSRFConfig{SessionCookie: "session", Secure: true, SameSite: http.SameSiteStrictMode, MaxAge: time.Hour})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/csrf", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		cookie := cookies[0]
+		assert.Equal(t, "csrf_token", cookie.Name)
+		assert.Equal(t, w.Body.String(), cookie.Value)
+		assert.Len(t, cookie.Value, 43)
+		assert.Equal(t, "/", cookie.Path)
+		assert.Equal(t, 3600, cookie.MaxAge)
+		assert.True(t, cookie.Secure)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+	})
+
+	t.Run("Defaults the cookie attributes", func(t *testing.T) {
+		router := newCSRFTestRouter(CSRFConfig{SessionCookie: "session"})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/csrf", nil))
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, int((12 * time.Hour).Seconds()), cookies[0].MaxAge)
+		assert.False(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	})
+
+	t.Run("Keeps a valid token", func(t *testing.T) {
+		router := newCSRFTestRouter(CSRFConfig{SessionCookie: "session"})
+		token := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+		req := httptest.NewRequest(http.MethodGet, "/csrf", nil)
+		req.AddCookie(&http.Cookie{Name: "csrf_token", Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, token, w.Body.String())
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("Replaces a malformed token", func(t *testing.T) {
+		router := newCSRFTestRouter(CSRFConfig{SessionCookie: "session"})
+
+		req := httptest.NewRequest(http.MethodGet, "/csrf", nil)
+		req.AddCookie(&http.Cookie{Name: "csrf_token", Value: "short"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Len(t, w.Result().Cookies(), 1)
+		assert.NotEqual(t, "short", w.Body.String())
+	})
+
+	t.Run("Issued tokens pass the middleware", func(t *testing.T) {
+		router := newCSRFTestRouter(CSRFConfig{SessionCookie: "session"})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/csrf", nil))
+		token := w.Body.String()
+
+		req := httptest.NewRequest(http.MethodPost, "/quizzes", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+		req.AddCookie(w.Result().Cookies()[0])
+		req.Header.Set("X-CSRF-Token", token)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
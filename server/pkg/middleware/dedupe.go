@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/correlation"
+)
+
+// dedupeHeaders are the request headers that always tell deduplicated requests apart: the
+// credentials and the preconditions, which change the response
+var dedupeHeaders = []string{"Authorization", "Cookie", "If-None-Match", "If-Modified-Since"}
+
+// sharedResponse is the response of a deduplicated request replayed to the requests that waited
+// for it
+type sharedResponse struct {
+	status    int
+	header    http.Header
+	body      []byte
+	requestID string
+}
+
+// Deduplicate returns a middleware collapsing concurrent identical GET requests into one execution
+// of the rest of the chain, whose response is replayed to the requests that arrived while it ran
+// Requests are identical when they have the same principal, URL, and values of the credential,
+// precondition and vary headers. Nothing is kept once the execution finishes: requests arriving
+// after it run the chain again. Replayed responses carry the headers the chain added, the request
+// ID of the request they answer in the meta block of their envelope, and are counted by the
+// http.server.deduplicated_requests metric. When the execution panics, the requests that waited
+// for it panic with the same error, so that each is answered by Recovery.
+func Deduplicate(vary ...string) gin.HandlerFunc {
+	key := KeyByURLAndHeaders(append(slices.Clone(dedupeHeaders), vary...)...)
+	var group singleflight.Group
+
+	deduplicated, err := otel.Meter("http").Int64Counter("http.server.deduplicated_requests",
+		metric.WithDescription("Number of requests answered with the response of an identical concurrent request, by route"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create deduplicated request counter", zap.Error(err))
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ids := correlation.FromContext(c.Request.Context())
+		requestKey := ids.UserID + "/" + ids.ActorID + "|" + key(c)
+
+		executed := false
+		value, _, _ := group.Do(requestKey, func() (interface{}, error) {
+			executed = true
+			before := c.Writer.Header().Clone()
+			writer := &recordingWriter{ResponseWriter: c.Writer}
+			c.Writer = writer
+			defer func() { c.Writer = writer.ResponseWriter }()
+
+			c.Next()
+
+			return &sharedResponse{
+				status:    writer.Status(),
+				header:    addedHeaders(before, writer.Header()),
+				body:      bytes.Clone(writer.body.Bytes()),
+				requestID: c.GetString(requestIDKey),
+			}, nil
+		})
+		if executed {
+			return
+		}
+
+		response := value.(*sharedResponse)
+		for name, values := range response.header {
+			c.Writer.Header()[name] = slices.Clone(values)
+		}
+		body := response.body
+		if requestID := c.GetString(requestIDKey); requestID != response.requestID {
+			body = withRequestID(body, requestID)
+			c.Writer.Header().Del("Content-Length")
+		}
+		c.Status(response.status)
+		_, _ = c.Writer.Write(body)
+		c.Abort()
+
+		if deduplicated != nil {
+			deduplicated.Add(c.Request.Context(), 1, metric.WithAttributes(attribute.String("route", c.FullPath())))
+		}
+	}
+}
+
+// requestIDFields are the names of the request ID in the meta block of an envelope: its v1 name,
+// and the camelCase one of API versions that rename keys
+var requestIDFields = []string{"request_id", "requestId"}
+
+// withRequestID returns the JSON envelope body with the request ID of its meta block replaced by
+// requestID, or removed when requestID is empty; other bodies are returned unchanged
+func withRequestID(body []byte, requestID string) []byte {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope["meta"] == nil {
+		return body
+	}
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(envelope["meta"], &meta); err != nil {
+		return body
+	}
+	field := ""
+	for _, name := range requestIDFields {
+		if meta[name] != nil {
+			field = name
+			break
+		}
+	}
+	if field == "" {
+		return body
+	}
+
+	if requestID == "" {
+		delete(meta, field)
+	} else {
+		meta[field], _ = json.Marshal(requestID)
+	}
+	var err error
+	if envelope["meta"], err = json.Marshal(meta); err != nil {
+		return body
+	}
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		return body
+	}
+	return rewritten
+}
+
+// addedHeaders returns the headers of after that are not in before, or hold other values
+func addedHeaders(before, after http.Header) http.Header {
+	added := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			added[name] = slices.Clone(values)
+		}
+	}
+	return added
+}
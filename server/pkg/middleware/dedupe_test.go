@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"quizizz.com/internal/api/response"
+	"quizizz.com/internal/api/versioning"
+	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/correlation"
+)
+
+// dedupeTestServer serves GET /quizzes/:id through Deduplicate with a handler that blocks until
+// released, so that tests control which requests overlap
+type dedupeTestServer struct {
+	router   *gin.Engine
+	runs     atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+	requests atomic.Int32
+}
+
+func newDedupeTestServer(handler func(c *gin.Context)) *dedupeTestServer {
+	gin.SetMode(gin.TestMode)
+	s := &dedupeTestServer{started: make(chan struct{}, 100), release: make(chan struct{})}
+
+	s.router = gin.New()
+	s.router.Use(Recovery(nil), func(c *gin.Context) {
+		// Authenticate as X-Test-User and give every request its own ID
+		c.Set(requestIDKey, "req-"+strconv.Itoa(int(s.requests.Add(1))))
+		ids := correlation.IDs{UserID: c.GetHeader("X-Test-User")}
+		c.Request = c.Request.WithContext(correlation.WithIDs(c.Request.Context(), ids))
+	})
+	s.router.GET("/quizzes/:id", Deduplicate("Accept"), func(c *gin.Context) {
+		s.runs.Add(1)
+		s.started <- struct{}{}
+		<-s.release
+		handler(c)
+	})
+	return s
+}
+
+// serve sends requests concurrently: the first one alone, the others once it reached the handler
+// and for long enough to wait for it; it returns their responses in order
+func (s *dedupeTestServer) serve(t *testing.T, requests ...*http.Request) []*httptest.ResponseRecorder {
+	t.Helper()
+	recorders := make([]*httptest.ResponseRecorder, len(requests))
+	var wg sync.WaitGroup
+	send := func(i int) {
+		defer wg.Done()
+		recorders[i] = httptest.NewRecorder()
+		s.router.ServeHTTP(recorders[i], requests[i])
+	}
+
+	wg.Add(len(requests))
+	go send(0)
+	<-s.started
+	for i := 1; i < len(requests); i++ {
+		go send(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(s.release)
+	wg.Wait()
+	return recorders
+}
+
+func newDedupeRequest(target, user string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("X-Test-User", user)
+	return req
+}
+
+func TestDeduplicate(t *testing.T) {
+	respond := func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.Header("X-Quiz", c.Param("id"))
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    gin.H{"id": c.Param("id"), "query": c.Query("page")},
+			"meta":    gin.H{"request_id": c.GetString(requestIDKey)},
+		})
+	}
+
+	t.Run("Collapses concurrent identical requests into one execution", func(t *testing.T) {
+		server := newDedupeTestServer(respond)
+		requests := make([]*http.Request, 5)
+		for i := range requests {
+			requests[i] = newDedupeRequest("/quizzes/q1?page=2", "user-1")
+		}
+
+		recorders := server.serve(t, requests...)
+		assert.Equal(t, int32(1), server.runs.Load())
+		for _, recorder := range recorders {
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, `"v1"`, recorder.Header().Get("ETag"), "headers are copied")
+			assert.Equal(t, "q1", recorder.Header().Get("X-Quiz"))
+			assert.Contains(t, recorder.Body.String(), `"query":"2"`)
+		}
+	})
+
+	t.Run("Replayed responses carry their own request ID", func(t *testing.T) {
+		server := newDedupeTestServer(respond)
+		recorders := server.serve(t, newDedupeRequest("/quizzes/q1", "user-1"), newDedupeRequest("/quizzes/q1", "user-1"))
+		require.Equal(t, int32(1), server.runs.Load())
+
+		ids := make(map[string]bool)
+		for _, recorder := range recorders {
+			var body struct {
+				Meta struct {
+					RequestID string `json:"request_id"`
+				} `json:"meta"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			ids[body.Meta.RequestID] = true
+		}
+		assert.Equal(t, map[string]bool{"req-1": true, "req-2": true}, ids)
+	})
+
+	t.Run("Replayed v2 responses carry their own request ID", func(t *testing.T) {
+		// v2 renames the request_id of the meta block to requestId
+		server := newDedupeTestServer(func(c *gin.Context) {
+			response.SetTransformer(c, versioning.CamelCaseKeys)
+			response.Success(c, gin.H{"id": c.Param("id")})
+		})
+		recorders := server.serve(t, newDedupeRequest("/quizzes/q1", "user-1"), newDedupeRequest("/quizzes/q1", "user-1"))
+		require.Equal(t, int32(1), server.runs.Load())
+
+		ids := make(map[string]bool)
+		for _, recorder := range recorders {
+			var body struct {
+				Meta map[string]string `json:"meta"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.NotContains(t, body.Meta, "request_id")
+			ids[body.Meta["requestId"]] = true
+		}
+		assert.Equal(t, map[string]bool{"req-1": true, "req-2": true}, ids)
+	})
+
+	t.Run("Requests of other users or queries are not shared", func(t *testing.T) {
+		server := newDedupeTestServer(respond)
+		recorders := server.serve(t,
+			newDedupeRequest("/quizzes/q1?page=1", "user-1"),
+			newDedupeRequest("/quizzes/q1?page=1", "user-2"),
+			newDedupeRequest("/quizzes/q1?page=2", "user-1"),
+		)
+
+		assert.Equal(t, int32(3), server.runs.Load())
+		assert.Contains(t, recorders[1].Body.String(), `"query":"1"`)
+		assert.Contains(t, recorders[2].Body.String(), `"query":"2"`)
+	})
+
+	t.Run("Requests with other credentials are not shared", func(t *testing.T) {
+		server := newDedupeTestServer(respond)
+		first, second := newDedupeRequest("/quizzes/q1", ""), newDedupeRequest("/quizzes/q1", "")
+		first.Header.Set("Authorization", "Bearer a")
+		second.Header.Set("Authorization", "Bearer b")
+
+		server.serve(t, first, second)
+		assert.Equal(t, int32(2), server.runs.Load())
+	})
+
+	t.Run("Waiters of a panicking execution are answered by Recovery", func(t *testing.T) {
+		logger.Init("test") // Recovery logs from several goroutines at once
+		server := newDedupeTestServer(func(c *gin.Context) { panic("boom") })
+		recorders := server.serve(t, newDedupeRequest("/quizzes/q1", "user-1"), newDedupeRequest("/quizzes/q1", "user-1"))
+
+		assert.Equal(t, int32(1), server.runs.Load())
+		for _, recorder := range recorders {
+			assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		}
+	})
+
+	t.Run("Other methods are not deduplicated", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		var runs atomic.Int32
+		router := gin.New()
+		router.POST("/quizzes", Deduplicate(), func(c *gin.Context) { runs.Add(1) })
+
+		for i := 0; i < 2; i++ {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/quizzes", nil))
+		}
+		assert.Equal(t, int32(2), runs.Load())
+	})
+}
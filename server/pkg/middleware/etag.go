@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagWriter buffers a response so an ETag can be computed from its body
+// Calling Flush (e.g. from a streaming handler) switches it to pass-through mode.
+type etagWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *etagWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *etagWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *etagWriter) Status() int {
+	if !w.passthrough && w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *etagWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *etagWriter) Written() bool {
+	return w.passthrough || w.body.Len() > 0
+}
+
+func (w *etagWriter) Flush() {
+	if !w.passthrough {
+		w.passthrough = true
+		w.writeBuffered()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// writeBuffered sends the buffered status and body to the underlying writer
+func (w *etagWriter) writeBuffered() {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// ETag returns a middleware that adds weak ETags to successful GET responses and
+// answers 304 Not Modified when If-None-Match matches
+// Handlers may set their own ETag (e.g. derived from a document version); it is kept as is. The
+// request ID in the meta block of an envelope is left out of the hash, since it changes with
+// every request while the representation does not.
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &etagWriter{ResponseWriter: original}
+		c.Writer = writer
+		defer func() { c.Writer = original }()
+
+		c.Next()
+
+		// Nothing was written (e.g. gin's own 404 handling), leave the response to the engine
+		if writer.passthrough || (writer.status == 0 && writer.body.Len() == 0) {
+			return
+		}
+
+		status := writer.Status()
+		if status != http.StatusOK {
+			writer.writeBuffered()
+			return
+		}
+
+		etag := original.Header().Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(withRequestID(writer.body.Bytes(), ""))
+			etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			original.Header().Set("ETag", etag)
+		}
+
+		if ETagMatches(c.GetHeader("If-None-Match"), etag) {
+			original.Header().Del("Content-Type")
+			original.Header().Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		writer.writeBuffered()
+	}
+}
+
+// ETagMatches reports whether an If-None-Match style header matches etag using weak comparison
+func ETagMatches(header, etag string) bool {
+	if header == "" || etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
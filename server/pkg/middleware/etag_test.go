@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	requests := 0
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		requests++
+		c.Set(requestIDKey, "req-"+strconv.Itoa(requests))
+	}, ETag())
+	router.GET("/quizzes/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    gin.H{"id": c.Param("id")},
+			"meta":    gin.H{"request_id": c.GetString(requestIDKey)},
+		})
+	})
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	first := get("/quizzes/q1", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	t.Run("Ignores the request ID of the envelope", func(t *testing.T) {
+		recorder := get("/quizzes/q1", etag)
+		assert.Equal(t, http.StatusNotModified, recorder.Code)
+		assert.Empty(t, recorder.Body.String())
+	})
+
+	t.Run("Changes with the data", func(t *testing.T) {
+		recorder := get("/quizzes/q2", etag)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+		assert.Contains(t, recorder.Body.String(), `"request_id":"req-3"`)
+	})
+}
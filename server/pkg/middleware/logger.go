@@ -2,11 +2,22 @@
 package middleware
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"runtime/debug"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/clientip"
+	"quizizz.com/pkg/correlation"
+	"quizizz.com/pkg/errortracking"
+	"quizizz.com/pkg/requestid"
 )
 
 // requestLog contains the structured fields for request logging
@@ -23,19 +34,37 @@ type requestLog struct {
 	RequestID  string        `json:"requestId,omitempty"`
 }
 
+// LoggerConfig controls which requests are logged
+// The zero value logs every request.
+type LoggerConfig struct {
+	// SampleRate is the fraction of successful requests logged (0.0 - 1.0); defaults to 1.
+	// Requests failing with a 4xx or 5xx status are always logged.
+	SampleRate float64
+
+	// SlowThreshold is the latency above which requests are always logged (0 disables)
+	SlowThreshold time.Duration
+
+	// Skip excludes paths whose requests succeed from the log, e.g. health checks and metrics
+	Skip []string
+}
+
 // Logger returns a gin middleware for logging HTTP requests
-func Logger() gin.HandlerFunc {
+// Logs of sampled requests carry the sample rate, so counts derived from them can be scaled back up.
+func Logger(cfg LoggerConfig) gin.HandlerFunc {
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+	skip := make(map[string]bool, len(cfg.Skip))
+	for _, path := range cfg.Skip {
+		skip[path] = true
+	}
+
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
 		path := c.Request.URL.Path
 		query := c.Request.URL.RawQuery
-		requestID := c.GetHeader("X-Request-ID")
-
-		// Add request ID to context for downstream handlers
-		if requestID != "" {
-			c.Set("requestID", requestID)
-		}
+		requestID := c.GetString("requestID")
 
 		// Process request
 		c.Next()
@@ -44,12 +73,26 @@ func Logger() gin.HandlerFunc {
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
 		bodySize := c.Writer.Size()
-		clientIP := c.ClientIP()
+		clientIP := clientip.Resolve(c)
 		userAgent := c.Request.UserAgent()
 
 		// Add HTTP headers for API responses
 		c.Header("X-Response-Time", latency.String())
 
+		// Errors and slow requests are always logged; others may be skipped or sampled out
+		sampled := false
+		if statusCode < 400 && (cfg.SlowThreshold <= 0 || latency < cfg.SlowThreshold) {
+			if skip[path] {
+				return
+			}
+			if cfg.SampleRate < 1 {
+				if rand.Float64() >= cfg.SampleRate {
+					return
+				}
+				sampled = true
+			}
+		}
+
 		// Build log structure
 		logData := requestLog{
 			ClientIP:   clientIP,
@@ -104,51 +147,79 @@ func Logger() gin.HandlerFunc {
 		if logData.RequestID != "" {
 			logFields = append(logFields, zap.String("requestID", logData.RequestID))
 		}
+		if ids := correlation.FromContext(c.Request.Context()); ids.Impersonated() {
+			logFields = append(logFields, zap.String("userID", ids.UserID), zap.String("actorID", ids.ActorID))
+		}
+		if sampled {
+			logFields = append(logFields, zap.Float64("sampleRate", cfg.SampleRate))
+		}
+		if statusCode >= 500 {
+			// The error is reported by whatever answered with it, e.g. response.Fail or Recovery
+			logFields = append(logFields, logger.NotReported())
+		}
 
 		// Log the request
 		logFunc("http-request", logFields...)
 	}
 }
 
-// RequestID is a middleware that generates a unique ID for each request
+// RequestID is a middleware that assigns a unique ID to each request
+// A valid X-Request-ID from the caller is kept; otherwise a new UUIDv7 is generated.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Use X-Request-ID from the request if it exists
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			// Generate a random request ID (in a real app, use a proper UUID generator)
-			requestID = time.Now().Format("20060102150405.000000")
+		requestID := c.GetHeader(requestid.Header)
+		if !requestid.Valid(requestID) {
+			requestID = requestid.New()
 		}
 
-		// Set the request ID in the context and response header
+		// Set the request ID in the gin context, the request context (for outgoing calls) and the response header
+		// The start time lets handlers report timing in the Server-Timing header
 		c.Set("requestID", requestID)
-		c.Header("X-Request-ID", requestID)
+		c.Set("requestStart", time.Now())
+		c.Request = c.Request.WithContext(requestid.WithContext(c.Request.Context(), requestID))
+		c.Header(requestid.Header, requestID)
 
 		c.Next()
 	}
 }
 
 // Recovery returns a middleware that recovers from panics
-func Recovery() gin.HandlerFunc {
+// Panics are logged with their stack trace, recorded on the request's span and sent to reporter,
+// or errortracking.Default() when it is nil. The request is put in the request context, so errors
+// reported while serving it describe it. Installed after OTEL, so the span is still open when the
+// panic is recorded.
+func Recovery(reporter errortracking.Reporter) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(errortracking.WithRequest(c.Request.Context(), c.Request))
+
 		defer func() {
 			if err := recover(); err != nil {
+				stack := string(debug.Stack())
+				panicReporter := reporter
+				if panicReporter == nil {
+					panicReporter = errortracking.Default()
+				}
+				panicReporter.CapturePanic(err, errortracking.ScopeFromContext(c.Request.Context()))
+
 				// Log the error with stack trace
 				logger.Error("http-panic",
 					zap.Any("error", err),
 					zap.String("method", c.Request.Method),
 					zap.String("path", c.Request.URL.Path),
-					zap.String("clientIP", c.ClientIP()),
+					zap.String("clientIP", clientip.Resolve(c)),
+					zap.String("requestID", c.GetString("requestID")),
+					zap.String("stack", stack),
+					logger.NotReported(),
 				)
 
+				span := trace.SpanFromContext(c.Request.Context())
+				span.RecordError(fmt.Errorf("panic: %v", err), trace.WithAttributes(
+					semconv.ExceptionStacktraceKey.String(stack),
+				))
+				span.SetStatus(codes.Error, "panic")
+
 				// Return a 500 error
-				c.AbortWithStatusJSON(500, gin.H{
-					"success": false,
-					"error": gin.H{
-						"code":    "INTERNAL_ERROR",
-						"message": "An unexpected error occurred",
-					},
-				})
+				abortWithError(c, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred")
 			}
 		}()
 
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NoMethod handles requests whose path is routed but not for their method
+// It must be installed with engine.NoMethod on an engine with HandleMethodNotAllowed set, which
+// fills the Allow header with the methods the path is routed for. OPTIONS requests are answered
+// with 204 No Content, other methods with gin's 405 Method Not Allowed.
+func NoMethod() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodOptions {
+			return
+		}
+
+		allow := c.Writer.Header().Get("Allow")
+		if allow != "" {
+			allow += ", "
+		}
+		c.Header("Allow", allow+http.MethodOptions)
+		c.Status(http.StatusNoContent)
+	}
+}
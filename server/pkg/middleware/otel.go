@@ -15,7 +15,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"quizizz.com/internal/logger"
+	"quizizz.com/pkg/clientip"
 	"quizizz.com/pkg/httpclient"
+	appotel "quizizz.com/pkg/otel"
 )
 
 // TracingContextKey is the key used to store the tracing context in the gin.Context
@@ -30,8 +32,10 @@ func OTEL(serviceName string) gin.HandlerFunc {
 	propagator := otel.GetTextMapPropagator()
 
 	return func(c *gin.Context) {
-		// Extract tracing context from the incoming request headers
+		// Extract tracing context from the incoming request headers, without the baggage members
+		// the server sets, so that the span never records those claimed by the caller
 		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx = appotel.WithoutReservedBaggage(ctx)
 
 		// Start a new span for this request
 		spanName := fmt.Sprintf("%s %s", c.Request.Method, c.FullPath())
@@ -55,7 +59,7 @@ func OTEL(serviceName string) gin.HandlerFunc {
 		}
 
 		// Add client IP information
-		clientIP := c.ClientIP()
+		clientIP := clientip.Resolve(c)
 		if clientIP != "" {
 			attrs = append(attrs, attribute.String("http.client_ip", clientIP))
 			attrs = append(attrs, attribute.String("net.peer.ip", clientIP))
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"quizizz.com/pkg/clientip"
+)
+
+// RequireAuth returns a middleware rejecting requests without credentials in the Authorization header
+// It only checks that credentials are present; verifying them is up to the handlers of the route.
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.TrimSpace(c.GetHeader("Authorization")) == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithError(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// cacheTTLKey is the context key holding the response cache TTL override
+const cacheTTLKey = "cacheTTL"
+
+// CacheTTL returns a middleware overriding the TTL of the response caches of the routes it applies to
+// Routes that do not cache responses server-side are unaffected.
+func CacheTTL(ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(cacheTTLKey, ttl)
+		c.Next()
+	}
+}
+
+// RateLimiter limits the rate of requests of each client with a token bucket per client
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket holds the tokens of a client as of updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing rate requests per second with bursts of up to burst requests
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:      rate,
+		burst:     math.Max(float64(burst), 1),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token for key, returning false with how long until one is available when none is left
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets refilled since their last use, at most once a minute
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit returns a middleware limiting requests per client IP with limiter
+// Rejected requests get 429 Too Many Requests with a Retry-After header.
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.Allow(clientip.Resolve(c))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			abortWithError(c, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortWithError aborts the request with an error response in the API's envelope
+func abortWithError(c *gin.Context, status int, code, message string) {
+	abortWithDetails(c, status, code, message, nil)
+}
+
+// abortWithDetails aborts the request with an error response carrying details in the API's envelope
+func abortWithDetails(c *gin.Context, status int, code, message string, details gin.H) {
+	body := gin.H{
+		"code":    code,
+		"message": message,
+	}
+	if details != nil {
+		body["details"] = details
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error":   body,
+		"meta": gin.H{
+			"request_id": c.GetString("requestID"),
+		},
+	})
+}
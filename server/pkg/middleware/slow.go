@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Context keys of the slow request detection
+const (
+	latencyBudgetKey = "latencyBudget"
+	handlerStartKey  = "handlerStart"
+	handlerEndKey    = "handlerEnd"
+)
+
+// LatencyBudget returns a middleware setting the latency budget of the routes it applies to,
+// overriding the default of SlowRequests; the innermost budget applies
+func LatencyBudget(budget time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(latencyBudgetKey, budget)
+		c.Next()
+	}
+}
+
+// HandlerTiming returns a middleware recording when the handlers after it start and finish, so
+// SlowRequests can tell the time spent in the handler from the time spent in middleware
+// It must be installed right before the route's handler.
+func HandlerTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(handlerStartKey, time.Now())
+		c.Next()
+		c.Set(handlerEndKey, time.Now())
+	}
+}
+
+// SlowRequests returns a middleware flagging requests slower than their route's latency budget,
+// or defaultBudget for routes without one (0 flags none of them)
+// Slow requests are logged at warn level with the time spent in middleware and in the handler,
+// marked slow=true on their span and counted by the http.server.slow_requests metric. It must be
+// installed after OTEL to annotate the request's span.
+func SlowRequests(defaultBudget time.Duration) gin.HandlerFunc {
+	slowRequests, err := otel.Meter("http").Int64Counter("http.server.slow_requests",
+		metric.WithDescription("Number of requests exceeding the latency budget of their route, by route and method"),
+	)
+	if err != nil {
+		logger.Warn("Failed to create slow request counter", zap.Error(err))
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		budget := defaultBudget
+		if value, ok := c.Get(latencyBudgetKey); ok {
+			budget = value.(time.Duration)
+		}
+		if budget <= 0 || latency <= budget {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := c.Request.Context()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.Int("statusCode", c.Writer.Status()),
+			zap.Duration("latency", latency),
+			zap.Duration("budget", budget),
+		}
+		handlerStart, started := c.Get(handlerStartKey)
+		handlerEnd, ended := c.Get(handlerEndKey)
+		if started && ended {
+			handler := handlerEnd.(time.Time).Sub(handlerStart.(time.Time))
+			fields = append(fields,
+				zap.Duration("handler", handler),
+				zap.Duration("middleware", latency-handler),
+			)
+		}
+		logger.WarnCtx(ctx, "slow-request", fields...)
+
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Bool("slow", true),
+			attribute.Int64("latency_budget_ms", budget.Milliseconds()),
+		)
+
+		if slowRequests != nil {
+			slowRequests.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("route", route),
+				attribute.String("method", c.Request.Method),
+			))
+		}
+	}
+}
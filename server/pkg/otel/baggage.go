@@ -0,0 +1,99 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// Baggage keys propagated to downstream services and copied onto spans
+const (
+	BaggageUserID        = "user.id"
+	BaggageTenantID      = "tenant.id"
+	BaggageClientVersion = "client.version"
+
+	// BaggageActorID is the administrator impersonating the user, if any
+	BaggageActorID = "actor.id"
+)
+
+// spanBaggageKeys are the baggage members copied onto every span as attributes
+// Other members are propagated but not recorded, since callers control their contents.
+var spanBaggageKeys = []string{BaggageUserID, BaggageTenantID, BaggageClientVersion, BaggageActorID}
+
+// WithoutReservedBaggage returns ctx with the members only the server sets removed from its baggage
+// Use it on baggage extracted from untrusted callers, which could otherwise claim any user, tenant
+// or actor on spans and towards downstream services.
+func WithoutReservedBaggage(ctx context.Context) context.Context {
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return ctx
+	}
+	for _, key := range spanBaggageKeys {
+		bag = bag.DeleteMember(key)
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// WithBaggage returns ctx with the given members added to its baggage and to the active span
+// Empty values are skipped; invalid values are logged and skipped.
+func WithBaggage(ctx context.Context, members map[string]string) context.Context {
+	bag := baggage.FromContext(ctx)
+	span := trace.SpanFromContext(ctx)
+
+	for key, value := range members {
+		if value == "" {
+			continue
+		}
+
+		member, err := baggage.NewMemberRaw(key, value)
+		if err == nil {
+			bag, err = bag.SetMember(member)
+		}
+		if err != nil {
+			logger.WarnCtx(ctx, "Failed to set baggage member", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		span.SetAttributes(attribute.String(key, value))
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// WithUser returns ctx carrying the user and tenant in its baggage
+func WithUser(ctx context.Context, userID, tenantID string) context.Context {
+	return WithBaggage(ctx, map[string]string{
+		BaggageUserID:   userID,
+		BaggageTenantID: tenantID,
+	})
+}
+
+// BaggageValue returns the value of a baggage member of ctx, or "" when absent
+func BaggageValue(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// baggageSpanProcessor copies well-known baggage members onto spans as they start
+type baggageSpanProcessor struct{}
+
+// newBaggageSpanProcessor creates a processor recording spanBaggageKeys as span attributes
+func newBaggageSpanProcessor() sdktrace.SpanProcessor {
+	return baggageSpanProcessor{}
+}
+
+func (baggageSpanProcessor) OnStart(ctx context.Context, s sdktrace.ReadWriteSpan) {
+	bag := baggage.FromContext(ctx)
+	for _, key := range spanBaggageKeys {
+		if value := bag.Member(key).Value(); value != "" {
+			s.SetAttributes(attribute.String(key, value))
+		}
+	}
+}
+
+func (baggageSpanProcessor) OnEnd(sdktrace.ReadOnlySpan)      {}
+func (baggageSpanProcessor) Shutdown(context.Context) error   { return nil }
+func (baggageSpanProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,111 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+)
+
+// Supported trace exporters, named as in OTEL_TRACES_EXPORTER
+const (
+	ExporterOTLP     = "otlp"
+	ExporterOTLPHTTP = "otlp_http"
+	ExporterStdout   = "stdout"
+	ExporterZipkin   = "zipkin"
+	ExporterNone     = "none"
+)
+
+// newTraceExporters creates the exporters listed in cfg.OTEL.TracesExporters
+// Exporters connect in the background with retries, so an unreachable backend never blocks startup.
+func newTraceExporters(cfg *config.Config) ([]sdktrace.SpanExporter, error) {
+	var exporters []sdktrace.SpanExporter
+	for _, name := range cfg.OTEL.TracesExporters {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == ExporterNone {
+			continue
+		}
+
+		if !supportedExporters[name] {
+			return nil, fmt.Errorf("unsupported trace exporter %q", name)
+		}
+
+		exporterName := name
+		exporters = append(exporters, newResilientExporter(name, cfg.OTEL.ExporterConnectTimeout,
+			func(ctx context.Context) (sdktrace.SpanExporter, error) {
+				return newTraceExporter(ctx, exporterName, cfg)
+			},
+		))
+		logger.Info("Connecting trace exporter in the background", zap.String("exporter", name))
+	}
+	return exporters, nil
+}
+
+// supportedExporters are the accepted OTEL_TRACES_EXPORTER values
+var supportedExporters = map[string]bool{
+	ExporterOTLP: true, "otlp_grpc": true, ExporterOTLPHTTP: true,
+	ExporterStdout: true, "console": true, ExporterZipkin: true,
+}
+
+// newTraceExporter creates a single exporter by name
+func newTraceExporter(ctx context.Context, name string, cfg *config.Config) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterOTLP, "otlp_grpc":
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.OTEL.TracingExporterEndpoint),
+			otlptracegrpc.WithTimeout(cfg.OTEL.ExporterTimeout),
+		}
+		if cfg.OTEL.TracingExporterInsecure {
+			logger.Info("Using insecure connection for OTLP exporter")
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.OTEL.TracingHTTPEndpoint),
+			otlptracehttp.WithTimeout(cfg.OTEL.ExporterTimeout),
+		}
+		if cfg.OTEL.TracingExporterInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	case ExporterStdout, "console":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+
+	case ExporterZipkin:
+		return zipkin.New(cfg.OTEL.ZipkinEndpoint)
+
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", name)
+	}
+}
+
+// fanoutProcessor forwards ended spans to several processors
+type fanoutProcessor []sdktrace.SpanProcessor
+
+func (f fanoutProcessor) OnStart(ctx context.Context, s sdktrace.ReadWriteSpan) {
+	for _, p := range f {
+		p.OnStart(ctx, s)
+	}
+}
+
+func (f fanoutProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	for _, p := range f {
+		p.OnEnd(s)
+	}
+}
+
+// Shutdown and ForceFlush are no-ops; the processors are registered and shut down separately
+func (f fanoutProcessor) Shutdown(context.Context) error   { return nil }
+func (f fanoutProcessor) ForceFlush(context.Context) error { return nil }
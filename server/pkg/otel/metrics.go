@@ -0,0 +1,98 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+)
+
+var (
+	// Global meter provider
+	meterProvider *sdkmetric.MeterProvider
+
+	// Registry backing the Prometheus metrics endpoint
+	registry = prometheus.NewRegistry()
+
+	// To ensure we only initialize metrics once
+	meterOnce sync.Once
+)
+
+// InitMeter initializes the OpenTelemetry meter provider with a Prometheus exporter
+// Instruments created through otel.Meter before this call are bound once it completes
+func InitMeter(ctx context.Context, cfg *config.Config) (*sdkmetric.MeterProvider, error) {
+	var err error
+
+	meterOnce.Do(func() {
+		logger.Info("Initializing OpenTelemetry metrics",
+			zap.String("service", cfg.OTEL.ServiceName),
+			zap.String("path", cfg.OTEL.MetricsPath),
+		)
+
+		exporter, expErr := otelprom.New(otelprom.WithRegisterer(registry))
+		if expErr != nil {
+			err = fmt.Errorf("failed to create prometheus exporter: %w", expErr)
+			logger.Error("Failed to create prometheus exporter", zap.Error(err))
+			return
+		}
+
+		res, resErr := resource.New(ctx,
+			resource.WithAttributes(
+				semconv.ServiceName(cfg.OTEL.ServiceName),
+				attribute.String("environment", cfg.Env),
+			),
+		)
+		if resErr != nil {
+			err = fmt.Errorf("failed to create resource: %w", resErr)
+			logger.Error("Failed to create resource", zap.Error(err))
+			return
+		}
+
+		// Measurements recorded within a sampled span keep its trace as exemplar
+		meterProvider = sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(exporter),
+			sdkmetric.WithResource(res),
+			sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter),
+		)
+		otel.SetMeterProvider(meterProvider)
+		startRuntimeInstrumentation(cfg, meterProvider)
+
+		logger.Info("OpenTelemetry metrics initialized successfully")
+	})
+
+	return meterProvider, err
+}
+
+// MetricsHandler returns the HTTP handler serving metrics in the Prometheus exposition format, or in
+// the OpenMetrics format, which carries exemplars, to scrapers accepting it
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// shutdownMeter flushes and stops the meter provider if it was initialized
+func shutdownMeter(ctx context.Context) error {
+	if meterProvider == nil {
+		return nil
+	}
+
+	logger.Info("Shutting down OpenTelemetry meter provider")
+	err := meterProvider.Shutdown(ctx)
+	if err != nil {
+		logger.Error("Error shutting down meter provider", zap.Error(err))
+	}
+
+	return err
+}
@@ -0,0 +1,131 @@
+package otel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"quizizz.com/internal/logger"
+)
+
+// resilientExporter connects an exporter in the background, retrying until it succeeds, and logs
+// when exporting starts failing and recovers instead of on every failed batch
+// Spans exported before the connection succeeds are dropped, so tracing outages never block serving.
+type resilientExporter struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.RWMutex
+	exporter sdktrace.SpanExporter
+
+	degraded atomic.Bool
+	dropped  atomic.Int64
+}
+
+// newResilientExporter starts connecting with create, each attempt bounded by attemptTimeout
+func newResilientExporter(name string, attemptTimeout time.Duration, create func(ctx context.Context) (sdktrace.SpanExporter, error)) *resilientExporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &resilientExporter{name: name, cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(e.done)
+		e.connect(ctx, attemptTimeout, create)
+	}()
+
+	return e
+}
+
+// connect creates the exporter, retrying with exponential backoff until it succeeds or ctx ends
+func (e *resilientExporter) connect(ctx context.Context, attemptTimeout time.Duration, create func(ctx context.Context) (sdktrace.SpanExporter, error)) {
+	retry := backoff.NewExponentialBackOff()
+	retry.MaxInterval = time.Minute
+	retry.MaxElapsedTime = 0
+
+	attempt := 0
+	err := backoff.Retry(func() error {
+		attempt++
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		defer cancel()
+
+		exporter, err := create(attemptCtx)
+		if err != nil {
+			e.markDegraded("connect", err, zap.Int("attempt", attempt))
+			return err
+		}
+
+		e.mu.Lock()
+		e.exporter = exporter
+		e.mu.Unlock()
+		return nil
+	}, backoff.WithContext(retry, ctx))
+
+	if err == nil {
+		e.markRecovered()
+	}
+}
+
+// ExportSpans implements sdktrace.SpanExporter
+func (e *resilientExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.RLock()
+	exporter := e.exporter
+	e.mu.RUnlock()
+
+	if exporter == nil {
+		e.dropped.Add(int64(len(spans)))
+		return nil
+	}
+
+	if err := exporter.ExportSpans(ctx, spans); err != nil {
+		e.dropped.Add(int64(len(spans)))
+		e.markDegraded("export", err)
+		return err
+	}
+
+	e.markRecovered()
+	return nil
+}
+
+// Shutdown stops connecting and shuts the exporter down
+func (e *resilientExporter) Shutdown(ctx context.Context) error {
+	e.cancel()
+	<-e.done
+
+	e.mu.RLock()
+	exporter := e.exporter
+	e.mu.RUnlock()
+
+	if exporter == nil {
+		return nil
+	}
+	return exporter.Shutdown(ctx)
+}
+
+// markDegraded logs the first failure after a healthy period
+func (e *resilientExporter) markDegraded(stage string, err error, fields ...zap.Field) {
+	if e.degraded.Swap(true) {
+		return
+	}
+	logger.Warn("Tracing degraded, spans are being dropped",
+		append(fields,
+			zap.String("exporter", e.name),
+			zap.String("stage", stage),
+			zap.Error(err),
+		)...,
+	)
+}
+
+// markRecovered logs the first success after failures, with the number of spans lost meanwhile
+func (e *resilientExporter) markRecovered() {
+	if !e.degraded.Swap(false) {
+		return
+	}
+	logger.Info("Tracing recovered",
+		zap.String("exporter", e.name),
+		zap.Int64("droppedSpans", e.dropped.Swap(0)),
+	)
+}
@@ -0,0 +1,31 @@
+package otel
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/host"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+	"quizizz.com/internal/config"
+	"quizizz.com/internal/logger"
+)
+
+// startRuntimeInstrumentation records Go runtime metrics (goroutines, GC pauses, heap) and host
+// metrics (CPU, memory, network) on provider, as enabled by cfg
+// Failures are logged rather than returned, since they should not keep the service from starting.
+func startRuntimeInstrumentation(cfg *config.Config, provider *sdkmetric.MeterProvider) {
+	if cfg.OTEL.RuntimeMetrics {
+		err := runtime.Start(
+			runtime.WithMeterProvider(provider),
+			runtime.WithMinimumReadMemStatsInterval(cfg.OTEL.RuntimeMetricsInterval),
+		)
+		if err != nil {
+			logger.Warn("Failed to start runtime metrics", zap.Error(err))
+		}
+	}
+
+	if cfg.OTEL.HostMetrics {
+		if err := host.Start(host.WithMeterProvider(provider)); err != nil {
+			logger.Warn("Failed to start host metrics", zap.Error(err))
+		}
+	}
+}
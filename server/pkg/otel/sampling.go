@@ -0,0 +1,177 @@
+package otel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplingConfig declares how traces are sampled
+type SamplingConfig struct {
+	// DefaultRatio is the share of traces sampled on routes without a rule (0.0 - 1.0)
+	DefaultRatio float64
+
+	// Routes overrides the ratio per route, keyed by route pattern; a trailing * matches any suffix
+	// (e.g. "/health": 0, "/api/v1/users*": 0.5). The longest matching pattern wins.
+	Routes map[string]float64
+
+	// MaxPerSecond caps the number of new traces sampled per second (0 disables the cap)
+	MaxPerSecond float64
+
+	// SampleErrors records spans that were not sampled, so the tail processor can still export
+	// traces that end with an error or run slow
+	SampleErrors bool
+}
+
+// routeSampler is the sampling ratio of a route pattern
+type routeSampler struct {
+	pattern string
+	prefix  bool
+	sampler sdktrace.Sampler
+}
+
+// matches reports whether route is covered by the pattern
+func (r routeSampler) matches(route string) bool {
+	if r.prefix {
+		return strings.HasPrefix(route, r.pattern)
+	}
+	return route == r.pattern
+}
+
+// ruleSampler samples root spans by route ratio and rate limit
+type ruleSampler struct {
+	cfg      SamplingConfig
+	routes   []routeSampler
+	fallback sdktrace.Sampler
+	limiter  *rateLimiter
+}
+
+// NewSampler creates the sampler described by cfg
+// Child spans follow their parent's decision; root spans are sampled by route rules.
+func NewSampler(cfg SamplingConfig) sdktrace.Sampler {
+	root := &ruleSampler{
+		cfg:      cfg,
+		fallback: sdktrace.TraceIDRatioBased(cfg.DefaultRatio),
+	}
+	if cfg.MaxPerSecond > 0 {
+		root.limiter = newRateLimiter(cfg.MaxPerSecond)
+	}
+
+	for pattern, ratio := range cfg.Routes {
+		prefix := strings.HasSuffix(pattern, "*")
+		root.routes = append(root.routes, routeSampler{
+			pattern: strings.TrimSuffix(pattern, "*"),
+			prefix:  prefix,
+			sampler: sdktrace.TraceIDRatioBased(ratio),
+		})
+	}
+	// Longest patterns first, so the most specific rule wins
+	sort.Slice(root.routes, func(i, j int) bool {
+		return len(root.routes[i].pattern) > len(root.routes[j].pattern)
+	})
+
+	notSampled := sdktrace.NeverSample()
+	if cfg.SampleErrors {
+		notSampled = recordOnlySampler{}
+	}
+
+	return sdktrace.ParentBased(root,
+		sdktrace.WithRemoteParentNotSampled(notSampled),
+		sdktrace.WithLocalParentNotSampled(notSampled),
+	)
+}
+
+// ShouldSample implements sdktrace.Sampler
+func (s *ruleSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.samplerFor(routeOf(p)).ShouldSample(p)
+
+	if result.Decision == sdktrace.RecordAndSample && s.limiter != nil && !s.limiter.allow() {
+		result.Decision = sdktrace.Drop
+	}
+	if result.Decision == sdktrace.Drop && s.cfg.SampleErrors {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+// Description implements sdktrace.Sampler
+func (s *ruleSampler) Description() string {
+	return fmt.Sprintf("RuleSampler{default=%g,routes=%d,maxPerSecond=%g,sampleErrors=%t}",
+		s.cfg.DefaultRatio, len(s.routes), s.cfg.MaxPerSecond, s.cfg.SampleErrors)
+}
+
+// samplerFor returns the sampler of the most specific rule matching route
+func (s *ruleSampler) samplerFor(route string) sdktrace.Sampler {
+	if route != "" {
+		for _, rule := range s.routes {
+			if rule.matches(route) {
+				return rule.sampler
+			}
+		}
+	}
+	return s.fallback
+}
+
+// routeOf returns the route of a span from its start attributes, falling back to the span name
+func routeOf(p sdktrace.SamplingParameters) string {
+	for _, attr := range p.Attributes {
+		if attr.Key == semconv.HTTPRouteKey && attr.Value.AsString() != "" {
+			return attr.Value.AsString()
+		}
+	}
+	if _, path, ok := strings.Cut(p.Name, " "); ok {
+		return path
+	}
+	return p.Name
+}
+
+// recordOnlySampler records spans without sampling them, so errors can still be exported
+type recordOnlySampler struct{}
+
+func (recordOnlySampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return sdktrace.SamplingResult{
+		Decision:   sdktrace.RecordOnly,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+}
+
+func (recordOnlySampler) Description() string {
+	return "RecordOnly"
+}
+
+// rateLimiter is a token bucket allowing up to perSecond events per second
+type rateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	tokens    float64
+	last      time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{perSecond: perSecond, tokens: perSecond, last: time.Now()}
+}
+
+// allow takes a token if one is available
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > l.perSecond {
+		l.tokens = l.perSecond
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,159 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testTraceID is a trace ID sampled by any ratio above zero
+var testTraceID = trace.TraceID{0x01}
+
+// rootParams returns the sampling parameters of a root span
+func rootParams(name string, attrs ...attribute.KeyValue) sdktrace.SamplingParameters {
+	return sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       testTraceID,
+		Name:          name,
+		Kind:          trace.SpanKindServer,
+		Attributes:    attrs,
+	}
+}
+
+// childParams returns the sampling parameters of a span whose parent was sampled or not
+func childParams(name string, sampled, remote bool) sdktrace.SamplingParameters {
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    testTraceID,
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: flags,
+		Remote:     remote,
+	})
+	params := rootParams(name)
+	params.ParentContext = trace.ContextWithSpanContext(context.Background(), parent)
+	return params
+}
+
+func TestSampler(t *testing.T) {
+	t.Run("Samples root spans by route", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{
+			DefaultRatio: 1,
+			Routes: map[string]float64{
+				"/health":        0,
+				"/api/*":         0,
+				"/api/v1/users*": 1,
+			},
+		})
+
+		tests := []struct {
+			name   string
+			params sdktrace.SamplingParameters
+			want   sdktrace.SamplingDecision
+		}{
+			{name: "Exact routes", params: rootParams("GET /health"), want: sdktrace.Drop},
+			{name: "Exact routes do not match longer paths", params: rootParams("GET /healthz"), want: sdktrace.RecordAndSample},
+			{name: "Prefix routes", params: rootParams("GET /api/v1/quizzes"), want: sdktrace.Drop},
+			{name: "The longest pattern wins", params: rootParams("GET /api/v1/users/42"), want: sdktrace.RecordAndSample},
+			{name: "Routes without a rule use the default ratio", params: rootParams("GET /login"), want: sdktrace.RecordAndSample},
+			{
+				name:   "The route attribute wins over the span name",
+				params: rootParams("GET /api/v1/users/42", semconv.HTTPRoute("/health")),
+				want:   sdktrace.Drop,
+			},
+			{name: "Names without a method are routes", params: rootParams("/health"), want: sdktrace.Drop},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, sampler.ShouldSample(tt.params).Decision)
+			})
+		}
+	})
+
+	t.Run("Drops everything at a zero default ratio", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{})
+
+		assert.Equal(t, sdktrace.Drop, sampler.ShouldSample(rootParams("GET /login")).Decision)
+	})
+
+	t.Run("Child spans follow their parent", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{DefaultRatio: 1, Routes: map[string]float64{"/health": 0}})
+
+		for _, remote := range []bool{false, true} {
+			assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(childParams("GET /health", true, remote)).Decision,
+				"sampled parent, remote %t", remote)
+			assert.Equal(t, sdktrace.Drop, sampler.ShouldSample(childParams("GET /login", false, remote)).Decision,
+				"unsampled parent, remote %t", remote)
+		}
+	})
+
+	t.Run("Records spans dropped by sampling when sampling errors", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{Routes: map[string]float64{"/api/*": 1}, SampleErrors: true})
+
+		assert.Equal(t, sdktrace.RecordOnly, sampler.ShouldSample(rootParams("GET /login")).Decision)
+		assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(rootParams("GET /api/quizzes")).Decision)
+		for _, remote := range []bool{false, true} {
+			assert.Equal(t, sdktrace.RecordOnly, sampler.ShouldSample(childParams("GET /api/quizzes", false, remote)).Decision,
+				"unsampled parent, remote %t", remote)
+			assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(childParams("GET /login", true, remote)).Decision,
+				"sampled parent, remote %t", remote)
+		}
+	})
+
+	t.Run("Caps new traces per second", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{DefaultRatio: 1, MaxPerSecond: 2})
+
+		var decisions []sdktrace.SamplingDecision
+		for i := 0; i < 3; i++ {
+			decisions = append(decisions, sampler.ShouldSample(rootParams("GET /login")).Decision)
+		}
+		assert.Equal(t, []sdktrace.SamplingDecision{sdktrace.RecordAndSample, sdktrace.RecordAndSample, sdktrace.Drop}, decisions)
+
+		assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(childParams("GET /login", true, false)).Decision,
+			"children of sampled traces are not capped")
+	})
+
+	t.Run("Records traces over the cap when sampling errors", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{DefaultRatio: 1, MaxPerSecond: 1, SampleErrors: true})
+
+		assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(rootParams("GET /login")).Decision)
+		assert.Equal(t, sdktrace.RecordOnly, sampler.ShouldSample(rootParams("GET /login")).Decision)
+	})
+
+	t.Run("Keeps the parent trace state", func(t *testing.T) {
+		sampler := NewSampler(SamplingConfig{SampleErrors: true})
+		state, err := trace.ParseTraceState("vendor=value")
+		assert.NoError(t, err)
+
+		params := childParams("GET /login", false, true)
+		parent := trace.SpanContextFromContext(params.ParentContext).WithTraceState(state)
+		params.ParentContext = trace.ContextWithSpanContext(context.Background(), parent)
+
+		assert.Equal(t, "vendor=value", sampler.ShouldSample(params).Tracestate.String())
+	})
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2)
+
+	assert.True(t, limiter.allow())
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow())
+
+	limiter.last = limiter.last.Add(-500 * time.Millisecond)
+	assert.True(t, limiter.allow(), "tokens refill over time")
+	assert.False(t, limiter.allow())
+
+	limiter.last = limiter.last.Add(-5 * time.Second)
+	assert.True(t, limiter.allow())
+	assert.True(t, limiter.allow())
+	assert.False(t, limiter.allow(), "refills are capped at one second of tokens")
+}
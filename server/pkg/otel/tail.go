@@ -0,0 +1,176 @@
+package otel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TailConfig declares which unsampled traces are exported once they complete
+type TailConfig struct {
+	// LatencyThreshold exports traces whose local root span took longer (0 disables latency retention)
+	LatencyThreshold time.Duration
+
+	// MaxTraces bounds the number of traces buffered at once; the oldest are decided early when full
+	MaxTraces int
+
+	// MaxSpansPerTrace bounds the spans buffered per trace; further spans are dropped
+	MaxSpansPerTrace int
+}
+
+// decisionTTL is how long the decision for a completed trace applies to its late spans
+const decisionTTL = time.Minute
+
+// bufferedTrace holds the recorded spans of an unsampled trace until its local root ends
+type bufferedTrace struct {
+	spans   []sdktrace.ReadOnlySpan
+	failed  bool
+	started time.Time
+}
+
+// decision remembers whether a completed trace was exported
+type decision struct {
+	export    bool
+	expiresAt time.Time
+}
+
+// tailSpanProcessor buffers recorded but unsampled spans per trace and exports the whole trace
+// when any span failed or the local root exceeded the latency threshold
+// Exported spans are handed to the export processor marked as sampled, which would otherwise skip them.
+type tailSpanProcessor struct {
+	cfg    TailConfig
+	export sdktrace.SpanProcessor
+
+	mu        sync.Mutex
+	traces    map[trace.TraceID]*bufferedTrace
+	decisions map[trace.TraceID]decision
+}
+
+// newTailSpanProcessor creates a processor forwarding retained traces to export
+func newTailSpanProcessor(cfg TailConfig, export sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	if cfg.MaxTraces <= 0 {
+		cfg.MaxTraces = 10000
+	}
+	if cfg.MaxSpansPerTrace <= 0 {
+		cfg.MaxSpansPerTrace = 1000
+	}
+	return &tailSpanProcessor{
+		cfg:       cfg,
+		export:    export,
+		traces:    make(map[trace.TraceID]*bufferedTrace),
+		decisions: make(map[trace.TraceID]decision),
+	}
+}
+
+func (p *tailSpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *tailSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+	failed := s.Status().Code == codes.Error
+	root := !s.Parent().IsValid() || s.Parent().IsRemote()
+
+	var export []sdktrace.ReadOnlySpan
+	p.mu.Lock()
+	if d, ok := p.decisions[traceID]; ok && time.Now().Before(d.expiresAt) {
+		// A span ending after its trace was decided, e.g. from a background goroutine
+		if d.export || failed {
+			export = []sdktrace.ReadOnlySpan{s}
+		}
+		p.mu.Unlock()
+		p.forward(export)
+		return
+	}
+
+	buffered, ok := p.traces[traceID]
+	if !ok {
+		if len(p.traces) >= p.cfg.MaxTraces {
+			export = p.evictOldest()
+		}
+		buffered = &bufferedTrace{started: time.Now()}
+		p.traces[traceID] = buffered
+	}
+	if len(buffered.spans) < p.cfg.MaxSpansPerTrace {
+		buffered.spans = append(buffered.spans, s)
+	}
+	buffered.failed = buffered.failed || failed
+
+	if root {
+		slow := p.cfg.LatencyThreshold > 0 && s.EndTime().Sub(s.StartTime()) > p.cfg.LatencyThreshold
+		keep := buffered.failed || slow
+		if keep {
+			export = append(export, buffered.spans...)
+		}
+		p.decide(traceID, keep)
+	}
+	p.mu.Unlock()
+
+	p.forward(export)
+}
+
+// decide records the decision for a completed trace and drops its buffer; callers hold mu
+func (p *tailSpanProcessor) decide(traceID trace.TraceID, export bool) {
+	delete(p.traces, traceID)
+
+	now := time.Now()
+	for id, d := range p.decisions {
+		if now.After(d.expiresAt) {
+			delete(p.decisions, id)
+		}
+	}
+	p.decisions[traceID] = decision{export: export, expiresAt: now.Add(decisionTTL)}
+}
+
+// evictOldest decides the longest buffered trace early, returning its spans when it failed; callers hold mu
+func (p *tailSpanProcessor) evictOldest() []sdktrace.ReadOnlySpan {
+	var oldestID trace.TraceID
+	var oldest *bufferedTrace
+	for id, buffered := range p.traces {
+		if oldest == nil || buffered.started.Before(oldest.started) {
+			oldestID, oldest = id, buffered
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+
+	p.decide(oldestID, oldest.failed)
+	if oldest.failed {
+		return oldest.spans
+	}
+	return nil
+}
+
+// forward hands spans to the export processor as sampled spans
+func (p *tailSpanProcessor) forward(spans []sdktrace.ReadOnlySpan) {
+	for _, s := range spans {
+		p.export.OnEnd(sampledSpan{ReadOnlySpan: s})
+	}
+}
+
+// Shutdown drops buffered traces; the export processor is registered and shut down separately
+func (p *tailSpanProcessor) Shutdown(context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.traces = make(map[trace.TraceID]*bufferedTrace)
+	return nil
+}
+
+func (p *tailSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+// sampledSpan presents a recorded span as sampled
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
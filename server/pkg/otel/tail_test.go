@@ -0,0 +1,166 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTailTestTracer creates a tracer sampling the routes under /sampled and recording the rest for
+// the tail processor; exported spans end up in the returned exporter
+func newTailTestTracer(t *testing.T, cfg TailConfig) (trace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	export := sdktrace.NewSimpleSpanProcessor(exporter)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewSampler(SamplingConfig{Routes: map[string]float64{"/sampled*": 1}, SampleErrors: true})),
+		sdktrace.WithSpanProcessor(export),
+		sdktrace.WithSpanProcessor(newTailSpanProcessor(cfg, export)),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider.Tracer("test"), exporter
+}
+
+// spanNames returns the names of the exported spans, checking they are all marked as sampled
+func spanNames(t *testing.T, exporter *tracetest.InMemoryExporter) []string {
+	t.Helper()
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		assert.True(t, span.SpanContext.IsSampled(), span.Name)
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+func TestTailSpanProcessor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Drops fast traces without errors", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{LatencyThreshold: time.Hour})
+
+		ctx, root := tracer.Start(ctx, "GET /quizzes")
+		_, child := tracer.Start(ctx, "db.find")
+		child.End()
+		root.End()
+
+		assert.Empty(t, spanNames(t, exporter))
+	})
+
+	t.Run("Keeps whole traces with an error", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{})
+
+		ctx, root := tracer.Start(ctx, "GET /quizzes")
+		_, failed := tracer.Start(ctx, "db.find")
+		failed.RecordError(errors.New("timeout"))
+		failed.SetStatus(codes.Error, "timeout")
+		failed.End()
+		_, ok := tracer.Start(ctx, "cache.get")
+		ok.End()
+		assert.Empty(t, spanNames(t, exporter), "traces are decided when their root ends")
+		root.End()
+
+		assert.Equal(t, []string{"db.find", "cache.get", "GET /quizzes"}, spanNames(t, exporter))
+	})
+
+	t.Run("Keeps slow traces", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{LatencyThreshold: time.Second})
+		start := time.Now()
+
+		ctx, root := tracer.Start(ctx, "GET /reports", trace.WithTimestamp(start))
+		_, child := tracer.Start(ctx, "db.aggregate", trace.WithTimestamp(start))
+		child.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+		root.End(trace.WithTimestamp(start.Add(2 * time.Second)))
+
+		_, fast := tracer.Start(context.Background(), "GET /quizzes", trace.WithTimestamp(start))
+		fast.End(trace.WithTimestamp(start.Add(500 * time.Millisecond)))
+
+		assert.Equal(t, []string{"db.aggregate", "GET /reports"}, spanNames(t, exporter))
+	})
+
+	t.Run("Does not export sampled traces twice", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{})
+
+		_, root := tracer.Start(ctx, "GET /sampled")
+		root.SetStatus(codes.Error, "failed")
+		root.End()
+
+		assert.Equal(t, []string{"GET /sampled"}, spanNames(t, exporter))
+	})
+
+	t.Run("Treats spans with a remote parent as roots", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{})
+		remote := trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: trace.TraceID{0x0a},
+			SpanID:  trace.SpanID{0x0b},
+		}))
+
+		ctx, root := tracer.Start(remote, "consume quiz.created")
+		_, child := tracer.Start(ctx, "db.insert")
+		child.SetStatus(codes.Error, "duplicate")
+		child.End()
+		root.End()
+
+		assert.Equal(t, []string{"db.insert", "consume quiz.created"}, spanNames(t, exporter))
+	})
+
+	t.Run("Applies the decision to late spans", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{})
+
+		keptCtx, kept := tracer.Start(ctx, "GET /quizzes")
+		kept.SetStatus(codes.Error, "failed")
+		droppedCtx, dropped := tracer.Start(ctx, "GET /users")
+		_, lateKept := tracer.Start(keptCtx, "audit.write")
+		_, lateDropped := tracer.Start(droppedCtx, "audit.write")
+		_, lateFailed := tracer.Start(droppedCtx, "email.send")
+		kept.End()
+		dropped.End()
+
+		lateKept.End()
+		lateDropped.End()
+		lateFailed.SetStatus(codes.Error, "bounced")
+		lateFailed.End()
+
+		assert.Equal(t, []string{"GET /quizzes", "audit.write", "email.send"}, spanNames(t, exporter))
+	})
+
+	t.Run("Decides the oldest trace early when full", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{MaxTraces: 1})
+
+		firstCtx, first := tracer.Start(ctx, "GET /quizzes")
+		_, failed := tracer.Start(firstCtx, "db.find")
+		failed.SetStatus(codes.Error, "timeout")
+		failed.End()
+
+		secondCtx, second := tracer.Start(ctx, "GET /users")
+		_, child := tracer.Start(secondCtx, "db.find")
+		child.End()
+		assert.Equal(t, []string{"db.find"}, spanNames(t, exporter), "the failed trace is exported when evicted")
+
+		first.End()
+		second.End()
+		assert.Equal(t, []string{"db.find", "GET /quizzes"}, spanNames(t, exporter), "its root follows the early decision")
+	})
+
+	t.Run("Caps the spans buffered per trace", func(t *testing.T) {
+		tracer, exporter := newTailTestTracer(t, TailConfig{MaxSpansPerTrace: 2})
+
+		ctx, root := tracer.Start(ctx, "GET /quizzes")
+		for _, name := range []string{"db.find", "cache.get", "cache.set"} {
+			_, child := tracer.Start(ctx, name)
+			child.End()
+		}
+		root.SetStatus(codes.Error, "failed")
+		root.End()
+
+		require.Len(t, exporter.GetSpans(), 2)
+		assert.Equal(t, []string{"db.find", "cache.get"}, spanNames(t, exporter))
+	})
+}
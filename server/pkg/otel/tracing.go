@@ -9,15 +9,11 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"google.golang.org/grpc"
 	"quizizz.com/internal/config"
 	"quizizz.com/internal/logger"
 )
@@ -49,43 +45,31 @@ func InitTracer(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvid
 	once.Do(func() {
 		logger.Info("Initializing OpenTelemetry tracer",
 			zap.String("service", cfg.OTEL.ServiceName),
-			zap.String("endpoint", cfg.OTEL.TracingExporterEndpoint),
+			zap.Strings("exporters", cfg.OTEL.TracesExporters),
 		)
 
+		propagator, propErr := NewPropagator(cfg.OTEL.Propagators)
+		if propErr != nil {
+			err = propErr
+			logger.Error("Failed to create propagator", zap.Error(err))
+			return
+		}
+
 		// If OTEL is disabled, use a no-op tracer
 		if !cfg.OTEL.Enabled {
 			logger.Info("OpenTelemetry tracing is disabled")
 			tracerProvider = sdktrace.NewTracerProvider()
 			tracer = tracerProvider.Tracer(cfg.OTEL.ServiceName)
 			otel.SetTracerProvider(tracerProvider)
-			otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-				propagation.TraceContext{},
-				propagation.Baggage{},
-			))
+			otel.SetTextMapPropagator(propagator)
 			return
 		}
 
-		// Create a new OTLP exporter
-		var traceExporter *otlptrace.Exporter
-		var opts []otlptracegrpc.Option
-
-		// Set endpoint
-		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.OTEL.TracingExporterEndpoint))
-
-		// Use insecure credentials if configured, otherwise use the default secure credentials
-		if cfg.OTEL.TracingExporterInsecure {
-			logger.Info("Using insecure connection for OTLP exporter")
-			opts = append(opts, otlptracegrpc.WithInsecure())
-		}
-
-		// Add gRPC dial option
-		opts = append(opts, otlptracegrpc.WithDialOption(grpc.WithBlock()))
-
-		traceExporter, err = otlptracegrpc.New(ctx, opts...)
-
-		if err != nil {
-			err = fmt.Errorf("failed to create trace exporter: %w", err)
-			logger.Error("Failed to create trace exporter", zap.Error(err))
+		// Create the configured exporters; they connect in the background
+		exporters, expErr := newTraceExporters(cfg)
+		if expErr != nil {
+			err = expErr
+			logger.Error("Failed to create trace exporters", zap.Error(err))
 			return
 		}
 
@@ -105,21 +89,38 @@ func InitTracer(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvid
 
 		// Configure trace sampling
 		samplingRatio := cfg.OTEL.TracingSampleRatio
-		sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))
-
-		// Create a trace provider with the exporter
-		tracerProvider = sdktrace.NewTracerProvider(
+		sampler := NewSampler(SamplingConfig{
+			DefaultRatio: samplingRatio,
+			Routes:       cfg.OTEL.SamplingRouteRatios,
+			MaxPerSecond: cfg.OTEL.TracingMaxPerSecond,
+			SampleErrors: cfg.OTEL.SampleErrors,
+		})
+
+		// Create a trace provider batching spans to every exporter; failed or slow traces dropped
+		// by sampling are exported too once they complete
+		providerOpts := []sdktrace.TracerProviderOption{
 			sdktrace.WithSampler(sampler),
-			sdktrace.WithBatcher(traceExporter),
 			sdktrace.WithResource(res),
-		)
+			sdktrace.WithSpanProcessor(newBaggageSpanProcessor()),
+		}
+		var batchers fanoutProcessor
+		for _, exporter := range exporters {
+			batcher := sdktrace.NewBatchSpanProcessor(exporter)
+			batchers = append(batchers, batcher)
+			providerOpts = append(providerOpts, sdktrace.WithSpanProcessor(batcher))
+		}
+		if cfg.OTEL.SampleErrors && len(batchers) > 0 {
+			tail := newTailSpanProcessor(TailConfig{
+				LatencyThreshold: cfg.OTEL.TailLatencyThreshold,
+				MaxTraces:        cfg.OTEL.TailMaxTraces,
+			}, batchers)
+			providerOpts = append(providerOpts, sdktrace.WithSpanProcessor(tail))
+		}
+		tracerProvider = sdktrace.NewTracerProvider(providerOpts...)
 
 		// Set the global trace provider and propagator
 		otel.SetTracerProvider(tracerProvider)
-		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-			propagation.TraceContext{},
-			propagation.Baggage{},
-		))
+		otel.SetTextMapPropagator(propagator)
 
 		// Create a tracer
 		tracer = tracerProvider.Tracer(cfg.OTEL.ServiceName)
@@ -133,20 +134,23 @@ func InitTracer(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvid
 	return tracerProvider, err
 }
 
-// Shutdown gracefully shuts down the tracer provider
+// Shutdown gracefully shuts down the tracer and meter providers
 func Shutdown(ctx context.Context) error {
-	if tracerProvider == nil {
-		return nil
-	}
-
-	// Allow some time for traces to be flushed
+	// Allow some time for traces and metrics to be flushed
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	logger.Info("Shutting down OpenTelemetry tracer")
-	err := tracerProvider.Shutdown(ctx)
-	if err != nil {
-		logger.Error("Error shutting down tracer provider", zap.Error(err))
+	var err error
+	if tracerProvider != nil {
+		logger.Info("Shutting down OpenTelemetry tracer")
+		err = tracerProvider.Shutdown(ctx)
+		if err != nil {
+			logger.Error("Error shutting down tracer provider", zap.Error(err))
+		}
+	}
+
+	if meterErr := shutdownMeter(ctx); meterErr != nil && err == nil {
+		err = meterErr
 	}
 
 	return err
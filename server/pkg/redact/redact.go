@@ -0,0 +1,138 @@
+// Package redact removes secrets from headers, URLs and bodies before they are logged or stored
+package redact
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces redacted values
+const Placeholder = "[REDACTED]"
+
+// DefaultHeaders are always redacted
+var DefaultHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+	"X-Api-Key", "X-Amz-Security-Token", "X-Signature", "X-CSRF-Token",
+}
+
+// DefaultFields are body fields and query parameters always redacted
+var DefaultFields = []string{
+	"password", "secret", "token", "access_token", "refresh_token", "client_secret", "api_key", "apiKey",
+}
+
+// Redactor redacts configured headers, fields and patterns in addition to the defaults
+type Redactor struct {
+	headers  map[string]bool
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// New creates a Redactor for the given headers, field names (case-insensitive) and body patterns
+func New(headers, fields []string, patterns []*regexp.Regexp) *Redactor {
+	r := &Redactor{
+		headers:  make(map[string]bool),
+		fields:   make(map[string]bool),
+		patterns: patterns,
+	}
+	for _, header := range append(DefaultHeaders, headers...) {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, field := range append(DefaultFields, fields...) {
+		r.fields[strings.ToLower(field)] = true
+	}
+	return r
+}
+
+// Header returns a copy of header with sensitive values redacted
+func (r *Redactor) Header(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{Placeholder}
+		}
+	}
+	return redacted
+}
+
+// Values returns a copy of values with sensitive keys redacted
+func (r *Redactor) Values(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for key, value := range values {
+		if r.fields[strings.ToLower(key)] {
+			redacted[key] = []string{Placeholder}
+		} else {
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+// URL returns u with sensitive query parameters and user info redacted
+func (r *Redactor) URL(u *url.URL) string {
+	copied := *u
+	if copied.User != nil {
+		copied.User = url.User(Placeholder)
+	}
+	copied.RawQuery = r.Values(copied.Query()).Encode()
+	return copied.String()
+}
+
+// Body redacts a complete body according to its content type: fields of JSON and form bodies, and
+// pattern matches in any body
+func (r *Redactor) Body(body []byte, contentType string) string {
+	text := string(body)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		text = r.JSON(body)
+	case "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(text); err == nil {
+			text = r.Values(values).Encode()
+		}
+	}
+	return r.Text(text)
+}
+
+// Text replaces pattern matches in text, e.g. in truncated bodies that cannot be parsed
+func (r *Redactor) Text(text string) string {
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllString(text, Placeholder)
+	}
+	return text
+}
+
+// JSON redacts sensitive fields of a JSON document at any depth, returning invalid JSON unchanged
+func (r *Redactor) JSON(raw []byte) string {
+	var document interface{}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return string(raw)
+	}
+
+	redacted, err := json.Marshal(r.value(document))
+	if err != nil {
+		return string(raw)
+	}
+	return string(redacted)
+}
+
+func (r *Redactor) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.fields[strings.ToLower(key)] {
+				v[key] = Placeholder
+			} else {
+				v[key] = r.value(field)
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = r.value(elem)
+		}
+	}
+	return value
+}
@@ -45,6 +45,7 @@ REDIS_PORT=6379
 REDIS_PASSWORD=
 REDIS_DB=0
 REDIS_TIMEOUT=5s
+REDIS_SLOW_COMMAND_THRESHOLD=20ms
 
 # OpenTelemetry Configuration
 OTEL_ENABLED=true
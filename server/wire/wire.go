@@ -4,90 +4,586 @@
 package wire
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
 	"quizizz.com/internal/api"
+	"quizizz.com/internal/api/routes"
 	"quizizz.com/internal/app"
+	"quizizz.com/internal/auth/impersonation"
+	"quizizz.com/internal/auth/rbac"
+	"quizizz.com/internal/auth/throttle"
+	"quizizz.com/internal/clients"
 	"quizizz.com/internal/config"
+	"quizizz.com/internal/domain"
+	"quizizz.com/internal/domain/events"
+	"quizizz.com/internal/gdpr"
+	"quizizz.com/internal/healthcheck"
+	"quizizz.com/internal/jobs"
+	"quizizz.com/internal/logger"
+	"quizizz.com/internal/modules"
 	"quizizz.com/internal/repository"
 	"quizizz.com/internal/resources"
+	"quizizz.com/internal/retention"
+	"quizizz.com/internal/saga"
+	"quizizz.com/internal/search"
 	"quizizz.com/internal/service"
+	"quizizz.com/internal/views"
+	"quizizz.com/internal/web"
+	"quizizz.com/pkg/clock"
+	"quizizz.com/pkg/idgen"
+	"quizizz.com/pkg/metrics"
+	"quizizz.com/pkg/middleware"
+)
+
+// Options parametrize InitializeApp
+type Options struct {
+	// Resources are connected resources to build the app on; when nil, InitializeApp creates and
+	// connects them itself
+	Resources *resources.Resources
+}
+
+// ClockSet is a Wire provider set for the clock shared by services and repositories
+var ClockSet = wire.NewSet(
+	clock.New,
 )
 
-// ResourcesSet is a Wire provider set for resources
+// IDSet is a Wire provider set for the ID generator shared by services and repositories
+var IDSet = wire.NewSet(
+	provideIDGenerator,
+)
+
+// ResourcesSet is a Wire provider set for the connected resources
 var ResourcesSet = wire.NewSet(
-	resources.NewDB,
-	resources.NewRedis,
 	provideResources,
+	providePush,
+	provideSearch,
+	provideObjectStorage,
 )
 
 // RepositorySet is a Wire provider set for repositories
 var RepositorySet = wire.NewSet(
 	provideUserRepository,
+	provideJobRepository,
+	provideAuditRepository,
+	provideSessionRepository,
+	providePreferencesRepository,
+	provideFileRepository,
+	provideDeviceRepository,
+	providePushReceiptRepository,
+	provideSagaRepository,
+	provideUserSummaryRepository,
+	provideUploadRepository,
+)
+
+// JobsSet is a Wire provider set for the background job queue
+var JobsSet = wire.NewSet(
+	provideJobQueue,
+	provideRetentionPolicies,
+	saga.NewCoordinator,
+	provideViewMaintainer,
+	provideUserIndex,
+	provideSearchIndexer,
+)
+
+// AuthSet is a Wire provider set for authentication components
+var AuthSet = wire.NewSet(
+	provideLoginThrottle,
+	rbac.NewAuthorizer,
+	impersonation.NewTokens,
+)
+
+// CommandSet is a Wire provider set for the write services, which validate writes and publish the
+// events that keep the read models up to date
+var CommandSet = wire.NewSet(
+	provideEventBus,
+	wire.Bind(new(events.Publisher), new(*events.Bus)),
+	provideUserService,
+)
+
+// QuerySet is a Wire provider set for the read services, which answer from the read models and
+// their caches without touching the write path
+var QuerySet = wire.NewSet(
+	service.NewUserSummaryService,
 )
 
 // ServiceSet is a Wire provider set for services
 var ServiceSet = wire.NewSet(
 	service.NewAppService,
-	service.NewUserService,
+	service.NewGDPRService,
+	service.NewSessionService,
+	service.NewAdminService,
+	service.NewPreferencesService,
+	service.NewAvatarService,
+	service.NewNotificationService,
+	service.NewUploadService,
+	provideGDPRRegistry,
+)
+
+// ClientSet is a Wire provider set for downstream service clients
+var ClientSet = wire.NewSet(
+	clients.NewRegistry,
 )
 
+// APISet is a Wire provider set for the HTTP API
+var APISet = wire.NewSet(
+	provideResponseCache,
+	provideHealthChecks,
+	provideRenderer,
+	routes.NewPolicies,
+	api.NewHandler,
+)
+
+// AppSet composes the provider sets into the application
+var AppSet = wire.NewSet(
+	ClockSet,
+	IDSet,
+	ResourcesSet,
+	RepositorySet,
+	JobsSet,
+	CommandSet,
+	QuerySet,
+	ServiceSet,
+	AuthSet,
+	ClientSet,
+	modules.ProviderSet,
+	APISet,
+	app.NewApp,
+)
+
+// InitializeApp wires up the dependencies and returns an App
+func InitializeApp(cfg *config.Config, opts Options) (*app.App, error) {
+	wire.Build(AppSet)
+	return &app.App{}, nil
+}
+
+// provideResources provides the resources of opts, or creates and connects them
+// Repositories sync their collections when created, so the resources must be connected first.
+func provideResources(cfg *config.Config, opts Options) (*resources.Resources, error) {
+	if opts.Resources != nil {
+		return opts.Resources, nil
+	}
+
+	res := &resources.Resources{
+		DB:    resources.NewDB(cfg),
+		Redis: resources.NewRedis(cfg),
+		Push:  resources.NewPush(cfg),
+	}
+	if cfg.Search.Enabled {
+		res.Search = resources.NewSearch(cfg)
+	}
+	if cfg.Uploads.Enabled {
+		res.Storage = resources.NewObjectStorage(cfg)
+	}
+	if err := resources.InitResources(context.Background(), res); err != nil {
+		return nil, fmt.Errorf("failed to initialize resources: %w", err)
+	}
+	return res, nil
+}
+
+// providePush provides the push resource, connecting one when the provided resources have none
+func providePush(cfg *config.Config, res *resources.Resources) (resources.PushResource, error) {
+	if res.Push != nil {
+		return res.Push, nil
+	}
+
+	push := resources.NewPush(cfg)
+	if err := push.Connect(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to initialize push: %w", err)
+	}
+	res.Push = push
+	return push, nil
+}
+
+// provideSearch provides the search resource, connecting one when search is enabled and the
+// provided resources have none; it is nil while search is disabled
+func provideSearch(cfg *config.Config, res *resources.Resources) (resources.SearchResource, error) {
+	if res.Search != nil || !cfg.Search.Enabled {
+		return res.Search, nil
+	}
+
+	cluster := resources.NewSearch(cfg)
+	if err := cluster.Connect(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to initialize search: %w", err)
+	}
+	res.Search = cluster
+	return cluster, nil
+}
+
+// provideObjectStorage provides the object storage uploads go to, connecting one when uploads are
+// enabled and the provided resources have none; it is nil while uploads are disabled
+func provideObjectStorage(cfg *config.Config, res *resources.Resources) (resources.ObjectStorageResource, error) {
+	if res.Storage != nil || !cfg.Uploads.Enabled {
+		return res.Storage, nil
+	}
+
+	storage := resources.NewObjectStorage(cfg)
+	if err := storage.Connect(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to initialize object storage: %w", err)
+	}
+	res.Storage = storage
+	return storage, nil
+}
+
 // provideUserRepository provides a UserRepository
-func provideUserRepository(db resources.DBResource) repository.UserRepository {
-	return repository.NewUserRepository(db)
+func provideUserRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.UserRepository, error) {
+	codec, err := idCodec(cfg, "users", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewUserRepository(res.DB, userCacheConfig(cfg, res.Redis), clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
 }
 
-// provideResources provides a resources.Resources struct with all resources
-func provideResources(db resources.DBResource, redis resources.RedisResource) *resources.Resources {
-	return &resources.Resources{
-		DB:    db,
-		Redis: redis,
+// provideJobRepository provides a JobRepository
+func provideJobRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.JobRepository, error) {
+	codec, err := idCodec(cfg, "jobs", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewJobRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
 	}
+	return repo, nil
 }
 
-// InitializeApp wires up the dependencies and returns an App
-func InitializeApp() (*app.App, error) {
-	wire.Build(
-		// Configuration
-		config.NewConfig,
+// provideAuditRepository provides an AuditRepository
+func provideAuditRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.AuditRepository, error) {
+	codec, err := idCodec(cfg, "audit_log", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewAuditRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// provideSessionRepository provides a SessionRepository
+func provideSessionRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.SessionRepository, error) {
+	codec, err := idCodec(cfg, "sessions", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewSessionRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// providePreferencesRepository provides a PreferencesRepository keyed by user IDs, cached like users
+func providePreferencesRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.PreferencesRepository, error) {
+	codec, err := idCodec(cfg, "users", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewPreferencesRepository(res.DB, userCacheConfig(cfg, res.Redis), clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// provideFileRepository provides the FileRepository storing avatars and other binary files in GridFS
+func provideFileRepository(cfg *config.Config, res *resources.Resources) (repository.FileRepository, error) {
+	return repository.NewFileRepository(res.DB, cfg)
+}
 
-		// Resources
-		ResourcesSet,
+// provideDeviceRepository provides the DeviceRepository storing the devices registered for push
+// notifications
+func provideDeviceRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.DeviceRepository, error) {
+	codec, err := idCodec(cfg, "devices", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewDeviceRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
 
-		// Repositories
-		RepositorySet,
+// providePushReceiptRepository provides the PushReceiptRepository
+func providePushReceiptRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.PushReceiptRepository, error) {
+	codec, err := idCodec(cfg, "push_receipts", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewPushReceiptRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
 
-		// Services
-		ServiceSet,
+// provideSagaRepository provides the SagaRepository persisting the progress of workflows
+func provideSagaRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.SagaRepository, error) {
+	codec, err := idCodec(cfg, "sagas", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewSagaRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
 
-		// API Handlers
-		api.NewHandler,
+// provideUserSummaryRepository provides the UserSummaryRepository keyed by user IDs, cached like users
+func provideUserSummaryRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.UserSummaryRepository, error) {
+	codec, err := idCodec(cfg, "users", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewUserSummaryRepository(res.DB, userCacheConfig(cfg, res.Redis), clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
 
-		// App
-		app.NewApp,
-	)
-	return &app.App{}, nil
+// provideUploadRepository provides the UploadRepository storing the uploads to object storage
+func provideUploadRepository(cfg *config.Config, res *resources.Resources, clk clock.Clock, ids idgen.Generator) (repository.UploadRepository, error) {
+	codec, err := idCodec(cfg, "uploads", ids)
+	if err != nil {
+		return nil, err
+	}
+	repo := repository.NewUploadRepository(res.DB, clk, codec)
+	if err := syncCollection(cfg, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
 }
 
-// InitializeAppWithResources wires up the dependencies with pre-initialized resources
-// This is used when resources are initialized before Wire creates the app
-func InitializeAppWithResources(cfg *config.Config, res *resources.Resources) (*app.App, error) {
-	wire.Build(
-		// Repositories - use the provided resources
-		provideUserRepositoryFromResources,
+// provideJobQueue provides the job queue with the retention job registered
+// Other handlers are registered by the services that own them.
+func provideJobQueue(cfg *config.Config, repo repository.JobRepository, clk clock.Clock, policies []retention.Policy) *jobs.Queue {
+	queue := jobs.NewQueue(repo, clk, cfg.Jobs)
+	retention.NewEnforcer(policies, queue, clk, cfg.Retention)
+	return queue
+}
 
-		// Services
-		ServiceSet,
+// provideViewMaintainer provides the maintainer of the read models, following change streams when
+// enabled
+func provideViewMaintainer(cfg *config.Config, res *resources.Resources, queue *jobs.Queue, clk clock.Clock, summaries repository.UserSummaryRepository) (*views.Maintainer, error) {
+	var source views.Source
+	if cfg.Views.ChangeStreams {
+		source = views.NewChangeStreams(res.DB, clk)
+	}
+	return views.NewMaintainer([]views.View{
+		views.NewUserSummaryView(summaries),
+	}, queue, source, clk, cfg.Views)
+}
+
+// provideUserIndex provides the search index of users
+func provideUserIndex(cfg *config.Config, res resources.SearchResource, clk clock.Clock) *search.Users {
+	return search.NewUsers(res, clk, cfg.Search)
+}
+
+// provideSearchIndexer provides the Indexer keeping the user index up to date
+func provideSearchIndexer(index *search.Users, users repository.UserRepository, queue *jobs.Queue) *search.Indexer {
+	return search.NewIndexer(index, users, queue)
+}
+
+// provideEventBus provides the bus delivering domain events to the read models and the search index
+func provideEventBus(maintainer *views.Maintainer, indexer *search.Indexer) *events.Bus {
+	bus := events.NewBus()
+	bus.Subscribe(maintainer.Handle)
+	bus.Subscribe(indexer.Handle)
+	return bus
+}
 
-		// API Handlers
-		api.NewHandler,
+// provideUserService provides the UserService recording the business metrics and publishing the
+// events of its writes, and answering searches from the user index
+func provideUserService(repo repository.UserRepository, publisher events.Publisher, index *search.Users, ids idgen.Generator, clk clock.Clock) service.UserService {
+	users := service.NewInstrumentedUserService(service.NewUserService(repo, clk), metrics.Default, clk)
+	users = service.NewPublishingUserService(users, publisher, ids, clk)
+	return service.NewIndexedUserService(users, index)
+}
+
+// provideRetentionPolicies provides the retention rules of each collection
+func provideRetentionPolicies(cfg *config.Config, res *resources.Resources) []retention.Policy {
+	return []retention.Policy{
+		{
+			Collection: repository.NewPurger(res.DB, "jobs"),
+			Rules: []retention.Rule{{
+				Name:   "finished",
+				Field:  "updatedAt",
+				MaxAge: cfg.Retention.JobsMaxAge,
+				Filter: bson.M{"status": bson.M{"$in": bson.A{repository.JobSucceeded, repository.JobDead}}},
+			}},
+		},
+		{
+			Collection: repository.NewPurger(res.DB, "audit_log"),
+			Rules: []retention.Rule{
+				retention.OlderThan("createdAt", cfg.Retention.AuditLogMaxAge).Archive(),
+			},
+		},
+		{
+			Collection: repository.NewPurger(res.DB, "push_receipts"),
+			Rules: []retention.Rule{
+				retention.OlderThan("createdAt", cfg.Retention.PushReceiptsMaxAge),
+			},
+		},
+		{
+			Collection: repository.NewPurger(res.DB, "sagas"),
+			Rules: []retention.Rule{{
+				Name:   "finished",
+				Field:  "updatedAt",
+				MaxAge: cfg.Retention.SagasMaxAge,
+				Filter: bson.M{"status": bson.M{"$in": bson.A{repository.SagaCompleted, repository.SagaCompensated}}},
+			}},
+		},
+		{
+			Collection: repository.NewPurger(res.DB, "uploads"),
+			Rules: []retention.Rule{{
+				Name:   "abandoned",
+				Field:  "createdAt",
+				MaxAge: cfg.Uploads.PendingMaxAge,
+				Filter: bson.M{"status": domain.UploadPending},
+			}},
+		},
+	}
+}
 
-		// App
-		app.NewApp,
+// provideLoginThrottle provides the login throttle, sharing its state through Redis
+// Without a Redis client the state is kept in memory, which only holds for a single instance.
+func provideLoginThrottle(cfg *config.Config, res *resources.Resources, audit repository.AuditRepository, clk clock.Clock) *throttle.Throttle {
+	var store throttle.Store
+	if client, ok := res.Redis.Client().(*redis.Client); ok && client != nil {
+		store = throttle.NewRedisStore(client)
+	} else {
+		logger.Warn("Redis client unavailable, login throttle state kept in memory")
+		store = throttle.NewMemoryStore(clk)
+	}
+	return throttle.New(store, audit, cfg.LoginThrottle)
+}
+
+// provideGDPRRegistry provides the collections holding personal data, users first
+func provideGDPRRegistry(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	preferencesRepo repository.PreferencesRepository,
+	files repository.FileRepository,
+	devices repository.DeviceRepository,
+	receipts repository.PushReceiptRepository,
+	summaries repository.UserSummaryRepository,
+	uploads repository.UploadRepository,
+	storage resources.ObjectStorageResource,
+) *gdpr.Registry {
+	return gdpr.NewRegistry(
+		gdpr.Users(userRepo),
+		gdpr.Sessions(sessionRepo),
+		gdpr.Preferences(preferencesRepo),
+		gdpr.Avatars(userRepo, files),
+		gdpr.Devices(devices),
+		gdpr.PushReceipts(receipts),
+		gdpr.UserSummaries(summaries),
+		gdpr.Uploads(uploads, storage),
 	)
-	return &app.App{}, nil
 }
 
-// provideUserRepositoryFromResources creates a user repository from pre-initialized resources
-func provideUserRepositoryFromResources(res *resources.Resources) repository.UserRepository {
-	return repository.NewUserRepository(res.DB)
+// idCodec returns the ID codec declared for collection in the configuration
+func idCodec(cfg *config.Config, collection string, ids idgen.Generator) (repository.IDCodec, error) {
+	codec, err := repository.NewIDCodec(cfg.IDs.Codecs[collection], ids)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID codec for %s: %w", collection, err)
+	}
+	return codec, nil
+}
+
+// provideIDGenerator provides the ID generator selected by the configured strategy
+func provideIDGenerator(cfg *config.Config, clk clock.Clock) (idgen.Generator, error) {
+	return idgen.New(cfg.IDs.Strategy, clk)
+}
+
+// userCacheConfig returns the cache settings for the user repository
+func userCacheConfig(cfg *config.Config, redis resources.RedisResource) repository.CacheConfig {
+	if !cfg.RepositoryCache.Enabled {
+		return repository.CacheConfig{}
+	}
+
+	return repository.CacheConfig{
+		Redis:       redis,
+		TTL:         cfg.RepositoryCache.TTL,
+		NegativeTTL: cfg.RepositoryCache.NegativeTTL,
+		CountTTL:    cfg.RepositoryCache.CountTTL,
+	}
+}
+
+// provideResponseCache provides the Redis-backed response cache, or nil when disabled
+func provideResponseCache(cfg *config.Config, res *resources.Resources) *middleware.ResponseCache {
+	if !cfg.ResponseCache.Enabled {
+		return nil
+	}
+
+	client, ok := res.Redis.Client().(*redis.Client)
+	if !ok || client == nil {
+		return nil
+	}
+
+	return middleware.NewResponseCache(client, cfg.ResponseCache.KeyPrefix)
+}
+
+// provideHealthChecks provides the readiness checks shared by /readyz and the gRPC health service
+func provideHealthChecks(cfg *config.Config, res *resources.Resources) *healthcheck.Registry {
+	checks := healthcheck.NewRegistry(cfg.Health.CheckTimeout)
+	if cfg.Health.HistorySize > 0 {
+		checks.KeepHistory(cfg.Health.HistorySize, cfg.Health.IncidentThreshold)
+	}
+
+	optional := make(map[string]bool, len(cfg.Health.OptionalChecks))
+	for _, name := range cfg.Health.OptionalChecks {
+		optional[name] = true
+	}
+	register := func(res resources.Resource) {
+		if optional[res.Name()] {
+			checks.RegisterOptional(res.Name(), res.Ping)
+			return
+		}
+		checks.RegisterResource(res)
+	}
+
+	register(res.DB)
+	register(res.Redis)
+	if res.Push != nil {
+		register(res.Push)
+	}
+	if res.Search != nil {
+		register(res.Search)
+	}
+	if res.Storage != nil {
+		register(res.Storage)
+	}
+	return checks
+}
+
+// provideRenderer provides the renderer of the HTML pages, or nil when pages are disabled
+func provideRenderer(cfg *config.Config) (*web.Renderer, error) {
+	if !cfg.Pages.Enabled {
+		return nil, nil
+	}
+	return web.NewRenderer(cfg)
+}
+
+// syncCollection applies the repository's declared schema and indexes when startup sync is enabled
+func syncCollection(cfg *config.Config, repo interface{}) error {
+	syncer, ok := repo.(repository.CollectionSyncer)
+	if !ok || !cfg.MongoDB.SyncCollectionsOnStartup {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MongoDB.Timeout)
+	defer cancel()
+
+	return syncer.SyncCollection(ctx)
 }
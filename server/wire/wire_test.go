@@ -0,0 +1,19 @@
+package wire
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerated verifies that wire_gen.go is generated from the current injectors
+func TestGenerated(t *testing.T) {
+	path, err := exec.LookPath("wire")
+	if err != nil {
+		t.Skip("wire is not installed")
+	}
+
+	out, err := exec.Command(path, "diff", ".").CombinedOutput()
+	require.NoError(t, err, "wire_gen.go is out of date, run make wire:\n%s", out)
+}